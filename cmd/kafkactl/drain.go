@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/decommission"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// drainPlan is decommission.Plan printed as its moves
+type drainPlan decommission.Plan
+
+// Header implements cli.Table
+func (p drainPlan) Header() []string {
+	return moveHeader
+}
+
+// Rows implements cli.Table
+func (p drainPlan) Rows() [][]string {
+	return moveRows(p.Moves)
+}
+
+// drainStatus is decommission.Status printed as a table
+type drainStatus decommission.Status
+
+// Header implements cli.Table
+func (s drainStatus) Header() []string {
+	return []string{"BROKER", "STATE", "MOVES", "MESSAGE"}
+}
+
+// Rows implements cli.Table
+func (s drainStatus) Rows() [][]string {
+	return [][]string{{
+		strconv.Itoa(int(s.BrokerID)),
+		string(s.State),
+		fmt.Sprintf("%d/%d", s.CompletedMoves, s.PlannedMoves),
+		s.Message,
+	}}
+}
+
+// runDrain starts draining a broker through the sidecar's decommission
+// workflow, or with --dry-run prints its plan
+func runDrain(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var sidecars sidecarFlags
+	fs := newFlagSet("drain", &g)
+	sidecars.register(fs, env)
+	dryRun := fs.Bool("dry-run", false, "print the planned moves without draining")
+	confirm := fs.Bool("confirm-min-isr-reduction", false, "allow lowering min.insync.replicas of topics that could no longer satisfy it (DECOMMISSION_MIN_ISR_POLICY=lower)")
+	requestedBy := fs.String("requested-by", env.Get("USER"), "who requested the drain, for the audit trail")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("usage: kafkactl drain [FLAGS] BROKER_ID")
+	}
+	brokerID, err := strconv.ParseInt(fs.Arg(0), 10, 32)
+	if err != nil || brokerID < 0 {
+		return usageErrorf("invalid broker ID: %q", fs.Arg(0))
+	}
+	client, err := sidecars.client(g.timeout)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		var plan decommission.Plan
+		if err := client.call(ctx, http.MethodGet, fmt.Sprintf("/admin/decommission/%d/plan", brokerID), nil, &plan); err != nil {
+			return err
+		}
+		return cli.Write(w, g.Output, drainPlan(plan))
+	}
+
+	var started decommission.StartResponse
+	err = client.call(ctx, http.MethodPost, fmt.Sprintf("/admin/decommission/%d", brokerID), decommission.StartRequest{
+		ConfirmMinISRReduction: *confirm,
+		RequestedBy:            *requestedBy,
+	}, &started)
+	if err != nil {
+		return err
+	}
+
+	status := started.Status
+	err = g.wait(ctx, func(ctx context.Context) (bool, error) {
+		if err := client.call(ctx, http.MethodGet, "/admin/decommission", nil, &status); err != nil {
+			return false, err
+		}
+		switch status.State {
+		case decommission.StateCompleted:
+			return true, nil
+		case decommission.StateFailed:
+			return false, fmt.Errorf("%w: draining broker %d: %s", cli.ErrOperationFailed, brokerID, status.Message)
+		}
+		return false, nil
+	})
+	if werr := cli.Write(w, g.Output, drainStatus(status)); werr != nil {
+		return werr
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// Results of a preferred leader election
+const (
+	electionPending   = "pending"
+	electionElected   = "elected"
+	electionNotNeeded = "not-needed"
+	electionFailed    = "failed"
+)
+
+// election is a partition whose leader is not its preferred replica
+type election struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Leader    int32  `json:"leader"`
+	Preferred int32  `json:"preferredLeader"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// elections is what kafkactl elect-leaders prints
+type elections []election
+
+// Header implements cli.Table
+func (e elections) Header() []string {
+	return []string{"TOPIC", "PARTITION", "LEADER", "PREFERRED", "RESULT"}
+}
+
+// Rows implements cli.Table
+func (e elections) Rows() [][]string {
+	rows := make([][]string, 0, len(e))
+	for _, p := range e {
+		result := p.Result
+		if p.Error != "" {
+			result += ": " + p.Error
+		}
+		rows = append(rows, []string{p.Topic, strconv.Itoa(int(p.Partition)), strconv.Itoa(int(p.Leader)), strconv.Itoa(int(p.Preferred)), result})
+	}
+	return rows
+}
+
+// imbalancedLeaders returns the partitions led by another broker than their
+// preferred (first) replica, when the preferred replica is in sync and can
+// take over
+func imbalancedLeaders(md kadm.Metadata) elections {
+	found := elections{}
+	md.Topics.EachPartition(func(p kadm.PartitionDetail) {
+		if p.Err != nil || len(p.Replicas) == 0 {
+			return
+		}
+		preferred := p.Replicas[0]
+		if p.Leader == preferred || !slices.Contains(p.ISR, preferred) {
+			return
+		}
+		found = append(found, election{Topic: p.Topic, Partition: p.Partition, Leader: p.Leader, Preferred: preferred, Result: electionPending})
+	})
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Topic != found[j].Topic {
+			return found[i].Topic < found[j].Topic
+		}
+		return found[i].Partition < found[j].Partition
+	})
+	return found
+}
+
+// electLeaders runs a preferred leader election of the partitions and records
+// the result of each
+func electLeaders(ctx context.Context, adm adminClient, partitions elections) error {
+	set := make(kadm.TopicsSet)
+	for _, p := range partitions {
+		if set[p.Topic] == nil {
+			set[p.Topic] = make(map[int32]struct{})
+		}
+		set[p.Topic][p.Partition] = struct{}{}
+	}
+	results, err := adm.ElectLeaders(ctx, kadm.ElectPreferredReplica, set)
+	if err != nil {
+		return kafkaError("failed to elect preferred leaders", err)
+	}
+
+	failed := 0
+	for i := range partitions {
+		p := &partitions[i]
+		r, ok := results[p.Topic][p.Partition]
+		switch {
+		case !ok:
+			p.Result, p.Error = electionFailed, "no result returned"
+		case r.Err == nil:
+			p.Result = electionElected
+		case errors.Is(r.Err, kerr.ElectionNotNeeded):
+			p.Result = electionNotNeeded
+		default:
+			p.Result, p.Error = electionFailed, r.Err.Error()
+		}
+		if p.Result == electionFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return cli.WithCode(cli.ExitFailed, fmt.Errorf("preferred leader election failed for %d of %d partitions", failed, len(partitions)))
+	}
+	return nil
+}
+
+// runElectLeaders hands leadership of the partitions of all topics, or of
+// those named on the command line, back to their preferred replicas. Only
+// partitions led by another replica are elected.
+func runElectLeaders(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var kafka cli.KafkaFlags
+	fs := newFlagSet("elect-leaders", &g)
+	kafka.RegisterFlags(fs, env)
+	dryRun := fs.Bool("dry-run", false, "print the partitions that would be elected without electing them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	adm, cleanup, err := connect(&kafka)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	ctx, cancel := g.requestContext(ctx)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx, fs.Args()...)
+	if err != nil {
+		return kafkaError("failed to read metadata", err)
+	}
+	for _, topic := range md.Topics {
+		if errors.Is(topic.Err, kerr.UnknownTopicOrPartition) {
+			return cli.WithCode(cli.ExitNotFound, fmt.Errorf("topic %s not found", topic.Topic))
+		}
+	}
+
+	partitions := imbalancedLeaders(md)
+	// An empty set would elect every partition of the cluster
+	if !*dryRun && len(partitions) > 0 {
+		err = electLeaders(ctx, adm, partitions)
+		if err != nil && cli.ExitCode(err) != cli.ExitFailed {
+			// Nothing to print when the election request itself failed
+			return err
+		}
+	}
+	if werr := cli.Write(w, g.Output, partitions); werr != nil {
+		return werr
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// adminClient defines the Kafka admin operations of the commands that talk to
+// Kafka directly. This enables mocking in tests.
+type adminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+	ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+	ListGroups(ctx context.Context, filterStates ...string) (kadm.ListedGroups, error)
+	DescribeGroups(ctx context.Context, groups ...string) (kadm.DescribedGroups, error)
+	FetchOffsets(ctx context.Context, group string) (kadm.OffsetResponses, error)
+	ListEndOffsets(ctx context.Context, topics ...string) (kadm.ListedOffsets, error)
+}
+
+// newAdminClient connects to Kafka. Replaced in tests.
+var newAdminClient = func(cfg kafkaclient.Config) (adminClient, func(), error) {
+	return kafkaclient.NewAdminClient(cfg)
+}
+
+// connect returns an admin client of the cluster the flags select
+func connect(f *cli.KafkaFlags) (adminClient, func(), error) {
+	cfg, err := f.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	adm, cleanup, err := newAdminClient(cfg)
+	if err != nil {
+		return nil, nil, cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to create kafka client: %w", err))
+	}
+	return adm, cleanup, nil
+}
+
+// kafkaError classifies a failed Kafka request as the cluster being unavailable
+func kafkaError(msg string, err error) error {
+	return cli.WithCode(cli.ExitUnavailable, fmt.Errorf("%s: %w", msg, err))
+}
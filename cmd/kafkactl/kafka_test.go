@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of adminClient for testing
+type MockAdminClient struct {
+	MetadataFunc       func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	ElectLeadersFunc   func(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+	ListGroupsFunc     func(ctx context.Context, filterStates ...string) (kadm.ListedGroups, error)
+	DescribeGroupsFunc func(ctx context.Context, groups ...string) (kadm.DescribedGroups, error)
+	FetchOffsetsFunc   func(ctx context.Context, group string) (kadm.OffsetResponses, error)
+	ListEndFunc        func(ctx context.Context, topics ...string) (kadm.ListedOffsets, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(context.Context, kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+	if m.ElectLeadersFunc != nil {
+		return m.ElectLeadersFunc(ctx, how, s)
+	}
+	return kadm.ElectLeadersResults{}, nil
+}
+
+func (m *MockAdminClient) ListGroups(ctx context.Context, filterStates ...string) (kadm.ListedGroups, error) {
+	if m.ListGroupsFunc != nil {
+		return m.ListGroupsFunc(ctx, filterStates...)
+	}
+	return kadm.ListedGroups{}, nil
+}
+
+func (m *MockAdminClient) DescribeGroups(ctx context.Context, groups ...string) (kadm.DescribedGroups, error) {
+	if m.DescribeGroupsFunc != nil {
+		return m.DescribeGroupsFunc(ctx, groups...)
+	}
+	return kadm.DescribedGroups{}, nil
+}
+
+func (m *MockAdminClient) FetchOffsets(ctx context.Context, group string) (kadm.OffsetResponses, error) {
+	if m.FetchOffsetsFunc != nil {
+		return m.FetchOffsetsFunc(ctx, group)
+	}
+	return kadm.OffsetResponses{}, nil
+}
+
+func (m *MockAdminClient) ListEndOffsets(ctx context.Context, topics ...string) (kadm.ListedOffsets, error) {
+	if m.ListEndFunc != nil {
+		return m.ListEndFunc(ctx, topics...)
+	}
+	return kadm.ListedOffsets{}, nil
+}
+
+// useAdminClient makes the commands connect to adm for the rest of the test
+func useAdminClient(t *testing.T, adm adminClient) {
+	previous := newAdminClient
+	newAdminClient = func(kafkaclient.Config) (adminClient, func(), error) {
+		return adm, func() {}, nil
+	}
+	t.Cleanup(func() { newAdminClient = previous })
+}
+
+func offset(topic string, partition int32, at int64) kadm.OffsetResponse {
+	return kadm.OffsetResponse{Offset: kadm.Offset{Topic: topic, Partition: partition, At: at}}
+}
+
+func TestLag(t *testing.T) {
+	useAdminClient(t, &MockAdminClient{
+		ListGroupsFunc: func(context.Context, ...string) (kadm.ListedGroups, error) {
+			return kadm.ListedGroups{
+				"payments": {Group: "payments", State: "Stable"},
+				"deleted":  {Group: "deleted", State: "Empty"},
+			}, nil
+		},
+		DescribeGroupsFunc: func(_ context.Context, groups ...string) (kadm.DescribedGroups, error) {
+			described := kadm.DescribedGroups{}
+			for _, g := range groups {
+				state := "Stable"
+				if g != "payments" {
+					state = "Dead"
+				}
+				described[g] = kadm.DescribedGroup{Group: g, State: state}
+			}
+			return described, nil
+		},
+		FetchOffsetsFunc: func(context.Context, string) (kadm.OffsetResponses, error) {
+			return kadm.OffsetResponses{
+				"orders": {
+					0: offset("orders", 0, 100),
+					1: offset("orders", 1, 42),
+					2: offset("orders", 2, -1),
+				},
+				"refunds": {0: offset("refunds", 0, 7)},
+			}, nil
+		},
+		ListEndFunc: func(context.Context, ...string) (kadm.ListedOffsets, error) {
+			return kadm.ListedOffsets{
+				"orders": {
+					0: {Topic: "orders", Partition: 0, Offset: 110},
+					1: {Topic: "orders", Partition: 1, Offset: 42},
+					2: {Topic: "orders", Partition: 2, Offset: 9},
+				},
+				"refunds": {0: {Topic: "refunds", Partition: 0, Err: kerr.UnknownTopicOrPartition}},
+			}, nil
+		},
+	})
+
+	var stdout bytes.Buffer
+	if err := run(context.Background(), discovery.MapEnv(nil), []string{"lag", "-o", "json"}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report lagReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid output %q: %v", stdout.String(), err)
+	}
+	// The deleted group is skipped, and so are the uncommitted orders-2 and
+	// the deleted refunds topic
+	if len(report.Groups) != 1 || report.Groups[0].Group != "payments" {
+		t.Fatalf("expected only the payments group, got %+v", report.Groups)
+	}
+	lag := report.Groups[0]
+	expected := []partitionLag{
+		{Topic: "orders", Partition: 0, Committed: 100, End: 110, Lag: 10},
+		{Topic: "orders", Partition: 1, Committed: 42, End: 42, Lag: 0},
+	}
+	if lag.Lag != 10 || len(lag.Partitions) != len(expected) {
+		t.Fatalf("expected a lag of 10 over %d partitions, got %+v", len(expected), lag)
+	}
+	for i, p := range expected {
+		if lag.Partitions[i] != p {
+			t.Errorf("partition %d: expected %+v, got %+v", i, p, lag.Partitions[i])
+		}
+	}
+
+	err := run(context.Background(), discovery.MapEnv(nil), []string{"lag", "deleted"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if code := cli.ExitCode(err); code != cli.ExitNotFound {
+		t.Errorf("expected exit code %d for a named dead group, got %d (%v)", cli.ExitNotFound, code, err)
+	}
+}
+
+func TestElectLeaders(t *testing.T) {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{
+		"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+			// Balanced
+			0: {Topic: "orders", Partition: 0, Leader: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+			// Led by broker 0 while broker 1 is preferred and in sync
+			1: {Topic: "orders", Partition: 1, Leader: 0, Replicas: []int32{1, 0}, ISR: []int32{0, 1}},
+			// Preferred broker 2 is out of sync and cannot take over
+			2: {Topic: "orders", Partition: 2, Leader: 0, Replicas: []int32{2, 0}, ISR: []int32{0}},
+		}},
+		"refunds": {Topic: "refunds", Partitions: kadm.PartitionDetails{
+			0: {Topic: "refunds", Partition: 0, Leader: 1, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+		}},
+	}}
+
+	var elected kadm.TopicsSet
+	useAdminClient(t, &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return md, nil
+		},
+		ElectLeadersFunc: func(_ context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+			if how != kadm.ElectPreferredReplica {
+				t.Errorf("expected a preferred replica election, got %v", how)
+			}
+			elected = s
+			return kadm.ElectLeadersResults{
+				"orders":  {1: {Topic: "orders", Partition: 1}},
+				"refunds": {0: {Topic: "refunds", Partition: 0, Err: kerr.PreferredLeaderNotAvailable}},
+			}, nil
+		},
+	})
+
+	var stdout bytes.Buffer
+	err := run(context.Background(), discovery.MapEnv(nil), []string{"elect-leaders", "-o", "json"}, &stdout, &bytes.Buffer{})
+	if code := cli.ExitCode(err); code != cli.ExitFailed {
+		t.Fatalf("expected exit code %d, got %d (%v)", cli.ExitFailed, code, err)
+	}
+	if len(elected) != 2 || len(elected["orders"]) != 1 || len(elected["refunds"]) != 1 {
+		t.Errorf("expected orders-1 and refunds-0 elected, got %v", elected)
+	}
+
+	var results elections
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("invalid output %q: %v", stdout.String(), err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 elections, got %+v", results)
+	}
+	if results[0].Topic != "orders" || results[0].Partition != 1 || results[0].Result != electionElected {
+		t.Errorf("expected orders-1 elected, got %+v", results[0])
+	}
+	if results[1].Topic != "refunds" || results[1].Result != electionFailed || results[1].Error == "" {
+		t.Errorf("expected the refunds-0 election failed, got %+v", results[1])
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// partitionLag is the lag of a consumer group on one partition
+type partitionLag struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Committed int64  `json:"committed"`
+	End       int64  `json:"end"`
+	Lag       int64  `json:"lag"`
+}
+
+// groupLag is the lag of a consumer group on the partitions it committed to
+type groupLag struct {
+	Group      string         `json:"group"`
+	State      string         `json:"state"`
+	Lag        int64          `json:"lag"`
+	Partitions []partitionLag `json:"partitions"`
+}
+
+// lagReport is what kafkactl lag prints
+type lagReport struct {
+	Groups []groupLag `json:"groups"`
+	// summary prints one row per group rather than per partition
+	summary bool
+}
+
+// Header implements cli.Table
+func (r *lagReport) Header() []string {
+	if r.summary {
+		return []string{"GROUP", "STATE", "PARTITIONS", "LAG"}
+	}
+	return []string{"GROUP", "STATE", "TOPIC", "PARTITION", "COMMITTED", "END", "LAG"}
+}
+
+// Rows implements cli.Table
+func (r *lagReport) Rows() [][]string {
+	var rows [][]string
+	for _, g := range r.Groups {
+		if r.summary {
+			rows = append(rows, []string{g.Group, g.State, strconv.Itoa(len(g.Partitions)), strconv.FormatInt(g.Lag, 10)})
+			continue
+		}
+		for _, p := range g.Partitions {
+			rows = append(rows, []string{
+				g.Group,
+				g.State,
+				p.Topic,
+				strconv.Itoa(int(p.Partition)),
+				strconv.FormatInt(p.Committed, 10),
+				strconv.FormatInt(p.End, 10),
+				strconv.FormatInt(p.Lag, 10),
+			})
+		}
+	}
+	return rows
+}
+
+// consumerGroups returns the named groups, or every group of the cluster
+// when none is named
+func consumerGroups(ctx context.Context, adm adminClient, named []string) ([]string, error) {
+	if len(named) > 0 {
+		return named, nil
+	}
+	listed, err := adm.ListGroups(ctx)
+	if err != nil {
+		return nil, kafkaError("failed to list consumer groups", err)
+	}
+	groups := make([]string, 0, len(listed))
+	for group := range listed {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// measureLag returns the lag of a consumer group: how far its committed
+// offsets are behind the end of every partition it committed to
+func measureLag(ctx context.Context, adm adminClient, group string, described kadm.DescribedGroup) (groupLag, error) {
+	lag := groupLag{Group: group, State: described.State, Partitions: []partitionLag{}}
+
+	fetched, err := adm.FetchOffsets(ctx, group)
+	if err != nil {
+		return groupLag{}, kafkaError("failed to fetch offsets of group "+group, err)
+	}
+	if err := fetched.Error(); err != nil {
+		return groupLag{}, kafkaError("failed to fetch offsets of group "+group, err)
+	}
+	committed := fetched.Sorted()
+	seen := map[string]bool{}
+	var topics []string
+	for _, o := range committed {
+		if o.At >= 0 && !seen[o.Topic] {
+			seen[o.Topic] = true
+			topics = append(topics, o.Topic)
+		}
+	}
+	if len(topics) == 0 {
+		return lag, nil
+	}
+
+	ends, err := adm.ListEndOffsets(ctx, topics...)
+	if err != nil {
+		return groupLag{}, kafkaError("failed to list end offsets", err)
+	}
+	for _, o := range committed {
+		if o.At < 0 {
+			continue
+		}
+		end, ok := ends[o.Topic][o.Partition]
+		// A partition deleted since the commit has no end to lag behind
+		if !ok || errors.Is(end.Err, kerr.UnknownTopicOrPartition) {
+			continue
+		}
+		if end.Err != nil {
+			return groupLag{}, kafkaError(fmt.Sprintf("failed to list end offset of %s-%d", o.Topic, o.Partition), end.Err)
+		}
+		p := partitionLag{Topic: o.Topic, Partition: o.Partition, Committed: o.At, End: end.Offset, Lag: max(end.Offset-o.At, 0)}
+		lag.Partitions = append(lag.Partitions, p)
+		lag.Lag += p.Lag
+	}
+	return lag, nil
+}
+
+// runLag prints the lag of the named consumer groups, or of every group
+func runLag(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var kafka cli.KafkaFlags
+	fs := newFlagSet("lag", &g)
+	kafka.RegisterFlags(fs, env)
+	summary := fs.Bool("summary", false, "print the total lag of every group instead of every partition")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	adm, cleanup, err := connect(&kafka)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	ctx, cancel := g.requestContext(ctx)
+	defer cancel()
+
+	groups, err := consumerGroups(ctx, adm, fs.Args())
+	if err != nil {
+		return err
+	}
+	report := &lagReport{Groups: []groupLag{}, summary: *summary}
+	if len(groups) == 0 {
+		return cli.Write(w, g.Output, report)
+	}
+
+	described, err := adm.DescribeGroups(ctx, groups...)
+	if err != nil {
+		return kafkaError("failed to describe consumer groups", err)
+	}
+	for _, group := range groups {
+		dg, ok := described[group]
+		// Kafka describes unknown groups as Dead rather than failing
+		if !ok || errors.Is(dg.Err, kerr.GroupIDNotFound) || dg.State == "Dead" {
+			// A listed group may be deleted before it is described
+			if fs.NArg() == 0 {
+				continue
+			}
+			return cli.WithCode(cli.ExitNotFound, fmt.Errorf("consumer group %s not found", group))
+		}
+		if dg.Err != nil {
+			return kafkaError("failed to describe group "+group, dg.Err)
+		}
+		lag, err := measureLag(ctx, adm, group, dg)
+		if err != nil {
+			return err
+		}
+		report.Groups = append(report.Groups, lag)
+	}
+	return cli.Write(w, g.Output, report)
+}
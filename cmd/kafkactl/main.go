@@ -0,0 +1,163 @@
+// kafkactl is the operator CLI of an orchestrated Kafka cluster. Workflows the
+// sidecar runs (drains, replication factor changes) go through the sidecar
+// API; reads and leader elections go to Kafka directly.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/about"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// command is a kafkactl subcommand
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, env discovery.Env, args []string, w io.Writer) error
+}
+
+var commands = []command{
+	{name: "status", summary: "Readiness of the broker behind every sidecar", run: runStatus},
+	{name: "topics", args: "[TOPIC...]", summary: "Partitions, replication factor and under-replicated partitions of topics", run: runTopics},
+	{name: "reassign", summary: "List partition reassignments, or change a topic's replication factor", run: runReassign},
+	{name: "drain", args: "BROKER_ID", summary: "Move every replica off a broker ahead of its removal", run: runDrain},
+	{name: "elect-leaders", args: "[TOPIC...]", summary: "Hand leadership back to the preferred replicas", run: runElectLeaders},
+	{name: "lag", args: "[GROUP...]", summary: "Consumer group lag by partition", run: runLag},
+	{name: "version", summary: "Print the version", run: runVersion},
+}
+
+// errUsage is returned for invalid flags, which the flag package has
+// already reported
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := run(ctx, discovery.OSEnv, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	if err != nil && !errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, "kafkactl:", err)
+	}
+	os.Exit(cli.ExitCode(err))
+}
+
+// run runs the command named by the first argument
+func run(ctx context.Context, env discovery.Env, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return cli.WithCode(cli.ExitUsage, errUsage)
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(ctx, env, args[1:], stdout)
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	usage(stderr)
+	return cli.WithCode(cli.ExitUsage, fmt.Errorf("unknown command %q", args[0]))
+}
+
+// usage lists the commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: kafkactl COMMAND [FLAGS] [ARGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'kafkactl COMMAND -h' for the flags of a command.")
+}
+
+// globals holds the flags every command accepts
+type globals struct {
+	cli.Options
+	// timeout bounds every sidecar and Kafka request
+	timeout time.Duration
+}
+
+// newFlagSet returns the flag set of a command, with the flags of globals
+func newFlagSet(name string, g *globals) *flag.FlagSet {
+	fs := flag.NewFlagSet("kafkactl "+name, flag.ContinueOnError)
+	g.RegisterFlags(fs)
+	fs.DurationVar(&g.timeout, "timeout", 30*time.Second, "timeout of every sidecar and Kafka request")
+	return fs
+}
+
+// requestContext bounds a request by --timeout
+func (g *globals) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, g.timeout)
+}
+
+// wait polls a long operation until it finishes when --wait is set
+func (g *globals) wait(ctx context.Context, poll func(ctx context.Context) (bool, error)) error {
+	if !g.Wait {
+		return nil
+	}
+	return cli.Wait(ctx, clock.Real, g.WaitInterval, g.WaitTimeout, poll)
+}
+
+// parseFlags parses the arguments of a command. Invalid flags are usage
+// errors; -h returns flag.ErrHelp.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return err
+	default:
+		return cli.WithCode(cli.ExitUsage, errUsage)
+	}
+}
+
+// usageErrorf returns a usage error with the message
+func usageErrorf(format string, args ...any) error {
+	return cli.WithCode(cli.ExitUsage, fmt.Errorf(format, args...))
+}
+
+// runVersion prints the version of kafkactl
+func runVersion(_ context.Context, _ discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	fs := newFlagSet("version", &g)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return cli.Write(w, g.Output, version(about.About))
+}
+
+// version is about.About printed as a table
+type version about.Ab
+
+// Header implements cli.Table
+func (v version) Header() []string {
+	return []string{"VERSION", "BUILD", "TIMESTAMP"}
+}
+
+// Rows implements cli.Table
+func (v version) Rows() [][]string {
+	return [][]string{{v.Version, v.Build, v.Timestamp}}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/decommission"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{args: nil, code: cli.ExitUsage},
+		{args: []string{"help"}, code: cli.ExitOK},
+		{args: []string{"rebalance"}, code: cli.ExitUsage},
+		{args: []string{"drain"}, code: cli.ExitUsage},
+		{args: []string{"drain", "-h"}, code: cli.ExitOK},
+		{args: []string{"drain", "--no-such-flag", "1"}, code: cli.ExitUsage},
+		{args: []string{"reassign", "--topic", "orders"}, code: cli.ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(context.Background(), discovery.MapEnv(nil), tt.args, &stdout, &stderr)
+			if code := cli.ExitCode(err); code != tt.code {
+				t.Errorf("expected exit code %d, got %d (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		code   int
+		msg    string
+	}{
+		{status: http.StatusNotFound, body: `{"code":404,"error":"topic orders not found"}`, code: cli.ExitNotFound, msg: "topic orders not found"},
+		{status: http.StatusConflict, body: `{"code":409,"error":"a decommission is in progress"}`, code: cli.ExitConflict, msg: "a decommission is in progress"},
+		{status: http.StatusUnauthorized, body: ``, code: cli.ExitUsage, msg: "Unauthorized"},
+		{status: http.StatusInternalServerError, body: `not json`, code: cli.ExitError, msg: "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		err := responseError(http.MethodPost, "/admin/decommission/3", tt.status, []byte(tt.body))
+		if code := cli.ExitCode(err); code != tt.code {
+			t.Errorf("%d: expected exit code %d, got %d", tt.status, tt.code, code)
+		}
+		if !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%d: expected %q in %q", tt.status, tt.msg, err)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ready := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/ready" {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, health.ReadinessResponse{Status: "ready", BrokerID: 0, BrokerRegistered: true})
+	}))
+	defer ready.Close()
+	unready := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, health.ReadinessResponse{Status: "not ready", BrokerID: 1, UnderReplicatedPartitions: 4, ErrorMessage: "4 under-replicated partitions"})
+	}))
+	defer unready.Close()
+
+	var stdout bytes.Buffer
+	env := discovery.MapEnv(map[string]string{"KAFKACTL_SIDECARS": ready.URL + "," + unready.URL})
+	err := run(context.Background(), env, []string{"status", "-o", "json"}, &stdout, &bytes.Buffer{})
+	if code := cli.ExitCode(err); code != cli.ExitUnavailable {
+		t.Fatalf("expected exit code %d, got %d (%v)", cli.ExitUnavailable, code, err)
+	}
+
+	var status clusterStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("invalid output %q: %v", stdout.String(), err)
+	}
+	if len(status) != 2 {
+		t.Fatalf("expected 2 brokers, got %+v", status)
+	}
+	if !status[0].Ready || status[0].Readiness.BrokerID != 0 {
+		t.Errorf("expected broker 0 ready, got %+v", status[0])
+	}
+	if status[1].Ready || status[1].Readiness == nil || status[1].Readiness.UnderReplicatedPartitions != 4 {
+		t.Errorf("expected broker 1 not ready with 4 URPs, got %+v", status[1])
+	}
+}
+
+func TestDrainWait(t *testing.T) {
+	tests := []struct {
+		name  string
+		final decommission.Status
+		code  int
+	}{
+		{name: "completed", final: decommission.Status{State: decommission.StateCompleted, BrokerID: 3, PlannedMoves: 2, CompletedMoves: 2}, code: cli.ExitOK},
+		{name: "failed", final: decommission.Status{State: decommission.StateFailed, BrokerID: 3, Message: "reassignment timed out"}, code: cli.ExitFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls atomic.Int32
+			var started decommission.StartRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					writeJSON(w, http.StatusUnauthorized, map[string]any{"code": 401, "error": "unauthorized"})
+					return
+				}
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v1/admin/decommission/3":
+					_ = json.NewDecoder(r.Body).Decode(&started)
+					writeJSON(w, http.StatusAccepted, decommission.StartResponse{Status: decommission.Status{State: decommission.StateMoving, BrokerID: 3, PlannedMoves: 2}})
+				case r.Method == http.MethodGet && r.URL.Path == "/v1/admin/decommission":
+					if polls.Add(1) < 3 {
+						writeJSON(w, http.StatusOK, decommission.Status{State: decommission.StateMoving, BrokerID: 3, PlannedMoves: 2, CompletedMoves: 1})
+						return
+					}
+					writeJSON(w, http.StatusOK, tt.final)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			var stdout bytes.Buffer
+			env := discovery.MapEnv(map[string]string{"KAFKACTL_SIDECARS": server.URL, "KAFKACTL_TOKEN": "secret", "USER": "ops"})
+			args := []string{"drain", "--wait", "--wait-interval", "1ms", "-o", "json", "3"}
+			err := run(context.Background(), env, args, &stdout, &bytes.Buffer{})
+			if code := cli.ExitCode(err); code != tt.code {
+				t.Fatalf("expected exit code %d, got %d (%v)", tt.code, code, err)
+			}
+			if started.RequestedBy != "ops" {
+				t.Errorf("expected the drain requested by ops, got %q", started.RequestedBy)
+			}
+			if polls.Load() != 3 {
+				t.Errorf("expected 3 polls, got %d", polls.Load())
+			}
+			var status decommission.Status
+			if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+				t.Fatalf("invalid output %q: %v", stdout.String(), err)
+			}
+			if status.State != tt.final.State {
+				t.Errorf("expected final state %s, got %s", tt.final.State, status.State)
+			}
+		})
+	}
+}
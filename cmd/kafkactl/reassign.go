@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/replication"
+)
+
+// moveHeader is the table header of planned moves
+var moveHeader = []string{"TOPIC", "PARTITION", "CURRENT", "TARGET"}
+
+// moveRows returns the table rows of planned moves
+func moveRows(moves []reassign.Move) [][]string {
+	rows := make([][]string, 0, len(moves))
+	for _, m := range moves {
+		rows = append(rows, []string{m.Topic, strconv.Itoa(int(m.Partition)), brokerList(m.Current), brokerList(m.Target)})
+	}
+	return rows
+}
+
+// brokerList formats broker IDs as a comma-separated list
+func brokerList(ids []int32) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+// reassignment is a partition being reassigned
+type reassignment struct {
+	Topic     string  `json:"topic"`
+	Partition int32   `json:"partition"`
+	Replicas  []int32 `json:"replicas"`
+	Adding    []int32 `json:"addingReplicas,omitempty"`
+	Removing  []int32 `json:"removingReplicas,omitempty"`
+}
+
+// reassignments is what kafkactl reassign prints without --topic
+type reassignments []reassignment
+
+// Header implements cli.Table
+func (r reassignments) Header() []string {
+	return []string{"TOPIC", "PARTITION", "REPLICAS", "ADDING", "REMOVING"}
+}
+
+// Rows implements cli.Table
+func (r reassignments) Rows() [][]string {
+	rows := make([][]string, 0, len(r))
+	for _, p := range r {
+		rows = append(rows, []string{p.Topic, strconv.Itoa(int(p.Partition)), brokerList(p.Replicas), brokerList(p.Adding), brokerList(p.Removing)})
+	}
+	return rows
+}
+
+// listReassignments returns the partitions being reassigned, sorted by topic
+// and partition
+func listReassignments(ctx context.Context, adm adminClient) (reassignments, error) {
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return nil, kafkaError("failed to read metadata", err)
+	}
+	listed, err := adm.ListPartitionReassignments(ctx, md.Topics.TopicsSet())
+	if err != nil {
+		return nil, kafkaError("failed to list partition reassignments", err)
+	}
+	list := reassignments{}
+	listed.Each(func(r kadm.ListPartitionReassignmentsResponse) {
+		if len(r.AddingReplicas) == 0 && len(r.RemovingReplicas) == 0 {
+			return
+		}
+		list = append(list, reassignment{
+			Topic:     r.Topic,
+			Partition: r.Partition,
+			Replicas:  r.Replicas,
+			Adding:    r.AddingReplicas,
+			Removing:  r.RemovingReplicas,
+		})
+	})
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Topic != list[j].Topic {
+			return list[i].Topic < list[j].Topic
+		}
+		return list[i].Partition < list[j].Partition
+	})
+	return list, nil
+}
+
+// rfPlan is replication.Plan printed as its moves
+type rfPlan replication.Plan
+
+// Header implements cli.Table
+func (p rfPlan) Header() []string {
+	return moveHeader
+}
+
+// Rows implements cli.Table
+func (p rfPlan) Rows() [][]string {
+	return moveRows(p.Moves)
+}
+
+// rfStatus is replication.Status printed as a table
+type rfStatus replication.Status
+
+// Header implements cli.Table
+func (s rfStatus) Header() []string {
+	return []string{"TOPIC", "REPLICATION FACTOR", "STATE", "MOVES", "MESSAGE"}
+}
+
+// Rows implements cli.Table
+func (s rfStatus) Rows() [][]string {
+	return [][]string{{
+		s.Topic,
+		strconv.Itoa(s.TargetReplicationFactor),
+		string(s.State),
+		fmt.Sprintf("%d/%d", s.CompletedMoves, s.PlannedMoves),
+		s.Message,
+	}}
+}
+
+// runReassign lists the partition reassignments in progress, read from Kafka.
+// With --topic and --replication-factor it changes the topic's replication
+// factor through the sidecar instead.
+func runReassign(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var sidecars sidecarFlags
+	var kafka cli.KafkaFlags
+	fs := newFlagSet("reassign", &g)
+	sidecars.register(fs, env)
+	kafka.RegisterFlags(fs, env)
+	topic := fs.String("topic", "", "topic whose replication factor to change")
+	rf := fs.Int("replication-factor", 0, "target replication factor of --topic")
+	throttle := fs.Int64("throttle", -1, "replication throttle in bytes/sec while replicas are added (0 unthrottled, default the sidecar's REPLICATION_FACTOR_THROTTLE)")
+	dryRun := fs.Bool("dry-run", false, "print the planned moves without changing the replication factor")
+	requestedBy := fs.String("requested-by", env.Get("USER"), "who requested the change, for the sidecar's log")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("usage: kafkactl reassign [FLAGS]")
+	}
+
+	if *topic == "" {
+		if *rf != 0 {
+			return usageErrorf("--replication-factor requires --topic")
+		}
+		return waitForReassignments(ctx, &g, &kafka, w)
+	}
+	if *rf <= 0 {
+		return usageErrorf("--topic requires a positive --replication-factor")
+	}
+	client, err := sidecars.client(g.timeout)
+	if err != nil {
+		return err
+	}
+	path := "/topics/" + url.PathEscape(*topic) + "/replication-factor"
+
+	if *dryRun {
+		var plan replication.Plan
+		if err := client.call(ctx, http.MethodGet, fmt.Sprintf("%s/plan?replicationFactor=%d", path, *rf), nil, &plan); err != nil {
+			return err
+		}
+		return cli.Write(w, g.Output, rfPlan(plan))
+	}
+
+	req := replication.StartRequest{ReplicationFactor: *rf, RequestedBy: *requestedBy}
+	if *throttle >= 0 {
+		req.ThrottleBytesPerSec = throttle
+	}
+	var started replication.StartResponse
+	if err := client.call(ctx, http.MethodPost, path, req, &started); err != nil {
+		return err
+	}
+
+	status := started.Status
+	err = g.wait(ctx, func(ctx context.Context) (bool, error) {
+		if err := client.call(ctx, http.MethodGet, "/topics/replication-factor", nil, &status); err != nil {
+			return false, err
+		}
+		switch status.State {
+		case replication.StateCompleted:
+			return true, nil
+		case replication.StateFailed:
+			return false, fmt.Errorf("%w: changing the replication factor of %s: %s", cli.ErrOperationFailed, *topic, status.Message)
+		}
+		return false, nil
+	})
+	if werr := cli.Write(w, g.Output, rfStatus(status)); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// waitForReassignments prints the partition reassignments in progress, and
+// with --wait polls until there are none left
+func waitForReassignments(ctx context.Context, g *globals, kafka *cli.KafkaFlags, w io.Writer) error {
+	adm, cleanup, err := connect(kafka)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	list := func(ctx context.Context) (reassignments, error) {
+		ctx, cancel := g.requestContext(ctx)
+		defer cancel()
+		return listReassignments(ctx, adm)
+	}
+	inProgress, err := list(ctx)
+	if err != nil {
+		return err
+	}
+	err = g.wait(ctx, func(ctx context.Context) (bool, error) {
+		listed, err := list(ctx)
+		if err != nil {
+			return false, err
+		}
+		inProgress = listed
+		return len(inProgress) == 0, nil
+	})
+	if werr := cli.Write(w, g.Output, inProgress); werr != nil {
+		return werr
+	}
+	return err
+}
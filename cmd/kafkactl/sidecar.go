@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// apiPrefix is the version prefix of the sidecar API
+const apiPrefix = "/v1"
+
+// defaultSidecar is the sidecar of the pod kafkactl runs in
+const defaultSidecar = "http://localhost:8080"
+
+// sidecarFlags select the sidecars and the bearer token for their admin routes
+type sidecarFlags struct {
+	urls      string
+	token     string
+	tokenFile string
+}
+
+// register adds the sidecar flags, defaulting to KAFKACTL_SIDECARS,
+// KAFKACTL_TOKEN and KAFKACTL_TOKEN_FILE
+func (f *sidecarFlags) register(fs *flag.FlagSet, env discovery.Env) {
+	urls := env.Get("KAFKACTL_SIDECARS")
+	if urls == "" {
+		urls = defaultSidecar
+	}
+	fs.StringVar(&f.urls, "sidecar", urls, "comma-separated sidecar URLs; drains and reassignments use the first")
+	fs.StringVar(&f.token, "token", env.Get("KAFKACTL_TOKEN"), "bearer token for the sidecar's admin routes")
+	fs.StringVar(&f.tokenFile, "token-file", env.Get("KAFKACTL_TOKEN_FILE"), "file holding the bearer token")
+}
+
+// clients returns a client of every sidecar, timing out requests after timeout
+func (f *sidecarFlags) clients(timeout time.Duration) ([]*sidecarClient, error) {
+	token := f.token
+	if f.tokenFile != "" {
+		data, err := os.ReadFile(f.tokenFile)
+		if err != nil {
+			return nil, cli.WithCode(cli.ExitUsage, fmt.Errorf("failed to read token file: %w", err))
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	var clients []*sidecarClient
+	for _, url := range strings.Split(f.urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			clients = append(clients, newSidecarClient(url, token, timeout))
+		}
+	}
+	if len(clients) == 0 {
+		return nil, usageErrorf("no sidecar URL given")
+	}
+	return clients, nil
+}
+
+// client returns a client of the first sidecar, which runs the workflows
+// kafkactl starts
+func (f *sidecarFlags) client(timeout time.Duration) (*sidecarClient, error) {
+	clients, err := f.clients(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return clients[0], nil
+}
+
+// sidecarClient calls the HTTP API of a sidecar
+type sidecarClient struct {
+	baseURL string
+	token   string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func newSidecarClient(baseURL, token string, timeout time.Duration) *sidecarClient {
+	return &sidecarClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		timeout: timeout,
+		client:  http.DefaultClient,
+	}
+}
+
+// send sends a request to the versioned API, with body encoded as JSON. The
+// response body is read in full before it is returned.
+func (c *sidecarClient) send(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+apiPrefix+path, reader)
+	if err != nil {
+		return 0, nil, cli.WithCode(cli.ExitUsage, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, cli.WithCode(cli.ExitUnavailable, fmt.Errorf("sidecar %s unreachable: %w", c.baseURL, err))
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to read response of sidecar %s: %w", c.baseURL, err))
+	}
+	return resp.StatusCode, data, nil
+}
+
+// call sends a request and decodes a successful response into out. A failed
+// response is returned as an error with the exit code of its status.
+func (c *sidecarClient) call(ctx context.Context, method, path string, body, out any) error {
+	status, data, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if status >= 400 {
+		return responseError(method, path, status, data)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// responseError returns the error of a failed response, with the message the
+// sidecar returned when there is one
+func responseError(method, path string, status int, data []byte) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	msg := http.StatusText(status)
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		msg = body.Error
+	}
+	return cli.WithCode(cli.StatusCode(status), fmt.Errorf("%s %s: %d %s", method, path, status, msg))
+}
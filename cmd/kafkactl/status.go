@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// brokerStatus is the readiness of the broker behind one sidecar
+type brokerStatus struct {
+	Sidecar   string                    `json:"sidecar"`
+	Ready     bool                      `json:"ready"`
+	Readiness *health.ReadinessResponse `json:"readiness,omitempty"`
+	// Error is set when the sidecar could not be asked
+	Error string `json:"error,omitempty"`
+}
+
+// clusterStatus is what kafkactl status prints
+type clusterStatus []brokerStatus
+
+// Header implements cli.Table
+func (s clusterStatus) Header() []string {
+	return []string{"SIDECAR", "BROKER", "STATUS", "REGISTERED", "URP", "MESSAGE"}
+}
+
+// Rows implements cli.Table
+func (s clusterStatus) Rows() [][]string {
+	rows := make([][]string, 0, len(s))
+	for _, b := range s {
+		if b.Readiness == nil {
+			rows = append(rows, []string{b.Sidecar, "", "unreachable", "", "", b.Error})
+			continue
+		}
+		r := b.Readiness
+		rows = append(rows, []string{
+			b.Sidecar,
+			strconv.Itoa(int(r.BrokerID)),
+			r.Status,
+			strconv.FormatBool(r.BrokerRegistered),
+			strconv.Itoa(r.UnderReplicatedPartitions),
+			r.ErrorMessage,
+		})
+	}
+	return rows
+}
+
+// readiness asks a sidecar for the readiness of its broker. An unready broker
+// is not an error: its sidecar answers 503 with the failed check.
+func readiness(ctx context.Context, client *sidecarClient) brokerStatus {
+	status := brokerStatus{Sidecar: client.baseURL}
+	code, data, err := client.send(ctx, http.MethodGet, "/health/ready", nil)
+	if err == nil && code != http.StatusOK && code != http.StatusServiceUnavailable {
+		err = responseError(http.MethodGet, "/health/ready", code, data)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	var r health.ReadinessResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		status.Error = fmt.Sprintf("invalid readiness response: %v", err)
+		return status
+	}
+	status.Ready, status.Readiness = code == http.StatusOK, &r
+	return status
+}
+
+// runStatus prints the readiness of the broker behind every sidecar. It fails
+// with ExitUnavailable when any broker is not ready.
+func runStatus(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var sidecars sidecarFlags
+	fs := newFlagSet("status", &g)
+	sidecars.register(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	clients, err := sidecars.clients(g.timeout)
+	if err != nil {
+		return err
+	}
+
+	status := make(clusterStatus, len(clients))
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status[i] = readiness(ctx, client)
+		}()
+	}
+	wg.Wait()
+
+	if err := cli.Write(w, g.Output, status); err != nil {
+		return err
+	}
+	unready := 0
+	for _, b := range status {
+		if !b.Ready {
+			unready++
+		}
+	}
+	if unready > 0 {
+		return cli.WithCode(cli.ExitUnavailable, fmt.Errorf("%d of %d brokers not ready", unready, len(status)))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// topicSummary is a topic as kafkactl topics lists it
+type topicSummary struct {
+	Topic             string `json:"topic"`
+	Partitions        int    `json:"partitions"`
+	ReplicationFactor int    `json:"replicationFactor"`
+	UnderReplicated   int    `json:"underReplicatedPartitions"`
+	Offline           int    `json:"offlinePartitions"`
+	Internal          bool   `json:"internal,omitempty"`
+}
+
+// topicList is what kafkactl topics prints
+type topicList []topicSummary
+
+// Header implements cli.Table
+func (l topicList) Header() []string {
+	return []string{"TOPIC", "PARTITIONS", "RF", "URP", "OFFLINE"}
+}
+
+// Rows implements cli.Table
+func (l topicList) Rows() [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, t := range l {
+		rows = append(rows, []string{
+			t.Topic,
+			strconv.Itoa(t.Partitions),
+			strconv.Itoa(t.ReplicationFactor),
+			strconv.Itoa(t.UnderReplicated),
+			strconv.Itoa(t.Offline),
+		})
+	}
+	return rows
+}
+
+// summarizeTopic counts the partitions of a topic. The replication factor is
+// that of its largest partition, which differs from the others only while a
+// replication factor change is under way.
+func summarizeTopic(topic kadm.TopicDetail) topicSummary {
+	summary := topicSummary{Topic: topic.Topic, Partitions: len(topic.Partitions), Internal: topic.IsInternal}
+	for _, p := range topic.Partitions {
+		summary.ReplicationFactor = max(summary.ReplicationFactor, len(p.Replicas))
+		switch {
+		case p.Leader < 0:
+			summary.Offline++
+		case len(p.ISR) < len(p.Replicas):
+			summary.UnderReplicated++
+		}
+	}
+	return summary
+}
+
+// runTopics lists topics from the cluster metadata: all of them, or those
+// named on the command line
+func runTopics(ctx context.Context, env discovery.Env, args []string, w io.Writer) error {
+	var g globals
+	var kafka cli.KafkaFlags
+	fs := newFlagSet("topics", &g)
+	kafka.RegisterFlags(fs, env)
+	internal := fs.Bool("internal", false, "include internal topics such as __consumer_offsets")
+	unhealthy := fs.Bool("under-replicated", false, "only list topics with under-replicated or offline partitions")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	adm, cleanup, err := connect(&kafka)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	ctx, cancel := g.requestContext(ctx)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx, fs.Args()...)
+	if err != nil {
+		return kafkaError("failed to read metadata", err)
+	}
+
+	list := topicList{}
+	for _, topic := range md.Topics {
+		switch {
+		case errors.Is(topic.Err, kerr.UnknownTopicOrPartition):
+			return cli.WithCode(cli.ExitNotFound, fmt.Errorf("topic %s not found", topic.Topic))
+		case topic.Err != nil:
+			return kafkaError("failed to describe topic "+topic.Topic, topic.Err)
+		case topic.IsInternal && !*internal && fs.NArg() == 0:
+			continue
+		}
+		summary := summarizeTopic(topic)
+		if *unhealthy && summary.UnderReplicated == 0 && summary.Offline == 0 {
+			continue
+		}
+		list = append(list, summary)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Topic < list[j].Topic })
+	return cli.Write(w, g.Output, list)
+}
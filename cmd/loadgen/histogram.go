@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// histogramGrowth is the ratio between the bounds of consecutive histogram
+// buckets, which bounds the error of a percentile to 1%
+const histogramGrowth = 1.01
+
+// histogram records latencies in logarithmic microsecond buckets, so a long
+// run at a high rate takes constant memory. It is safe for concurrent use.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []int64
+	count   int64
+	max     time.Duration
+}
+
+// bucket returns the index of the bucket of a latency: the smallest index
+// whose upper bound is at least the latency
+func bucket(d time.Duration) int {
+	us := float64(d) / float64(time.Microsecond)
+	if us <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log(us) / math.Log(histogramGrowth)))
+}
+
+// upperBound returns the largest latency of a bucket
+func upperBound(i int) time.Duration {
+	return time.Duration(math.Pow(histogramGrowth, float64(i)) * float64(time.Microsecond))
+}
+
+// record adds a latency
+func (h *histogram) record(d time.Duration) {
+	i := bucket(d)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i >= len(h.buckets) {
+		h.buckets = append(h.buckets, make([]int64, i+1-len(h.buckets))...)
+	}
+	h.buckets[i]++
+	h.count++
+	h.max = max(h.max, d)
+}
+
+// percentile returns the nearest-rank percentile (0 < q <= 1) of the recorded
+// latencies, or 0 when none was recorded
+func (h *histogram) percentile(q float64) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.count)))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			// The bound of the last bucket may exceed the largest latency in it
+			return min(upperBound(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// summary returns the percentiles of the recorded latencies
+func (h *histogram) summary() latencySummary {
+	h.mu.Lock()
+	count, longest := h.count, h.max
+	h.mu.Unlock()
+	return latencySummary{
+		Count: count,
+		P50:   millis(h.percentile(0.50)),
+		P95:   millis(h.percentile(0.95)),
+		P99:   millis(h.percentile(0.99)),
+		P999:  millis(h.percentile(0.999)),
+		Max:   millis(longest),
+	}
+}
+
+// millis converts a latency to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
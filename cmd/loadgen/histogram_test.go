@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramPercentiles(t *testing.T) {
+	var h histogram
+	if h.percentile(0.99) != 0 {
+		t.Errorf("expected 0 from an empty histogram, got %s", h.percentile(0.99))
+	}
+	// 1ms to 1000ms in 1ms steps: the nearest-rank pN is N*10ms
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0.50, want: 500 * time.Millisecond},
+		{q: 0.95, want: 950 * time.Millisecond},
+		{q: 0.99, want: 990 * time.Millisecond},
+		{q: 1, want: time.Second},
+	}
+	for _, tt := range tests {
+		got := h.percentile(tt.q)
+		if math.Abs(float64(got-tt.want)) > 0.01*float64(tt.want) {
+			t.Errorf("p%v: expected %s within 1%%, got %s", tt.q*100, tt.want, got)
+		}
+	}
+
+	summary := h.summary()
+	if summary.Count != 1000 || summary.Max != 1000 {
+		t.Errorf("expected 1000 latencies up to 1000ms, got %+v", summary)
+	}
+}
+
+func TestHistogramSubMicrosecond(t *testing.T) {
+	var h histogram
+	h.record(0)
+	h.record(500 * time.Nanosecond)
+	if got := h.percentile(1); got != 500*time.Nanosecond {
+		t.Errorf("expected the max to bound the first bucket, got %s", got)
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// Headers of the records loadgen produces. The run header tells the records
+// of this run from those of earlier runs and other producers of the topic.
+const (
+	runHeader  = "loadgen-run"
+	sentHeader = "loadgen-sent"
+)
+
+// options are the load settings
+type options struct {
+	topic             string
+	partitions        int
+	replicationFactor int
+	rate              int
+	size              int
+	acks              string
+	duration          time.Duration
+	drainTimeout      time.Duration
+	reportInterval    time.Duration
+	consume           bool
+}
+
+// parseAcks returns the acks a produce request waits for
+func parseAcks(acks string) (kgo.Acks, error) {
+	switch strings.ToLower(acks) {
+	case "all", "-1":
+		return kgo.AllISRAcks(), nil
+	case "leader", "1":
+		return kgo.LeaderAck(), nil
+	case "none", "0":
+		return kgo.NoAck(), nil
+	default:
+		return kgo.Acks{}, fmt.Errorf("invalid acks %q: expected all, leader or none", acks)
+	}
+}
+
+// generator produces records at the target rate and consumes them back,
+// recording produce and end-to-end latencies
+type generator struct {
+	cfg      kafkaclient.Config
+	opts     options
+	progress io.Writer
+	runID    []byte
+	payload  []byte
+
+	produced atomic.Int64
+	failed   atomic.Int64
+	consumed atomic.Int64
+
+	produceLatency  histogram
+	endToEndLatency histogram
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+func newGenerator(cfg kafkaclient.Config, opts options, progress io.Writer) *generator {
+	payload := make([]byte, opts.size)
+	// Random bytes keep compression from flattering the throughput
+	_, _ = rand.Read(payload)
+	return &generator{
+		cfg:      cfg,
+		opts:     opts,
+		progress: progress,
+		runID:    []byte(fmt.Sprintf("%d", time.Now().UnixNano())),
+		payload:  payload,
+	}
+}
+
+// run generates load for the configured duration and returns its report
+func (g *generator) run(ctx context.Context) (*report, error) {
+	acks, err := parseAcks(g.opts.acks)
+	if err != nil {
+		return nil, cli.WithCode(cli.ExitUsage, err)
+	}
+	producerOpts := []kgo.Opt{kgo.DefaultProduceTopic(g.opts.topic), kgo.RequiredAcks(acks)}
+	// Idempotent writes require acks from all in-sync replicas
+	if acks != kgo.AllISRAcks() {
+		producerOpts = append(producerOpts, kgo.DisableIdempotentWrite())
+	}
+	producer, closeProducer, err := kafkaclient.NewClient(g.cfg, producerOpts...)
+	if err != nil {
+		return nil, cli.WithCode(cli.ExitUnavailable, err)
+	}
+	defer closeProducer()
+
+	adm := kadm.NewClient(producer)
+	if err := g.ensureTopic(ctx, adm); err != nil {
+		return nil, err
+	}
+
+	var consumerDone chan struct{}
+	stopConsumer := func() {}
+	if g.opts.consume {
+		// Consuming from the end offsets listed before producing starts counts
+		// every record of the run, however long the consumer takes to join
+		ends, err := adm.ListEndOffsets(ctx, g.opts.topic)
+		if err == nil {
+			err = ends.Error()
+		}
+		if err != nil {
+			return nil, cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to list end offsets of %s: %w", g.opts.topic, err))
+		}
+		consumer, closeConsumer, err := kafkaclient.NewClient(g.cfg, kgo.ConsumePartitions(ends.KOffsets()))
+		if err != nil {
+			return nil, cli.WithCode(cli.ExitUnavailable, err)
+		}
+		defer closeConsumer()
+		consumeCtx, cancel := context.WithCancel(ctx)
+		stopConsumer = cancel
+		consumerDone = make(chan struct{})
+		go func() {
+			defer close(consumerDone)
+			g.consume(consumeCtx, consumer)
+		}()
+	}
+	defer stopConsumer()
+
+	start := time.Now()
+	stopProgress := g.reportProgress(ctx, start)
+	g.produce(ctx, producer, start)
+
+	// Records still buffered are part of the run; a cancelled run still
+	// reports what it produced
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.opts.drainTimeout)
+	defer cancel()
+	if err := producer.Flush(flushCtx); err != nil {
+		g.fail(fmt.Errorf("records still buffered after %s: %w", g.opts.drainTimeout, err))
+	}
+	elapsed := time.Since(start)
+
+	if g.opts.consume {
+		g.awaitConsumed(flushCtx)
+		stopConsumer()
+		<-consumerDone
+	}
+	stopProgress()
+	return g.report(elapsed), nil
+}
+
+// ensureTopic checks the topic exists, creating it when --partitions is set
+func (g *generator) ensureTopic(ctx context.Context, adm *kadm.Client) error {
+	if g.opts.partitions > 0 {
+		_, err := adm.CreateTopic(ctx, int32(g.opts.partitions), int16(g.opts.replicationFactor), nil, g.opts.topic)
+		if err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+			return cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to create topic %s: %w", g.opts.topic, err))
+		}
+	}
+	md, err := adm.Metadata(ctx, g.opts.topic)
+	if err != nil {
+		return cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to read metadata: %w", err))
+	}
+	topic := md.Topics[g.opts.topic]
+	switch {
+	case errors.Is(topic.Err, kerr.UnknownTopicOrPartition):
+		return cli.WithCode(cli.ExitNotFound, fmt.Errorf("topic %s not found (set --partitions to create it)", g.opts.topic))
+	case topic.Err != nil:
+		return cli.WithCode(cli.ExitUnavailable, fmt.Errorf("failed to describe topic %s: %w", g.opts.topic, topic.Err))
+	}
+	return nil
+}
+
+// produce produces records until the duration elapses or ctx is cancelled,
+// pacing them to the target rate when there is one. Without a rate the
+// producer's buffer limit is the only back pressure. Records already buffered
+// when ctx is cancelled are still sent, to be flushed.
+func (g *generator) produce(ctx context.Context, producer *kgo.Client, start time.Time) {
+	deadline := start.Add(g.opts.duration)
+	produceCtx := context.WithoutCancel(ctx)
+	for n := int64(0); ; n++ {
+		if g.opts.rate > 0 {
+			due := start.Add(time.Duration(n) * time.Second / time.Duration(g.opts.rate))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+		now := time.Now()
+		if ctx.Err() != nil || !now.Before(deadline) {
+			return
+		}
+		sent := make([]byte, 8)
+		binary.BigEndian.PutUint64(sent, uint64(now.UnixNano()))
+		record := &kgo.Record{
+			Value: g.payload,
+			Headers: []kgo.RecordHeader{
+				{Key: runHeader, Value: g.runID},
+				{Key: sentHeader, Value: sent},
+			},
+		}
+		producer.Produce(produceCtx, record, func(_ *kgo.Record, err error) {
+			if err != nil {
+				g.failed.Add(1)
+				g.fail(err)
+				return
+			}
+			g.produced.Add(1)
+			g.produceLatency.record(time.Since(now))
+		})
+	}
+}
+
+// consume reads the records of the run until ctx is cancelled
+func (g *generator) consume(ctx context.Context, consumer *kgo.Client) {
+	for {
+		fetches := consumer.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			g.fail(fmt.Errorf("failed to consume %s-%d: %w", topic, partition, err))
+		})
+		fetches.EachRecord(func(r *kgo.Record) {
+			if sent, ok := g.sentAt(r); ok {
+				g.consumed.Add(1)
+				g.endToEndLatency.record(time.Since(sent))
+			}
+		})
+	}
+}
+
+// sentAt returns when a record of this run was produced
+func (g *generator) sentAt(r *kgo.Record) (time.Time, bool) {
+	var run, sent []byte
+	for _, h := range r.Headers {
+		switch h.Key {
+		case runHeader:
+			run = h.Value
+		case sentHeader:
+			sent = h.Value
+		}
+	}
+	if !bytes.Equal(run, g.runID) || len(sent) != 8 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(sent))), true
+}
+
+// awaitConsumed waits until every acknowledged record is consumed back, or ctx
+// is done
+func (g *generator) awaitConsumed(ctx context.Context) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for g.consumed.Load() < g.produced.Load() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportProgress prints the counters every report interval until the
+// returned function is called
+func (g *generator) reportProgress(ctx context.Context, start time.Time) func() {
+	if g.opts.reportInterval <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(g.opts.reportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				elapsed := time.Since(start)
+				produced := g.produced.Load()
+				fmt.Fprintf(g.progress, "%s: produced %d (%.0f msg/s), failed %d, consumed %d, produce p99 %.2fms\n",
+					elapsed.Truncate(time.Second), produced, float64(produced)/elapsed.Seconds(),
+					g.failed.Load(), g.consumed.Load(), millis(g.produceLatency.percentile(0.99)))
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// fail records the last error of the run
+func (g *generator) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lastErr = err
+}
+
+// report summarizes the run
+func (g *generator) report(elapsed time.Duration) *report {
+	produced := g.produced.Load()
+	r := &report{
+		Topic:           g.opts.topic,
+		Acks:            g.opts.acks,
+		MessageSize:     g.opts.size,
+		TargetRate:      g.opts.rate,
+		DurationSeconds: elapsed.Seconds(),
+		Produced:        produced,
+		Failed:          g.failed.Load(),
+		MessagesPerSec:  float64(produced) / elapsed.Seconds(),
+		MBPerSec:        float64(produced) * float64(g.opts.size) / elapsed.Seconds() / 1e6,
+		ProduceLatency:  g.produceLatency.summary(),
+	}
+	if g.opts.consume {
+		endToEnd := g.endToEndLatency.summary()
+		r.EndToEndLatency = &endToEnd
+	}
+	g.mu.Lock()
+	if g.lastErr != nil {
+		r.LastError = g.lastErr.Error()
+	}
+	g.mu.Unlock()
+	return r
+}
@@ -0,0 +1,156 @@
+// loadgen produces records to a topic of an orchestrated Kafka cluster at a
+// target rate and consumes them back, reporting throughput and produce and
+// end-to-end latency percentiles. It validates the capacity of the cluster
+// after scaling or rebalancing.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := run(ctx, discovery.OSEnv, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+	}
+	os.Exit(cli.ExitCode(err))
+}
+
+// run parses the flags, generates the load and prints its report. Progress
+// goes to stderr so the report on stdout stays machine-readable.
+func run(ctx context.Context, env discovery.Env, args []string, stdout, stderr io.Writer) error {
+	var output cli.Options
+	var kafka cli.KafkaFlags
+	var opts options
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output.RegisterFlags(fs)
+	kafka.RegisterFlags(fs, env)
+	fs.StringVar(&opts.topic, "topic", "loadgen", "topic to produce to and consume from")
+	fs.IntVar(&opts.partitions, "partitions", 0, "create the topic with this many partitions when it does not exist (0 requires an existing topic)")
+	fs.IntVar(&opts.replicationFactor, "replication-factor", -1, "replication factor of a created topic (-1 for the broker default)")
+	fs.IntVar(&opts.rate, "rate", 1000, "target records per second (0 produces as fast as the cluster accepts)")
+	fs.IntVar(&opts.size, "size", 1024, "record value size in bytes")
+	fs.StringVar(&opts.acks, "acks", "all", "acks of produce requests: all, leader or none")
+	fs.DurationVar(&opts.duration, "duration", time.Minute, "how long to produce")
+	fs.DurationVar(&opts.drainTimeout, "drain-timeout", 30*time.Second, "how long to wait for buffered records to be acknowledged and consumed back after producing")
+	fs.DurationVar(&opts.reportInterval, "report-interval", 10*time.Second, "print progress to stderr this often (0 disables)")
+	fs.BoolVar(&opts.consume, "consume", true, "consume the records back to measure end-to-end latency")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return cli.WithCode(cli.ExitUsage, err)
+	}
+	switch {
+	case fs.NArg() > 0:
+		return cli.WithCode(cli.ExitUsage, fmt.Errorf("unexpected arguments: %v", fs.Args()))
+	case opts.rate < 0:
+		return cli.WithCode(cli.ExitUsage, fmt.Errorf("--rate must not be negative"))
+	case opts.size < 0:
+		return cli.WithCode(cli.ExitUsage, fmt.Errorf("--size must not be negative"))
+	case opts.duration <= 0:
+		return cli.WithCode(cli.ExitUsage, fmt.Errorf("--duration must be positive"))
+	}
+	if _, err := parseAcks(opts.acks); err != nil {
+		return cli.WithCode(cli.ExitUsage, err)
+	}
+
+	cfg, err := kafka.Config()
+	if err != nil {
+		return err
+	}
+	report, err := newGenerator(cfg, opts, stderr).run(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cli.Write(stdout, output.Output, report); err != nil {
+		return err
+	}
+	return report.err()
+}
+
+// latencySummary is the distribution of a latency, in milliseconds
+type latencySummary struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50Ms"`
+	P95   float64 `json:"p95Ms"`
+	P99   float64 `json:"p99Ms"`
+	P999  float64 `json:"p999Ms"`
+	Max   float64 `json:"maxMs"`
+}
+
+// report is what loadgen prints
+type report struct {
+	Topic           string          `json:"topic"`
+	Acks            string          `json:"acks"`
+	MessageSize     int             `json:"messageSize"`
+	TargetRate      int             `json:"targetRate"`
+	DurationSeconds float64         `json:"durationSeconds"`
+	Produced        int64           `json:"produced"`
+	Failed          int64           `json:"failed"`
+	MessagesPerSec  float64         `json:"messagesPerSec"`
+	MBPerSec        float64         `json:"mbPerSec"`
+	ProduceLatency  latencySummary  `json:"produceLatency"`
+	EndToEndLatency *latencySummary `json:"endToEndLatency,omitempty"`
+	LastError       string          `json:"lastError,omitempty"`
+}
+
+// Header implements cli.Table
+func (r *report) Header() []string {
+	return []string{"LATENCY", "COUNT", "FAILED", "MSG/S", "MB/S", "P50 MS", "P95 MS", "P99 MS", "P99.9 MS", "MAX MS"}
+}
+
+// Rows implements cli.Table
+func (r *report) Rows() [][]string {
+	row := func(name string, l latencySummary, failed string) []string {
+		rate := float64(l.Count) / r.DurationSeconds
+		return []string{
+			name,
+			strconv.FormatInt(l.Count, 10),
+			failed,
+			strconv.FormatFloat(rate, 'f', 0, 64),
+			strconv.FormatFloat(rate*float64(r.MessageSize)/1e6, 'f', 2, 64),
+			strconv.FormatFloat(l.P50, 'f', 2, 64),
+			strconv.FormatFloat(l.P95, 'f', 2, 64),
+			strconv.FormatFloat(l.P99, 'f', 2, 64),
+			strconv.FormatFloat(l.P999, 'f', 2, 64),
+			strconv.FormatFloat(l.Max, 'f', 2, 64),
+		}
+	}
+	rows := [][]string{row("produce", r.ProduceLatency, strconv.FormatInt(r.Failed, 10))}
+	if r.EndToEndLatency != nil {
+		rows = append(rows, row("end-to-end", *r.EndToEndLatency, ""))
+	}
+	return rows
+}
+
+// err fails a run that lost records: produce requests that failed, or
+// acknowledged records not consumed back within the drain timeout. Records
+// produced without acks may be lost silently, so they are not counted.
+func (r *report) err() error {
+	if r.Failed > 0 {
+		return cli.WithCode(cli.ExitFailed, fmt.Errorf("%d of %d records failed to produce: %s", r.Failed, r.Failed+r.Produced, r.LastError))
+	}
+	acks, _ := parseAcks(r.Acks)
+	if r.EndToEndLatency != nil && acks != kgo.NoAck() && r.EndToEndLatency.Count < r.Produced {
+		return cli.WithCode(cli.ExitFailed, fmt.Errorf("%d of %d acknowledged records not consumed back", r.Produced-r.EndToEndLatency.Count, r.Produced))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+func TestRunUsage(t *testing.T) {
+	tests := [][]string{
+		{"--acks", "2"},
+		{"--rate", "-1"},
+		{"--duration", "0s"},
+		{"--no-such-flag"},
+		{"extra"},
+	}
+	for _, args := range tests {
+		err := run(context.Background(), discovery.MapEnv(nil), args, &bytes.Buffer{}, &bytes.Buffer{})
+		if code := cli.ExitCode(err); code != cli.ExitUsage {
+			t.Errorf("%v: expected exit code %d, got %d (%v)", args, cli.ExitUsage, code, err)
+		}
+	}
+}
+
+func TestReportErr(t *testing.T) {
+	tests := []struct {
+		name   string
+		report report
+		code   int
+	}{
+		{
+			name:   "all consumed",
+			report: report{Acks: "all", Produced: 100, EndToEndLatency: &latencySummary{Count: 100}},
+			code:   cli.ExitOK,
+		},
+		{
+			name:   "produce failed",
+			report: report{Acks: "all", Produced: 99, Failed: 1, LastError: "NOT_ENOUGH_REPLICAS"},
+			code:   cli.ExitFailed,
+		},
+		{
+			name:   "acknowledged records lost",
+			report: report{Acks: "leader", Produced: 100, EndToEndLatency: &latencySummary{Count: 97}},
+			code:   cli.ExitFailed,
+		},
+		{
+			name:   "unacknowledged records lost",
+			report: report{Acks: "none", Produced: 100, EndToEndLatency: &latencySummary{Count: 97}},
+			code:   cli.ExitOK,
+		},
+		{
+			name:   "not consumed",
+			report: report{Acks: "all", Produced: 100},
+			code:   cli.ExitOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := cli.ExitCode(tt.report.err()); code != tt.code {
+				t.Errorf("expected exit code %d, got %d (%v)", tt.code, code, tt.report.err())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/alerting"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+// newAlerter returns the alerter notifying the configured Slack webhook and
+// PagerDuty service. Each condition is enabled when the component it watches is:
+// readiness on broker nodes, disks with DISK_USAGE_PATHS and certificates with
+// TLS_ENABLED.
+func newAlerter(healthChecker *health.Checker, certMonitor *certs.Monitor, logger *slog.Logger) *alerting.Alerter {
+	var sinks []alerting.Sink
+	if types.Config.AlertSlackWebhookURL != "" {
+		sinks = append(sinks, alerting.NewSlack(alertSender(types.Config.AlertSlackWebhookURL, logger)))
+	}
+	if types.Config.AlertPagerDutyRoutingKey != "" {
+		sinks = append(sinks, alerting.NewPagerDuty(alertSender(types.Config.AlertPagerDutyURL, logger), types.Config.AlertPagerDutyRoutingKey))
+	}
+
+	alerter := alerting.NewAlerter(types.Config.BrokerID, kafkaConfig(), alerting.Options{
+		Cluster:           types.Config.ClusterID,
+		Interval:          types.Config.AlertInterval,
+		UnreadyAfter:      types.Config.AlertBrokerUnreadyAfter,
+		OfflinePartitions: types.Config.AlertOfflinePartitions,
+		CertMinValidity:   types.Config.AlertCertMinValidity,
+		RepeatInterval:    types.Config.AlertRepeatInterval,
+		Template:          types.Config.AlertTemplate,
+		Timeout:           types.Config.CheckTimeout,
+	}, sinks, logger)
+
+	if types.Config.Profile().BrokerChecks {
+		alerter.SetReadiness(healthChecker)
+	}
+	// A collector of its own, so the alert threshold stays apart from readiness's
+	if paths := metrics.ParseDiskPaths(types.Config.DiskUsagePaths); len(paths) > 0 && types.Config.AlertDiskUsageRatio > 0 {
+		disks := metrics.NewDiskCollector(logger, paths, metrics.StatfsUsage)
+		disks.SetMaxUsageRatio(types.Config.AlertDiskUsageRatio)
+		alerter.SetDisks(disks)
+	}
+	if certMonitor != nil {
+		alerter.SetCerts(certMonitor)
+	}
+	return alerter
+}
+
+// alertSender delivers notifications to a sink's endpoint one at a time, so
+// none waits behind a batch
+func alertSender(url string, logger *slog.Logger) *webhook.Sender {
+	return webhook.NewSender(webhook.Options{
+		URL:      url,
+		Attempts: 3,
+		Backoff:  5 * time.Second,
+		Timeout:  types.Config.CheckTimeout,
+	}, logger)
+}
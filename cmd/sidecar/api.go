@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/gorilla/mux"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/about"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/alerting"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/audit"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/backup"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/connectivity"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/openapi"
+)
+
+// apiPrefix is the version prefix of the HTTP API
+const apiPrefix = "/v1"
+
+// openAPIPath serves the OpenAPI document, under apiPrefix like every route
+const openAPIPath = "/openapi.json"
+
+// routeSummaries describe the operations in the OpenAPI document, keyed by
+// method and path as registered on the router
+var routeSummaries = map[string]string{
+	"GET /health/live":                               "Liveness: the broker appears in cluster metadata",
+	"GET /health/ready":                              "Readiness: broker health, ISR status and log directories",
+	"GET /metrics":                                   "Prometheus metrics",
+	"GET /status":                                    "Last attempt and success of every check and background loop",
+	"GET /about":                                     "Version and build information",
+	"GET " + openAPIPath:                             "This OpenAPI document",
+	"GET /admin/config":                              "Effective configuration with secrets masked",
+	"POST " + reloadPath:                             "Reload the configuration",
+	"GET " + audit.Path:                              "Audit trail of admin API requests",
+	"GET " + alerting.Path:                           "Active Slack and PagerDuty alerts",
+	"GET " + backup.Path:                             "Last metadata backup to object storage",
+	"POST " + backup.Path:                            "Take a metadata backup now",
+	"GET /admin/state":                               "Safe mode status",
+	"POST /admin/state/reset":                        "Discard corrupted state and leave safe mode",
+	"GET /admin/onboarding":                          "New-broker onboarding progress",
+	"GET /admin/verification":                        "Post-restart verification state and report",
+	"GET /admin/canary":                              "Result of the last canary produce/consume probe",
+	"GET /admin/tls/certificates":                    "Served and client certificate expiry",
+	"GET /admin/peers":                               "Peer reachability matrix",
+	"GET " + connectivity.Path:                       "Probe every broker from every sidecar, with latency",
+	"GET /admin/decommission":                        "Progress of the running or last broker decommission",
+	"GET /admin/decommission/{brokerId:[0-9]+}/plan": "Plan for draining a broker",
+	"POST /admin/decommission/{brokerId:[0-9]+}":     "Start draining a broker",
+	"POST /admin/decommission/{brokerId:[0-9]+}/unregister": "Unregister a removed broker",
+	"GET /admin/decommission/min-isr":                       "Temporary min.insync.replicas adjustments",
+	"POST /admin/decommission/min-isr/restore":              "Restore original min.insync.replicas",
+	"GET /topics/replication-factor":                        "Progress of the running or last replication factor change",
+	"GET /topics/{name}/replication-factor/plan":            "Plan for changing a topic's replication factor",
+	"POST /topics/{name}/replication-factor":                "Start changing a topic's replication factor",
+	"GET /admin/standby":                                    "Warm standby state",
+	"POST /admin/standby/promote":                           "Promote the standby broker",
+	"GET /admin/scram":                                      "SCRAM credential reconciliation state",
+	"GET /admin/configs/drift":                              "Configs that differ from the desired spec",
+	"GET /catalog/topics":                                   "Search the topic catalog",
+	"GET /admin/maintenance/safety":                         "Maintenance safety score",
+	"GET /admin/tls/inter-broker":                           "TLS handshakes with the peers' inter-broker listeners",
+	"GET /rack":                                             "The broker's rack, for broker.rack",
+	"GET /listeners":                                        "Generated advertised listeners",
+	"GET /health/startup":                                   "Startup: every bootstrap host resolves",
+	"GET /kraft/metadata-log":                               "KRaft metadata log size and snapshots",
+	"GET /kraft/broker-epoch":                               "The broker's registration epoch and fencing",
+	"GET /kraft/voters":                                     "Generated KRaft controller quorum voters",
+	"GET /admin/consumer-groups/{group}/offsets/export":     "Export a consumer group's committed offsets",
+	"POST /admin/consumer-groups/{group}/offsets":           "Reset or restore a consumer group's offsets",
+	"GET /admin/quotas/recommendations":                     "Recommended quotas per principal",
+	"POST /admin/quotas/recommendations/apply":              "Apply recommended quotas",
+}
+
+// versioned serves the router under apiPrefix. Routes are registered at their
+// unversioned paths, which stay available as legacy aliases; versioned
+// requests are routed with the prefix stripped, so that middleware, route
+// templates and request logs see the same path for both.
+func versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, ok := strings.CutPrefix(r.URL.Path, apiPrefix)
+		if !ok || !strings.HasPrefix(path, "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = path
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, apiPrefix)
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// openAPIHandler serves the OpenAPI document of the routes enabled on router
+func openAPIHandler(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		doc, err := openapi.FromRouter(router,
+			openapi.Info{Title: "Kafka sidecar", Version: about.About.Version},
+			[]openapi.Server{{URL: apiPrefix}},
+			routeSummaries)
+		if err != nil {
+			_, _ = web.ReturnError(w, err)
+			return
+		}
+		_, _ = web.ReturnResponse(w, doc)
+	}
+}
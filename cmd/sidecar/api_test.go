@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestProbeOnly(t *testing.T) {
+	router := mux.NewRouter()
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/health/ready", ok).Methods("GET")
+	router.HandleFunc("/admin/decommission", ok).Methods("GET")
+	router.HandleFunc("/topics/{name}/replication-factor", ok).Methods("POST")
+	handler := versioned(probeOnly(router))
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/health/ready", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1/health/ready", status: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/decommission", status: http.StatusNotFound},
+		{method: http.MethodGet, path: "/v1/admin/decommission", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/topics/orders/replication-factor", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.status, w.Code)
+		}
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		tpl, _ := mux.CurrentRoute(r).GetPathTemplate()
+		_, _ = w.Write([]byte(tpl + " " + mux.Vars(r)["brokerId"]))
+	}).Methods("GET")
+	handler := versioned(router)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/v1/admin/decommission/3", status: http.StatusOK, body: "/admin/decommission/{brokerId:[0-9]+} 3"},
+		{path: "/admin/decommission/3", status: http.StatusOK, body: "/admin/decommission/{brokerId:[0-9]+} 3"},
+		{path: "/v1admin/decommission/3", status: http.StatusNotFound},
+		{path: "/v2/admin/decommission/3", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// newBootstrapWatcher returns the watcher that rebuilds the auto-built
+// bootstrap servers when the cluster is scaled, applying them with apply, or
+// nil when the bootstrap servers were set explicitly or refreshing is disabled
+func newBootstrapWatcher(apply func(servers []string), logger *slog.Logger) *discovery.Watcher {
+	if !types.Discovered("BootstrapServers") || types.Config.BootstrapRefreshInterval <= 0 {
+		return nil
+	}
+
+	topology := types.Config.Topology()
+	counter := discovery.DNSReplicaCounter(net.DefaultResolver.LookupHost, topology)
+	if types.Config.ReplicaCountFromAPI {
+		counter = discovery.APIReplicaCounter(http.DefaultClient, discovery.APIOptions{
+			Endpoint: types.Config.CplnEndpoint,
+			Token:    types.Config.CplnToken,
+		}, os.Getenv("CPLN_WORKLOAD"))
+	}
+
+	return discovery.NewWatcher(counter, discovery.WatchOptions{
+		Topology: topology,
+		Interval: types.Config.BootstrapRefreshInterval,
+	}, kafkaclient.ParseBootstrapServers(types.Config.BootstrapServers), apply, logger)
+}
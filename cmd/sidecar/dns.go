@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/dnsgate"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// newDNSGate returns the gate that waits for the bootstrap hosts to resolve
+func newDNSGate(logger *slog.Logger) *dnsgate.Gate {
+	return dnsgate.NewGate(kafkaclient.ParseBootstrapServers(types.Config.BootstrapServers), dnsgate.Options{
+		Interval: types.Config.DNSPreflightInterval,
+		Timeout:  types.Config.CheckTimeout,
+	}, logger)
+}
+
+// startupResponse is the startup check as served at /health/startup
+type startupResponse struct {
+	Status string `json:"status"`
+	// DNS is the result of every bootstrap host, with DNS_PREFLIGHT_ENABLED
+	DNS *dnsgate.Report `json:"dns,omitempty"`
+}
+
+// startupHandler handles GET /health/startup, for a startup probe that holds
+// the pod back until the broker can start: 503 until every bootstrap host
+// resolves with DNS_PREFLIGHT_ENABLED, and 200 from then on
+func (s *Server) startupHandler(w http.ResponseWriter, _ *http.Request) {
+	response := startupResponse{Status: "started"}
+	if s.dnsGate != nil {
+		report := s.dnsGate.Report()
+		response.DNS = &report
+		if !report.Open {
+			response.Status = "waiting-for-dns"
+			_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+			return
+		}
+	}
+	_, _ = web.ReturnResponse(w, response)
+}
+
+// runWaitForDNS waits until the host of every bootstrap server resolves and
+// exits, for running the sidecar as an init container ahead of the Kafka
+// container. It fails after DNS_PREFLIGHT_TIMEOUT.
+func runWaitForDNS() int {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := types.Initialize(logger); err != nil {
+		logger.Error("failed to initialize configuration", "error", err)
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), types.Config.DNSPreflightTimeout)
+	defer cancel()
+	gate := newDNSGate(logger)
+	if err := gate.Wait(ctx); err != nil {
+		logger.Error("dns: giving up", "timeout", types.Config.DNSPreflightTimeout, "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/listeners"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// writeEnvFiles writes the settings the Kafka container sources before it
+// starts: the rack to RACK_ENV_FILE, the quorum voters to KRAFT_VOTERS_FILE and
+// the advertised listeners to ADVERTISED_LISTENERS_FILE. They may name the same
+// file.
+func writeEnvFiles(logger *slog.Logger) error {
+	files := map[string]map[string]string{}
+	add := func(path, name, value string) {
+		if files[path] == nil {
+			files[path] = map[string]string{}
+		}
+		files[path][name] = value
+	}
+	if types.Config.RackEnvFile != "" {
+		add(types.Config.RackEnvFile, discovery.RackEnvVar, types.Config.Rack)
+	}
+	if types.Config.KRaftVotersFile != "" {
+		voters, err := types.Config.QuorumVoters()
+		if err != nil {
+			return fmt.Errorf("failed to generate the quorum voters: %w", err)
+		}
+		add(types.Config.KRaftVotersFile, discovery.VotersEnvVar, discovery.FormatVoters(voters))
+	}
+	if types.Config.AdvertisedListenersFile != "" {
+		advertised, err := types.Config.AdvertisedListeners()
+		if err != nil {
+			return fmt.Errorf("failed to generate the advertised listeners: %w", err)
+		}
+		add(types.Config.AdvertisedListenersFile, listeners.EnvVar, listeners.Format(advertised))
+	}
+
+	for path, vars := range files {
+		if err := discovery.WriteEnvFile(path, vars); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		logger.Info("wrote env file for the Kafka container", "path", path, "vars", vars)
+	}
+	return nil
+}
+
+// runInit writes the env files and exits, for running the sidecar as an init
+// container ahead of the Kafka container
+func runInit() int {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := types.Initialize(logger); err != nil {
+		logger.Error("failed to initialize configuration", "error", err)
+		return 1
+	}
+	if types.Config.RackEnvFile == "" && types.Config.KRaftVotersFile == "" && types.Config.AdvertisedListenersFile == "" {
+		logger.Error("--init requires RACK_ENV_FILE, KRAFT_VOTERS_FILE or ADVERTISED_LISTENERS_FILE")
+		return 1
+	}
+	if err := writeEnvFiles(logger); err != nil {
+		logger.Error("failed to write env files", "error", err)
+		return 1
+	}
+	return 0
+}
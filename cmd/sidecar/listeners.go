@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/listeners"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// listenersResponse is the broker's advertised listeners as served at /listeners
+type listenersResponse struct {
+	// AdvertisedListeners is the advertised.listeners value
+	AdvertisedListeners string               `json:"advertisedListeners"`
+	Listeners           []listeners.Listener `json:"listeners"`
+}
+
+// listenersHandler returns the broker's advertised.listeners, for a Kafka
+// container that templates its configuration from the sidecar rather than from
+// ADVERTISED_LISTENERS_FILE
+func (s *Server) listenersHandler(w http.ResponseWriter, _ *http.Request) {
+	advertised, err := types.Config.AdvertisedListeners()
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Internal("failed to generate the advertised listeners", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, listenersResponse{AdvertisedListeners: listeners.Format(advertised), Listeners: advertised})
+}
@@ -2,19 +2,46 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/controlplane-com/kafka-orchestrator/pkg/about"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requestlog"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
-	"github.com/controlplane-com/libs-go/pkg/config"
 )
 
 var logger *slog.Logger
 
 func main() {
+	validate := flag.Bool("validate-config", false, "validate the configuration, print a report and exit")
+	connect := flag.Bool("connect", false, "with --validate-config, also connect to Kafka")
+	output := cli.FormatTable
+	flag.Var(&output, "output", "with --validate-config, the report format: json, yaml or table")
+	initMode := flag.Bool("init", false, "write RACK_ENV_FILE, KRAFT_VOTERS_FILE and ADVERTISED_LISTENERS_FILE for the Kafka container and exit")
+	render := flag.Bool("render-config", false, "render server.properties to SERVER_PROPERTIES_FILE and exit")
+	initStorage := flag.Bool("init-storage", false, "check or write meta.properties in LOG_DIRS for CLUSTER_ID and exit")
+	waitForDNS := flag.Bool("wait-for-dns", false, "wait until every bootstrap host resolves, up to DNS_PREFLIGHT_TIMEOUT, and exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(runValidation(os.Stdout, *connect, output))
+	}
+	if *initMode {
+		os.Exit(runInit())
+	}
+	if *render {
+		os.Exit(runRender())
+	}
+	if *initStorage {
+		os.Exit(runInitStorage())
+	}
+	if *waitForDNS {
+		os.Exit(runWaitForDNS())
+	}
+
 	// Initialize logger with default level for startup
 	logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
@@ -25,17 +52,15 @@ func main() {
 		logger.Error("failed to initialize configuration", "error", err)
 		os.Exit(1)
 	}
-	logger.Info("configuration loaded", "config", config.Summarize(types.Config))
+	logger.Info("configuration loaded", "config", types.Config.Summary())
 
-	// Re-initialize logger with configured level
-	var level slog.Level
-	if err := level.UnmarshalText([]byte(types.Config.LogLevel)); err != nil {
-		logger.Error("invalid log level", "error", err)
-		os.Exit(1)
-	}
-	logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
+	// Re-initialize logger with configured level, which a reload can change.
+	// Validated in types.Initialize.
+	_ = logLevel.UnmarshalText([]byte(types.Config.LogLevel))
+	// Records logged with a request's context carry its request ID
+	logger = slog.New(requestlog.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel,
+	})))
 
 	logger.Info("starting kafka-sidecar",
 		"version", about.Version,
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// rackResponse is the broker's rack as served at /rack
+type rackResponse struct {
+	Rack string `json:"rack"`
+}
+
+// rackHandler returns the broker's rack, for a Kafka container that sets
+// broker.rack from the sidecar rather than from RACK_ENV_FILE
+func (s *Server) rackHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, rackResponse{Rack: types.Config.Rack})
+}
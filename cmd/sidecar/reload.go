@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// reloadPath reloads the configuration, like SIGHUP
+const reloadPath = "/admin/reload"
+
+// logLevel is the level of the sidecar's logger, which a reload can change
+var logLevel = new(slog.LevelVar)
+
+// saslCredentials are shared by every Kafka client the sidecar creates, so that
+// a reload rotates them for new connections
+var saslCredentials = &kafkaclient.Credentials{}
+
+// healthThresholds returns the reloadable settings of the health checker
+func healthThresholds() health.Thresholds {
+	// Validated in types.Initialize
+	urpFilter, _ := health.NewTopicFilter(types.Config.URPIncludeTopics, types.Config.URPExcludeTopics)
+	faults, _ := health.ParseFaults(types.Config.FaultInjection)
+	return health.Thresholds{
+		CheckTimeout:   types.Config.CheckTimeout,
+		MaxTimeout:     types.Config.CheckTimeoutMax,
+		FDMinFreeRatio: types.Config.FDMinFreeRatio,
+		FormationGrace: types.Config.FormationGrace,
+		URPFilter:      urpFilter,
+		Faults:         faults,
+	}
+}
+
+// applyConfig applies the reloadable settings of types.Config to the running sidecar
+func (s *Server) applyConfig() {
+	// Validated in types.Initialize
+	_ = logLevel.UnmarshalText([]byte(types.Config.LogLevel))
+	// Credentials kept in the secret manager stay in use
+	var values map[string]string
+	if s.secrets != nil {
+		values = s.secrets.Values()
+	}
+	setSASLCredentials(values)
+	s.healthChecker.SetThresholds(healthThresholds())
+}
+
+// reload re-reads the configuration and applies its reloadable settings. An
+// invalid configuration is rejected as a whole, keeping the current one.
+func (s *Server) reload(ctx context.Context, trigger string) (types.ReloadResult, error) {
+	result, err := types.Reload(s.logger)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "configuration reload failed, keeping the current configuration",
+			"trigger", trigger, "error", err)
+		return result, err
+	}
+	s.applyConfig()
+	s.logger.InfoContext(ctx, "configuration reloaded", "trigger", trigger, "applied", result.Applied)
+	if len(result.RestartRequired) > 0 {
+		s.logger.WarnContext(ctx, "changed settings take effect on the next restart", "settings", result.RestartRequired)
+	}
+	return result, nil
+}
+
+// reloadOnSignal reloads the configuration on every signal until ctx is done
+func (s *Server) reloadOnSignal(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			_, _ = s.reload(ctx, sig.String())
+		}
+	}
+}
+
+// reloadHandler handles POST /admin/reload requests
+func (s *Server) reloadHandler(w http.ResponseWriter, r *http.Request) {
+	result, err := s.reload(r.Context(), "api")
+	if err != nil {
+		_, _ = web.ReturnResponseWithCode(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		return
+	}
+	_, _ = web.ReturnResponse(w, result)
+}
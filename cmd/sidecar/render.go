@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/serverprops"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// runRender renders server.properties to SERVER_PROPERTIES_FILE and exits, for
+// running the sidecar as an init container ahead of the Kafka container
+func runRender() int {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := types.Initialize(logger); err != nil {
+		logger.Error("failed to initialize configuration", "error", err)
+		return 1
+	}
+	if types.Config.ServerPropertiesFile == "" {
+		logger.Error("--render-config requires SERVER_PROPERTIES_FILE")
+		return 1
+	}
+	rendered, err := types.Config.ServerProperties()
+	if err != nil {
+		logger.Error("failed to render server.properties", "error", err)
+		return 1
+	}
+	if err := serverprops.WriteFile(types.Config.ServerPropertiesFile, rendered); err != nil {
+		logger.Error("failed to write server.properties", "path", types.Config.ServerPropertiesFile, "error", err)
+		return 1
+	}
+	logger.Info("rendered server.properties", "path", types.Config.ServerPropertiesFile, "nodeId", types.Config.BrokerID)
+	return 0
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/secrets"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// Names the secrets fetched from the secret manager are applied under
+const (
+	secretSASLUsername = "SASL_USERNAME"
+	secretSASLPassword = "SASL_PASSWORD"
+	secretTLSCert      = "TLS_CERT"
+	secretTLSKey       = "TLS_KEY"
+)
+
+// newSecretRefresher returns the refresher of the secrets kept in the secret
+// manager, applying them with apply, or nil when no secret manager is configured
+func newSecretRefresher(apply func(values map[string]string) error, logger *slog.Logger) *secrets.Refresher {
+	if types.Config.SecretProvider == "" {
+		return nil
+	}
+	// Validated in types.Initialize
+	provider, _ := secrets.New(types.Config.SecretOptions())
+	refs := map[string]string{}
+	for name, ref := range map[string]string{
+		secretSASLUsername: types.Config.SecretSASLUsernameRef,
+		secretSASLPassword: types.Config.SecretSASLPasswordRef,
+		secretTLSCert:      types.Config.SecretTLSCertRef,
+		secretTLSKey:       types.Config.SecretTLSKeyRef,
+	} {
+		if ref != "" {
+			refs[name] = ref
+		}
+	}
+	return secrets.NewRefresher(provider, refs, apply, types.Config.SecretRefreshInterval, logger)
+}
+
+// applySecrets applies the secrets fetched from the secret manager: the SASL
+// credentials to new connections, and the client certificate to the files new
+// clients load it from
+func applySecrets(values map[string]string) error {
+	if cert, ok := values[secretTLSCert]; ok {
+		key := values[secretTLSKey]
+		// A certificate and key rotated out of step would break every new client
+		if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+			return fmt.Errorf("invalid client certificate: %w", err)
+		}
+		if err := writeSecretFile(types.Config.TLSKeyFile, key); err != nil {
+			return err
+		}
+		if err := writeSecretFile(types.Config.TLSCertFile, cert); err != nil {
+			return err
+		}
+	}
+	setSASLCredentials(values)
+	return nil
+}
+
+// setSASLCredentials sets the SASL credentials of new connections from the
+// configuration, replaced by those fetched from the secret manager
+func setSASLCredentials(values map[string]string) {
+	username, password := types.Config.SASLUsername, types.Config.SASLPassword
+	if value, ok := values[secretSASLUsername]; ok {
+		username = value
+	}
+	if value, ok := values[secretSASLPassword]; ok {
+		password = value
+	}
+	saslCredentials.Set(username, password)
+}
+
+// writeSecretFile replaces a file with content readable by the sidecar only.
+// The file is renamed into place, so readers never see it half written.
+func writeSecretFile(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,38 +1,140 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/controlplane-com/libs-go/pkg/config"
 	"github.com/controlplane-com/libs-go/pkg/web"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/controlplane-com/kafka-orchestrator/pkg/about"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/alerting"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/audit"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/auth"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/backup"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/canary"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/catalog"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/connectivity"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/cors"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/dashboard"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/decommission"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/dnsgate"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/drift"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/gclog"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/handshake"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/inflight"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/jolokia"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kraft"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/latency"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/logdirs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/maintenance"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/offsets"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/onboarding"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/oom"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/otlp"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/peers"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/quotas"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/replication"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requesterrors"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requestlog"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/safemode"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scheduler"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scram"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/secrets"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/servertls"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/standby"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/statsd"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/statusfile"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/verification"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+const (
+	// staleCheckInterval is how often check freshness is evaluated for stale alerts
+	staleCheckInterval = 30 * time.Second
+	// leakCheckInterval is how often Kafka clients are checked for leaks
+	leakCheckInterval = time.Minute
 )
 
 // Server represents the HTTP server for the sidecar
 type Server struct {
-	logger        *slog.Logger
-	healthChecker *health.Checker
-	httpServer    *http.Server
+	logger           *slog.Logger
+	healthChecker    *health.Checker
+	brokerProcess    *procfs.Process
+	tracker          *freshness.Tracker
+	quotaRecommender *quotas.Recommender
+	onboarder        *onboarding.Onboarder
+	decommissioner   *decommission.Decommissioner
+	rfChanger        *replication.Changer
+	standby          *standby.Standby
+	scramManager     *scram.Manager
+	driftDetector    *drift.Detector
+	offsetsManager   *offsets.Manager
+	verifier         *verification.Verifier
+	diskCollector    *metrics.DiskCollector
+	statusFile       *statusfile.Writer
+	auditLog         *audit.Log
+	inflight         *inflight.Tracker
+	webhook          *webhook.Sender
+	canary           *canary.Canary
+	latencyProber    *latency.Prober
+	handshakeProber  *handshake.Prober
+	interBrokerTLS   *handshake.InterBrokerChecker
+	peerChecker      *peers.Checker
+	connProber       *connectivity.Prober
+	partitionSizes   *logdirs.Sampler
+	certMonitor      *certs.Monitor
+	catalog          *catalog.Catalog
+	maintenance      *maintenance.Scorer
+	metadataLog      *kraft.Monitor
+	brokerEpoch      *kraft.EpochTracker
+	gcLog            *gclog.Tailer
+	oomPredictor     *oom.Predictor
+	alerter          *alerting.Alerter
+	backup           *backup.Exporter
+	requestErrors    *requesterrors.Sampler
+	safeMode         *safemode.Guard
+	secrets          *secrets.Refresher
+	bootstrap        *discovery.Watcher
+	dnsGate          *dnsgate.Gate
+	tracerProvider   *sdktrace.TracerProvider
+	httpServer       *http.Server
+	adminServer      *http.Server
 }
 
 // NewServer creates a new sidecar server
 func NewServer(logger *slog.Logger) *Server {
+	setSASLCredentials(nil)
 	saslConfig := health.SASLConfig{
-		Enabled:   types.Config.SASLEnabled,
-		Mechanism: types.Config.SASLMechanism,
-		Username:  types.Config.SASLUsername,
-		Password:  types.Config.SASLPassword,
+		Enabled:     types.Config.SASLEnabled,
+		Mechanism:   types.Config.SASLMechanism,
+		Username:    types.Config.SASLUsername,
+		Password:    types.Config.SASLPassword,
+		Credentials: saslCredentials,
 	}
 
 	healthChecker := health.NewChecker(
@@ -43,47 +145,914 @@ func NewServer(logger *slog.Logger) *Server {
 		logger,
 	)
 
-	return &Server{
+	brokerProcess := procfs.NewProcess(
+		procfs.NewFS(procfs.DefaultRoot),
+		types.Config.BrokerPIDFile,
+		types.Config.BrokerProcessMatch,
+	)
+	healthChecker.SetClusterOnly(!types.Config.Profile().BrokerChecks)
+	healthChecker.SetFDUsageReader(brokerProcess, types.Config.FDMinFreeRatio)
+	healthChecker.SetThresholds(healthThresholds())
+	healthChecker.SetTLS(kafkaConfig().TLS)
+	healthChecker.SetRetryPolicy(types.Config.KafkaRetryPolicy())
+
+	var dnsGate *dnsgate.Gate
+	if types.Config.DNSPreflightEnabled {
+		dnsGate = newDNSGate(logger)
+	}
+	applyBootstrapServers := func(servers []string) {
+		healthChecker.SetBootstrapServers(servers)
+		if dnsGate != nil {
+			dnsGate.SetServers(servers)
+		}
+	}
+
+	s := &Server{
 		logger:        logger,
 		healthChecker: healthChecker,
+		brokerProcess: brokerProcess,
+		tracker:       freshness.NewTracker(types.Config.CheckStaleAfter, logger),
+		inflight:      inflight.NewTracker(),
+		safeMode:      safemode.NewGuard(logger),
+		secrets:       newSecretRefresher(applySecrets, logger),
+		bootstrap:     newBootstrapWatcher(applyBootstrapServers, logger),
+		dnsGate:       dnsGate,
+	}
+	if s.bootstrap != nil {
+		s.bootstrap.SetTracker(s.tracker)
+	}
+
+	// The reconcilers share one pool of admin request slots
+	reconcileScheduler := scheduler.New(scheduler.Options{
+		Concurrency: types.Config.ReconcileConcurrency,
+		BatchSize:   types.Config.ReconcileBatchSize,
+		CycleBudget: types.Config.ReconcileCycleBudget,
+	})
+
+	if types.Config.QuotaRecommenderEnabled {
+		source := quotas.NewJolokiaUsageSource(jolokia.NewClient(types.Config.JolokiaURL, types.Config.CheckTimeout))
+		s.quotaRecommender = quotas.NewRecommender(source, kafkaConfig(), quotas.Options{
+			SampleInterval: types.Config.QuotaSampleInterval,
+			Window:         types.Config.QuotaSampleWindow,
+			HeadroomFactor: types.Config.QuotaHeadroomFactor,
+			MinByteRate:    types.Config.QuotaMinByteRate,
+			Timeout:        types.Config.CheckTimeout,
+		}, logger)
+		s.quotaRecommender.SetTracker(s.tracker)
+		s.quotaRecommender.SetScheduler(reconcileScheduler)
+	}
+
+	// Request errors are per broker; client nodes have none of their own
+	if types.Config.RequestErrorsEnabled && types.Config.Profile().BrokerChecks {
+		// Validated in types.Initialize
+		thresholds, _ := requesterrors.ParseThresholds(types.Config.RequestErrorsMaxRatios)
+		s.requestErrors = requesterrors.NewSampler(jolokia.NewClient(types.Config.JolokiaURL, types.Config.CheckTimeout), requesterrors.Options{
+			APIs:           requesterrors.ParseAPIs(types.Config.RequestErrorsAPIs),
+			Interval:       types.Config.RequestErrorsInterval,
+			Timeout:        types.Config.CheckTimeout,
+			MaxErrorRatio:  types.Config.RequestErrorsMaxRatio,
+			MaxErrorRatios: thresholds,
+		}, logger)
+		s.requestErrors.SetTracker(s.tracker)
+		healthChecker.SetRequestErrors(s.requestErrors)
+	}
+
+	if types.Config.OnboardingEnabled {
+		s.onboarder = onboarding.NewOnboarder(types.Config.BrokerID, kafkaConfig(), onboarding.Options{
+			CheckInterval:   types.Config.OnboardingCheckInterval,
+			BatchSize:       types.Config.OnboardingBatchSize,
+			PollInterval:    5 * time.Second,
+			Timeout:         types.Config.CheckTimeout,
+			IncludeInternal: types.Config.OnboardingIncludeInternal,
+		}, logger)
+		s.onboarder.SetTracker(s.tracker)
+	}
+
+	if types.Config.VerificationEnabled {
+		s.verifier = verification.NewVerifier(types.Config.BrokerID, kafkaConfig(), healthChecker, metrics.NewCgroupReader(logger), verification.Options{
+			CheckInterval:        10 * time.Second,
+			SettleDelay:          types.Config.VerificationSettleDelay,
+			CanaryTopic:          types.Config.VerificationCanaryTopic,
+			WebhookURL:           types.Config.VerificationWebhookURL,
+			ArtifactDir:          types.Config.VerificationArtifactDir,
+			BaselineInterval:     types.Config.VerificationBaselineInterval,
+			MinLeadershipPercent: types.Config.VerificationMinLeadershipPercent,
+			MaxBaselineRatio:     types.Config.VerificationMaxBaselineRatio,
+			PollInterval:         5 * time.Second,
+			Timeout:              types.Config.CheckTimeout,
+		}, logger)
+		s.verifier.SetTracker(s.tracker)
+		if types.Config.VerificationWebhookURL != "" {
+			s.webhook = webhook.NewSender(webhook.Options{
+				URL:            types.Config.VerificationWebhookURL,
+				Gzip:           types.Config.WebhookGzip,
+				MaxBatchEvents: types.Config.WebhookBatchMaxEvents,
+				MaxBatchBytes:  types.Config.WebhookBatchMaxBytes,
+				FlushInterval:  types.Config.WebhookBatchFlushInterval,
+				Attempts:       3,
+				Backoff:        5 * time.Second,
+				Timeout:        types.Config.CheckTimeout,
+			}, logger)
+			s.verifier.SetWebhook(s.webhook)
+		}
+	}
+
+	if types.Config.CanaryEnabled {
+		s.canary = canary.NewCanary(types.Config.BrokerID, kafkaConfig(), canary.Options{
+			Topic:             types.Config.CanaryTopic,
+			Interval:          types.Config.CanaryInterval,
+			ReplicationFactor: types.Config.CanaryReplicationFactor,
+			Timeout:           types.Config.CheckTimeout,
+			RebalanceGrace:    types.Config.CanaryRebalanceGrace,
+		}, logger)
+		s.canary.SetTracker(s.tracker)
+		s.canary.SetSafeMode(s.safeMode)
+		if types.Config.CanaryReadiness {
+			healthChecker.SetCanary(s.canary)
+		}
+	}
+
+	if types.Config.RequestLatencyEnabled {
+		s.latencyProber = latency.NewProber(types.Config.BrokerID, kafkaConfig(), latency.Options{
+			Interval: types.Config.RequestLatencyInterval,
+			Timeout:  types.Config.CheckTimeout,
+		}, logger)
+		s.latencyProber.SetTracker(s.tracker)
+	}
+
+	if types.Config.TLSHandshakeListeners != "" {
+		// Validated in types.Initialize
+		listeners, _ := handshake.ParseListeners(types.Config.TLSHandshakeListeners)
+		s.handshakeProber = handshake.NewProber(listeners, kafkaConfig().TLS, handshake.Options{
+			Interval: types.Config.TLSHandshakeInterval,
+			Timeout:  types.Config.CheckTimeout,
+		}, logger)
+		s.handshakeProber.SetTracker(s.tracker)
+	}
+
+	if types.Config.InterBrokerTLSCheckEnabled {
+		s.interBrokerTLS = handshake.NewInterBrokerChecker(types.Config.BrokerID, kafkaConfig(), handshake.InterBrokerOptions{
+			Interval: types.Config.InterBrokerTLSInterval,
+			Timeout:  types.Config.CheckTimeout,
+			Port:     types.Config.InterBrokerTLSPort,
+		}, logger)
+		s.interBrokerTLS.SetTracker(s.tracker)
+	}
+
+	if types.Config.PeerCheckEnabled {
+		s.peerChecker = peers.NewChecker(types.Config.BrokerID, kafkaConfig(), peers.Options{
+			Interval:    types.Config.PeerCheckInterval,
+			SidecarPort: types.Config.Port,
+			Timeout:     types.Config.CheckTimeout,
+			TLSConfig:   peerTLSConfig(logger),
+		}, logger)
+		s.peerChecker.SetTracker(s.tracker)
+	}
+
+	if types.Config.ConnectivityDiagnosticsEnabled {
+		s.connProber = connectivity.NewProber(types.Config.BrokerID, kafkaConfig(), connectivity.Options{
+			SidecarPort: types.Config.Port,
+			Timeout:     types.Config.CheckTimeout,
+			TLSConfig:   peerTLSConfig(logger),
+		}, logger)
+	}
+
+	if types.Config.StatusFilePath != "" {
+		s.statusFile = statusfile.NewWriter(types.Config.BrokerID, healthChecker, statusfile.Options{
+			Path:            types.Config.StatusFilePath,
+			Interval:        types.Config.StatusFileInterval,
+			RefreshInterval: types.Config.StatusFileRefreshInterval,
+			Timeout:         types.Config.CheckTimeout,
+		}, logger)
+		s.statusFile.SetTracker(s.tracker)
+	}
+
+	if types.Config.PartitionSizeMetricsEnabled {
+		s.partitionSizes = logdirs.NewSampler(types.Config.BrokerID, kafkaConfig(), logdirs.Options{
+			Interval:  types.Config.PartitionSizeInterval,
+			TopN:      types.Config.PartitionSizeTopN,
+			MaxSeries: types.Config.PartitionSizeMaxSeries,
+			Timeout:   types.Config.CheckTimeout,
+		}, logger)
+		s.partitionSizes.SetTracker(s.tracker)
+	}
+
+	if types.Config.TLSEnabled {
+		brokerAddress := types.Config.TLSExpiryBrokerAddress
+		if brokerAddress == "" && types.Config.Profile().BrokerChecks {
+			brokerAddress = net.JoinHostPort("localhost", strconv.Itoa(types.Config.KafkaPort))
+		}
+		s.certMonitor = certs.NewMonitor(certs.Options{
+			BrokerAddress:  brokerAddress,
+			ClientCertFile: types.Config.TLSCertFile,
+			Interval:       types.Config.TLSExpiryCheckInterval,
+			Timeout:        types.Config.CheckTimeout,
+			MinValidity:    types.Config.TLSExpiryMinValidity,
+		}, logger)
+		s.certMonitor.SetTracker(s.tracker)
+		if types.Config.TLSExpiryMinValidity > 0 {
+			healthChecker.SetCerts(s.certMonitor)
+		}
+	}
+
+	if (types.Config.ClusterID != "" || types.Config.ClusterIDFile != "") && types.Config.Profile().BrokerChecks {
+		// Validated in types.Initialize
+		clusterID, _ := kraft.NewClusterIDStore(types.Config.ClusterID, types.Config.ClusterIDFile)
+		healthChecker.SetClusterID(clusterID)
+	}
+
+	if types.Config.BrokerIDConflictCheckEnabled {
+		// Validated in types.Initialize
+		advertised, _ := types.Config.AdvertisedListeners()
+		hosts := make([]string, len(advertised))
+		for i, listener := range advertised {
+			hosts[i] = listener.Host
+		}
+		healthChecker.SetAdvertisedHosts(hosts)
+	}
+
+	if types.Config.BrokerEpochCheckEnabled {
+		s.brokerEpoch = kraft.NewEpochTracker(types.Config.BrokerID, kraft.EpochOptions{
+			Dir:      types.Config.KRaftMetadataLogDir,
+			Interval: types.Config.BrokerEpochCheckInterval,
+		}, logger)
+		s.brokerEpoch.SetTracker(s.tracker)
+		healthChecker.SetEpoch(s.brokerEpoch)
+	}
+
+	if paths := metrics.ParseDiskPaths(types.Config.DiskUsagePaths); len(paths) > 0 {
+		s.diskCollector = metrics.NewDiskCollector(logger, paths, metrics.StatfsUsage)
+		if types.Config.DiskReadinessMaxUsageRatio > 0 {
+			s.diskCollector.SetMaxUsageRatio(types.Config.DiskReadinessMaxUsageRatio)
+			healthChecker.SetDisks(s.diskCollector)
+		}
+	}
+
+	if types.Config.DecommissionEnabled {
+		// Validated in types.Initialize
+		policy, _ := decommission.ParseMinISRPolicy(types.Config.DecommissionMinISRPolicy)
+		s.decommissioner = decommission.NewDecommissioner(kafkaConfig(), decommission.Options{
+			BatchSize:    types.Config.DecommissionBatchSize,
+			PollInterval: 5 * time.Second,
+			Timeout:      types.Config.CheckTimeout,
+			MinISRPolicy: policy,
+		}, logger)
+		if types.Config.DecommissionStateFile != "" {
+			s.decommissioner.SetStateFile(types.Config.DecommissionStateFile)
+		}
+		if err := s.decommissioner.LoadState(); errors.Is(err, decommission.ErrCorruptState) {
+			s.safeMode.Enter("decommission", err, s.decommissioner.ResetState)
+		} else if err != nil {
+			logger.Error("failed to load decommission state", "error", err)
+		}
+	}
+
+	if types.Config.ReplicationFactorEnabled {
+		s.rfChanger = replication.NewChanger(kafkaConfig(), replication.Options{
+			BatchSize:    types.Config.ReplicationFactorBatchSize,
+			ThrottleRate: types.Config.ReplicationFactorThrottle,
+			PollInterval: 5 * time.Second,
+			Timeout:      types.Config.CheckTimeout,
+		}, logger)
+	}
+
+	if types.Config.Profile().Standby {
+		s.standby = standby.NewStandby(types.Config.BrokerID, kafkaConfig(), standby.Options{
+			CheckInterval: types.Config.StandbyCheckInterval,
+			BatchSize:     types.Config.StandbyBatchSize,
+			PollInterval:  5 * time.Second,
+			Timeout:       types.Config.CheckTimeout,
+		}, logger)
+		s.standby.SetTracker(s.tracker)
+		healthChecker.SetStandby(s.standby)
+	}
+
+	if types.Config.SCRAMCredentialsFile != "" {
+		s.scramManager = scram.NewManager(kafkaConfig(), scram.Options{
+			Path:           types.Config.SCRAMCredentialsFile,
+			CheckInterval:  types.Config.SCRAMCheckInterval,
+			Verify:         types.Config.SCRAMVerify,
+			VerifyTimeout:  types.Config.SCRAMVerifyTimeout,
+			VerifyInterval: 5 * time.Second,
+			Timeout:        types.Config.CheckTimeout,
+		}, logger)
+		s.scramManager.SetTracker(s.tracker)
+		s.scramManager.SetScheduler(reconcileScheduler)
+	}
+
+	if types.Config.ConfigDriftSpecFile != "" {
+		s.driftDetector = drift.NewDetector(kafkaConfig(), drift.Options{
+			Path:          types.Config.ConfigDriftSpecFile,
+			CheckInterval: types.Config.ConfigDriftCheckInterval,
+			Timeout:       types.Config.CheckTimeout,
+		}, logger)
+		s.driftDetector.SetTracker(s.tracker)
+	}
+
+	if types.Config.CatalogEnabled {
+		s.catalog = catalog.NewCatalog(kafkaConfig(), catalog.Options{
+			RefreshInterval: types.Config.CatalogRefreshInterval,
+			TagsFile:        types.Config.CatalogTagsFile,
+			Timeout:         types.Config.CheckTimeout,
+		}, logger)
+		s.catalog.SetTracker(s.tracker)
+	}
+
+	if types.Config.MaintenanceScoreEnabled {
+		s.maintenance = maintenance.NewScorer(kafkaConfig(), maintenance.Options{
+			Interval:               types.Config.MaintenanceScoreInterval,
+			ControllerStablePeriod: types.Config.MaintenanceControllerStablePeriod,
+			DiskMinFreeRatio:       types.Config.MaintenanceDiskMinFreeRatio,
+			SafeScore:              types.Config.MaintenanceSafeScore,
+			Timeout:                types.Config.CheckTimeout,
+		}, logger)
+		s.maintenance.SetTracker(s.tracker)
+	}
+
+	if types.Config.KRaftMetadataLogDir != "" {
+		s.metadataLog = kraft.NewMonitor(kraft.Options{
+			Dir:            types.Config.KRaftMetadataLogDir,
+			Interval:       types.Config.KRaftMetadataLogInterval,
+			MaxBytes:       types.Config.KRaftMetadataLogMaxBytes,
+			MaxSnapshotAge: types.Config.KRaftSnapshotMaxAge,
+		}, logger)
+		s.metadataLog.SetTracker(s.tracker)
+	}
+
+	if types.Config.Profile().Metrics && types.Config.OOMPredictionWindow > 0 {
+		s.oomPredictor = oom.NewPredictor(metrics.NewCgroupReader(logger), oom.Options{
+			Interval: types.Config.OOMPredictionInterval,
+			Window:   types.Config.OOMPredictionWindow,
+		}, logger)
+		s.oomPredictor.SetTracker(s.tracker)
+	}
+
+	if types.Config.GCLogPath != "" {
+		s.gcLog = gclog.NewTailer(gclog.Options{
+			Path:     types.Config.GCLogPath,
+			Interval: types.Config.GCLogInterval,
+		}, logger)
+		s.gcLog.SetTracker(s.tracker)
+	}
+
+	if types.Config.AlertingEnabled() {
+		s.alerter = newAlerter(healthChecker, s.certMonitor, logger)
+		s.alerter.SetTracker(s.tracker)
+	}
+
+	if types.Config.BackupBucket != "" {
+		// Validated in validateBackup
+		store, _ := backup.NewS3(types.Config.BackupS3Options(), &http.Client{})
+		s.backup = backup.NewExporter(types.Config.BrokerID, kafkaConfig(), store, backup.Options{
+			Prefix:   types.Config.BackupPrefix,
+			Interval: types.Config.BackupInterval,
+			Timeout:  types.Config.CheckTimeout,
+		}, logger)
+	}
+
+	if types.Config.OffsetsExportEnabled || types.Config.OffsetsResetEnabled {
+		s.offsetsManager = offsets.NewManager(kafkaConfig(), types.Config.CheckTimeout, logger)
+	}
+
+	return s
+}
+
+// kafkaConfig returns the Kafka connection settings from the sidecar configuration
+func kafkaConfig() kafkaclient.Config {
+	return kafkaclient.Config{
+		BootstrapServers: kafkaclient.ParseBootstrapServers(types.Config.BootstrapServers),
+		SASL: kafkaclient.SASLConfig{
+			Enabled:     types.Config.SASLEnabled,
+			Mechanism:   types.Config.SASLMechanism,
+			Username:    types.Config.SASLUsername,
+			Password:    types.Config.SASLPassword,
+			Credentials: saslCredentials,
+		},
+		TLS: kafkaclient.TLSConfig{
+			Enabled:  types.Config.TLSEnabled,
+			CertFile: types.Config.TLSCertFile,
+			KeyFile:  types.Config.TLSKeyFile,
+			CAFiles:  kafkaclient.ParseCAFiles(types.Config.TLSCAFiles),
+		},
+		Retry: types.Config.KafkaRetryPolicy(),
 	}
 }
 
 // Start starts the HTTP server
 func (s *Server) Start(ctx context.Context) error {
+	// Credentials kept in the secret manager are needed before anything connects
+	if s.secrets != nil {
+		if err := s.secrets.Step(ctx); err != nil {
+			return err
+		}
+		s.secrets.SetTracker(s.tracker)
+		if types.Config.SecretRefreshInterval > 0 {
+			go s.secrets.Run(ctx)
+		}
+		s.logger.Info("secrets: fetched from the secret manager", "provider", types.Config.SecretProvider)
+	}
+
+	// Settings the Kafka container sources before it starts, unless an init
+	// container wrote them already
+	if err := writeEnvFiles(s.logger); err != nil {
+		return err
+	}
+	if types.Config.StorageInitEnabled {
+		if err := prepareStorage(s.logger); err != nil {
+			return err
+		}
+	}
+
 	router := mux.NewRouter()
+	if types.Config.RequestLogEnabled {
+		// Validated in types.Initialize
+		sampler, _ := requestlog.ParseSampling(types.Config.RequestLogSampling)
+		router.Use(requestlog.Middleware(s.logger, sampler))
+	}
+	// The audit trail runs ahead of auth, so rejected requests are recorded too
+	if types.Config.AuditLogPath != "" {
+		auditLog, err := audit.Open(audit.Options{
+			Path:         types.Config.AuditLogPath,
+			MaxSize:      types.Config.AuditLogMaxSize,
+			MaxBackups:   types.Config.AuditLogMaxBackups,
+			MaxBodyBytes: types.Config.AuditLogMaxBodyBytes,
+			Reads:        types.Config.AuditLogReads,
+		}, s.logger)
+		if err != nil {
+			return err
+		}
+		s.auditLog = auditLog
+		router.Use(auditLog.Middleware(auth.Protected))
+		router.HandleFunc(audit.Path, auditLog.Handler).Methods("GET")
+	}
+	verifiers, err := authVerifiers(s.logger)
+	if err != nil {
+		return err
+	}
+	if len(verifiers) > 0 {
+		router.Use(auth.Middleware(verifiers, s.logger))
+	}
+	if types.Config.AdminClientCertRequired {
+		router.Use(servertls.RequireClientCert(auth.Protected))
+	}
+	router.Use(s.inflight.Middleware)
+	router.Use(s.safeMode.Middleware)
+
+	fmt.Println(types.Config.Summary())
+
+	// Tracing of the health probes, before they are served
+	if types.Config.OTLPTracesEndpoint != "" {
+		// Validated in types.Initialize
+		protocol, _ := otlp.ParseProtocol(types.Config.OTLPTracesProtocol)
+		headers, _ := otlp.ParseHeaders(types.Config.OTLPTracesHeaders)
+		location, _ := discovery.DiscoverLocation()
+		provider, err := otlp.NewTracerProvider(ctx, otlp.TraceOptions{
+			Endpoint:    types.Config.OTLPTracesEndpoint,
+			Protocol:    protocol,
+			Headers:     headers,
+			Insecure:    types.Config.OTLPTracesInsecure,
+			Timeout:     types.Config.CheckTimeout,
+			SampleRatio: types.Config.OTLPTracesSampleRatio,
+			Attributes: map[string]string{
+				"broker_id": strconv.Itoa(int(types.Config.BrokerID)),
+				"location":  location,
+			},
+		})
+		if err != nil {
+			s.logger.Error("failed to create OTLP trace exporter, tracing disabled", "endpoint", types.Config.OTLPTracesEndpoint, "error", err)
+		} else {
+			s.tracerProvider = provider
+			s.healthChecker.SetTracerProvider(provider)
+			s.logger.Info("otlp: exporting traces", "endpoint", types.Config.OTLPTracesEndpoint, "protocol", protocol)
+		}
+	}
 
-	fmt.Println(config.Summarize(types.Config))
+	// Health endpoints, served from cache beyond the concurrency cap
+	router.HandleFunc("/health/live", s.inflight.Shed("/health/live", types.Config.ProbeMaxConcurrency,
+		s.tracked("liveness", s.healthChecker.LivenessHandler))).Methods("GET")
+	router.HandleFunc("/health/ready", s.inflight.Shed("/health/ready", types.Config.ProbeMaxConcurrency,
+		s.tracked("readiness", s.healthChecker.ReadinessHandler))).Methods("GET")
+	router.HandleFunc("/health/startup", s.startupHandler).Methods("GET")
 
-	// Health endpoints
-	router.HandleFunc("/health/live", s.healthChecker.LivenessHandler).Methods("GET")
-	router.HandleFunc("/health/ready", s.healthChecker.ReadinessHandler).Methods("GET")
+	// Safe mode
+	router.HandleFunc("/admin/state", s.safeMode.StatusHandler).Methods("GET")
+	router.HandleFunc(safemode.ResetPath, s.safeMode.ResetHandler).Methods("POST")
+
+	// Check freshness
+	router.HandleFunc("/status", s.tracker.StatusHandler).Methods("GET")
+	if types.Config.CheckStaleAfter > 0 {
+		go s.tracker.Watch(ctx, staleCheckInterval)
+	}
+
+	// Status file for node agents
+	if s.statusFile != nil {
+		go s.statusFile.Run(ctx)
+	}
+
+	// Kafka client leak detection
+	if types.Config.ClientLeakThreshold > 0 {
+		go kafkaclient.NewLeakDetector(kafkaclient.Clients, types.Config.ClientLeakThreshold, s.logger).Run(ctx, leakCheckInterval)
+	}
 
 	// Metrics endpoint
-	metricsCollector := metrics.NewCollector(s.logger)
-	if err := metricsCollector.Register(); err != nil {
-		s.logger.Warn("failed to register metrics collector", "error", err)
+	if types.Config.Profile().Metrics {
+		// Validated in types.Initialize
+		disabled, _ := metrics.ParseCollectorNames(types.Config.MetricsDisabledCollectors)
+		register := func(name string, collector prometheus.Collector) bool {
+			if disabled[name] {
+				s.logger.Info("metrics collector disabled", "collector", name)
+				return false
+			}
+			if err := prometheus.Register(metrics.Instrument(name, collector)); err != nil {
+				s.logger.Warn("failed to register metrics collector", "collector", name, "error", err)
+				return false
+			}
+			return true
+		}
+
+		register("memory", metrics.NewCollector(s.logger))
+		register("safe_mode", metrics.NewSafeModeCollector(s.safeMode))
+		if s.oomPredictor != nil {
+			register("oom_prediction", metrics.NewOOMPredictionCollector(s.oomPredictor))
+		}
+		register("network", metrics.NewNetworkCollector(s.logger, procfs.NewFS(procfs.DefaultRoot)))
+		fdCollector := metrics.NewFDCollector(s.logger, s.brokerProcess)
+		fdCollector.SetSidecarReader(procfs.NewSelf(procfs.NewFS(procfs.DefaultRoot)))
+		register("fd", fdCollector)
+		if types.Config.ProcessMetricsEnabled {
+			register("process", metrics.NewProcessCollector(s.logger, s.brokerProcess))
+		}
+		if s.diskCollector != nil {
+			register("disk", s.diskCollector)
+		}
+		if types.Config.Profile().BrokerChecks {
+			register("urp", metrics.NewURPCollector(s.healthChecker))
+		}
+		register("health", metrics.NewHealthCollector(s.healthChecker))
+		if types.Config.JMXMetricsEnabled {
+			register("jmx", metrics.NewJMXCollector(jolokia.NewClient(types.Config.JolokiaURL, types.Config.CheckTimeout), types.Config.CheckTimeout, s.logger))
+		}
+		if s.requestErrors != nil {
+			register("request_errors", metrics.NewRequestErrorsCollector(s.requestErrors))
+		}
+		register("clients", metrics.NewClientCollector(kafkaclient.Clients, types.Config.ClientLeakThreshold))
+		register("checks", metrics.NewCheckCollector(s.tracker))
+		register("inflight", metrics.NewInFlightCollector(s.inflight))
+		probeCollector := metrics.NewProbeCollector()
+		if register("probes", probeCollector) {
+			s.healthChecker.SetProbeObserver(probeCollector)
+		}
+		if s.canary != nil {
+			canaryCollector := metrics.NewCanaryCollector(s.canary)
+			if register("canary", canaryCollector) {
+				s.canary.SetObserver(canaryCollector)
+			}
+		}
+		if s.latencyProber != nil {
+			latencyCollector := metrics.NewRequestLatencyCollector()
+			if register("request_latency", latencyCollector) {
+				s.latencyProber.SetObserver(latencyCollector)
+			}
+		}
+		if s.handshakeProber != nil {
+			handshakeCollector := metrics.NewHandshakeCollector()
+			if register("handshake", handshakeCollector) {
+				s.handshakeProber.SetObserver(handshakeCollector)
+			}
+		}
+		if s.partitionSizes != nil {
+			register("partition_sizes", metrics.NewLogDirsCollector(s.partitionSizes))
+		}
+		if s.peerChecker != nil {
+			register("peers", metrics.NewPeerCollector(s.peerChecker))
+		}
+		if s.interBrokerTLS != nil {
+			register("inter_broker_tls", metrics.NewInterBrokerTLSCollector(s.interBrokerTLS))
+		}
+		if s.certMonitor != nil {
+			register("tls", metrics.NewCertCollector(s.certMonitor))
+		}
+		if s.maintenance != nil {
+			register("maintenance", metrics.NewMaintenanceCollector(s.maintenance))
+		}
+		if s.metadataLog != nil {
+			register("kraft", metrics.NewKRaftCollector(s.metadataLog))
+		}
+		if s.driftDetector != nil {
+			register("drift", metrics.NewDriftCollector(s.driftDetector))
+		}
+		if s.gcLog != nil {
+			gcCollector := metrics.NewGCCollector()
+			if register("gc", gcCollector) {
+				s.gcLog.SetObserver(gcCollector)
+			}
+		}
+		var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+		merged := false
+		// Not running on Control Plane leaves the location label off
+		location, _ := discovery.DiscoverLocation()
+		if types.Config.UpstreamMetricsURL != "" {
+			upstream := metrics.NewUpstreamGatherer(types.Config.UpstreamMetricsURL, map[string]string{
+				"broker_id": strconv.Itoa(int(types.Config.BrokerID)),
+				"location":  location,
+			}, types.Config.CheckTimeout, s.logger)
+			gatherer = prometheus.Gatherers{gatherer, upstream}
+			merged = true
+		}
+		if types.Config.MetricsRelabelFile != "" {
+			// Validated in types.Initialize
+			relabel, _ := metrics.LoadRelabelConfig(types.Config.MetricsRelabelFile)
+			gatherer = metrics.NewRelabelGatherer(gatherer, relabel)
+			merged = true
+		}
+		opts := promhttp.HandlerOpts{EnableOpenMetrics: types.Config.MetricsOpenMetricsEnabled}
+		if merged {
+			// Upstream series that collide with the sidecar's, and relabeled
+			// series that collide with each other, are dropped rather than
+			// failing the whole scrape
+			opts.ErrorHandling = promhttp.ContinueOnError
+			opts.ErrorLog = slog.NewLogLogger(s.logger.Handler(), slog.LevelWarn)
+		}
+		router.Handle("/metrics", metricsHandler(gatherer, opts)).Methods("GET")
+
+		if types.Config.OTLPMetricsEndpoint != "" {
+			// Validated in types.Initialize
+			protocol, _ := otlp.ParseProtocol(types.Config.OTLPMetricsProtocol)
+			headers, _ := otlp.ParseHeaders(types.Config.OTLPMetricsHeaders)
+			exporter := otlp.NewExporter(gatherer, otlp.Options{
+				Endpoint: types.Config.OTLPMetricsEndpoint,
+				Protocol: protocol,
+				Headers:  headers,
+				Insecure: types.Config.OTLPMetricsInsecure,
+				Interval: types.Config.OTLPMetricsInterval,
+				Timeout:  types.Config.CheckTimeout,
+				Attributes: map[string]string{
+					"broker_id": strconv.Itoa(int(types.Config.BrokerID)),
+					"location":  location,
+				},
+			}, s.logger)
+			exporter.SetTracker(s.tracker)
+			go exporter.Run(ctx)
+		}
+
+		if types.Config.StatsDAddress != "" {
+			// Validated in types.Initialize
+			tagFormat, _ := statsd.ParseTagFormat(types.Config.StatsDTagFormat)
+			tags, _ := statsd.ParseTags(types.Config.StatsDTags)
+			selected, _ := statsd.ParseMetrics(types.Config.StatsDMetrics)
+			sink := statsd.NewSink(gatherer, statsd.Options{
+				Address:   types.Config.StatsDAddress,
+				Prefix:    types.Config.StatsDPrefix,
+				TagFormat: tagFormat,
+				Tags:      tags,
+				Metrics:   selected,
+				Interval:  types.Config.StatsDInterval,
+			}, s.logger)
+			sink.SetTracker(s.tracker)
+			go sink.Run(ctx)
+		}
 	}
-	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
 	// About endpoint
 	router.HandleFunc("/about", s.aboutHandler).Methods("GET")
 
-	addr := fmt.Sprintf(":%d", types.Config.Port)
-	s.httpServer = &http.Server{
-		Addr:         addr,
-		Handler:      router,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	// The broker's rack, for broker.rack
+	if types.Config.Rack != "" {
+		router.HandleFunc("/rack", s.rackHandler).Methods("GET")
+	}
+
+	// Generated KRaft controller quorum, for controller.quorum.voters
+	router.HandleFunc("/kraft/voters", s.votersHandler).Methods("GET")
+
+	// Generated advertised listeners, for advertised.listeners
+	router.HandleFunc("/listeners", s.listenersHandler).Methods("GET")
+
+	// OpenAPI document of the enabled routes
+	router.HandleFunc(openAPIPath, openAPIHandler(router)).Methods("GET")
+
+	// On-call dashboard, polling the endpoints above from the browser
+	if types.Config.UIEnabled {
+		router.Handle(strings.TrimSuffix(dashboard.Path, "/"), http.RedirectHandler(dashboard.Path, http.StatusMovedPermanently))
+		router.PathPrefix(dashboard.Path).Handler(dashboard.Handler())
+	}
+
+	// Effective configuration, with secrets masked, and reloads of it
+	router.HandleFunc("/admin/config", s.configHandler).Methods("GET")
+	router.HandleFunc(reloadPath, s.reloadHandler).Methods("POST")
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	go s.reloadOnSignal(ctx, sighup)
+
+	// Profiling of the sidecar itself
+	if types.Config.PprofEnabled {
+		registerPprof(router)
+		s.logger.Warn("pprof endpoints enabled", "path", "/debug/pprof/")
+	}
+
+	// Quota recommendation endpoints
+	if s.quotaRecommender != nil {
+		router.HandleFunc("/admin/quotas/recommendations", s.quotaRecommender.RecommendationsHandler).Methods("GET")
+		router.HandleFunc("/admin/quotas/recommendations/apply", s.quotaRecommender.ApplyHandler).Methods("POST")
+		go s.quotaRecommender.Run(ctx)
+	}
+
+	// New-broker onboarding
+	if s.onboarder != nil {
+		router.HandleFunc("/admin/onboarding", s.onboarder.StatusHandler).Methods("GET")
+		s.safeMode.Remediate(ctx, s.onboarder.Run)
+	}
+
+	// Post-restart verification
+	if s.verifier != nil {
+		router.HandleFunc("/admin/verification", s.verifier.StatusHandler).Methods("GET")
+		go s.verifier.Run(ctx)
+	}
+	if s.webhook != nil {
+		go s.webhook.Run(ctx)
+	}
+
+	// Produce/consume canary
+	if s.canary != nil {
+		router.HandleFunc("/admin/canary", s.canary.StatusHandler).Methods("GET")
+		go s.canary.Run(ctx)
+	}
+
+	// Broker request latency probes
+	if s.latencyProber != nil {
+		go s.latencyProber.Run(ctx)
+	}
+
+	// Listener TLS handshake probes
+	if s.handshakeProber != nil {
+		go s.handshakeProber.Run(ctx)
+	}
+
+	// Inter-broker TLS handshakes
+	if s.interBrokerTLS != nil {
+		router.HandleFunc("/admin/tls/inter-broker", s.interBrokerTLS.ReportHandler).Methods("GET")
+		go s.interBrokerTLS.Run(ctx)
+	}
+
+	// TLS certificate expiry
+	if s.certMonitor != nil {
+		router.HandleFunc("/admin/tls/certificates", s.certMonitor.StatusHandler).Methods("GET")
+		go s.certMonitor.Run(ctx)
+	}
+
+	// Peer reachability
+	if s.peerChecker != nil {
+		router.HandleFunc("/admin/peers", s.peerChecker.ReportHandler).Methods("GET")
+		go s.peerChecker.Run(ctx)
+	}
+
+	// On-demand connectivity matrix
+	if s.connProber != nil {
+		router.HandleFunc(connectivity.Path, s.connProber.Handler).Methods("GET")
+	}
+
+	// Partition sizes
+	if s.partitionSizes != nil {
+		go s.partitionSizes.Run(ctx)
+	}
+
+	// Broker decommission
+	if s.decommissioner != nil {
+		// The restored drain is already marked in progress, so it cannot be started twice
+		s.safeMode.Remediate(ctx, s.decommissioner.Resume)
+		router.HandleFunc("/admin/decommission", s.decommissioner.StatusHandler).Methods("GET")
+		router.HandleFunc("/admin/decommission/min-isr", s.decommissioner.MinISRHandler).Methods("GET")
+		router.HandleFunc("/admin/decommission/min-isr/restore", s.decommissioner.RestoreMinISRHandler).Methods("POST")
+		router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}/plan", s.decommissioner.PlanHandler).Methods("GET")
+		router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}", s.decommissioner.StartHandler).Methods("POST")
+		router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}/unregister", s.decommissioner.UnregisterHandler).Methods("POST")
+	}
+
+	// Topic replication factor changes
+	if s.rfChanger != nil {
+		router.HandleFunc("/topics/replication-factor", s.rfChanger.StatusHandler).Methods("GET")
+		router.HandleFunc("/topics/{name}/replication-factor/plan", s.rfChanger.PlanHandler).Methods("GET")
+		router.HandleFunc("/topics/{name}/replication-factor", s.rfChanger.StartHandler).Methods("POST")
+	}
+
+	// Warm standby
+	if s.standby != nil {
+		router.HandleFunc("/admin/standby", s.standby.StatusHandler).Methods("GET")
+		router.HandleFunc("/admin/standby/promote", s.standby.PromoteHandler).Methods("POST")
+		s.safeMode.Remediate(ctx, s.standby.Run)
+	}
+
+	// SCRAM credentials
+	if s.scramManager != nil {
+		router.HandleFunc("/admin/scram", s.scramManager.StatusHandler).Methods("GET")
+		s.safeMode.Remediate(ctx, s.scramManager.Run)
+	}
+
+	// Config drift
+	if s.driftDetector != nil {
+		router.HandleFunc("/admin/configs/drift", s.driftDetector.DriftHandler).Methods("GET")
+		go s.driftDetector.Run(ctx)
+	}
+
+	// Topic catalog
+	if s.catalog != nil {
+		router.HandleFunc("/catalog/topics", s.catalog.TopicsHandler).Methods("GET")
+		go s.catalog.Run(ctx)
+	}
+
+	// Maintenance safety score
+	if s.maintenance != nil {
+		router.HandleFunc("/admin/maintenance/safety", s.maintenance.StatusHandler).Methods("GET")
+		go s.maintenance.Run(ctx)
+	}
+
+	// KRaft metadata log and snapshots
+	if s.metadataLog != nil {
+		router.HandleFunc("/kraft/metadata-log", s.metadataLog.StatusHandler).Methods("GET")
+		go s.metadataLog.Run(ctx)
+	}
+	if s.brokerEpoch != nil {
+		router.HandleFunc("/kraft/broker-epoch", s.brokerEpoch.StatusHandler).Methods("GET")
+		go s.brokerEpoch.Run(ctx)
+	}
+
+	// Broker request error rates
+	if s.requestErrors != nil {
+		go s.requestErrors.Run(ctx)
+	}
+
+	// OOM prediction from the working set trend
+	if s.oomPredictor != nil {
+		go s.oomPredictor.Run(ctx)
 	}
 
-	errCh := make(chan error, 1)
-	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			errCh <- err
+	// Broker GC pauses
+	if s.gcLog != nil {
+		go s.gcLog.Run(ctx)
+	}
+
+	// Slack and PagerDuty alerts
+	if s.alerter != nil {
+		router.HandleFunc(alerting.Path, s.alerter.StatusHandler).Methods("GET")
+		go s.alerter.Run(ctx)
+	}
+
+	// Metadata snapshots to object storage
+	if s.backup != nil {
+		router.HandleFunc(backup.Path, s.backup.StatusHandler).Methods("GET")
+		router.HandleFunc(backup.Path, s.backup.BackupHandler).Methods("POST")
+		go s.backup.Run(ctx)
+	}
+
+	if s.bootstrap != nil {
+		go s.bootstrap.Run(ctx)
+	}
+	if s.dnsGate != nil {
+		go s.dnsGate.Run(ctx)
+	}
+
+	// Consumer group offsets export and reset
+	if types.Config.OffsetsExportEnabled {
+		router.HandleFunc("/admin/consumer-groups/{group}/offsets/export", s.offsetsManager.ExportHandler).Methods("GET")
+	}
+	if types.Config.OffsetsResetEnabled {
+		router.HandleFunc("/admin/consumer-groups/{group}/offsets", s.offsetsManager.ResetHandler).Methods("POST")
+	}
+
+	tlsConfig, err := listenerTLS(types.Config.ServerTLSClientAuth)
+	if err != nil {
+		return err
+	}
+	serve := versioned
+	if types.Config.CORSAllowedOrigins != "" {
+		// Validated in types.Initialize
+		origins, _ := cors.ParseOrigins(types.Config.CORSAllowedOrigins)
+		methods, _ := cors.ParseMethods(types.Config.CORSAllowedMethods)
+		allow := cors.Middleware(cors.Options{AllowedOrigins: origins, AllowedMethods: methods})
+		serve = func(next http.Handler) http.Handler {
+			return allow(versioned(next))
+		}
+		s.logger.Info("cors: allowing browser requests", "origins", origins, "methods", methods)
+	}
+	handler := serve(router)
+	if types.Config.AdminPort > 0 {
+		adminTLSConfig, err := listenerTLS(cmp.Or(types.Config.AdminTLSClientAuth, types.Config.ServerTLSClientAuth))
+		if err != nil {
+			return err
 		}
-	}()
+		s.adminServer = newHTTPServer(types.Config.AdminPort, handler,
+			types.Config.AdminReadTimeout, types.Config.AdminWriteTimeout, adminTLSConfig)
+		handler = serve(probeOnly(router))
+		s.logger.Info("serving admin endpoints on a separate port", "port", types.Config.AdminPort)
+	}
+	s.httpServer = newHTTPServer(types.Config.Port, handler,
+		types.Config.HTTPReadTimeout, types.Config.HTTPWriteTimeout, tlsConfig)
+
+	errCh := make(chan error, 2)
+	go serveHTTP(s.httpServer, errCh)
+	if s.adminServer != nil {
+		go serveHTTP(s.adminServer, errCh)
+	}
 
 	select {
 	case <-ctx.Done():
@@ -99,10 +1068,177 @@ func (s *Server) Shutdown() error {
 	defer cancel()
 
 	s.logger.Info("shutting down HTTP server")
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if s.adminServer != nil {
+		err = errors.Join(err, s.adminServer.Shutdown(ctx))
+	}
+	if s.auditLog != nil {
+		err = errors.Join(err, s.auditLog.Close())
+	}
+	if s.tracerProvider != nil {
+		// Flush the spans of the last probes
+		if flushErr := s.tracerProvider.Shutdown(ctx); flushErr != nil {
+			s.logger.Warn("otlp: failed to flush traces on shutdown", "error", flushErr)
+		}
+	}
+	return err
+}
+
+// metricsHandler serves the gatherer's metrics. The format follows the
+// scraper's Accept header (OpenMetrics when enabled and asked for, otherwise
+// the classic text format) and the body is compressed when the scraper accepts
+// gzip, which keeps large per-partition and upstream payloads within scrape
+// timeouts.
+func metricsHandler(gatherer prometheus.Gatherer, opts promhttp.HandlerOpts) http.Handler {
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherer, opts))
+}
+
+// newHTTPServer creates a listener for the handler, served over TLS when
+// tlsConfig is set
+func newHTTPServer(port int, handler http.Handler, readTimeout, writeTimeout time.Duration, tlsConfig *tls.Config) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      handler,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  60 * time.Second,
+		TLSConfig:    tlsConfig,
+	}
+}
+
+// serveHTTP runs the server until it is shut down, reporting any other error
+func serveHTTP(server *http.Server, errCh chan<- error) {
+	var err error
+	if server.TLSConfig != nil {
+		// The certificate comes from TLSConfig.GetCertificate
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errCh <- err
+	}
+}
+
+// listenerTLS returns the TLS configuration of a listener with the client
+// authentication mode, or nil when the sidecar serves plain HTTP
+func listenerTLS(clientAuth string) (*tls.Config, error) {
+	if types.Config.ServerTLSCertFile == "" {
+		return nil, nil
+	}
+	// Validated in types.Initialize
+	mode, _ := servertls.ParseClientAuth(clientAuth)
+	return servertls.NewConfig(servertls.Options{
+		CertFile:      types.Config.ServerTLSCertFile,
+		KeyFile:       types.Config.ServerTLSKeyFile,
+		ClientCAFiles: kafkaclient.ParseCAFiles(types.Config.ServerTLSClientCAFiles),
+		ClientAuth:    mode,
+	})
+}
+
+// peerTLSConfig returns the TLS configuration for reading peer sidecars'
+// reports when the listeners serve HTTPS, or nil. Peers are verified against
+// the system roots and SERVER_TLS_CLIENT_CA_FILES, and are shown this sidecar's
+// certificate, so that peers requiring client certificates accept each other.
+func peerTLSConfig(logger *slog.Logger) *tls.Config {
+	if types.Config.ServerTLSCertFile == "" {
+		return nil
+	}
+	cfg, err := kafkaclient.NewTLSConfig(kafkaclient.TLSConfig{
+		Enabled:  true,
+		CertFile: types.Config.ServerTLSCertFile,
+		KeyFile:  types.Config.ServerTLSKeyFile,
+		CAFiles:  kafkaclient.ParseCAFiles(types.Config.ServerTLSClientCAFiles),
+	})
+	if err != nil {
+		// Validated in types.Initialize; fall back to the system roots only
+		logger.Error("failed to configure TLS for peer sidecars", "error", err)
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return cfg
+}
+
+// probeOnly answers 404 for the endpoints that need authentication, which are
+// served on the admin port instead, so that port can be firewalled separately
+func probeOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.Protected(r) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authVerifiers returns the configured bearer-token verifiers, none when
+// authentication is disabled
+func authVerifiers(logger *slog.Logger) ([]auth.Verifier, error) {
+	var verifiers []auth.Verifier
+	if types.Config.AuthTokenFile != "" {
+		tokens, err := auth.LoadTokenFile(types.Config.AuthTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AUTH_TOKEN_FILE: %w", err)
+		}
+		verifiers = append(verifiers, tokens)
+	}
+	if types.Config.AuthJWKSURL != "" {
+		verifiers = append(verifiers, auth.NewJWKSVerifier(auth.JWTOptions{
+			JWKSURL:  types.Config.AuthJWKSURL,
+			Issuer:   types.Config.AuthJWTIssuer,
+			Audience: types.Config.AuthJWTAudience,
+			Timeout:  types.Config.CheckTimeout,
+		}, logger))
+	}
+	if len(verifiers) == 0 {
+		logger.Warn("admin endpoints are not authenticated; set AUTH_TOKEN_FILE or AUTH_JWKS_URL")
+	}
+	return verifiers, nil
+}
+
+// registerPprof mounts the net/http/pprof handlers on the router. The package
+// only registers them on http.DefaultServeMux, which the sidecar does not serve.
+func registerPprof(router *mux.Router) {
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	// Index serves the named profiles (heap, goroutine, allocs, ...) as well
+	router.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+}
+
+// tracked records every response of a health handler with the freshness tracker.
+// Any 2xx response counts as a success.
+func (s *Server) tracked(check string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(rec, req)
+
+		var err error
+		if rec.status < 200 || rec.status >= 300 {
+			err = fmt.Errorf("%s returned HTTP %d", check, rec.status)
+		}
+		s.tracker.Record(check, err)
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
 }
 
 // aboutHandler returns version information
 func (s *Server) aboutHandler(w http.ResponseWriter, _ *http.Request) {
 	_, _ = web.ReturnResponse(w, about.About)
 }
+
+// configHandler returns the effective configuration and where each value came from
+func (s *Server) configHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, types.Config.Settings())
+}
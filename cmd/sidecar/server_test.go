@@ -1,18 +1,26 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"github.com/controlplane-com/kafka-orchestrator/pkg/about"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
 )
 
@@ -244,3 +252,116 @@ func TestServerStartListenError(t *testing.T) {
 		t.Fatal("Start did not return after bind failure")
 	}
 }
+
+func TestTracked(t *testing.T) {
+	s := &Server{
+		logger:  testLogger(),
+		tracker: freshness.NewTracker(time.Minute, testLogger()),
+	}
+
+	healthy := s.tracked("liveness", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	unhealthy := s.tracked("readiness", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	healthy(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	w := httptest.NewRecorder()
+	unhealthy(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected wrapped status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	statuses := s.tracker.Statuses()
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 tracked checks, got %d", len(statuses))
+	}
+	if statuses[0].Name != "liveness" || statuses[0].LastSuccess == nil {
+		t.Errorf("expected liveness success, got %+v", statuses[0])
+	}
+	if statuses[1].Name != "readiness" || statuses[1].LastSuccess != nil || statuses[1].LastAttempt == nil {
+		t.Errorf("expected readiness attempt without success, got %+v", statuses[1])
+	}
+}
+
+func TestRegisterPprof(t *testing.T) {
+	router := mux.NewRouter()
+	registerPprof(router)
+
+	for path, want := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/heap?debug=1":      "heap profile",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile",
+		"/debug/pprof/cmdline":           "",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: expected body to contain %q", path, want)
+		}
+	}
+}
+
+func TestMetricsHandlerNegotiation(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kafka_memory_oom_ratio", Help: "test"})
+	gauge.Set(0.5)
+	registry.MustRegister(gauge)
+	openMetrics := "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5"
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		accept      string
+		encoding    string
+		contentType string
+	}{
+		{name: "openmetrics", enabled: true, accept: openMetrics, contentType: "application/openmetrics-text"},
+		{name: "openmetrics disabled", enabled: false, accept: openMetrics, contentType: "text/plain"},
+		{name: "classic", enabled: true, accept: "text/plain", contentType: "text/plain"},
+		{name: "gzip", enabled: true, encoding: "gzip", contentType: "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := metricsHandler(registry, promhttp.HandlerOpts{EnableOpenMetrics: tt.enabled})
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.encoding != "" {
+				req.Header.Set("Accept-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+				t.Errorf("expected content type %s, got %s", tt.contentType, ct)
+			}
+			body := io.Reader(w.Body)
+			if tt.encoding == "gzip" {
+				if ce := w.Header().Get("Content-Encoding"); ce != "gzip" {
+					t.Fatalf("expected a gzip response, got %q", ce)
+				}
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+				body = zr
+			} else if ce := w.Header().Get("Content-Encoding"); ce != "" {
+				t.Errorf("expected an uncompressed response, got %q", ce)
+			}
+			out, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), "kafka_memory_oom_ratio 0.5") {
+				t.Errorf("expected the gauge in the body, got %s", out)
+			}
+		})
+	}
+}
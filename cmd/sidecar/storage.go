@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kraft"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// prepareStorage checks the meta.properties of LOG_DIRS against CLUSTER_ID and
+// the broker ID, writing it to fresh volumes, so a volume of another cluster or
+// node fails here instead of sending Kafka into a restart loop
+func prepareStorage(logger *slog.Logger) error {
+	results, err := kraft.PrepareStorage(metrics.ParseDiskPaths(types.Config.LogDirs), types.Config.ClusterID, types.Config.BrokerID)
+	if err != nil {
+		return fmt.Errorf("storage does not match cluster %s and node %d: %w", types.Config.ClusterID, types.Config.BrokerID, err)
+	}
+	for _, result := range results {
+		if result.Formatted {
+			logger.Info("wrote meta.properties to a fresh log directory", "dir", result.Dir, "clusterId", types.Config.ClusterID, "nodeId", types.Config.BrokerID)
+		} else {
+			logger.Info("meta.properties matches", "dir", result.Dir)
+		}
+	}
+	return nil
+}
+
+// runInitStorage prepares the log directories and exits, for running the
+// sidecar as an init container ahead of the Kafka container
+func runInitStorage() int {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := types.Initialize(logger); err != nil {
+		logger.Error("failed to initialize configuration", "error", err)
+		return 1
+	}
+	if types.Config.ClusterID == "" || types.Config.LogDirs == "" {
+		logger.Error("--init-storage requires CLUSTER_ID and LOG_DIRS or DISK_USAGE_PATHS")
+		return 1
+	}
+	if !types.Config.Profile().MetadataLog {
+		logger.Error("--init-storage requires a KRaft node", "role", types.Config.Role)
+		return 1
+	}
+	if err := prepareStorage(logger); err != nil {
+		logger.Error("failed to prepare storage", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// Statuses of a validation step
+const (
+	stepOK      = "ok"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+// validationStep is the outcome of one check of --validate-config
+type validationStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// validationReport is what --validate-config prints
+type validationReport struct {
+	Valid bool             `json:"valid"`
+	Steps []validationStep `json:"steps"`
+	// Settings are the settings that do not use their default, with secrets
+	// masked as in GET /admin/config
+	Settings []types.Setting `json:"settings,omitempty"`
+	// exitCode classifies the first failure
+	exitCode int
+}
+
+// Header implements cli.Table
+func (r *validationReport) Header() []string {
+	return []string{"STEP", "STATUS", "DETAIL"}
+}
+
+// Rows implements cli.Table
+func (r *validationReport) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		rows = append(rows, []string{step.Name, step.Status, step.Detail})
+	}
+	return rows
+}
+
+// add records a step, and the exit code of the first failure
+func (r *validationReport) add(step validationStep, code int) {
+	r.Steps = append(r.Steps, step)
+	if step.Status == stepFailed && r.Valid {
+		r.Valid, r.exitCode = false, code
+	}
+}
+
+// lookupHost resolves bootstrap hosts. Replaced in tests.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// validateConfig parses the configuration, performs discovery and resolves the
+// bootstrap servers as the sidecar does at startup, and connects to Kafka when
+// connect is set. Nothing is started and nothing is written to Kafka.
+func validateConfig(ctx context.Context, logger *slog.Logger, connect bool) *validationReport {
+	report := &validationReport{Valid: true, exitCode: cli.ExitOK}
+
+	if err := types.Initialize(logger); err != nil {
+		report.add(validationStep{Name: "configuration", Status: stepFailed, Detail: err.Error()}, cli.ExitError)
+		for _, name := range []string{"discovery", "dns", "kafka"} {
+			report.add(validationStep{Name: name, Status: stepSkipped, Detail: "invalid configuration"}, cli.ExitOK)
+		}
+		return report
+	}
+
+	var discovered []string
+	for _, s := range types.Config.Settings() {
+		switch s.Source {
+		case types.SourceDefault:
+			continue
+		case types.SourceDiscovered:
+			discovered = append(discovered, fmt.Sprintf("%s=%v", s.Env, s.Value))
+		}
+		report.Settings = append(report.Settings, s)
+	}
+	report.add(validationStep{Name: "configuration", Status: stepOK,
+		Detail: fmt.Sprintf("%d settings set, role %s", len(report.Settings), types.Config.Role)}, cli.ExitOK)
+	if len(discovered) == 0 {
+		report.add(validationStep{Name: "discovery", Status: stepOK, Detail: "nothing to discover, all set explicitly"}, cli.ExitOK)
+	} else {
+		report.add(validationStep{Name: "discovery", Status: stepOK, Detail: strings.Join(discovered, ", ")}, cli.ExitOK)
+	}
+
+	// Connections use the credentials kept in the secret manager, without
+	// writing the client certificate anywhere
+	setSASLCredentials(nil)
+	if refresher := newSecretRefresher(func(values map[string]string) error {
+		setSASLCredentials(values)
+		return nil
+	}, logger); refresher != nil {
+		if err := refresher.Step(ctx); err != nil {
+			report.add(validationStep{Name: "secrets", Status: stepFailed, Detail: err.Error()}, cli.ExitUnavailable)
+		} else {
+			report.add(validationStep{Name: "secrets", Status: stepOK,
+				Detail: fmt.Sprintf("%d secrets fetched from %s", len(refresher.Values()), types.Config.SecretProvider)}, cli.ExitOK)
+		}
+	}
+
+	resolved := true
+	for _, server := range kafkaclient.ParseBootstrapServers(types.Config.BootstrapServers) {
+		step := resolveStep(ctx, server)
+		resolved = resolved && step.Status == stepOK
+		report.add(step, cli.ExitUnavailable)
+	}
+
+	switch {
+	case !connect:
+		report.add(validationStep{Name: "kafka", Status: stepSkipped, Detail: "use --connect to connect"}, cli.ExitOK)
+	case !resolved:
+		report.add(validationStep{Name: "kafka", Status: stepSkipped, Detail: "bootstrap servers do not resolve"}, cli.ExitOK)
+	default:
+		report.add(connectStep(ctx), cli.ExitUnavailable)
+	}
+	return report
+}
+
+// resolveStep resolves the host of a bootstrap server
+func resolveStep(ctx context.Context, server string) validationStep {
+	step := validationStep{Name: "dns " + server}
+	host, _, err := net.SplitHostPort(server)
+	if err != nil {
+		step.Status, step.Detail = stepFailed, err.Error()
+		return step
+	}
+	ctx, cancel := context.WithTimeout(ctx, types.Config.CheckTimeout)
+	defer cancel()
+	addrs, err := lookupHost(ctx, host)
+	if err != nil {
+		step.Status, step.Detail = stepFailed, err.Error()
+		return step
+	}
+	slices.Sort(addrs)
+	step.Status, step.Detail = stepOK, strings.Join(addrs, ", ")
+	return step
+}
+
+// connectStep connects to Kafka with the sidecar's client settings, including
+// SASL and TLS, and reads the cluster metadata
+func connectStep(ctx context.Context) validationStep {
+	step := validationStep{Name: "kafka"}
+	adm, cleanup, err := kafkaclient.NewAdminClient(kafkaConfig())
+	if err != nil {
+		step.Status, step.Detail = stepFailed, err.Error()
+		return step
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, types.Config.CheckTimeout)
+	defer cancel()
+	md, err := adm.BrokerMetadata(ctx)
+	if err != nil {
+		step.Status, step.Detail = stepFailed, err.Error()
+		return step
+	}
+	registered := "not registered yet"
+	if slices.ContainsFunc(md.Brokers, func(b kadm.BrokerDetail) bool { return b.NodeID == types.Config.BrokerID }) {
+		registered = "registered"
+	}
+	step.Status = stepOK
+	step.Detail = fmt.Sprintf("cluster %s, %d brokers, controller %d, broker %d %s",
+		md.Cluster, len(md.Brokers), md.Controller, types.Config.BrokerID, registered)
+	return step
+}
+
+// runValidation runs --validate-config, prints the report in the format and
+// returns the process exit code
+func runValidation(w io.Writer, connect bool, format cli.Format) int {
+	// The report goes to w; configuration warnings go to stderr
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	report := validateConfig(context.Background(), logger, connect)
+	if err := cli.Write(w, format, report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return cli.ExitError
+	}
+	return report.exitCode
+}
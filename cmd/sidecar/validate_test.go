@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/cli"
+)
+
+// setValidationEnv sets the environment variables for the test
+func setValidationEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	original := lookupHost
+	defer func() { lookupHost = original }()
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if strings.HasPrefix(host, "kafka-2.") {
+			return nil, errors.New("no such host")
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		setValidationEnv(t, map[string]string{
+			"HOSTNAME":          "kafka-1",
+			"BOOTSTRAP_SERVERS": "kafka-0.kafka:9092,kafka-1.kafka:9092",
+		})
+		report := validateConfig(context.Background(), testLogger(), false)
+		if !report.Valid || report.exitCode != cli.ExitOK {
+			t.Fatalf("expected a valid report, got %+v", report.Steps)
+		}
+		want := []string{"configuration", "discovery", "dns kafka-0.kafka:9092", "dns kafka-1.kafka:9092", "kafka"}
+		if len(report.Steps) != len(want) {
+			t.Fatalf("expected steps %v, got %+v", want, report.Steps)
+		}
+		for i, name := range want {
+			if report.Steps[i].Name != name {
+				t.Errorf("step %d: expected %s, got %s", i, name, report.Steps[i].Name)
+			}
+		}
+		if !strings.Contains(report.Steps[1].Detail, "BROKER_ID=1") {
+			t.Errorf("expected the discovered broker ID, got %q", report.Steps[1].Detail)
+		}
+		if report.Steps[4].Status != stepSkipped {
+			t.Errorf("expected the connection to be skipped without --connect, got %s", report.Steps[4].Status)
+		}
+	})
+
+	t.Run("unresolvable bootstrap server", func(t *testing.T) {
+		setValidationEnv(t, map[string]string{
+			"HOSTNAME":          "kafka-1",
+			"BOOTSTRAP_SERVERS": "kafka-0.kafka:9092,kafka-2.kafka:9092",
+		})
+		report := validateConfig(context.Background(), testLogger(), true)
+		if report.Valid || report.exitCode != cli.ExitUnavailable {
+			t.Fatalf("expected exit code %d, got %d", cli.ExitUnavailable, report.exitCode)
+		}
+		if last := report.Steps[len(report.Steps)-1]; last.Name != "kafka" || last.Status != stepSkipped {
+			t.Errorf("expected the connection to be skipped, got %+v", last)
+		}
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		setValidationEnv(t, map[string]string{
+			"HOSTNAME":          "kafka-1",
+			"BOOTSTRAP_SERVERS": "kafka-0.kafka:9092",
+			"LOG_LEVEL":         "loud",
+		})
+		report := validateConfig(context.Background(), testLogger(), true)
+		if report.Valid || report.exitCode != cli.ExitError {
+			t.Fatalf("expected exit code %d, got %d", cli.ExitError, report.exitCode)
+		}
+		if report.Steps[0].Status != stepFailed || !strings.Contains(report.Steps[0].Detail, "LOG_LEVEL") {
+			t.Errorf("expected the configuration step to fail on LOG_LEVEL, got %+v", report.Steps[0])
+		}
+	})
+}
+
+func TestRunValidationOutput(t *testing.T) {
+	t.Setenv("HOSTNAME", "kafka-1")
+	t.Setenv("BOOTSTRAP_SERVERS", "127.0.0.1:9092")
+
+	var out bytes.Buffer
+	if code := runValidation(&out, false, cli.FormatJSON); code != cli.ExitOK {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(out.String(), `"valid": true`) {
+		t.Errorf("expected a JSON report, got %s", out.String())
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/types"
+)
+
+// votersResponse is the KRaft controller quorum as served at /kraft/voters
+type votersResponse struct {
+	// QuorumVoters is the controller.quorum.voters value
+	QuorumVoters string            `json:"quorumVoters"`
+	Voters       []discovery.Voter `json:"voters"`
+}
+
+// votersHandler returns the generated controller.quorum.voters, for a Kafka
+// container that templates its configuration from the sidecar rather than
+// from KRAFT_VOTERS_FILE
+func (s *Server) votersHandler(w http.ResponseWriter, _ *http.Request) {
+	voters, err := types.Config.QuorumVoters()
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Internal("failed to generate the quorum voters", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, votersResponse{QuorumVoters: discovery.FormatVoters(voters), Voters: voters})
+}
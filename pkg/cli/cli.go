@@ -0,0 +1,32 @@
+// Package cli holds what the operator CLI subcommands share: the --output
+// formats, the exit codes scripts can branch on, and --wait polling for
+// long operations such as drains and reassignments.
+package cli
+
+import (
+	"flag"
+	"time"
+)
+
+// Options holds the flags shared by every subcommand
+type Options struct {
+	// Output is the --output format
+	Output Format
+	// Wait polls long operations until they finish instead of returning once
+	// they are accepted
+	Wait bool
+	// WaitTimeout bounds --wait; zero waits indefinitely
+	WaitTimeout time.Duration
+	// WaitInterval is how often --wait polls
+	WaitInterval time.Duration
+}
+
+// RegisterFlags adds the shared flags to a subcommand's flag set
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	o.Output = FormatTable
+	fs.Var(&o.Output, "output", "output format: json, yaml or table")
+	fs.Var(&o.Output, "o", "shorthand for --output")
+	fs.BoolVar(&o.Wait, "wait", false, "wait for long operations to finish")
+	fs.DurationVar(&o.WaitTimeout, "wait-timeout", 0, "give up waiting after this long (0 waits indefinitely)")
+	fs.DurationVar(&o.WaitInterval, "wait-interval", 5*time.Second, "how often to poll while waiting")
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Exit codes. They are stable across releases so runbooks and pipelines can
+// branch on the failure class rather than parse error messages.
+const (
+	// ExitOK means the command succeeded
+	ExitOK = 0
+	// ExitError is any failure without a more specific code
+	ExitError = 1
+	// ExitUsage means invalid flags or arguments
+	ExitUsage = 2
+	// ExitUnavailable means the sidecar or Kafka could not be reached
+	ExitUnavailable = 3
+	// ExitNotFound means the broker, topic or group does not exist
+	ExitNotFound = 4
+	// ExitConflict means the request was refused by a safety check or because
+	// another operation is in progress
+	ExitConflict = 5
+	// ExitTimeout means --wait gave up before the operation finished
+	ExitTimeout = 6
+	// ExitFailed means the operation was started but reported failure
+	ExitFailed = 7
+)
+
+// Error is an error carrying the exit code of its failure class
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode wraps err with an exit code
+func WithCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code for the error a command returned. An error
+// without an explicit code is classified by its cause where possible.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+	return ExitError
+}
+
+// StatusCode returns the exit code for a failed sidecar API response
+func StatusCode(status int) int {
+	switch {
+	case status < 400:
+		return ExitOK
+	case status == http.StatusNotFound:
+		return ExitNotFound
+	case status == http.StatusConflict || status == http.StatusPreconditionFailed:
+		return ExitConflict
+	case status == http.StatusServiceUnavailable || status == http.StatusBadGateway || status == http.StatusGatewayTimeout:
+		return ExitUnavailable
+	case status < 500:
+		return ExitUsage
+	default:
+		return ExitError
+	}
+}
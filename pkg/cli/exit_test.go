@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{WithCode(ExitNotFound, errors.New("no such topic")), ExitNotFound},
+		{fmt.Errorf("drain: %w", WithCode(ExitConflict, errors.New("in progress"))), ExitConflict},
+		{fmt.Errorf("request: %w", context.DeadlineExceeded), ExitTimeout},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, expected %d", tt.err, got, tt.want)
+		}
+	}
+	if WithCode(ExitFailed, nil) != nil {
+		t.Error("expected WithCode to keep a nil error nil")
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := map[int]int{
+		http.StatusOK:                  ExitOK,
+		http.StatusAccepted:            ExitOK,
+		http.StatusBadRequest:          ExitUsage,
+		http.StatusNotFound:            ExitNotFound,
+		http.StatusConflict:            ExitConflict,
+		http.StatusServiceUnavailable:  ExitUnavailable,
+		http.StatusInternalServerError: ExitError,
+	}
+	for status, want := range tests {
+		if got := StatusCode(status); got != want {
+			t.Errorf("StatusCode(%d) = %d, expected %d", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// KafkaFlags are the Kafka connection settings of a CLI. They default to the
+// variables the sidecar reads, so a CLI run in the sidecar container connects
+// as the sidecar does.
+type KafkaFlags struct {
+	bootstrapServers string
+	saslEnabled      bool
+	saslMechanism    string
+	saslUsername     string
+	saslPassword     string
+	saslPasswordFile string
+	tlsEnabled       bool
+	tlsCertFile      string
+	tlsKeyFile       string
+	tlsCAFiles       string
+}
+
+// RegisterFlags adds the Kafka connection flags to fs
+func (f *KafkaFlags) RegisterFlags(fs *flag.FlagSet, env discovery.Env) {
+	bootstrap := env.Get("BOOTSTRAP_SERVERS")
+	if bootstrap == "" {
+		bootstrap = "localhost:9092"
+	}
+	mechanism := env.Get("SASL_MECHANISM")
+	if mechanism == "" {
+		mechanism = "PLAIN"
+	}
+	saslEnabled, _ := strconv.ParseBool(env.Get("SASL_ENABLED"))
+	tlsEnabled, _ := strconv.ParseBool(env.Get("TLS_ENABLED"))
+
+	fs.StringVar(&f.bootstrapServers, "bootstrap-servers", bootstrap, "comma-separated Kafka bootstrap servers")
+	fs.BoolVar(&f.saslEnabled, "sasl", saslEnabled, "authenticate with SASL")
+	fs.StringVar(&f.saslMechanism, "sasl-mechanism", mechanism, "SASL mechanism: PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512")
+	fs.StringVar(&f.saslUsername, "sasl-username", env.Get("SASL_USERNAME"), "SASL username")
+	fs.StringVar(&f.saslPassword, "sasl-password", env.Get("SASL_PASSWORD"), "SASL password")
+	fs.StringVar(&f.saslPasswordFile, "sasl-password-file", env.Get("SASL_PASSWORD_FILE"), "file holding the SASL password")
+	fs.BoolVar(&f.tlsEnabled, "tls", tlsEnabled, "connect with TLS")
+	fs.StringVar(&f.tlsCertFile, "tls-cert-file", env.Get("TLS_CERT_FILE"), "client certificate for mutual TLS")
+	fs.StringVar(&f.tlsKeyFile, "tls-key-file", env.Get("TLS_KEY_FILE"), "client certificate key for mutual TLS")
+	fs.StringVar(&f.tlsCAFiles, "tls-ca-files", env.Get("TLS_CA_FILES"), "comma-separated PEM CA bundles trusted besides the system roots")
+}
+
+// Config returns the client configuration of the flags
+func (f *KafkaFlags) Config() (kafkaclient.Config, error) {
+	password := f.saslPassword
+	if f.saslPasswordFile != "" {
+		data, err := os.ReadFile(f.saslPasswordFile)
+		if err != nil {
+			return kafkaclient.Config{}, WithCode(ExitUsage, fmt.Errorf("failed to read SASL password file: %w", err))
+		}
+		password = strings.TrimSpace(string(data))
+	}
+	return kafkaclient.Config{
+		BootstrapServers: kafkaclient.ParseBootstrapServers(f.bootstrapServers),
+		SASL: kafkaclient.SASLConfig{
+			Enabled:   f.saslEnabled,
+			Mechanism: f.saslMechanism,
+			Username:  f.saslUsername,
+			Password:  password,
+		},
+		TLS: kafkaclient.TLSConfig{
+			Enabled:  f.tlsEnabled,
+			CertFile: f.tlsCertFile,
+			KeyFile:  f.tlsKeyFile,
+			CAFiles:  kafkaclient.ParseCAFiles(f.tlsCAFiles),
+		},
+	}, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Format is the output format of a command
+type Format string
+
+const (
+	// FormatTable prints aligned columns for people, and is the default
+	FormatTable Format = "table"
+	// FormatJSON prints indented JSON for scripts
+	FormatJSON Format = "json"
+	// FormatYAML prints YAML for scripts and runbooks
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat parses the value of --output
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatTable, FormatJSON, FormatYAML:
+		return f, nil
+	case "":
+		return FormatTable, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: must be json, yaml or table", s)
+	}
+}
+
+// String implements flag.Value
+func (f *Format) String() string {
+	if *f == "" {
+		return string(FormatTable)
+	}
+	return string(*f)
+}
+
+// Set implements flag.Value
+func (f *Format) Set(s string) error {
+	parsed, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// Table is implemented by command results that can be printed as a table
+type Table interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// Write prints v in the format. JSON and YAML print the value as the sidecar
+// API returns it, so scripts see the same fields either way; the table format
+// needs v to implement Table.
+func Write(w io.Writer, format Format, v any) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatYAML:
+		// Round trip through JSON so the json tags name the fields, as in the API
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	case FormatTable, "":
+		table, ok := v.(Table)
+		if !ok {
+			return fmt.Errorf("%T cannot be printed as a table, use --output json or yaml", v)
+		}
+		return writeTable(w, table)
+	default:
+		return fmt.Errorf("invalid output format %q", format)
+	}
+}
+
+// writeTable prints the table in tab-aligned columns
+func writeTable(w io.Writer, table Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if header := table.Header(); len(header) > 0 {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range table.Rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+type brokerList []struct {
+	ID   int32  `json:"id"`
+	Rack string `json:"rack,omitempty"`
+}
+
+func (b brokerList) Header() []string {
+	return []string{"ID", "RACK"}
+}
+
+func (b brokerList) Rows() [][]string {
+	rows := make([][]string, 0, len(b))
+	for _, broker := range b {
+		rows = append(rows, []string{strconv.Itoa(int(broker.ID)), broker.Rack})
+	}
+	return rows
+}
+
+func testBrokers() brokerList {
+	return brokerList{{ID: 0, Rack: "us-east-1a"}, {ID: 1}}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatTable, "json": FormatJSON, "YAML": FormatYAML, "table": FormatTable} {
+		got, err := ParseFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatJSON, "[\n  {\n    \"id\": 0,\n    \"rack\": \"us-east-1a\"\n  },\n  {\n    \"id\": 1\n  }\n]\n"},
+		{FormatYAML, "- id: 0\n  rack: us-east-1a\n- id: 1\n"},
+		{FormatTable, "ID   RACK\n0    us-east-1a\n1    \n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, tt.format, testBrokers()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.format, tt.want, buf.String())
+		}
+	}
+}
+
+func TestWriteTableUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatTable, map[string]int{"a": 1}); err == nil {
+		t.Error("expected an error for a value without a table form")
+	}
+}
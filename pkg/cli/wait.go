@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// ErrOperationFailed is returned by a poll function when the operation it
+// watches reported failure
+var ErrOperationFailed = errors.New("operation failed")
+
+// Wait calls poll every interval until it reports the operation done. It
+// returns an error with ExitTimeout when the timeout passes first, and with
+// ExitFailed when poll returns ErrOperationFailed.
+func Wait(ctx context.Context, clk clock.Clock, interval, timeout time.Duration, poll func(ctx context.Context) (bool, error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := poll(ctx)
+		switch {
+		case errors.Is(err, ErrOperationFailed):
+			return WithCode(ExitFailed, err)
+		case err != nil && ctx.Err() == nil:
+			return err
+		case done:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return WithCode(ExitTimeout, fmt.Errorf("timed out waiting for the operation to finish: %w", ctx.Err()))
+			}
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
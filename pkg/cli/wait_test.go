@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func TestWaitUntilDone(t *testing.T) {
+	polls := 0
+	err := Wait(context.Background(), clock.Real, time.Millisecond, time.Second, func(ctx context.Context) (bool, error) {
+		polls++
+		return polls == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 polls, got %d", polls)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	err := Wait(context.Background(), clock.Real, time.Millisecond, 20*time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, nil
+	})
+	if code := ExitCode(err); code != ExitTimeout {
+		t.Errorf("expected exit code %d, got %d (%v)", ExitTimeout, code, err)
+	}
+}
+
+func TestWaitOperationFailed(t *testing.T) {
+	err := Wait(context.Background(), clock.Real, time.Millisecond, time.Second, func(ctx context.Context) (bool, error) {
+		return false, fmt.Errorf("decommission of broker 2: %w", ErrOperationFailed)
+	})
+	if code := ExitCode(err); code != ExitFailed {
+		t.Errorf("expected exit code %d, got %d (%v)", ExitFailed, code, err)
+	}
+}
+
+func TestWaitPollError(t *testing.T) {
+	unreachable := WithCode(ExitUnavailable, errors.New("connection refused"))
+	err := Wait(context.Background(), clock.Real, time.Millisecond, time.Second, func(ctx context.Context) (bool, error) {
+		return false, unreachable
+	})
+	if code := ExitCode(err); code != ExitUnavailable {
+		t.Errorf("expected exit code %d, got %d (%v)", ExitUnavailable, code, err)
+	}
+}
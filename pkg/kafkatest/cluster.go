@@ -0,0 +1,524 @@
+// Package kafkatest provides an in-process fake Kafka cluster that speaks the
+// subset of the wire protocol the sidecar's admin clients use: ApiVersions,
+// Metadata, DescribeLogDirs, CreateTopics, CreatePartitions, DescribeConfigs,
+// IncrementalAlterConfigs, AlterPartitionAssignments,
+// ListPartitionReassignments, ElectLeaders and ListOffsets. Each fake broker
+// listens on its own localhost port, so franz-go clients route requests to
+// leaders and controllers as they would in a real cluster.
+//
+// Tests shape the cluster directly (topics, ISRs, leaders, log dir sizes and
+// errors, stopped brokers) and intercept any request to inject failures.
+// Records are not stored: produce and fetch are not supported. The
+// integration build tag runs the same scenarios against real KRaft clusters in
+// Docker to keep the fake honest (see pkg/sidecar/integration).
+package kafkatest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+const (
+	// DefaultClusterID is the cluster ID of a cluster started without one
+	DefaultClusterID = "kafkatest-cluster-00000"
+	// DefaultLogDir is the single log directory of every fake broker
+	DefaultLogDir = "/var/lib/kafka/data"
+)
+
+// Options configures a fake cluster
+type Options struct {
+	// Brokers is the number of brokers, with IDs starting at FirstBrokerID.
+	// Defaults to 3.
+	Brokers int
+	// FirstBrokerID is the ID of the first broker. Defaults to 0.
+	FirstBrokerID int32
+	// ClusterID defaults to DefaultClusterID
+	ClusterID string
+	// Racks are the racks of the brokers in ID order; brokers past the end
+	// have none
+	Racks []string
+	// AutoCompleteReassignments completes partition reassignments as soon as
+	// they are requested, as if adding replicas caught up instantly.
+	// Otherwise they stay in progress until CompleteReassignments.
+	AutoCompleteReassignments bool
+}
+
+// Interceptor handles a request before the fake does. It returns handled
+// false to let the fake handle the request; a nil response with handled true
+// drops the connection, as a broker failing mid-request would.
+type Interceptor func(broker int32, req kmsg.Request) (resp kmsg.Response, handled bool)
+
+// Cluster is a fake Kafka cluster. It is safe for concurrent use.
+type Cluster struct {
+	mu           sync.Mutex
+	opts         Options
+	brokers      []*broker
+	topics       map[string]*topic
+	interceptors map[int16]Interceptor
+	wg           sync.WaitGroup
+	closed       bool
+}
+
+// broker is a fake broker and the connections it serves
+type broker struct {
+	id       int32
+	rack     *string
+	listener net.Listener
+	port     int32
+	stopped  bool
+	conns    map[net.Conn]struct{}
+	configs  map[string]string
+	// logDirErr fails the broker's log directory
+	logDirErr int16
+}
+
+// topic is a fake topic
+type topic struct {
+	name       string
+	id         [16]byte
+	internal   bool
+	partitions []*partition
+	configs    map[string]string
+}
+
+// partition is the state of a fake partition
+type partition struct {
+	leader      int32
+	leaderEpoch int32
+	replicas    []int32
+	isr         []int32
+	adding      []int32
+	removing    []int32
+	// sizes is the size of the partition's log on each replica broker
+	sizes       map[int32]int64
+	startOffset int64
+	endOffset   int64
+}
+
+// NewCluster starts a fake cluster listening on localhost. Close stops it.
+func NewCluster(opts Options) (*Cluster, error) {
+	if opts.Brokers <= 0 {
+		opts.Brokers = 3
+	}
+	if opts.ClusterID == "" {
+		opts.ClusterID = DefaultClusterID
+	}
+	c := &Cluster{
+		opts:         opts,
+		topics:       make(map[string]*topic),
+		interceptors: make(map[int16]Interceptor),
+	}
+	for i := 0; i < opts.Brokers; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to listen for broker %d: %w", opts.FirstBrokerID+int32(i), err)
+		}
+		b := &broker{
+			id:       opts.FirstBrokerID + int32(i),
+			listener: ln,
+			port:     int32(ln.Addr().(*net.TCPAddr).Port),
+			conns:    make(map[net.Conn]struct{}),
+			configs:  make(map[string]string),
+		}
+		if i < len(opts.Racks) && opts.Racks[i] != "" {
+			rack := opts.Racks[i]
+			b.rack = &rack
+		}
+		c.brokers = append(c.brokers, b)
+		c.wg.Add(1)
+		go c.serve(b)
+	}
+	return c, nil
+}
+
+// Start starts a fake cluster that is closed when the test finishes
+func Start(t testing.TB, opts Options) *Cluster {
+	t.Helper()
+	c, err := NewCluster(opts)
+	if err != nil {
+		t.Fatalf("failed to start fake cluster: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Close stops every broker and waits for their connections to close
+func (c *Cluster) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	for _, b := range c.brokers {
+		_ = b.listener.Close()
+		b.closeConns()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// Bootstrap returns the bootstrap servers in the sidecar's BOOTSTRAP_SERVERS form
+func (c *Cluster) Bootstrap() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	addrs := make([]string, 0, len(c.brokers))
+	for _, b := range c.brokers {
+		addrs = append(addrs, "127.0.0.1:"+strconv.Itoa(int(b.port)))
+	}
+	return strings.Join(addrs, ",")
+}
+
+// Config returns the client configuration of the cluster
+func (c *Cluster) Config() kafkaclient.Config {
+	return kafkaclient.Config{BootstrapServers: kafkaclient.ParseBootstrapServers(c.Bootstrap())}
+}
+
+// BrokerIDs returns the IDs of the brokers, stopped ones included
+func (c *Cluster) BrokerIDs() []int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]int32, 0, len(c.brokers))
+	for _, b := range c.brokers {
+		ids = append(ids, b.id)
+	}
+	return ids
+}
+
+// Intercept routes requests of the key through fn before the fake handles
+// them. A nil fn removes the interceptor.
+func (c *Cluster) Intercept(key int16, fn Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fn == nil {
+		delete(c.interceptors, key)
+		return
+	}
+	c.interceptors[key] = fn
+}
+
+// AddTopic adds a topic with one partition per assignment. The first replica
+// of each leads it and every replica is in sync.
+func (c *Cluster) AddTopic(name string, assignments ...[]int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[name] = c.newTopic(name, assignments)
+}
+
+// AddInternalTopic adds an internal topic, such as __consumer_offsets
+func (c *Cluster) AddInternalTopic(name string, assignments ...[]int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.newTopic(name, assignments)
+	t.internal = true
+	c.topics[name] = t
+}
+
+// newTopic returns a topic with the partition assignments
+func (c *Cluster) newTopic(name string, assignments [][]int32) *topic {
+	t := &topic{name: name, configs: make(map[string]string)}
+	_, _ = rand.Read(t.id[:])
+	for _, replicas := range assignments {
+		t.partitions = append(t.partitions, c.newPartition(replicas))
+	}
+	return t
+}
+
+// newPartition returns a partition led by the first replica that is running,
+// with every running replica in sync
+func (c *Cluster) newPartition(replicas []int32) *partition {
+	p := &partition{leader: -1, replicas: slices.Clone(replicas), sizes: make(map[int32]int64)}
+	for _, id := range replicas {
+		if b := c.broker(id); b != nil && !b.stopped {
+			p.isr = append(p.isr, id)
+		}
+	}
+	if len(p.isr) > 0 {
+		p.leader = p.isr[0]
+	}
+	return p
+}
+
+// DeleteTopic removes a topic
+func (c *Cluster) DeleteTopic(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.topics, name)
+}
+
+// PartitionState is a snapshot of a partition
+type PartitionState struct {
+	Leader      int32
+	LeaderEpoch int32
+	Replicas    []int32
+	ISR         []int32
+	Adding      []int32
+	Removing    []int32
+}
+
+// Partition returns a snapshot of a partition, or false when it does not exist
+func (c *Cluster) Partition(topic string, partition int32) (PartitionState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.partition(topic, partition)
+	if p == nil {
+		return PartitionState{}, false
+	}
+	return PartitionState{
+		Leader:      p.leader,
+		LeaderEpoch: p.leaderEpoch,
+		Replicas:    slices.Clone(p.replicas),
+		ISR:         slices.Clone(p.isr),
+		Adding:      slices.Clone(p.adding),
+		Removing:    slices.Clone(p.removing),
+	}, true
+}
+
+// SetISR sets the in-sync replicas of a partition. A leader that drops out
+// of the ISR hands leadership to the first replica left in it.
+func (c *Cluster) SetISR(topic string, partition int32, isr ...int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.partition(topic, partition); p != nil {
+		p.isr = slices.Clone(isr)
+		if !slices.Contains(p.isr, p.leader) {
+			c.electFromISR(p)
+		}
+	}
+}
+
+// SetLeader makes a replica lead a partition, bumping its leader epoch. -1
+// takes the partition offline.
+func (c *Cluster) SetLeader(topic string, partition int32, leader int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.partition(topic, partition); p != nil {
+		p.leader = leader
+		p.leaderEpoch++
+	}
+}
+
+// SetLogSize sets the size of a partition's log on a replica broker, as
+// DescribeLogDirs reports it
+func (c *Cluster) SetLogSize(topic string, partition int32, broker int32, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.partition(topic, partition); p != nil {
+		p.sizes[broker] = size
+	}
+}
+
+// SetOffsets sets the start and end offsets of a partition, as ListOffsets
+// reports them
+func (c *Cluster) SetOffsets(topic string, partition int32, start, end int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.partition(topic, partition); p != nil {
+		p.startOffset, p.endOffset = start, end
+	}
+}
+
+// SetLogDirError fails the log directory of a broker with the error, as an
+// offline disk would. A nil error clears it.
+func (c *Cluster) SetLogDirError(id int32, err *kerr.Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b := c.broker(id); b != nil {
+		b.logDirErr = 0
+		if err != nil {
+			b.logDirErr = err.Code
+		}
+	}
+}
+
+// SetTopicConfig sets a dynamic config of a topic
+func (c *Cluster) SetTopicConfig(topic, name, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t := c.topics[topic]; t != nil {
+		t.configs[name] = value
+	}
+}
+
+// TopicConfig returns a dynamic config of a topic
+func (c *Cluster) TopicConfig(topic, name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t := c.topics[topic]; t != nil {
+		v, ok := t.configs[name]
+		return v, ok
+	}
+	return "", false
+}
+
+// BrokerConfig returns a dynamic config of a broker
+func (c *Cluster) BrokerConfig(id int32, name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b := c.broker(id); b != nil {
+		v, ok := b.configs[name]
+		return v, ok
+	}
+	return "", false
+}
+
+// StopBroker stops a broker: it drops its connections and refuses new ones,
+// leaves the metadata, falls out of every ISR and hands over leadership of
+// its partitions
+func (c *Cluster) StopBroker(id int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.broker(id)
+	if b == nil || b.stopped {
+		return
+	}
+	b.stopped = true
+	b.closeConns()
+	c.eachPartition(func(p *partition) {
+		p.isr = slices.DeleteFunc(p.isr, func(r int32) bool { return r == id })
+		if p.leader == id {
+			c.electFromISR(p)
+		}
+	})
+}
+
+// StartBroker restarts a stopped broker. It rejoins the ISR of its partitions
+// at once, as if it caught up instantly, and leads the partitions that were
+// offline.
+func (c *Cluster) StartBroker(id int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.broker(id)
+	if b == nil || !b.stopped {
+		return
+	}
+	b.stopped = false
+	c.eachPartition(func(p *partition) {
+		if slices.Contains(p.replicas, id) && !slices.Contains(p.isr, id) {
+			p.isr = append(p.isr, id)
+		}
+		if p.leader < 0 {
+			c.electFromISR(p)
+		}
+	})
+}
+
+// CompleteReassignments completes every partition reassignment in progress,
+// as if the adding replicas caught up
+func (c *Cluster) CompleteReassignments() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eachPartition(func(p *partition) {
+		if len(p.adding) > 0 || len(p.removing) > 0 {
+			c.completeReassignment(p)
+		}
+	})
+}
+
+// completeReassignment moves a partition onto its target replicas
+func (c *Cluster) completeReassignment(p *partition) {
+	target := slices.DeleteFunc(slices.Clone(p.replicas), func(r int32) bool { return slices.Contains(p.removing, r) })
+	for _, r := range p.adding {
+		if b := c.broker(r); b != nil && !b.stopped && !slices.Contains(p.isr, r) {
+			p.isr = append(p.isr, r)
+		}
+	}
+	p.isr = slices.DeleteFunc(p.isr, func(r int32) bool { return !slices.Contains(target, r) })
+	for _, r := range p.removing {
+		delete(p.sizes, r)
+	}
+	p.replicas, p.adding, p.removing = target, nil, nil
+	if !slices.Contains(p.replicas, p.leader) {
+		c.electFromISR(p)
+	}
+}
+
+// electFromISR hands leadership to the first replica in the ISR that is
+// running, or takes the partition offline when there is none
+func (c *Cluster) electFromISR(p *partition) {
+	p.leader = -1
+	for _, r := range p.replicas {
+		if b := c.broker(r); b != nil && !b.stopped && slices.Contains(p.isr, r) {
+			p.leader = r
+			break
+		}
+	}
+	p.leaderEpoch++
+}
+
+// broker returns a broker by ID
+func (c *Cluster) broker(id int32) *broker {
+	for _, b := range c.brokers {
+		if b.id == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// running returns the brokers that are not stopped
+func (c *Cluster) running() []*broker {
+	var running []*broker
+	for _, b := range c.brokers {
+		if !b.stopped {
+			running = append(running, b)
+		}
+	}
+	return running
+}
+
+// controller returns the ID of the controller: the first running broker
+func (c *Cluster) controller() int32 {
+	if running := c.running(); len(running) > 0 {
+		return running[0].id
+	}
+	return -1
+}
+
+// partition returns a partition by topic and index
+func (c *Cluster) partition(topic string, partition int32) *partition {
+	t := c.topics[topic]
+	if t == nil || partition < 0 || int(partition) >= len(t.partitions) {
+		return nil
+	}
+	return t.partitions[partition]
+}
+
+// eachPartition calls fn for every partition of every topic
+func (c *Cluster) eachPartition(fn func(p *partition)) {
+	for _, t := range c.topics {
+		for _, p := range t.partitions {
+			fn(p)
+		}
+	}
+}
+
+// sortedTopics returns the topic names in order
+func (c *Cluster) sortedTopics() []string {
+	names := make([]string, 0, len(c.topics))
+	for name := range c.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closeConns closes the connections of a broker
+func (b *broker) closeConns() {
+	for conn := range b.conns {
+		_ = conn.Close()
+	}
+}
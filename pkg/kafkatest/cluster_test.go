@@ -0,0 +1,295 @@
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// admin returns an admin client of the cluster, closed when the test finishes
+func admin(t *testing.T, c *Cluster) *kadm.Client {
+	t.Helper()
+	adm, cleanup, err := kafkaclient.NewAdminClient(c.Config())
+	if err != nil {
+		t.Fatalf("failed to create admin client: %v", err)
+	}
+	t.Cleanup(cleanup)
+	return adm
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestMetadata(t *testing.T) {
+	c := Start(t, Options{Brokers: 3, Racks: []string{"us-east-1a", "us-east-1b"}})
+	c.AddTopic("orders", []int32{0, 1, 2}, []int32{1, 2, 0})
+	c.AddInternalTopic("__consumer_offsets", []int32{2, 0, 1})
+	ctx := testContext(t)
+
+	md, err := admin(t, c).Metadata(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if md.Cluster != DefaultClusterID || md.Controller != 0 || len(md.Brokers) != 3 {
+		t.Errorf("unexpected cluster: id %s, controller %d, %d brokers", md.Cluster, md.Controller, len(md.Brokers))
+	}
+	if rack := md.Brokers[0].Rack; rack == nil || *rack != "us-east-1a" {
+		t.Errorf("expected broker 0 in us-east-1a, got %v", rack)
+	}
+	if md.Brokers[2].Rack != nil {
+		t.Errorf("expected broker 2 without a rack, got %s", *md.Brokers[2].Rack)
+	}
+	orders := md.Topics["orders"]
+	if orders.Err != nil || len(orders.Partitions) != 2 || orders.Partitions[1].Leader != 1 {
+		t.Errorf("unexpected orders topic: %+v", orders)
+	}
+	if !md.Topics["__consumer_offsets"].IsInternal {
+		t.Error("expected __consumer_offsets to be internal")
+	}
+
+	md, err = admin(t, c).Metadata(ctx, "missing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(md.Topics["missing"].Err, kerr.UnknownTopicOrPartition) {
+		t.Errorf("expected an unknown topic, got %v", md.Topics["missing"].Err)
+	}
+}
+
+func TestStopBroker(t *testing.T) {
+	c := Start(t, Options{Brokers: 3})
+	c.AddTopic("orders", []int32{1, 2}, []int32{2, 1})
+	c.AddTopic("audit", []int32{1})
+	c.StopBroker(1)
+
+	md, err := admin(t, c).Metadata(testContext(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(md.Brokers) != 2 {
+		t.Errorf("expected broker 1 to leave the metadata, got %+v", md.Brokers)
+	}
+	p := md.Topics["orders"].Partitions[0]
+	if p.Leader != 2 || !slices.Equal(p.ISR, []int32{2}) || !slices.Equal(p.OfflineReplicas, []int32{1}) {
+		t.Errorf("expected broker 2 to take over orders-0, got %+v", p)
+	}
+	if leader := md.Topics["audit"].Partitions[0].Leader; leader != -1 {
+		t.Errorf("expected audit-0 offline, got leader %d", leader)
+	}
+
+	c.StartBroker(1)
+	state, _ := c.Partition("audit", 0)
+	if state.Leader != 1 || !slices.Equal(state.ISR, []int32{1}) {
+		t.Errorf("expected broker 1 to lead audit-0 again, got %+v", state)
+	}
+}
+
+func TestDescribeLogDirs(t *testing.T) {
+	c := Start(t, Options{Brokers: 2})
+	c.AddTopic("orders", []int32{0, 1}, []int32{1})
+	c.SetLogSize("orders", 0, 0, 1000)
+	c.SetLogSize("orders", 0, 1, 900)
+	c.SetLogSize("orders", 1, 1, 500)
+	c.SetLogDirError(1, kerr.KafkaStorageError)
+	ctx := testContext(t)
+
+	dirs, err := admin(t, c).DescribeAllLogDirs(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	size := dirs[0][DefaultLogDir].Topics["orders"][0].Size
+	if size != 1000 {
+		t.Errorf("expected orders-0 to take 1000 bytes on broker 0, got %d", size)
+	}
+	if _, ok := dirs[0][DefaultLogDir].Topics["orders"][1]; ok {
+		t.Error("expected broker 0 not to host orders-1")
+	}
+	if !errors.Is(dirs[1][DefaultLogDir].Err, kerr.KafkaStorageError) {
+		t.Errorf("expected the log dir of broker 1 to fail, got %v", dirs[1][DefaultLogDir].Err)
+	}
+}
+
+func TestReassignment(t *testing.T) {
+	c := Start(t, Options{Brokers: 4})
+	c.AddTopic("orders", []int32{0, 1}, []int32{1, 2})
+	adm := admin(t, c)
+	ctx := testContext(t)
+
+	var req kadm.AlterPartitionAssignmentsReq
+	req.Assign("orders", 0, []int32{2, 1})
+	req.Assign("orders", 1, []int32{3, 0})
+	resp, err := adm.AlterPartitionAssignments(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Each(func(r kadm.AlterPartitionAssignmentsResponse) {
+		if r.Err != nil {
+			t.Errorf("%s-%d: unexpected error: %v", r.Topic, r.Partition, r.Err)
+		}
+	})
+
+	listed, err := adm.ListPartitionReassignments(ctx, kadm.TopicsSet{"orders": {0: {}, 1: {}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, ok := listed["orders"][0]
+	if !ok || !slices.Equal(r.Replicas, []int32{2, 1, 0}) || !slices.Equal(r.AddingReplicas, []int32{2}) || !slices.Equal(r.RemovingReplicas, []int32{0}) {
+		t.Errorf("unexpected reassignment of orders-0: %+v", r)
+	}
+
+	c.CompleteReassignments()
+	state, _ := c.Partition("orders", 0)
+	if !slices.Equal(state.Replicas, []int32{2, 1}) || state.Leader != 2 || len(state.Adding) != 0 {
+		t.Errorf("expected orders-0 on 2,1 led by 2, got %+v", state)
+	}
+	state, _ = c.Partition("orders", 1)
+	if !slices.Equal(state.Replicas, []int32{3, 0}) || state.Leader != 3 {
+		t.Errorf("expected orders-1 on 3,0 led by 3, got %+v", state)
+	}
+
+	req = kadm.AlterPartitionAssignmentsReq{}
+	req.Assign("orders", 0, []int32{9})
+	resp, err = adm.AlterPartitionAssignments(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := resp["orders"][0]; !errors.Is(r.Err, kerr.InvalidReplicaAssignment) {
+		t.Errorf("expected an unknown broker to be rejected, got %v", r.Err)
+	}
+}
+
+func TestElectLeaders(t *testing.T) {
+	c := Start(t, Options{Brokers: 3})
+	c.AddTopic("orders", []int32{0, 1}, []int32{1, 2}, []int32{2, 0})
+	c.SetLeader("orders", 0, 1)
+	c.SetLeader("orders", 2, 0)
+	c.SetISR("orders", 2, 0)
+
+	results, err := admin(t, c).ElectLeaders(testContext(t), kadm.ElectPreferredReplica, kadm.TopicsSet{"orders": {0: {}, 1: {}, 2: {}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[int32]error{0: nil, 1: kerr.ElectionNotNeeded, 2: kerr.PreferredLeaderNotAvailable}
+	for partition, want := range expected {
+		if got := results["orders"][partition].Err; !errors.Is(got, want) && got != want {
+			t.Errorf("orders-%d: expected %v, got %v", partition, want, got)
+		}
+	}
+	if state, _ := c.Partition("orders", 0); state.Leader != 0 {
+		t.Errorf("expected broker 0 to lead orders-0 again, got %d", state.Leader)
+	}
+}
+
+func TestTopicsAndConfigs(t *testing.T) {
+	c := Start(t, Options{Brokers: 3})
+	adm := admin(t, c)
+	ctx := testContext(t)
+
+	created, err := adm.CreateTopic(ctx, 6, 2, map[string]*string{"min.insync.replicas": kmsg.StringPtr("2")}, "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Err != nil || created.NumPartitions != 6 || created.ReplicationFactor != 2 {
+		t.Errorf("unexpected created topic: %+v", created)
+	}
+	if _, err := adm.CreateTopic(ctx, 1, 1, nil, "orders"); !errors.Is(err, kerr.TopicAlreadyExists) {
+		t.Errorf("expected the topic to exist already, got %v", err)
+	}
+	if _, err := adm.CreatePartitions(ctx, 2, "orders"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if state, ok := c.Partition("orders", 7); !ok || len(state.Replicas) != 2 {
+		t.Errorf("expected 8 partitions of 2 replicas, got %+v", state)
+	}
+
+	_, err = adm.AlterTopicConfigs(ctx, []kadm.AlterConfig{
+		{Op: kadm.SetConfig, Name: "leader.replication.throttled.replicas", Value: kmsg.StringPtr("0:1")},
+		{Op: kadm.DeleteConfig, Name: "min.insync.replicas"},
+	}, "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := adm.AlterBrokerConfigs(ctx, []kadm.AlterConfig{{Op: kadm.SetConfig, Name: "leader.replication.throttled.rate", Value: kmsg.StringPtr("1048576")}}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate, _ := c.BrokerConfig(1, "leader.replication.throttled.rate"); rate != "1048576" {
+		t.Errorf("expected the throttle set on broker 1, got %q", rate)
+	}
+
+	described, err := adm.DescribeTopicConfigs(ctx, "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rc, err := described.On("orders", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configs := map[string]kadm.Config{}
+	for _, cfg := range rc.Configs {
+		configs[cfg.Key] = cfg
+	}
+	if cfg := configs["min.insync.replicas"]; cfg.MaybeValue() != "1" || cfg.Source != kmsg.ConfigSourceDefaultConfig {
+		t.Errorf("expected the default min.insync.replicas, got %+v", cfg)
+	}
+	if cfg := configs["leader.replication.throttled.replicas"]; cfg.MaybeValue() != "0:1" || cfg.Source != kmsg.ConfigSourceDynamicTopicConfig {
+		t.Errorf("expected the dynamic throttled replicas, got %+v", cfg)
+	}
+}
+
+func TestListOffsets(t *testing.T) {
+	c := Start(t, Options{Brokers: 2})
+	c.AddTopic("orders", []int32{0, 1}, []int32{1, 0})
+	c.SetOffsets("orders", 0, 10, 120)
+	c.SetOffsets("orders", 1, 0, 7)
+	adm := admin(t, c)
+	ctx := testContext(t)
+
+	ends, err := adm.ListEndOffsets(ctx, "orders")
+	if err != nil || ends.Error() != nil {
+		t.Fatalf("unexpected error: %v, %v", err, ends.Error())
+	}
+	if o, _ := ends.Lookup("orders", 0); o.Offset != 120 {
+		t.Errorf("expected orders-0 to end at 120, got %d", o.Offset)
+	}
+	if o, _ := ends.Lookup("orders", 1); o.Offset != 7 {
+		t.Errorf("expected orders-1 to end at 7, got %d", o.Offset)
+	}
+	starts, err := adm.ListStartOffsets(ctx, "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o, _ := starts.Lookup("orders", 0); o.Offset != 10 {
+		t.Errorf("expected orders-0 to start at 10, got %d", o.Offset)
+	}
+}
+
+func TestIntercept(t *testing.T) {
+	c := Start(t, Options{Brokers: 1})
+	c.AddTopic("orders", []int32{0})
+	c.Intercept(kmsg.DescribeLogDirs.Int16(), func(broker int32, req kmsg.Request) (kmsg.Response, bool) {
+		resp := req.ResponseKind().(*kmsg.DescribeLogDirsResponse)
+		resp.ErrorCode = kerr.ClusterAuthorizationFailed.Code
+		return resp, true
+	})
+
+	_, err := admin(t, c).DescribeAllLogDirs(testContext(t), nil)
+	if !errors.Is(err, kerr.ClusterAuthorizationFailed) {
+		t.Errorf("expected the intercepted error, got %v", err)
+	}
+
+	c.Intercept(kmsg.DescribeLogDirs.Int16(), nil)
+	if _, err := admin(t, c).DescribeAllLogDirs(testContext(t), nil); err != nil {
+		t.Errorf("unexpected error after removing the interceptor: %v", err)
+	}
+}
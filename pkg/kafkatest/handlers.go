@@ -0,0 +1,653 @@
+package kafkatest
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// supportedKeys are the requests the fake handles itself
+var supportedKeys = []kmsg.Key{
+	kmsg.ApiVersions,
+	kmsg.Metadata,
+	kmsg.DescribeLogDirs,
+	kmsg.CreateTopics,
+	kmsg.CreatePartitions,
+	kmsg.DescribeConfigs,
+	kmsg.IncrementalAlterConfigs,
+	kmsg.AlterPartitionAssignments,
+	kmsg.ListPartitionReassignments,
+	kmsg.ElectLeaders,
+	kmsg.ListOffsets,
+}
+
+// topicDefaults are the configs every topic reports unless overridden
+var topicDefaults = map[string]string{
+	"cleanup.policy":      "delete",
+	"min.insync.replicas": "1",
+	"retention.bytes":     "-1",
+	"retention.ms":        "604800000",
+}
+
+// brokerDefaults are the configs every broker reports unless overridden
+var brokerDefaults = map[string]string{
+	"default.replication.factor": "1",
+	"log.dirs":                   DefaultLogDir,
+	"min.insync.replicas":        "1",
+	"num.partitions":             "1",
+}
+
+// handle returns the response to a request, or nil to drop the connection
+func (c *Cluster) handle(brokerID int32, req kmsg.Request) kmsg.Response {
+	c.mu.Lock()
+	intercept := c.interceptors[req.Key()]
+	c.mu.Unlock()
+	if intercept != nil {
+		if resp, handled := intercept(brokerID, req); handled {
+			return resp
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch req := req.(type) {
+	case *kmsg.ApiVersionsRequest:
+		return c.apiVersions(req)
+	case *kmsg.MetadataRequest:
+		return c.metadata(req)
+	case *kmsg.DescribeLogDirsRequest:
+		return c.describeLogDirs(brokerID, req)
+	case *kmsg.CreateTopicsRequest:
+		return c.createTopics(req)
+	case *kmsg.CreatePartitionsRequest:
+		return c.createPartitions(req)
+	case *kmsg.DescribeConfigsRequest:
+		return c.describeConfigs(req)
+	case *kmsg.IncrementalAlterConfigsRequest:
+		return c.incrementalAlterConfigs(req)
+	case *kmsg.AlterPartitionAssignmentsRequest:
+		return c.alterPartitionAssignments(req)
+	case *kmsg.ListPartitionReassignmentsRequest:
+		return c.listPartitionReassignments(req)
+	case *kmsg.ElectLeadersRequest:
+		return c.electLeaders(req)
+	case *kmsg.ListOffsetsRequest:
+		return c.listOffsets(brokerID, req)
+	}
+	return nil
+}
+
+// apiVersions advertises the supported requests, and those intercepted, at
+// every version kmsg knows
+func (c *Cluster) apiVersions(req *kmsg.ApiVersionsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ApiVersionsResponse)
+	keys := make([]int16, 0, len(supportedKeys)+len(c.interceptors))
+	for _, key := range supportedKeys {
+		keys = append(keys, key.Int16())
+	}
+	for key := range c.interceptors {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		k := kmsg.NewApiVersionsResponseApiKey()
+		k.ApiKey = key
+		k.MaxVersion = kmsg.RequestForKey(key).MaxVersion()
+		resp.ApiKeys = append(resp.ApiKeys, k)
+	}
+	return resp
+}
+
+// metadata describes the running brokers and the requested topics, or all
+func (c *Cluster) metadata(req *kmsg.MetadataRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.MetadataResponse)
+	for _, b := range c.running() {
+		rb := kmsg.NewMetadataResponseBroker()
+		rb.NodeID, rb.Host, rb.Port, rb.Rack = b.id, "127.0.0.1", b.port, b.rack
+		resp.Brokers = append(resp.Brokers, rb)
+	}
+	resp.ClusterID = kmsg.StringPtr(c.opts.ClusterID)
+	resp.ControllerID = c.controller()
+
+	var names []string
+	if req.Topics == nil || (req.Version == 0 && len(req.Topics) == 0) {
+		names = c.sortedTopics()
+	}
+	for _, rt := range req.Topics {
+		if rt.Topic != nil {
+			names = append(names, *rt.Topic)
+			continue
+		}
+		for name, t := range c.topics {
+			if t.id == rt.TopicID {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		mt := kmsg.NewMetadataResponseTopic()
+		mt.Topic = kmsg.StringPtr(name)
+		t := c.topics[name]
+		if t == nil {
+			mt.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			resp.Topics = append(resp.Topics, mt)
+			continue
+		}
+		mt.TopicID, mt.IsInternal = t.id, t.internal
+		for i, p := range t.partitions {
+			mp := kmsg.NewMetadataResponseTopicPartition()
+			mp.Partition = int32(i)
+			mp.Leader, mp.LeaderEpoch = p.leader, p.leaderEpoch
+			mp.Replicas, mp.ISR = slices.Clone(p.replicas), slices.Clone(p.isr)
+			mp.OfflineReplicas = []int32{}
+			for _, r := range p.replicas {
+				if b := c.broker(r); b == nil || b.stopped {
+					mp.OfflineReplicas = append(mp.OfflineReplicas, r)
+				}
+			}
+			if p.leader < 0 {
+				mp.ErrorCode = kerr.LeaderNotAvailable.Code
+			}
+			mt.Partitions = append(mt.Partitions, mp)
+		}
+		resp.Topics = append(resp.Topics, mt)
+	}
+	return resp
+}
+
+// describeLogDirs describes the replicas the broker hosts in its single log
+// directory
+func (c *Cluster) describeLogDirs(brokerID int32, req *kmsg.DescribeLogDirsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.DescribeLogDirsResponse)
+	b := c.broker(brokerID)
+	dir := kmsg.NewDescribeLogDirsResponseDir()
+	dir.Dir, dir.ErrorCode = DefaultLogDir, b.logDirErr
+	if b.logDirErr != 0 {
+		resp.Dirs = append(resp.Dirs, dir)
+		return resp
+	}
+
+	requested := map[string][]int32{}
+	for _, rt := range req.Topics {
+		requested[rt.Topic] = rt.Partitions
+	}
+	for _, name := range c.sortedTopics() {
+		partitions, ok := requested[name]
+		if req.Topics != nil && !ok {
+			continue
+		}
+		dt := kmsg.NewDescribeLogDirsResponseDirTopic()
+		dt.Topic = name
+		for i, p := range c.topics[name].partitions {
+			if !slices.Contains(p.replicas, brokerID) || (req.Topics != nil && !slices.Contains(partitions, int32(i))) {
+				continue
+			}
+			dp := kmsg.NewDescribeLogDirsResponseDirTopicPartition()
+			dp.Partition, dp.Size = int32(i), p.sizes[brokerID]
+			dt.Partitions = append(dt.Partitions, dp)
+		}
+		if len(dt.Partitions) > 0 {
+			dir.Topics = append(dir.Topics, dt)
+		}
+	}
+	resp.Dirs = append(resp.Dirs, dir)
+	return resp
+}
+
+// assign spreads partitions round-robin over the running brokers, starting
+// each partition one broker further than the last
+func (c *Cluster) assign(first, count, rf int) [][]int32 {
+	running := c.running()
+	assignments := make([][]int32, count)
+	for i := range assignments {
+		for j := 0; j < rf; j++ {
+			assignments[i] = append(assignments[i], running[(first+i+j)%len(running)].id)
+		}
+	}
+	return assignments
+}
+
+// validReplicas reports whether replicas are known brokers without
+// duplicates
+func (c *Cluster) validReplicas(replicas []int32) bool {
+	if len(replicas) == 0 {
+		return false
+	}
+	for i, r := range replicas {
+		if c.broker(r) == nil || slices.Contains(replicas[:i], r) {
+			return false
+		}
+	}
+	return true
+}
+
+// createTopics creates topics from their partition count and replication
+// factor or their explicit assignments
+func (c *Cluster) createTopics(req *kmsg.CreateTopicsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.CreateTopicsResponse)
+	for _, rt := range req.Topics {
+		st := kmsg.NewCreateTopicsResponseTopic()
+		st.Topic = rt.Topic
+		var assignments [][]int32
+		switch {
+		case c.topics[rt.Topic] != nil:
+			st.ErrorCode = kerr.TopicAlreadyExists.Code
+		case len(rt.ReplicaAssignment) > 0:
+			sort.Slice(rt.ReplicaAssignment, func(i, j int) bool {
+				return rt.ReplicaAssignment[i].Partition < rt.ReplicaAssignment[j].Partition
+			})
+			for _, a := range rt.ReplicaAssignment {
+				if !c.validReplicas(a.Replicas) {
+					st.ErrorCode = kerr.InvalidReplicaAssignment.Code
+				}
+				assignments = append(assignments, a.Replicas)
+			}
+		default:
+			partitions, rf := int(rt.NumPartitions), int(rt.ReplicationFactor)
+			if partitions < 0 {
+				partitions = 1
+			}
+			if rf < 0 {
+				rf = min(3, len(c.running()))
+			}
+			switch {
+			case partitions == 0:
+				st.ErrorCode = kerr.InvalidPartitions.Code
+			case rf == 0 || rf > len(c.running()):
+				st.ErrorCode = kerr.InvalidReplicationFactor.Code
+			default:
+				assignments = c.assign(len(c.topics), partitions, rf)
+			}
+		}
+		if st.ErrorCode == 0 {
+			t := c.newTopic(rt.Topic, assignments)
+			for _, cfg := range rt.Configs {
+				if cfg.Value != nil {
+					t.configs[cfg.Name] = *cfg.Value
+				}
+			}
+			st.TopicID = t.id
+			st.NumPartitions = int32(len(assignments))
+			st.ReplicationFactor = int16(len(assignments[0]))
+			if !req.ValidateOnly {
+				c.topics[rt.Topic] = t
+			}
+		}
+		resp.Topics = append(resp.Topics, st)
+	}
+	return resp
+}
+
+// createPartitions adds partitions to topics
+func (c *Cluster) createPartitions(req *kmsg.CreatePartitionsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.CreatePartitionsResponse)
+	for _, rt := range req.Topics {
+		st := kmsg.NewCreatePartitionsResponseTopic()
+		st.Topic = rt.Topic
+		t := c.topics[rt.Topic]
+		switch {
+		case t == nil:
+			st.ErrorCode = kerr.UnknownTopicOrPartition.Code
+		case int(rt.Count) <= len(t.partitions):
+			st.ErrorCode = kerr.InvalidPartitions.Code
+		default:
+			var assignments [][]int32
+			if len(rt.Assignment) > 0 {
+				for _, a := range rt.Assignment {
+					if !c.validReplicas(a.Replicas) {
+						st.ErrorCode = kerr.InvalidReplicaAssignment.Code
+					}
+					assignments = append(assignments, a.Replicas)
+				}
+			} else {
+				assignments = c.assign(len(t.partitions), int(rt.Count)-len(t.partitions), len(t.partitions[0].replicas))
+			}
+			if st.ErrorCode == 0 && !req.ValidateOnly {
+				for _, replicas := range assignments {
+					t.partitions = append(t.partitions, c.newPartition(replicas))
+				}
+			}
+		}
+		resp.Topics = append(resp.Topics, st)
+	}
+	return resp
+}
+
+// configs returns the configs of a resource, dynamic ones over defaults, or
+// false when the resource does not exist
+func (c *Cluster) configs(resourceType kmsg.ConfigResourceType, name string) (map[string]string, map[string]string, bool) {
+	switch resourceType {
+	case kmsg.ConfigResourceTypeTopic:
+		if t := c.topics[name]; t != nil {
+			return t.configs, topicDefaults, true
+		}
+	case kmsg.ConfigResourceTypeBroker:
+		id, err := strconv.ParseInt(name, 10, 32)
+		if err != nil {
+			return nil, nil, false
+		}
+		if b := c.broker(int32(id)); b != nil {
+			return b.configs, brokerDefaults, true
+		}
+	}
+	return nil, nil, false
+}
+
+// describeConfigs describes the configs of topics and brokers
+func (c *Cluster) describeConfigs(req *kmsg.DescribeConfigsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.DescribeConfigsResponse)
+	for _, rr := range req.Resources {
+		sr := kmsg.NewDescribeConfigsResponseResource()
+		sr.ResourceType, sr.ResourceName = rr.ResourceType, rr.ResourceName
+		dynamic, defaults, ok := c.configs(rr.ResourceType, rr.ResourceName)
+		if !ok {
+			sr.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			if rr.ResourceType != kmsg.ConfigResourceTypeTopic {
+				sr.ErrorCode = kerr.InvalidRequest.Code
+			}
+			resp.Resources = append(resp.Resources, sr)
+			continue
+		}
+
+		dynamicSource := kmsg.ConfigSourceDynamicTopicConfig
+		defaultSource := kmsg.ConfigSourceDefaultConfig
+		if rr.ResourceType == kmsg.ConfigResourceTypeBroker {
+			dynamicSource = kmsg.ConfigSourceDynamicBrokerConfig
+			defaultSource = kmsg.ConfigSourceStaticBrokerConfig
+		}
+		names := make([]string, 0, len(dynamic)+len(defaults))
+		for name := range defaults {
+			names = append(names, name)
+		}
+		for name := range dynamic {
+			if _, ok := defaults[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if rr.ConfigNames != nil && !slices.Contains(rr.ConfigNames, name) {
+				continue
+			}
+			cfg := kmsg.NewDescribeConfigsResponseResourceConfig()
+			cfg.Name = name
+			if value, ok := dynamic[name]; ok {
+				cfg.Value, cfg.Source = kmsg.StringPtr(value), dynamicSource
+			} else {
+				cfg.Value, cfg.Source, cfg.IsDefault = kmsg.StringPtr(defaults[name]), defaultSource, true
+			}
+			sr.Configs = append(sr.Configs, cfg)
+		}
+		resp.Resources = append(resp.Resources, sr)
+	}
+	return resp
+}
+
+// incrementalAlterConfigs sets, deletes, appends to and subtracts from the
+// dynamic configs of topics and brokers
+func (c *Cluster) incrementalAlterConfigs(req *kmsg.IncrementalAlterConfigsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.IncrementalAlterConfigsResponse)
+	for _, rr := range req.Resources {
+		sr := kmsg.NewIncrementalAlterConfigsResponseResource()
+		sr.ResourceType, sr.ResourceName = rr.ResourceType, rr.ResourceName
+		dynamic, _, ok := c.configs(rr.ResourceType, rr.ResourceName)
+		if !ok {
+			sr.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			if rr.ResourceType != kmsg.ConfigResourceTypeTopic {
+				sr.ErrorCode = kerr.InvalidRequest.Code
+			}
+			resp.Resources = append(resp.Resources, sr)
+			continue
+		}
+		if req.ValidateOnly {
+			dynamic = make(map[string]string)
+		}
+		for _, cfg := range rr.Configs {
+			value := ""
+			if cfg.Value != nil {
+				value = *cfg.Value
+			}
+			switch cfg.Op {
+			case kmsg.IncrementalAlterConfigOpSet:
+				dynamic[cfg.Name] = value
+			case kmsg.IncrementalAlterConfigOpDelete:
+				delete(dynamic, cfg.Name)
+			case kmsg.IncrementalAlterConfigOpAppend:
+				list := splitList(dynamic[cfg.Name])
+				if !slices.Contains(list, value) {
+					list = append(list, value)
+				}
+				dynamic[cfg.Name] = strings.Join(list, ",")
+			case kmsg.IncrementalAlterConfigOpSubtract:
+				list := slices.DeleteFunc(splitList(dynamic[cfg.Name]), func(v string) bool { return v == value })
+				dynamic[cfg.Name] = strings.Join(list, ",")
+			default:
+				sr.ErrorCode = kerr.InvalidRequest.Code
+			}
+		}
+		resp.Resources = append(resp.Resources, sr)
+	}
+	return resp
+}
+
+// splitList splits a list config
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// alterPartitionAssignments starts, or with nil replicas cancels, partition
+// reassignments. The target replicas lead the replica list and the removing
+// replicas follow them until the reassignment completes.
+func (c *Cluster) alterPartitionAssignments(req *kmsg.AlterPartitionAssignmentsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.AlterPartitionAssignmentsResponse)
+	for _, rt := range req.Topics {
+		st := kmsg.NewAlterPartitionAssignmentsResponseTopic()
+		st.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			sp := kmsg.NewAlterPartitionAssignmentsResponseTopicPartition()
+			sp.Partition = rp.Partition
+			p := c.partition(rt.Topic, rp.Partition)
+			switch {
+			case p == nil:
+				sp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			case rp.Replicas == nil:
+				if len(p.adding) == 0 && len(p.removing) == 0 {
+					sp.ErrorCode = kerr.NoReassignmentInProgress.Code
+					break
+				}
+				c.cancelReassignment(p)
+			case !c.validReplicas(rp.Replicas):
+				sp.ErrorCode = kerr.InvalidReplicaAssignment.Code
+			default:
+				c.startReassignment(p, rp.Replicas)
+			}
+			st.Partitions = append(st.Partitions, sp)
+		}
+		resp.Topics = append(resp.Topics, st)
+	}
+	return resp
+}
+
+// startReassignment moves a partition towards the target replicas
+func (c *Cluster) startReassignment(p *partition, target []int32) {
+	original := slices.DeleteFunc(slices.Clone(p.replicas), func(r int32) bool { return slices.Contains(p.adding, r) })
+	p.isr = slices.DeleteFunc(p.isr, func(r int32) bool { return slices.Contains(p.adding, r) })
+	p.adding, p.removing = nil, nil
+	for _, r := range target {
+		if !slices.Contains(original, r) {
+			p.adding = append(p.adding, r)
+		}
+	}
+	for _, r := range original {
+		if !slices.Contains(target, r) {
+			p.removing = append(p.removing, r)
+		}
+	}
+	p.replicas = append(slices.Clone(target), p.removing...)
+	if c.opts.AutoCompleteReassignments || len(p.adding) == 0 {
+		c.completeReassignment(p)
+	}
+}
+
+// cancelReassignment reverts a partition to its replicas before the
+// reassignment
+func (c *Cluster) cancelReassignment(p *partition) {
+	p.isr = slices.DeleteFunc(p.isr, func(r int32) bool { return slices.Contains(p.adding, r) })
+	p.replicas = slices.DeleteFunc(p.replicas, func(r int32) bool { return slices.Contains(p.adding, r) })
+	p.adding, p.removing = nil, nil
+	if !slices.Contains(p.isr, p.leader) {
+		c.electFromISR(p)
+	}
+}
+
+// listPartitionReassignments lists the reassignments in progress of the
+// requested partitions, or all
+func (c *Cluster) listPartitionReassignments(req *kmsg.ListPartitionReassignmentsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ListPartitionReassignmentsResponse)
+	requested := map[string][]int32{}
+	for _, rt := range req.Topics {
+		requested[rt.Topic] = rt.Partitions
+	}
+	for _, name := range c.sortedTopics() {
+		partitions, ok := requested[name]
+		if req.Topics != nil && !ok {
+			continue
+		}
+		st := kmsg.NewListPartitionReassignmentsResponseTopic()
+		st.Topic = name
+		for i, p := range c.topics[name].partitions {
+			if len(p.adding) == 0 && len(p.removing) == 0 {
+				continue
+			}
+			if req.Topics != nil && !slices.Contains(partitions, int32(i)) {
+				continue
+			}
+			sp := kmsg.NewListPartitionReassignmentsResponseTopicPartition()
+			sp.Partition = int32(i)
+			sp.Replicas = slices.Clone(p.replicas)
+			sp.AddingReplicas = slices.Clone(p.adding)
+			sp.RemovingReplicas = slices.Clone(p.removing)
+			st.Partitions = append(st.Partitions, sp)
+		}
+		if len(st.Partitions) > 0 {
+			resp.Topics = append(resp.Topics, st)
+		}
+	}
+	return resp
+}
+
+// electLeaders runs preferred or unclean leader elections of the requested
+// partitions, or of all. Elections of all partitions leave out those that
+// did not need one, as Kafka does.
+func (c *Cluster) electLeaders(req *kmsg.ElectLeadersRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ElectLeadersResponse)
+	requested := req.Topics
+	if requested == nil {
+		for _, name := range c.sortedTopics() {
+			rt := kmsg.NewElectLeadersRequestTopic()
+			rt.Topic = name
+			for i := range c.topics[name].partitions {
+				rt.Partitions = append(rt.Partitions, int32(i))
+			}
+			requested = append(requested, rt)
+		}
+	}
+	for _, rt := range requested {
+		st := kmsg.NewElectLeadersResponseTopic()
+		st.Topic = rt.Topic
+		for _, partition := range rt.Partitions {
+			sp := kmsg.NewElectLeadersResponseTopicPartition()
+			sp.Partition = partition
+			if p := c.partition(rt.Topic, partition); p == nil {
+				sp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			} else if req.ElectionType == 1 {
+				sp.ErrorCode = c.electUnclean(p)
+			} else {
+				sp.ErrorCode = c.electPreferred(p)
+			}
+			if req.Topics == nil && sp.ErrorCode == kerr.ElectionNotNeeded.Code {
+				continue
+			}
+			st.Partitions = append(st.Partitions, sp)
+		}
+		if len(st.Partitions) > 0 {
+			resp.Topics = append(resp.Topics, st)
+		}
+	}
+	return resp
+}
+
+// electPreferred hands leadership of a partition to its preferred replica
+func (c *Cluster) electPreferred(p *partition) int16 {
+	if len(p.replicas) == 0 {
+		return kerr.PreferredLeaderNotAvailable.Code
+	}
+	preferred := p.replicas[0]
+	if p.leader == preferred {
+		return kerr.ElectionNotNeeded.Code
+	}
+	if b := c.broker(preferred); b == nil || b.stopped || !slices.Contains(p.isr, preferred) {
+		return kerr.PreferredLeaderNotAvailable.Code
+	}
+	p.leader = preferred
+	p.leaderEpoch++
+	return 0
+}
+
+// electUnclean hands leadership of an offline partition to any running
+// replica, in sync or not
+func (c *Cluster) electUnclean(p *partition) int16 {
+	if p.leader >= 0 {
+		return kerr.ElectionNotNeeded.Code
+	}
+	for _, r := range p.replicas {
+		if b := c.broker(r); b != nil && !b.stopped {
+			p.leader, p.isr = r, []int32{r}
+			p.leaderEpoch++
+			return 0
+		}
+	}
+	return kerr.EligibleLeadersNotAvailable.Code
+}
+
+// listOffsets returns the start or end offsets of partitions the broker leads
+func (c *Cluster) listOffsets(brokerID int32, req *kmsg.ListOffsetsRequest) kmsg.Response {
+	resp := req.ResponseKind().(*kmsg.ListOffsetsResponse)
+	for _, rt := range req.Topics {
+		st := kmsg.NewListOffsetsResponseTopic()
+		st.Topic = rt.Topic
+		for _, rp := range rt.Partitions {
+			sp := kmsg.NewListOffsetsResponseTopicPartition()
+			sp.Partition = rp.Partition
+			p := c.partition(rt.Topic, rp.Partition)
+			switch {
+			case p == nil:
+				sp.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			case p.leader != brokerID:
+				sp.ErrorCode = kerr.NotLeaderForPartition.Code
+			default:
+				// Records are not stored, so every timestamp but the
+				// earliest resolves to the end of the log
+				sp.Offset = p.endOffset
+				if rp.Timestamp == -2 {
+					sp.Offset = p.startOffset
+				}
+				sp.LeaderEpoch = p.leaderEpoch
+				sp.OldStyleOffsets = []int64{sp.Offset}
+			}
+			st.Partitions = append(st.Partitions, sp)
+		}
+		resp.Topics = append(resp.Topics, st)
+	}
+	return resp
+}
@@ -0,0 +1,152 @@
+package kafkatest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// maxRequestSize bounds the requests a fake broker reads
+const maxRequestSize = 100 << 20
+
+// serve accepts the connections of a broker until its listener closes
+func (c *Cluster) serve(b *broker) {
+	defer c.wg.Done()
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		// A stopped broker refuses connections
+		if c.closed || b.stopped {
+			c.mu.Unlock()
+			_ = conn.Close()
+			continue
+		}
+		b.conns[conn] = struct{}{}
+		c.wg.Add(1)
+		c.mu.Unlock()
+		go c.handleConn(b, conn)
+	}
+}
+
+// handleConn serves the requests of a connection in order until the client
+// closes it or a request cannot be handled
+func (c *Cluster) handleConn(b *broker, conn net.Conn) {
+	defer c.wg.Done()
+	defer func() {
+		c.mu.Lock()
+		delete(b.conns, conn)
+		c.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		req, correlationID, err := readRequest(r)
+		if err != nil {
+			return
+		}
+		resp := c.handle(b.id, req)
+		if resp == nil {
+			return
+		}
+		if _, err := conn.Write(encodeResponse(req, correlationID, resp)); err != nil {
+			return
+		}
+	}
+}
+
+// readRequest reads a request frame: its size, the request header and the
+// request body
+func readRequest(r io.Reader) (kmsg.Request, int32, error) {
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return nil, 0, err
+	}
+	size := int32(binary.BigEndian.Uint32(sizeBuf[:]))
+	if size < 8 || size > maxRequestSize {
+		return nil, 0, fmt.Errorf("invalid request size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, 0, err
+	}
+
+	key := int16(binary.BigEndian.Uint16(buf[0:]))
+	version := int16(binary.BigEndian.Uint16(buf[2:]))
+	correlationID := int32(binary.BigEndian.Uint32(buf[4:]))
+	req := kmsg.RequestForKey(key)
+	if req == nil {
+		return nil, 0, fmt.Errorf("unknown request key %d", key)
+	}
+	req.SetVersion(version)
+
+	// The client ID is a nullable string in every header version
+	body := buf[8:]
+	if len(body) < 2 {
+		return nil, 0, errors.New("truncated request header")
+	}
+	clientIDLen := int16(binary.BigEndian.Uint16(body))
+	body = body[2:]
+	if clientIDLen > 0 {
+		if len(body) < int(clientIDLen) {
+			return nil, 0, errors.New("truncated client ID")
+		}
+		body = body[clientIDLen:]
+	}
+	// Flexible request headers end with tagged fields
+	if req.IsFlexible() {
+		var err error
+		if body, err = skipTags(body); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := req.ReadFrom(body); err != nil {
+		return nil, 0, fmt.Errorf("invalid %s request: %w", kmsg.NameForKey(key), err)
+	}
+	return req, correlationID, nil
+}
+
+// skipTags skips a tagged field section
+func skipTags(b []byte) ([]byte, error) {
+	n, read := binary.Uvarint(b)
+	if read <= 0 {
+		return nil, errors.New("invalid tagged fields")
+	}
+	b = b[read:]
+	for i := uint64(0); i < n; i++ {
+		if _, read = binary.Uvarint(b); read <= 0 {
+			return nil, errors.New("invalid tag")
+		}
+		b = b[read:]
+		size, read := binary.Uvarint(b)
+		if read <= 0 || uint64(len(b)-read) < size {
+			return nil, errors.New("invalid tag size")
+		}
+		b = b[read+int(size):]
+	}
+	return b, nil
+}
+
+// encodeResponse frames a response: its size, the response header and the
+// response body
+func encodeResponse(req kmsg.Request, correlationID int32, resp kmsg.Response) []byte {
+	resp.SetVersion(req.GetVersion())
+	buf := make([]byte, 8, 256)
+	binary.BigEndian.PutUint32(buf[4:], uint32(correlationID))
+	// ApiVersions responses keep the first header version, so that clients
+	// can read them before knowing which versions the broker supports
+	if resp.IsFlexible() && req.Key() != kmsg.ApiVersions.Int16() {
+		buf = append(buf, 0)
+	}
+	buf = resp.AppendTo(buf)
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-4))
+	return buf
+}
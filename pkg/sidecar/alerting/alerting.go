@@ -0,0 +1,506 @@
+// Package alerting notifies Slack and PagerDuty of critical conditions the
+// sidecar observes: the broker staying unready, offline partitions, a data
+// volume nearly full and a TLS certificate about to expire. A condition is
+// announced once when it starts holding, optionally repeated, and resolved once
+// when it stops, so a flapping probe or a restarted loop does not page twice.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the alert evaluation loop in the freshness tracker
+const CheckName = "alerting"
+
+// Path serves the active alerts
+const Path = "/admin/alerts"
+
+// Conditions an alert is raised for
+const (
+	ConditionBrokerUnready     = "broker_unready"
+	ConditionOfflinePartitions = "offline_partitions"
+	ConditionDiskFull          = "disk_full"
+	ConditionCertExpiring      = "cert_expiring"
+)
+
+// Statuses of a notification
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// maxListedPartitions bounds the offline partitions named in a summary
+const maxListedPartitions = 10
+
+// AdminClient defines the Kafka admin operations needed to find offline
+// partitions. This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Readiness runs the broker's readiness checks
+type Readiness interface {
+	CheckReadiness(ctx context.Context) health.CheckResult
+}
+
+// DiskReporter reports data volumes whose usage is above the alert threshold
+type DiskReporter interface {
+	DiskError() error
+}
+
+// CertSource returns the inspected TLS certificates
+type CertSource interface {
+	Certificates() []certs.Certificate
+}
+
+// Options configures alerting
+type Options struct {
+	// Cluster prefixes the deduplication keys, so clusters sharing a PagerDuty
+	// service do not resolve each other's incidents. Empty omits it.
+	Cluster string
+	// Interval is how often the conditions are evaluated
+	Interval time.Duration
+	// UnreadyAfter is how long the broker must stay unready before it is
+	// alerted on. Zero disables the condition.
+	UnreadyAfter time.Duration
+	// OfflinePartitions alerts on partitions without a leader. The cluster-wide
+	// condition is only evaluated by the live broker with the lowest ID, so a
+	// single notification goes out per cluster.
+	OfflinePartitions bool
+	// CertMinValidity alerts on a certificate expiring sooner. Zero disables
+	// the condition.
+	CertMinValidity time.Duration
+	// RepeatInterval repeats the notification of a condition that still holds.
+	// Zero notifies once.
+	RepeatInterval time.Duration
+	// Template renders the message of a notification. Empty uses DefaultTemplate.
+	Template string
+	// Timeout bounds each readiness check and metadata request
+	Timeout time.Duration
+}
+
+// DefaultTemplate is the message of a notification unless Options.Template is set
+const DefaultTemplate = `[{{.Status}}] {{if .Cluster}}{{.Cluster}} {{end}}{{.Source}}: {{.Summary}}`
+
+// ParseTemplate parses the message template of the notifications. Its data is
+// an Alert.
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tpl, err := template.New("alert").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Catch references to unknown fields before the first alert
+	if err := tpl.Execute(&strings.Builder{}, Alert{}); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Alert is a condition that holds, or just stopped holding
+type Alert struct {
+	// Key identifies the alert across notifications and sidecars, e.g.
+	// broker-3/disk_full; PagerDuty deduplicates incidents on it
+	Key       string `json:"key"`
+	Condition string `json:"condition"`
+	Status    string `json:"status"`
+	Cluster   string `json:"cluster,omitempty"`
+	// Source is the broker that observed the condition, e.g. broker-3
+	Source  string `json:"source"`
+	Summary string `json:"summary"`
+	// Since is when the condition started holding
+	Since time.Time `json:"since"`
+	// Notified lists the sinks that announced the alert
+	Notified []string `json:"notified,omitempty"`
+}
+
+// active is an alert and when each sink last announced it
+type active struct {
+	alert    Alert
+	notified map[string]time.Time
+}
+
+// Alerter evaluates the conditions periodically and notifies the sinks of
+// those that start or stop holding
+type Alerter struct {
+	brokerID    int32
+	kafkaConfig kafkaclient.Config
+	opts        Options
+	template    *template.Template
+	sinks       []Sink
+	logger      *slog.Logger
+	tracker     *freshness.Tracker
+	clock       clock.Clock
+
+	clientFactory ClientFactory
+	readiness     Readiness
+	disks         DiskReporter
+	certs         CertSource
+
+	mu           sync.RWMutex
+	unreadySince time.Time
+	active       map[string]*active
+}
+
+// NewAlerter creates a new alerter notifying the sinks. The template must
+// have been validated with ParseTemplate.
+func NewAlerter(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, sinks []Sink, logger *slog.Logger) *Alerter {
+	// Validated in types.Initialize
+	tpl, _ := ParseTemplate(opts.Template)
+	a := &Alerter{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		template:    tpl,
+		sinks:       sinks,
+		logger:      logger,
+		clock:       clock.Real,
+		active:      make(map[string]*active),
+	}
+	a.clientFactory = a.defaultClientFactory
+	return a
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (a *Alerter) SetClientFactory(factory ClientFactory) {
+	a.clientFactory = factory
+}
+
+// SetReadiness enables the broker unready condition
+func (a *Alerter) SetReadiness(readiness Readiness) {
+	a.readiness = readiness
+}
+
+// SetDisks enables the disk nearly full condition
+func (a *Alerter) SetDisks(disks DiskReporter) {
+	a.disks = disks
+}
+
+// SetCerts enables the certificate expiring condition
+func (a *Alerter) SetCerts(certs CertSource) {
+	a.certs = certs
+}
+
+// SetTracker records every evaluation with the freshness tracker
+func (a *Alerter) SetTracker(tracker *freshness.Tracker) {
+	a.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (a *Alerter) SetClock(clk clock.Clock) {
+	a.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (a *Alerter) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(a.kafkaConfig)
+}
+
+// Alerts returns the active alerts, sorted by key
+func (a *Alerter) Alerts() []Alert {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	alerts := make([]Alert, 0, len(a.active))
+	for _, act := range a.active {
+		alert := act.alert
+		alert.Notified = notifiedSinks(act)
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Key < alerts[j].Key
+	})
+	return alerts
+}
+
+// Run evaluates the conditions every Interval until the context is cancelled
+func (a *Alerter) Run(ctx context.Context) {
+	ticker := a.clock.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	a.tracker.Register(CheckName)
+
+	for {
+		a.tracker.Record(CheckName, a.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// observation is the outcome of evaluating a condition
+type observation struct {
+	condition string
+	// cluster marks a cluster-wide condition, keyed without the broker
+	cluster bool
+	holds   bool
+	summary string
+	since   time.Time
+}
+
+// Step evaluates every enabled condition once and notifies the sinks of the
+// changes. A condition that cannot be evaluated keeps its previous state.
+func (a *Alerter) Step(ctx context.Context) error {
+	var observations []observation
+	var errs []error
+	if a.readiness != nil && a.opts.UnreadyAfter > 0 {
+		observations = append(observations, a.brokerUnready(ctx))
+	}
+	if a.opts.OfflinePartitions {
+		obs, evaluated, err := a.offlinePartitions(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to evaluate offline partitions: %w", err))
+		} else if evaluated {
+			observations = append(observations, obs)
+		}
+	}
+	if a.disks != nil {
+		observations = append(observations, a.diskFull())
+	}
+	if a.certs != nil && a.opts.CertMinValidity > 0 {
+		observations = append(observations, a.certExpiring())
+	}
+
+	for _, obs := range observations {
+		if err := a.apply(ctx, obs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// brokerUnready holds once readiness has failed for UnreadyAfter. A broker
+// waiting for its peers while the cluster forms after a cold start is not
+// unready, and restarts the grace period.
+func (a *Alerter) brokerUnready(ctx context.Context) observation {
+	ctx, cancel := context.WithTimeout(ctx, a.opts.Timeout)
+	result := a.readiness.CheckReadiness(ctx)
+	cancel()
+
+	now := a.clock.Now()
+	a.mu.Lock()
+	unready := !result.Healthy && !result.Forming
+	if !unready {
+		a.unreadySince = time.Time{}
+	} else if a.unreadySince.IsZero() {
+		a.unreadySince = now
+	}
+	since := a.unreadySince
+	a.mu.Unlock()
+
+	obs := observation{condition: ConditionBrokerUnready, since: since}
+	if unready && now.Sub(since) >= a.opts.UnreadyAfter {
+		obs.holds = true
+		obs.summary = fmt.Sprintf("broker %d not ready for %s: %s", a.brokerID, now.Sub(since).Round(time.Second), result.Message)
+	}
+	return obs
+}
+
+// offlinePartitions holds while any partition has no leader. Only the live
+// broker with the lowest ID evaluates it; the others report it as not evaluated.
+func (a *Alerter) offlinePartitions(ctx context.Context) (observation, bool, error) {
+	adm, cleanup, err := a.clientFactory()
+	if err != nil {
+		return observation{}, false, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, a.opts.Timeout)
+	defer cancel()
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return observation{}, false, err
+	}
+	if len(md.Brokers) == 0 || md.Brokers.NodeIDs()[0] != a.brokerID {
+		return observation{}, false, nil
+	}
+
+	var offline []string
+	md.Topics.EachPartition(func(p kadm.PartitionDetail) {
+		if p.Leader < 0 {
+			offline = append(offline, fmt.Sprintf("%s-%d", p.Topic, p.Partition))
+		}
+	})
+	obs := observation{condition: ConditionOfflinePartitions, cluster: true, since: a.clock.Now()}
+	if len(offline) > 0 {
+		sort.Strings(offline)
+		listed := offline
+		if len(listed) > maxListedPartitions {
+			listed = append(listed[:maxListedPartitions:maxListedPartitions], "...")
+		}
+		obs.holds = true
+		obs.summary = fmt.Sprintf("%d partitions offline: %s", len(offline), strings.Join(listed, ", "))
+	}
+	return obs, true, nil
+}
+
+// diskFull holds while a data volume is used above the alert threshold
+func (a *Alerter) diskFull() observation {
+	obs := observation{condition: ConditionDiskFull, since: a.clock.Now()}
+	if err := a.disks.DiskError(); err != nil {
+		obs.holds = true
+		obs.summary = "data volume nearly full: " + err.Error()
+	}
+	return obs
+}
+
+// certExpiring holds while an inspected certificate expires within
+// CertMinValidity. Certificates that could not be inspected are left to the
+// certificate monitor.
+func (a *Alerter) certExpiring() observation {
+	now := a.clock.Now()
+	obs := observation{condition: ConditionCertExpiring, since: now}
+	var expiring []string
+	for _, cert := range a.certs.Certificates() {
+		if cert.Error != "" {
+			continue
+		}
+		if remaining := cert.NotAfter.Sub(now); remaining < a.opts.CertMinValidity {
+			expiring = append(expiring, fmt.Sprintf("%s certificate %s expires at %s", cert.Source, cert.Subject, cert.NotAfter.Format(time.RFC3339)))
+		}
+	}
+	if len(expiring) > 0 {
+		obs.holds = true
+		obs.summary = strings.Join(expiring, "; ")
+	}
+	return obs
+}
+
+// key identifies the alert of a condition
+func (a *Alerter) key(obs observation) string {
+	scope := fmt.Sprintf("broker-%d", a.brokerID)
+	if obs.cluster {
+		scope = "cluster"
+	}
+	key := scope + "/" + obs.condition
+	if a.opts.Cluster != "" {
+		key = a.opts.Cluster + "/" + key
+	}
+	return key
+}
+
+// apply updates the alert of an observation and notifies the sinks that have
+// not announced it yet, are due to repeat it, or announced a condition that no
+// longer holds. Failed notifications are retried on the next evaluation.
+func (a *Alerter) apply(ctx context.Context, obs observation) error {
+	key := a.key(obs)
+	now := a.clock.Now()
+
+	a.mu.Lock()
+	act, ok := a.active[key]
+	if obs.holds && !ok {
+		act = &active{
+			alert: Alert{
+				Key:       key,
+				Condition: obs.condition,
+				Cluster:   a.opts.Cluster,
+				Source:    fmt.Sprintf("broker-%d", a.brokerID),
+				Since:     obs.since,
+			},
+			notified: make(map[string]time.Time),
+		}
+		a.active[key] = act
+		a.logger.Error("alerting: condition holds", "key", key, "summary", obs.summary)
+	}
+	if !ok && !obs.holds {
+		a.mu.Unlock()
+		return nil
+	}
+	alert := act.alert
+	if obs.holds {
+		act.alert.Summary = obs.summary
+		alert = act.alert
+		alert.Status = StatusFiring
+	} else {
+		alert.Status = StatusResolved
+	}
+	var due []Sink
+	for _, sink := range a.sinks {
+		last, announced := act.notified[sink.Name()]
+		switch {
+		case !obs.holds && announced:
+			due = append(due, sink)
+		case obs.holds && (!announced || (a.opts.RepeatInterval > 0 && now.Sub(last) >= a.opts.RepeatInterval)):
+			due = append(due, sink)
+		}
+	}
+	a.mu.Unlock()
+
+	message, err := a.render(alert)
+	if err != nil {
+		return fmt.Errorf("failed to render alert %s: %w", key, err)
+	}
+	var errs []error
+	for _, sink := range due {
+		if err := sink.Notify(ctx, alert, message); err != nil {
+			a.logger.Warn("alerting: failed to notify", "sink", sink.Name(), "key", key, "status", alert.Status, "error", err)
+			errs = append(errs, fmt.Errorf("failed to notify %s of %s: %w", sink.Name(), key, err))
+			continue
+		}
+		a.mu.Lock()
+		if obs.holds {
+			act.notified[sink.Name()] = now
+		} else {
+			delete(act.notified, sink.Name())
+		}
+		a.mu.Unlock()
+	}
+
+	if !obs.holds {
+		a.mu.Lock()
+		if len(act.notified) == 0 {
+			delete(a.active, key)
+			a.logger.Info("alerting: condition resolved", "key", key)
+		}
+		a.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// render renders the message of a notification
+func (a *Alerter) render(alert Alert) (string, error) {
+	var b strings.Builder
+	if err := a.template.Execute(&b, alert); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// notifiedSinks returns the names of the sinks that announced the alert, sorted
+func notifiedSinks(act *active) []string {
+	var names []string
+	for name := range act.notified {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StatusHandler handles GET /admin/alerts requests
+func (a *Alerter) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, a.Alerts())
+}
@@ -0,0 +1,372 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+// MockReadiness is a mock implementation of Readiness for testing
+type MockReadiness struct {
+	Result health.CheckResult
+}
+
+func (m *MockReadiness) CheckReadiness(context.Context) health.CheckResult {
+	return m.Result
+}
+
+// MockDisks is a mock implementation of DiskReporter for testing
+type MockDisks struct {
+	Err error
+}
+
+func (m *MockDisks) DiskError() error {
+	return m.Err
+}
+
+// MockCerts is a mock implementation of CertSource for testing
+type MockCerts struct {
+	Certs []certs.Certificate
+}
+
+func (m *MockCerts) Certificates() []certs.Certificate {
+	return m.Certs
+}
+
+// notification is a message a MockSink received
+type notification struct {
+	key     string
+	status  string
+	message string
+}
+
+// MockSink records notifications, failing while Err is set
+type MockSink struct {
+	name string
+	Err  error
+	sent []notification
+}
+
+func (m *MockSink) Name() string {
+	return m.name
+}
+
+func (m *MockSink) Notify(_ context.Context, alert Alert, message string) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, notification{key: alert.Key, status: alert.Status, message: message})
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func newTestAlerter(brokerID int32, opts Options, sinks ...Sink) (*Alerter, *clock.Fake) {
+	if opts.Interval == 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Second
+	}
+	a := NewAlerter(brokerID, kafkaclient.Config{}, opts, sinks, testLogger())
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	a.SetClock(clk)
+	return a, clk
+}
+
+func statuses(sent []notification) string {
+	var s []string
+	for _, n := range sent {
+		s = append(s, n.status)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestBrokerUnready(t *testing.T) {
+	sink := &MockSink{name: "test"}
+	a, clk := newTestAlerter(3, Options{UnreadyAfter: 5 * time.Minute, RepeatInterval: time.Hour}, sink)
+	readiness := &MockReadiness{Result: health.CheckResult{Healthy: false, Message: "log directory offline"}}
+	a.SetReadiness(readiness)
+	ctx := context.Background()
+
+	steps := []struct {
+		advance  time.Duration
+		healthy  bool
+		expected string
+	}{
+		{advance: 0, expected: ""},
+		{advance: 4 * time.Minute, expected: ""},
+		{advance: time.Minute, expected: "firing"},
+		// Deduplicated until the repeat interval
+		{advance: 30 * time.Minute, expected: "firing"},
+		{advance: 30 * time.Minute, expected: "firing,firing"},
+		{advance: time.Minute, healthy: true, expected: "firing,firing,resolved"},
+		{advance: time.Minute, healthy: true, expected: "firing,firing,resolved"},
+		// Unready again restarts the grace period
+		{advance: time.Minute, expected: "firing,firing,resolved"},
+	}
+	for i, step := range steps {
+		clk.Advance(step.advance)
+		readiness.Result.Healthy = step.healthy
+		if err := a.Step(ctx); err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if got := statuses(sink.sent); got != step.expected {
+			t.Errorf("step %d: expected notifications %q, got %q", i, step.expected, got)
+		}
+	}
+
+	first := sink.sent[0]
+	if first.key != "broker-3/broker_unready" {
+		t.Errorf("expected key broker-3/broker_unready, got %s", first.key)
+	}
+	expected := "[firing] broker-3: broker 3 not ready for 5m0s: log directory offline"
+	if first.message != expected {
+		t.Errorf("expected message %q, got %q", expected, first.message)
+	}
+	if resolved := sink.sent[2].message; !strings.HasPrefix(resolved, "[resolved] broker-3: broker 3 not ready") {
+		t.Errorf("expected the resolution to carry the last summary, got %q", resolved)
+	}
+}
+
+func TestBrokerUnreadyForming(t *testing.T) {
+	sink := &MockSink{name: "test"}
+	a, clk := newTestAlerter(3, Options{UnreadyAfter: 5 * time.Minute, RepeatInterval: time.Hour}, sink)
+	readiness := &MockReadiness{Result: health.CheckResult{Healthy: false, Forming: true, Message: "cluster forming"}}
+	a.SetReadiness(readiness)
+	ctx := context.Background()
+
+	steps := []struct {
+		advance  time.Duration
+		forming  bool
+		expected string
+	}{
+		{advance: 0, forming: true, expected: ""},
+		// Forming never fires, however long it takes
+		{advance: 10 * time.Minute, forming: true, expected: ""},
+		// The grace period starts once the broker is unready without forming
+		{advance: time.Minute, expected: ""},
+		{advance: 4 * time.Minute, expected: ""},
+		{advance: time.Minute, expected: "firing"},
+		// Forming again resolves the alert
+		{advance: time.Minute, forming: true, expected: "firing,resolved"},
+	}
+	for i, step := range steps {
+		clk.Advance(step.advance)
+		readiness.Result.Forming = step.forming
+		if err := a.Step(ctx); err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if got := statuses(sink.sent); got != step.expected {
+			t.Errorf("step %d: expected notifications %q, got %q", i, step.expected, got)
+		}
+	}
+}
+
+func TestOfflinePartitions(t *testing.T) {
+	md := kadm.Metadata{
+		Brokers: kadm.BrokerDetails{{NodeID: 2}, {NodeID: 1}, {NodeID: 3}},
+		Topics: kadm.TopicDetails{
+			"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+				0: {Topic: "orders", Partition: 0, Leader: 1},
+				1: {Topic: "orders", Partition: 1, Leader: -1},
+			}},
+		},
+	}
+	factory := func() (AdminClient, func(), error) {
+		return &MockAdminClient{MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return md, nil
+		}}, func() {}, nil
+	}
+
+	tests := []struct {
+		name     string
+		brokerID int32
+		expected int
+	}{
+		{name: "lowest broker", brokerID: 1, expected: 1},
+		{name: "other broker", brokerID: 2, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &MockSink{name: "test"}
+			a, _ := newTestAlerter(tt.brokerID, Options{Cluster: "prod", OfflinePartitions: true}, sink)
+			a.SetClientFactory(factory)
+			if err := a.Step(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(sink.sent) != tt.expected {
+				t.Fatalf("expected %d notifications, got %+v", tt.expected, sink.sent)
+			}
+			if tt.expected == 0 {
+				return
+			}
+			if sink.sent[0].key != "prod/cluster/offline_partitions" {
+				t.Errorf("expected a cluster-wide key, got %s", sink.sent[0].key)
+			}
+			if expected := "[firing] prod broker-1: 1 partitions offline: orders-1"; sink.sent[0].message != expected {
+				t.Errorf("expected message %q, got %q", expected, sink.sent[0].message)
+			}
+		})
+	}
+}
+
+func TestMetadataErrorKeepsState(t *testing.T) {
+	sink := &MockSink{name: "test"}
+	a, _ := newTestAlerter(1, Options{OfflinePartitions: true}, sink)
+	fail := false
+	a.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			if fail {
+				return kadm.Metadata{}, errors.New("timeout")
+			}
+			return kadm.Metadata{
+				Brokers: kadm.BrokerDetails{{NodeID: 1}},
+				Topics: kadm.TopicDetails{"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+					0: {Topic: "orders", Partition: 0, Leader: -1},
+				}}},
+			}, nil
+		}}, func() {}, nil
+	})
+
+	if err := a.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fail = true
+	if err := a.Step(context.Background()); err == nil {
+		t.Error("expected the metadata error")
+	}
+	if got := statuses(sink.sent); got != "firing" {
+		t.Errorf("expected the alert kept without a resolution, got %q", got)
+	}
+	if alerts := a.Alerts(); len(alerts) != 1 || alerts[0].Condition != ConditionOfflinePartitions {
+		t.Errorf("expected the offline partitions alert active, got %+v", alerts)
+	}
+}
+
+func TestDiskAndCerts(t *testing.T) {
+	sink := &MockSink{name: "test"}
+	a, clk := newTestAlerter(1, Options{CertMinValidity: 7 * 24 * time.Hour}, sink)
+	disks := &MockDisks{Err: errors.New("/var/lib/kafka is 93.0% used, above 90.0%")}
+	a.SetDisks(disks)
+	a.SetCerts(&MockCerts{Certs: []certs.Certificate{
+		{Source: certs.SourceBroker, Subject: "CN=kafka-1", NotAfter: clk.Now().Add(48 * time.Hour)},
+		{Source: certs.SourceClient, Subject: "CN=sidecar", NotAfter: clk.Now().Add(90 * 24 * time.Hour)},
+		{Source: certs.SourceBroker, Error: "connection refused"},
+	}})
+
+	if err := a.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alerts := a.Alerts()
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %+v", alerts)
+	}
+	if alerts[0].Condition != ConditionCertExpiring || alerts[0].Summary != "broker certificate CN=kafka-1 expires at 2026-01-03T00:00:00Z" {
+		t.Errorf("unexpected certificate alert %+v", alerts[0])
+	}
+	if alerts[1].Condition != ConditionDiskFull || !strings.Contains(alerts[1].Summary, "93.0% used") {
+		t.Errorf("unexpected disk alert %+v", alerts[1])
+	}
+	if len(alerts[1].Notified) != 1 || alerts[1].Notified[0] != "test" {
+		t.Errorf("expected the disk alert notified to the sink, got %v", alerts[1].Notified)
+	}
+
+	disks.Err = nil
+	if err := a.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alerts := a.Alerts(); len(alerts) != 1 || alerts[0].Condition != ConditionCertExpiring {
+		t.Errorf("expected only the certificate alert left, got %+v", alerts)
+	}
+	if got := statuses(sink.sent); got != "firing,firing,resolved" {
+		t.Errorf("expected the disk alert resolved, got %q", got)
+	}
+}
+
+func TestFailedNotificationRetried(t *testing.T) {
+	slack := &MockSink{name: SinkSlack}
+	pagerDuty := &MockSink{name: SinkPagerDuty, Err: errors.New("503 Service Unavailable")}
+	a, _ := newTestAlerter(1, Options{}, slack, pagerDuty)
+	disks := &MockDisks{Err: errors.New("full")}
+	a.SetDisks(disks)
+	ctx := context.Background()
+
+	if err := a.Step(ctx); err == nil {
+		t.Error("expected the failed notification reported")
+	}
+	pagerDuty.Err = nil
+	if err := a.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slack.sent) != 1 || len(pagerDuty.sent) != 1 {
+		t.Errorf("expected one notification per sink, got slack %+v and pagerduty %+v", slack.sent, pagerDuty.sent)
+	}
+
+	// A resolution that fails keeps the alert until it is delivered
+	disks.Err = nil
+	pagerDuty.Err = errors.New("503 Service Unavailable")
+	_ = a.Step(ctx)
+	if alerts := a.Alerts(); len(alerts) != 1 || len(alerts[0].Notified) != 1 || alerts[0].Notified[0] != SinkPagerDuty {
+		t.Fatalf("expected the alert kept for pagerduty only, got %+v", alerts)
+	}
+	pagerDuty.Err = nil
+	if err := a.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statuses(slack.sent) != "firing,resolved" || statuses(pagerDuty.sent) != "firing,resolved" {
+		t.Errorf("expected one resolution per sink, got slack %+v and pagerduty %+v", slack.sent, pagerDuty.sent)
+	}
+	if alerts := a.Alerts(); len(alerts) != 0 {
+		t.Errorf("expected no alert left, got %+v", alerts)
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		expectErr bool
+	}{
+		{name: "default"},
+		{name: "custom", template: `{{.Condition}} on {{.Source}} since {{.Since.Format "15:04"}}`},
+		{name: "syntax error", template: `{{.Summary`, expectErr: true},
+		{name: "unknown field", template: `{{.Broker}}`, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.template)
+			if tt.expectErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
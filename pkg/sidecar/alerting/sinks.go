@@ -0,0 +1,114 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+// Sink names
+const (
+	SinkSlack     = "slack"
+	SinkPagerDuty = "pagerduty"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxSummaryLength is the longest summary PagerDuty accepts
+const maxSummaryLength = 1024
+
+// Sink delivers notifications
+type Sink interface {
+	// Name identifies the sink in the alert state and logs
+	Name() string
+	// Notify announces a firing alert or resolves it, with its rendered message
+	Notify(ctx context.Context, alert Alert, message string) error
+}
+
+// Slack posts notifications to a Slack incoming webhook
+type Slack struct {
+	sender *webhook.Sender
+}
+
+// NewSlack creates a Slack sink posting to the incoming webhook of the sender
+func NewSlack(sender *webhook.Sender) *Slack {
+	return &Slack{sender: sender}
+}
+
+// slackMessage is the body of an incoming webhook request
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Name implements Sink
+func (s *Slack) Name() string {
+	return SinkSlack
+}
+
+// Notify implements Sink
+func (s *Slack) Notify(ctx context.Context, _ Alert, message string) error {
+	return s.sender.Send(ctx, slackMessage{Text: message})
+}
+
+// PagerDuty triggers and resolves PagerDuty incidents through the Events API
+// v2, deduplicated on the alert key
+type PagerDuty struct {
+	sender     *webhook.Sender
+	routingKey string
+}
+
+// NewPagerDuty creates a PagerDuty sink posting events for the integration's
+// routing key to the Events API endpoint of the sender
+func NewPagerDuty(sender *webhook.Sender, routingKey string) *PagerDuty {
+	return &PagerDuty{sender: sender, routingKey: routingKey}
+}
+
+// pagerDutyEvent is an Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes a triggered incident
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	Timestamp     string `json:"timestamp"`
+	Component     string `json:"component"`
+	Class         string `json:"class"`
+	CustomDetails Alert  `json:"custom_details"`
+}
+
+// Name implements Sink
+func (p *PagerDuty) Name() string {
+	return SinkPagerDuty
+}
+
+// Notify implements Sink
+func (p *PagerDuty) Notify(ctx context.Context, alert Alert, message string) error {
+	event := pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "resolve",
+		DedupKey:    alert.Key,
+	}
+	if alert.Status == StatusFiring {
+		if len(message) > maxSummaryLength {
+			message = message[:maxSummaryLength]
+		}
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:       message,
+			Source:        alert.Source,
+			Severity:      "critical",
+			Timestamp:     alert.Since.UTC().Format("2006-01-02T15:04:05.000Z"),
+			Component:     "kafka",
+			Class:         alert.Condition,
+			CustomDetails: alert,
+		}
+	}
+	return p.sender.Send(ctx, event)
+}
@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+// capture starts a server recording the JSON bodies posted to it
+func capture(t *testing.T) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid body %s: %v", data, err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func sender(url string) *webhook.Sender {
+	return webhook.NewSender(webhook.Options{URL: url, Attempts: 1, Timeout: time.Second}, testLogger())
+}
+
+func TestSlack(t *testing.T) {
+	srv, bodies := capture(t)
+	sink := NewSlack(sender(srv.URL))
+	if err := sink.Notify(context.Background(), Alert{Status: StatusFiring}, "[firing] broker-1: disk full"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*bodies) != 1 || (*bodies)[0]["text"] != "[firing] broker-1: disk full" {
+		t.Errorf("expected the message as text, got %+v", *bodies)
+	}
+}
+
+func TestPagerDuty(t *testing.T) {
+	srv, bodies := capture(t)
+	sink := NewPagerDuty(sender(srv.URL), "routing-key")
+	alert := Alert{
+		Key:       "broker-1/disk_full",
+		Condition: ConditionDiskFull,
+		Status:    StatusFiring,
+		Source:    "broker-1",
+		Since:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	ctx := context.Background()
+	if err := sink.Notify(ctx, alert, strings.Repeat("x", 2000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alert.Status = StatusResolved
+	if err := sink.Notify(ctx, alert, "resolved"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*bodies) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(*bodies))
+	}
+	trigger, resolve := (*bodies)[0], (*bodies)[1]
+	if trigger["event_action"] != "trigger" || trigger["dedup_key"] != "broker-1/disk_full" || trigger["routing_key"] != "routing-key" {
+		t.Errorf("unexpected trigger event %+v", trigger)
+	}
+	payload, _ := trigger["payload"].(map[string]any)
+	if summary, _ := payload["summary"].(string); len(summary) != maxSummaryLength {
+		t.Errorf("expected the summary truncated to %d, got %d", maxSummaryLength, len(summary))
+	}
+	if payload["severity"] != "critical" || payload["source"] != "broker-1" || payload["timestamp"] != "2026-01-01T00:00:00.000Z" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	if resolve["event_action"] != "resolve" || resolve["dedup_key"] != "broker-1/disk_full" || resolve["payload"] != nil {
+		t.Errorf("unexpected resolve event %+v", resolve)
+	}
+}
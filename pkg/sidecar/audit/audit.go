@@ -0,0 +1,257 @@
+// Package audit keeps a trail of the requests made to the sidecar's admin API:
+// who made them, what they asked for, when, and how they ended. Records are
+// appended as JSON lines to a file rotated by size, so the trail survives
+// restarts and can be shipped by a log agent, and are served back at
+// /admin/audit.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Path serves the audit trail
+const Path = "/admin/audit"
+
+// Results of an audited request
+const (
+	ResultSuccess = "success"
+	ResultDenied  = "denied"
+	ResultFailure = "failure"
+)
+
+// Record is an audited request
+type Record struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	// Principal is who the bearer token identifies: the subject of a JWT or
+	// the fingerprint of a static token. Without a valid token, it is the
+	// common name of a verified client certificate, if any.
+	Principal  string `json:"principal,omitempty"`
+	RemoteAddr string `json:"remoteAddr"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	// Route is the path template of the matched route, e.g.
+	// /admin/decommission/{brokerId:[0-9]+}
+	Route  string `json:"route,omitempty"`
+	Query  string `json:"query,omitempty"`
+	Status int    `json:"status"`
+	Result string `json:"result"`
+	// Body is the request body of a mutation, with secrets masked: its JSON
+	// value, or its text when it is not JSON. BodyTruncated replaces a body
+	// longer than Options.MaxBodyBytes.
+	Body          any  `json:"body,omitempty"`
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
+	// Error is the start of the response body of a failed request
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// Options configures the audit log
+type Options struct {
+	// Path is the file records are appended to
+	Path string
+	// MaxSize is the size in bytes at which the file is rotated
+	MaxSize int64
+	// MaxBackups is how many rotated files are kept, as Path.1 (the newest)
+	// to Path.N
+	MaxBackups int
+	// MaxBodyBytes bounds the request body recorded for a mutation
+	MaxBodyBytes int
+	// Reads records successful reads of protected endpoints too. Mutations and
+	// denied requests are always recorded.
+	Reads bool
+}
+
+// Log appends audit records to a file rotated by size
+type Log struct {
+	opts   Options
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the audit log for appending, creating the file and its directory
+// when missing
+func Open(opts Options, logger *slog.Logger) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	l := &Log{opts: opts, logger: logger}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the current file and reads its size
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// Append writes a record and syncs it to disk, rotating the file first when
+// the record would take it past MaxSize
+func (l *Log) Append(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts the rotated files up by one, dropping the oldest, and starts
+// a new file
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+	if l.opts.MaxBackups > 0 {
+		for i := l.opts.MaxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to rotate audit log: %w", err)
+			}
+		}
+		if err := os.Rename(l.opts.Path, l.backup(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(l.opts.Path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return l.open()
+}
+
+// backup is the path of the i-th newest rotated file
+func (l *Log) backup(i int) string {
+	return l.opts.Path + "." + strconv.Itoa(i)
+}
+
+// Close closes the file. Records appended afterwards fail.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Filter selects records
+type Filter struct {
+	// Since keeps records at or after it
+	Since time.Time
+	// Principal keeps records of the principal
+	Principal string
+	// Result keeps records with the result
+	Result string
+	// Limit keeps the newest records up to it
+	Limit int
+}
+
+// matches reports whether the filter keeps the record
+func (f Filter) matches(r Record) bool {
+	return !r.Time.Before(f.Since) &&
+		(f.Principal == "" || r.Principal == f.Principal) &&
+		(f.Result == "" || r.Result == f.Result)
+}
+
+// Records returns the records the filter selects from the current and rotated
+// files, newest first. Lines that cannot be parsed, e.g. a record cut short by
+// a crash, are skipped.
+func (l *Log) Records(filter Filter) ([]Record, error) {
+	// Rotation renames files, so they are read without a rotation in between
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var records []Record
+	files := []string{l.opts.Path}
+	for i := 1; i <= l.opts.MaxBackups; i++ {
+		files = append(files, l.backup(i))
+	}
+	for _, path := range files {
+		fileRecords, err := readRecords(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		for i := len(fileRecords) - 1; i >= 0; i-- {
+			if !filter.matches(fileRecords[i]) {
+				continue
+			}
+			records = append(records, fileRecords[i])
+			if filter.Limit > 0 && len(records) == filter.Limit {
+				return records, nil
+			}
+		}
+	}
+	return records, nil
+}
+
+// readRecords parses the records of a file, oldest first
+func readRecords(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	// A record holds at most MaxBodyBytes of body, escaped
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func openLog(t *testing.T, opts Options) *Log {
+	t.Helper()
+	if opts.Path == "" {
+		opts.Path = filepath.Join(t.TempDir(), "audit", "audit.log")
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = 1 << 20
+	}
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = 1024
+	}
+	l, err := Open(opts, testLogger())
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	// Every record is about 150 bytes, so each file holds two
+	l := openLog(t, Options{Path: path, MaxSize: 350, MaxBackups: 2})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 8; i++ {
+		record := Record{Time: start.Add(time.Duration(i) * time.Minute), Method: "POST", Path: fmt.Sprintf("/admin/op/%d", i), Status: 200, Result: ResultSuccess}
+		if err := l.Append(record); err != nil {
+			t.Fatalf("failed to append record %d: %v", i, err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 350 {
+			t.Errorf("expected %s within the max size, got %d bytes", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 rotated files, got %v", err)
+	}
+
+	records, err := l.Records(Filter{})
+	if err != nil {
+		t.Fatalf("failed to read records: %v", err)
+	}
+	if len(records) != 6 || records[0].Path != "/admin/op/7" || records[5].Path != "/admin/op/2" {
+		t.Errorf("expected records 7 down to 2, got %+v", records)
+	}
+
+	// A reopened log keeps appending to the current file
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l = openLog(t, Options{Path: path, MaxSize: 350, MaxBackups: 2})
+	if err := l.Append(Record{Time: start.Add(time.Hour), Path: "/admin/op/8"}); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := l.Records(Filter{Limit: 1}); len(records) != 1 || records[0].Path != "/admin/op/8" {
+		t.Errorf("expected the record appended after reopening, got %+v", records)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	l := openLog(t, Options{})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Time: start, Principal: "alice", Result: ResultSuccess},
+		{Time: start.Add(time.Minute), Principal: "bob", Result: ResultDenied},
+		{Time: start.Add(2 * time.Minute), Principal: "alice", Result: ResultFailure},
+		{Time: start.Add(3 * time.Minute), Principal: "alice", Result: ResultSuccess},
+	}
+	for _, r := range records {
+		if err := l.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A record cut short by a crash is skipped
+	f, err := os.OpenFile(l.opts.Path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"time":"2026-01-01T00:0`)
+	_ = f.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		expected []string
+	}{
+		{name: "all", expected: []string{"alice", "alice", "bob", "alice"}},
+		{name: "principal", query: "?principal=alice", expected: []string{"alice", "alice", "alice"}},
+		{name: "result", query: "?result=denied", expected: []string{"bob"}},
+		{name: "since", query: "?since=2026-01-01T00:02:00Z", expected: []string{"alice", "alice"}},
+		{name: "limit", query: "?limit=1", expected: []string{"alice"}},
+		{name: "invalid result", query: "?result=ok", status: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=yesterday", status: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=5000", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			l.Handler(rec, httptest.NewRequest(http.MethodGet, Path+tt.query, nil))
+			if tt.status != 0 {
+				if rec.Code != tt.status {
+					t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+				}
+				return
+			}
+			var resp Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var principals []string
+			for _, r := range resp.Records {
+				principals = append(principals, r.Principal)
+			}
+			if fmt.Sprint(principals) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, principals)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+)
+
+const (
+	// defaultLimit is how many records are served without ?limit=
+	defaultLimit = 100
+	// maxLimit bounds ?limit=
+	maxLimit = 1000
+)
+
+// Response is the body of GET /admin/audit
+type Response struct {
+	Records []Record `json:"records"`
+}
+
+// Handler handles GET /admin/audit requests: the newest records first,
+// filtered by ?since= (RFC 3339), ?principal= and ?result=, up to ?limit=
+func (l *Log) Handler(w http.ResponseWriter, req *http.Request) {
+	filter, err := parseFilter(req)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return
+	}
+	records, err := l.Records(filter)
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Internal("failed to read audit log", err))
+		return
+	}
+	if records == nil {
+		records = []Record{}
+	}
+	_, _ = web.ReturnResponse(w, Response{Records: records})
+}
+
+// parseFilter reads the filter of a request's query
+func parseFilter(req *http.Request) (Filter, error) {
+	query := req.URL.Query()
+	filter := Filter{
+		Principal: query.Get("principal"),
+		Result:    query.Get("result"),
+		Limit:     defaultLimit,
+	}
+	switch filter.Result {
+	case "", ResultSuccess, ResultDenied, ResultFailure:
+	default:
+		return Filter{}, cplnErrors.Validationf("invalid result: %q (expected %s, %s or %s)", filter.Result, ResultSuccess, ResultDenied, ResultFailure)
+	}
+	if raw := query.Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return Filter{}, cplnErrors.Validationf("invalid since: %q (expected an RFC 3339 time)", raw)
+		}
+		filter.Since = since
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return Filter{}, cplnErrors.Validationf("invalid limit: %q (expected 1 to %d)", raw, maxLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requestlog"
+)
+
+// maxErrorBytes bounds the response body recorded for a failed request
+const maxErrorBytes = 1024
+
+// masked replaces the value of a secret field in a recorded body
+const masked = "***"
+
+// secretKeys are substrings of the JSON keys whose values are masked
+var secretKeys = []string{"password", "secret", "token", "credential", "private"}
+
+type contextKey struct{}
+
+// SetPrincipal records who the bearer token of the request identifies. The
+// auth middleware calls it once it accepts a token; outside an audited
+// request it does nothing.
+func SetPrincipal(ctx context.Context, principal string) {
+	if r, ok := ctx.Value(contextKey{}).(*Record); ok {
+		r.Principal = principal
+	}
+}
+
+// Middleware records the requests protected selects, i.e. those needing a
+// bearer token: mutations, with their body, and denied requests always, and
+// successful reads when Options.Reads is set. It runs ahead of the auth
+// middleware, so that rejected tokens are recorded too. A record that cannot
+// be written is logged; the request is served regardless.
+func (l *Log) Middleware(protected func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !protected(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			record := &Record{
+				Time:       start.UTC(),
+				RequestID:  requestlog.ID(r.Context()),
+				RemoteAddr: r.RemoteAddr,
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Route:      routeName(r),
+				Query:      r.URL.RawQuery,
+			}
+			// A verified client certificate identifies the caller until a
+			// bearer token does
+			if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+				record.Principal = "cert:" + r.TLS.VerifiedChains[0][0].Subject.CommonName
+			}
+			mutation := isMutation(r.Method)
+			if mutation && r.Body != nil {
+				record.Body, record.BodyTruncated = l.captureBody(r)
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), contextKey{}, record)))
+
+			record.Status = rec.status
+			record.Result = result(rec.status)
+			record.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+			if rec.status >= http.StatusBadRequest {
+				record.Error = strings.TrimSpace(rec.body.String())
+			}
+			if !mutation && record.Result == ResultSuccess && !l.opts.Reads {
+				return
+			}
+			if err := l.Append(*record); err != nil {
+				l.logger.ErrorContext(r.Context(), "failed to record audit entry",
+					"method", r.Method, "path", r.URL.Path, "error", err)
+			}
+		})
+	}
+}
+
+// captureBody reads up to MaxBodyBytes of the request body for the record
+// and leaves the body intact for the handler. A longer body is only flagged,
+// since its secrets could not be masked.
+func (l *Log) captureBody(r *http.Request) (any, bool) {
+	captured, err := io.ReadAll(io.LimitReader(r.Body, int64(l.opts.MaxBodyBytes)+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(captured), r.Body), Closer: r.Body}
+	if err != nil || len(captured) == 0 {
+		return nil, false
+	}
+	if len(captured) > l.opts.MaxBodyBytes {
+		return nil, true
+	}
+	var value any
+	if err := json.Unmarshal(captured, &value); err != nil {
+		return string(captured), false
+	}
+	return redact(value), false
+}
+
+// redact masks the values of secret fields in a JSON value
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecret(key) {
+				v[key] = masked
+			} else {
+				v[key] = redact(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+// isSecret reports whether a JSON key names a secret
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMutation reports whether a request method can change state
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// result classifies a response status
+func result(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ResultDenied
+	case status >= http.StatusBadRequest:
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// routeName returns the path template of the matched route
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return ""
+}
+
+// readCloser reads the captured start of a body, then the rest, and closes
+// the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// recorder captures the status code of a response and the start of its body
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status >= http.StatusBadRequest && r.body.Len() < maxErrorBytes {
+		r.body.Write(b[:min(len(b), maxErrorBytes-r.body.Len())])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
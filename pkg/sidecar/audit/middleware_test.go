@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// protected selects the admin endpoints and every mutation
+func protected(r *http.Request) bool {
+	return r.Method != http.MethodGet || strings.HasPrefix(r.URL.Path, "/admin/")
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		reads         bool
+		method        string
+		path          string
+		body          string
+		principal     string
+		status        int
+		expectRecord  bool
+		expectResult  string
+		expectBody    string
+		expectTrimmed bool
+	}{
+		{name: "open endpoint", method: "GET", path: "/health/ready", status: 200},
+		{name: "read", method: "GET", path: "/admin/config", principal: "alice", status: 200},
+		{name: "audited read", reads: true, method: "GET", path: "/admin/config", principal: "alice", status: 200, expectRecord: true, expectResult: ResultSuccess},
+		{name: "denied read", method: "GET", path: "/admin/config", status: 401, expectRecord: true, expectResult: ResultDenied},
+		{
+			name: "mutation", method: "POST", path: "/admin/decommission/2", principal: "alice", status: 202,
+			body:         `{"requestedBy":"alice","confirmMinIsrReduction":true}`,
+			expectRecord: true, expectResult: ResultSuccess,
+			expectBody: `map[confirmMinIsrReduction:true requestedBy:alice]`,
+		},
+		{
+			name: "secrets masked", method: "POST", path: "/admin/users", principal: "alice", status: 200,
+			body:         `{"user":"app","password":"hunter2","tls":{"privateKey":"pem"}}`,
+			expectRecord: true, expectResult: ResultSuccess,
+			expectBody: `map[password:*** tls:map[privateKey:***] user:app]`,
+		},
+		{
+			name: "failed mutation", method: "POST", path: "/admin/reload", principal: "alice", status: 500,
+			body: "not json", expectRecord: true, expectResult: ResultFailure, expectBody: "not json",
+		},
+		{
+			name: "long body", method: "POST", path: "/admin/reload", principal: "alice", status: 200,
+			body: strings.Repeat("x", 2000), expectRecord: true, expectResult: ResultSuccess, expectTrimmed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := openLog(t, Options{Reads: tt.reads})
+			var served string
+			handler := l.Middleware(protected)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The handler still reads the whole body
+				body, _ := io.ReadAll(r.Body)
+				served = string(body)
+				if tt.principal != "" {
+					SetPrincipal(r.Context(), tt.principal)
+				}
+				w.WriteHeader(tt.status)
+				if tt.status >= 400 {
+					_, _ = w.Write([]byte(`{"error":"nope"}`))
+				}
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if served != tt.body {
+				t.Errorf("expected the handler to read the whole body, got %d of %d bytes", len(served), len(tt.body))
+			}
+
+			records, err := l.Records(Filter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.expectRecord {
+				if len(records) != 0 {
+					t.Errorf("expected no record, got %+v", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("expected 1 record, got %+v", records)
+			}
+			r := records[0]
+			if r.Method != tt.method || r.Path != tt.path || r.Status != tt.status || r.Result != tt.expectResult || r.Principal != tt.principal {
+				t.Errorf("unexpected record %+v", r)
+			}
+			body := ""
+			if r.Body != nil {
+				body = fmt.Sprint(r.Body)
+			}
+			if body != tt.expectBody {
+				t.Errorf("expected body %s, got %s", tt.expectBody, body)
+			}
+			if r.BodyTruncated != tt.expectTrimmed {
+				t.Errorf("expected bodyTruncated=%v, got %v", tt.expectTrimmed, r.BodyTruncated)
+			}
+			if tt.status >= 400 && r.Error != `{"error":"nope"}` {
+				t.Errorf("expected the error response recorded, got %q", r.Error)
+			}
+		})
+	}
+}
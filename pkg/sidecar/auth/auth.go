@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/audit"
+)
+
+// ErrInvalidToken is returned by a Verifier that does not accept a token
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier accepts or rejects a bearer token
+type Verifier interface {
+	Verify(ctx context.Context, token string) error
+}
+
+// Identifier names who a token it verified belongs to, for the audit trail
+type Identifier interface {
+	Identify(token string) string
+}
+
+// fingerprint identifies a token without revealing it
+func fingerprint(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(hash[:6])
+}
+
+// PeersPath is the reachability report peer sidecars read from each other. It
+// stays open, since peers hold no credentials for each other.
+const PeersPath = "/admin/peers"
+
+// Protected reports whether a request needs a bearer token: the admin and debug
+// endpoints, and every request that can change state. Probes, metrics, status
+// and version information stay open so that the kubelet and scrapers need no
+// credentials.
+func Protected(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return true
+	}
+	if r.URL.Path == PeersPath {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/admin/") || strings.HasPrefix(r.URL.Path, "/debug/")
+}
+
+// Middleware rejects protected requests unless one of the verifiers accepts
+// their bearer token, and records who the token identifies for the audit trail
+func Middleware(verifiers []Verifier, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Protected(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			token, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, "missing bearer token", "")
+				return
+			}
+			var errs []error
+			for _, v := range verifiers {
+				err := v.Verify(r.Context(), token)
+				if err == nil {
+					principal := fingerprint(token)
+					if id, ok := v.(Identifier); ok {
+						principal = id.Identify(token)
+					}
+					audit.SetPrincipal(r.Context(), principal)
+					next.ServeHTTP(w, r)
+					return
+				}
+				errs = append(errs, err)
+			}
+			logger.WarnContext(r.Context(), "rejected bearer token",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"error", errors.Join(errs...))
+			unauthorized(w, "invalid bearer token", "invalid_token")
+		})
+	}
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// unauthorized writes a 401 with the challenge of RFC 6750
+func unauthorized(w http.ResponseWriter, message, code string) {
+	challenge := `Bearer realm="kafka-sidecar"`
+	if code != "" {
+		challenge += `, error="` + code + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	_, _ = web.ReturnResponseWithCode(w, map[string]string{"error": message}, http.StatusUnauthorized)
+}
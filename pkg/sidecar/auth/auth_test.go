@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/audit"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func writeTokens(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTokenFile(t *testing.T) {
+	tokens, err := LoadTokenFile(writeTokens(t, "# operators\nalpha\n\n  beta  \n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for token, want := range map[string]bool{"alpha": true, "beta": true, "gamma": false, "": false, "# operators": false} {
+		if got := tokens.Verify(context.Background(), token) == nil; got != want {
+			t.Errorf("Verify(%q) = %v, want %v", token, got, want)
+		}
+	}
+
+	if _, err := LoadTokenFile(writeTokens(t, "# nothing here\n")); err == nil {
+		t.Error("expected an error for a file without tokens")
+	}
+	if _, err := LoadTokenFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tokens, err := LoadTokenFile(writeTokens(t, "secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := Middleware([]Verifier{tokens}, testLogger())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		status int
+	}{
+		{name: "probe open", method: http.MethodGet, path: "/health/ready", status: http.StatusOK},
+		{name: "metrics open", method: http.MethodGet, path: "/metrics", status: http.StatusOK},
+		{name: "admin without token", method: http.MethodGet, path: "/admin/decommission", status: http.StatusUnauthorized},
+		{name: "admin with token", method: http.MethodGet, path: "/admin/decommission", auth: "Bearer secret", status: http.StatusOK},
+		{name: "scheme is case-insensitive", method: http.MethodGet, path: "/admin/config", auth: "bearer secret", status: http.StatusOK},
+		{name: "admin with wrong token", method: http.MethodGet, path: "/admin/config", auth: "Bearer nope", status: http.StatusUnauthorized},
+		{name: "basic auth", method: http.MethodGet, path: "/admin/config", auth: "Basic c2VjcmV0", status: http.StatusUnauthorized},
+		{name: "peer reports open", method: http.MethodGet, path: "/admin/peers", status: http.StatusOK},
+		{name: "pprof", method: http.MethodGet, path: "/debug/pprof/heap", status: http.StatusUnauthorized},
+		{name: "mutation outside admin", method: http.MethodPost, path: "/topics/orders/replication-factor", status: http.StatusUnauthorized},
+		{name: "mutation with token", method: http.MethodPost, path: "/topics/orders/replication-factor", auth: "Bearer secret", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestMiddlewareAuditPrincipal(t *testing.T) {
+	tokens, err := LoadTokenFile(writeTokens(t, "secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog, err := audit.Open(audit.Options{Path: filepath.Join(t.TempDir(), "audit.log"), MaxSize: 1 << 20}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+	handler := auditLog.Middleware(Protected)(Middleware([]Verifier{tokens}, testLogger())(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	for _, token := range []string{"secret", "wrong"} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	records, err := auditLog.Records(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %+v", records)
+	}
+	if records[0].Result != audit.ResultDenied || records[0].Principal != "" {
+		t.Errorf("expected a denied request without principal, got %+v", records[0])
+	}
+	if records[1].Result != audit.ResultSuccess || records[1].Principal != fingerprint("secret") {
+		t.Errorf("expected the token's fingerprint as principal, got %+v", records[1])
+	}
+}
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+const (
+	// jwksMaxAge is how long fetched keys are used before they are fetched again
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh limits refetches for tokens signed with an unknown key
+	jwksMinRefresh = time.Minute
+	// clockSkew is the leeway allowed on exp and nbf
+	clockSkew = time.Minute
+	// maxJWKSBytes bounds the size of a JWKS response
+	maxJWKSBytes = 1 << 20
+)
+
+// JWTOptions configures a JWKSVerifier
+type JWTOptions struct {
+	// JWKSURL serves the JSON Web Key Set the tokens are signed with
+	JWKSURL string
+	// Issuer, when set, must match the iss claim
+	Issuer string
+	// Audience, when set, must be one of the aud claim
+	Audience string
+	// Timeout bounds a JWKS fetch
+	Timeout time.Duration
+}
+
+// JWKSVerifier accepts JWTs signed with RS256/384/512 or ES256/384/512 by a
+// key of a JWKS, an ES algorithm only with a key on its curve. Keys are fetched on first use, refreshed hourly, and
+// refetched (at most once a minute) when a token names an unknown key, so
+// key rotation needs no restart.
+type JWKSVerifier struct {
+	opts   JWTOptions
+	client *http.Client
+	logger *slog.Logger
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKSVerifier creates a verifier for tokens signed by the keys at opts.JWKSURL
+func NewJWKSVerifier(opts JWTOptions, logger *slog.Logger) *JWKSVerifier {
+	return &JWKSVerifier{
+		opts:   opts,
+		client: &http.Client{},
+		logger: logger,
+		clock:  clock.Real,
+	}
+}
+
+// validMethods are the accepted JWS algorithms
+var validMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// Verify implements Verifier
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) error {
+	var c jwt.RegisteredClaims
+	parser := jwt.NewParser(jwt.WithValidMethods(validMethods), jwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(token, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if err := matchKey(t.Method, key); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		return key, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return v.checkClaims(&c)
+}
+
+// Identify implements Identifier: the sub claim of a verified token, or its
+// fingerprint when it has none
+func (v *JWKSVerifier) Identify(token string) string {
+	var c jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &c); err == nil && c.Subject != "" {
+		return c.Subject
+	}
+	return fingerprint(token)
+}
+
+// checkClaims validates expiry, issuer and audience
+func (v *JWKSVerifier) checkClaims(c *jwt.RegisteredClaims) error {
+	now := v.clock.Now()
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("%w: no exp claim", ErrInvalidToken)
+	}
+	if !c.VerifyExpiresAt(now.Add(-clockSkew), true) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if !c.VerifyNotBefore(now.Add(clockSkew), false) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if v.opts.Issuer != "" && !c.VerifyIssuer(v.opts.Issuer, true) {
+		return fmt.Errorf("%w: issuer %q", ErrInvalidToken, c.Issuer)
+	}
+	if v.opts.Audience != "" && !c.VerifyAudience(v.opts.Audience, true) {
+		return fmt.Errorf("%w: audience %v", ErrInvalidToken, []string(c.Audience))
+	}
+	return nil
+}
+
+// matchKey checks the key has the type, and for EC the curve, the algorithm
+// expects, so that a token cannot pick another scheme for a key
+func matchKey(method jwt.SigningMethod, key crypto.PublicKey) error {
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA:
+		if _, ok := key.(*rsa.PublicKey); !ok {
+			return fmt.Errorf("algorithm %s does not match an EC key", m.Alg())
+		}
+	case *jwt.SigningMethodECDSA:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %s does not match an RSA key", m.Alg())
+		}
+		if params := k.Curve.Params(); params.BitSize != m.CurveBits {
+			return fmt.Errorf("algorithm %s does not match curve %s", m.Alg(), params.Name)
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %s", method.Alg())
+	}
+	return nil
+}
+
+// key returns the key named kid, fetching the JWKS when it is stale or does not
+// hold the key. Without a kid, the set must hold exactly one key.
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	age := v.clock.Since(v.fetchedAt)
+	key, ok := v.lookup(kid)
+	if v.keys == nil || age >= jwksMaxAge || (!ok && age >= jwksMinRefresh) {
+		if err := v.fetch(ctx); err != nil {
+			if v.keys == nil {
+				return nil, err
+			}
+			// Keep verifying with the last good set while the JWKS is unavailable
+			v.logger.WarnContext(ctx, "failed to refresh JWKS", "url", v.opts.JWKSURL, "error", err)
+		}
+		key, ok = v.lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (v *JWKSVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// fetch replaces the keys with the current JWKS. Keys of unsupported types or
+// meant for encryption are skipped.
+func (v *JWKSVerifier) fetch(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, v.opts.Timeout)
+	defer cancel()
+	// A failed fetch counts as one, so an unreachable JWKS is not hammered
+	v.fetchedAt = v.clock.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.opts.JWKSURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+	keys, err := ParseJWKS(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+	return nil
+}
+
+// jwk is a JSON Web Key, RSA or EC
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS reads the RSA and EC signing keys of a JSON Web Key Set by key ID
+func ParseJWKS(r io.Reader) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid JWKS: no RSA or EC signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key, or returns nil for an unsupported key type
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, errors.New("invalid EC coordinates")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+var b64 = base64.RawURLEncoding
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64.EncodeToString(key.N.Bytes()),
+		"e": b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	point, _ := key.PublicKey.Bytes()
+	size := (len(point) - 1) / 2
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": key.Curve.Params().Name,
+		"x": b64.EncodeToString(point[1 : 1+size]),
+		"y": b64.EncodeToString(point[1+size:]),
+	}
+}
+
+// sign builds a JWT with the given header and claims
+func sign(t *testing.T, key crypto.Signer, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(alg), jwt.MapClaims(claims))
+	token.Header["kid"] = kid
+	var signingKey any = key
+	if alg == "none" {
+		signingKey = jwt.UnsafeAllowNoneSignatureType
+	}
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+type jwksServer struct {
+	*httptest.Server
+	keys    atomic.Value
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...map[string]string) *jwksServer {
+	s := &jwksServer{}
+	s.keys.Store(keys)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": s.keys.Load()})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ec521Key, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", ecKey), ecJWK("ec384", ec384Key), ecJWK("ec521", ec521Key))
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := NewJWKSVerifier(JWTOptions{JWKSURL: server.URL, Issuer: "https://issuer", Audience: "kafka-sidecar", Timeout: time.Second}, testLogger())
+	v.clock = clock.NewFake(now)
+
+	valid := func() map[string]any {
+		return map[string]any{"iss": "https://issuer", "aud": "kafka-sidecar", "exp": now.Add(time.Hour).Unix()}
+	}
+	with := func(key string, value any) map[string]any {
+		c := valid()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		ok     bool
+		reason string
+	}{
+		{name: "RS256", token: sign(t, rsaKey, "RS256", "rsa", valid()), ok: true},
+		{name: "RS512", token: sign(t, rsaKey, "RS512", "rsa", valid()), ok: true},
+		{name: "ES256", token: sign(t, ecKey, "ES256", "ec", valid()), ok: true},
+		{name: "ES384", token: sign(t, ec384Key, "ES384", "ec384", valid()), ok: true},
+		{name: "ES512", token: sign(t, ec521Key, "ES512", "ec521", valid()), ok: true},
+		{name: "audience array", token: sign(t, rsaKey, "RS256", "rsa", with("aud", []string{"other", "kafka-sidecar"})), ok: true},
+		{name: "expired", token: sign(t, rsaKey, "RS256", "rsa", with("exp", now.Add(-time.Hour).Unix()))},
+		{name: "within clock skew", token: sign(t, rsaKey, "RS256", "rsa", with("exp", now.Add(-30*time.Second).Unix())), ok: true},
+		{name: "no exp", token: sign(t, rsaKey, "RS256", "rsa", with("exp", nil))},
+		{name: "not yet valid", token: sign(t, rsaKey, "RS256", "rsa", with("nbf", now.Add(time.Hour).Unix()))},
+		{name: "wrong issuer", token: sign(t, rsaKey, "RS256", "rsa", with("iss", "https://evil"))},
+		{name: "wrong audience", token: sign(t, rsaKey, "RS256", "rsa", with("aud", "other"))},
+		{name: "wrong key", token: sign(t, otherKey, "RS256", "rsa", valid())},
+		{name: "unknown kid", token: sign(t, rsaKey, "RS256", "missing", valid())},
+		{name: "alg none", token: sign(t, rsaKey, "none", "rsa", valid())},
+		{name: "alg does not match key", token: sign(t, ecKey, "ES256", "rsa", valid()), reason: "RSA key"},
+		{name: "ES256 with a P-384 key", token: sign(t, ecKey, "ES256", "ec384", valid()), reason: "curve P-384"},
+		{name: "ES256 with a P-521 key", token: sign(t, ecKey, "ES256", "ec521", valid()), reason: "curve P-521"},
+		{name: "ES384 with a P-256 key", token: sign(t, ec384Key, "ES384", "ec", valid()), reason: "curve P-256"},
+		{name: "ES384 with a P-521 key", token: sign(t, ec384Key, "ES384", "ec521", valid()), reason: "curve P-521"},
+		{name: "ES512 with a P-256 key", token: sign(t, ec521Key, "ES512", "ec", valid()), reason: "curve P-256"},
+		{name: "ES512 with a P-384 key", token: sign(t, ec521Key, "ES512", "ec384", valid()), reason: "curve P-384"},
+		{name: "garbage", token: "not.a.jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Verify(context.Background(), tt.token)
+			if (err == nil) != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+			if tt.reason != "" && !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("expected an error about %q, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestJWKSVerifier_Rotation(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("old", &oldKey.PublicKey))
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	v := NewJWKSVerifier(JWTOptions{JWKSURL: server.URL, Timeout: time.Second}, testLogger())
+	v.clock = clk
+	claims := map[string]any{"exp": clk.Now().Add(24 * time.Hour).Unix()}
+
+	if err := v.Verify(context.Background(), sign(t, oldKey, "RS256", "old", claims)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.keys.Store([]map[string]string{rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey)})
+	rotated := sign(t, newKey, "RS256", "new", claims)
+
+	// An unknown key is only refetched once the minimum refresh interval passed
+	if err := v.Verify(context.Background(), rotated); err == nil {
+		t.Fatal("expected the new key to be unknown right after a fetch")
+	}
+	clk.Advance(jwksMinRefresh)
+	if err := v.Verify(context.Background(), rotated); err != nil {
+		t.Fatalf("expected the rotated key to be fetched, got %v", err)
+	}
+	if got := server.fetches.Load(); got != 2 {
+		t.Errorf("expected 2 fetches, got %d", got)
+	}
+
+	// The last good set keeps working while the JWKS is down
+	server.Close()
+	clk.Advance(jwksMaxAge)
+	if err := v.Verify(context.Background(), rotated); err != nil {
+		t.Errorf("expected cached keys to be used, got %v", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	for name, body := range map[string]string{
+		"no keys":         `{"keys":[]}`,
+		"only oct keys":   `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+		"bad curve":       `{"keys":[{"kty":"EC","crv":"P-192","x":"AA","y":"AA"}]}`,
+		"not json":        `nope`,
+		"off-curve point": `{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseJWKS(strings.NewReader(body)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestJWKSVerifier_Identify(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	v := NewJWKSVerifier(JWTOptions{}, testLogger())
+	exp := time.Now().Add(time.Hour).Unix()
+
+	if got := v.Identify(sign(t, key, "RS256", "rsa", map[string]any{"sub": "alice@example.com", "exp": exp})); got != "alice@example.com" {
+		t.Errorf("expected the sub claim, got %q", got)
+	}
+	anonymous := sign(t, key, "RS256", "rsa", map[string]any{"exp": exp})
+	if got := v.Identify(anonymous); got != fingerprint(anonymous) || !strings.HasPrefix(got, "token:") {
+		t.Errorf("expected the fingerprint of a token without sub, got %q", got)
+	}
+}
@@ -0,0 +1,553 @@
+// Package backup exports the cluster's metadata (topics with their replica
+// assignment, dynamic configs, ACLs and client quotas) as versioned JSON
+// snapshots to S3-compatible object storage, so the metadata can be recreated
+// after the loss of the cluster and its KRaft log.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// Path serves the last backup and takes one on demand
+const Path = "/admin/backup"
+
+// FormatVersion is the version of the snapshot document. It changes whenever a
+// field changes meaning, so a restore can tell old snapshots apart.
+const FormatVersion = 1
+
+// keyTimeFormat names the snapshots so that they sort by time
+const keyTimeFormat = "20060102T150405Z"
+
+// AdminClient defines the Kafka admin operations needed to snapshot the
+// metadata. This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeBrokerConfigs(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error)
+	DescribeACLs(ctx context.Context, b *kadm.ACLBuilder) (kadm.DescribeACLsResults, error)
+	DescribeClientQuotas(ctx context.Context, strict bool, entityComponents []kadm.DescribeClientQuotaComponent) (kadm.DescribedClientQuotas, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the metadata backup
+type Options struct {
+	// Prefix starts the object keys, which continue with the cluster ID and the
+	// time of the snapshot: <prefix>/<cluster ID>/20260101T000000Z.json
+	Prefix string
+	// Interval is how often a snapshot is taken
+	Interval time.Duration
+	// Timeout bounds the snapshot and its upload
+	Timeout time.Duration
+}
+
+// Snapshot is the cluster metadata at a point in time
+type Snapshot struct {
+	Version   int       `json:"version"`
+	ClusterID string    `json:"clusterId"`
+	TakenAt   time.Time `json:"takenAt"`
+	// TakenBy is the broker whose sidecar took the snapshot
+	TakenBy int32    `json:"takenBy"`
+	Brokers []Broker `json:"brokers"`
+	// ClusterConfigs are the dynamic broker configs set cluster-wide
+	ClusterConfigs []Config `json:"clusterConfigs"`
+	// BrokerConfigs are the dynamic configs set on single brokers
+	BrokerConfigs []BrokerConfigs `json:"brokerConfigs"`
+	// Topics are the topics other than Kafka's internal ones
+	Topics []Topic `json:"topics"`
+	// ACLs are empty when the brokers run without an authorizer
+	ACLs   []ACL   `json:"acls"`
+	Quotas []Quota `json:"quotas"`
+}
+
+// Broker is a broker of the cluster
+type Broker struct {
+	ID   int32  `json:"id"`
+	Rack string `json:"rack,omitempty"`
+}
+
+// Config is a dynamic config. Kafka never returns the value of a sensitive
+// config, so it has to be set again by hand after a restore.
+type Config struct {
+	Name      string  `json:"name"`
+	Value     *string `json:"value,omitempty"`
+	Sensitive bool    `json:"sensitive,omitempty"`
+}
+
+// BrokerConfigs are the dynamic configs of a broker
+type BrokerConfigs struct {
+	Broker  int32    `json:"broker"`
+	Configs []Config `json:"configs"`
+}
+
+// Topic is a topic and its dynamic configs
+type Topic struct {
+	Name              string `json:"name"`
+	Partitions        int    `json:"partitions"`
+	ReplicationFactor int    `json:"replicationFactor"`
+	// Replicas are the replicas of each partition, indexed by partition
+	Replicas [][]int32 `json:"replicas"`
+	Configs  []Config  `json:"configs"`
+}
+
+// ACL is an access control entry, in the names kafka-acls.sh uses
+type ACL struct {
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+	PatternType  string `json:"patternType"`
+	Principal    string `json:"principal"`
+	Host         string `json:"host"`
+	Operation    string `json:"operation"`
+	Permission   string `json:"permission"`
+}
+
+// Quota is the client quota of an entity
+type Quota struct {
+	Entity []QuotaEntity      `json:"entity"`
+	Values map[string]float64 `json:"values"`
+}
+
+// QuotaEntity is a component of a quota entity. A nil name is the default of
+// the type.
+type QuotaEntity struct {
+	Type string  `json:"type"`
+	Name *string `json:"name"`
+}
+
+// Report is the outcome of the last backup
+type Report struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	// Skipped is set when another broker takes the scheduled backups
+	Skipped bool `json:"skipped,omitempty"`
+	// Key is the object the snapshot was written to
+	Key     string     `json:"key,omitempty"`
+	TakenAt *time.Time `json:"takenAt,omitempty"`
+	Topics  int        `json:"topics"`
+	ACLs    int        `json:"acls"`
+	Quotas  int        `json:"quotas"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Exporter periodically snapshots the cluster metadata to a store. Only the
+// live broker with the lowest ID takes the scheduled snapshots, so a single
+// copy is written per interval.
+//
+// A backup runs far less often than the staleness bound of the freshness
+// tracker, so the loop is not tracked; its last outcome is served at Path.
+type Exporter struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	store         Store
+	logger        *slog.Logger
+	clock         clock.Clock
+	clientFactory ClientFactory
+
+	// running serializes scheduled and on-demand backups
+	running sync.Mutex
+
+	mu     sync.RWMutex
+	report *Report
+}
+
+// NewExporter creates a new metadata exporter writing to the store
+func NewExporter(brokerID int32, kafkaConfig kafkaclient.Config, store Store, opts Options, logger *slog.Logger) *Exporter {
+	e := &Exporter{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		store:       store,
+		logger:      logger,
+		clock:       clock.Real,
+	}
+	e.clientFactory = e.defaultClientFactory
+	return e
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (e *Exporter) SetClientFactory(factory ClientFactory) {
+	e.clientFactory = factory
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (e *Exporter) SetClock(clk clock.Clock) {
+	e.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (e *Exporter) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(e.kafkaConfig)
+}
+
+// LastReport returns the outcome of the last backup, and false before the first one
+func (e *Exporter) LastReport() (Report, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.report == nil {
+		return Report{}, false
+	}
+	return *e.report, true
+}
+
+// Run takes a snapshot every Interval until the context is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := e.clock.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Step(ctx); err != nil {
+			e.logger.Error("backup: failed to back up cluster metadata", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step takes a scheduled snapshot, when this broker is the live broker with
+// the lowest ID
+func (e *Exporter) Step(ctx context.Context) error {
+	return e.backup(ctx, false)
+}
+
+// Backup takes a snapshot regardless of which broker takes the scheduled ones
+func (e *Exporter) Backup(ctx context.Context) (Report, error) {
+	err := e.backup(ctx, true)
+	report, _ := e.LastReport()
+	return report, err
+}
+
+// backup snapshots the metadata and writes it to the store, recording the outcome
+func (e *Exporter) backup(ctx context.Context, force bool) error {
+	e.running.Lock()
+	defer e.running.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+
+	report := Report{CheckedAt: e.clock.Now()}
+	snapshot, err := e.snapshot(ctx, force)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to snapshot metadata: %w", err)
+	case snapshot == nil:
+		report.Skipped = true
+	default:
+		report.Key = e.key(snapshot)
+		report.TakenAt = &snapshot.TakenAt
+		report.Topics = len(snapshot.Topics)
+		report.ACLs = len(snapshot.ACLs)
+		report.Quotas = len(snapshot.Quotas)
+		err = e.write(ctx, report.Key, snapshot)
+	}
+	if err != nil {
+		report.Error = err.Error()
+	} else if !report.Skipped {
+		e.logger.Info("backup: cluster metadata backed up",
+			"key", report.Key, "topics", report.Topics, "acls", report.ACLs, "quotas", report.Quotas)
+	}
+
+	e.mu.Lock()
+	e.report = &report
+	e.mu.Unlock()
+	return err
+}
+
+// key is the object key of a snapshot
+func (e *Exporter) key(snapshot *Snapshot) string {
+	return path.Join(e.opts.Prefix, snapshot.ClusterID, snapshot.TakenAt.UTC().Format(keyTimeFormat)+".json")
+}
+
+// write uploads a snapshot
+func (e *Exporter) write(ctx context.Context, key string, snapshot *Snapshot) error {
+	body, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := e.store.Put(ctx, key, body); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshot reads the metadata of the cluster. Unless forced, it returns nil
+// when another broker takes the scheduled snapshots.
+func (e *Exporter) snapshot(ctx context.Context, force bool) (*Snapshot, error) {
+	adm, cleanup, err := e.clientFactory()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	ids := md.Brokers.NodeIDs()
+	if !force && (len(ids) == 0 || ids[0] != e.brokerID) {
+		return nil, nil
+	}
+
+	snapshot := &Snapshot{
+		Version:   FormatVersion,
+		ClusterID: md.Cluster,
+		TakenAt:   e.clock.Now().UTC(),
+		TakenBy:   e.brokerID,
+	}
+	for _, b := range md.Brokers {
+		broker := Broker{ID: b.NodeID}
+		if b.Rack != nil {
+			broker.Rack = *b.Rack
+		}
+		snapshot.Brokers = append(snapshot.Brokers, broker)
+	}
+	sort.Slice(snapshot.Brokers, func(i, j int) bool {
+		return snapshot.Brokers[i].ID < snapshot.Brokers[j].ID
+	})
+
+	if snapshot.Topics, err = topics(ctx, adm, md); err != nil {
+		return nil, err
+	}
+	if snapshot.ClusterConfigs, snapshot.BrokerConfigs, err = brokerConfigs(ctx, adm, ids); err != nil {
+		return nil, err
+	}
+	if snapshot.ACLs, err = acls(ctx, adm); err != nil {
+		return nil, err
+	}
+	if snapshot.Quotas, err = quotas(ctx, adm); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// topics returns the topics other than the internal ones, with their dynamic
+// configs, sorted by name
+func topics(ctx context.Context, adm AdminClient, md kadm.Metadata) ([]Topic, error) {
+	var names []string
+	for _, t := range md.Topics {
+		if t.Err != nil {
+			return nil, fmt.Errorf("failed to describe topic %s: %w", t.Topic, t.Err)
+		}
+		if !t.IsInternal {
+			names = append(names, t.Topic)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return []Topic{}, nil
+	}
+
+	configs, err := adm.DescribeTopicConfigs(ctx, names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	result := make([]Topic, 0, len(names))
+	for _, name := range names {
+		detail := md.Topics[name]
+		topic := Topic{
+			Name:              name,
+			Partitions:        len(detail.Partitions),
+			ReplicationFactor: detail.Partitions.NumReplicas(),
+			Replicas:          make([][]int32, len(detail.Partitions)),
+		}
+		for _, p := range detail.Partitions {
+			if int(p.Partition) < len(topic.Replicas) {
+				topic.Replicas[p.Partition] = p.Replicas
+			}
+		}
+		rc, err := configs.On(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe configs of topic %s: %w", name, err)
+		}
+		if rc.Err != nil {
+			return nil, fmt.Errorf("failed to describe configs of topic %s: %w", name, rc.Err)
+		}
+		topic.Configs = dynamic(rc.Configs, kmsg.ConfigSourceDynamicTopicConfig)
+		result = append(result, topic)
+	}
+	return result, nil
+}
+
+// brokerConfigs returns the dynamic broker configs set cluster-wide and on
+// each broker
+func brokerConfigs(ctx context.Context, adm AdminClient, ids []int32) ([]Config, []BrokerConfigs, error) {
+	defaults, err := adm.DescribeBrokerConfigs(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe cluster-wide broker configs: %w", err)
+	}
+	cluster := []Config{}
+	for _, rc := range defaults {
+		if rc.Err != nil {
+			return nil, nil, fmt.Errorf("failed to describe cluster-wide broker configs: %w", rc.Err)
+		}
+		cluster = append(cluster, dynamic(rc.Configs, kmsg.ConfigSourceDynamicDefaultBrokerConfig)...)
+	}
+
+	brokers := []BrokerConfigs{}
+	if len(ids) == 0 {
+		return cluster, brokers, nil
+	}
+	perBroker, err := adm.DescribeBrokerConfigs(ctx, ids...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe broker configs: %w", err)
+	}
+	for _, rc := range perBroker {
+		if rc.Err != nil {
+			return nil, nil, fmt.Errorf("failed to describe configs of broker %s: %w", rc.Name, rc.Err)
+		}
+		configs := dynamic(rc.Configs, kmsg.ConfigSourceDynamicBrokerConfig)
+		if len(configs) == 0 {
+			continue
+		}
+		id, err := strconv.ParseInt(rc.Name, 10, 32)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unexpected broker config resource %q", rc.Name)
+		}
+		brokers = append(brokers, BrokerConfigs{Broker: int32(id), Configs: configs})
+	}
+	sort.Slice(brokers, func(i, j int) bool {
+		return brokers[i].Broker < brokers[j].Broker
+	})
+	return cluster, brokers, nil
+}
+
+// dynamic returns the configs set from the source, sorted by name
+func dynamic(configs []kadm.Config, source kmsg.ConfigSource) []Config {
+	result := []Config{}
+	for _, c := range configs {
+		if c.Source != source {
+			continue
+		}
+		result = append(result, Config{Name: c.Key, Value: c.Value, Sensitive: c.Sensitive})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// acls returns every ACL, sorted. Brokers without an authorizer have none.
+func acls(ctx context.Context, adm AdminClient) ([]ACL, error) {
+	filter := kadm.NewACLs().
+		AnyResource().
+		ResourcePatternType(kadm.ACLPatternAny).
+		Allow().AllowHosts().
+		Deny().DenyHosts().
+		Operations(kadm.OpAny)
+	results, err := adm.DescribeACLs(ctx, filter)
+	if errors.Is(err, kerr.SecurityDisabled) {
+		return []ACL{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe ACLs: %w", err)
+	}
+
+	result := []ACL{}
+	for _, r := range results {
+		if errors.Is(r.Err, kerr.SecurityDisabled) {
+			continue
+		}
+		if r.Err != nil {
+			return nil, fmt.Errorf("failed to describe ACLs: %w", r.Err)
+		}
+		for _, d := range r.Described {
+			result = append(result, ACL{
+				ResourceType: d.Type.String(),
+				ResourceName: d.Name,
+				PatternType:  d.Pattern.String(),
+				Principal:    d.Principal,
+				Host:         d.Host,
+				Operation:    d.Operation.String(),
+				Permission:   d.Permission.String(),
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return aclKey(result[i]) < aclKey(result[j])
+	})
+	return result, nil
+}
+
+// aclKey orders ACLs by resource, then principal
+func aclKey(a ACL) string {
+	return strings.Join([]string{a.ResourceType, a.ResourceName, a.PatternType, a.Principal, a.Host, a.Operation, a.Permission}, "\x00")
+}
+
+// quotas returns every client quota, sorted by entity
+func quotas(ctx context.Context, adm AdminClient) ([]Quota, error) {
+	described, err := adm.DescribeClientQuotas(ctx, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe client quotas: %w", err)
+	}
+	result := make([]Quota, 0, len(described))
+	for _, d := range described {
+		quota := Quota{Values: make(map[string]float64, len(d.Values))}
+		for _, c := range d.Entity {
+			quota.Entity = append(quota.Entity, QuotaEntity{Type: c.Type, Name: c.Name})
+		}
+		for _, v := range d.Values {
+			quota.Values[v.Key] = v.Value
+		}
+		result = append(result, quota)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return entityKey(result[i].Entity) < entityKey(result[j].Entity)
+	})
+	return result, nil
+}
+
+// entityKey orders quota entities
+func entityKey(entity []QuotaEntity) string {
+	var key string
+	for _, c := range entity {
+		name := "<default>"
+		if c.Name != nil {
+			name = *c.Name
+		}
+		key += c.Type + "=" + name + ","
+	}
+	return key
+}
+
+// StatusHandler handles GET /admin/backup requests
+func (e *Exporter) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := e.LastReport()
+	if !ok {
+		report = Report{Error: "no backup taken yet"}
+	}
+	_, _ = web.ReturnResponse(w, report)
+}
+
+// BackupHandler handles POST /admin/backup requests, taking a snapshot at once
+func (e *Exporter) BackupHandler(w http.ResponseWriter, req *http.Request) {
+	report, err := e.Backup(req.Context())
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Internal("failed to back up cluster metadata", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, report)
+}
@@ -0,0 +1,319 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc              func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigsFunc  func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeBrokerConfigsFunc func(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error)
+	DescribeACLsFunc          func(ctx context.Context, b *kadm.ACLBuilder) (kadm.DescribeACLsResults, error)
+	DescribeClientQuotasFunc  func(ctx context.Context, strict bool, entityComponents []kadm.DescribeClientQuotaComponent) (kadm.DescribedClientQuotas, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+	if m.DescribeTopicConfigsFunc != nil {
+		return m.DescribeTopicConfigsFunc(ctx, topics...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) DescribeBrokerConfigs(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error) {
+	if m.DescribeBrokerConfigsFunc != nil {
+		return m.DescribeBrokerConfigsFunc(ctx, brokers...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) DescribeACLs(ctx context.Context, b *kadm.ACLBuilder) (kadm.DescribeACLsResults, error) {
+	if m.DescribeACLsFunc != nil {
+		return m.DescribeACLsFunc(ctx, b)
+	}
+	return kadm.DescribeACLsResults{}, nil
+}
+
+func (m *MockAdminClient) DescribeClientQuotas(ctx context.Context, strict bool, entityComponents []kadm.DescribeClientQuotaComponent) (kadm.DescribedClientQuotas, error) {
+	if m.DescribeClientQuotasFunc != nil {
+		return m.DescribeClientQuotasFunc(ctx, strict, entityComponents)
+	}
+	return kadm.DescribedClientQuotas{}, nil
+}
+
+// memoryStore keeps objects in memory, failing while err is set
+type memoryStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, body []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[key] = body
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// cluster returns brokers 1 to 3 hosting the topics orders and
+// __consumer_offsets, with dynamic configs, an ACL and a quota
+func cluster() *MockAdminClient {
+	return &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return kadm.Metadata{
+				Cluster: "abc",
+				Brokers: kadm.BrokerDetails{{NodeID: 3}, {NodeID: 1, Rack: ptr("a")}, {NodeID: 2}},
+				Topics: kadm.TopicDetails{
+					"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+						0: {Topic: "orders", Partition: 0, Replicas: []int32{1, 2}},
+						1: {Topic: "orders", Partition: 1, Replicas: []int32{2, 3}},
+					}},
+					"__consumer_offsets": {Topic: "__consumer_offsets", IsInternal: true, Partitions: kadm.PartitionDetails{
+						0: {Topic: "__consumer_offsets", Partition: 0, Replicas: []int32{1, 2, 3}},
+					}},
+				},
+			}, nil
+		},
+		DescribeTopicConfigsFunc: func(_ context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			if len(topics) != 1 || topics[0] != "orders" {
+				return nil, errors.New("expected only orders to be described")
+			}
+			return kadm.ResourceConfigs{{Name: "orders", Configs: []kadm.Config{
+				{Key: "retention.ms", Value: ptr("86400000"), Source: kmsg.ConfigSourceDynamicTopicConfig},
+				{Key: "cleanup.policy", Value: ptr("delete"), Source: kmsg.ConfigSourceDefaultConfig},
+			}}}, nil
+		},
+		DescribeBrokerConfigsFunc: func(_ context.Context, brokers ...int32) (kadm.ResourceConfigs, error) {
+			if len(brokers) == 0 {
+				return kadm.ResourceConfigs{{Name: "", Configs: []kadm.Config{
+					{Key: "log.retention.ms", Value: ptr("604800000"), Source: kmsg.ConfigSourceDynamicDefaultBrokerConfig},
+				}}}, nil
+			}
+			return kadm.ResourceConfigs{
+				{Name: "1", Configs: []kadm.Config{
+					{Key: "listener.name.internal.ssl.keystore.password", Sensitive: true, Source: kmsg.ConfigSourceDynamicBrokerConfig},
+					{Key: "log.retention.ms", Value: ptr("604800000"), Source: kmsg.ConfigSourceDynamicDefaultBrokerConfig},
+				}},
+				{Name: "2"},
+				{Name: "3"},
+			}, nil
+		},
+		DescribeACLsFunc: func(context.Context, *kadm.ACLBuilder) (kadm.DescribeACLsResults, error) {
+			return kadm.DescribeACLsResults{{Described: kadm.DescribedACLs{{
+				Principal:  "User:app",
+				Host:       "*",
+				Type:       kmsg.ACLResourceTypeTopic,
+				Name:       "orders",
+				Pattern:    kadm.ACLPatternLiteral,
+				Operation:  kadm.OpRead,
+				Permission: kmsg.ACLPermissionTypeAllow,
+			}}}}, nil
+		},
+		DescribeClientQuotasFunc: func(context.Context, bool, []kadm.DescribeClientQuotaComponent) (kadm.DescribedClientQuotas, error) {
+			return kadm.DescribedClientQuotas{{
+				Entity: kadm.ClientQuotaEntity{{Type: "user", Name: ptr("app")}},
+				Values: kadm.ClientQuotaValues{{Key: "producer_byte_rate", Value: 1048576}},
+			}}, nil
+		},
+	}
+}
+
+func newTestExporter(brokerID int32, adm *MockAdminClient) (*Exporter, *memoryStore) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	e := NewExporter(brokerID, kafkaclient.Config{}, store, Options{
+		Prefix:   "kafka-metadata",
+		Interval: time.Hour,
+		Timeout:  time.Second,
+	}, testLogger())
+	e.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	e.SetClock(clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)))
+	return e, store
+}
+
+func TestSnapshot(t *testing.T) {
+	e, store := newTestExporter(1, cluster())
+	if err := e.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := "kafka-metadata/abc/20260101T120000Z.json"
+	body, ok := store.objects[key]
+	if !ok {
+		t.Fatalf("expected snapshot %s, got %v", key, store.objects)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		t.Fatalf("invalid snapshot: %v", err)
+	}
+
+	if snapshot.Version != FormatVersion || snapshot.ClusterID != "abc" || snapshot.TakenBy != 1 {
+		t.Errorf("unexpected header %+v", snapshot)
+	}
+	if len(snapshot.Brokers) != 3 || snapshot.Brokers[0].ID != 1 || snapshot.Brokers[0].Rack != "a" {
+		t.Errorf("expected brokers sorted by ID, got %+v", snapshot.Brokers)
+	}
+	if len(snapshot.Topics) != 1 {
+		t.Fatalf("expected only orders, got %+v", snapshot.Topics)
+	}
+	topic := snapshot.Topics[0]
+	if topic.Name != "orders" || topic.Partitions != 2 || topic.ReplicationFactor != 2 || len(topic.Replicas) != 2 || topic.Replicas[1][0] != 2 {
+		t.Errorf("unexpected topic %+v", topic)
+	}
+	if len(topic.Configs) != 1 || topic.Configs[0].Name != "retention.ms" || *topic.Configs[0].Value != "86400000" {
+		t.Errorf("expected only the dynamic topic config, got %+v", topic.Configs)
+	}
+	if len(snapshot.ClusterConfigs) != 1 || snapshot.ClusterConfigs[0].Name != "log.retention.ms" {
+		t.Errorf("unexpected cluster configs %+v", snapshot.ClusterConfigs)
+	}
+	if len(snapshot.BrokerConfigs) != 1 || snapshot.BrokerConfigs[0].Broker != 1 || len(snapshot.BrokerConfigs[0].Configs) != 1 {
+		t.Fatalf("expected the dynamic config of broker 1 only, got %+v", snapshot.BrokerConfigs)
+	}
+	if c := snapshot.BrokerConfigs[0].Configs[0]; !c.Sensitive || c.Value != nil {
+		t.Errorf("expected a sensitive config without value, got %+v", c)
+	}
+	expectedACL := ACL{ResourceType: "TOPIC", ResourceName: "orders", PatternType: "LITERAL", Principal: "User:app", Host: "*", Operation: "READ", Permission: "ALLOW"}
+	if len(snapshot.ACLs) != 1 || snapshot.ACLs[0] != expectedACL {
+		t.Errorf("expected %+v, got %+v", expectedACL, snapshot.ACLs)
+	}
+	if len(snapshot.Quotas) != 1 || *snapshot.Quotas[0].Entity[0].Name != "app" || snapshot.Quotas[0].Values["producer_byte_rate"] != 1048576 {
+		t.Errorf("unexpected quotas %+v", snapshot.Quotas)
+	}
+
+	report, _ := e.LastReport()
+	if report.Key != key || report.Topics != 1 || report.ACLs != 1 || report.Quotas != 1 || report.Error != "" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestSnapshotSkippedOnOtherBrokers(t *testing.T) {
+	e, store := newTestExporter(2, cluster())
+	if err := e.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("expected no scheduled snapshot from broker 2, got %v", store.objects)
+	}
+	if report, _ := e.LastReport(); !report.Skipped {
+		t.Errorf("expected a skipped report, got %+v", report)
+	}
+
+	// An on-demand backup runs on any broker
+	rec := httptest.NewRecorder()
+	e.BackupHandler(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.objects) != 1 {
+		t.Errorf("expected a snapshot, got %v", store.objects)
+	}
+}
+
+func TestSnapshotWithoutAuthorizer(t *testing.T) {
+	adm := cluster()
+	adm.DescribeACLsFunc = func(context.Context, *kadm.ACLBuilder) (kadm.DescribeACLsResults, error) {
+		return kadm.DescribeACLsResults{{Err: kerr.SecurityDisabled}}, nil
+	}
+	e, store := newTestExporter(1, adm)
+	if err := e.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(store.objects["kafka-metadata/abc/20260101T120000Z.json"], &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.ACLs == nil || len(snapshot.ACLs) != 0 {
+		t.Errorf("expected an empty ACL list, got %v", snapshot.ACLs)
+	}
+}
+
+func TestBackupErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(adm *MockAdminClient, store *memoryStore)
+	}{
+		{
+			name: "metadata",
+			setup: func(adm *MockAdminClient, _ *memoryStore) {
+				adm.MetadataFunc = func(context.Context, ...string) (kadm.Metadata, error) {
+					return kadm.Metadata{}, errors.New("timeout")
+				}
+			},
+		},
+		{
+			name: "topic configs",
+			setup: func(adm *MockAdminClient, _ *memoryStore) {
+				adm.DescribeTopicConfigsFunc = func(context.Context, ...string) (kadm.ResourceConfigs, error) {
+					return kadm.ResourceConfigs{{Name: "orders", Err: kerr.TopicAuthorizationFailed}}, nil
+				}
+			},
+		},
+		{
+			name: "quotas",
+			setup: func(adm *MockAdminClient, _ *memoryStore) {
+				adm.DescribeClientQuotasFunc = func(context.Context, bool, []kadm.DescribeClientQuotaComponent) (kadm.DescribedClientQuotas, error) {
+					return nil, kerr.ClusterAuthorizationFailed
+				}
+			},
+		},
+		{
+			name: "store",
+			setup: func(_ *MockAdminClient, store *memoryStore) {
+				store.err = errors.New("403 Forbidden")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adm := cluster()
+			e, store := newTestExporter(1, adm)
+			tt.setup(adm, store)
+			if err := e.Step(context.Background()); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(store.objects) != 0 {
+				t.Errorf("expected no snapshot, got %v", store.objects)
+			}
+			if report, _ := e.LastReport(); report.Error == "" {
+				t.Errorf("expected the error reported, got %+v", report)
+			}
+
+			rec := httptest.NewRecorder()
+			e.BackupHandler(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("expected 500, got %d", rec.Code)
+			}
+		})
+	}
+}
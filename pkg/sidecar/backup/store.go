@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/secrets"
+)
+
+// Store keeps snapshots
+type Store interface {
+	// Put writes an object, replacing any object with the same key
+	Put(ctx context.Context, key string, body []byte) error
+}
+
+// S3Options configures an S3-compatible bucket
+type S3Options struct {
+	// Bucket holds the snapshots
+	Bucket string
+	// Endpoint is the storage API, e.g. https://storage.googleapis.com for
+	// Google Cloud Storage. Empty uses the regional AWS S3 endpoint.
+	Endpoint string
+	// Region signs the requests, e.g. eu-west-1 (auto for Google Cloud Storage)
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken (for temporary
+	// credentials) sign the requests. Google Cloud Storage takes HMAC keys.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3 writes objects to a bucket through the S3 API, which AWS S3, Google Cloud
+// Storage (XML API with HMAC keys) and MinIO all serve. Requests address the
+// bucket in the path, which every one of them accepts.
+type S3 struct {
+	opts   S3Options
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 creates a store writing to an S3-compatible bucket
+func NewS3(opts S3Options, client *http.Client) (*S3, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("s3: the bucket is required")
+	}
+	if opts.Region == "" {
+		return nil, errors.New("s3: the region is required")
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("s3: an access key ID and secret access key are required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://s3." + opts.Region + ".amazonaws.com"
+	}
+	return &S3{opts: opts, client: client, now: time.Now}, nil
+}
+
+// Put implements Store
+func (s *S3) Put(ctx context.Context, key string, body []byte) error {
+	target := strings.TrimSuffix(s.opts.Endpoint, "/") + "/" + url.PathEscape(s.opts.Bucket) + "/" + escapeKey(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+	secrets.SignV4(req, body, secrets.AWSOptions{
+		Region:          s.opts.Region,
+		AccessKeyID:     s.opts.AccessKeyID,
+		SecretAccessKey: s.opts.SecretAccessKey,
+		SessionToken:    s.opts.SessionToken,
+	}, "s3", s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("PUT %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// escapeKey escapes every segment of an object key, keeping the slashes
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
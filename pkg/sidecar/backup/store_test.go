@@ -0,0 +1,79 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestS3Put(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/auto/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+	}))
+	defer server.Close()
+
+	store, err := NewS3(S3Options{
+		Bucket:          "backups",
+		Endpoint:        server.URL,
+		Region:          "auto",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), "kafka-metadata/abc/20260101T120000Z.json", []byte(`{"version":1}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/backups/kafka-metadata/abc/20260101T120000Z.json" || body != `{"version":1}` {
+		t.Errorf("unexpected object %s: %s", path, body)
+	}
+
+	store.opts.AccessKeyID = "OTHER"
+	err = store.Put(context.Background(), "key", nil)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected the rejection, got %v", err)
+	}
+}
+
+func TestNewS3(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      S3Options
+		expectErr bool
+	}{
+		{name: "aws", opts: S3Options{Bucket: "b", Region: "eu-west-1", AccessKeyID: "a", SecretAccessKey: "s"}},
+		{name: "no bucket", opts: S3Options{Region: "eu-west-1", AccessKeyID: "a", SecretAccessKey: "s"}, expectErr: true},
+		{name: "no region", opts: S3Options{Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}, expectErr: true},
+		{name: "no credentials", opts: S3Options{Bucket: "b", Region: "eu-west-1"}, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewS3(tt.opts, http.DefaultClient)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if store.opts.Endpoint != "https://s3.eu-west-1.amazonaws.com" {
+				t.Errorf("expected the regional endpoint, got %s", store.opts.Endpoint)
+			}
+		})
+	}
+}
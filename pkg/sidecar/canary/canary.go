@@ -0,0 +1,345 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the canary loop in the freshness tracker
+const CheckName = "canary"
+
+// retention keeps the canary topic small; records are only read back once
+const retention = time.Hour
+
+// AdminClient defines the Kafka admin operations needed to maintain the canary
+// topic. This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	CreateTopic(ctx context.Context, partitions int32, replicationFactor int16, configs map[string]*string, topic string) (kadm.CreateTopicResponse, error)
+	CreatePartitions(ctx context.Context, add int, topics ...string) (kadm.CreatePartitionsResponses, error)
+	AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+	ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Prober produces a record to the partition and consumes it back
+type Prober func(ctx context.Context, topic string, partition int32) (Probe, error)
+
+// Probe is the timing of a canary record that was produced and consumed back
+type Probe struct {
+	// Produce is how long the leader took to acknowledge the record
+	Produce time.Duration
+	// EndToEnd is how long until the record was consumed back
+	EndToEnd time.Duration
+}
+
+// SafeMode reports whether the sidecar is in safe mode, which holds back
+// changes to the cluster
+type SafeMode interface {
+	Active() bool
+}
+
+// Observer receives the timing of every successful probe
+type Observer interface {
+	ObserveProbe(probe Probe)
+}
+
+// Options configures the canary
+type Options struct {
+	// Topic is the canary topic, created when missing with a partition per broker
+	Topic string
+	// Interval is how often a canary record is produced and consumed
+	Interval time.Duration
+	// ReplicationFactor of the canary topic, capped at the number of brokers
+	ReplicationFactor int
+	// Timeout bounds each probe
+	Timeout time.Duration
+	// RebalanceGrace is how long a broker holding canary replicas may be gone
+	// before its replicas are moved to the remaining brokers. Zero disables
+	// rebalancing the topic.
+	RebalanceGrace time.Duration
+}
+
+// Result is the outcome of the last canary probe
+type Result struct {
+	Success   bool      `json:"success"`
+	Topic     string    `json:"topic"`
+	Partition *int32    `json:"partition,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// ProduceLatencyMs is how long the leader took to acknowledge the record
+	ProduceLatencyMs float64 `json:"produceLatencyMs,omitempty"`
+	// EndToEndLatencyMs is how long until the record was consumed back
+	EndToEndLatencyMs float64 `json:"endToEndLatencyMs,omitempty"`
+	// Skipped explains why no record was produced; a skipped probe neither passes nor fails
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Canary periodically produces a record through the local broker to the canary
+// topic and consumes it back, catching broken produce and fetch paths that
+// metadata-only checks miss
+type Canary struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	prober        Prober
+	tracker       *freshness.Tracker
+	observer      Observer
+	safeMode      SafeMode
+	clock         clock.Clock
+
+	// missingSince is when each broker holding canary replicas was first seen missing
+	missingSince map[int32]time.Time
+
+	mu     sync.RWMutex
+	result *Result
+}
+
+// NewCanary creates a new canary for the local broker
+func NewCanary(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Canary {
+	c := &Canary{
+		brokerID:     brokerID,
+		kafkaConfig:  kafkaConfig,
+		opts:         opts,
+		logger:       logger,
+		clock:        clock.Real,
+		missingSince: map[int32]time.Time{},
+	}
+	// Set default client factory and prober
+	c.clientFactory = c.defaultClientFactory
+	c.prober = c.defaultProber
+	return c
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (c *Canary) SetClientFactory(factory ClientFactory) {
+	c.clientFactory = factory
+}
+
+// SetProber allows overriding the prober for testing
+func (c *Canary) SetProber(prober Prober) {
+	c.prober = prober
+}
+
+// SetTracker records every probe with the freshness tracker
+func (c *Canary) SetTracker(tracker *freshness.Tracker) {
+	c.tracker = tracker
+}
+
+// SetObserver reports the timing of every successful probe to the observer
+func (c *Canary) SetObserver(observer Observer) {
+	c.observer = observer
+}
+
+// SetSafeMode holds back rebalancing the canary topic while in safe mode. The
+// probes go on.
+func (c *Canary) SetSafeMode(safeMode SafeMode) {
+	c.safeMode = safeMode
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (c *Canary) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *Canary) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(c.kafkaConfig)
+}
+
+// LastResult returns the result of the last probe, and false before the first one
+func (c *Canary) LastResult() (Result, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.result == nil {
+		return Result{}, false
+	}
+	return *c.result, true
+}
+
+// CanaryError returns why the last probe failed, or nil when it passed, was
+// skipped or has not run yet
+func (c *Canary) CanaryError() error {
+	result, ok := c.LastResult()
+	if !ok || result.Success || result.Skipped != "" {
+		return nil
+	}
+	return errors.New(result.Error)
+}
+
+// Run probes every Interval until the context is cancelled
+func (c *Canary) Run(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	c.tracker.Register(CheckName)
+
+	for {
+		c.tracker.Record(CheckName, c.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step makes sure the canary topic exists and is spread over the brokers, then
+// produces a record to the partition the broker leads and consumes it back
+func (c *Canary) Step(ctx context.Context) error {
+	result := Result{Topic: c.opts.Topic}
+	err := c.probe(ctx, &result)
+	if err != nil {
+		result.Error = err.Error()
+		c.logger.Warn("canary: probe failed", "topic", c.opts.Topic, "error", err)
+	}
+	result.Success = err == nil && result.Skipped == ""
+	result.CheckedAt = c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.result = &result
+	return err
+}
+
+func (c *Canary) probe(ctx context.Context, result *Result) error {
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	md, err := c.ensureTopic(ctx, adm)
+	if err != nil {
+		return err
+	}
+	// A failed rebalance does not make the produce path broken
+	if err := c.rebalance(ctx, adm, md); err != nil {
+		c.logger.Warn("canary: failed to rebalance topic", "topic", c.opts.Topic, "error", err)
+	}
+	partition, ok := ledPartition(md, c.opts.Topic, c.brokerID)
+	if !ok {
+		// Leadership moves during restarts and rebalances; that is not a broken produce path
+		result.Skipped = fmt.Sprintf("broker %d leads no partition of %s", c.brokerID, c.opts.Topic)
+		return nil
+	}
+	result.Partition = &partition
+
+	probe, err := c.prober(ctx, c.opts.Topic, partition)
+	if err != nil {
+		return err
+	}
+	result.ProduceLatencyMs = float64(probe.Produce) / float64(time.Millisecond)
+	result.EndToEndLatencyMs = float64(probe.EndToEnd) / float64(time.Millisecond)
+	if c.observer != nil {
+		c.observer.ObserveProbe(probe)
+	}
+	return nil
+}
+
+// ensureTopic creates the canary topic with a partition per broker, or adds
+// partitions when brokers have joined, and returns the topic's metadata
+func (c *Canary) ensureTopic(ctx context.Context, adm AdminClient) (kadm.Metadata, error) {
+	md, err := adm.Metadata(ctx, c.opts.Topic)
+	if err != nil {
+		return kadm.Metadata{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	brokers := len(md.Brokers)
+	if brokers == 0 {
+		return kadm.Metadata{}, errors.New("no brokers in cluster metadata")
+	}
+
+	detail, ok := md.Topics[c.opts.Topic]
+	switch {
+	case !ok || errors.Is(detail.Err, kerr.UnknownTopicOrPartition):
+		rf := c.opts.ReplicationFactor
+		if rf > brokers {
+			rf = brokers
+		}
+		configs := map[string]*string{"retention.ms": kadm.StringPtr(strconv.FormatInt(retention.Milliseconds(), 10))}
+		resp, err := adm.CreateTopic(ctx, int32(brokers), int16(rf), configs, c.opts.Topic)
+		// Every broker's sidecar races to create the topic
+		if err == nil && resp.Err != nil && !errors.Is(resp.Err, kerr.TopicAlreadyExists) {
+			err = resp.Err
+		}
+		if err != nil {
+			return kadm.Metadata{}, fmt.Errorf("failed to create canary topic %s: %w", c.opts.Topic, err)
+		}
+		c.logger.Info("canary: created topic", "topic", c.opts.Topic, "partitions", brokers, "replicationFactor", rf)
+	case detail.Err != nil:
+		return kadm.Metadata{}, fmt.Errorf("failed to describe canary topic %s: %w", c.opts.Topic, detail.Err)
+	case len(detail.Partitions) < brokers:
+		add := brokers - len(detail.Partitions)
+		resp, err := adm.CreatePartitions(ctx, add, c.opts.Topic)
+		if err == nil {
+			if r, ok := resp[c.opts.Topic]; ok && r.Err != nil {
+				err = r.Err
+			}
+		}
+		if err != nil {
+			return kadm.Metadata{}, fmt.Errorf("failed to add partitions to canary topic %s: %w", c.opts.Topic, err)
+		}
+		c.logger.Info("canary: added partitions", "topic", c.opts.Topic, "added", add)
+	default:
+		return md, nil
+	}
+
+	// Re-read the metadata so the new partitions and their leaders are known
+	md, err = adm.Metadata(ctx, c.opts.Topic)
+	if err != nil {
+		return kadm.Metadata{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	return md, nil
+}
+
+// ledPartition picks the lowest canary partition the broker leads
+func ledPartition(md kadm.Metadata, topic string, broker int32) (int32, bool) {
+	detail, ok := md.Topics[topic]
+	if !ok {
+		return 0, false
+	}
+	var led []int32
+	for _, p := range detail.Partitions {
+		if p.Leader == broker {
+			led = append(led, p.Partition)
+		}
+	}
+	if len(led) == 0 {
+		return 0, false
+	}
+	sort.Slice(led, func(i, j int) bool { return led[i] < led[j] })
+	return led[0], true
+}
+
+// StatusHandler handles GET /admin/canary requests
+func (c *Canary) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	result, ok := c.LastResult()
+	if !ok {
+		_, _ = web.ReturnResponse(w, Result{Topic: c.opts.Topic})
+		return
+	}
+	_, _ = web.ReturnResponse(w, result)
+}
@@ -0,0 +1,302 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	CreateTopicFunc                func(ctx context.Context, partitions int32, replicationFactor int16, configs map[string]*string, topic string) (kadm.CreateTopicResponse, error)
+	CreatePartitionsFunc           func(ctx context.Context, add int, topics ...string) (kadm.CreatePartitionsResponses, error)
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+	ElectLeadersFunc               func(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) CreateTopic(ctx context.Context, partitions int32, replicationFactor int16, configs map[string]*string, topic string) (kadm.CreateTopicResponse, error) {
+	if m.CreateTopicFunc != nil {
+		return m.CreateTopicFunc(ctx, partitions, replicationFactor, configs, topic)
+	}
+	return kadm.CreateTopicResponse{Topic: topic}, nil
+}
+
+func (m *MockAdminClient) CreatePartitions(ctx context.Context, add int, topics ...string) (kadm.CreatePartitionsResponses, error) {
+	if m.CreatePartitionsFunc != nil {
+		return m.CreatePartitionsFunc(ctx, add, topics...)
+	}
+	return kadm.CreatePartitionsResponses{}, nil
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+	if m.ElectLeadersFunc != nil {
+		return m.ElectLeadersFunc(ctx, how, s)
+	}
+	return kadm.ElectLeadersResults{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+const testTopic = "canary"
+
+// metadata returns three brokers and the canary topic with the given partition leaders
+func metadata(leaders ...int32) kadm.Metadata {
+	md := kadm.Metadata{
+		Brokers: kadm.BrokerDetails{{NodeID: 1}, {NodeID: 2}, {NodeID: 3}},
+		Topics:  kadm.TopicDetails{},
+	}
+	if leaders == nil {
+		md.Topics[testTopic] = kadm.TopicDetail{Topic: testTopic, Err: kerr.UnknownTopicOrPartition}
+		return md
+	}
+	detail := kadm.TopicDetail{Topic: testTopic, Partitions: kadm.PartitionDetails{}}
+	for i, leader := range leaders {
+		detail.Partitions[int32(i)] = kadm.PartitionDetail{Topic: testTopic, Partition: int32(i), Leader: leader}
+	}
+	md.Topics[testTopic] = detail
+	return md
+}
+
+func newTestCanary(adm AdminClient, prober Prober) *Canary {
+	c := NewCanary(2, kafkaclient.Config{}, Options{
+		Topic:             testTopic,
+		Interval:          time.Minute,
+		ReplicationFactor: 5,
+		Timeout:           10 * time.Second,
+	}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	c.SetProber(prober)
+	return c
+}
+
+func okProber(context.Context, string, int32) (Probe, error) {
+	return Probe{Produce: 4 * time.Millisecond, EndToEnd: 9 * time.Millisecond}, nil
+}
+
+func TestStepCreatesTopic(t *testing.T) {
+	created := false
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			if !created {
+				return metadata(), nil
+			}
+			return metadata(1, 2, 3), nil
+		},
+		CreateTopicFunc: func(_ context.Context, partitions int32, rf int16, configs map[string]*string, topic string) (kadm.CreateTopicResponse, error) {
+			created = true
+			if partitions != 3 || rf != 3 {
+				t.Errorf("expected 3 partitions with replication factor 3, got %d and %d", partitions, rf)
+			}
+			if configs["retention.ms"] == nil {
+				t.Error("expected retention.ms to be set")
+			}
+			return kadm.CreateTopicResponse{Topic: topic}, nil
+		},
+	}
+
+	c := newTestCanary(adm, func(_ context.Context, topic string, partition int32) (Probe, error) {
+		if topic != testTopic || partition != 1 {
+			t.Errorf("expected to probe %s-1, got %s-%d", testTopic, topic, partition)
+		}
+		return okProber(context.Background(), topic, partition)
+	})
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, ok := c.LastResult()
+	if !ok || !result.Success || result.ProduceLatencyMs != 4 || result.EndToEndLatencyMs != 9 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !created {
+		t.Error("expected the canary topic to be created")
+	}
+}
+
+func TestStepTopicAlreadyCreated(t *testing.T) {
+	calls := 0
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			calls++
+			if calls == 1 {
+				return metadata(), nil
+			}
+			return metadata(2, 3, 1), nil
+		},
+		CreateTopicFunc: func(_ context.Context, _ int32, _ int16, _ map[string]*string, topic string) (kadm.CreateTopicResponse, error) {
+			return kadm.CreateTopicResponse{Topic: topic, Err: kerr.TopicAlreadyExists}, nil
+		},
+	}
+
+	if err := newTestCanary(adm, okProber).Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStepAddsPartitions(t *testing.T) {
+	added := 0
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			if added == 0 {
+				return metadata(1, 3), nil
+			}
+			return metadata(1, 3, 2), nil
+		},
+		CreatePartitionsFunc: func(_ context.Context, add int, _ ...string) (kadm.CreatePartitionsResponses, error) {
+			added = add
+			return kadm.CreatePartitionsResponses{}, nil
+		},
+	}
+
+	c := newTestCanary(adm, okProber)
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("expected 1 partition to be added, got %d", added)
+	}
+	if result, _ := c.LastResult(); result.Partition == nil || *result.Partition != 2 {
+		t.Errorf("expected to probe partition 2, got %+v", result)
+	}
+}
+
+func TestStepResults(t *testing.T) {
+	tests := []struct {
+		name          string
+		leaders       []int32
+		prober        Prober
+		expectSuccess bool
+		expectSkipped bool
+		expectError   bool
+	}{
+		{
+			name:          "probe passes",
+			leaders:       []int32{1, 2, 3},
+			prober:        okProber,
+			expectSuccess: true,
+		},
+		{
+			name:    "probe fails",
+			leaders: []int32{1, 2, 3},
+			prober: func(context.Context, string, int32) (Probe, error) {
+				return Probe{}, errors.New("NOT_ENOUGH_REPLICAS")
+			},
+			expectError: true,
+		},
+		{
+			name:    "broker leads no partition",
+			leaders: []int32{1, 3, 3},
+			prober: func(context.Context, string, int32) (Probe, error) {
+				t.Error("expected no probe")
+				return Probe{}, nil
+			},
+			expectSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adm := &MockAdminClient{
+				MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+					return metadata(tt.leaders...), nil
+				},
+			}
+			c := newTestCanary(adm, tt.prober)
+
+			err := c.Step(context.Background())
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+			result, _ := c.LastResult()
+			if result.Success != tt.expectSuccess || (result.Skipped != "") != tt.expectSkipped {
+				t.Errorf("unexpected result: %+v", result)
+			}
+			if (c.CanaryError() != nil) != tt.expectError {
+				t.Errorf("expected canary error %v, got %v", tt.expectError, c.CanaryError())
+			}
+		})
+	}
+}
+
+// recordingObserver collects the probes it observes
+type recordingObserver struct {
+	probes []Probe
+}
+
+func (o *recordingObserver) ObserveProbe(probe Probe) {
+	o.probes = append(o.probes, probe)
+}
+
+func TestStepObservesProbes(t *testing.T) {
+	leaders := []int32{1, 2, 3}
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return metadata(leaders...), nil
+		},
+	}
+	failing := false
+	c := newTestCanary(adm, func(ctx context.Context, topic string, partition int32) (Probe, error) {
+		if failing {
+			return Probe{}, errors.New("NOT_ENOUGH_REPLICAS")
+		}
+		return okProber(ctx, topic, partition)
+	})
+	observer := &recordingObserver{}
+	c.SetObserver(observer)
+
+	_ = c.Step(context.Background())
+	failing = true
+	_ = c.Step(context.Background())
+	leaders = []int32{1, 3, 3}
+	_ = c.Step(context.Background())
+
+	// Failed and skipped probes have no meaningful latency
+	if len(observer.probes) != 1 || observer.probes[0].EndToEnd != 9*time.Millisecond {
+		t.Errorf("expected only the successful probe to be observed, got %+v", observer.probes)
+	}
+}
+
+func TestCanaryErrorBeforeFirstProbe(t *testing.T) {
+	c := newTestCanary(&MockAdminClient{}, okProber)
+	if err := c.CanaryError(); err != nil {
+		t.Errorf("expected no error before the first probe, got %v", err)
+	}
+	if _, ok := c.LastResult(); ok {
+		t.Error("expected no result before the first probe")
+	}
+}
@@ -0,0 +1,160 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// rebalance keeps the canary partitions spread over the brokers: every live
+// broker is the preferred leader of a partition, and no replica is left on a
+// broker gone for longer than RebalanceGrace. Brokers gone for less, such as
+// during a rolling restart, keep their partitions. Every sidecar tracks the
+// brokers, but only the lowest live broker's submits the plan, which is the
+// same on all of them. Nothing is submitted in safe mode.
+func (c *Canary) rebalance(ctx context.Context, adm AdminClient, md kadm.Metadata) error {
+	if c.opts.RebalanceGrace <= 0 {
+		return nil
+	}
+	detail, ok := md.Topics[c.opts.Topic]
+	if !ok || detail.Err != nil {
+		return nil
+	}
+
+	live := make(map[int32]bool, len(md.Brokers))
+	lowest := int32(-1)
+	for _, b := range md.Brokers {
+		live[b.NodeID] = true
+		if lowest < 0 || b.NodeID < lowest {
+			lowest = b.NodeID
+		}
+	}
+	placed, departed := c.trackBrokers(detail, live)
+	if c.brokerID != lowest {
+		return nil
+	}
+	if c.safeMode != nil && c.safeMode.Active() {
+		return nil
+	}
+
+	if len(departed) == 0 && covered(detail, live) {
+		return c.electPreferred(ctx, adm, detail, live)
+	}
+
+	rf := c.opts.ReplicationFactor
+	if rf > len(placed) {
+		rf = len(placed)
+	}
+	moves := reassign.PlanSpread(md, c.opts.Topic, placed, rf)
+	if len(moves) == 0 {
+		return nil
+	}
+
+	set := reassign.TopicsSet(moves)
+	inProgress, err := adm.ListPartitionReassignments(ctx, set)
+	if err != nil {
+		return fmt.Errorf("failed to list partition reassignments: %w", err)
+	}
+	if len(inProgress[c.opts.Topic]) > 0 {
+		// The previous rebalance is still copying replicas
+		return nil
+	}
+
+	if err := reassign.Execute(ctx, adm, moves); err != nil {
+		return err
+	}
+	c.logger.Info("canary: rebalancing topic", "topic", c.opts.Topic, "moves", len(moves), "brokers", placed, "departed", departed)
+	return nil
+}
+
+// trackBrokers returns the brokers to place partitions on, the live brokers and
+// those missing for less than RebalanceGrace, and the departed brokers still
+// holding replicas. Only Step calls it, so the missing brokers need no lock.
+func (c *Canary) trackBrokers(detail kadm.TopicDetail, live map[int32]bool) ([]int32, []int32) {
+	now := c.clock.Now()
+	referenced := map[int32]bool{}
+	for _, p := range detail.Partitions {
+		for _, r := range p.Replicas {
+			referenced[r] = true
+		}
+	}
+
+	for id := range c.missingSince {
+		if live[id] || !referenced[id] {
+			delete(c.missingSince, id)
+		}
+	}
+
+	var placed, departed []int32
+	for id := range live {
+		placed = append(placed, id)
+	}
+	for id := range referenced {
+		if live[id] {
+			continue
+		}
+		since, ok := c.missingSince[id]
+		if !ok {
+			since = now
+			c.missingSince[id] = now
+		}
+		if now.Sub(since) < c.opts.RebalanceGrace {
+			placed = append(placed, id)
+		} else {
+			departed = append(departed, id)
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool { return placed[i] < placed[j] })
+	sort.Slice(departed, func(i, j int) bool { return departed[i] < departed[j] })
+	return placed, departed
+}
+
+// covered reports whether every live broker is the preferred leader of a partition
+func covered(detail kadm.TopicDetail, live map[int32]bool) bool {
+	preferred := map[int32]bool{}
+	for _, p := range detail.Partitions {
+		if len(p.Replicas) > 0 {
+			preferred[p.Replicas[0]] = true
+		}
+	}
+	for id := range live {
+		if !preferred[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// electPreferred hands leadership back to the preferred replicas, which a
+// completed reassignment does not do by itself. Partitions whose preferred
+// replica is down or out of sync are left alone.
+func (c *Canary) electPreferred(ctx context.Context, adm AdminClient, detail kadm.TopicDetail, live map[int32]bool) error {
+	set := kadm.TopicsSet{}
+	for _, p := range detail.Partitions {
+		if len(p.Replicas) == 0 || p.Leader == p.Replicas[0] || !live[p.Replicas[0]] || !containsBroker(p.ISR, p.Replicas[0]) {
+			continue
+		}
+		set.Add(c.opts.Topic, p.Partition)
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	if err := reassign.ElectPreferredLeaders(ctx, adm, set); err != nil {
+		return err
+	}
+	c.logger.Info("canary: elected preferred leaders", "topic", c.opts.Topic, "partitions", len(set[c.opts.Topic]))
+	return nil
+}
+
+func containsBroker(ids []int32, id int32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
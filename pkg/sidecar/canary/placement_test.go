@@ -0,0 +1,233 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// placedMetadata returns the live brokers and the canary topic with the given
+// replica assignments, led by their first replica
+func placedMetadata(brokers []int32, assignments ...[]int32) kadm.Metadata {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{}}
+	for _, b := range brokers {
+		md.Brokers = append(md.Brokers, kadm.BrokerDetail{NodeID: b})
+	}
+	detail := kadm.TopicDetail{Topic: testTopic, Partitions: kadm.PartitionDetails{}}
+	for i, replicas := range assignments {
+		detail.Partitions[int32(i)] = kadm.PartitionDetail{
+			Topic:     testTopic,
+			Partition: int32(i),
+			Leader:    replicas[0],
+			Replicas:  replicas,
+			ISR:       replicas,
+		}
+	}
+	md.Topics[testTopic] = detail
+	return md
+}
+
+// newPlacementCanary returns a canary for broker 1, the lowest broker in the
+// test metadata, so it submits rebalances
+func newPlacementCanary(adm AdminClient, clk clock.Clock) *Canary {
+	c := NewCanary(1, kafkaclient.Config{}, Options{
+		Topic:             testTopic,
+		Interval:          time.Minute,
+		ReplicationFactor: 2,
+		Timeout:           10 * time.Second,
+		RebalanceGrace:    10 * time.Minute,
+	}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	c.SetProber(okProber)
+	c.SetClock(clk)
+	return c
+}
+
+func TestRebalanceWaitsForGrace(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	// Broker 3 is gone; its partition must stay until the grace period passes
+	md := placedMetadata([]int32{1, 2}, []int32{1, 2}, []int32{2, 3}, []int32{3, 1})
+	var altered kadm.AlterPartitionAssignmentsReq
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		AlterPartitionAssignmentsFunc: func(_ context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			altered = req
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clk)
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if altered != nil {
+		t.Fatalf("expected no rebalance within the grace period, got %v", altered)
+	}
+
+	clk.Advance(10 * time.Minute)
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[int32]string{1: "[2 1]", 2: "[1 2]"}
+	if len(altered[testTopic]) != len(want) {
+		t.Fatalf("expected partitions 1 and 2 to move off broker 3, got %v", altered)
+	}
+	for p, replicas := range want {
+		if got := fmt.Sprint(altered[testTopic][p]); got != replicas {
+			t.Errorf("partition %d: expected %s, got %s", p, replicas, got)
+		}
+	}
+}
+
+func TestRebalanceCoversJoinedBroker(t *testing.T) {
+	// Broker 3 joined and got a partition, but not as its preferred leader
+	md := placedMetadata([]int32{1, 2, 3}, []int32{1, 2}, []int32{2, 1}, []int32{1, 3})
+	var altered kadm.AlterPartitionAssignmentsReq
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		AlterPartitionAssignmentsFunc: func(_ context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			altered = req
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clock.NewFake(time.Now()))
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(altered[testTopic][1]); got != "[2 3]" {
+		t.Errorf("partition 1: expected [2 3], got %s", got)
+	}
+	if got := fmt.Sprint(altered[testTopic][2]); got != "[3 1]" {
+		t.Errorf("partition 2: expected [3 1], got %s", got)
+	}
+}
+
+func TestRebalanceOnlyLowestBrokerSubmits(t *testing.T) {
+	md := placedMetadata([]int32{1, 2, 3}, []int32{1, 2}, []int32{2, 1}, []int32{1, 3})
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		AlterPartitionAssignmentsFunc: func(context.Context, kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			t.Error("expected only broker 1's sidecar to rebalance")
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clock.NewFake(time.Now()))
+	c.brokerID = 2
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRebalanceSkipsInProgress(t *testing.T) {
+	md := placedMetadata([]int32{1, 2, 3}, []int32{1, 2}, []int32{2, 1}, []int32{1, 3})
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		ListPartitionReassignmentsFunc: func(context.Context, kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+			return kadm.ListPartitionReassignmentsResponses{
+				testTopic: {2: {Topic: testTopic, Partition: 2, AddingReplicas: []int32{3}}},
+			}, nil
+		},
+		AlterPartitionAssignmentsFunc: func(context.Context, kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			t.Error("expected no rebalance while one is in progress")
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clock.NewFake(time.Now()))
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRebalanceElectsPreferredLeaders(t *testing.T) {
+	md := placedMetadata([]int32{1, 2, 3}, []int32{1, 2}, []int32{2, 3}, []int32{3, 1})
+	// A completed reassignment left partition 2 led by its follower
+	p := md.Topics[testTopic].Partitions[2]
+	p.Leader = 1
+	md.Topics[testTopic].Partitions[2] = p
+
+	var elected kadm.TopicsSet
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		ElectLeadersFunc: func(_ context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+			if how != kadm.ElectPreferredReplica {
+				t.Errorf("expected a preferred replica election, got %v", how)
+			}
+			elected = s
+			return kadm.ElectLeadersResults{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clock.NewFake(time.Now()))
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := elected[testTopic][2]; !ok || len(elected[testTopic]) != 1 {
+		t.Errorf("expected only partition 2 to be elected, got %v", elected)
+	}
+}
+
+// fakeSafeMode is in safe mode while active is set
+type fakeSafeMode struct {
+	active bool
+}
+
+func (f *fakeSafeMode) Active() bool {
+	return f.active
+}
+
+func TestRebalanceHeldInSafeMode(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	// Broker 3 is gone and partition 1 is led by its follower
+	md := placedMetadata([]int32{1, 2}, []int32{1, 2}, []int32{2, 3}, []int32{3, 1})
+	p := md.Topics[testTopic].Partitions[1]
+	p.Leader = 3
+	md.Topics[testTopic].Partitions[1] = p
+	var altered, elected int
+	adm := &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) { return md, nil },
+		AlterPartitionAssignmentsFunc: func(context.Context, kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			altered++
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+		ElectLeadersFunc: func(context.Context, kadm.ElectLeadersHow, kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+			elected++
+			return kadm.ElectLeadersResults{}, nil
+		},
+	}
+	c := newPlacementCanary(adm, clk)
+	safeMode := &fakeSafeMode{active: true}
+	c.SetSafeMode(safeMode)
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clk.Advance(10 * time.Minute)
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if altered != 0 || elected != 0 {
+		t.Fatalf("expected no reassignment or election in safe mode, got %d and %d", altered, elected)
+	}
+	if result, _ := c.LastResult(); !result.Success {
+		t.Errorf("expected the probe to go on in safe mode, got %+v", result)
+	}
+
+	safeMode.active = false
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if altered != 1 {
+		t.Errorf("expected the rebalance once safe mode is left, got %d reassignments", altered)
+	}
+}
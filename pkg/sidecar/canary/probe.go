@@ -0,0 +1,64 @@
+package canary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// defaultProber produces a uniquely keyed record to the partition, then fetches
+// the partition from the record's offset until the record is read back
+func (c *Canary) defaultProber(ctx context.Context, topic string, partition int32) (Probe, error) {
+	producer, closeProducer, err := kafkaclient.NewClient(c.kafkaConfig, kgo.RecordPartitioner(kgo.ManualPartitioner()))
+	if err != nil {
+		return Probe{}, err
+	}
+	defer closeProducer()
+
+	start := time.Now()
+	key := []byte(fmt.Sprintf("%d-%d", c.brokerID, start.UnixNano()))
+	record := &kgo.Record{
+		Topic:     topic,
+		Partition: partition,
+		Key:       key,
+		Value:     []byte(start.UTC().Format(time.RFC3339Nano)),
+	}
+	produced, err := producer.ProduceSync(ctx, record).First()
+	if err != nil {
+		return Probe{}, fmt.Errorf("failed to produce canary record: %w", err)
+	}
+	probe := Probe{Produce: time.Since(start)}
+
+	consumer, closeConsumer, err := kafkaclient.NewClient(c.kafkaConfig, kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{
+		topic: {partition: kgo.NewOffset().At(produced.Offset)},
+	}))
+	if err != nil {
+		return Probe{}, err
+	}
+	defer closeConsumer()
+
+	for {
+		fetches := consumer.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			return Probe{}, fmt.Errorf("canary record not consumed back: %w", err)
+		}
+		if errs := fetches.Errors(); len(errs) > 0 {
+			return Probe{}, fmt.Errorf("failed to consume canary record: %w", errs[0].Err)
+		}
+		var found bool
+		fetches.EachRecord(func(r *kgo.Record) {
+			if r.Offset == produced.Offset && bytes.Equal(r.Key, key) {
+				found = true
+			}
+		})
+		if found {
+			probe.EndToEnd = time.Since(start)
+			return probe, nil
+		}
+	}
+}
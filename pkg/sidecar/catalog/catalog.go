@@ -0,0 +1,266 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the catalog refresh loop in the freshness tracker
+const CheckName = "catalog"
+
+// AdminClient defines the Kafka admin operations needed to build the catalog.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeAllLogDirs(ctx context.Context, s kadm.TopicsSet) (kadm.DescribedAllLogDirs, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the topic catalog
+type Options struct {
+	// RefreshInterval is how often the snapshot is rebuilt
+	RefreshInterval time.Duration
+	// TagsFile is a mounted file of topic tags (e.g. owner). Empty disables tags.
+	TagsFile string
+	// Timeout bounds each refresh
+	Timeout time.Duration
+}
+
+// Topic is a single catalog entry
+type Topic struct {
+	Name              string `json:"name"`
+	Internal          bool   `json:"internal,omitempty"`
+	Partitions        int    `json:"partitions"`
+	ReplicationFactor int    `json:"replicationFactor"`
+	// SizeBytes is the size of the largest replica of each partition, summed
+	SizeBytes int64 `json:"sizeBytes"`
+	// Configs holds the configs overridden on the topic
+	Configs map[string]string `json:"configs,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+
+	// effective holds every config value, defaults included, for filtering
+	effective map[string]string
+}
+
+// Snapshot is the catalog as of its last refresh
+type Snapshot struct {
+	RefreshedAt *time.Time
+	Topics      []Topic
+	// Error is set when the last refresh failed; Topics are then from the last
+	// successful refresh
+	Error string
+}
+
+// Catalog periodically snapshots every topic's metadata, configs, size and tags
+type Catalog struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu       sync.RWMutex
+	snapshot Snapshot
+}
+
+// NewCatalog creates a new topic catalog
+func NewCatalog(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Catalog {
+	c := &Catalog{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+	}
+	// Set default client factory
+	c.clientFactory = c.defaultClientFactory
+	return c
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (c *Catalog) SetClientFactory(factory ClientFactory) {
+	c.clientFactory = factory
+}
+
+// SetTracker records every refresh with the freshness tracker
+func (c *Catalog) SetTracker(tracker *freshness.Tracker) {
+	c.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (c *Catalog) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *Catalog) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(c.kafkaConfig)
+}
+
+// Snapshot returns the last catalog snapshot
+func (c *Catalog) Snapshot() Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshot
+}
+
+// Run refreshes the snapshot every RefreshInterval until the context is cancelled
+func (c *Catalog) Run(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+	c.tracker.Register(CheckName)
+
+	for {
+		c.tracker.Record(CheckName, c.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step rebuilds the snapshot from the cluster and the tags file
+func (c *Catalog) Step(ctx context.Context) error {
+	tags := Tags{}
+	if c.opts.TagsFile != "" {
+		var err error
+		if tags, err = LoadTags(c.opts.TagsFile); err != nil {
+			c.fail(err)
+			return err
+		}
+	}
+
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		c.fail(err)
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	topics, err := c.build(ctx, adm, tags)
+	if err != nil {
+		c.fail(err)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	c.snapshot = Snapshot{RefreshedAt: &now, Topics: topics}
+	return nil
+}
+
+// build describes every topic and returns the entries sorted by name
+func (c *Catalog) build(ctx context.Context, adm AdminClient, tags Tags) ([]Topic, error) {
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	names := md.Topics.Names()
+	sort.Strings(names)
+	if len(names) == 0 {
+		return []Topic{}, nil
+	}
+
+	configs, err := adm.DescribeTopicConfigs(ctx, names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	described := make(map[string]kadm.ResourceConfig, len(configs))
+	for _, rc := range configs {
+		described[rc.Name] = rc
+	}
+
+	sizes, err := c.sizes(ctx, adm)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]Topic, 0, len(names))
+	for _, name := range names {
+		detail := md.Topics[name]
+		topic := Topic{
+			Name:       name,
+			Internal:   detail.IsInternal,
+			Partitions: len(detail.Partitions),
+			Tags:       tags.For(name),
+			effective:  map[string]string{},
+		}
+		for _, p := range detail.Partitions {
+			if len(p.Replicas) > topic.ReplicationFactor {
+				topic.ReplicationFactor = len(p.Replicas)
+			}
+			topic.SizeBytes += sizes[name][p.Partition]
+		}
+		// A topic deleted since the metadata request has no configs; keep the entry
+		for _, cfg := range described[name].Configs {
+			if cfg.Value == nil || cfg.Sensitive {
+				continue
+			}
+			topic.effective[cfg.Key] = *cfg.Value
+			if cfg.Source == kmsg.ConfigSourceDynamicTopicConfig {
+				if topic.Configs == nil {
+					topic.Configs = map[string]string{}
+				}
+				topic.Configs[cfg.Key] = *cfg.Value
+			}
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+// sizes returns the size of the largest replica of every partition. Brokers that
+// fail to describe their log dirs are skipped, so sizes may be partial.
+func (c *Catalog) sizes(ctx context.Context, adm AdminClient) (map[string]map[int32]int64, error) {
+	logDirs, err := adm.DescribeAllLogDirs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe log dirs: %w", err)
+	}
+	sizes := map[string]map[int32]int64{}
+	for broker, dirs := range logDirs {
+		if err := dirs.Error(); err != nil {
+			c.logger.Warn("catalog: topic sizes may be incomplete", "broker", broker, "error", err)
+		}
+		dirs.EachPartition(func(p kadm.DescribedLogDirPartition) {
+			// Replicas being moved between log dirs would otherwise count twice
+			if p.IsFuture {
+				return
+			}
+			if sizes[p.Topic] == nil {
+				sizes[p.Topic] = map[int32]int64{}
+			}
+			if p.Size > sizes[p.Topic][p.Partition] {
+				sizes[p.Topic][p.Partition] = p.Size
+			}
+		})
+	}
+	return sizes, nil
+}
+
+func (c *Catalog) fail(err error) {
+	c.logger.Warn("catalog: refresh failed", "error", err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot.Error = err.Error()
+}
@@ -0,0 +1,211 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc             func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigsFunc func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeAllLogDirsFunc   func(ctx context.Context, s kadm.TopicsSet) (kadm.DescribedAllLogDirs, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+	if m.DescribeTopicConfigsFunc != nil {
+		return m.DescribeTopicConfigsFunc(ctx, topics...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) DescribeAllLogDirs(ctx context.Context, s kadm.TopicsSet) (kadm.DescribedAllLogDirs, error) {
+	if m.DescribeAllLogDirsFunc != nil {
+		return m.DescribeAllLogDirsFunc(ctx, s)
+	}
+	return kadm.DescribedAllLogDirs{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func config(key, value string, source kmsg.ConfigSource) kadm.Config {
+	return kadm.Config{Key: key, Value: &value, Source: source}
+}
+
+// clusterMock has orders (2 partitions, RF 2, retention overridden) and
+// __consumer_offsets (1 partition, RF 1) on brokers 0 and 1
+func clusterMock() *MockAdminClient {
+	return &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return kadm.Metadata{Topics: kadm.TopicDetails{
+				"orders": kadm.TopicDetail{
+					Topic: "orders",
+					Partitions: kadm.PartitionDetails{
+						0: {Partition: 0, Replicas: []int32{0, 1}},
+						1: {Partition: 1, Replicas: []int32{1, 0}},
+					},
+				},
+				"__consumer_offsets": kadm.TopicDetail{
+					Topic:      "__consumer_offsets",
+					IsInternal: true,
+					Partitions: kadm.PartitionDetails{
+						0: {Partition: 0, Replicas: []int32{0}},
+					},
+				},
+			}}, nil
+		},
+		DescribeTopicConfigsFunc: func(_ context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			var configs kadm.ResourceConfigs
+			for _, topic := range topics {
+				rc := kadm.ResourceConfig{Name: topic, Configs: []kadm.Config{
+					config("cleanup.policy", "delete", kmsg.ConfigSourceDefaultConfig),
+					{Key: "sasl.jaas.config", Sensitive: true},
+				}}
+				if topic == "orders" {
+					rc.Configs = append(rc.Configs, config("retention.ms", "86400000", kmsg.ConfigSourceDynamicTopicConfig))
+				}
+				configs = append(configs, rc)
+			}
+			return configs, nil
+		},
+		DescribeAllLogDirsFunc: func(context.Context, kadm.TopicsSet) (kadm.DescribedAllLogDirs, error) {
+			return kadm.DescribedAllLogDirs{
+				0: {"/var/kafka-logs": kadm.DescribedLogDir{Broker: 0, Dir: "/var/kafka-logs", Topics: kadm.DescribedLogDirTopics{
+					"orders": {
+						0: {Topic: "orders", Partition: 0, Size: 100},
+						1: {Topic: "orders", Partition: 1, Size: 40},
+					},
+					"__consumer_offsets": {0: {Topic: "__consumer_offsets", Partition: 0, Size: 5}},
+				}}},
+				1: {"/var/kafka-logs": kadm.DescribedLogDir{Broker: 1, Dir: "/var/kafka-logs", Topics: kadm.DescribedLogDirTopics{
+					"orders": {
+						0: {Topic: "orders", Partition: 0, Size: 90},
+						1: {Topic: "orders", Partition: 1, Size: 50},
+					},
+				}}},
+			}, nil
+		},
+	}
+}
+
+func newTestCatalog(adm AdminClient, tagsFile string) *Catalog {
+	c := NewCatalog(kafkaclient.Config{}, Options{RefreshInterval: time.Minute, TagsFile: tagsFile, Timeout: 5 * time.Second}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	return c
+}
+
+func TestStep(t *testing.T) {
+	tagsFile := filepath.Join(t.TempDir(), "tags.json")
+	if err := os.WriteFile(tagsFile, []byte(`{"topics": {"orders": {"owner": "payments"}, "__.*": {"owner": "platform"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestCatalog(clusterMock(), tagsFile)
+
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot := c.Snapshot()
+	if snapshot.RefreshedAt == nil || len(snapshot.Topics) != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	offsets, orders := snapshot.Topics[0], snapshot.Topics[1]
+	if offsets.Name != "__consumer_offsets" || !offsets.Internal || offsets.ReplicationFactor != 1 || offsets.SizeBytes != 5 {
+		t.Errorf("unexpected internal topic entry: %+v", offsets)
+	}
+	if orders.Partitions != 2 || orders.ReplicationFactor != 2 {
+		t.Errorf("unexpected orders entry: %+v", orders)
+	}
+	// Largest replica of each partition: 100 + 50
+	if orders.SizeBytes != 150 {
+		t.Errorf("expected orders size 150, got %d", orders.SizeBytes)
+	}
+	if len(orders.Configs) != 1 || orders.Configs["retention.ms"] != "86400000" {
+		t.Errorf("expected only overridden configs, got %v", orders.Configs)
+	}
+	if orders.effective["cleanup.policy"] != "delete" {
+		t.Errorf("expected default configs to be kept for filtering, got %v", orders.effective)
+	}
+	if _, ok := orders.effective["sasl.jaas.config"]; ok {
+		t.Error("expected sensitive configs to be skipped")
+	}
+	if orders.Tags[OwnerTag] != "payments" || offsets.Tags[OwnerTag] != "platform" {
+		t.Errorf("unexpected tags: %v, %v", orders.Tags, offsets.Tags)
+	}
+}
+
+func TestStepFailureKeepsSnapshot(t *testing.T) {
+	adm := clusterMock()
+	c := newTestCatalog(adm, "")
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	adm.MetadataFunc = func(context.Context, ...string) (kadm.Metadata, error) {
+		return kadm.Metadata{}, errors.New("connection lost")
+	}
+	if err := c.Step(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	snapshot := c.Snapshot()
+	if len(snapshot.Topics) != 2 || snapshot.Error == "" {
+		t.Errorf("expected the last snapshot with an error, got %+v", snapshot)
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags, err := ParseTags([]byte(`{"topics": {
+		"payments-.*": {"owner": "payments", "tier": "1"},
+		"payments-audit": {"owner": "compliance"}
+	}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		topic    string
+		expected map[string]string
+	}{
+		{topic: "payments-events", expected: map[string]string{"owner": "payments", "tier": "1"}},
+		{topic: "payments-audit", expected: map[string]string{"owner": "compliance", "tier": "1"}},
+		{topic: "orders", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			got := tags.For(tt.topic)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("expected %s=%s, got %v", k, v, got)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseTags([]byte(`{"topics": {"payments-([": {}}}`)); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+}
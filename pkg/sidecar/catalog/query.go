@@ -0,0 +1,221 @@
+package catalog
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Sort orders supported by the catalog
+const (
+	SortName              = "name"
+	SortSize              = "size"
+	SortPartitions        = "partitions"
+	SortReplicationFactor = "replicationFactor"
+)
+
+// Query filters, sorts and pages the catalog. Zero values do not filter.
+type Query struct {
+	// Name matches the whole topic name
+	Name *regexp.Regexp
+	// Configs requires exact effective config values, defaults included
+	Configs           map[string]string
+	MinSize           *int64
+	MaxSize           *int64
+	Owner             string
+	ReplicationFactor int
+	Internal          *bool
+	Sort              string
+	Descending        bool
+	Offset            int
+	Limit             int
+}
+
+// Page is one page of catalog results
+type Page struct {
+	RefreshedAt *time.Time `json:"refreshedAt,omitempty"`
+	// Total is the number of topics matching the filters across all pages
+	Total  int     `json:"total"`
+	Offset int     `json:"offset"`
+	Limit  int     `json:"limit"`
+	Topics []Topic `json:"topics"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// ParseQuery parses catalog query parameters:
+// name, config (key=value, repeatable), minSize, maxSize, owner, rf, internal,
+// sort (name, size, partitions, replicationFactor), order (asc, desc), offset and limit
+func ParseQuery(values url.Values) (Query, error) {
+	q := Query{Sort: SortName, Limit: defaultLimit, Owner: values.Get("owner")}
+
+	if name := values.Get("name"); name != "" {
+		re, err := regexp.Compile("^(?:" + name + ")$")
+		if err != nil {
+			return Query{}, cplnErrors.Validationf("invalid name pattern: %v", err)
+		}
+		q.Name = re
+	}
+	for _, kv := range values["config"] {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return Query{}, cplnErrors.Validationf("invalid config filter %q (expected key=value)", kv)
+		}
+		if q.Configs == nil {
+			q.Configs = map[string]string{}
+		}
+		q.Configs[key] = value
+	}
+
+	var err error
+	if q.MinSize, err = optionalInt64(values, "minSize"); err != nil {
+		return Query{}, err
+	}
+	if q.MaxSize, err = optionalInt64(values, "maxSize"); err != nil {
+		return Query{}, err
+	}
+	if raw := values.Get("rf"); raw != "" {
+		if q.ReplicationFactor, err = strconv.Atoi(raw); err != nil || q.ReplicationFactor < 1 {
+			return Query{}, cplnErrors.Validationf("invalid rf: %q", raw)
+		}
+	}
+	if raw := values.Get("internal"); raw != "" {
+		internal, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Query{}, cplnErrors.Validationf("invalid internal: %q", raw)
+		}
+		q.Internal = &internal
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		switch raw {
+		case SortName, SortSize, SortPartitions, SortReplicationFactor:
+			q.Sort = raw
+		default:
+			return Query{}, cplnErrors.Validationf("unsupported sort: %q (supported: name, size, partitions, replicationFactor)", raw)
+		}
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Descending = true
+	default:
+		return Query{}, cplnErrors.Validationf("unsupported order: %q (supported: asc, desc)", values.Get("order"))
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		if q.Offset, err = strconv.Atoi(raw); err != nil || q.Offset < 0 {
+			return Query{}, cplnErrors.Validationf("invalid offset: %q", raw)
+		}
+	}
+	if raw := values.Get("limit"); raw != "" {
+		if q.Limit, err = strconv.Atoi(raw); err != nil || q.Limit < 1 || q.Limit > maxLimit {
+			return Query{}, cplnErrors.Validationf("invalid limit: %q (1-%d)", raw, maxLimit)
+		}
+	}
+	return q, nil
+}
+
+// Matches reports whether the topic passes every filter
+func (q Query) Matches(t Topic) bool {
+	switch {
+	case q.Name != nil && !q.Name.MatchString(t.Name):
+		return false
+	case q.MinSize != nil && t.SizeBytes < *q.MinSize:
+		return false
+	case q.MaxSize != nil && t.SizeBytes > *q.MaxSize:
+		return false
+	case q.Owner != "" && t.Tags[OwnerTag] != q.Owner:
+		return false
+	case q.ReplicationFactor != 0 && t.ReplicationFactor != q.ReplicationFactor:
+		return false
+	case q.Internal != nil && t.Internal != *q.Internal:
+		return false
+	}
+	for key, want := range q.Configs {
+		if value, ok := t.effective[key]; !ok || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply filters, sorts and pages the topics
+func (q Query) Apply(topics []Topic) Page {
+	matched := make([]Topic, 0, len(topics))
+	for _, t := range topics {
+		if q.Matches(t) {
+			matched = append(matched, t)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if q.Descending {
+			a, b = b, a
+		}
+		switch q.Sort {
+		case SortSize:
+			if a.SizeBytes != b.SizeBytes {
+				return a.SizeBytes < b.SizeBytes
+			}
+		case SortPartitions:
+			if a.Partitions != b.Partitions {
+				return a.Partitions < b.Partitions
+			}
+		case SortReplicationFactor:
+			if a.ReplicationFactor != b.ReplicationFactor {
+				return a.ReplicationFactor < b.ReplicationFactor
+			}
+		}
+		return a.Name < b.Name
+	})
+
+	page := Page{Total: len(matched), Offset: q.Offset, Limit: q.Limit, Topics: []Topic{}}
+	if q.Offset < len(matched) {
+		end := q.Offset + q.Limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Topics = matched[q.Offset:end]
+	}
+	return page
+}
+
+// TopicsHandler handles GET /catalog/topics requests
+func (c *Catalog) TopicsHandler(w http.ResponseWriter, req *http.Request) {
+	q, err := ParseQuery(req.URL.Query())
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return
+	}
+
+	snapshot := c.Snapshot()
+	page := q.Apply(snapshot.Topics)
+	page.RefreshedAt = snapshot.RefreshedAt
+	page.Error = snapshot.Error
+	_, _ = web.ReturnResponse(w, page)
+}
+
+func optionalInt64(values url.Values, key string) (*int64, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 0 {
+		return nil, cplnErrors.Validationf("invalid %s: %q", key, raw)
+	}
+	return &v, nil
+}
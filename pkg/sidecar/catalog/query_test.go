@@ -0,0 +1,131 @@
+package catalog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func catalogTopics() []Topic {
+	return []Topic{
+		{Name: "audit", Partitions: 1, ReplicationFactor: 1, SizeBytes: 10, effective: map[string]string{"cleanup.policy": "compact"}},
+		{Name: "orders", Partitions: 12, ReplicationFactor: 3, SizeBytes: 5000, Tags: map[string]string{OwnerTag: "payments"}, effective: map[string]string{"cleanup.policy": "delete"}},
+		{Name: "payments", Partitions: 6, ReplicationFactor: 3, SizeBytes: 800, Tags: map[string]string{OwnerTag: "payments"}, effective: map[string]string{"cleanup.policy": "delete"}},
+		{Name: "__consumer_offsets", Internal: true, Partitions: 50, ReplicationFactor: 3, SizeBytes: 20, effective: map[string]string{"cleanup.policy": "compact"}},
+	}
+}
+
+func names(topics []Topic) []string {
+	out := make([]string, 0, len(topics))
+	for _, t := range topics {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestQueryApply(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      []string
+		expectedTotal int
+	}{
+		{name: "default", query: "", expected: []string{"__consumer_offsets", "audit", "orders", "payments"}, expectedTotal: 4},
+		{name: "name pattern", query: "name=o.*", expected: []string{"orders"}, expectedTotal: 1},
+		{name: "config value", query: "config=cleanup.policy%3Dcompact", expected: []string{"__consumer_offsets", "audit"}, expectedTotal: 2},
+		{name: "size range", query: "minSize=20&maxSize=1000", expected: []string{"__consumer_offsets", "payments"}, expectedTotal: 2},
+		{name: "owner", query: "owner=payments", expected: []string{"orders", "payments"}, expectedTotal: 2},
+		{name: "replication factor", query: "rf=1", expected: []string{"audit"}, expectedTotal: 1},
+		{name: "exclude internal", query: "internal=false&rf=3", expected: []string{"orders", "payments"}, expectedTotal: 2},
+		{name: "sort by size descending", query: "sort=size&order=desc", expected: []string{"orders", "payments", "__consumer_offsets", "audit"}, expectedTotal: 4},
+		{name: "sort by partitions", query: "sort=partitions", expected: []string{"audit", "payments", "orders", "__consumer_offsets"}, expectedTotal: 4},
+		{name: "page", query: "offset=1&limit=2", expected: []string{"audit", "orders"}, expectedTotal: 4},
+		{name: "page past the end", query: "offset=10", expected: []string{}, expectedTotal: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := ParseQuery(values)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			page := q.Apply(catalogTopics())
+			got := names(page.Topics)
+			if page.Total != tt.expectedTotal {
+				t.Errorf("expected total %d, got %d", tt.expectedTotal, page.Total)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	queries := []string{
+		"name=orders-([",
+		"config=retention.ms",
+		"minSize=-1",
+		"maxSize=large",
+		"rf=0",
+		"internal=maybe",
+		"sort=owner",
+		"order=up",
+		"offset=-1",
+		"limit=0",
+		"limit=5000",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			values, err := url.ParseQuery(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseQuery(values); err == nil {
+				t.Errorf("expected error for %q", query)
+			}
+		})
+	}
+}
+
+func TestTopicsHandler(t *testing.T) {
+	c := newTestCatalog(clusterMock(), "")
+
+	req := httptest.NewRequest(http.MethodGet, "/catalog/topics?sort=bogus", nil)
+	w := httptest.NewRecorder()
+	c.TopicsHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if err := c.Step(req.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/catalog/topics?internal=false", nil)
+	w = httptest.NewRecorder()
+	c.TopicsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var page Page
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if page.RefreshedAt == nil || page.Total != 1 || page.Topics[0].Name != "orders" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+}
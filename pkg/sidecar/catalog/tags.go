@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// OwnerTag is the tag the owner filter matches
+const OwnerTag = "owner"
+
+// Tags maps topic patterns to tags. Patterns are regular expressions matched
+// against the whole topic name.
+type Tags struct {
+	patterns []tagPattern
+}
+
+type tagPattern struct {
+	pattern string
+	literal bool
+	re      *regexp.Regexp
+	tags    map[string]string
+}
+
+// LoadTags reads and validates a tags file
+func LoadTags(path string) (Tags, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Tags{}, fmt.Errorf("failed to read tags file: %w", err)
+	}
+	return ParseTags(data)
+}
+
+// ParseTags parses a tags file of the form {"topics": {"<pattern>": {"owner": "..."}}}
+func ParseTags(data []byte) (Tags, error) {
+	var file struct {
+		Topics map[string]map[string]string `json:"topics"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Tags{}, fmt.Errorf("invalid tags file: %w", err)
+	}
+
+	var tags Tags
+	for pattern, values := range file.Topics {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return Tags{}, fmt.Errorf("invalid tags file: topic pattern %q: %w", pattern, err)
+		}
+		literal := regexp.QuoteMeta(pattern) == pattern
+		tags.patterns = append(tags.patterns, tagPattern{pattern: pattern, literal: literal, re: re, tags: values})
+	}
+	// Apply exact topic names last, so they win over patterns matching the same topic
+	sort.Slice(tags.patterns, func(i, j int) bool {
+		if tags.patterns[i].literal != tags.patterns[j].literal {
+			return !tags.patterns[i].literal
+		}
+		return tags.patterns[i].pattern < tags.patterns[j].pattern
+	})
+	return tags, nil
+}
+
+// For returns the merged tags of every pattern matching the topic
+func (t Tags) For(topic string) map[string]string {
+	var merged map[string]string
+	for _, p := range t.patterns {
+		if !p.re.MatchString(topic) {
+			continue
+		}
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for k, v := range p.tags {
+			merged[k] = v
+		}
+	}
+	return merged
+}
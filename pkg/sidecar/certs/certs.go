@@ -0,0 +1,226 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the certificate expiry loop in the freshness tracker
+const CheckName = "tls_certificates"
+
+const (
+	// SourceBroker is the certificate the local broker serves
+	SourceBroker = "broker"
+	// SourceClient is the client certificate the sidecar presents to the brokers
+	SourceClient = "client"
+)
+
+// Inspector returns the leaf certificate served at the address
+type Inspector func(ctx context.Context, address string) (*x509.Certificate, error)
+
+// Options configures the certificate expiry monitor
+type Options struct {
+	// BrokerAddress is the local broker's TLS listener. Empty skips the broker certificate.
+	BrokerAddress string
+	// ClientCertFile is the sidecar's client certificate. Empty skips it.
+	ClientCertFile string
+	// Interval is how often the certificates are inspected
+	Interval time.Duration
+	// Timeout bounds each TLS handshake
+	Timeout time.Duration
+	// MinValidity fails CertError when a certificate expires sooner. Zero disables it.
+	MinValidity time.Duration
+}
+
+// Certificate is the expiry of an inspected certificate
+type Certificate struct {
+	Source    string    `json:"source"`
+	Subject   string    `json:"subject,omitempty"`
+	NotAfter  time.Time `json:"notAfter,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Monitor periodically inspects the certificate the local broker serves and the
+// sidecar's own client certificate, so renewals that did not happen are caught
+// before clients start failing handshakes
+type Monitor struct {
+	opts    Options
+	logger  *slog.Logger
+	inspect Inspector
+	tracker *freshness.Tracker
+	clock   clock.Clock
+
+	mu           sync.RWMutex
+	certificates []Certificate
+}
+
+// NewMonitor creates a new certificate expiry monitor
+func NewMonitor(opts Options, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		opts:    opts,
+		logger:  logger,
+		clock:   clock.Real,
+		inspect: defaultInspect,
+	}
+}
+
+// SetInspector allows overriding the broker certificate inspector for testing
+func (m *Monitor) SetInspector(inspect Inspector) {
+	m.inspect = inspect
+}
+
+// SetTracker records every inspection with the freshness tracker
+func (m *Monitor) SetTracker(tracker *freshness.Tracker) {
+	m.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (m *Monitor) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
+
+// defaultInspect completes a TLS handshake and returns the served leaf certificate
+func defaultInspect(ctx context.Context, address string) (*x509.Certificate, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName: host,
+		// Only the expiry is read; verifying the chain is the clients' job, and an
+		// expired certificate must still be inspected
+		InsecureSkipVerify: true, //nolint:gosec
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	peers := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return nil, errors.New("no certificate served")
+	}
+	return peers[0], nil
+}
+
+// readCertFile returns the first certificate in a PEM file
+func readCertFile(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate in %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// Certificates returns the certificates of the last inspection
+func (m *Monitor) Certificates() []Certificate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	certificates := make([]Certificate, len(m.certificates))
+	copy(certificates, m.certificates)
+	return certificates
+}
+
+// CertError returns an error when an inspected certificate expires within
+// MinValidity. Certificates that could not be inspected do not fail it; an
+// unreachable broker fails the other checks.
+func (m *Monitor) CertError() error {
+	if m.opts.MinValidity <= 0 {
+		return nil
+	}
+	var errs []error
+	for _, cert := range m.Certificates() {
+		if cert.Error != "" {
+			continue
+		}
+		if remaining := cert.NotAfter.Sub(m.clock.Now()); remaining < m.opts.MinValidity {
+			errs = append(errs, fmt.Errorf("%s certificate %s expires at %s", cert.Source, cert.Subject, cert.NotAfter.Format(time.RFC3339)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run inspects the certificates every Interval until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	m.tracker.Register(CheckName)
+
+	for {
+		m.tracker.Record(CheckName, m.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step inspects every configured certificate and returns the failures
+func (m *Monitor) Step(ctx context.Context) error {
+	var certificates []Certificate
+	var errs []error
+	record := func(source string, cert *x509.Certificate, err error) {
+		c := Certificate{Source: source, CheckedAt: m.clock.Now()}
+		if err != nil {
+			c.Error = err.Error()
+			errs = append(errs, fmt.Errorf("failed to inspect %s certificate: %w", source, err))
+		} else {
+			c.Subject = cert.Subject.String()
+			c.NotAfter = cert.NotAfter
+		}
+		certificates = append(certificates, c)
+	}
+
+	if m.opts.BrokerAddress != "" {
+		ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+		cert, err := m.inspect(ctx, m.opts.BrokerAddress)
+		cancel()
+		record(SourceBroker, cert, err)
+	}
+	if m.opts.ClientCertFile != "" {
+		cert, err := readCertFile(m.opts.ClientCertFile)
+		record(SourceClient, cert, err)
+	}
+
+	m.mu.Lock()
+	m.certificates = certificates
+	m.mu.Unlock()
+
+	if err := m.CertError(); err != nil {
+		m.logger.Warn("tls: certificate expiring", "error", err)
+	}
+	return errors.Join(errs...)
+}
+
+// StatusHandler handles GET /admin/tls/certificates requests
+func (m *Monitor) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, m.Certificates())
+}
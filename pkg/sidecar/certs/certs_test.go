@@ -0,0 +1,164 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// newCert returns a self-signed certificate for the common name expiring after validity
+func newCert(t *testing.T, commonName string, validity time.Duration) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+// writeCert writes the certificate as PEM, after a key block as in a combined file
+func writeCert(t *testing.T, cert *x509.Certificate) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tls.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	return path
+}
+
+func TestStep(t *testing.T) {
+	broker := newCert(t, "kafka-1", 30*24*time.Hour)
+	client := newCert(t, "sidecar", 48*time.Hour)
+
+	m := NewMonitor(Options{
+		BrokerAddress:  "localhost:9093",
+		ClientCertFile: writeCert(t, client),
+		Timeout:        time.Second,
+		MinValidity:    72 * time.Hour,
+	}, testLogger())
+	m.SetInspector(func(_ context.Context, address string) (*x509.Certificate, error) {
+		if address != "localhost:9093" {
+			t.Errorf("expected to inspect localhost:9093, got %s", address)
+		}
+		return broker, nil
+	})
+
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	certificates := m.Certificates()
+	if len(certificates) != 2 {
+		t.Fatalf("expected 2 certificates, got %+v", certificates)
+	}
+	if certificates[0].Source != SourceBroker || certificates[0].Subject != "CN=kafka-1" || !certificates[0].NotAfter.Equal(broker.NotAfter) {
+		t.Errorf("unexpected broker certificate: %+v", certificates[0])
+	}
+	if certificates[1].Source != SourceClient || certificates[1].Subject != "CN=sidecar" {
+		t.Errorf("unexpected client certificate: %+v", certificates[1])
+	}
+
+	// Only the client certificate expires within MinValidity
+	err := m.CertError()
+	if err == nil || !strings.Contains(err.Error(), "client certificate CN=sidecar") || strings.Contains(err.Error(), "broker") {
+		t.Errorf("expected the client certificate to fail, got %v", err)
+	}
+}
+
+func TestStepInspectionFailure(t *testing.T) {
+	m := NewMonitor(Options{BrokerAddress: "localhost:9093", Timeout: time.Second, MinValidity: time.Hour}, testLogger())
+	m.SetInspector(func(context.Context, string) (*x509.Certificate, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	if err := m.Step(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+	if certificates := m.Certificates(); len(certificates) != 1 || certificates[0].Error == "" {
+		t.Errorf("expected the failure to be recorded, got %+v", certificates)
+	}
+	if err := m.CertError(); err != nil {
+		t.Errorf("expected a failed inspection not to fail readiness, got %v", err)
+	}
+}
+
+func TestCertErrorAsTimePasses(t *testing.T) {
+	broker := newCert(t, "kafka-1", 30*24*time.Hour)
+	clk := clock.NewFake(time.Now())
+	m := NewMonitor(Options{BrokerAddress: "localhost:9093", Timeout: time.Second, MinValidity: 72 * time.Hour}, testLogger())
+	m.SetClock(clk)
+	m.SetInspector(func(context.Context, string) (*x509.Certificate, error) {
+		return broker, nil
+	})
+
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.CertError(); err != nil {
+		t.Fatalf("expected no error 30 days before expiry, got %v", err)
+	}
+
+	// Without another inspection, the certificate falls within MinValidity
+	clk.Advance(28 * 24 * time.Hour)
+	if err := m.CertError(); err == nil || !strings.Contains(err.Error(), "broker certificate CN=kafka-1") {
+		t.Errorf("expected the broker certificate to fail 2 days before expiry, got %v", err)
+	}
+}
+
+func TestCertErrorDisabled(t *testing.T) {
+	m := NewMonitor(Options{ClientCertFile: writeCert(t, newCert(t, "sidecar", -time.Hour))}, testLogger())
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.CertError(); err != nil {
+		t.Errorf("expected no error without MinValidity, got %v", err)
+	}
+}
+
+func TestDefaultInspect(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	cert, err := defaultInspect(context.Background(), strings.TrimPrefix(server.URL, "https://"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cert.NotAfter.Equal(server.Certificate().NotAfter) {
+		t.Errorf("expected expiry %s, got %s", server.Certificate().NotAfter, cert.NotAfter)
+	}
+}
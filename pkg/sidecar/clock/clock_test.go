@@ -0,0 +1,75 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeNowAndSince(t *testing.T) {
+	f := NewFake(start)
+	f.Advance(90 * time.Second)
+
+	if !f.Now().Equal(start.Add(90 * time.Second)) {
+		t.Errorf("unexpected now: %v", f.Now())
+	}
+	if since := f.Since(start); since != 90*time.Second {
+		t.Errorf("expected 90s since start, got %v", since)
+	}
+}
+
+// ticked returns the pending tick, if any
+func ticked(ticker Ticker) (time.Time, bool) {
+	select {
+	case at := <-ticker.C():
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(time.Minute)
+
+	f.Advance(59 * time.Second)
+	if _, ok := ticked(ticker); ok {
+		t.Fatal("expected no tick before the interval")
+	}
+
+	f.Advance(time.Second)
+	if at, ok := ticked(ticker); !ok || !at.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected a tick at 1m, got %v %v", at, ok)
+	}
+
+	// Missed ticks are dropped rather than queued
+	f.Advance(5 * time.Minute)
+	if at, ok := ticked(ticker); !ok || !at.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("expected a single tick at 2m, got %v %v", at, ok)
+	}
+	if _, ok := ticked(ticker); ok {
+		t.Fatal("expected missed ticks to be dropped")
+	}
+	f.Advance(time.Minute)
+	if at, ok := ticked(ticker); !ok || !at.Equal(start.Add(7*time.Minute)) {
+		t.Fatalf("expected the next tick at 7m, got %v %v", at, ok)
+	}
+}
+
+func TestFakeTickerStop(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(time.Second)
+	if f.Tickers() != 1 {
+		t.Fatalf("expected 1 ticker, got %d", f.Tickers())
+	}
+
+	ticker.Stop()
+	f.Advance(time.Minute)
+	if _, ok := ticked(ticker); ok {
+		t.Error("expected a stopped ticker not to fire")
+	}
+	if f.Tickers() != 0 {
+		t.Errorf("expected no tickers, got %d", f.Tickers())
+	}
+}
@@ -0,0 +1,349 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// Path is where every sidecar serves its connectivity, and where peers are
+// asked for their row of the matrix
+const Path = "/diagnostics/connectivity"
+
+// Client defines the Kafka operations needed to find and probe the brokers.
+// This enables mocking in tests.
+type Client interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	// Request sends the request to one broker, over its own connection
+	Request(ctx context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error)
+}
+
+// ClientFactory creates Kafka clients. Allows injection for testing.
+type ClientFactory func() (Client, func(), error)
+
+// Dialer opens and closes a TCP connection to the address
+type Dialer func(ctx context.Context, address string) error
+
+// Fetcher reads a peer sidecar's row of the matrix
+type Fetcher func(ctx context.Context, url string) (Row, error)
+
+// Options configures the connectivity probes
+type Options struct {
+	// SidecarPort is the HTTP port of every peer sidecar
+	SidecarPort int
+	// Timeout bounds the probes of one sidecar
+	Timeout time.Duration
+	// TLSConfig, when set, asks peer sidecars over HTTPS with it
+	TLSConfig *tls.Config
+}
+
+// Attempt is the outcome of one connection attempt
+type Attempt struct {
+	OK        bool    `json:"ok"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Result is how one sidecar reached one broker
+type Result struct {
+	Address string `json:"address"`
+	// TCP is a connect to the broker's address
+	TCP Attempt `json:"tcp"`
+	// Kafka is an ApiVersions round trip with the configured TLS and SASL,
+	// when requested
+	Kafka *Attempt `json:"kafka,omitempty"`
+}
+
+// Row is one sidecar's results, by broker ID
+type Row struct {
+	BrokerID  int32            `json:"brokerId"`
+	CheckedAt time.Time        `json:"checkedAt"`
+	Brokers   map[int32]Result `json:"brokers"`
+}
+
+// Matrix holds the row of every sidecar that answered
+type Matrix struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	// Rows holds each sidecar's row, by the broker ID it runs next to
+	Rows map[int32]Row `json:"rows"`
+	// Errors holds why a peer sidecar's row is missing, by broker ID
+	Errors map[int32]string `json:"errors,omitempty"`
+	// Unreachable lists the failed attempts as observer->target, e.g. "0->2"
+	Unreachable []string `json:"unreachable,omitempty"`
+}
+
+// Prober probes every broker on demand, from this sidecar and from the peer
+// sidecars, so a single request tells network problems apart from broker ones
+type Prober struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	dial          Dialer
+	fetch         Fetcher
+	clock         clock.Clock
+}
+
+// NewProber creates a new connectivity prober for the local broker
+func NewProber(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Prober {
+	p := &Prober{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+	}
+	// Set default client factory, dialer and fetcher
+	p.clientFactory = p.defaultClientFactory
+	p.dial = defaultDial
+	p.fetch = newFetcher(&http.Client{Transport: &http.Transport{TLSClientConfig: opts.TLSConfig}})
+	return p
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (p *Prober) SetClientFactory(factory ClientFactory) {
+	p.clientFactory = factory
+}
+
+// SetDialer allows overriding the dialer for testing
+func (p *Prober) SetDialer(dial Dialer) {
+	p.dial = dial
+}
+
+// SetFetcher allows overriding the fetcher for testing
+func (p *Prober) SetFetcher(fetch Fetcher) {
+	p.fetch = fetch
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (p *Prober) SetClock(clk clock.Clock) {
+	p.clock = clk
+}
+
+// kgoClient addresses single brokers of a franz-go client
+type kgoClient struct {
+	*kadm.Client
+	cl *kgo.Client
+}
+
+func (c kgoClient) Request(ctx context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+	return c.cl.Broker(int(brokerID)).Request(ctx, req)
+}
+
+// defaultClientFactory creates a franz-go client
+func (p *Prober) defaultClientFactory() (Client, func(), error) {
+	cl, cleanup, err := kafkaclient.NewClient(p.kafkaConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kgoClient{Client: kadm.NewClient(cl), cl: cl}, cleanup, nil
+}
+
+func defaultDial(ctx context.Context, address string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// newFetcher returns a Fetcher that reads rows with the client
+func newFetcher(client *http.Client) Fetcher {
+	return func(ctx context.Context, url string) (Row, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return Row{}, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return Row{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Row{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		var row Row
+		if err := json.NewDecoder(resp.Body).Decode(&row); err != nil {
+			return Row{}, fmt.Errorf("failed to decode row: %w", err)
+		}
+		return row, nil
+	}
+}
+
+// Local probes every broker in the cluster metadata from this sidecar: a TCP
+// connect to its address and, with kafka, an ApiVersions round trip
+func (p *Prober) Local(ctx context.Context, kafka bool) (Row, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	client, cleanup, err := p.clientFactory()
+	if err != nil {
+		return Row{}, err
+	}
+	defer cleanup()
+
+	md, err := client.Metadata(ctx)
+	if err != nil {
+		return Row{}, fmt.Errorf("failed to fetch broker addresses: %w", err)
+	}
+	if len(md.Brokers) == 0 {
+		return Row{}, errors.New("no brokers in metadata")
+	}
+
+	row := Row{BrokerID: p.brokerID, Brokers: map[int32]Result{}}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, b := range md.Brokers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := Result{Address: net.JoinHostPort(b.Host, strconv.Itoa(int(b.Port)))}
+			result.TCP = attempt(func() error { return p.dial(ctx, result.Address) })
+			if kafka {
+				round := attempt(func() error {
+					return check(client.Request(ctx, b.NodeID, kmsg.NewPtrApiVersionsRequest()))
+				})
+				result.Kafka = &round
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			row.Brokers[b.NodeID] = result
+		}()
+	}
+	wg.Wait()
+	row.CheckedAt = p.clock.Now()
+	return row, nil
+}
+
+// attempt times fn
+func attempt(fn func() error) Attempt {
+	start := time.Now()
+	err := fn()
+	a := Attempt{OK: err == nil, LatencyMs: float64(time.Since(start)) / float64(time.Millisecond)}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	return a
+}
+
+// check turns a top-level error code in the response into an error
+func check(resp kmsg.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if r, ok := resp.(*kmsg.ApiVersionsResponse); ok {
+		return kerr.ErrorForCode(r.ErrorCode)
+	}
+	return nil
+}
+
+// Matrix probes every broker from this sidecar and asks the sidecar next to
+// every other broker for its row. Peers probe with their own Timeout, so
+// they are given twice as long to answer.
+func (p *Prober) Matrix(ctx context.Context, kafka bool) (Matrix, error) {
+	local, err := p.Local(ctx, kafka)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*p.opts.Timeout)
+	defer cancel()
+
+	matrix := Matrix{Rows: map[int32]Row{p.brokerID: local}, Errors: map[int32]string{}}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for id, result := range local.Brokers {
+		if id == p.brokerID {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			row, err := p.peerRow(ctx, id, result.Address, kafka)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				matrix.Errors[id] = err.Error()
+				return
+			}
+			matrix.Rows[id] = row
+		}()
+	}
+	wg.Wait()
+
+	matrix.Unreachable = unreachable(matrix.Rows)
+	matrix.CheckedAt = p.clock.Now()
+	if len(matrix.Errors) == 0 {
+		matrix.Errors = nil
+	}
+	if len(matrix.Unreachable) > 0 || len(matrix.Errors) > 0 {
+		p.logger.Warn("connectivity: brokers unreachable", "unreachable", matrix.Unreachable, "errors", matrix.Errors)
+	}
+	return matrix, nil
+}
+
+// peerRow asks the sidecar running next to the broker at address for its row
+func (p *Prober) peerRow(ctx context.Context, id int32, address string, kafka bool) (Row, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return Row{}, err
+	}
+	scheme := "http"
+	if p.opts.TLSConfig != nil {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s?scope=local&kafka=%t", scheme, net.JoinHostPort(host, strconv.Itoa(p.opts.SidecarPort)), Path, kafka)
+	row, err := p.fetch(ctx, url)
+	if err != nil {
+		return Row{}, err
+	}
+	if row.BrokerID != id {
+		return Row{}, fmt.Errorf("sidecar reports broker %d", row.BrokerID)
+	}
+	return row, nil
+}
+
+// unreachable lists the failed attempts of every row, sorted
+func unreachable(rows map[int32]Row) []string {
+	type pair struct{ observer, target int32 }
+	var pairs []pair
+	for observer, row := range rows {
+		for target, result := range row.Brokers {
+			if !result.TCP.OK || (result.Kafka != nil && !result.Kafka.OK) {
+				pairs = append(pairs, pair{observer, target})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].observer != pairs[j].observer {
+			return pairs[i].observer < pairs[j].observer
+		}
+		return pairs[i].target < pairs[j].target
+	})
+	var out []string
+	for _, pr := range pairs {
+		out = append(out, fmt.Sprintf("%d->%d", pr.observer, pr.target))
+	}
+	return out
+}
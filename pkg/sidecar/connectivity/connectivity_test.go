@@ -0,0 +1,248 @@
+package connectivity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockClient is a mock implementation of Client for testing
+type MockClient struct {
+	MetadataFunc func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	RequestFunc  func(ctx context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error)
+}
+
+func (m *MockClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockClient) Request(ctx context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+	if m.RequestFunc != nil {
+		return m.RequestFunc(ctx, brokerID, req)
+	}
+	return kmsg.NewPtrApiVersionsResponse(), nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// threeBrokers returns metadata of brokers 1-3 on kafka-<id>:9092
+func threeBrokers(context.Context, ...string) (kadm.Metadata, error) {
+	return kadm.Metadata{Brokers: kadm.BrokerDetails{
+		{NodeID: 1, Host: "kafka-1", Port: 9092},
+		{NodeID: 2, Host: "kafka-2", Port: 9092},
+		{NodeID: 3, Host: "kafka-3", Port: 9092},
+	}}, nil
+}
+
+// newTestProber returns a prober for broker 1 that cannot connect to the
+// addresses in unreachable, and reads the rows of peers from rows by host
+func newTestProber(client Client, unreachable map[string]bool, rows map[string]Row) *Prober {
+	p := NewProber(1, kafkaclient.Config{}, Options{SidecarPort: 8080, Timeout: 10 * time.Second}, testLogger())
+	p.SetClientFactory(func() (Client, func(), error) {
+		return client, func() {}, nil
+	})
+	p.SetDialer(func(_ context.Context, address string) error {
+		if unreachable[address] {
+			return errors.New("i/o timeout")
+		}
+		return nil
+	})
+	p.SetFetcher(func(_ context.Context, url string) (Row, error) {
+		if !strings.HasSuffix(url, Path+"?scope=local&kafka=false") && !strings.HasSuffix(url, Path+"?scope=local&kafka=true") {
+			return Row{}, errors.New("unexpected url " + url)
+		}
+		host := strings.TrimPrefix(url, "http://")
+		host = host[:strings.Index(host, ":")]
+		row, ok := rows[host]
+		if !ok {
+			return Row{}, errors.New("connection refused")
+		}
+		return row, nil
+	})
+	return p
+}
+
+// reachable returns a row of observer reaching every broker but the missed ones
+func reachable(observer int32, missed ...int32) Row {
+	row := Row{BrokerID: observer, Brokers: map[int32]Result{}}
+	for _, id := range []int32{1, 2, 3} {
+		row.Brokers[id] = Result{TCP: Attempt{OK: true}}
+	}
+	for _, id := range missed {
+		row.Brokers[id] = Result{TCP: Attempt{Error: "i/o timeout"}}
+	}
+	return row
+}
+
+func TestLocal(t *testing.T) {
+	client := &MockClient{
+		MetadataFunc: threeBrokers,
+		RequestFunc: func(_ context.Context, brokerID int32, _ kmsg.Request) (kmsg.Response, error) {
+			if brokerID == 3 {
+				return nil, errors.New("SASL authentication failed")
+			}
+			return kmsg.NewPtrApiVersionsResponse(), nil
+		},
+	}
+	p := newTestProber(client, map[string]bool{"kafka-2:9092": true}, nil)
+
+	row, err := p.Local(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Local failed: %v", err)
+	}
+	if row.BrokerID != 1 || len(row.Brokers) != 3 {
+		t.Fatalf("expected a row of broker 1 with 3 brokers, got %+v", row)
+	}
+	if got := row.Brokers[2]; got.Address != "kafka-2:9092" || got.TCP.OK || got.TCP.Error == "" {
+		t.Errorf("expected broker 2 unreachable, got %+v", got)
+	}
+	if got := row.Brokers[3]; !got.TCP.OK || got.Kafka != nil {
+		t.Errorf("expected broker 3 reachable without a Kafka probe, got %+v", got)
+	}
+
+	row, err = p.Local(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Local failed: %v", err)
+	}
+	if got := row.Brokers[1].Kafka; got == nil || !got.OK {
+		t.Errorf("expected a Kafka round trip to broker 1, got %+v", got)
+	}
+	if got := row.Brokers[3].Kafka; got == nil || got.OK || got.Error != "SASL authentication failed" {
+		t.Errorf("expected the Kafka round trip to broker 3 to fail, got %+v", got)
+	}
+
+	// The addresses come from metadata
+	client.MetadataFunc = func(context.Context, ...string) (kadm.Metadata, error) {
+		return kadm.Metadata{}, errors.New("not controller")
+	}
+	if _, err := p.Local(context.Background(), false); err == nil {
+		t.Error("expected an error without metadata")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		name              string
+		unreachable       map[string]bool
+		rows              map[string]Row
+		expectRows        []int32
+		expectErrors      []int32
+		expectUnreachable []string
+	}{
+		{
+			name:       "fully connected",
+			rows:       map[string]Row{"kafka-2": reachable(2), "kafka-3": reachable(3)},
+			expectRows: []int32{1, 2, 3},
+		},
+		{
+			name:              "asymmetric",
+			rows:              map[string]Row{"kafka-2": reachable(2, 3), "kafka-3": reachable(3, 2)},
+			expectRows:        []int32{1, 2, 3},
+			expectUnreachable: []string{"2->3", "3->2"},
+		},
+		{
+			name:              "sidecar down",
+			unreachable:       map[string]bool{"kafka-3:9092": true},
+			rows:              map[string]Row{"kafka-2": reachable(2, 3)},
+			expectRows:        []int32{1, 2},
+			expectErrors:      []int32{3},
+			expectUnreachable: []string{"1->3", "2->3"},
+		},
+		{
+			name:         "row of another broker",
+			rows:         map[string]Row{"kafka-2": reachable(3), "kafka-3": reachable(3)},
+			expectRows:   []int32{1, 3},
+			expectErrors: []int32{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProber(&MockClient{MetadataFunc: threeBrokers}, tt.unreachable, tt.rows)
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			p.SetClock(clock.NewFake(now))
+			matrix, err := p.Matrix(context.Background(), false)
+			if err != nil {
+				t.Fatalf("Matrix failed: %v", err)
+			}
+			if !matrix.CheckedAt.Equal(now) || !matrix.Rows[1].CheckedAt.Equal(now) {
+				t.Errorf("expected the matrix and the local row checked at %v, got %v and %v", now, matrix.CheckedAt, matrix.Rows[1].CheckedAt)
+			}
+			var rows, errs []int32
+			for _, id := range []int32{1, 2, 3} {
+				if _, ok := matrix.Rows[id]; ok {
+					rows = append(rows, id)
+				}
+				if _, ok := matrix.Errors[id]; ok {
+					errs = append(errs, id)
+				}
+			}
+			if !reflect.DeepEqual(rows, tt.expectRows) {
+				t.Errorf("expected rows %v, got %v", tt.expectRows, rows)
+			}
+			if !reflect.DeepEqual(errs, tt.expectErrors) {
+				t.Errorf("expected errors for %v, got %v", tt.expectErrors, errs)
+			}
+			if !reflect.DeepEqual(matrix.Unreachable, tt.expectUnreachable) {
+				t.Errorf("expected unreachable %v, got %v", tt.expectUnreachable, matrix.Unreachable)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	p := newTestProber(&MockClient{MetadataFunc: threeBrokers}, nil, map[string]Row{"kafka-2": reachable(2), "kafka-3": reachable(3)})
+
+	rec := httptest.NewRecorder()
+	p.Handler(rec, httptest.NewRequest(http.MethodGet, Path+"?scope=local", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var row Row
+	if err := json.NewDecoder(rec.Body).Decode(&row); err != nil {
+		t.Fatal(err)
+	}
+	if row.BrokerID != 1 || len(row.Brokers) != 3 {
+		t.Errorf("expected the local row, got %+v", row)
+	}
+
+	rec = httptest.NewRecorder()
+	p.Handler(rec, httptest.NewRequest(http.MethodGet, Path+"?kafka=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var matrix Matrix
+	if err := json.NewDecoder(rec.Body).Decode(&matrix); err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix.Rows) != 3 || matrix.Rows[1].Brokers[2].Kafka == nil {
+		t.Errorf("expected the matrix with Kafka round trips, got %+v", matrix)
+	}
+
+	for _, query := range []string{"?kafka=maybe", "?scope=cluster"} {
+		rec = httptest.NewRecorder()
+		p.Handler(rec, httptest.NewRequest(http.MethodGet, Path+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package connectivity
+
+import (
+	"net/http"
+	"strconv"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+)
+
+// Handler handles GET /diagnostics/connectivity requests. It returns the
+// matrix of every sidecar's probes, or with ?scope=local only the row of this
+// sidecar, as peers are asked for. ?kafka=true adds an ApiVersions round trip
+// to every TCP connect.
+func (p *Prober) Handler(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	kafka := false
+	if raw := query.Get("kafka"); raw != "" {
+		var err error
+		if kafka, err = strconv.ParseBool(raw); err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid kafka: %q", raw))
+			return
+		}
+	}
+
+	switch scope := query.Get("scope"); scope {
+	case "local":
+		row, err := p.Local(req.Context(), kafka)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Internal("failed to probe brokers", err))
+			return
+		}
+		_, _ = web.ReturnResponse(w, row)
+	case "":
+		matrix, err := p.Matrix(req.Context(), kafka)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Internal("failed to probe brokers", err))
+			return
+		}
+		_, _ = web.ReturnResponse(w, matrix)
+	default:
+		_, _ = web.ReturnError(w, cplnErrors.Validationf("unsupported scope: %q (supported: local)", scope))
+	}
+}
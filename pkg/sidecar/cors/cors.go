@@ -0,0 +1,142 @@
+// Package cors lets browser-based tooling, like an internal dashboard, call the
+// sidecar's API from another origin.
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requestlog"
+)
+
+// AnyOrigin allows requests from every origin
+const AnyOrigin = "*"
+
+// DefaultMethods are the methods allowed when none are configured
+var DefaultMethods = []string{http.MethodGet, http.MethodPost}
+
+// allowedHeaders are the request headers a browser may send: bearer tokens,
+// JSON bodies and request IDs
+var allowedHeaders = []string{"Authorization", "Content-Type", requestlog.Header}
+
+// exposedHeaders are the response headers a browser lets scripts read
+var exposedHeaders = []string{requestlog.Header, "WWW-Authenticate"}
+
+// maxAge is how long a browser may cache a preflight response
+const maxAge = 10 * time.Minute
+
+// ParseOrigins parses a comma-separated list of origins, e.g.
+// "https://dashboard.example.com,http://localhost:3000", or "*" for any origin
+func ParseOrigins(s string) ([]string, error) {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin != AnyOrigin {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+				u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+				return nil, fmt.Errorf("invalid origin %q (want scheme://host[:port])", origin)
+			}
+			// Browsers send origins in lower case
+			origin = strings.ToLower(origin)
+		}
+		origins = append(origins, origin)
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("no origins in %q", s)
+	}
+	return origins, nil
+}
+
+// ParseMethods parses a comma-separated list of HTTP methods. Empty returns
+// DefaultMethods.
+func ParseMethods(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultMethods, nil
+	}
+	var methods []string
+	for _, method := range strings.Split(s, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			methods = append(methods, method)
+		case "":
+		default:
+			return nil, fmt.Errorf("unsupported method %q", method)
+		}
+	}
+	return methods, nil
+}
+
+// Options configures the middleware
+type Options struct {
+	// AllowedOrigins are the origins allowed to call the API, or AnyOrigin
+	AllowedOrigins []string
+	// AllowedMethods are the methods a preflight may ask for
+	AllowedMethods []string
+}
+
+// Middleware answers CORS preflight requests and adds the CORS headers to the
+// responses of allowed origins. Requests from other origins are served without
+// them, so the browser keeps their responses from the calling script.
+//
+// Preflights match no route, since they use OPTIONS, so the middleware wraps
+// the router rather than being one of its middlewares. They carry no
+// credentials either; the request that follows is authenticated as usual.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, AnyOrigin)
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(allowedHeaders, ", ")
+	exposed := strings.Join(exposedHeaders, ", ")
+	age := strconv.Itoa(int(maxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !anyOrigin {
+				// The response depends on the origin, so caches must not share it
+				w.Header().Add("Vary", "Origin")
+			}
+			allowed := anyOrigin || slices.Contains(opts.AllowedOrigins, strings.ToLower(origin))
+			requestedMethod := r.Header.Get("Access-Control-Request-Method")
+
+			if r.Method == http.MethodOptions && requestedMethod != "" {
+				if !allowed || !slices.Contains(opts.AllowedMethods, requestedMethod) {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				setOrigin(w, origin, anyOrigin)
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", age)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			if allowed {
+				setOrigin(w, origin, anyOrigin)
+				w.Header().Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setOrigin allows the origin of the request
+func setOrigin(w http.ResponseWriter, origin string, anyOrigin bool) {
+	if anyOrigin {
+		origin = AnyOrigin
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
@@ -0,0 +1,92 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestParseOrigins(t *testing.T) {
+	origins, err := ParseOrigins(" https://Dashboard.example.com , http://localhost:3000,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"https://dashboard.example.com", "http://localhost:3000"}
+	if !slices.Equal(origins, want) {
+		t.Errorf("expected %v, got %v", want, origins)
+	}
+	if origins, err := ParseOrigins("*"); err != nil || !slices.Equal(origins, []string{AnyOrigin}) {
+		t.Errorf("expected any origin, got %v, %v", origins, err)
+	}
+
+	for _, invalid := range []string{"", "dashboard.example.com", "https://example.com/", "ftp://example.com", "https://user@example.com"} {
+		if _, err := ParseOrigins(invalid); err == nil {
+			t.Errorf("ParseOrigins(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestParseMethods(t *testing.T) {
+	methods, err := ParseMethods("")
+	if err != nil || !slices.Equal(methods, DefaultMethods) {
+		t.Errorf("expected the default methods, got %v, %v", methods, err)
+	}
+	methods, err = ParseMethods("get, delete")
+	if err != nil || !slices.Equal(methods, []string{"GET", "DELETE"}) {
+		t.Errorf("expected GET and DELETE, got %v, %v", methods, err)
+	}
+	if _, err := ParseMethods("GET,TRACE"); err == nil {
+		t.Error("expected an error for TRACE")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	dashboard := "https://dashboard.example.com"
+
+	tests := []struct {
+		name        string
+		origins     []string
+		method      string
+		origin      string
+		preflight   string
+		status      int
+		allowOrigin string
+	}{
+		{name: "same origin", origins: []string{dashboard}, method: http.MethodGet, status: http.StatusTeapot},
+		{name: "allowed origin", origins: []string{dashboard}, method: http.MethodGet, origin: dashboard, status: http.StatusTeapot, allowOrigin: dashboard},
+		{name: "other origin", origins: []string{dashboard}, method: http.MethodGet, origin: "https://evil.example.com", status: http.StatusTeapot},
+		{name: "any origin", origins: []string{AnyOrigin}, method: http.MethodGet, origin: "https://evil.example.com", status: http.StatusTeapot, allowOrigin: AnyOrigin},
+		{name: "preflight", origins: []string{dashboard}, method: http.MethodOptions, origin: dashboard, preflight: http.MethodPost, status: http.StatusNoContent, allowOrigin: dashboard},
+		{name: "preflight for other origin", origins: []string{dashboard}, method: http.MethodOptions, origin: "https://evil.example.com", preflight: http.MethodPost, status: http.StatusForbidden},
+		{name: "preflight for other method", origins: []string{dashboard}, method: http.MethodOptions, origin: dashboard, preflight: http.MethodDelete, status: http.StatusForbidden},
+		{name: "plain OPTIONS", origins: []string{dashboard}, method: http.MethodOptions, origin: dashboard, status: http.StatusTeapot, allowOrigin: dashboard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Middleware(Options{AllowedOrigins: tt.origins, AllowedMethods: DefaultMethods})(next)
+			req := httptest.NewRequest(tt.method, "/admin/config", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.preflight)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.allowOrigin, got)
+			}
+			if tt.status == http.StatusNoContent && rec.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("expected Access-Control-Allow-Headers on a preflight")
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package dashboard serves a small single-page UI for on-call triage: broker
+// health, check history, memory, partition and leader counts, and running
+// reassignments. The page is static and embedded in the binary; it polls the
+// sidecar's existing JSON and metrics endpoints from the browser.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// Path is where the dashboard is served
+const Path = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy keeps the page to its own scripts and the sidecar's API
+const contentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// Handler serves the dashboard under Path
+func Handler() http.Handler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded directory is fixed at build time
+		panic(err)
+	}
+	files := http.StripPrefix(strings.TrimSuffix(Path, "/"), http.FileServerFS(root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// The page changes with the binary, not while it runs
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{name: "index", method: http.MethodGet, path: "/ui/", status: http.StatusOK, contentType: "text/html", contains: "app.js"},
+		{name: "script", method: http.MethodGet, path: "/ui/app.js", status: http.StatusOK, contentType: "javascript", contains: "/v1"},
+		{name: "stylesheet", method: http.MethodGet, path: "/ui/style.css", status: http.StatusOK, contentType: "text/css"},
+		{name: "missing file", method: http.MethodGet, path: "/ui/missing.js", status: http.StatusNotFound},
+		{name: "post", method: http.MethodPost, path: "/ui/", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.Contains(got, tt.contentType) {
+				t.Errorf("expected content type %q, got %q", tt.contentType, got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("expected the body to contain %q", tt.contains)
+			}
+			if tt.status == http.StatusOK && rec.Header().Get("Content-Security-Policy") == "" {
+				t.Error("expected a Content-Security-Policy header")
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+"use strict";
+
+// Polls the sidecar's API and renders the dashboard. Everything shown comes
+// from the same endpoints operators and probes use, under /v1.
+
+const API = "/v1";
+const REFRESH_MS = 10000;
+const HISTORY_LENGTH = 90;
+const TOKEN_KEY = "kafka-sidecar-token";
+
+// Workflows that move partitions, and the endpoints reporting their progress
+const REASSIGNMENTS = [
+  { name: "Onboarding", path: "/admin/onboarding" },
+  { name: "Decommission", path: "/admin/decommission" },
+  { name: "Replication factor", path: "/topics/replication-factor" },
+];
+
+const history = [];
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+// get fetches an endpoint, returning its status and parsed body. Probes and
+// workflows answer 503 with a JSON body, so only the status decides.
+async function get(path, { text = false } = {}) {
+  const headers = {};
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) {
+    headers.Authorization = "Bearer " + token;
+  }
+  try {
+    const resp = await fetch(API + path, { headers, cache: "no-store" });
+    let body = null;
+    try {
+      body = text ? await resp.text() : await resp.json();
+    } catch (e) {
+      // Not found and method errors have no JSON body
+    }
+    return { status: resp.status, body };
+  } catch (e) {
+    return { status: 0, body: null, error: e.message };
+  }
+}
+
+function setValue(id, text, cls) {
+  const el = $(id);
+  el.textContent = text;
+  el.className = "value" + (cls ? " " + cls : "");
+}
+
+function statusClass(status) {
+  switch (status) {
+    case "healthy":
+      return "ok";
+    case "degraded":
+    case "forming":
+      return "warn";
+    default:
+      return "bad";
+  }
+}
+
+// unavailable explains a response that carries nothing to show
+function unavailable(res) {
+  switch (res.status) {
+    case 0:
+      return "unreachable" + (res.error ? ": " + res.error : "");
+    case 401:
+      return "needs a bearer token";
+    case 403:
+      return "forbidden (client certificate required)";
+    case 404:
+      return "not enabled on this port";
+    default:
+      return "HTTP " + res.status;
+  }
+}
+
+function formatTime(value) {
+  if (!value) {
+    return "-";
+  }
+  const date = new Date(value);
+  const seconds = Math.round((Date.now() - date.getTime()) / 1000);
+  let ago;
+  if (seconds < 90) {
+    ago = seconds + "s ago";
+  } else if (seconds < 5400) {
+    ago = Math.round(seconds / 60) + "m ago";
+  } else {
+    ago = Math.round(seconds / 3600) + "h ago";
+  }
+  return date.toLocaleTimeString() + " (" + ago + ")";
+}
+
+function formatBytes(value) {
+  if (value === undefined) {
+    return "-";
+  }
+  const units = ["B", "KiB", "MiB", "GiB", "TiB"];
+  let i = 0;
+  while (value >= 1024 && i < units.length - 1) {
+    value /= 1024;
+    i++;
+  }
+  return value.toFixed(i === 0 ? 0 : 1) + " " + units[i];
+}
+
+// parseMetrics reads the unlabelled samples of a Prometheus text exposition
+function parseMetrics(text) {
+  const samples = {};
+  for (const line of text.split("\n")) {
+    if (line === "" || line.startsWith("#")) {
+      continue;
+    }
+    const match = /^([a-zA-Z_:][a-zA-Z0-9_:]*)\s+(\S+)/.exec(line);
+    if (match) {
+      samples[match[1]] = Number(match[2]);
+    }
+  }
+  return samples;
+}
+
+function cell(row, text, title) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (title) {
+    td.title = title;
+  }
+  row.appendChild(td);
+}
+
+async function refreshHealth() {
+  const [live, ready] = await Promise.all([get("/health/live"), get("/health/ready")]);
+
+  if (live.body && live.body.status) {
+    setValue("live", live.body.status, statusClass(live.body.status));
+    $("broker").textContent = "broker " + live.body.brokerId;
+  } else {
+    setValue("live", unavailable(live), "bad");
+  }
+
+  let entry = "bad";
+  if (ready.body && ready.body.status) {
+    const r = ready.body;
+    entry = statusClass(r.status);
+    setValue("ready", r.status, entry);
+    setValue("controller", r.controllerElected ? "elected" : "none", r.controllerElected ? "ok" : "bad");
+    setValue("urp", String(r.underReplicatedPartitions), r.underReplicatedPartitions > 0 ? "bad" : "ok");
+    const details = [].concat(r.error ? [r.error] : [], r.warnings || []);
+    if (r.excludedUnderReplicatedPartitions) {
+      details.push(r.excludedUnderReplicatedPartitions + " excluded under-replicated partitions");
+    }
+    $("ready-detail").textContent = details.join("; ");
+  } else {
+    setValue("ready", unavailable(ready), "bad");
+    $("ready-detail").textContent = "";
+  }
+
+  history.push({ cls: entry, label: new Date().toLocaleTimeString() + " " + (ready.body ? ready.body.status : unavailable(ready)) });
+  if (history.length > HISTORY_LENGTH) {
+    history.shift();
+  }
+  const bars = $("history");
+  bars.replaceChildren(...history.map((h) => {
+    const bar = document.createElement("span");
+    bar.className = h.cls;
+    bar.title = h.label;
+    return bar;
+  }));
+}
+
+async function refreshChecks() {
+  const res = await get("/status");
+  const rows = $("checks");
+  if (!res.body || !res.body.checks) {
+    $("checks-status").textContent = unavailable(res);
+    rows.replaceChildren();
+    return;
+  }
+  const stale = res.body.stale || [];
+  $("checks-status").textContent = stale.length
+    ? "Stale: " + stale.join(", ")
+    : "No stale checks (stale after " + res.body.staleAfter + ")";
+  rows.replaceChildren(...res.body.checks.map((c) => {
+    const row = document.createElement("tr");
+    if (c.stale) {
+      row.className = "stale";
+    }
+    cell(row, c.name);
+    cell(row, formatTime(c.lastAttempt), c.lastAttempt);
+    cell(row, formatTime(c.lastSuccess), c.lastSuccess);
+    cell(row, c.lastError || "");
+    return row;
+  }));
+}
+
+async function refreshMetrics() {
+  const res = await get("/metrics", { text: true });
+  if (res.status !== 200 || !res.body) {
+    $("metrics-status").textContent = "Metrics " + unavailable(res);
+    return;
+  }
+  $("metrics-status").textContent = "";
+  const m = parseMetrics(res.body);
+  const count = (v) => (v === undefined ? "-" : String(v));
+  setValue("partitions", count(m.kafka_broker_partition_count));
+  setValue("leaders", count(m.kafka_broker_leader_count));
+  setValue("working-set", formatBytes(m.kafka_memory_working_set_bytes));
+  setValue("memory-limit", formatBytes(m.kafka_memory_limit_bytes));
+  setValue("rss", formatBytes(m.kafka_memory_rss_bytes));
+  const ratio = m.kafka_memory_oom_ratio;
+  setValue("oom-ratio", ratio === undefined ? "-" : (ratio * 100).toFixed(1) + "%",
+    ratio === undefined ? "" : ratio > 0.9 ? "bad" : ratio > 0.8 ? "warn" : "ok");
+}
+
+async function refreshReassignments() {
+  const results = await Promise.all(REASSIGNMENTS.map((w) => get(w.path)));
+  $("reassignments").replaceChildren(...REASSIGNMENTS.map((w, i) => {
+    const res = results[i];
+    const row = document.createElement("tr");
+    cell(row, w.name);
+    if (res.status !== 200 || !res.body) {
+      cell(row, unavailable(res));
+      cell(row, "");
+      cell(row, "");
+      cell(row, "");
+      return row;
+    }
+    const s = res.body;
+    cell(row, s.state || "-");
+    cell(row, s.plannedMoves ? s.completedMoves + " / " + s.plannedMoves + " moves" : "-");
+    cell(row, formatTime(s.startedAt), s.startedAt);
+    cell(row, [s.topic, s.message].filter(Boolean).join(": "));
+    return row;
+  }));
+}
+
+async function refresh() {
+  await Promise.all([refreshHealth(), refreshChecks(), refreshMetrics(), refreshReassignments()]);
+  $("updated").textContent = "Updated " + new Date().toLocaleTimeString();
+}
+
+async function loadVersion() {
+  const res = await get("/about");
+  if (res.body && res.body.version) {
+    $("version").textContent = res.body.version;
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("token").value = sessionStorage.getItem(TOKEN_KEY) || "";
+  $("token-form").addEventListener("submit", (e) => {
+    e.preventDefault();
+    const token = $("token").value.trim();
+    if (token) {
+      sessionStorage.setItem(TOKEN_KEY, token);
+    } else {
+      sessionStorage.removeItem(TOKEN_KEY);
+    }
+    refresh();
+  });
+
+  loadVersion();
+  refresh();
+  setInterval(() => {
+    // Every refresh runs the readiness checks, so pause while nobody is looking
+    if (!document.hidden) {
+      refresh();
+    }
+  }, REFRESH_MS);
+});
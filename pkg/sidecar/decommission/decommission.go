@@ -0,0 +1,699 @@
+package decommission
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+const minISRKey = "min.insync.replicas"
+
+// State is the state of the decommission workflow
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateMoving    State = "moving"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+)
+
+// MinISRPolicy controls what happens when a decommission would leave topics
+// with fewer replicas than their min.insync.replicas
+type MinISRPolicy string
+
+const (
+	// MinISRPolicyReject refuses to start the decommission
+	MinISRPolicyReject MinISRPolicy = "reject"
+	// MinISRPolicyLower temporarily lowers min.insync.replicas on affected topics
+	// after explicit operator confirmation
+	MinISRPolicyLower MinISRPolicy = "lower"
+)
+
+// ParseMinISRPolicy validates a policy name
+func ParseMinISRPolicy(s string) (MinISRPolicy, error) {
+	switch p := MinISRPolicy(strings.ToLower(s)); p {
+	case MinISRPolicyReject, MinISRPolicyLower:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported min.insync.replicas policy: %s (supported: reject, lower)", s)
+	}
+}
+
+// AdminClient defines the Kafka admin operations needed for decommissioning.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	AlterTopicConfigs(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error)
+	reassign.AdminClient
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the decommission workflow
+type Options struct {
+	// BatchSize is the number of partitions moved per reassignment batch
+	BatchSize int
+	// PollInterval is how often in-flight reassignments are polled
+	PollInterval time.Duration
+	// Timeout bounds each metadata and config request
+	Timeout time.Duration
+	// MinISRPolicy decides how min.insync.replicas violations are handled
+	MinISRPolicy MinISRPolicy
+}
+
+// MinISRViolation is a topic whose replication factor would drop below its
+// min.insync.replicas once the broker is removed
+type MinISRViolation struct {
+	Topic             string `json:"topic"`
+	MinISR            int    `json:"minInsyncReplicas"`
+	ReplicationFactor int    `json:"replicationFactor"`
+	// Override is set when min.insync.replicas is a topic-level override rather
+	// than inherited from the broker default
+	Override bool `json:"override"`
+}
+
+// Plan describes the work needed to drain a broker
+type Plan struct {
+	BrokerID         int32             `json:"brokerId"`
+	Moves            []reassign.Move   `json:"moves"`
+	MinISRViolations []MinISRViolation `json:"minIsrViolations,omitempty"`
+}
+
+// StartOptions carries the operator's decisions for a decommission request
+type StartOptions struct {
+	// ConfirmMinISRReduction acknowledges that min.insync.replicas will be lowered
+	ConfirmMinISRReduction bool
+	// Actor identifies who requested the decommission, for audit records
+	Actor string
+}
+
+// Adjustment records a temporary min.insync.replicas reduction so it can be restored
+type Adjustment struct {
+	Topic      string     `json:"topic"`
+	BrokerID   int32      `json:"brokerId"`
+	Original   int        `json:"original"`
+	Override   bool       `json:"override"`
+	Lowered    int        `json:"lowered"`
+	AdjustedAt time.Time  `json:"adjustedAt"`
+	RestoredAt *time.Time `json:"restoredAt,omitempty"`
+}
+
+// RestoreResult is the outcome of restoring a single adjustment
+type RestoreResult struct {
+	Topic    string `json:"topic"`
+	Restored bool   `json:"restored"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditRecord is an entry in the decommission audit trail
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Actor    string    `json:"actor,omitempty"`
+	BrokerID int32     `json:"brokerId"`
+	Topic    string    `json:"topic,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// Status is the observable progress of the decommission workflow
+type Status struct {
+	State          State      `json:"state"`
+	BrokerID       int32      `json:"brokerId,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	PlannedMoves   int        `json:"plannedMoves"`
+	CompletedMoves int        `json:"completedMoves"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Decommissioner drains all partition replicas off a broker ahead of its removal
+type Decommissioner struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	unregister    Unregisterer
+	stateFile     string
+	clock         clock.Clock
+
+	mu          sync.RWMutex
+	status      Status
+	moves       []reassign.Move
+	adjustments map[string]*Adjustment
+	audit       []AuditRecord
+
+	saveMu sync.Mutex
+}
+
+// NewDecommissioner creates a new decommission workflow
+func NewDecommissioner(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Decommissioner {
+	d := &Decommissioner{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		status:      Status{State: StateIdle},
+		adjustments: make(map[string]*Adjustment),
+	}
+	// Set default client factory and unregister call
+	d.clientFactory = d.defaultClientFactory
+	d.unregister = d.defaultUnregister
+	return d
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (d *Decommissioner) SetClientFactory(factory ClientFactory) {
+	d.clientFactory = factory
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (d *Decommissioner) SetClock(clk clock.Clock) {
+	d.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (d *Decommissioner) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(d.kafkaConfig)
+}
+
+// Status returns a snapshot of the workflow status
+func (d *Decommissioner) Status() Status {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.status
+}
+
+// Adjustments returns every min.insync.replicas adjustment made, sorted by topic
+func (d *Decommissioner) Adjustments() []Adjustment {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	adjustments := make([]Adjustment, 0, len(d.adjustments))
+	for _, a := range d.adjustments {
+		adjustments = append(adjustments, *a)
+	}
+	sort.Slice(adjustments, func(i, j int) bool {
+		return adjustments[i].Topic < adjustments[j].Topic
+	})
+	return adjustments
+}
+
+// AuditLog returns the audit trail, oldest first
+func (d *Decommissioner) AuditLog() []AuditRecord {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]AuditRecord(nil), d.audit...)
+}
+
+// Plan computes the moves needed to drain the broker and the topics whose
+// min.insync.replicas could no longer be satisfied afterwards
+func (d *Decommissioner) Plan(ctx context.Context, brokerID int32) (Plan, error) {
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		return Plan{}, err
+	}
+	defer cleanup()
+
+	return d.plan(ctx, adm, brokerID)
+}
+
+func (d *Decommissioner) plan(ctx context.Context, adm AdminClient, brokerID int32) (Plan, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	if !brokerRegistered(md, brokerID) {
+		return Plan{}, cplnErrors.NotFound("broker", strconv.Itoa(int(brokerID)))
+	}
+	if len(md.Brokers) < 2 {
+		return Plan{}, cplnErrors.Validation("cannot decommission the only broker in the cluster")
+	}
+
+	plan := Plan{
+		BrokerID: brokerID,
+		Moves:    reassign.PlanDecommission(md, brokerID, reassign.PlanOptions{}),
+	}
+
+	replicationAfter := replicationAfter(md, plan.Moves)
+	if len(replicationAfter) == 0 {
+		return plan, nil
+	}
+
+	topics := make([]string, 0, len(replicationAfter))
+	for topic := range replicationAfter {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	configs, err := adm.DescribeTopicConfigs(ctx, topics...)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	for _, rc := range configs {
+		if rc.Err != nil {
+			return Plan{}, fmt.Errorf("failed to describe config of topic %s: %w", rc.Name, rc.Err)
+		}
+		minISR, override, ok := minISRConfig(rc)
+		if !ok {
+			continue
+		}
+		if rf := replicationAfter[rc.Name]; minISR > rf {
+			plan.MinISRViolations = append(plan.MinISRViolations, MinISRViolation{
+				Topic:             rc.Name,
+				MinISR:            minISR,
+				ReplicationFactor: rf,
+				Override:          override,
+			})
+		}
+	}
+	sort.Slice(plan.MinISRViolations, func(i, j int) bool {
+		return plan.MinISRViolations[i].Topic < plan.MinISRViolations[j].Topic
+	})
+
+	return plan, nil
+}
+
+// Start plans the decommission and, if allowed by the min.insync.replicas policy,
+// starts draining the broker in the background. Violations are rejected unless
+// the policy is "lower" and the operator explicitly confirmed the reduction.
+func (d *Decommissioner) Start(ctx context.Context, brokerID int32, opts StartOptions) (Plan, error) {
+	if d.Status().State == StateMoving {
+		return Plan{}, cplnErrors.Conflictf("decommission of broker %d is already in progress", d.Status().BrokerID)
+	}
+
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan, err := d.plan(ctx, adm, brokerID)
+	if err != nil {
+		cleanup()
+		return Plan{}, err
+	}
+
+	if len(plan.MinISRViolations) > 0 {
+		topics := violatingTopics(plan.MinISRViolations)
+		if d.opts.MinISRPolicy != MinISRPolicyLower {
+			cleanup()
+			return plan, cplnErrors.Conflictf(
+				"decommissioning broker %d would leave topics unable to satisfy min.insync.replicas: %s (policy is %q)",
+				brokerID, topics, d.opts.MinISRPolicy)
+		}
+		if !opts.ConfirmMinISRReduction {
+			cleanup()
+			return plan, cplnErrors.Conflictf(
+				"decommissioning broker %d requires lowering min.insync.replicas on: %s; resubmit with confirmMinIsrReduction to proceed",
+				brokerID, topics)
+		}
+		if err := d.lowerMinISR(ctx, adm, brokerID, plan.MinISRViolations, opts.Actor); err != nil {
+			cleanup()
+			return plan, err
+		}
+	}
+
+	if !d.begin(brokerID, plan.Moves) {
+		cleanup()
+		return Plan{}, cplnErrors.Conflictf("decommission of broker %d is already in progress", d.Status().BrokerID)
+	}
+	d.record(AuditRecord{
+		Action:   "decommission-started",
+		Actor:    opts.Actor,
+		BrokerID: brokerID,
+		Detail:   fmt.Sprintf("%d partition moves planned", len(plan.Moves)),
+	})
+
+	// The drain outlives the request that started it
+	go func() {
+		defer cleanup()
+		d.execute(context.WithoutCancel(ctx), adm, brokerID, plan.Moves)
+	}()
+
+	return plan, nil
+}
+
+// execute moves the planned replicas in batches and records the outcome
+func (d *Decommissioner) execute(ctx context.Context, adm AdminClient, brokerID int32, moves []reassign.Move) {
+	for start := 0; start < len(moves); start += d.opts.BatchSize {
+		end := start + d.opts.BatchSize
+		if end > len(moves) {
+			end = len(moves)
+		}
+		batch := moves[start:end]
+
+		if err := reassign.Execute(ctx, adm, batch); err != nil {
+			d.fail(brokerID, err)
+			return
+		}
+		if err := reassign.WaitForCompletion(ctx, d.clock, adm, batch, d.opts.PollInterval); err != nil {
+			d.fail(brokerID, err)
+			return
+		}
+		d.progress(len(batch))
+	}
+
+	d.finish()
+	d.record(AuditRecord{
+		Action:   "decommission-completed",
+		BrokerID: brokerID,
+		Detail:   fmt.Sprintf("%d partition moves completed", len(moves)),
+	})
+	d.restoreAfterDrain(ctx, brokerID)
+}
+
+// restoreAfterDrain attempts to restore the min.insync.replicas lowered so far
+// and records the topics it could not restore. They stay lowered until
+// restored through RestoreMinISR once their replication factor allows it.
+func (d *Decommissioner) restoreAfterDrain(ctx context.Context, brokerID int32) {
+	results, err := d.RestoreMinISR(ctx, "")
+	if err != nil {
+		d.logger.Error("decommission: failed to restore min.insync.replicas", "brokerId", brokerID, "error", err)
+		d.record(AuditRecord{
+			Action:   "min-isr-restore-failed",
+			BrokerID: brokerID,
+			Detail:   err.Error(),
+		})
+		return
+	}
+	for _, r := range results {
+		if !r.Restored {
+			d.record(AuditRecord{
+				Action:   "min-isr-not-restored",
+				BrokerID: brokerID,
+				Topic:    r.Topic,
+				Detail:   r.Reason,
+			})
+		}
+	}
+}
+
+// lowerMinISR sets min.insync.replicas on each violating topic to the
+// replication factor it will have after the decommission, recording the original
+func (d *Decommissioner) lowerMinISR(ctx context.Context, adm AdminClient, brokerID int32, violations []MinISRViolation, actor string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	for _, v := range violations {
+		lowered := max(v.ReplicationFactor, 1)
+		value := strconv.Itoa(lowered)
+		resp, err := adm.AlterTopicConfigs(ctx, []kadm.AlterConfig{
+			{Op: kadm.SetConfig, Name: minISRKey, Value: &value},
+		}, v.Topic)
+		if err == nil {
+			err = alterError(resp)
+		}
+		if err != nil {
+			return cplnErrors.Internal(fmt.Sprintf("failed to lower min.insync.replicas on topic %s", v.Topic), err)
+		}
+
+		d.mu.Lock()
+		// Keep the first original if the topic was already lowered by an earlier decommission
+		if existing, ok := d.adjustments[v.Topic]; ok && existing.RestoredAt == nil {
+			existing.Lowered = lowered
+			existing.BrokerID = brokerID
+		} else {
+			d.adjustments[v.Topic] = &Adjustment{
+				Topic:      v.Topic,
+				BrokerID:   brokerID,
+				Original:   v.MinISR,
+				Override:   v.Override,
+				Lowered:    lowered,
+				AdjustedAt: d.clock.Now(),
+			}
+		}
+		d.mu.Unlock()
+
+		d.record(AuditRecord{
+			Action:   "min-isr-lowered",
+			Actor:    actor,
+			BrokerID: brokerID,
+			Topic:    v.Topic,
+			Detail:   fmt.Sprintf("min.insync.replicas %d -> %d", v.MinISR, lowered),
+		})
+	}
+	return nil
+}
+
+// RestoreMinISR restores the original min.insync.replicas on every lowered topic
+// whose replication factor can satisfy it again. Topics that still cannot are
+// left lowered and reported with a reason.
+func (d *Decommissioner) RestoreMinISR(ctx context.Context, actor string) ([]RestoreResult, error) {
+	pending := make([]Adjustment, 0)
+	for _, a := range d.Adjustments() {
+		if a.RestoredAt == nil {
+			pending = append(pending, a)
+		}
+	}
+	if len(pending) == 0 {
+		return []RestoreResult{}, nil
+	}
+
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	topics := make([]string, len(pending))
+	for i, a := range pending {
+		topics[i] = a.Topic
+	}
+	md, err := adm.Metadata(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	results := make([]RestoreResult, 0, len(pending))
+	for _, a := range pending {
+		result := RestoreResult{Topic: a.Topic}
+
+		rf, ok := minReplicationFactor(md, a.Topic)
+		switch {
+		case !ok:
+			result.Reason = "topic not found in metadata"
+		case rf < a.Original:
+			result.Reason = fmt.Sprintf("replication factor %d is below original min.insync.replicas %d", rf, a.Original)
+		default:
+			if err := d.restore(ctx, adm, a); err != nil {
+				result.Reason = err.Error()
+				break
+			}
+			result.Restored = true
+			d.record(AuditRecord{
+				Action:   "min-isr-restored",
+				Actor:    actor,
+				BrokerID: a.BrokerID,
+				Topic:    a.Topic,
+				Detail:   fmt.Sprintf("min.insync.replicas %d -> %d", a.Lowered, a.Original),
+			})
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// restore reverts a single adjustment. Inherited values are restored by removing
+// the topic override rather than pinning the old broker default.
+func (d *Decommissioner) restore(ctx context.Context, adm AdminClient, a Adjustment) error {
+	alter := kadm.AlterConfig{Op: kadm.DeleteConfig, Name: minISRKey}
+	if a.Override {
+		value := strconv.Itoa(a.Original)
+		alter = kadm.AlterConfig{Op: kadm.SetConfig, Name: minISRKey, Value: &value}
+	}
+
+	resp, err := adm.AlterTopicConfigs(ctx, []kadm.AlterConfig{alter}, a.Topic)
+	if err == nil {
+		err = alterError(resp)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore min.insync.replicas: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	d.adjustments[a.Topic].RestoredAt = &now
+	return nil
+}
+
+// record appends an audit record and mirrors it to the log
+func (d *Decommissioner) record(rec AuditRecord) {
+	rec.Time = d.clock.Now()
+	d.logger.Info("decommission audit",
+		"action", rec.Action,
+		"actor", rec.Actor,
+		"brokerId", rec.BrokerID,
+		"topic", rec.Topic,
+		"detail", rec.Detail)
+
+	d.mu.Lock()
+	d.audit = append(d.audit, rec)
+	d.mu.Unlock()
+	d.save()
+}
+
+// begin transitions to moving unless a decommission is already running
+func (d *Decommissioner) begin(brokerID int32, moves []reassign.Move) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.status.State == StateMoving {
+		return false
+	}
+	now := d.clock.Now()
+	d.status = Status{
+		State:        StateMoving,
+		BrokerID:     brokerID,
+		PlannedMoves: len(moves),
+		StartedAt:    &now,
+	}
+	d.moves = moves
+	return true
+}
+
+func (d *Decommissioner) progress(completed int) {
+	d.mu.Lock()
+	d.status.CompletedMoves += completed
+	d.mu.Unlock()
+	d.save()
+}
+
+func (d *Decommissioner) finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	d.status.State = StateCompleted
+	d.status.FinishedAt = &now
+}
+
+func (d *Decommissioner) fail(brokerID int32, err error) {
+	d.logger.Error("decommission: failed", "brokerId", brokerID, "error", err)
+	d.mu.Lock()
+	now := d.clock.Now()
+	d.status.State = StateFailed
+	d.status.Message = err.Error()
+	d.status.FinishedAt = &now
+	d.mu.Unlock()
+
+	d.record(AuditRecord{
+		Action:   "decommission-failed",
+		BrokerID: brokerID,
+		Detail:   err.Error(),
+	})
+}
+
+// brokerRegistered reports whether the broker is present in cluster metadata
+func brokerRegistered(md kadm.Metadata, brokerID int32) bool {
+	for _, b := range md.Brokers {
+		if b.NodeID == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// replicationAfter returns, for each topic with a shrinking move, the smallest
+// partition replication factor the topic will have once the moves complete
+func replicationAfter(md kadm.Metadata, moves []reassign.Move) map[string]int {
+	shrinking := make(map[string]map[int32]int)
+	for _, m := range moves {
+		if !m.ShrinksReplication() {
+			continue
+		}
+		if shrinking[m.Topic] == nil {
+			shrinking[m.Topic] = make(map[int32]int)
+		}
+		shrinking[m.Topic][m.Partition] = len(m.Target)
+	}
+
+	after := make(map[string]int, len(shrinking))
+	for topic, partitions := range shrinking {
+		rf := -1
+		for _, p := range md.Topics[topic].Partitions {
+			n := len(p.Replicas)
+			if shrunk, ok := partitions[p.Partition]; ok {
+				n = shrunk
+			}
+			if rf < 0 || n < rf {
+				rf = n
+			}
+		}
+		after[topic] = rf
+	}
+	return after
+}
+
+// minReplicationFactor returns the smallest partition replication factor of a topic
+func minReplicationFactor(md kadm.Metadata, topic string) (int, bool) {
+	detail, ok := md.Topics[topic]
+	if !ok || detail.Err != nil || len(detail.Partitions) == 0 {
+		return 0, false
+	}
+	rf := -1
+	for _, p := range detail.Partitions {
+		if rf < 0 || len(p.Replicas) < rf {
+			rf = len(p.Replicas)
+		}
+	}
+	return rf, true
+}
+
+// minISRConfig extracts min.insync.replicas and whether it is a topic override
+func minISRConfig(rc kadm.ResourceConfig) (int, bool, bool) {
+	for _, c := range rc.Configs {
+		if c.Key != minISRKey || c.Value == nil {
+			continue
+		}
+		v, err := strconv.Atoi(*c.Value)
+		if err != nil {
+			return 0, false, false
+		}
+		return v, c.Source == kmsg.ConfigSourceDynamicTopicConfig, true
+	}
+	return 0, false, false
+}
+
+// alterError returns the first error in an alter configs response
+func alterError(resp kadm.AlterConfigsResponses) error {
+	for _, r := range resp {
+		if r.Err == nil {
+			continue
+		}
+		if r.ErrMessage != "" {
+			return fmt.Errorf("%w: %s", r.Err, r.ErrMessage)
+		}
+		return r.Err
+	}
+	return nil
+}
+
+// violatingTopics formats the violating topic names for error messages
+func violatingTopics(violations []MinISRViolation) string {
+	topics := make([]string, len(violations))
+	for i, v := range violations {
+		topics[i] = fmt.Sprintf("%s (min.insync.replicas=%d, replicas=%d)", v.Topic, v.MinISR, v.ReplicationFactor)
+	}
+	return strings.Join(topics, ", ")
+}
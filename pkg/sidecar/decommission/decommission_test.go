@@ -0,0 +1,563 @@
+package decommission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/kafkatest"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigsFunc       func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	AlterTopicConfigsFunc          func(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error)
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+	if m.DescribeTopicConfigsFunc != nil {
+		return m.DescribeTopicConfigsFunc(ctx, topics...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) AlterTopicConfigs(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error) {
+	if m.AlterTopicConfigsFunc != nil {
+		return m.AlterTopicConfigsFunc(ctx, configs, topics...)
+	}
+	return kadm.AlterConfigsResponses{}, nil
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func testOptions(policy MinISRPolicy) Options {
+	return Options{
+		BatchSize:    2,
+		PollInterval: time.Millisecond,
+		Timeout:      time.Second,
+		MinISRPolicy: policy,
+	}
+}
+
+// clusterMetadata returns a 3-broker cluster with one RF=3 topic of 2 partitions
+func clusterMetadata() kadm.Metadata {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{}}
+	for _, b := range []int32{0, 1, 2} {
+		md.Brokers = append(md.Brokers, kadm.BrokerDetail{NodeID: b})
+	}
+	partitions := kadm.PartitionDetails{}
+	for i := int32(0); i < 2; i++ {
+		partitions[i] = kadm.PartitionDetail{Topic: "orders", Partition: i, Replicas: []int32{0, 1, 2}, ISR: []int32{0, 1, 2}}
+	}
+	md.Topics["orders"] = kadm.TopicDetail{Topic: "orders", Partitions: partitions}
+	return md
+}
+
+// topicConfigs returns min.insync.replicas for the orders topic
+func topicConfigs(minISR string, source kmsg.ConfigSource) kadm.ResourceConfigs {
+	return kadm.ResourceConfigs{{
+		Name:    "orders",
+		Configs: []kadm.Config{{Key: minISRKey, Value: &minISR, Source: source}},
+	}}
+}
+
+// alterRecorder records AlterTopicConfigs calls
+type alterRecorder struct {
+	mu    sync.Mutex
+	calls []kadm.AlterConfig
+}
+
+func (r *alterRecorder) alter(_ context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, configs...)
+	var resp kadm.AlterConfigsResponses
+	for _, t := range topics {
+		resp = append(resp, kadm.AlterConfigsResponse{Name: t})
+	}
+	return resp, nil
+}
+
+func newTestDecommissioner(policy MinISRPolicy, mock *MockAdminClient) *Decommissioner {
+	d := NewDecommissioner(kafkaclient.Config{}, testOptions(policy), testLogger())
+	d.SetClientFactory(func() (AdminClient, func(), error) {
+		return mock, func() {}, nil
+	})
+	return d
+}
+
+func waitForState(t *testing.T, d *Decommissioner, state State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if d.Status().State == state {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected state %s, got %s", state, d.Status().State)
+}
+
+// waitForAudit waits for the audit trail to end with the action
+func waitForAudit(t *testing.T, d *Decommissioner, action string) []AuditRecord {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if audit := d.AuditLog(); len(audit) > 0 && audit[len(audit)-1].Action == action {
+			return audit
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected the audit trail to end with %s, got %+v", action, d.AuditLog())
+	return nil
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name               string
+		brokerID           int32
+		minISR             string
+		expectErr          bool
+		expectViolations   int
+		expectViolationRF  int
+		expectMovesShrinks bool
+	}{
+		{
+			name:               "violation when replication factor drops below min.isr",
+			brokerID:           2,
+			minISR:             "3",
+			expectViolations:   1,
+			expectViolationRF:  2,
+			expectMovesShrinks: true,
+		},
+		{
+			name:               "no violation when min.isr still satisfiable",
+			brokerID:           2,
+			minISR:             "2",
+			expectMovesShrinks: true,
+		},
+		{
+			name:      "unknown broker",
+			brokerID:  9,
+			minISR:    "2",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{
+				MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+					return clusterMetadata(), nil
+				},
+				DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+					return topicConfigs(tt.minISR, kmsg.ConfigSourceDefaultConfig), nil
+				},
+			})
+
+			plan, err := d.Plan(context.Background(), tt.brokerID)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(plan.Moves) != 2 {
+				t.Fatalf("expected 2 moves, got %d", len(plan.Moves))
+			}
+			if plan.Moves[0].ShrinksReplication() != tt.expectMovesShrinks {
+				t.Errorf("expected shrinks=%v", tt.expectMovesShrinks)
+			}
+			if len(plan.MinISRViolations) != tt.expectViolations {
+				t.Fatalf("expected %d violations, got %d", tt.expectViolations, len(plan.MinISRViolations))
+			}
+			if tt.expectViolations > 0 && plan.MinISRViolations[0].ReplicationFactor != tt.expectViolationRF {
+				t.Errorf("expected violation RF %d, got %d", tt.expectViolationRF, plan.MinISRViolations[0].ReplicationFactor)
+			}
+		})
+	}
+}
+
+func TestStartMinISRPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      MinISRPolicy
+		confirm     bool
+		expectErr   string
+		expectAlter bool
+	}{
+		{
+			name:      "reject policy refuses",
+			policy:    MinISRPolicyReject,
+			confirm:   true,
+			expectErr: "policy is",
+		},
+		{
+			name:      "lower policy requires confirmation",
+			policy:    MinISRPolicyLower,
+			expectErr: "confirmMinIsrReduction",
+		},
+		{
+			name:        "lower policy with confirmation lowers min.isr",
+			policy:      MinISRPolicyLower,
+			confirm:     true,
+			expectAlter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &alterRecorder{}
+			reassigned := false
+			d := newTestDecommissioner(tt.policy, &MockAdminClient{
+				MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+					return clusterMetadata(), nil
+				},
+				DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+					return topicConfigs("3", kmsg.ConfigSourceDynamicTopicConfig), nil
+				},
+				AlterTopicConfigsFunc: recorder.alter,
+				AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+					reassigned = true
+					return kadm.AlterPartitionAssignmentsResponses{}, nil
+				},
+			})
+
+			_, err := d.Start(context.Background(), 2, StartOptions{ConfirmMinISRReduction: tt.confirm, Actor: "alice"})
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				if len(recorder.calls) > 0 || reassigned {
+					t.Error("expected no changes to the cluster")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			waitForState(t, d, StateCompleted)
+			// The replication factor is back to 3, so the drain restores the original
+			audit := waitForAudit(t, d, "min-isr-restored")
+
+			recorder.mu.Lock()
+			calls := recorder.calls
+			recorder.mu.Unlock()
+			if len(calls) != 2 || *calls[0].Value != "2" || *calls[1].Value != "3" {
+				t.Fatalf("expected min.insync.replicas lowered to 2 and restored to 3, got %+v", calls)
+			}
+			adjustments := d.Adjustments()
+			if len(adjustments) != 1 || adjustments[0].Original != 3 || !adjustments[0].Override {
+				t.Errorf("expected original override of 3 recorded, got %+v", adjustments)
+			}
+
+			actions := make([]string, 0)
+			for _, rec := range audit {
+				actions = append(actions, rec.Action)
+				if rec.Action == "min-isr-lowered" && rec.Actor != "alice" {
+					t.Errorf("expected actor alice, got %q", rec.Actor)
+				}
+			}
+			expected := "min-isr-lowered,decommission-started,decommission-completed,min-isr-restored"
+			if strings.Join(actions, ",") != expected {
+				t.Errorf("expected audit %s, got %v", expected, actions)
+			}
+		})
+	}
+}
+
+func TestStartFailedReassignment(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			return clusterMetadata(), nil
+		},
+		DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			return topicConfigs("1", kmsg.ConfigSourceDefaultConfig), nil
+		},
+		AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			return nil, errors.New("controller unavailable")
+		},
+	})
+
+	if _, err := d.Start(context.Background(), 2, StartOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForState(t, d, StateFailed)
+	if !strings.Contains(d.Status().Message, "controller unavailable") {
+		t.Errorf("unexpected message: %s", d.Status().Message)
+	}
+}
+
+func TestDecommissionFakeCluster(t *testing.T) {
+	c := kafkatest.Start(t, kafkatest.Options{Brokers: 3, AutoCompleteReassignments: true})
+	c.AddTopic("orders", []int32{0, 2}, []int32{2, 1}, []int32{1, 0}, []int32{2, 0})
+	c.SetTopicConfig("orders", minISRKey, "1")
+
+	d := NewDecommissioner(c.Config(), Options{
+		BatchSize:    1,
+		PollInterval: time.Millisecond,
+		Timeout:      5 * time.Second,
+		MinISRPolicy: MinISRPolicyReject,
+	}, testLogger())
+	plan, err := d.Start(context.Background(), 2, StartOptions{Actor: "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Moves) != 3 {
+		t.Fatalf("expected 3 moves, got %v", plan.Moves)
+	}
+	waitForState(t, d, StateCompleted)
+
+	for p := int32(0); p < 4; p++ {
+		state, _ := c.Partition("orders", p)
+		if len(state.Replicas) != 2 {
+			t.Errorf("partition %d lost a replica: %v", p, state.Replicas)
+		}
+		for _, r := range state.Replicas {
+			if r == 2 {
+				t.Errorf("partition %d still on broker 2: %v", p, state.Replicas)
+			}
+		}
+	}
+}
+
+func TestRestoreMinISR(t *testing.T) {
+	tests := []struct {
+		name          string
+		override      bool
+		replicas      []int32
+		expectRestore bool
+		expectOp      kadm.IncrementalOp
+	}{
+		{
+			name:          "override restored once replication factor recovers",
+			override:      true,
+			replicas:      []int32{0, 1, 3},
+			expectRestore: true,
+			expectOp:      kadm.SetConfig,
+		},
+		{
+			name:          "inherited value restored by deleting override",
+			replicas:      []int32{0, 1, 3},
+			expectRestore: true,
+			expectOp:      kadm.DeleteConfig,
+		},
+		{
+			name:     "left lowered while replication factor is still reduced",
+			override: true,
+			replicas: []int32{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &alterRecorder{}
+			md := clusterMetadata()
+			detail := md.Topics["orders"]
+			for p, pd := range detail.Partitions {
+				pd.Replicas = tt.replicas
+				detail.Partitions[p] = pd
+			}
+			d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{
+				MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+					return md, nil
+				},
+				AlterTopicConfigsFunc: recorder.alter,
+			})
+			d.adjustments["orders"] = &Adjustment{Topic: "orders", Original: 3, Override: tt.override, Lowered: 2}
+
+			results, err := d.RestoreMinISR(context.Background(), "alice")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != 1 || results[0].Restored != tt.expectRestore {
+				t.Fatalf("expected restored=%v, got %+v", tt.expectRestore, results)
+			}
+			if !tt.expectRestore {
+				if len(recorder.calls) != 0 || results[0].Reason == "" {
+					t.Errorf("expected no alteration and a reason, got %+v", results[0])
+				}
+				return
+			}
+			if len(recorder.calls) != 1 || recorder.calls[0].Op != tt.expectOp {
+				t.Fatalf("expected op %v, got %+v", tt.expectOp, recorder.calls)
+			}
+			if d.Adjustments()[0].RestoredAt == nil {
+				t.Error("expected adjustment marked restored")
+			}
+		})
+	}
+}
+
+func TestDrainRestoresMinISR(t *testing.T) {
+	drained := clusterMetadata()
+	detail := drained.Topics["orders"]
+	for p, pd := range detail.Partitions {
+		pd.Replicas = []int32{0, 1}
+		detail.Partitions[p] = pd
+	}
+
+	tests := []struct {
+		name         string
+		metadata     func() (kadm.Metadata, error)
+		expectAction string
+		expectDetail string
+	}{
+		{
+			name:         "left lowered while replication factor is still reduced",
+			metadata:     func() (kadm.Metadata, error) { return drained, nil },
+			expectAction: "min-isr-not-restored",
+			expectDetail: "replication factor 2",
+		},
+		{
+			name:         "restore failure recorded",
+			metadata:     func() (kadm.Metadata, error) { return kadm.Metadata{}, errors.New("controller unavailable") },
+			expectAction: "min-isr-restore-failed",
+			expectDetail: "controller unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &alterRecorder{}
+			d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{
+				MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+					// Only the restore asks for the metadata of specific topics
+					if len(topics) > 0 {
+						return tt.metadata()
+					}
+					return clusterMetadata(), nil
+				},
+				DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+					return topicConfigs("3", kmsg.ConfigSourceDynamicTopicConfig), nil
+				},
+				AlterTopicConfigsFunc: recorder.alter,
+			})
+
+			if _, err := d.Start(context.Background(), 2, StartOptions{ConfirmMinISRReduction: true}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			audit := waitForAudit(t, d, tt.expectAction)
+			if last := audit[len(audit)-1]; last.BrokerID != 2 || !strings.Contains(last.Detail, tt.expectDetail) {
+				t.Errorf("expected broker 2 and detail containing %q, got %+v", tt.expectDetail, last)
+			}
+			if audit[len(audit)-2].Action != "decommission-completed" {
+				t.Errorf("expected the restore to follow the completed drain, got %+v", audit)
+			}
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if len(recorder.calls) != 1 {
+				t.Errorf("expected min.insync.replicas only lowered, got %+v", recorder.calls)
+			}
+			if adjustments := d.Adjustments(); len(adjustments) != 1 || adjustments[0].RestoredAt != nil {
+				t.Errorf("expected the adjustment left for a later restore, got %+v", adjustments)
+			}
+		})
+	}
+}
+
+func TestStartHandlerConflict(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			return clusterMetadata(), nil
+		},
+		DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			return topicConfigs("3", kmsg.ConfigSourceDefaultConfig), nil
+		},
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/decommission/{brokerId}", d.StartHandler).Methods("POST")
+
+	req := httptest.NewRequest("POST", "/admin/decommission/2", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPlanHandlerInvalidBrokerID(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/decommission/{brokerId}/plan", d.PlanHandler).Methods("GET")
+
+	req := httptest.NewRequest("GET", "/admin/decommission/abc/plan", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestMinISRHandler(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{})
+	d.adjustments["orders"] = &Adjustment{Topic: "orders", Original: 3, Lowered: 2}
+
+	req := httptest.NewRequest("GET", "/admin/decommission/min-isr", nil)
+	w := httptest.NewRecorder()
+	d.MinISRHandler(w, req)
+
+	var response MinISRResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Policy != MinISRPolicyLower || len(response.Adjustments) != 1 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestParseMinISRPolicy(t *testing.T) {
+	if p, err := ParseMinISRPolicy("LOWER"); err != nil || p != MinISRPolicyLower {
+		t.Errorf("expected lower, got %q (%v)", p, err)
+	}
+	if _, err := ParseMinISRPolicy("ignore"); err == nil {
+		t.Error("expected error for unsupported policy")
+	}
+}
@@ -0,0 +1,164 @@
+package decommission
+
+import (
+	"net/http"
+	"strconv"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/gorilla/mux"
+)
+
+// StartRequest is the body of a decommission request
+type StartRequest struct {
+	// ConfirmMinISRReduction acknowledges that min.insync.replicas will be lowered
+	// on topics that could otherwise no longer satisfy it
+	ConfirmMinISRReduction bool `json:"confirmMinIsrReduction"`
+	// RequestedBy identifies the operator for the audit trail
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// StartResponse represents the response for the start endpoint
+type StartResponse struct {
+	Plan   Plan   `json:"plan"`
+	Status Status `json:"status"`
+}
+
+// UnregisterRequest is the body of an unregister request
+type UnregisterRequest struct {
+	// RequestedBy identifies the operator for the audit trail
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// MinISRResponse represents the response for the min.insync.replicas endpoint
+type MinISRResponse struct {
+	Policy      MinISRPolicy  `json:"policy"`
+	Adjustments []Adjustment  `json:"adjustments"`
+	Audit       []AuditRecord `json:"audit"`
+}
+
+// RestoreResponse represents the response for the restore endpoint
+type RestoreResponse struct {
+	Results []RestoreResult `json:"results"`
+}
+
+// PlanHandler handles GET /admin/decommission/{brokerId}/plan requests
+func (d *Decommissioner) PlanHandler(w http.ResponseWriter, req *http.Request) {
+	brokerID, err := brokerIDVar(req)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return
+	}
+
+	plan, err := d.Plan(req.Context(), brokerID)
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to plan decommission", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, plan)
+}
+
+// StartHandler handles POST /admin/decommission/{brokerId} requests
+func (d *Decommissioner) StartHandler(w http.ResponseWriter, req *http.Request) {
+	brokerID, err := brokerIDVar(req)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return
+	}
+
+	var body StartRequest
+	if req.ContentLength > 0 {
+		parsed, err := web.ParseJsonRequestBody[StartRequest](req)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+			return
+		}
+		body = parsed
+	}
+
+	plan, err := d.Start(req.Context(), brokerID, StartOptions{
+		ConfirmMinISRReduction: body.ConfirmMinISRReduction,
+		Actor:                  actor(req, body.RequestedBy),
+	})
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to start decommission", err))
+		return
+	}
+	_, _ = web.ReturnResponseWithCode(w, StartResponse{Plan: plan, Status: d.Status()}, http.StatusAccepted)
+}
+
+// UnregisterHandler handles POST /admin/decommission/{brokerId}/unregister requests
+func (d *Decommissioner) UnregisterHandler(w http.ResponseWriter, req *http.Request) {
+	brokerID, err := brokerIDVar(req)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return
+	}
+
+	var body UnregisterRequest
+	if req.ContentLength > 0 {
+		parsed, err := web.ParseJsonRequestBody[UnregisterRequest](req)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+			return
+		}
+		body = parsed
+	}
+
+	result, err := d.Unregister(req.Context(), brokerID, actor(req, body.RequestedBy))
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to unregister broker", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, result)
+}
+
+// StatusHandler handles GET /admin/decommission requests
+func (d *Decommissioner) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, d.Status())
+}
+
+// MinISRHandler handles GET /admin/decommission/min-isr requests
+func (d *Decommissioner) MinISRHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, MinISRResponse{
+		Policy:      d.opts.MinISRPolicy,
+		Adjustments: d.Adjustments(),
+		Audit:       d.AuditLog(),
+	})
+}
+
+// RestoreMinISRHandler handles POST /admin/decommission/min-isr/restore requests
+func (d *Decommissioner) RestoreMinISRHandler(w http.ResponseWriter, req *http.Request) {
+	results, err := d.RestoreMinISR(req.Context(), actor(req, req.URL.Query().Get("requestedBy")))
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to restore min.insync.replicas", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, RestoreResponse{Results: results})
+}
+
+// brokerIDVar parses the brokerId path variable
+func brokerIDVar(req *http.Request) (int32, error) {
+	raw := mux.Vars(req)["brokerId"]
+	id, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || id < 0 {
+		return 0, cplnErrors.Validationf("invalid broker ID: %q", raw)
+	}
+	return int32(id), nil
+}
+
+// actor identifies the requester, falling back to the remote address
+func actor(req *http.Request, requestedBy string) string {
+	if requestedBy != "" {
+		return requestedBy
+	}
+	return req.RemoteAddr
+}
+
+// wrapError passes domain errors through and reports anything else as internal
+func wrapError(msg string, err error) error {
+	if cplnErrors.IsDomainError(err) {
+		return err
+	}
+	return cplnErrors.Internal(msg, err)
+}
@@ -0,0 +1,235 @@
+package decommission
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// ErrCorruptState is returned when the state file fails its integrity check.
+// Resuming from it could repeat or undo cluster changes, so it is not used.
+var ErrCorruptState = errors.New("decommission state failed its integrity check")
+
+// persistedState is the workflow state kept in the state file, enough for a
+// restarted sidecar to resume a drain and restore lowered min.insync.replicas
+type persistedState struct {
+	Status      Status          `json:"status"`
+	Moves       []reassign.Move `json:"moves,omitempty"`
+	Adjustments []Adjustment    `json:"adjustments,omitempty"`
+	Audit       []AuditRecord   `json:"audit,omitempty"`
+	// Checksum is the SHA-256 of the state's JSON encoding without it
+	Checksum string `json:"checksum"`
+}
+
+// checksum returns the checksum of the state, ignoring its Checksum field
+func (s persistedState) checksum() (string, error) {
+	s.Checksum = ""
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// verify checks the state was written whole by this sidecar and describes a
+// workflow it can act on
+func (s persistedState) verify() error {
+	sum, err := s.checksum()
+	if err != nil {
+		return err
+	}
+	if s.Checksum != sum {
+		return errors.New("checksum mismatch")
+	}
+	switch s.Status.State {
+	case StateIdle, StateMoving, StateCompleted, StateFailed:
+	default:
+		return fmt.Errorf("unknown state %q", s.Status.State)
+	}
+	if s.Status.CompletedMoves < 0 || s.Status.CompletedMoves > s.Status.PlannedMoves {
+		return fmt.Errorf("%d of %d moves completed", s.Status.CompletedMoves, s.Status.PlannedMoves)
+	}
+	return nil
+}
+
+// SetStateFile persists the workflow state to path after every change, so a
+// decommission interrupted by a sidecar restart can be resumed
+func (d *Decommissioner) SetStateFile(path string) {
+	d.stateFile = path
+}
+
+// LoadState restores the workflow state from the state file. A file that
+// cannot be parsed or fails its checksum returns an error wrapping
+// ErrCorruptState and leaves the decommissioner idle.
+func (d *Decommissioner) LoadState() error {
+	if d.stateFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(d.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read decommission state: %w", err)
+	}
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrCorruptState, d.stateFile, err)
+	}
+	if err := state.verify(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrCorruptState, d.stateFile, err)
+	}
+
+	d.mu.Lock()
+	d.status = state.Status
+	d.moves = state.Moves
+	d.audit = state.Audit
+	d.adjustments = make(map[string]*Adjustment, len(state.Adjustments))
+	for _, a := range state.Adjustments {
+		d.adjustments[a.Topic] = &a
+	}
+	d.mu.Unlock()
+	return nil
+}
+
+// Resume continues in the background a decommission that LoadState found still
+// in progress. The cluster is checked again first: reassignments submitted
+// before the restart are waited for, and the remaining moves are planned from
+// current metadata rather than taken from the file.
+func (d *Decommissioner) Resume(ctx context.Context) {
+	d.mu.RLock()
+	status, moves := d.status, d.moves
+	d.mu.RUnlock()
+	if status.State != StateMoving {
+		return
+	}
+	d.logger.Info("decommission: resuming after restart",
+		"brokerId", status.BrokerID,
+		"completedMoves", status.CompletedMoves,
+		"plannedMoves", status.PlannedMoves)
+
+	// Like a started drain, the resumed one is not stopped by shutdown; a
+	// restart resumes it again
+	go d.resume(context.WithoutCancel(ctx), status.BrokerID, moves)
+}
+
+// ResetState discards a corrupted state file, keeping it aside as
+// <file>.corrupt-<unix time> for inspection, and starts over idle
+func (d *Decommissioner) ResetState() error {
+	if d.stateFile == "" {
+		return nil
+	}
+	aside := fmt.Sprintf("%s.corrupt-%d", d.stateFile, d.clock.Now().Unix())
+	if err := os.Rename(d.stateFile, aside); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to move aside %s: %w", d.stateFile, err)
+	}
+
+	d.mu.Lock()
+	d.status = Status{State: StateIdle}
+	d.moves = nil
+	d.adjustments = make(map[string]*Adjustment)
+	d.audit = nil
+	d.mu.Unlock()
+	d.logger.Warn("decommission: discarded corrupted state", "path", d.stateFile, "keptAs", aside)
+	return nil
+}
+
+// resume re-verifies the cluster and moves the replicas still on the broker
+func (d *Decommissioner) resume(ctx context.Context, brokerID int32, moves []reassign.Move) {
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		d.fail(brokerID, err)
+		return
+	}
+	defer cleanup()
+
+	// The batch in flight at the restart may still be copying replicas
+	if err := reassign.WaitForCompletion(ctx, d.clock, adm, moves, d.opts.PollInterval); err != nil {
+		d.fail(brokerID, err)
+		return
+	}
+
+	mdCtx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	md, err := adm.Metadata(mdCtx)
+	cancel()
+	if err != nil {
+		d.fail(brokerID, fmt.Errorf("failed to fetch metadata: %w", err))
+		return
+	}
+
+	remaining := reassign.PlanDecommission(md, brokerID, reassign.PlanOptions{})
+	if len(remaining) > 0 && !brokerRegistered(md, brokerID) {
+		d.fail(brokerID, fmt.Errorf("broker %d is no longer registered but still holds %d partition replicas", brokerID, len(remaining)))
+		return
+	}
+
+	d.mu.Lock()
+	d.status.PlannedMoves = d.status.CompletedMoves + len(remaining)
+	d.moves = remaining
+	d.mu.Unlock()
+	d.record(AuditRecord{
+		Action:   "decommission-resumed",
+		BrokerID: brokerID,
+		Detail:   fmt.Sprintf("%d partition moves remaining", len(remaining)),
+	})
+
+	d.execute(ctx, adm, brokerID, remaining)
+}
+
+// save writes the workflow state to the state file. A failed write is only
+// logged: the drain itself must not stop because its progress cannot be saved.
+func (d *Decommissioner) save() {
+	if d.stateFile == "" {
+		return
+	}
+	// Serialize writes so an older snapshot never replaces a newer one
+	d.saveMu.Lock()
+	defer d.saveMu.Unlock()
+
+	d.mu.RLock()
+	state := persistedState{
+		Status:      d.status,
+		Moves:       d.moves,
+		Adjustments: make([]Adjustment, 0, len(d.adjustments)),
+		Audit:       append([]AuditRecord(nil), d.audit...),
+	}
+	for _, a := range d.adjustments {
+		state.Adjustments = append(state.Adjustments, *a)
+	}
+	d.mu.RUnlock()
+
+	sum, err := state.checksum()
+	if err != nil {
+		d.logger.Warn("decommission: failed to save state", "path", d.stateFile, "error", err)
+		return
+	}
+	state.Checksum = sum
+
+	if err := writeJSON(d.stateFile, state); err != nil {
+		d.logger.Warn("decommission: failed to save state", "path", d.stateFile, "error", err)
+	}
+}
+
+// writeJSON atomically replaces path with the JSON encoding of v, so a restart
+// mid-write never leaves a truncated state file behind
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
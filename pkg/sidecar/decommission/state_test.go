@@ -0,0 +1,263 @@
+package decommission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// writeState writes a state file recording a drain of broker 2 interrupted
+// after its first batch
+func writeState(t *testing.T, path string) {
+	t.Helper()
+	started := time.Now().Add(-time.Hour)
+	state := persistedState{
+		Status: Status{State: StateMoving, BrokerID: 2, PlannedMoves: 2, CompletedMoves: 1, StartedAt: &started},
+		Moves: []reassign.Move{
+			{Topic: "orders", Partition: 0, Current: []int32{0, 1, 2}, Target: []int32{0, 1}},
+			{Topic: "orders", Partition: 1, Current: []int32{0, 1, 2}, Target: []int32{0, 1}},
+		},
+		Adjustments: []Adjustment{{Topic: "orders", BrokerID: 2, Original: 3, Override: true, Lowered: 2}},
+		Audit:       []AuditRecord{{Action: "decommission-started", BrokerID: 2}},
+	}
+	sum, err := state.checksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Checksum = sum
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// partlyDrained returns the cluster with partition 0 already moved off broker 2
+func partlyDrained() kadm.Metadata {
+	md := clusterMetadata()
+	p := md.Topics["orders"].Partitions[0]
+	p.Replicas = []int32{0, 1}
+	p.ISR = []int32{0, 1}
+	md.Topics["orders"].Partitions[0] = p
+	return md
+}
+
+// waitForSaved waits for the state file to record the audit action, which is
+// saved just after the state change it follows
+func waitForSaved(t *testing.T, path, action string) persistedState {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		var saved persistedState
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &saved) == nil {
+			if n := len(saved.Audit); n > 0 && saved.Audit[n-1].Action == action {
+				return saved
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %s to be saved", action)
+	return persistedState{}
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decommission.json")
+	mock := &MockAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			return clusterMetadata(), nil
+		},
+		DescribeTopicConfigsFunc: func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			return topicConfigs("3", kmsg.ConfigSourceDynamicTopicConfig), nil
+		},
+		AlterTopicConfigsFunc: (&alterRecorder{}).alter,
+	}
+	d := newTestDecommissioner(MinISRPolicyLower, mock)
+	d.SetStateFile(path)
+	if _, err := d.Start(context.Background(), 2, StartOptions{ConfirmMinISRReduction: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Once drained, the lowered min.insync.replicas is restored
+	waitForSaved(t, path, "min-isr-restored")
+
+	restarted := newTestDecommissioner(MinISRPolicyLower, mock)
+	restarted.SetStateFile(path)
+	if err := restarted.LoadState(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restarted.Resume(context.Background())
+	if status := restarted.Status(); status.State != StateCompleted || status.CompletedMoves != 2 {
+		t.Errorf("expected the completed status to be restored, got %+v", status)
+	}
+	if adjustments := restarted.Adjustments(); len(adjustments) != 1 || adjustments[0].Original != 3 {
+		t.Errorf("expected the min.insync.replicas adjustment to be restored, got %+v", adjustments)
+	}
+	if len(restarted.AuditLog()) != len(d.AuditLog()) {
+		t.Errorf("expected the audit trail to be restored, got %+v", restarted.AuditLog())
+	}
+}
+
+func TestResumeContinuesDrain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decommission.json")
+	writeState(t, path)
+
+	var (
+		mu        sync.Mutex
+		listCalls int
+		altered   kadm.AlterPartitionAssignmentsReq
+	)
+	d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			return partlyDrained(), nil
+		},
+		ListPartitionReassignmentsFunc: func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			listCalls++
+			if listCalls == 1 {
+				// The batch submitted before the restart is still running
+				return kadm.ListPartitionReassignmentsResponses{
+					"orders": {0: {Topic: "orders", Partition: 0, RemovingReplicas: []int32{2}}},
+				}, nil
+			}
+			return kadm.ListPartitionReassignmentsResponses{}, nil
+		},
+		AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if listCalls < 2 {
+				t.Error("expected the in-flight reassignment to finish before resuming")
+			}
+			altered = req
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	})
+	d.SetStateFile(path)
+
+	if err := d.LoadState(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d.Resume(context.Background())
+	// Partition 0 is down to two replicas, too few to restore min.insync.replicas
+	saved := waitForSaved(t, path, "min-isr-not-restored")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := altered["orders"][0]; ok || len(altered["orders"]) != 1 {
+		t.Errorf("expected only partition 1 to be moved, got %v", altered)
+	}
+	if status := d.Status(); status.PlannedMoves != 2 || status.CompletedMoves != 2 {
+		t.Errorf("expected 2 of 2 moves completed, got %+v", status)
+	}
+	if saved.Status.State != StateCompleted {
+		t.Errorf("expected the completed state to be saved, got %s", saved.Status.State)
+	}
+}
+
+func TestResumeFailsForUnregisteredBroker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decommission.json")
+	writeState(t, path)
+
+	d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			md := partlyDrained()
+			md.Brokers = md.Brokers[:2]
+			return md, nil
+		},
+		AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			t.Error("expected no moves for an unregistered broker")
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	})
+	d.SetStateFile(path)
+
+	if err := d.LoadState(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d.Resume(context.Background())
+	waitForState(t, d, StateFailed)
+	if !strings.Contains(d.Status().Message, "no longer registered") {
+		t.Errorf("unexpected message: %s", d.Status().Message)
+	}
+}
+
+func TestResumeWithoutStateFile(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{})
+	d.SetStateFile(filepath.Join(t.TempDir(), "missing.json"))
+
+	if err := d.LoadState(); err != nil {
+		t.Fatalf("expected a missing state file to be ignored, got %v", err)
+	}
+	if d.Status().State != StateIdle {
+		t.Errorf("expected idle, got %s", d.Status().State)
+	}
+}
+
+func TestLoadStateCorrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, path string)
+	}{
+		{
+			name: "truncated",
+			corrupt: func(t *testing.T, path string) {
+				data, _ := os.ReadFile(path)
+				if err := os.WriteFile(path, data[:len(data)/2], 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "edited",
+			corrupt: func(t *testing.T, path string) {
+				data, _ := os.ReadFile(path)
+				edited := strings.Replace(string(data), `"completedMoves":1`, `"completedMoves":2`, 1)
+				if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "decommission.json")
+			writeState(t, path)
+			tt.corrupt(t, path)
+
+			d := newTestDecommissioner(MinISRPolicyLower, &MockAdminClient{})
+			d.SetStateFile(path)
+			if err := d.LoadState(); !errors.Is(err, ErrCorruptState) {
+				t.Fatalf("expected ErrCorruptState, got %v", err)
+			}
+			if d.Status().State != StateIdle {
+				t.Errorf("expected corrupted state not to be restored, got %s", d.Status().State)
+			}
+
+			if err := d.ResetState(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected the state file to be moved aside, got %v", err)
+			}
+			kept, _ := filepath.Glob(path + ".corrupt-*")
+			if len(kept) != 1 {
+				t.Errorf("expected the corrupted file to be kept for inspection, got %v", kept)
+			}
+			if err := d.LoadState(); err != nil {
+				t.Errorf("expected a fresh start after the reset, got %v", err)
+			}
+		})
+	}
+}
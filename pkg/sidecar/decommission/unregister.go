@@ -0,0 +1,166 @@
+package decommission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// maxListedPartitions caps how many blocking partitions an error names
+const maxListedPartitions = 5
+
+// Unregisterer removes a broker's registration from the KRaft controller quorum
+type Unregisterer func(ctx context.Context, brokerID int32) error
+
+// UnregisterResult is the outcome of unregistering a broker
+type UnregisterResult struct {
+	BrokerID       int32     `json:"brokerId"`
+	UnregisteredAt time.Time `json:"unregisteredAt"`
+}
+
+// SetUnregisterer allows overriding the unregister call for testing
+func (d *Decommissioner) SetUnregisterer(unregister Unregisterer) {
+	d.unregister = unregister
+}
+
+// Unregister removes a permanently removed broker from the cluster. It refuses
+// while the broker is still alive, still holds or is gaining replicas, or is
+// being drained, since an unregistered broker with replicas leaves them
+// permanently offline.
+func (d *Decommissioner) Unregister(ctx context.Context, brokerID int32, actor string) (UnregisterResult, error) {
+	if status := d.Status(); status.State == StateMoving && status.BrokerID == brokerID {
+		return UnregisterResult{}, cplnErrors.Conflictf("broker %d is still being decommissioned", brokerID)
+	}
+
+	if err := d.checkUnregister(ctx, brokerID); err != nil {
+		if cplnErrors.IsDomainError(err) {
+			d.record(AuditRecord{
+				Action:   "unregister-rejected",
+				Actor:    actor,
+				BrokerID: brokerID,
+				Detail:   err.Error(),
+			})
+		}
+		return UnregisterResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	if err := d.unregister(ctx, brokerID); err != nil {
+		d.record(AuditRecord{
+			Action:   "unregister-failed",
+			Actor:    actor,
+			BrokerID: brokerID,
+			Detail:   err.Error(),
+		})
+		return UnregisterResult{}, fmt.Errorf("failed to unregister broker %d: %w", brokerID, err)
+	}
+
+	d.record(AuditRecord{
+		Action:   "broker-unregistered",
+		Actor:    actor,
+		BrokerID: brokerID,
+	})
+	return UnregisterResult{BrokerID: brokerID, UnregisteredAt: d.clock.Now()}, nil
+}
+
+// checkUnregister verifies the broker is gone and nothing references it
+func (d *Decommissioner) checkUnregister(ctx context.Context, brokerID int32) error {
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	// Metadata only lists live brokers, and a live broker would register again
+	if brokerRegistered(md, brokerID) {
+		return cplnErrors.Conflictf("broker %d is still alive; stop it permanently before unregistering", brokerID)
+	}
+
+	var blocking []string
+	for name, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			if containsBroker(p.Replicas, brokerID) {
+				blocking = append(blocking, fmt.Sprintf("%s-%d", name, p.Partition))
+			}
+		}
+	}
+	if len(blocking) > 0 {
+		return cplnErrors.Conflictf("broker %d still holds replicas of %s; decommission it first",
+			brokerID, listPartitions(blocking))
+	}
+
+	reassignments, err := adm.ListPartitionReassignments(ctx, md.Topics.TopicsSet())
+	if err != nil {
+		return fmt.Errorf("failed to list partition reassignments: %w", err)
+	}
+	for name, partitions := range reassignments {
+		for _, r := range partitions {
+			if containsBroker(r.Replicas, brokerID) || containsBroker(r.AddingReplicas, brokerID) || containsBroker(r.RemovingReplicas, brokerID) {
+				blocking = append(blocking, fmt.Sprintf("%s-%d", name, r.Partition))
+			}
+		}
+	}
+	if len(blocking) > 0 {
+		return cplnErrors.Conflictf("broker %d is part of in-progress reassignments of %s",
+			brokerID, listPartitions(blocking))
+	}
+	return nil
+}
+
+// defaultUnregister issues UnregisterBroker, which kadm does not wrap. Clusters
+// still on ZooKeeper reject it as unsupported.
+func (d *Decommissioner) defaultUnregister(ctx context.Context, brokerID int32) error {
+	cl, cleanup, err := kafkaclient.NewClient(d.kafkaConfig)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	req := kmsg.NewPtrUnregisterBrokerRequest()
+	req.BrokerID = brokerID
+	resp, err := req.RequestWith(ctx, cl)
+	if err != nil {
+		return err
+	}
+	if err := kerr.ErrorForCode(resp.ErrorCode); err != nil {
+		if resp.ErrorMessage != nil {
+			return fmt.Errorf("%w: %s", err, *resp.ErrorMessage)
+		}
+		return err
+	}
+	return nil
+}
+
+func containsBroker(replicas []int32, brokerID int32) bool {
+	for _, r := range replicas {
+		if r == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// listPartitions names the first few partitions, sorted, and counts the rest
+func listPartitions(partitions []string) string {
+	sort.Strings(partitions)
+	if len(partitions) <= maxListedPartitions {
+		return strings.Join(partitions, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(partitions[:maxListedPartitions], ", "), len(partitions)-maxListedPartitions)
+}
@@ -0,0 +1,129 @@
+package decommission
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func TestUnregister(t *testing.T) {
+	tests := []struct {
+		name          string
+		brokerID      int32
+		modify        func(md *kadm.Metadata)
+		reassignments kadm.ListPartitionReassignmentsResponses
+		unregisterErr error
+		expectError   string
+		expectAction  string
+	}{
+		{
+			name:         "removed broker",
+			brokerID:     3,
+			expectAction: "broker-unregistered",
+		},
+		{
+			name:         "broker still alive",
+			brokerID:     2,
+			expectError:  "still alive",
+			expectAction: "unregister-rejected",
+		},
+		{
+			name:     "broker still holds replicas",
+			brokerID: 3,
+			modify: func(md *kadm.Metadata) {
+				p := md.Topics["orders"].Partitions[1]
+				p.Replicas = []int32{0, 1, 3}
+				md.Topics["orders"].Partitions[1] = p
+			},
+			expectError:  "orders-1",
+			expectAction: "unregister-rejected",
+		},
+		{
+			name:     "broker is being added by a reassignment",
+			brokerID: 3,
+			reassignments: kadm.ListPartitionReassignmentsResponses{
+				"orders": {0: {Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, AddingReplicas: []int32{3}}},
+			},
+			expectError:  "in-progress reassignments",
+			expectAction: "unregister-rejected",
+		},
+		{
+			name:          "unregister rejected by the cluster",
+			brokerID:      3,
+			unregisterErr: errors.New("UNSUPPORTED_VERSION"),
+			expectError:   "UNSUPPORTED_VERSION",
+			expectAction:  "unregister-failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := clusterMetadata()
+			if tt.modify != nil {
+				tt.modify(&md)
+			}
+			d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{
+				MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+					return md, nil
+				},
+				ListPartitionReassignmentsFunc: func(context.Context, kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+					return tt.reassignments, nil
+				},
+			})
+			var unregistered []int32
+			d.SetUnregisterer(func(_ context.Context, brokerID int32) error {
+				unregistered = append(unregistered, brokerID)
+				return tt.unregisterErr
+			})
+
+			_, err := d.Unregister(context.Background(), tt.brokerID, "alice")
+			if tt.expectError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(unregistered) != 1 || unregistered[0] != tt.brokerID {
+					t.Errorf("expected broker %d to be unregistered, got %v", tt.brokerID, unregistered)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+			}
+			if tt.expectAction == "unregister-rejected" && len(unregistered) != 0 {
+				t.Errorf("expected no unregister call, got %v", unregistered)
+			}
+
+			audit := d.AuditLog()
+			if len(audit) != 1 || audit[0].Action != tt.expectAction || audit[0].Actor != "alice" {
+				t.Errorf("expected a single %s audit record, got %+v", tt.expectAction, audit)
+			}
+		})
+	}
+}
+
+func TestUnregisterHandlerConflict(t *testing.T) {
+	d := newTestDecommissioner(MinISRPolicyReject, &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return clusterMetadata(), nil
+		},
+	})
+	d.SetUnregisterer(func(context.Context, int32) error {
+		t.Error("expected no unregister call for a live broker")
+		return nil
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/decommission/{brokerId}/unregister", d.UnregisterHandler).Methods("POST")
+
+	req := httptest.NewRequest("POST", "/admin/decommission/1/unregister", strings.NewReader(`{"requestedBy": "alice"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BrokerIDMapping derives broker IDs from replica hostnames, for workloads
+// whose broker IDs are not their replica ordinals: several workloads sharing
+// one cluster, or a cluster migrated with its IDs
+type BrokerIDMapping struct {
+	// Offset is added to the replica ordinal
+	Offset int32
+	// Hostnames maps hostnames to broker IDs, replacing ordinal + Offset
+	Hostnames map[string]int32
+}
+
+// BrokerID returns the broker ID of the replica with the given hostname
+func (m BrokerIDMapping) BrokerID(hostname string) (int32, error) {
+	if id, ok := m.Hostnames[hostname]; ok {
+		return id, nil
+	}
+	ordinal, err := ParseBrokerIDFromHostname(hostname)
+	if err != nil {
+		return 0, err
+	}
+	id := int64(ordinal) + int64(m.Offset)
+	if id < 0 || id > math.MaxInt32 {
+		return 0, fmt.Errorf("broker ID %d of %s is out of range", id, hostname)
+	}
+	return int32(id), nil
+}
+
+// ParseBrokerIDMap parses a comma-separated list of hostname=brokerID pairs,
+// e.g. kafka-0=100,kafka-1=101
+func ParseBrokerIDMap(s string) (map[string]int32, error) {
+	mapping := map[string]int32{}
+	ids := map[int32]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		hostname, value, ok := strings.Cut(pair, "=")
+		hostname = strings.TrimSpace(hostname)
+		if !ok || hostname == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected hostname=brokerID", pair)
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid broker ID for %s: %q", hostname, value)
+		}
+		if _, dup := mapping[hostname]; dup {
+			return nil, fmt.Errorf("duplicate hostname %s", hostname)
+		}
+		if other, dup := ids[int32(id)]; dup {
+			return nil, fmt.Errorf("broker ID %d is mapped to both %s and %s", id, other, hostname)
+		}
+		mapping[hostname] = int32(id)
+		ids[int32(id)] = hostname
+	}
+	return mapping, nil
+}
@@ -0,0 +1,63 @@
+package discovery
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBrokerIDMapping(t *testing.T) {
+	mapping := BrokerIDMapping{Offset: 100, Hostnames: map[string]int32{"kafka-legacy-0": 7}}
+	tests := []struct {
+		name        string
+		mapping     BrokerIDMapping
+		hostname    string
+		expected    int32
+		expectError bool
+	}{
+		{name: "ordinal", hostname: "kafka-2", expected: 2},
+		{name: "offset", mapping: mapping, hostname: "kafka-2", expected: 102},
+		{name: "mapped hostname", mapping: mapping, hostname: "kafka-legacy-0", expected: 7},
+		{name: "negative offset", mapping: BrokerIDMapping{Offset: -1}, hostname: "kafka-1", expected: 0},
+		{name: "negative result", mapping: BrokerIDMapping{Offset: -1}, hostname: "kafka-0", expectError: true},
+		{name: "overflow", mapping: BrokerIDMapping{Offset: math.MaxInt32}, hostname: "kafka-1", expectError: true},
+		{name: "invalid hostname", mapping: mapping, hostname: "kafka", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.mapping.BrokerID(tt.hostname)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %d", result)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("expected %d, got %d, %v", tt.expected, result, err)
+			}
+		})
+	}
+}
+
+func TestParseBrokerIDMap(t *testing.T) {
+	mapping, err := ParseBrokerIDMap(" kafka-0=100, kafka-1 = 101 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mapping) != 2 || mapping["kafka-0"] != 100 || mapping["kafka-1"] != 101 {
+		t.Errorf("expected two entries, got %v", mapping)
+	}
+
+	for _, invalid := range []string{
+		"kafka-0",
+		"=100",
+		"kafka-0=abc",
+		"kafka-0=-1",
+		"kafka-0=100,kafka-0=101",
+		"kafka-0=100,kafka-1=100",
+	} {
+		if _, err := ParseBrokerIDMap(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
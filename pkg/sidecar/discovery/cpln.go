@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// DefaultAPIEndpoint is the public Control Plane API
+const DefaultAPIEndpoint = "https://api.cpln.io"
+
+// APIOptions configures requests to the Control Plane API
+type APIOptions struct {
+	// Endpoint is the API, e.g. the CPLN_ENDPOINT injected into workloads.
+	// Empty uses DefaultAPIEndpoint.
+	Endpoint string
+	// Token is the workload's identity token (CPLN_TOKEN)
+	Token string
+}
+
+// workloadScale is the part of a Control Plane workload that sets its
+// replica count
+type workloadScale struct {
+	Spec struct {
+		DefaultOptions scaleOptions `json:"defaultOptions"`
+		LocalOptions   []struct {
+			Location string `json:"location"`
+			scaleOptions
+		} `json:"localOptions"`
+	} `json:"spec"`
+}
+
+type scaleOptions struct {
+	Autoscaling *struct {
+		MinScale *int `json:"minScale"`
+	} `json:"autoscaling"`
+}
+
+// minScale returns the minimum scale, if set
+func (o scaleOptions) minScale() (int, bool) {
+	if o.Autoscaling == nil || o.Autoscaling.MinScale == nil {
+		return 0, false
+	}
+	return *o.Autoscaling.MinScale, true
+}
+
+// DiscoverReplicaCount returns the replica count of a workload from the
+// Control Plane API: the minimum scale of its options for the location, or of
+// its default options. Those replicas always exist, so every hostname built
+// from them resolves.
+//
+// workloadLink is the link injected as CPLN_WORKLOAD, e.g.
+// /org/gitops/gvc/kafka/workload/kafka. location may be empty.
+func DiscoverReplicaCount(ctx context.Context, client *http.Client, opts APIOptions, workloadLink, location string) (int, error) {
+	if opts.Token == "" {
+		return 0, errors.New("a Control Plane token is required")
+	}
+	if _, err := ParseWorkloadNameFromLink(workloadLink); err != nil {
+		return 0, err
+	}
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultAPIEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/"+strings.TrimPrefix(workloadLink, "/"), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", opts.Token)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GET %s: %s", req.URL.Redacted(), resp.Status)
+	}
+
+	var workload workloadScale
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&workload); err != nil {
+		return 0, fmt.Errorf("failed to decode workload %s: %w", workloadLink, err)
+	}
+	for _, local := range workload.Spec.LocalOptions {
+		// Local options name their location by link
+		if location == "" || path.Base(local.Location) != location {
+			continue
+		}
+		if count, ok := local.minScale(); ok {
+			return validReplicaCount(workloadLink, count)
+		}
+	}
+	count, ok := workload.Spec.DefaultOptions.minScale()
+	if !ok {
+		return 0, fmt.Errorf("workload %s has no minimum scale", workloadLink)
+	}
+	return validReplicaCount(workloadLink, count)
+}
+
+// validReplicaCount rejects a workload scaled to zero, which has no brokers to
+// bootstrap from
+func validReplicaCount(workloadLink string, count int) (int, error) {
+	if count <= 0 {
+		return 0, fmt.Errorf("workload %s is scaled to %d replicas", workloadLink, count)
+	}
+	return count, nil
+}
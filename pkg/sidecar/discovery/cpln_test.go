@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverReplicaCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/org/test/gvc/kafka/workload/kafka":
+			_, _ = w.Write([]byte(`{"spec":{
+				"defaultOptions":{"autoscaling":{"minScale":3,"maxScale":3}},
+				"localOptions":[{"location":"/org/test/location/aws-eu-central-1","autoscaling":{"minScale":5,"maxScale":5}}]
+			}}`))
+		case "/org/test/gvc/kafka/workload/empty":
+			_, _ = w.Write([]byte(`{"spec":{"defaultOptions":{"autoscaling":{"minScale":0}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	opts := APIOptions{Endpoint: server.URL, Token: "token"}
+	tests := []struct {
+		name        string
+		opts        APIOptions
+		link        string
+		location    string
+		expected    int
+		expectError bool
+	}{
+		{name: "default options", opts: opts, link: "/org/test/gvc/kafka/workload/kafka", expected: 3},
+		{name: "other location", opts: opts, link: "/org/test/gvc/kafka/workload/kafka", location: "aws-us-west-2", expected: 3},
+		{name: "local options", opts: opts, link: "/org/test/gvc/kafka/workload/kafka", location: "aws-eu-central-1", expected: 5},
+		{name: "scaled to zero", opts: opts, link: "/org/test/gvc/kafka/workload/empty", expectError: true},
+		{name: "missing workload", opts: opts, link: "/org/test/gvc/kafka/workload/missing", expectError: true},
+		{name: "invalid link", opts: opts, link: "/org/test/gvc/kafka", expectError: true},
+		{name: "no token", opts: APIOptions{Endpoint: server.URL}, link: "/org/test/gvc/kafka/workload/kafka", expectError: true},
+		{name: "wrong token", opts: APIOptions{Endpoint: server.URL, Token: "other"}, link: "/org/test/gvc/kafka/workload/kafka", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DiscoverReplicaCount(context.Background(), server.Client(), tt.opts, tt.link, tt.location)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %d", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
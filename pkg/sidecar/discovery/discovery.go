@@ -3,21 +3,21 @@ package discovery
 import (
 	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 )
 
-// DiscoverBrokerID extracts the replica index from the hostname.
+// DiscoverBrokerID derives the broker ID from the hostname with the mapping,
+// by default the replica index.
 // Hostname format: ${workloadName}-${replicaIndex}
 // Example: "kafka-2" -> brokerID = 2
-func DiscoverBrokerID() (int32, error) {
-	hostname := os.Getenv("HOSTNAME")
+func (e Env) DiscoverBrokerID(mapping BrokerIDMapping) (int32, error) {
+	hostname := e.Get("HOSTNAME")
 	if hostname == "" {
 		return 0, errors.New("HOSTNAME environment variable not set")
 	}
 
-	return ParseBrokerIDFromHostname(hostname)
+	return mapping.BrokerID(hostname)
 }
 
 // ParseBrokerIDFromHostname extracts the replica index from a hostname string.
@@ -41,39 +41,103 @@ func ParseBrokerIDFromHostname(hostname string) (int32, error) {
 	return int32(index), nil
 }
 
+// DefaultDNSSuffix is the cluster domain of the headless Service records
+const DefaultDNSSuffix = "svc.cluster.local"
+
+// Naming is how replicas are named in DNS. The zero value is the StatefulSet's
+// headless Service naming: ${workloadName}-${i} in svc.cluster.local.
+type Naming struct {
+	// ReplicaPrefix precedes the replica index in a replica's hostname, e.g.
+	// replica- for replica-0. Defaults to the workload name and a hyphen.
+	ReplicaPrefix string
+	// DNSSuffix is the DNS zone that follows the namespace. Defaults to
+	// DefaultDNSSuffix.
+	DNSSuffix string
+}
+
+// PodName returns the hostname of the replica with the given index, the first
+// label of its DNS name
+func (n Naming) PodName(workloadName string, index int) string {
+	prefix := n.ReplicaPrefix
+	if prefix == "" {
+		prefix = workloadName + "-"
+	}
+	return prefix + strconv.Itoa(index)
+}
+
+// Hostname returns the DNS name of the replica with the given index
+func (n Naming) Hostname(workloadName, gvcAlias string, index int) string {
+	suffix := n.DNSSuffix
+	if suffix == "" {
+		suffix = DefaultDNSSuffix
+	}
+	return fmt.Sprintf("%s.%s.%s.%s", n.PodName(workloadName, index), workloadName, gvcAlias, suffix)
+}
+
 // BuildBootstrapServers creates per-pod hostnames using the Kubernetes headless
-// Service that backs the StatefulSet. The orchestrator only ever talks to the
-// brokers it lives next to (same cluster, same GVC), so we always use the
-// in-cluster headless DNS path — never the cpln.local mesh path. The headless
-// Service is published with publishNotReadyAddresses=true (required for KRaft
-// peer discovery on cold start), which means DNS resolves the pod IPs even
-// before any replica is Ready and the orchestrator can break the readiness
+// Service that backs the StatefulSet. The brokers the orchestrator lives next to
+// (same cluster, same GVC) are always reached through the in-cluster headless
+// DNS path; only the replicas of other locations of a stretch cluster go through
+// the cpln.local mesh path, see Topology.Hostname. The headless Service is
+// published with publishNotReadyAddresses=true (required for KRaft peer
+// discovery on cold start), which means DNS resolves the pod IPs even before
+// any replica is Ready and the orchestrator can break the readiness
 // chicken-and-egg.
 //
-// Format: ${workloadName}-${i}.${workloadName}.${gvcAlias}.svc.cluster.local:${port}
+// Format: ${replicaPrefix}${i}.${workloadName}.${gvcAlias}.${dnsSuffix}:${port},
+// by default ${workloadName}-${i}.${workloadName}.${gvcAlias}.svc.cluster.local:${port}
 //
 // gvcAlias here is the Control Plane GVC's parent identifier (the value
 // injected as $CPLN_GVC_ALIAS, which is the Kubernetes namespace), not the GVC
 // name.
-func BuildBootstrapServers(workloadName, gvcAlias string, replicaCount int, port int) string {
-	if replicaCount <= 0 {
-		replicaCount = 1
+func BuildBootstrapServers(workloadName, gvcAlias string, replicaCount int, port int, naming Naming) string {
+	topology := Topology{WorkloadName: workloadName, GvcAlias: gvcAlias, Port: port, Naming: naming}
+	return strings.Join(topology.BootstrapServers(map[string]int{"": replicaCount}), ",")
+}
+
+// ParseDNSSuffix normalizes a DNS zone, dropping leading and trailing dots
+func ParseDNSSuffix(suffix string) (string, error) {
+	suffix = strings.Trim(strings.TrimSpace(suffix), ".")
+	if suffix == "" {
+		return "", errors.New("empty DNS suffix")
+	}
+	for _, label := range strings.Split(suffix, ".") {
+		if !isDNSLabel(label) {
+			return "", fmt.Errorf("invalid DNS label %q in %s", label, suffix)
+		}
 	}
+	return suffix, nil
+}
 
-	servers := make([]string, replicaCount)
-	for i := 0; i < replicaCount; i++ {
-		servers[i] = fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local:%d",
-			workloadName, i, workloadName, gvcAlias, port)
+// ParseReplicaPrefix checks that a replica prefix followed by an index is a
+// valid hostname
+func ParseReplicaPrefix(prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix != "" && !isDNSLabel(prefix+"0") {
+		return "", fmt.Errorf("%q followed by a replica index is not a valid hostname", prefix)
 	}
+	return prefix, nil
+}
 
-	return strings.Join(servers, ",")
+// isDNSLabel reports whether s is a valid DNS label: up to 63 lowercase
+// letters, digits and hyphens, not starting or ending with a hyphen
+func isDNSLabel(s string) bool {
+	if s == "" || len(s) > 63 || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
 }
 
 // DiscoverWorkloadName extracts the workload name from CPLN_WORKLOAD env var.
 // CPLN_WORKLOAD format: /org/{org}/gvc/{gvc}/workload/{workloadName}
 // Example: "/org/gitops/gvc/igor-kafka/workload/kafka-fix-cluster" -> "kafka-fix-cluster"
-func DiscoverWorkloadName() (string, error) {
-	cplnWorkload := os.Getenv("CPLN_WORKLOAD")
+func (e Env) DiscoverWorkloadName() (string, error) {
+	cplnWorkload := e.Get("CPLN_WORKLOAD")
 	if cplnWorkload == "" {
 		return "", errors.New("CPLN_WORKLOAD environment variable not set")
 	}
@@ -108,10 +172,37 @@ func ParseWorkloadNameFromLink(link string) (string, error) {
 // DiscoverGvcAlias returns the GVC alias from CPLN_GVC_ALIAS env var.
 // This is the Kubernetes namespace name (Control Plane's GVC parent identifier),
 // used as the in-cluster DNS namespace for headless Service per-pod records.
-func DiscoverGvcAlias() (string, error) {
-	gvcAlias := os.Getenv("CPLN_GVC_ALIAS")
+func (e Env) DiscoverGvcAlias() (string, error) {
+	gvcAlias := e.Get("CPLN_GVC_ALIAS")
 	if gvcAlias == "" {
 		return "", errors.New("CPLN_GVC_ALIAS environment variable not set")
 	}
 	return gvcAlias, nil
 }
+
+// DiscoverGvcName returns the GVC's name from the CPLN_GVC env var, which may be
+// a bare name or a GVC link
+func (e Env) DiscoverGvcName() (string, error) {
+	gvc := e.Get("CPLN_GVC")
+	if gvc == "" {
+		return "", errors.New("CPLN_GVC environment variable not set")
+	}
+	if idx := strings.LastIndex(gvc, "/gvc/"); idx != -1 {
+		gvc = gvc[idx+len("/gvc/"):]
+	}
+	return strings.Trim(gvc, "/"), nil
+}
+
+// DiscoverLocation returns the Control Plane location the replica runs in from
+// the CPLN_LOCATION env var, which may be a bare name or a location link.
+// Example: "/org/gitops/location/aws-us-west-2" -> "aws-us-west-2"
+func (e Env) DiscoverLocation() (string, error) {
+	location := e.Get("CPLN_LOCATION")
+	if location == "" {
+		return "", errors.New("CPLN_LOCATION environment variable not set")
+	}
+	if idx := strings.LastIndex(location, "/location/"); idx != -1 {
+		location = location[idx+len("/location/"):]
+	}
+	return strings.Trim(location, "/"), nil
+}
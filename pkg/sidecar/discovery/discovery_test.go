@@ -104,7 +104,7 @@ func TestDiscoverBrokerID(t *testing.T) {
 			}
 		}()
 
-		_, err = DiscoverBrokerID()
+		_, err = DiscoverBrokerID(BrokerIDMapping{})
 		if err == nil {
 			t.Errorf("expected error when HOSTNAME is not set")
 		}
@@ -134,7 +134,7 @@ func TestDiscoverBrokerID(t *testing.T) {
 			}
 		}()
 
-		result, err := DiscoverBrokerID()
+		result, err := DiscoverBrokerID(BrokerIDMapping{})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 			return
@@ -152,6 +152,7 @@ func TestBuildBootstrapServers(t *testing.T) {
 		gvcAlias     string
 		replicaCount int
 		port         int
+		naming       Naming
 		expected     string
 	}{
 		{
@@ -194,11 +195,20 @@ func TestBuildBootstrapServers(t *testing.T) {
 			port:         9092,
 			expected:     "kafka-0.kafka.abc123.svc.cluster.local:9092",
 		},
+		{
+			name:         "custom replica prefix and DNS suffix",
+			workloadName: "kafka",
+			gvcAlias:     "abc123",
+			replicaCount: 2,
+			port:         9092,
+			naming:       Naming{ReplicaPrefix: "replica-", DNSSuffix: "svc.corp.internal"},
+			expected:     "replica-0.kafka.abc123.svc.corp.internal:9092,replica-1.kafka.abc123.svc.corp.internal:9092",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := BuildBootstrapServers(tt.workloadName, tt.gvcAlias, tt.replicaCount, tt.port)
+			result := BuildBootstrapServers(tt.workloadName, tt.gvcAlias, tt.replicaCount, tt.port, tt.naming)
 			if result != tt.expected {
 				t.Errorf("expected %q, got %q", tt.expected, result)
 			}
@@ -206,6 +216,48 @@ func TestBuildBootstrapServers(t *testing.T) {
 	}
 }
 
+func TestParseDNSSuffix(t *testing.T) {
+	tests := []struct {
+		suffix      string
+		expected    string
+		expectError bool
+	}{
+		{suffix: "svc.cluster.local", expected: "svc.cluster.local"},
+		{suffix: ".svc.corp.internal.", expected: "svc.corp.internal"},
+		{suffix: "", expectError: true},
+		{suffix: "svc..local", expectError: true},
+		{suffix: "svc.Cluster.local", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			result, err := ParseDNSSuffix(tt.suffix)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("expected %q, got %q, %v", tt.expected, result, err)
+			}
+		})
+	}
+}
+
+func TestParseReplicaPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "replica-", "broker"} {
+		if _, err := ParseReplicaPrefix(prefix); err != nil {
+			t.Errorf("%q: unexpected error: %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"-", "kafka.replica-", "Replica-"} {
+		if _, err := ParseReplicaPrefix(prefix); err == nil {
+			t.Errorf("%q: expected an error", prefix)
+		}
+	}
+}
+
 func TestParseWorkloadNameFromLink(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -375,3 +427,30 @@ func TestDiscoverGvcAlias(t *testing.T) {
 		}
 	})
 }
+
+func TestDiscoverLocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "not set", value: "", expectError: true},
+		{name: "bare name", value: "aws-us-west-2", expected: "aws-us-west-2"},
+		{name: "link", value: "/org/gitops/location/gcp-us-east1", expected: "gcp-us-east1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CPLN_LOCATION", tt.value)
+
+			result, err := DiscoverLocation()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error=%v, got %v", tt.expectError, err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
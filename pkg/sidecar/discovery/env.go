@@ -0,0 +1,56 @@
+package discovery
+
+import "os"
+
+// Env looks up an environment variable, as os.LookupEnv does. Discovery and
+// the sidecar configuration read the environment through an Env, so that they
+// can be given another source than the process environment, e.g. in tests or
+// when embedded in another program.
+type Env func(key string) (string, bool)
+
+// OSEnv is the process environment
+var OSEnv Env = os.LookupEnv
+
+// MapEnv returns an Env holding only the given variables
+func MapEnv(values map[string]string) Env {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// Get returns the value of the variable, or "" when it is unset
+func (e Env) Get(key string) string {
+	value, _ := e(key)
+	return value
+}
+
+// DiscoverBrokerID is OSEnv.DiscoverBrokerID
+func DiscoverBrokerID(mapping BrokerIDMapping) (int32, error) {
+	return OSEnv.DiscoverBrokerID(mapping)
+}
+
+// DiscoverWorkloadName is OSEnv.DiscoverWorkloadName
+func DiscoverWorkloadName() (string, error) {
+	return OSEnv.DiscoverWorkloadName()
+}
+
+// DiscoverGvcAlias is OSEnv.DiscoverGvcAlias
+func DiscoverGvcAlias() (string, error) {
+	return OSEnv.DiscoverGvcAlias()
+}
+
+// DiscoverGvcName is OSEnv.DiscoverGvcName
+func DiscoverGvcName() (string, error) {
+	return OSEnv.DiscoverGvcName()
+}
+
+// DiscoverLocation is OSEnv.DiscoverLocation
+func DiscoverLocation() (string, error) {
+	return OSEnv.DiscoverLocation()
+}
+
+// DiscoverRack is OSEnv.DiscoverRack
+func DiscoverRack(zoneFile string) (Rack, error) {
+	return OSEnv.DiscoverRack(zoneFile)
+}
@@ -0,0 +1,25 @@
+package discovery
+
+import "testing"
+
+func TestMapEnv(t *testing.T) {
+	t.Setenv("HOSTNAME", "kafka-5")
+	env := MapEnv(map[string]string{
+		"HOSTNAME":      "kafka-2",
+		"CPLN_LOCATION": "/org/gitops/location/aws-us-west-2",
+	})
+
+	// Only the map is read, not the process environment
+	if id, err := env.DiscoverBrokerID(BrokerIDMapping{}); err != nil || id != 2 {
+		t.Errorf("expected broker ID 2, got %d, %v", id, err)
+	}
+	if rack, err := env.DiscoverRack(""); err != nil || rack.String() != "aws-us-west-2" {
+		t.Errorf("expected rack aws-us-west-2, got %q, %v", rack, err)
+	}
+	if _, err := env.DiscoverWorkloadName(); err == nil {
+		t.Error("expected an error for a variable missing from the map")
+	}
+	if env.Get("CPLN_GVC") != "" {
+		t.Errorf("expected an unset variable to be empty, got %q", env.Get("CPLN_GVC"))
+	}
+}
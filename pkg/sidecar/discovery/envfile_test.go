@@ -0,0 +1,29 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kafka.env")
+	if err := WriteEnvFile(path, map[string]string{
+		VotersEnvVar: "0@kafka-0:9093",
+		RackEnvVar:   "aws-us-west-2/us-west-2a",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "KAFKA_BROKER_RACK=aws-us-west-2/us-west-2a\nKAFKA_CONTROLLER_QUORUM_VOTERS=0@kafka-0:9093\n"
+	if string(content) != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("expected a world-readable file, got %v, %v", info, err)
+	}
+}
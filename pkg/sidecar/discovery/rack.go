@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ZoneLabels are the labels carrying the availability zone, in order of preference
+var ZoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+// RackEnvVar is the variable of the env file (WriteEnvFile) that holds broker.rack
+const RackEnvVar = "KAFKA_BROKER_RACK"
+
+// Rack identifies the failure domain of a broker, its broker.rack
+type Rack struct {
+	Location string `json:"location"`
+	Zone     string `json:"zone,omitempty"`
+}
+
+// String returns the broker.rack value: the location, followed by the zone when known.
+// Example: aws-us-west-2/us-west-2a
+func (r Rack) String() string {
+	if r.Zone == "" {
+		return r.Location
+	}
+	return r.Location + "/" + r.Zone
+}
+
+// DiscoverRack returns the rack of the replica from its location (CPLN_LOCATION)
+// and, when zoneFile is set, the zone in that downward API file. The file may hold
+// the zone alone or the pod's labels, in the downward API's key="value" format.
+func (e Env) DiscoverRack(zoneFile string) (Rack, error) {
+	location, err := e.DiscoverLocation()
+	if err != nil {
+		return Rack{}, err
+	}
+	rack := Rack{Location: location}
+	if zoneFile == "" {
+		return rack, nil
+	}
+
+	content, err := os.ReadFile(zoneFile)
+	if err != nil {
+		return Rack{}, fmt.Errorf("failed to read zone file: %w", err)
+	}
+	if rack.Zone, err = ParseZone(string(content)); err != nil {
+		return Rack{}, fmt.Errorf("invalid zone file %s: %w", zoneFile, err)
+	}
+	return rack, nil
+}
+
+// ParseZone returns the zone in the content of a downward API file: a bare zone,
+// or labels with one of ZoneLabels
+func ParseZone(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errors.New("empty")
+	}
+	if !strings.Contains(content, "=") {
+		return content, nil
+	}
+
+	labels := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		labels[key] = value
+	}
+	for _, label := range ZoneLabels {
+		if zone := labels[label]; zone != "" {
+			return zone, nil
+		}
+	}
+	return "", fmt.Errorf("no %s label", ZoneLabels[0])
+}
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseZone(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    string
+		expectError bool
+	}{
+		{name: "bare zone", content: "us-west-2a\n", expected: "us-west-2a"},
+		{name: "labels", content: "app=\"kafka\"\ntopology.kubernetes.io/zone=\"us-west-2b\"\n", expected: "us-west-2b"},
+		{name: "legacy label", content: "failure-domain.beta.kubernetes.io/zone=\"us-west-2c\"", expected: "us-west-2c"},
+		{name: "labels without zone", content: "app=\"kafka\"", expectError: true},
+		{name: "empty", content: " \n", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseZone(tt.content)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("expected %q, got %q, %v", tt.expected, result, err)
+			}
+		})
+	}
+}
+
+func TestDiscoverRack(t *testing.T) {
+	t.Setenv("CPLN_LOCATION", "/org/gitops/location/aws-us-west-2")
+
+	rack, err := DiscoverRack("")
+	if err != nil || rack.String() != "aws-us-west-2" {
+		t.Errorf("expected the location alone, got %q, %v", rack, err)
+	}
+
+	zoneFile := filepath.Join(t.TempDir(), "labels")
+	if err := os.WriteFile(zoneFile, []byte("topology.kubernetes.io/zone=\"us-west-2a\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rack, err = DiscoverRack(zoneFile)
+	if err != nil || rack.String() != "aws-us-west-2/us-west-2a" {
+		t.Errorf("expected the location and zone, got %q, %v", rack, err)
+	}
+
+	if _, err := DiscoverRack(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing zone file")
+	}
+
+	t.Setenv("CPLN_LOCATION", "")
+	if _, err := DiscoverRack(""); err == nil {
+		t.Error("expected an error without CPLN_LOCATION")
+	}
+}
@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MeshDNSSuffix is the zone of the Control Plane mesh records, which reach
+// replicas in other locations
+const MeshDNSSuffix = "cpln.local"
+
+// MeshReplicaPrefix precedes the replica index in the mesh records when the
+// naming has no ReplicaPrefix
+const MeshReplicaPrefix = "replica-"
+
+// Topology is where the replicas of a cluster run: one location, or every
+// location of a stretch cluster
+type Topology struct {
+	WorkloadName string
+	GvcAlias     string
+	Port         int
+	Naming       Naming
+	// Local is the location of this replica, if known
+	Local string
+	// Locations lists every location of a stretch cluster, Local included.
+	// Empty for a single-location cluster.
+	Locations []string
+	// GvcName is the GVC's name (not its alias), which the mesh records of
+	// remote replicas are under
+	GvcName string
+}
+
+// Sites returns the locations replicas are counted in: Locations, or only
+// Local for a single-location cluster
+func (t Topology) Sites() []string {
+	if len(t.Locations) == 0 {
+		return []string{t.Local}
+	}
+	return t.Locations
+}
+
+// Hostname returns the DNS name of a replica. Replicas of this location are
+// reached through the headless Service, like BuildBootstrapServers; replicas of
+// other locations through the Control Plane mesh, as
+// ${replicaPrefix}${i}.${workloadName}.${location}.${gvcName}.cpln.local, by
+// default replica-${i}.${workloadName}.${location}.${gvcName}.cpln.local.
+func (t Topology) Hostname(location string, index int) string {
+	if location == t.Local {
+		return t.Naming.Hostname(t.WorkloadName, t.GvcAlias, index)
+	}
+	prefix := t.Naming.ReplicaPrefix
+	if prefix == "" {
+		prefix = MeshReplicaPrefix
+	}
+	return fmt.Sprintf("%s%d.%s.%s.%s.%s", prefix, index, t.WorkloadName, location, t.GvcName, MeshDNSSuffix)
+}
+
+// BootstrapServers lists the replicas of every location, given their count
+// in each. A missing or non-positive count counts as one replica.
+func (t Topology) BootstrapServers(counts map[string]int) []string {
+	var servers []string
+	for _, location := range t.Sites() {
+		count := counts[location]
+		if count <= 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			servers = append(servers, t.Hostname(location, i)+":"+strconv.Itoa(t.Port))
+		}
+	}
+	return servers
+}
+
+// Counts returns the replica count of every location in servers built by
+// BootstrapServers
+func (t Topology) Counts(servers []string) map[string]int {
+	listed := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		listed[server] = true
+	}
+	counts := map[string]int{}
+	for _, location := range t.Sites() {
+		for i := 0; listed[t.Hostname(location, i)+":"+strconv.Itoa(t.Port)]; i++ {
+			counts[location] = i + 1
+		}
+	}
+	return counts
+}
+
+// ParseLocations splits a comma-separated list of the locations of a stretch
+// cluster, which must include the local one
+func ParseLocations(locations, local string) ([]string, error) {
+	var parsed []string
+	seen := map[string]bool{}
+	for _, location := range strings.Split(locations, ",") {
+		location = strings.TrimSpace(location)
+		if location == "" {
+			continue
+		}
+		if !isDNSLabel(location) {
+			return nil, fmt.Errorf("invalid location %q", location)
+		}
+		if seen[location] {
+			return nil, fmt.Errorf("duplicate location %s", location)
+		}
+		seen[location] = true
+		parsed = append(parsed, location)
+	}
+	if len(parsed) == 0 {
+		return nil, nil
+	}
+	if local == "" {
+		return nil, errors.New("the local location is unknown")
+	}
+	if !seen[local] {
+		return nil, fmt.Errorf("the local location %s is not listed", local)
+	}
+	return parsed, nil
+}
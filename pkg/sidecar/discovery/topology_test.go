@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopologyBootstrapServers(t *testing.T) {
+	single := Topology{WorkloadName: "kafka", GvcAlias: "abc123", Port: 9092}
+	if got, want := single.BootstrapServers(map[string]int{"": 2}), []string{
+		"kafka-0.kafka.abc123.svc.cluster.local:9092",
+		"kafka-1.kafka.abc123.svc.cluster.local:9092",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	stretch := Topology{
+		WorkloadName: "kafka",
+		GvcAlias:     "abc123",
+		GvcName:      "prod",
+		Port:         9092,
+		Local:        "aws-us-west-2",
+		Locations:    []string{"aws-us-west-2", "aws-us-east-1"},
+	}
+	counts := map[string]int{"aws-us-west-2": 2, "aws-us-east-1": 1}
+	servers := stretch.BootstrapServers(counts)
+	if want := []string{
+		"kafka-0.kafka.abc123.svc.cluster.local:9092",
+		"kafka-1.kafka.abc123.svc.cluster.local:9092",
+		"replica-0.kafka.aws-us-east-1.prod.cpln.local:9092",
+	}; !reflect.DeepEqual(servers, want) {
+		t.Errorf("expected %v, got %v", want, servers)
+	}
+	if got := stretch.Counts(servers); !reflect.DeepEqual(got, counts) {
+		t.Errorf("expected counts %v, got %v", counts, got)
+	}
+
+	stretch.Naming = Naming{ReplicaPrefix: "broker-"}
+	if got, want := stretch.BootstrapServers(counts), []string{
+		"broker-0.kafka.abc123.svc.cluster.local:9092",
+		"broker-1.kafka.abc123.svc.cluster.local:9092",
+		"broker-0.kafka.aws-us-east-1.prod.cpln.local:9092",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParseLocations(t *testing.T) {
+	tests := []struct {
+		name        string
+		locations   string
+		local       string
+		expected    []string
+		expectError bool
+	}{
+		{name: "single location", locations: "", local: "aws-us-west-2"},
+		{name: "stretch", locations: "aws-us-west-2, aws-us-east-1", local: "aws-us-east-1", expected: []string{"aws-us-west-2", "aws-us-east-1"}},
+		{name: "local not listed", locations: "aws-us-west-2,aws-us-east-1", local: "gcp-us-east1", expectError: true},
+		{name: "local unknown", locations: "aws-us-west-2,aws-us-east-1", expectError: true},
+		{name: "duplicate", locations: "aws-us-west-2,aws-us-west-2", local: "aws-us-west-2", expectError: true},
+		{name: "invalid", locations: "aws us-west-2", local: "aws-us-west-2", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseLocations(tt.locations, tt.local)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v, %v", tt.expected, result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultControllerPort is the port KRaft controllers listen on by convention
+const DefaultControllerPort = 9093
+
+// VotersEnvVar is the variable of the env file (WriteEnvFile) that holds
+// controller.quorum.voters
+const VotersEnvVar = "KAFKA_CONTROLLER_QUORUM_VOTERS"
+
+// Voter is a member of the KRaft controller quorum
+type Voter struct {
+	ID       int32  `json:"id"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Location string `json:"location,omitempty"`
+}
+
+// String returns the voter as listed in controller.quorum.voters: id@host:port
+func (v Voter) String() string {
+	return fmt.Sprintf("%d@%s:%d", v.ID, v.Host, v.Port)
+}
+
+// Voters returns every replica of the topology as a quorum voter, given their
+// count in each location, listening for controller traffic on port. A missing
+// or non-positive count counts as one replica. IDs are derived from the
+// replicas' hostnames with mapping, as DiscoverBrokerID derives them, so every
+// voter has the node ID its replica starts with. Replicas of different
+// locations share hostnames, so two voters with the same ID are an error.
+func (t Topology) Voters(counts map[string]int, port int, mapping BrokerIDMapping) ([]Voter, error) {
+	var voters []Voter
+	owners := map[int32]string{}
+	for _, location := range t.Sites() {
+		count := counts[location]
+		if count <= 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			hostname := t.Naming.PodName(t.WorkloadName, i)
+			id, err := mapping.BrokerID(hostname)
+			if err != nil {
+				return nil, err
+			}
+			host := t.Hostname(location, i)
+			if other, dup := owners[id]; dup {
+				return nil, fmt.Errorf("voter ID %d is used by both %s and %s", id, other, host)
+			}
+			owners[id] = host
+			voters = append(voters, Voter{ID: id, Host: host, Port: port, Location: location})
+		}
+	}
+	return voters, nil
+}
+
+// FormatVoters joins voters into a controller.quorum.voters value
+func FormatVoters(voters []Voter) string {
+	entries := make([]string, len(voters))
+	for i, v := range voters {
+		entries[i] = v.String()
+	}
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,38 @@
+package discovery
+
+import "testing"
+
+func TestTopologyVoters(t *testing.T) {
+	single := Topology{WorkloadName: "kafka", GvcAlias: "abc123", Port: 9092}
+	voters, err := single.Voters(map[string]int{"": 3}, 9093, BrokerIDMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0@kafka-0.kafka.abc123.svc.cluster.local:9093," +
+		"1@kafka-1.kafka.abc123.svc.cluster.local:9093," +
+		"2@kafka-2.kafka.abc123.svc.cluster.local:9093"
+	if got := FormatVoters(voters); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// IDs follow the broker ID mapping
+	voters, err = single.Voters(map[string]int{"": 2}, 9093, BrokerIDMapping{Offset: 100, Hostnames: map[string]int32{"kafka-1": 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voters[0].ID != 100 || voters[1].ID != 7 {
+		t.Errorf("expected IDs 100 and 7, got %d and %d", voters[0].ID, voters[1].ID)
+	}
+
+	// The locations of a stretch cluster share hostnames, and so IDs
+	stretch := Topology{
+		WorkloadName: "kafka",
+		GvcAlias:     "abc123",
+		GvcName:      "prod",
+		Local:        "aws-us-west-2",
+		Locations:    []string{"aws-us-west-2", "aws-us-east-1"},
+	}
+	if _, err := stretch.Voters(map[string]int{"aws-us-west-2": 1, "aws-us-east-1": 1}, 9093, BrokerIDMapping{}); err == nil {
+		t.Error("expected an error for voters sharing an ID")
+	}
+}
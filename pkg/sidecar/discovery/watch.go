@@ -0,0 +1,202 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the bootstrap server watcher in the freshness tracker
+const CheckName = "bootstrap"
+
+// maxDNSReplicas bounds how far DNSReplicaCounter looks for replicas
+const maxDNSReplicas = 256
+
+// dnsScanGap is how many consecutive replicas DNSReplicaCounter finds missing
+// before it stops looking, so one replica being recreated does not hide the
+// ones after it
+const dnsScanGap = 3
+
+// shrinkConfirmations is how many consecutive steps a lower replica count must
+// be seen before the bootstrap servers shrink. Replicas briefly disappear from
+// DNS while they restart; a stale entry only costs a failed dial.
+const shrinkConfirmations = 3
+
+// ReplicaCounter returns the current replica count of the workload in a
+// location of the topology
+type ReplicaCounter func(ctx context.Context, location string) (int, error)
+
+// APIReplicaCounter counts the replicas with the workload's scale in the
+// Control Plane API. See DiscoverReplicaCount.
+func APIReplicaCounter(client *http.Client, opts APIOptions, workloadLink string) ReplicaCounter {
+	return func(ctx context.Context, location string) (int, error) {
+		return DiscoverReplicaCount(ctx, client, opts, workloadLink, location)
+	}
+}
+
+// DNSReplicaCounter counts the replicas by resolving their hostnames: the
+// count is one past the highest index that resolves. The headless Service
+// publishes replicas before they are ready, so new replicas are seen as soon
+// as their pods are scheduled.
+func DNSReplicaCounter(lookup func(ctx context.Context, host string) ([]string, error), topology Topology) ReplicaCounter {
+	return func(ctx context.Context, location string) (int, error) {
+		count, missing := 0, 0
+		for i := 0; i < maxDNSReplicas && missing < dnsScanGap; i++ {
+			if _, err := lookup(ctx, topology.Hostname(location, i)); err != nil {
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				missing++
+				continue
+			}
+			count, missing = i+1, 0
+		}
+		if count == 0 {
+			return 0, fmt.Errorf("no replica resolves, starting with %s", topology.Hostname(location, 0))
+		}
+		return count, nil
+	}
+}
+
+// WatchOptions configures the bootstrap server watcher
+type WatchOptions struct {
+	Topology Topology
+	// Interval is how often the replica counts are discovered again
+	Interval time.Duration
+}
+
+// Watcher discovers the replica count of every location periodically and
+// applies the bootstrap servers again when one changes, so clients of a
+// cluster that was scaled up reach the new brokers
+type Watcher struct {
+	counter ReplicaCounter
+	opts    WatchOptions
+	apply   func(servers []string)
+	logger  *slog.Logger
+	tracker *freshness.Tracker
+	clock   clock.Clock
+
+	mu         sync.Mutex
+	counts     map[string]int
+	lowerSteps map[string]int
+}
+
+// NewWatcher creates a watcher of the bootstrap servers currently in use,
+// which were built by the topology. apply receives the bootstrap servers
+// whenever a count changes.
+func NewWatcher(counter ReplicaCounter, opts WatchOptions, servers []string, apply func(servers []string), logger *slog.Logger) *Watcher {
+	return &Watcher{
+		counter:    counter,
+		opts:       opts,
+		apply:      apply,
+		logger:     logger,
+		clock:      clock.Real,
+		counts:     opts.Topology.Counts(servers),
+		lowerSteps: map[string]int{},
+	}
+}
+
+// SetTracker records every discovery with the freshness tracker
+func (w *Watcher) SetTracker(tracker *freshness.Tracker) {
+	w.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (w *Watcher) SetClock(clk clock.Clock) {
+	w.clock = clk
+}
+
+// Counts returns the replica count of every location the bootstrap servers
+// were last built with
+func (w *Watcher) Counts() map[string]int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return maps.Clone(w.counts)
+}
+
+// Run discovers the replica count every Interval until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := w.clock.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	w.tracker.Register(CheckName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		err := w.Step(ctx)
+		if err != nil {
+			w.logger.Warn("bootstrap: failed to discover the replica count, keeping the bootstrap servers", "error", err)
+		}
+		w.tracker.Record(CheckName, err)
+	}
+}
+
+// Step discovers the replica counts once and applies the bootstrap servers
+// when one grew, or when one has been lower for shrinkConfirmations steps in a
+// row. Nothing is applied when a location cannot be counted.
+func (w *Watcher) Step(ctx context.Context) error {
+	sites := w.opts.Topology.Sites()
+	counts := make(map[string]int, len(sites))
+	for _, location := range sites {
+		count, err := w.counter(ctx, location)
+		if err != nil {
+			if location != "" {
+				return fmt.Errorf("%s: %w", location, err)
+			}
+			return err
+		}
+		counts[location] = count
+	}
+
+	w.mu.Lock()
+	previous := maps.Clone(w.counts)
+	changed := false
+	for location, count := range counts {
+		if w.observe(location, count) {
+			changed = true
+		}
+	}
+	current := maps.Clone(w.counts)
+	w.mu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	servers := w.opts.Topology.BootstrapServers(current)
+	w.logger.Info("bootstrap: replica count changed, updating the bootstrap servers",
+		"previous", previous,
+		"replicaCounts", current,
+		"bootstrapServers", servers)
+	w.apply(servers)
+	return nil
+}
+
+// observe records the discovered count of a location and reports whether the
+// bootstrap servers are to be built with it. The caller holds mu.
+func (w *Watcher) observe(location string, count int) bool {
+	if count >= w.counts[location] {
+		w.lowerSteps[location] = 0
+		if count == w.counts[location] {
+			return false
+		}
+		w.counts[location] = count
+		return true
+	}
+	w.lowerSteps[location]++
+	if w.lowerSteps[location] < shrinkConfirmations {
+		return false
+	}
+	w.counts[location], w.lowerSteps[location] = count, 0
+	return true
+}
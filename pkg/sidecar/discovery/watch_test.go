@@ -0,0 +1,128 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDNSReplicaCounter(t *testing.T) {
+	topology := Topology{WorkloadName: "kafka", GvcAlias: "abc123", Port: 9092}
+	tests := []struct {
+		name        string
+		resolves    []int
+		expected    int
+		expectError bool
+	}{
+		{name: "three replicas", resolves: []int{0, 1, 2}, expected: 3},
+		{name: "replica being recreated", resolves: []int{0, 2, 3, 4}, expected: 5},
+		{name: "beyond the scan gap", resolves: []int{0, 5}, expected: 1},
+		{name: "no replicas", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts := map[string]bool{}
+			for _, i := range tt.resolves {
+				hosts[topology.Hostname("", i)] = true
+			}
+			lookup := func(_ context.Context, host string) ([]string, error) {
+				if !hosts[host] {
+					return nil, errors.New("no such host")
+				}
+				return []string{"10.0.0.1"}, nil
+			}
+
+			count, err := DNSReplicaCounter(lookup, topology)(context.Background(), "")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %d", count)
+				}
+				return
+			}
+			if err != nil || count != tt.expected {
+				t.Errorf("expected %d, got %d, %v", tt.expected, count, err)
+			}
+		})
+	}
+}
+
+func TestWatcherStep(t *testing.T) {
+	topology := Topology{WorkloadName: "kafka", GvcAlias: "abc123", Port: 9092}
+	count := 3
+	var applied [][]string
+	w := NewWatcher(func(context.Context, string) (int, error) {
+		return count, nil
+	}, WatchOptions{Topology: topology}, topology.BootstrapServers(map[string]int{"": 3}), func(servers []string) {
+		applied = append(applied, servers)
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	if err := w.Step(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("expected no change, got %v, %v", applied, err)
+	}
+
+	// Scaling up applies straight away
+	count = 5
+	if err := w.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 || len(applied[0]) != 5 || !strings.HasPrefix(applied[0][4], "kafka-4.kafka.abc123.") {
+		t.Fatalf("expected five bootstrap servers, got %v", applied)
+	}
+
+	// A replica briefly missing does not shrink the list
+	count = 4
+	for i := 0; i < shrinkConfirmations-1; i++ {
+		_ = w.Step(ctx)
+	}
+	count = 5
+	_ = w.Step(ctx)
+	if len(applied) != 1 || w.Counts()[""] != 5 {
+		t.Fatalf("expected no shrink, got %v", applied)
+	}
+
+	// Scaling down applies once confirmed
+	count = 3
+	for i := 0; i < shrinkConfirmations; i++ {
+		_ = w.Step(ctx)
+	}
+	if len(applied) != 2 || len(applied[1]) != 3 || w.Counts()[""] != 3 {
+		t.Fatalf("expected three bootstrap servers, got %v", applied)
+	}
+
+	// A failed discovery keeps the bootstrap servers
+	w.counter = func(context.Context, string) (int, error) { return 0, errors.New("unavailable") }
+	if err := w.Step(ctx); err == nil || len(applied) != 2 {
+		t.Errorf("expected an error and no change, got %v, %v", applied, err)
+	}
+}
+
+func TestWatcherStepStretch(t *testing.T) {
+	topology := Topology{
+		WorkloadName: "kafka",
+		GvcAlias:     "abc123",
+		GvcName:      "prod",
+		Port:         9092,
+		Local:        "aws-us-west-2",
+		Locations:    []string{"aws-us-west-2", "aws-us-east-1"},
+	}
+	counts := map[string]int{"aws-us-west-2": 3, "aws-us-east-1": 3}
+	var applied [][]string
+	w := NewWatcher(func(_ context.Context, location string) (int, error) {
+		return counts[location], nil
+	}, WatchOptions{Topology: topology}, topology.BootstrapServers(counts), func(servers []string) {
+		applied = append(applied, servers)
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	counts["aws-us-east-1"] = 4
+	if err := w.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 || len(applied[0]) != 7 || applied[0][6] != "replica-3.kafka.aws-us-east-1.prod.cpln.local:9092" {
+		t.Fatalf("expected the remote replica to be added, got %v", applied)
+	}
+}
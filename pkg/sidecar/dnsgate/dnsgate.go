@@ -0,0 +1,191 @@
+// Package dnsgate holds the broker back until the hostnames of every bootstrap
+// server resolve. Replica-direct DNS records appear some time after a replica
+// is scheduled, and a broker that starts before they do fails to reach its
+// peers and restarts in a loop.
+package dnsgate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// Resolver returns the addresses of a host
+type Resolver func(ctx context.Context, host string) ([]string, error)
+
+// Options configures the gate
+type Options struct {
+	// Interval is how often unresolved hosts are retried
+	Interval time.Duration
+	// Timeout bounds each lookup
+	Timeout time.Duration
+}
+
+// HostResult is the outcome of resolving one host
+type HostResult struct {
+	Host      string    `json:"host"`
+	Addresses []string  `json:"addresses,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the outcome of the last round of lookups
+type Report struct {
+	// Open reports whether every host has resolved in a round, which keeps the
+	// gate open for good
+	Open  bool         `json:"open"`
+	Hosts []HostResult `json:"hosts"`
+}
+
+// Gate resolves the hosts of the bootstrap servers until all of them resolve
+type Gate struct {
+	opts    Options
+	logger  *slog.Logger
+	resolve Resolver
+	clock   clock.Clock
+
+	mu      sync.RWMutex
+	servers []string
+	hosts   []HostResult
+	open    bool
+}
+
+// NewGate creates a gate for the hosts of servers, host:port pairs
+func NewGate(servers []string, opts Options, logger *slog.Logger) *Gate {
+	return &Gate{
+		opts:    opts,
+		logger:  logger,
+		resolve: net.DefaultResolver.LookupHost,
+		clock:   clock.Real,
+		servers: servers,
+	}
+}
+
+// SetResolver allows overriding the resolver for testing
+func (g *Gate) SetResolver(resolve Resolver) {
+	g.resolve = resolve
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (g *Gate) SetClock(clk clock.Clock) {
+	g.clock = clk
+}
+
+// SetServers replaces the bootstrap servers, e.g. after the cluster was scaled.
+// An open gate stays open.
+func (g *Gate) SetServers(servers []string) {
+	g.mu.Lock()
+	g.servers = servers
+	g.mu.Unlock()
+}
+
+// Open reports whether every host has resolved
+func (g *Gate) Open() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.open
+}
+
+// Report returns the outcome of the last round of lookups
+func (g *Gate) Report() Report {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return Report{Open: g.open, Hosts: slices.Clone(g.hosts)}
+}
+
+// Hosts returns the distinct hosts of servers, in order
+func Hosts(servers []string) ([]string, error) {
+	var hosts []string
+	for _, server := range servers {
+		host, _, err := net.SplitHostPort(server)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bootstrap server %q: %w", server, err)
+		}
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// Step resolves every host at once, opening the gate when all of them
+// resolve, and returns the lookups that failed
+func (g *Gate) Step(ctx context.Context) error {
+	g.mu.RLock()
+	servers := g.servers
+	g.mu.RUnlock()
+	hosts, err := Hosts(servers)
+	if err != nil {
+		return err
+	}
+
+	results := make([]HostResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, g.opts.Timeout)
+			defer cancel()
+			addrs, err := g.resolve(ctx, host)
+			results[i] = HostResult{Host: host, CheckedAt: g.clock.Now()}
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			slices.Sort(addrs)
+			results[i].Addresses = addrs
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", result.Host, result.Error))
+		}
+	}
+
+	g.mu.Lock()
+	g.hosts = results
+	opened := !g.open && len(errs) == 0
+	g.open = g.open || len(errs) == 0
+	g.mu.Unlock()
+	if opened {
+		g.logger.Info("dns: every bootstrap host resolves", "hosts", hosts)
+	}
+	return errors.Join(errs...)
+}
+
+// Wait resolves the hosts every Interval until all of them resolve, returning
+// the failed lookups of the last round when the context ends first
+func (g *Gate) Wait(ctx context.Context) error {
+	ticker := g.clock.NewTicker(g.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := g.Step(ctx)
+		if err == nil {
+			return nil
+		}
+		g.logger.Info("dns: waiting for bootstrap hosts to resolve", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("bootstrap hosts do not resolve: %w", err)
+		case <-ticker.C():
+		}
+	}
+}
+
+// Run waits for the hosts to resolve in the background of the sidecar
+func (g *Gate) Run(ctx context.Context) {
+	_ = g.Wait(ctx)
+}
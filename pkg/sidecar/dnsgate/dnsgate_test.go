@@ -0,0 +1,127 @@
+package dnsgate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// fakeResolver resolves the hosts it knows
+type fakeResolver struct {
+	mu    sync.Mutex
+	known map[string][]string
+}
+
+func (f *fakeResolver) add(host string, addrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.known[host] = addrs
+}
+
+func (f *fakeResolver) resolve(_ context.Context, host string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	addrs, ok := f.known[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return append([]string(nil), addrs...), nil
+}
+
+func TestHosts(t *testing.T) {
+	hosts, err := Hosts([]string{"kafka-0.kafka:9092", "kafka-1.kafka:9092", "kafka-0.kafka:9094", "[::1]:9092"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 3 || hosts[0] != "kafka-0.kafka" || hosts[1] != "kafka-1.kafka" || hosts[2] != "::1" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	if _, err := Hosts([]string{"kafka-0.kafka"}); err == nil {
+		t.Error("expected an error for a server without a port")
+	}
+}
+
+func TestGateStep(t *testing.T) {
+	resolver := &fakeResolver{known: map[string][]string{"kafka-0.kafka": {"10.0.0.2", "10.0.0.1"}}}
+	gate := NewGate([]string{"kafka-0.kafka:9092", "kafka-1.kafka:9092"}, Options{Interval: time.Millisecond, Timeout: time.Second}, testLogger())
+	gate.SetResolver(resolver.resolve)
+
+	if err := gate.Step(context.Background()); err == nil {
+		t.Fatal("expected an error while kafka-1 does not resolve")
+	}
+	report := gate.Report()
+	if report.Open || len(report.Hosts) != 2 {
+		t.Fatalf("expected a closed gate with two hosts, got %+v", report)
+	}
+	if report.Hosts[0].Addresses[0] != "10.0.0.1" || report.Hosts[0].Error != "" {
+		t.Errorf("expected kafka-0 resolved with sorted addresses, got %+v", report.Hosts[0])
+	}
+	if report.Hosts[1].Error == "" {
+		t.Errorf("expected kafka-1 failed, got %+v", report.Hosts[1])
+	}
+
+	resolver.add("kafka-1.kafka", "10.0.0.3")
+	if err := gate.Step(context.Background()); err != nil {
+		t.Fatalf("expected every host resolved, got %v", err)
+	}
+	if !gate.Open() {
+		t.Error("expected the gate open")
+	}
+
+	// Once open, the gate stays open
+	gate.SetServers([]string{"kafka-2.kafka:9092"})
+	if err := gate.Step(context.Background()); err == nil {
+		t.Error("expected an error for kafka-2")
+	}
+	if !gate.Open() {
+		t.Error("expected the gate to stay open")
+	}
+}
+
+func TestGateWait(t *testing.T) {
+	resolver := &fakeResolver{known: map[string][]string{}}
+	gate := NewGate([]string{"kafka-0.kafka:9092"}, Options{Interval: time.Millisecond, Timeout: time.Second}, testLogger())
+	gate.SetResolver(resolver.resolve)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := gate.Wait(ctx); err == nil {
+		t.Fatal("expected an error when the hosts never resolve")
+	}
+
+	clk := clock.NewFake(time.Now())
+	gate.SetClock(clk)
+	done := make(chan error, 1)
+	go func() {
+		done <- gate.Wait(context.Background())
+	}()
+	for clk.Tickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected the wait to go on while the host does not resolve, got %v", err)
+	default:
+	}
+
+	resolver.add("kafka-0.kafka", "10.0.0.1")
+	clk.Advance(time.Millisecond)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected the wait to end once the host resolves, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the wait to end once the host resolves")
+	}
+}
@@ -0,0 +1,339 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the drift comparator in the freshness tracker
+const CheckName = "config-drift"
+
+const (
+	ResourceTopic  = "topic"
+	ResourceBroker = "broker"
+)
+
+// AdminClient defines the Kafka admin operations needed to compare configs.
+// This enables mocking in tests.
+type AdminClient interface {
+	ListBrokers(ctx context.Context) (kadm.BrokerDetails, error)
+	DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeBrokerConfigs(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the drift comparator
+type Options struct {
+	// Path is the mounted desired config spec
+	Path string
+	// CheckInterval is how often actual configs are compared with the spec
+	CheckInterval time.Duration
+	// Timeout bounds each describe request
+	Timeout time.Duration
+}
+
+// Drift is a single config whose actual value differs from the spec
+type Drift struct {
+	ResourceType string  `json:"resourceType"`
+	Resource     string  `json:"resource"`
+	Config       string  `json:"config"`
+	Desired      string  `json:"desired"`
+	Actual       *string `json:"actual,omitempty"`
+	// Source is where the actual value comes from (e.g. DYNAMIC_TOPIC_CONFIG, DEFAULT_CONFIG)
+	Source string `json:"source,omitempty"`
+	// Reason explains drift that has no actual value
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report is the outcome of the last comparison
+type Report struct {
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	Drifted   int        `json:"drifted"`
+	Drift     []Drift    `json:"drift"`
+	// Error is set when the last comparison failed; Drift is then from the last
+	// successful comparison
+	Error string `json:"error,omitempty"`
+}
+
+// Detector periodically compares actual topic and broker configs with a desired spec
+type Detector struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	report Report
+}
+
+// NewDetector creates a new config drift detector
+func NewDetector(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Detector {
+	d := &Detector{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		report:      Report{Drift: []Drift{}},
+	}
+	// Set default client factory
+	d.clientFactory = d.defaultClientFactory
+	return d
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (d *Detector) SetClientFactory(factory ClientFactory) {
+	d.clientFactory = factory
+}
+
+// SetTracker records every comparison with the freshness tracker
+func (d *Detector) SetTracker(tracker *freshness.Tracker) {
+	d.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (d *Detector) SetClock(clk clock.Clock) {
+	d.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (d *Detector) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(d.kafkaConfig)
+}
+
+// Report returns a snapshot of the last comparison
+func (d *Detector) Report() Report {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.report
+}
+
+// Run compares configs every CheckInterval until the context is cancelled
+func (d *Detector) Run(ctx context.Context) {
+	ticker := d.clock.NewTicker(d.opts.CheckInterval)
+	defer ticker.Stop()
+	d.tracker.Register(CheckName)
+
+	for {
+		d.tracker.Record(CheckName, d.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step re-reads the spec and compares it with the cluster's actual configs
+func (d *Detector) Step(ctx context.Context) error {
+	spec, err := LoadSpec(d.opts.Path)
+	if err != nil {
+		d.fail(err)
+		return err
+	}
+
+	adm, cleanup, err := d.clientFactory()
+	if err != nil {
+		d.fail(err)
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	topicDrift, err := d.compareTopics(ctx, adm, spec)
+	if err != nil {
+		d.fail(err)
+		return err
+	}
+	brokerDrift, err := d.compareBrokers(ctx, adm, spec)
+	if err != nil {
+		d.fail(err)
+		return err
+	}
+	drift := append(topicDrift, brokerDrift...)
+	sortDrift(drift)
+
+	previous := d.Report().Drifted
+	if len(drift) != previous {
+		d.logger.Warn("config drift changed", "drifted", len(drift), "previous", previous)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	d.report = Report{CheckedAt: &now, Drifted: len(drift), Drift: drift}
+	return nil
+}
+
+// compareTopics compares the desired topic configs with the actual ones
+func (d *Detector) compareTopics(ctx context.Context, adm AdminClient, spec Spec) ([]Drift, error) {
+	drift := []Drift{}
+	if len(spec.Topics) == 0 {
+		return drift, nil
+	}
+
+	topics := make([]string, 0, len(spec.Topics))
+	for topic := range spec.Topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	configs, err := adm.DescribeTopicConfigs(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+
+	described := make(map[string]kadm.ResourceConfig, len(configs))
+	for _, rc := range configs {
+		described[rc.Name] = rc
+	}
+	for _, topic := range topics {
+		rc, ok := described[topic]
+		if !ok || errors.Is(rc.Err, kerr.UnknownTopicOrPartition) {
+			drift = append(drift, missing(ResourceTopic, topic, spec.Topics[topic], "topic not found")...)
+			continue
+		}
+		if rc.Err != nil {
+			return nil, fmt.Errorf("failed to describe config of topic %s: %w", topic, rc.Err)
+		}
+		drift = append(drift, compare(ResourceTopic, topic, spec.Topics[topic], rc)...)
+	}
+	return drift, nil
+}
+
+// compareBrokers compares the desired broker configs with the actual ones
+func (d *Detector) compareBrokers(ctx context.Context, adm AdminClient, spec Spec) ([]Drift, error) {
+	drift := []Drift{}
+	if len(spec.Brokers) == 0 {
+		return drift, nil
+	}
+
+	brokers, err := adm.ListBrokers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brokers: %w", err)
+	}
+	registered := make(map[int32]bool, len(brokers))
+	for _, b := range brokers {
+		registered[b.NodeID] = true
+	}
+
+	var ids []int32
+	for key := range spec.Brokers {
+		if key == AllBrokers {
+			continue
+		}
+		// Validated in ParseSpec
+		id, _ := strconv.ParseInt(key, 10, 32)
+		if !registered[int32(id)] {
+			drift = append(drift, missing(ResourceBroker, key, spec.brokerConfigs(int32(id)), "broker not registered")...)
+		}
+	}
+	for id := range registered {
+		if _, ok := spec.Brokers[AllBrokers]; ok {
+			ids = append(ids, id)
+		} else if _, ok := spec.Brokers[strconv.Itoa(int(id))]; ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// Broker configs are described one broker at a time: each request must go to
+	// the broker being described
+	for _, id := range ids {
+		configs, err := adm.DescribeBrokerConfigs(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe configs of broker %d: %w", id, err)
+		}
+		for _, rc := range configs {
+			if rc.Err != nil {
+				return nil, fmt.Errorf("failed to describe configs of broker %d: %w", id, rc.Err)
+			}
+			drift = append(drift, compare(ResourceBroker, strconv.Itoa(int(id)), spec.brokerConfigs(id), rc)...)
+		}
+	}
+	return drift, nil
+}
+
+// compare returns the desired configs whose actual value differs. Sensitive
+// configs are never returned by the cluster and cannot be compared.
+func compare(resourceType, resource string, desired map[string]string, rc kadm.ResourceConfig) []Drift {
+	actual := make(map[string]kadm.Config, len(rc.Configs))
+	for _, c := range rc.Configs {
+		actual[c.Key] = c
+	}
+
+	var drift []Drift
+	for key, want := range desired {
+		c, ok := actual[key]
+		switch {
+		case !ok:
+			drift = append(drift, Drift{
+				ResourceType: resourceType, Resource: resource, Config: key, Desired: want,
+				Reason: "config not reported by the cluster",
+			})
+		case c.Sensitive:
+			continue
+		case c.Value == nil || *c.Value != want:
+			drift = append(drift, Drift{
+				ResourceType: resourceType, Resource: resource, Config: key, Desired: want,
+				Actual: c.Value, Source: c.Source.String(),
+			})
+		}
+	}
+	return drift
+}
+
+// missing reports every desired config of a resource that does not exist
+func missing(resourceType, resource string, desired map[string]string, reason string) []Drift {
+	drift := make([]Drift, 0, len(desired))
+	for key, want := range desired {
+		drift = append(drift, Drift{ResourceType: resourceType, Resource: resource, Config: key, Desired: want, Reason: reason})
+	}
+	return drift
+}
+
+// sortDrift orders drift by resource type, resource and config
+func sortDrift(drift []Drift) {
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].ResourceType != drift[j].ResourceType {
+			return drift[i].ResourceType < drift[j].ResourceType
+		}
+		if drift[i].Resource != drift[j].Resource {
+			return drift[i].Resource < drift[j].Resource
+		}
+		return drift[i].Config < drift[j].Config
+	})
+}
+
+func (d *Detector) fail(err error) {
+	d.logger.Warn("config drift: comparison failed", "error", err)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.report.Error = err.Error()
+}
+
+// DriftHandler handles GET /admin/configs/drift requests
+func (d *Detector) DriftHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, d.Report())
+}
@@ -0,0 +1,215 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	ListBrokersFunc           func(ctx context.Context) (kadm.BrokerDetails, error)
+	DescribeTopicConfigsFunc  func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	DescribeBrokerConfigsFunc func(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error)
+}
+
+func (m *MockAdminClient) ListBrokers(ctx context.Context) (kadm.BrokerDetails, error) {
+	if m.ListBrokersFunc != nil {
+		return m.ListBrokersFunc(ctx)
+	}
+	return kadm.BrokerDetails{}, nil
+}
+
+func (m *MockAdminClient) DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+	if m.DescribeTopicConfigsFunc != nil {
+		return m.DescribeTopicConfigsFunc(ctx, topics...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) DescribeBrokerConfigs(ctx context.Context, brokers ...int32) (kadm.ResourceConfigs, error) {
+	if m.DescribeBrokerConfigsFunc != nil {
+		return m.DescribeBrokerConfigsFunc(ctx, brokers...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func config(key, value string, source kmsg.ConfigSource) kadm.Config {
+	return kadm.Config{Key: key, Value: &value, Source: source}
+}
+
+// clusterMock returns brokers 0 and 1 and the orders topic with retention.ms=86400000
+func clusterMock() *MockAdminClient {
+	return &MockAdminClient{
+		ListBrokersFunc: func(context.Context) (kadm.BrokerDetails, error) {
+			return kadm.BrokerDetails{{NodeID: 0}, {NodeID: 1}}, nil
+		},
+		DescribeTopicConfigsFunc: func(_ context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+			var configs kadm.ResourceConfigs
+			for _, topic := range topics {
+				if topic != "orders" {
+					configs = append(configs, kadm.ResourceConfig{Name: topic, Err: kerr.UnknownTopicOrPartition})
+					continue
+				}
+				configs = append(configs, kadm.ResourceConfig{Name: topic, Configs: []kadm.Config{
+					config("retention.ms", "86400000", kmsg.ConfigSourceDynamicTopicConfig),
+					config("cleanup.policy", "delete", kmsg.ConfigSourceDefaultConfig),
+					{Key: "sasl.jaas.config", Sensitive: true},
+				}})
+			}
+			return configs, nil
+		},
+		DescribeBrokerConfigsFunc: func(_ context.Context, brokers ...int32) (kadm.ResourceConfigs, error) {
+			threads := "8"
+			if brokers[0] == 1 {
+				threads = "16"
+			}
+			return kadm.ResourceConfigs{{Configs: []kadm.Config{
+				config("num.io.threads", threads, kmsg.ConfigSourceStaticBrokerConfig),
+			}}}, nil
+		},
+	}
+}
+
+func newTestDetector(t *testing.T, mock *MockAdminClient, spec string) *Detector {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "configs.json")
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatalf("failed to write spec: %v", err)
+	}
+
+	d := NewDetector(kafkaclient.Config{}, Options{Path: path, CheckInterval: time.Millisecond, Timeout: time.Second}, testLogger())
+	d.SetClientFactory(func() (AdminClient, func(), error) {
+		return mock, func() {}, nil
+	})
+	return d
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{
+			name: "no drift",
+			spec: `{"topics": {"orders": {"retention.ms": "86400000"}}, "brokers": {"*": {"num.io.threads": "8"}, "1": {"num.io.threads": "16"}}}`,
+		},
+		{
+			name:     "topic drift",
+			spec:     `{"topics": {"orders": {"retention.ms": "604800000", "cleanup.policy": "delete"}}}`,
+			expected: []string{"topic/orders/retention.ms"},
+		},
+		{
+			name:     "broker drift",
+			spec:     `{"brokers": {"*": {"num.io.threads": "8"}}}`,
+			expected: []string{"broker/1/num.io.threads"},
+		},
+		{
+			name:     "missing topic and broker",
+			spec:     `{"topics": {"payments": {"retention.ms": "1"}}, "brokers": {"5": {"num.io.threads": "8"}}}`,
+			expected: []string{"broker/5/num.io.threads", "topic/payments/retention.ms"},
+		},
+		{
+			name:     "unknown config",
+			spec:     `{"topics": {"orders": {"retention.bytes": "1"}}}`,
+			expected: []string{"topic/orders/retention.bytes"},
+		},
+		{
+			name: "sensitive configs are not compared",
+			spec: `{"topics": {"orders": {"sasl.jaas.config": "secret"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDetector(t, clusterMock(), tt.spec)
+
+			if err := d.Step(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			report := d.Report()
+			if report.CheckedAt == nil || report.Drifted != len(tt.expected) {
+				t.Fatalf("expected %d drifted configs, got %+v", len(tt.expected), report)
+			}
+			for i, drift := range report.Drift {
+				if got := drift.ResourceType + "/" + drift.Resource + "/" + drift.Config; got != tt.expected[i] {
+					t.Errorf("drift %d: expected %s, got %s", i, tt.expected[i], got)
+				}
+				if drift.Actual == nil && drift.Reason == "" {
+					t.Errorf("drift %d: expected an actual value or a reason", i)
+				}
+			}
+		})
+	}
+}
+
+func TestStepKeepsLastReportOnError(t *testing.T) {
+	mock := clusterMock()
+	d := newTestDetector(t, mock, `{"topics": {"orders": {"retention.ms": "1"}}}`)
+	if err := d.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.DescribeTopicConfigsFunc = func(context.Context, ...string) (kadm.ResourceConfigs, error) {
+		return nil, errors.New("broker unavailable")
+	}
+	if err := d.Step(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	report := d.Report()
+	if report.Drifted != 1 || report.Error == "" {
+		t.Errorf("expected last drift kept with an error, got %+v", report)
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectError bool
+	}{
+		{name: "valid", data: `{"topics": {"orders": {"retention.ms": "1"}}, "brokers": {"*": {}, "2": {}}}`},
+		{name: "empty", data: `{}`},
+		{name: "invalid json", data: `{"topics":`, expectError: true},
+		{name: "invalid broker", data: `{"brokers": {"kafka-0": {}}}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpec([]byte(tt.data))
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDriftHandler(t *testing.T) {
+	d := newTestDetector(t, clusterMock(), `{}`)
+
+	w := httptest.NewRecorder()
+	d.DriftHandler(w, httptest.NewRequest(http.MethodGet, "/admin/configs/drift", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// AllBrokers is the broker key in a spec that applies to every broker in the cluster
+const AllBrokers = "*"
+
+// Spec is the desired topic and broker configuration from the mounted file. Only
+// the keys listed are compared; anything else may differ freely.
+type Spec struct {
+	// Topics maps topic names to their desired configs
+	Topics map[string]map[string]string `json:"topics,omitempty"`
+	// Brokers maps broker IDs, or "*" for every broker, to their desired configs.
+	// An entry for a specific broker overrides "*" for the same key.
+	Brokers map[string]map[string]string `json:"brokers,omitempty"`
+}
+
+// LoadSpec reads and validates the desired config spec
+func LoadSpec(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("failed to read config spec: %w", err)
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec parses and validates a desired config spec
+func ParseSpec(data []byte) (Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return Spec{}, fmt.Errorf("invalid config spec: %w", err)
+	}
+
+	for topic := range spec.Topics {
+		if topic == "" {
+			return Spec{}, fmt.Errorf("invalid config spec: empty topic name")
+		}
+	}
+	for broker := range spec.Brokers {
+		if broker == AllBrokers {
+			continue
+		}
+		if id, err := strconv.ParseInt(broker, 10, 32); err != nil || id < 0 {
+			return Spec{}, fmt.Errorf("invalid config spec: broker %q is not a broker ID or %q", broker, AllBrokers)
+		}
+	}
+	return spec, nil
+}
+
+// brokerConfigs returns the desired configs of a single broker, merging "*" with
+// its own entry
+func (s Spec) brokerConfigs(id int32) map[string]string {
+	merged := make(map[string]string)
+	for k, v := range s.Brokers[AllBrokers] {
+		merged[k] = v
+	}
+	for k, v := range s.Brokers[strconv.Itoa(int(id))] {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,232 @@
+package freshness
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// CheckStatus is the freshness of a single check or background loop
+type CheckStatus struct {
+	Name        string     `json:"name"`
+	LastAttempt *time.Time `json:"lastAttempt,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	// Finished is set once a background loop has exited normally; it is no
+	// longer expected to run and is never stale
+	Finished bool `json:"finished,omitempty"`
+	Stale    bool `json:"stale"`
+}
+
+// StatusResponse represents the response for the status endpoint
+type StatusResponse struct {
+	Status     string        `json:"status"`
+	StaleAfter string        `json:"staleAfter"`
+	Stale      []string      `json:"stale,omitempty"`
+	Checks     []CheckStatus `json:"checks"`
+}
+
+type check struct {
+	registeredAt time.Time
+	lastAttempt  time.Time
+	lastSuccess  time.Time
+	lastError    string
+	finished     bool
+	alerted      bool
+}
+
+// Tracker records when each check was last attempted and last succeeded, and
+// flags checks that have not succeeded within the staleness bound. A nil Tracker
+// ignores every call, so components can record unconditionally.
+type Tracker struct {
+	staleAfter time.Duration
+	logger     *slog.Logger
+	clock      clock.Clock
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// NewTracker creates a tracker that reports checks as stale when they have not
+// succeeded for longer than staleAfter
+func NewTracker(staleAfter time.Duration, logger *slog.Logger) *Tracker {
+	return &Tracker{
+		staleAfter: staleAfter,
+		logger:     logger,
+		clock:      clock.Real,
+		checks:     make(map[string]*check),
+	}
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (t *Tracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// Register starts the staleness clock for a background loop, so a loop that
+// never manages a single success still becomes stale
+func (t *Tracker) Register(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(name)
+}
+
+// Record records an attempt of the check, successful when err is nil
+func (t *Tracker) Record(name string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := t.get(name)
+	now := t.clock.Now()
+	c.lastAttempt = now
+	c.finished = false
+	if err != nil {
+		c.lastError = err.Error()
+		return
+	}
+	c.lastSuccess = now
+	c.lastError = ""
+}
+
+// Finish marks a background loop as having exited normally
+func (t *Tracker) Finish(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(name).finished = true
+}
+
+// Statuses returns the freshness of every known check, sorted by name
+func (t *Tracker) Statuses() []CheckStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := t.clock.Now()
+	statuses := make([]CheckStatus, 0, len(t.checks))
+	for name, c := range t.checks {
+		status := CheckStatus{
+			Name:      name,
+			LastError: c.lastError,
+			Finished:  c.finished,
+			Stale:     t.stale(c, now),
+		}
+		if !c.lastAttempt.IsZero() {
+			at := c.lastAttempt
+			status.LastAttempt = &at
+		}
+		if !c.lastSuccess.IsZero() {
+			at := c.lastSuccess
+			status.LastSuccess = &at
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// StaleChecks returns the names of checks that have not succeeded within the bound
+func (t *Tracker) StaleChecks() []string {
+	var stale []string
+	for _, s := range t.Statuses() {
+		if s.Stale {
+			stale = append(stale, s.Name)
+		}
+	}
+	return stale
+}
+
+// Watch evaluates staleness every interval until the context is cancelled,
+// logging an alert when a check goes stale and again when it recovers
+func (t *Tracker) Watch(ctx context.Context, interval time.Duration) {
+	ticker := t.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			t.evaluate()
+		}
+	}
+}
+
+// evaluate logs staleness transitions since the last evaluation
+func (t *Tracker) evaluate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	for name, c := range t.checks {
+		stale := t.stale(c, now)
+		switch {
+		case stale && !c.alerted:
+			t.logger.Error("check is stale: no success within staleness bound",
+				"check", name,
+				"staleAfter", t.staleAfter,
+				"lastSuccess", c.lastSuccess,
+				"lastAttempt", c.lastAttempt,
+				"lastError", c.lastError)
+		case !stale && c.alerted:
+			t.logger.Info("check recovered from staleness", "check", name)
+		}
+		c.alerted = stale
+	}
+}
+
+// stale reports whether the check has gone longer than the bound without a
+// success, counting from registration when it never succeeded
+func (t *Tracker) stale(c *check, now time.Time) bool {
+	if c.finished || t.staleAfter <= 0 {
+		return false
+	}
+	since := c.lastSuccess
+	if since.IsZero() {
+		since = c.registeredAt
+	}
+	return now.Sub(since) > t.staleAfter
+}
+
+// get returns the named check, creating it if needed. Callers must hold mu.
+func (t *Tracker) get(name string) *check {
+	c, ok := t.checks[name]
+	if !ok {
+		c = &check{registeredAt: t.clock.Now()}
+		t.checks[name] = c
+	}
+	return c
+}
+
+// StatusHandler handles GET /status requests
+func (t *Tracker) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	response := StatusResponse{
+		Status:     "ok",
+		StaleAfter: t.staleAfter.String(),
+		Checks:     t.Statuses(),
+	}
+	if stale := t.StaleChecks(); len(stale) > 0 {
+		response.Status = "stale"
+		response.Stale = stale
+	}
+	_, _ = web.ReturnResponse(w, response)
+}
@@ -0,0 +1,152 @@
+package freshness
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// testTracker returns a tracker with a controllable clock
+func testTracker(staleAfter time.Duration, logger *slog.Logger) (*Tracker, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	t := NewTracker(staleAfter, logger)
+	t.SetClock(clk)
+	return t, clk
+}
+
+func TestRecord(t *testing.T) {
+	tracker, clk := testTracker(time.Minute, testLogger())
+
+	tracker.Record("readiness", nil)
+	clk.Advance(30 * time.Second)
+	tracker.Record("readiness", errors.New("broker not registered"))
+
+	statuses := tracker.Statuses()
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 check, got %d", len(statuses))
+	}
+	s := statuses[0]
+	if !s.LastAttempt.Equal(clk.Now()) || !s.LastSuccess.Equal(clk.Now().Add(-30*time.Second)) {
+		t.Errorf("unexpected timestamps: attempt=%v success=%v", s.LastAttempt, s.LastSuccess)
+	}
+	if s.LastError != "broker not registered" || s.Stale {
+		t.Errorf("unexpected status: %+v", s)
+	}
+}
+
+func TestStaleness(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(tracker *Tracker)
+		elapsed     time.Duration
+		expectStale bool
+	}{
+		{
+			name:    "recent success",
+			setup:   func(tracker *Tracker) { tracker.Record("sampler", nil) },
+			elapsed: 30 * time.Second,
+		},
+		{
+			name:        "success too long ago",
+			setup:       func(tracker *Tracker) { tracker.Record("sampler", nil) },
+			elapsed:     2 * time.Minute,
+			expectStale: true,
+		},
+		{
+			name:        "registered but never succeeded",
+			setup:       func(tracker *Tracker) { tracker.Register("sampler") },
+			elapsed:     2 * time.Minute,
+			expectStale: true,
+		},
+		{
+			name: "finished loop",
+			setup: func(tracker *Tracker) {
+				tracker.Record("sampler", nil)
+				tracker.Finish("sampler")
+			},
+			elapsed: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, clk := testTracker(time.Minute, testLogger())
+			tt.setup(tracker)
+			clk.Advance(tt.elapsed)
+
+			stale := tracker.StaleChecks()
+			if (len(stale) > 0) != tt.expectStale {
+				t.Errorf("expected stale=%v, got %v", tt.expectStale, stale)
+			}
+		})
+	}
+}
+
+func TestEvaluateAlertsOnTransitions(t *testing.T) {
+	var buf bytes.Buffer
+	tracker, clk := testTracker(time.Minute, slog.New(slog.NewTextHandler(&buf, nil)))
+
+	tracker.Register("standby")
+	clk.Advance(2 * time.Minute)
+	tracker.evaluate()
+	tracker.evaluate()
+	if n := strings.Count(buf.String(), "check is stale"); n != 1 {
+		t.Errorf("expected a single stale alert, got %d:\n%s", n, buf.String())
+	}
+
+	tracker.Record("standby", nil)
+	tracker.evaluate()
+	if !strings.Contains(buf.String(), "check recovered") {
+		t.Errorf("expected recovery to be logged:\n%s", buf.String())
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	tracker.Register("sampler")
+	tracker.Record("sampler", nil)
+	tracker.Finish("sampler")
+	if tracker.Statuses() != nil {
+		t.Error("expected no statuses from a nil tracker")
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	tracker, clk := testTracker(time.Minute, testLogger())
+	tracker.Record("liveness", nil)
+	tracker.Register("quota-sampler")
+	clk.Advance(2 * time.Minute)
+	tracker.Record("liveness", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	w := httptest.NewRecorder()
+	tracker.StatusHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response StatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if response.Status != "stale" || len(response.Stale) != 1 || response.Stale[0] != "quota-sampler" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if len(response.Checks) != 2 || response.Checks[0].Name != "liveness" {
+		t.Errorf("expected checks sorted by name, got %+v", response.Checks)
+	}
+}
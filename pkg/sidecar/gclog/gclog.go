@@ -0,0 +1,273 @@
+package gclog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the GC log tailer in the freshness tracker
+const CheckName = "gc_log"
+
+// Pause types, from the collector's name for the pause
+const (
+	TypeYoung   = "young"
+	TypeMixed   = "mixed"
+	TypeFull    = "full"
+	TypeRemark  = "remark"
+	TypeCleanup = "cleanup"
+	TypeOther   = "other"
+)
+
+// maxReadBytes bounds how much new log is read in one step, so a log that was
+// written faster than it is polled cannot hold the memory of the sidecar
+const maxReadBytes = 4 << 20
+
+// pauseLine matches a stop-the-world pause of the JDK 9+ unified GC log
+// (-Xlog:gc), as written by Kafka's default KAFKA_GC_LOG_OPTS, e.g.
+//
+//	[2024-05-01T12:00:00.123+0000][gc] GC(42) Pause Young (Normal) (G1 Evacuation Pause) 512M->128M(1024M) 12.345ms
+var pauseLine = regexp.MustCompile(`GC\(\d+\) (Pause [A-Za-z]+(?: \((?:[^()]|\([^()]*\))*\))*) (\d+)([BKMG])->(\d+)([BKMG])\((\d+)([BKMG])\) (\d+(?:\.\d+)?)ms`)
+
+// Pause is a stop-the-world pause parsed from the GC log
+type Pause struct {
+	// Type is young, mixed, full, remark, cleanup or other
+	Type     string
+	Duration time.Duration
+	// HeapBefore and HeapAfter are the heap occupancy around the pause
+	HeapBefore uint64
+	HeapAfter  uint64
+	// HeapCommitted is the committed heap size after the pause
+	HeapCommitted uint64
+}
+
+// ParseLine parses a pause from a GC log line, and reports false for any
+// other line
+func ParseLine(line string) (Pause, bool) {
+	m := pauseLine.FindStringSubmatch(line)
+	if m == nil {
+		return Pause{}, false
+	}
+	ms, err := strconv.ParseFloat(m[8], 64)
+	if err != nil {
+		return Pause{}, false
+	}
+	return Pause{
+		Type:          pauseType(m[1]),
+		Duration:      time.Duration(math.Round(ms * float64(time.Millisecond))),
+		HeapBefore:    size(m[2], m[3]),
+		HeapAfter:     size(m[4], m[5]),
+		HeapCommitted: size(m[6], m[7]),
+	}, true
+}
+
+// pauseType classifies the pause description, e.g. "Pause Young (Mixed) (G1 Evacuation Pause)"
+func pauseType(description string) string {
+	switch {
+	case strings.HasPrefix(description, "Pause Full"):
+		return TypeFull
+	case strings.HasPrefix(description, "Pause Young (Mixed)"):
+		return TypeMixed
+	case strings.HasPrefix(description, "Pause Young"):
+		return TypeYoung
+	case strings.HasPrefix(description, "Pause Remark"):
+		return TypeRemark
+	case strings.HasPrefix(description, "Pause Cleanup"):
+		return TypeCleanup
+	default:
+		return TypeOther
+	}
+}
+
+// size converts a unified logging size to bytes
+func size(value, unit string) uint64 {
+	n, _ := strconv.ParseUint(value, 10, 64)
+	switch unit {
+	case "K":
+		return n << 10
+	case "M":
+		return n << 20
+	case "G":
+		return n << 30
+	default:
+		return n
+	}
+}
+
+// Observer receives every pause parsed from the log
+type Observer interface {
+	ObservePause(p Pause)
+}
+
+// Options configures the GC log tailer
+type Options struct {
+	// Path is the broker's GC log, mounted into the sidecar
+	Path string
+	// Interval is how often the log is read for new lines
+	Interval time.Duration
+}
+
+// Tailer follows the broker's GC log and reports its pauses. Long pauses are
+// the leading cause of a broker dropping out of the ISR, and the log is the
+// only place the JVM reports each one.
+type Tailer struct {
+	opts     Options
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+	observer Observer
+	clock    clock.Clock
+
+	// Only Step uses the open file, so it needs no lock
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	started bool
+}
+
+// NewTailer creates a new GC log tailer
+func NewTailer(opts Options, logger *slog.Logger) *Tailer {
+	return &Tailer{
+		opts:   opts,
+		logger: logger,
+		clock:  clock.Real,
+	}
+}
+
+// SetTracker records every read with the freshness tracker
+func (t *Tailer) SetTracker(tracker *freshness.Tracker) {
+	t.tracker = tracker
+}
+
+// SetObserver reports every pause to the observer
+func (t *Tailer) SetObserver(observer Observer) {
+	t.observer = observer
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (t *Tailer) SetClock(clk clock.Clock) {
+	t.clock = clk
+}
+
+// Run reads the log every Interval until the context is cancelled
+func (t *Tailer) Run(ctx context.Context) {
+	ticker := t.clock.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+	defer t.Close()
+	t.tracker.Register(CheckName)
+
+	for {
+		t.tracker.Record(CheckName, t.Step())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step reads the lines appended since the last step. The log present when the
+// tailer starts is skipped, so a sidecar restart does not report old pauses again.
+// When the JVM rotates the log, the rest of the old file is read before the
+// new one, which is read from its start.
+func (t *Tailer) Step() error {
+	info, err := os.Stat(t.opts.Path)
+	if err != nil {
+		// A log created after the tailer started holds no old pauses
+		t.started = true
+		return fmt.Errorf("failed to stat GC log: %w", err)
+	}
+
+	if t.file != nil && !os.SameFile(t.info, info) {
+		// Rotated: finish the old file, which the open descriptor still reads
+		if err := t.read(); err != nil {
+			t.logger.Warn("gclog: failed to read rotated GC log", "error", err)
+		}
+		t.Close()
+	}
+	if t.file == nil {
+		if err := t.open(info); err != nil {
+			return err
+		}
+	} else if info.Size() < t.offset {
+		// Truncated in place (copytruncate)
+		t.offset = 0
+		t.partial = nil
+	}
+	t.info = info
+
+	return t.read()
+}
+
+// open opens the log, at its end the first time and at its start after a rotation
+func (t *Tailer) open(info os.FileInfo) error {
+	file, err := os.Open(t.opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open GC log: %w", err)
+	}
+	t.file = file
+	t.info = info
+	t.offset = 0
+	t.partial = nil
+	if !t.started {
+		t.offset = info.Size()
+		t.started = true
+	}
+	return nil
+}
+
+// read parses the complete lines written since the last read, keeping an
+// unfinished last line for the next one
+func (t *Tailer) read() error {
+	data, err := io.ReadAll(io.NewSectionReader(t.file, t.offset, maxReadBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read GC log: %w", err)
+	}
+	t.offset += int64(len(data))
+
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		// A line this long is not a GC event; drop it rather than grow
+		if len(data) > maxReadBytes {
+			data = nil
+		}
+		t.partial = data
+		return nil
+	}
+	t.partial = append([]byte(nil), data[end+1:]...)
+
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		pause, ok := ParseLine(line)
+		if !ok {
+			continue
+		}
+		if t.observer != nil {
+			t.observer.ObservePause(pause)
+		}
+		if pause.Type == TypeFull {
+			t.logger.Warn("gclog: full GC pause", "duration", pause.Duration, "heapAfter", pause.HeapAfter, "heapCommitted", pause.HeapCommitted)
+		}
+	}
+	return nil
+}
+
+// Close closes the open log file
+func (t *Tailer) Close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
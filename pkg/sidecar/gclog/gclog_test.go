@@ -0,0 +1,176 @@
+package gclog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recorder is an Observer that keeps every pause
+type recorder struct {
+	pauses []Pause
+}
+
+func (r *recorder) ObservePause(p Pause) {
+	r.pauses = append(r.pauses, p)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		expect Pause
+		ok     bool
+	}{
+		{
+			name: "young pause",
+			line: "[2024-05-01T12:00:00.123+0000][gc] GC(42) Pause Young (Normal) (G1 Evacuation Pause) 512M->128M(1024M) 12.345ms",
+			expect: Pause{
+				Type:          TypeYoung,
+				Duration:      12345 * time.Microsecond,
+				HeapBefore:    512 << 20,
+				HeapAfter:     128 << 20,
+				HeapCommitted: 1024 << 20,
+			},
+			ok: true,
+		},
+		{
+			name:   "mixed pause",
+			line:   "[gc] GC(43) Pause Young (Mixed) (G1 Evacuation Pause) 600M->300M(1G) 20.000ms",
+			expect: Pause{Type: TypeMixed, Duration: 20 * time.Millisecond, HeapBefore: 600 << 20, HeapAfter: 300 << 20, HeapCommitted: 1 << 30},
+			ok:     true,
+		},
+		{
+			name:   "full pause",
+			line:   "[gc] GC(44) Pause Full (G1 Compaction Pause) 1000M->400M(1024M) 1500.5ms",
+			expect: Pause{Type: TypeFull, Duration: 1500500 * time.Microsecond, HeapBefore: 1000 << 20, HeapAfter: 400 << 20, HeapCommitted: 1024 << 20},
+			ok:     true,
+		},
+		{
+			name:   "remark pause",
+			line:   "[gc] GC(45) Pause Remark 210M->210M(1024M) 2.500ms",
+			expect: Pause{Type: TypeRemark, Duration: 2500 * time.Microsecond, HeapBefore: 210 << 20, HeapAfter: 210 << 20, HeapCommitted: 1024 << 20},
+			ok:     true,
+		},
+		{
+			name: "concurrent phase",
+			line: "[gc] GC(46) Concurrent Mark Cycle 45.123ms",
+		},
+		{
+			name: "phase detail",
+			line: "[gc,phases] GC(42)   Pre Evacuate Collection Set: 0.1ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pause, ok := ParseLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if pause != tt.expect {
+				t.Errorf("expected %+v, got %+v", tt.expect, pause)
+			}
+		})
+	}
+}
+
+const youngPause = "[gc] GC(1) Pause Young (Normal) (G1 Evacuation Pause) 512M->128M(1024M) 10.000ms\n"
+
+func appendLog(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestTailer(path string) (*Tailer, *recorder) {
+	rec := &recorder{}
+	tailer := NewTailer(Options{Path: path, Interval: time.Second}, testLogger())
+	tailer.SetObserver(rec)
+	return tailer, rec
+}
+
+func TestTailerSkipsExistingLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gc.log")
+	appendLog(t, path, youngPause)
+	tailer, rec := newTestTailer(path)
+	defer tailer.Close()
+
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.pauses) != 0 {
+		t.Fatalf("expected pauses logged before the start to be skipped, got %d", len(rec.pauses))
+	}
+
+	// The second line arrives in two writes
+	appendLog(t, path, "[gc,start] GC(2) Pause Young (Normal) (G1 Evacuation Pause)\n[gc] GC(2) Pause Young (Normal) ")
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.pauses) != 0 {
+		t.Fatalf("expected an unfinished line to wait, got %d pauses", len(rec.pauses))
+	}
+	appendLog(t, path, "(G1 Evacuation Pause) 600M->100M(1024M) 30.000ms\n")
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.pauses) != 1 || rec.pauses[0].Duration != 30*time.Millisecond {
+		t.Errorf("expected the 30ms pause, got %+v", rec.pauses)
+	}
+}
+
+func TestTailerReadsLogCreatedLater(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gc.log")
+	tailer, rec := newTestTailer(path)
+	defer tailer.Close()
+
+	if err := tailer.Step(); err == nil {
+		t.Fatal("expected an error for a missing log")
+	}
+	appendLog(t, path, youngPause)
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.pauses) != 1 {
+		t.Errorf("expected the pause of a new log, got %d", len(rec.pauses))
+	}
+}
+
+func TestTailerFollowsRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gc.log")
+	appendLog(t, path, "")
+	tailer, rec := newTestTailer(path)
+	defer tailer.Close()
+
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A pause written just before the JVM rotates the log, and one after
+	appendLog(t, path, youngPause)
+	if err := os.Rename(path, path+".0"); err != nil {
+		t.Fatal(err)
+	}
+	appendLog(t, path, "[gc] GC(3) Pause Full (System.gc()) 900M->200M(1024M) 800.000ms\n")
+
+	if err := tailer.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.pauses) != 2 || rec.pauses[0].Type != TypeYoung || rec.pauses[1].Type != TypeFull {
+		t.Errorf("expected the young pause from the old file then the full pause, got %+v", rec.pauses)
+	}
+}
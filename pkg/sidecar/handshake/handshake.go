@@ -0,0 +1,253 @@
+package handshake
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the listener handshake loop in the freshness tracker
+const CheckName = "tls_handshake"
+
+// The phases of a probe, each timed separately
+const (
+	// PhaseConnect is the TCP connect
+	PhaseConnect = "connect"
+	// PhaseHandshake is the TLS handshake, including certificate verification
+	PhaseHandshake = "handshake"
+	// PhaseRequest is an ApiVersions round trip over the established connection
+	PhaseRequest = "request"
+)
+
+// clientID identifies the probes in the broker's request logs
+const clientID = "kafka-sidecar-handshake"
+
+// maxResponseBytes bounds the ApiVersions response read from a listener
+const maxResponseBytes = 1 << 20
+
+// Listener is a named broker listener to probe
+type Listener struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// ParseListeners parses a comma-separated list of name=host:port listeners
+func ParseListeners(s string) ([]Listener, error) {
+	var listeners []Listener
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, address, ok := strings.Cut(entry, "=")
+		name, address = strings.TrimSpace(name), strings.TrimSpace(address)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("listener %q must be name=host:port", entry)
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("listener %q: %w", name, err)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("listener %q is listed twice", name)
+		}
+		seen[name] = true
+		listeners = append(listeners, Listener{Name: name, Address: address})
+	}
+	return listeners, nil
+}
+
+// Observer receives the duration of every probed phase, or why it failed
+type Observer interface {
+	ObserveHandshake(listener, phase string, d time.Duration, err error)
+}
+
+// TLSConfigFactory returns the TLS configuration for a probe. Allows injection for testing.
+type TLSConfigFactory func() (*tls.Config, error)
+
+// Options configures the listener handshake prober
+type Options struct {
+	// Interval is how often every listener is probed
+	Interval time.Duration
+	// Timeout bounds each probe
+	Timeout time.Duration
+}
+
+// Prober periodically connects to every configured broker listener and times
+// the TCP connect, TLS handshake and first Kafka request separately, so slow
+// handshakes (entropy starvation, OCSP stapling) are told apart from a slow
+// broker
+type Prober struct {
+	listeners []Listener
+	opts      Options
+	logger    *slog.Logger
+	tlsConfig TLSConfigFactory
+	tracker   *freshness.Tracker
+	observer  Observer
+	clock     clock.Clock
+}
+
+// NewProber creates a new handshake prober for the listeners, dialing them
+// with the sidecar's client TLS configuration
+func NewProber(listeners []Listener, tlsConfig kafkaclient.TLSConfig, opts Options, logger *slog.Logger) *Prober {
+	return &Prober{
+		listeners: listeners,
+		opts:      opts,
+		logger:    logger,
+		// A fresh configuration per probe holds no session tickets, so every
+		// probe times a full handshake rather than a resumption
+		tlsConfig: func() (*tls.Config, error) {
+			return kafkaclient.NewTLSConfig(tlsConfig)
+		},
+		clock: clock.Real,
+	}
+}
+
+// SetTLSConfigFactory allows overriding the TLS configuration for testing
+func (p *Prober) SetTLSConfigFactory(factory TLSConfigFactory) {
+	p.tlsConfig = factory
+}
+
+// SetTracker records every round of probes with the freshness tracker
+func (p *Prober) SetTracker(tracker *freshness.Tracker) {
+	p.tracker = tracker
+}
+
+// SetObserver reports every probed phase to the observer
+func (p *Prober) SetObserver(observer Observer) {
+	p.observer = observer
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (p *Prober) SetClock(clk clock.Clock) {
+	p.clock = clk
+}
+
+// Run probes every Interval until the context is cancelled
+func (p *Prober) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	p.tracker.Register(CheckName)
+
+	for {
+		p.tracker.Record(CheckName, p.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step probes every listener once and returns the failures
+func (p *Prober) Step(ctx context.Context) error {
+	var errs []error
+	for _, l := range p.listeners {
+		if err := p.probe(ctx, l); err != nil {
+			p.logger.Warn("handshake: probe failed", "listener", l.Name, "address", l.Address, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", l.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// probe times the phases of one connection to the listener. A failed phase
+// ends the probe, so later phases are not observed.
+func (p *Prober) probe(ctx context.Context, l Listener) error {
+	cfg, err := p.tlsConfig()
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	host, _, err := net.SplitHostPort(l.Address)
+	if err != nil {
+		return err
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	start := p.clock.Now()
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", l.Address)
+	if p.observe(l, PhaseConnect, start, err); err != nil {
+		return fmt.Errorf("%s: %w", PhaseConnect, err)
+	}
+	defer raw.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(deadline)
+	}
+
+	start = p.clock.Now()
+	conn := tls.Client(raw, cfg)
+	err = conn.HandshakeContext(ctx)
+	if p.observe(l, PhaseHandshake, start, err); err != nil {
+		return fmt.Errorf("%s: %w", PhaseHandshake, err)
+	}
+
+	start = p.clock.Now()
+	err = apiVersions(conn)
+	if p.observe(l, PhaseRequest, start, err); err != nil {
+		return fmt.Errorf("%s: %w", PhaseRequest, err)
+	}
+	return nil
+}
+
+// observe reports a phase that started at start to the observer
+func (p *Prober) observe(l Listener, phase string, start time.Time, err error) {
+	if p.observer != nil {
+		p.observer.ObserveHandshake(l.Name, phase, p.clock.Since(start), err)
+	}
+}
+
+// apiVersions sends a version 0 ApiVersions request and reads the response.
+// Brokers answer it before SASL authentication, so it measures protocol time
+// on every listener without credentials.
+func apiVersions(conn net.Conn) error {
+	req := kmsg.NewPtrApiVersionsRequest()
+	req.SetVersion(0)
+	const correlationID = 1
+	if _, err := conn.Write(kmsg.NewRequestFormatter(kmsg.FormatterClientID(clientID)).AppendRequest(nil, req, correlationID)); err != nil {
+		return err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return err
+	}
+	n := int32(binary.BigEndian.Uint32(size[:]))
+	if n < 4 || n > maxResponseBytes {
+		return fmt.Errorf("invalid response size %d", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return err
+	}
+	// ApiVersions responses always use the version 0 header: the correlation ID alone
+	if got := int32(binary.BigEndian.Uint32(body)); got != correlationID {
+		return fmt.Errorf("unexpected correlation ID %d", got)
+	}
+	resp := kmsg.NewPtrApiVersionsResponse()
+	resp.SetVersion(0)
+	if err := resp.ReadFrom(body[4:]); err != nil {
+		return err
+	}
+	return kerr.ErrorForCode(resp.ErrorCode)
+}
@@ -0,0 +1,209 @@
+package handshake
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// recordingObserver collects the phases it observes
+type recordingObserver struct {
+	phases []string
+	failed []string
+}
+
+func (o *recordingObserver) ObserveHandshake(listener, phase string, _ time.Duration, err error) {
+	o.phases = append(o.phases, listener+"/"+phase)
+	if err != nil {
+		o.failed = append(o.failed, listener+"/"+phase)
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and a pool trusting it
+func selfSigned(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "broker"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// fakeListener serves ApiVersions over TLS, answering with errorCode
+func fakeListener(t *testing.T, cert tls.Certificate, errorCode int16) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveApiVersions(conn, errorCode)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveApiVersions(conn net.Conn, errorCode int16) {
+	defer conn.Close()
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return
+	}
+	req := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return
+	}
+	// Request header: api key, version, then the correlation ID to echo
+	correlationID := req[4:8]
+
+	resp := kmsg.NewPtrApiVersionsResponse()
+	resp.SetVersion(0)
+	resp.ErrorCode = errorCode
+	body := append([]byte{}, correlationID...)
+	body = resp.AppendTo(body)
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	_, _ = conn.Write(append(out, body...))
+}
+
+func newTestProber(listeners []Listener, pool *x509.CertPool) (*Prober, *recordingObserver) {
+	p := NewProber(listeners, kafkaclient.TLSConfig{}, Options{Interval: time.Minute, Timeout: 5 * time.Second}, testLogger())
+	p.SetTLSConfigFactory(func() (*tls.Config, error) {
+		return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+	})
+	observer := &recordingObserver{}
+	p.SetObserver(observer)
+	return p, observer
+}
+
+func TestParseListeners(t *testing.T) {
+	listeners, err := ParseListeners(" internal=broker-0:9093, external = 10.0.0.1:9094 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Listener{{Name: "internal", Address: "broker-0:9093"}, {Name: "external", Address: "10.0.0.1:9094"}}
+	if len(listeners) != len(want) || listeners[0] != want[0] || listeners[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, listeners)
+	}
+
+	for _, invalid := range []string{"broker-0:9093", "=broker-0:9093", "internal=broker-0", "a=h:1,a=h:2"} {
+		if _, err := ParseListeners(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	cert, pool := selfSigned(t)
+	p, observer := newTestProber([]Listener{
+		{Name: "internal", Address: fakeListener(t, cert, 0)},
+		{Name: "external", Address: fakeListener(t, cert, 0)},
+	}, pool)
+
+	if err := p.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "internal/connect internal/handshake internal/request external/connect external/handshake external/request"
+	if got := strings.Join(observer.phases, " "); got != want {
+		t.Errorf("expected phases %q, got %q", want, got)
+	}
+	if len(observer.failed) != 0 {
+		t.Errorf("expected no failures, got %v", observer.failed)
+	}
+}
+
+func TestStepHandshakeFailure(t *testing.T) {
+	cert, _ := selfSigned(t)
+	// The sidecar does not trust the listener's certificate
+	_, otherPool := selfSigned(t)
+	p, observer := newTestProber([]Listener{{Name: "internal", Address: fakeListener(t, cert, 0)}}, otherPool)
+
+	err := p.Step(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "internal: handshake") {
+		t.Fatalf("expected a handshake failure, got %v", err)
+	}
+	if got := strings.Join(observer.failed, " "); got != "internal/handshake" {
+		t.Errorf("expected only the handshake to fail, got %q", got)
+	}
+	if got := strings.Join(observer.phases, " "); got != "internal/connect internal/handshake" {
+		t.Errorf("expected the request not to be probed, got %q", got)
+	}
+}
+
+func TestStepConnectFailure(t *testing.T) {
+	_, pool := selfSigned(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	_ = ln.Close()
+
+	p, observer := newTestProber([]Listener{{Name: "internal", Address: address}}, pool)
+	if err := p.Step(context.Background()); err == nil {
+		t.Fatal("expected a connect failure")
+	}
+	if got := strings.Join(observer.failed, " "); got != "internal/connect" {
+		t.Errorf("expected the connect to fail, got %q", got)
+	}
+}
+
+func TestStepRequestError(t *testing.T) {
+	cert, pool := selfSigned(t)
+	p, observer := newTestProber([]Listener{{Name: "internal", Address: fakeListener(t, cert, kerr.UnsupportedVersion.Code)}}, pool)
+
+	err := p.Step(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "internal: request") {
+		t.Fatalf("expected a request failure, got %v", err)
+	}
+	if got := strings.Join(observer.failed, " "); got != "internal/request" {
+		t.Errorf("expected the request to fail, got %q", got)
+	}
+}
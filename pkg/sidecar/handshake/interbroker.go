@@ -0,0 +1,338 @@
+package handshake
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// InterBrokerCheckName identifies the inter-broker TLS loop in the freshness tracker
+const InterBrokerCheckName = "inter_broker_tls"
+
+// AdminClient defines the Kafka admin operations needed to find the peers.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// InterBrokerOptions configures the inter-broker TLS checks
+type InterBrokerOptions struct {
+	// Interval is how often every peer is checked
+	Interval time.Duration
+	// Timeout bounds each round of checks
+	Timeout time.Duration
+	// Port is the port of the inter-broker listener of every broker; zero
+	// uses the port in the cluster metadata
+	Port int
+}
+
+// Certificate describes one certificate of a served chain
+type Certificate struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+// PeerTLS is the outcome of a handshake with one peer's inter-broker listener
+type PeerTLS struct {
+	BrokerID int32  `json:"brokerId"`
+	Address  string `json:"address"`
+	// Version and CipherSuite were negotiated, e.g. TLS 1.3 and TLS_AES_128_GCM_SHA256
+	Version     string `json:"version,omitempty"`
+	CipherSuite string `json:"cipherSuite,omitempty"`
+	// Chain is the certificate chain the peer served, leaf first
+	Chain []Certificate `json:"chain,omitempty"`
+	// Verified is whether the chain verifies against this sidecar's CA bundles
+	Verified bool `json:"verified"`
+	// Error is why the handshake or the verification failed
+	Error string `json:"error,omitempty"`
+}
+
+// InterBrokerReport is the outcome of the last round of checks
+type InterBrokerReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Peers     []PeerTLS `json:"peers"`
+	// Issuers are the issuers of the top certificate of every served chain
+	Issuers []string `json:"issuers"`
+	// Mixed is true when peers serve chains of different issuers, or some
+	// chains fail to verify while others do, as during a CA rollout
+	Mixed bool `json:"mixed"`
+}
+
+// InterBrokerChecker periodically completes a TLS handshake with the
+// inter-broker listener of every peer and records the negotiated parameters
+// and whether the served chain verifies, so a truststore or certificate that
+// was only rolled out to some brokers shows up before replication breaks
+type InterBrokerChecker struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          InterBrokerOptions
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tlsConfig     TLSConfigFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	report *InterBrokerReport
+}
+
+// NewInterBrokerChecker creates a new inter-broker TLS checker for the local
+// broker, handshaking with the sidecar's client TLS configuration
+func NewInterBrokerChecker(brokerID int32, kafkaConfig kafkaclient.Config, opts InterBrokerOptions, logger *slog.Logger) *InterBrokerChecker {
+	c := &InterBrokerChecker{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		tlsConfig: func() (*tls.Config, error) {
+			return kafkaclient.NewTLSConfig(kafkaConfig.TLS)
+		},
+	}
+	// Set default client factory
+	c.clientFactory = c.defaultClientFactory
+	return c
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (c *InterBrokerChecker) SetClientFactory(factory ClientFactory) {
+	c.clientFactory = factory
+}
+
+// SetTLSConfigFactory allows overriding the TLS configuration for testing
+func (c *InterBrokerChecker) SetTLSConfigFactory(factory TLSConfigFactory) {
+	c.tlsConfig = factory
+}
+
+// SetTracker records every round of checks with the freshness tracker
+func (c *InterBrokerChecker) SetTracker(tracker *freshness.Tracker) {
+	c.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (c *InterBrokerChecker) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *InterBrokerChecker) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(c.kafkaConfig)
+}
+
+// LastReport returns the report of the last round, and false before the first one
+func (c *InterBrokerChecker) LastReport() (InterBrokerReport, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.report == nil {
+		return InterBrokerReport{}, false
+	}
+	return *c.report, true
+}
+
+// Run checks every Interval until the context is cancelled
+func (c *InterBrokerChecker) Run(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	c.tracker.Register(InterBrokerCheckName)
+
+	for {
+		c.tracker.Record(InterBrokerCheckName, c.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step handshakes with every peer in the cluster metadata and returns the
+// peers that failed
+func (c *InterBrokerChecker) Step(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch peers from metadata: %w", err)
+	}
+
+	var peers []PeerTLS
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, b := range md.Brokers {
+		if b.NodeID == c.brokerID {
+			continue
+		}
+		port := int(b.Port)
+		if c.opts.Port != 0 {
+			port = c.opts.Port
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			peer := c.check(ctx, b.NodeID, net.JoinHostPort(b.Host, strconv.Itoa(port)))
+
+			mu.Lock()
+			defer mu.Unlock()
+			peers = append(peers, peer)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(peers, func(i, j int) bool { return peers[i].BrokerID < peers[j].BrokerID })
+
+	report := summarize(peers)
+	report.CheckedAt = c.clock.Now()
+	c.mu.Lock()
+	c.report = &report
+	c.mu.Unlock()
+
+	var errs []error
+	for _, peer := range peers {
+		if peer.Error != "" {
+			errs = append(errs, fmt.Errorf("broker %d: %s", peer.BrokerID, peer.Error))
+		}
+	}
+	if report.Mixed {
+		c.logger.Warn("inter-broker tls: peers serve mixed certificate chains", "issuers", report.Issuers, "errors", errs)
+	}
+	return errors.Join(errs...)
+}
+
+// check completes a handshake with the listener at address and verifies the
+// served chain afterwards, so the negotiated parameters and the chain are
+// reported even when it does not verify
+func (c *InterBrokerChecker) check(ctx context.Context, id int32, address string) PeerTLS {
+	peer := PeerTLS{BrokerID: id, Address: address}
+	cfg, err := c.tlsConfig()
+	if err != nil {
+		peer.Error = fmt.Sprintf("failed to configure TLS: %v", err)
+		return peer
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		peer.Error = err.Error()
+		return peer
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	verify := cfg.VerifyConnection
+	if verify == nil {
+		verify = verifier(cfg.RootCAs)
+	}
+	cfg.InsecureSkipVerify = true //nolint:gosec // verified below
+	cfg.VerifyConnection = nil
+
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		peer.Error = fmt.Sprintf("%s: %v", PhaseConnect, err)
+		return peer
+	}
+	defer raw.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(deadline)
+	}
+	conn := tls.Client(raw, cfg)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		peer.Error = fmt.Sprintf("%s: %v", PhaseHandshake, err)
+		return peer
+	}
+
+	state := conn.ConnectionState()
+	peer.Version = tls.VersionName(state.Version)
+	peer.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	for _, cert := range state.PeerCertificates {
+		peer.Chain = append(peer.Chain, Certificate{
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter,
+		})
+	}
+	if err := verify(state); err != nil {
+		peer.Error = fmt.Sprintf("certificate chain: %v", err)
+		return peer
+	}
+	peer.Verified = true
+	return peer
+}
+
+// verifier verifies the served chain against roots, the system roots when nil
+func verifier(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("no certificate served")
+		}
+		opts := x509.VerifyOptions{
+			DNSName:       cs.ServerName,
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
+
+// summarize collects the issuers of the peers' chains and tells whether the
+// peers disagree
+func summarize(peers []PeerTLS) InterBrokerReport {
+	report := InterBrokerReport{Peers: peers, Issuers: []string{}}
+	verified, unverified := 0, 0
+	for _, peer := range peers {
+		if len(peer.Chain) == 0 {
+			continue
+		}
+		if peer.Verified {
+			verified++
+		} else {
+			unverified++
+		}
+		issuer := peer.Chain[len(peer.Chain)-1].Issuer
+		if !slices.Contains(report.Issuers, issuer) {
+			report.Issuers = append(report.Issuers, issuer)
+		}
+	}
+	sort.Strings(report.Issuers)
+	report.Mixed = len(report.Issuers) > 1 || (verified > 0 && unverified > 0)
+	return report
+}
+
+// ReportHandler handles GET /admin/tls/inter-broker requests
+func (c *InterBrokerChecker) ReportHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := c.LastReport()
+	if !ok {
+		_, _ = web.ReturnResponse(w, InterBrokerReport{Peers: []PeerTLS{}, Issuers: []string{}})
+		return
+	}
+	_, _ = web.ReturnResponse(w, report)
+}
@@ -0,0 +1,142 @@
+package handshake
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+// brokersAt returns metadata listing a broker at every address, with IDs from 1
+func brokersAt(t *testing.T, addresses ...string) *MockAdminClient {
+	t.Helper()
+	var brokers kadm.BrokerDetails
+	for i, address := range addresses {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := strconv.Atoi(port)
+		brokers = append(brokers, kadm.BrokerDetail{NodeID: int32(i + 1), Host: host, Port: int32(p)})
+	}
+	return &MockAdminClient{MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+		return kadm.Metadata{Brokers: brokers}, nil
+	}}
+}
+
+func newTestInterBrokerChecker(adm AdminClient, pool *x509.CertPool) *InterBrokerChecker {
+	c := NewInterBrokerChecker(1, kafkaclient.Config{}, InterBrokerOptions{Interval: time.Minute, Timeout: 5 * time.Second}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	c.SetTLSConfigFactory(func() (*tls.Config, error) {
+		return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+	})
+	return c
+}
+
+func TestInterBrokerStep(t *testing.T) {
+	cert, pool := selfSigned(t)
+	self := fakeListener(t, cert, 0)
+	peer := fakeListener(t, cert, 0)
+	c := newTestInterBrokerChecker(brokersAt(t, self, peer, peer), pool)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.SetClock(clock.NewFake(now))
+
+	if _, ok := c.LastReport(); ok {
+		t.Error("expected no report before the first round")
+	}
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	report, ok := c.LastReport()
+	if !ok {
+		t.Fatal("expected a report")
+	}
+	if !report.CheckedAt.Equal(now) {
+		t.Errorf("expected the report checked at %v, got %v", now, report.CheckedAt)
+	}
+	// The local broker is not checked
+	if len(report.Peers) != 2 || report.Peers[0].BrokerID != 2 || report.Peers[1].BrokerID != 3 {
+		t.Fatalf("expected peers 2 and 3, got %+v", report.Peers)
+	}
+	for _, p := range report.Peers {
+		if !p.Verified || p.Error != "" || p.Version == "" || p.CipherSuite == "" || len(p.Chain) != 1 {
+			t.Errorf("expected a verified handshake, got %+v", p)
+		}
+	}
+	if report.Mixed || len(report.Issuers) != 1 || report.Issuers[0] != "CN=broker" {
+		t.Errorf("expected one issuer, got %v (mixed %v)", report.Issuers, report.Mixed)
+	}
+}
+
+func TestInterBrokerStepMixedTrust(t *testing.T) {
+	trusted, pool := selfSigned(t)
+	untrusted, _ := selfSigned(t)
+	c := newTestInterBrokerChecker(brokersAt(t, "127.0.0.1:1", fakeListener(t, trusted, 0), fakeListener(t, untrusted, 0)), pool)
+
+	err := c.Step(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broker 3") {
+		t.Fatalf("expected broker 3 to fail, got %v", err)
+	}
+	report, _ := c.LastReport()
+	if !report.Peers[0].Verified {
+		t.Errorf("expected broker 2 verified, got %+v", report.Peers[0])
+	}
+	// The handshake completes, so the chain that failed is reported
+	failed := report.Peers[1]
+	if failed.Verified || !strings.HasPrefix(failed.Error, "certificate chain") || failed.Version == "" || len(failed.Chain) != 1 {
+		t.Errorf("expected broker 3 to serve an unverified chain, got %+v", failed)
+	}
+	if !report.Mixed {
+		t.Error("expected mixed chains")
+	}
+}
+
+func TestInterBrokerStepConnectFailure(t *testing.T) {
+	cert, pool := selfSigned(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := ln.Addr().String()
+	_ = ln.Close()
+
+	// The inter-broker port overrides the one in metadata
+	peer := fakeListener(t, cert, 0)
+	_, port, _ := net.SplitHostPort(peer)
+	c := newTestInterBrokerChecker(brokersAt(t, closed, closed), pool)
+	if err := c.Step(context.Background()); err == nil {
+		t.Error("expected an error for an unreachable peer")
+	}
+	report, _ := c.LastReport()
+	if len(report.Peers) != 1 || !strings.HasPrefix(report.Peers[0].Error, PhaseConnect) || report.Mixed {
+		t.Errorf("expected a connect failure, got %+v", report)
+	}
+
+	c.opts.Port, _ = strconv.Atoi(port)
+	if err := c.Step(context.Background()); err != nil {
+		t.Errorf("expected the inter-broker port to be dialed, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// Faults of FAULT_INJECTION
+const (
+	FaultSlowMetadata  = "slow-metadata"
+	FaultBrokerMissing = "broker-missing"
+	FaultURP           = "urp"
+	FaultLogDirError   = "log-dir-error"
+)
+
+// FaultTopic is the topic of the injected under-replicated partitions
+const FaultTopic = "__fault_injection"
+
+// faultLogDir is the log directory reported failed when the broker reports none
+const faultLogDir = "/fault-injection"
+
+// Faults are simulated failures the checker injects into the responses of its
+// admin calls, so probe thresholds and alerting can be validated without
+// breaking a real cluster. For testing only; the zero value injects nothing.
+type Faults struct {
+	// SlowMetadata delays every metadata request, bounded by the check timeout
+	SlowMetadata time.Duration
+	// BrokerMissing drops the broker from the metadata broker list
+	BrokerMissing bool
+	// URP adds this many under-replicated partitions of the broker, in FaultTopic
+	URP int
+	// LogDirError fails every log directory of the broker with a storage error
+	LogDirError bool
+}
+
+// ParseFaults parses a comma-separated list of faults, e.g.
+// "slow-metadata=3s,urp=10,broker-missing,log-dir-error"
+func ParseFaults(spec string) (Faults, error) {
+	var f Faults
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		switch name {
+		case FaultSlowMetadata:
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return Faults{}, fmt.Errorf("%s needs a positive duration, e.g. %s=3s", name, name)
+			}
+			f.SlowMetadata = d
+		case FaultURP:
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return Faults{}, fmt.Errorf("%s needs a positive partition count, e.g. %s=10", name, name)
+			}
+			f.URP = n
+		case FaultBrokerMissing, FaultLogDirError:
+			if hasValue {
+				return Faults{}, fmt.Errorf("%s takes no value", name)
+			}
+			if name == FaultBrokerMissing {
+				f.BrokerMissing = true
+			} else {
+				f.LogDirError = true
+			}
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q (expected %s, %s, %s or %s)",
+				name, FaultSlowMetadata, FaultBrokerMissing, FaultURP, FaultLogDirError)
+		}
+	}
+	return f, nil
+}
+
+// Enabled reports whether any fault is injected
+func (f Faults) Enabled() bool {
+	return f != Faults{}
+}
+
+// String returns the faults in the form ParseFaults reads
+func (f Faults) String() string {
+	var faults []string
+	if f.SlowMetadata > 0 {
+		faults = append(faults, FaultSlowMetadata+"="+f.SlowMetadata.String())
+	}
+	if f.BrokerMissing {
+		faults = append(faults, FaultBrokerMissing)
+	}
+	if f.URP > 0 {
+		faults = append(faults, FaultURP+"="+strconv.Itoa(f.URP))
+	}
+	if f.LogDirError {
+		faults = append(faults, FaultLogDirError)
+	}
+	return strings.Join(faults, ",")
+}
+
+// SetFaults injects faults into the responses of the checker's admin calls.
+// The zero Faults stops injecting.
+func (c *Checker) SetFaults(faults Faults) {
+	c.updateThresholds(func(t *Thresholds) { t.Faults = faults })
+}
+
+// faultyAdminClient injects the checker's current faults into the responses
+// of an admin client
+type faultyAdminClient struct {
+	adm      KafkaAdminClient
+	brokerID int32
+	faults   func() Faults
+}
+
+// Metadata implements KafkaAdminClient
+func (f *faultyAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	faults := f.faults()
+	if faults.SlowMetadata > 0 {
+		select {
+		case <-time.After(faults.SlowMetadata):
+		case <-ctx.Done():
+			return kadm.Metadata{}, ctx.Err()
+		}
+	}
+	metadata, err := f.adm.Metadata(ctx, topics...)
+	if err != nil {
+		return metadata, err
+	}
+
+	if faults.BrokerMissing {
+		brokers := make(kadm.BrokerDetails, 0, len(metadata.Brokers))
+		for _, b := range metadata.Brokers {
+			if b.NodeID != f.brokerID {
+				brokers = append(brokers, b)
+			}
+		}
+		metadata.Brokers = brokers
+	}
+	if faults.URP > 0 && (len(topics) == 0 || slices.Contains(topics, FaultTopic)) {
+		partitions := make(kadm.PartitionDetails, faults.URP)
+		for p := int32(0); p < int32(faults.URP); p++ {
+			partitions[p] = kadm.PartitionDetail{
+				Topic:     FaultTopic,
+				Partition: p,
+				Leader:    -1,
+				Replicas:  []int32{f.brokerID},
+			}
+		}
+		// The topics map may be shared with the client's metadata cache
+		topicDetails := make(kadm.TopicDetails, len(metadata.Topics)+1)
+		for name, topic := range metadata.Topics {
+			topicDetails[name] = topic
+		}
+		topicDetails[FaultTopic] = kadm.TopicDetail{Topic: FaultTopic, Partitions: partitions}
+		metadata.Topics = topicDetails
+	}
+	return metadata, nil
+}
+
+// DescribeBrokerLogDirs implements KafkaAdminClient
+func (f *faultyAdminClient) DescribeBrokerLogDirs(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+	logDirs, err := f.adm.DescribeBrokerLogDirs(ctx, broker, topics)
+	if err != nil || broker != f.brokerID || !f.faults().LogDirError {
+		return logDirs, err
+	}
+
+	failed := make(kadm.DescribedLogDirs, len(logDirs)+1)
+	for dir, d := range logDirs {
+		d.Err = kerr.KafkaStorageError
+		failed[dir] = d
+	}
+	if len(failed) == 0 {
+		failed[faultLogDir] = kadm.DescribedLogDir{Broker: broker, Dir: faultLogDir, Err: kerr.KafkaStorageError}
+	}
+	return failed, nil
+}
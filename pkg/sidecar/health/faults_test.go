@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func TestParseFaults(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    Faults
+		expectError bool
+	}{
+		{spec: "", expected: Faults{}},
+		{spec: "slow-metadata=3s", expected: Faults{SlowMetadata: 3 * time.Second}},
+		{spec: " urp=10 , broker-missing,log-dir-error ", expected: Faults{URP: 10, BrokerMissing: true, LogDirError: true}},
+		{spec: "slow-metadata", expectError: true},
+		{spec: "slow-metadata=-1s", expectError: true},
+		{spec: "urp=0", expectError: true},
+		{spec: "broker-missing=true", expectError: true},
+		{spec: "disk-full", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			faults, err := ParseFaults(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", faults)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if faults != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, faults)
+			}
+			if parsed, _ := ParseFaults(faults.String()); parsed != faults {
+				t.Errorf("expected %q to parse back to %+v, got %+v", faults.String(), faults, parsed)
+			}
+		})
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	tests := []struct {
+		name          string
+		faults        Faults
+		expectLive    bool
+		expectMessage string
+	}{
+		{name: "none", expectLive: true},
+		{name: "slow metadata", faults: Faults{SlowMetadata: time.Minute}, expectMessage: "context deadline exceeded"},
+		{name: "broker missing", faults: Faults{BrokerMissing: true}, expectMessage: "broker not registered"},
+		{name: "urp", faults: Faults{URP: 3}, expectLive: true, expectMessage: "under-replicated partitions"},
+		{name: "log dir error", faults: Faults{LogDirError: true}, expectLive: true, expectMessage: "log directories unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(1, "localhost:9092", 50*time.Millisecond, SASLConfig{}, testLogger())
+			checker.SetFaults(tt.faults)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Controller: 1,
+							Brokers:    kadm.BrokerDetails{{NodeID: 1}, {NodeID: 2}},
+							Topics: kadm.TopicDetails{"orders": {Topic: "orders", Partitions: kadm.PartitionDetails{
+								0: {Topic: "orders", Partition: 0, Leader: 1, Replicas: []int32{1, 2}, ISR: []int32{1, 2}},
+							}}},
+						}, nil
+					},
+					DescribeBrokerLogDirsFunc: func(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+						return kadm.DescribedLogDirs{"/var/lib/kafka/data": {Broker: broker, Dir: "/var/lib/kafka/data"}}, nil
+					},
+				}, func() {}, nil
+			})
+			ctx := context.Background()
+
+			if result := checker.CheckLiveness(ctx); result.Healthy != tt.expectLive {
+				t.Errorf("expected live=%v, got %+v", tt.expectLive, result)
+			}
+			result := checker.CheckReadiness(ctx)
+			if result.Healthy != (tt.expectMessage == "") {
+				t.Errorf("expected ready=%v, got %+v", tt.expectMessage == "", result)
+			}
+			if !strings.Contains(result.Message, tt.expectMessage) {
+				t.Errorf("expected message containing %q, got %q", tt.expectMessage, result.Message)
+			}
+
+			if tt.faults.URP > 0 {
+				counts, _ := checker.LastURPCounts()
+				if counts.Counted != tt.faults.URP {
+					t.Errorf("expected %d under-replicated partitions, got %d", tt.faults.URP, counts.Counted)
+				}
+			}
+		})
+	}
+}
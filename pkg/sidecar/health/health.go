@@ -5,12 +5,21 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
-	"github.com/twmb/franz-go/pkg/sasl/plain"
-	"github.com/twmb/franz-go/pkg/sasl/scram"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
 )
 
 // KafkaAdminClient defines the interface for Kafka admin operations.
@@ -21,39 +30,150 @@ type KafkaAdminClient interface {
 }
 
 // SASLConfig holds SASL authentication configuration
-type SASLConfig struct {
-	Enabled   bool
-	Mechanism string // PLAIN, SCRAM-SHA-256, SCRAM-SHA-512
-	Username  string
-	Password  string
-}
+type SASLConfig = kafkaclient.SASLConfig
 
 // ClientFactory creates Kafka admin clients. Allows injection for testing.
 type ClientFactory func() (KafkaAdminClient, func(), error)
 
+// StandbyReporter reports whether the broker is a warm standby held out of leadership
+type StandbyReporter interface {
+	InStandby() bool
+}
+
+// CanaryReporter reports the outcome of the last canary produce/consume probe
+type CanaryReporter interface {
+	CanaryError() error
+}
+
+// CertReporter reports TLS certificates that expire too soon
+type CertReporter interface {
+	CertError() error
+}
+
+// DiskReporter reports data volumes whose usage is above the readiness threshold
+type DiskReporter interface {
+	DiskError() error
+}
+
+// RequestErrorReporter reports request types whose error ratio is above their threshold
+type RequestErrorReporter interface {
+	ErrorRateError() error
+}
+
+// EpochReporter reports a local broker registration whose epoch regressed or
+// that the controller fenced
+type EpochReporter interface {
+	EpochError() error
+}
+
+// ClusterIDVerifier checks the cluster ID the brokers report against the
+// expected one
+type ClusterIDVerifier interface {
+	VerifyClusterID(reported string) error
+}
+
+// Probe types, as reported to the ProbeObserver
+const (
+	ProbeLiveness  = "liveness"
+	ProbeReadiness = "readiness"
+)
+
+// Checks a probe can fail on, as reported to the ProbeObserver
+const (
+	// CheckRequest is an invalid probe request, e.g. a malformed timeout
+	CheckRequest          = "request"
+	CheckClient           = "client"
+	CheckForming          = "forming"
+	CheckBrokerRegistered = "broker_registered"
+	CheckClusterID        = "cluster_id"
+	CheckBrokerEpoch      = "broker_epoch"
+	CheckBrokerIDConflict = "broker_id_conflict"
+	CheckController       = "controller"
+	CheckURP              = "under_replicated"
+	CheckLogDirs          = "log_dirs"
+	CheckCanary           = "canary"
+	CheckCerts            = "certs"
+	CheckDisks            = "disks"
+)
+
+// ProbeObserver receives how long every liveness and readiness probe took, and
+// the check it failed on, or "" when it passed
+type ProbeObserver interface {
+	ObserveProbe(probe string, duration time.Duration, failedCheck string)
+}
+
+// Thresholds are the checker settings that can be changed while checks run,
+// e.g. by a configuration reload
+type Thresholds struct {
+	// CheckTimeout bounds each check
+	CheckTimeout time.Duration
+	// MaxTimeout bounds the ?timeout= override; zero ignores the parameter
+	MaxTimeout time.Duration
+	// FDMinFreeRatio degrades readiness when the broker's free FD ratio is lower
+	FDMinFreeRatio float64
+	// FormationGrace is how long after startup an empty cluster is forming
+	FormationGrace time.Duration
+	// URPFilter selects the topics whose under-replicated partitions count
+	URPFilter TopicFilter
+	// Faults are simulated failures injected into the admin call responses
+	Faults Faults
+}
+
 // Checker provides health check functionality for Kafka brokers
 type Checker struct {
 	brokerID         int32
-	bootstrapServers []string
-	checkTimeout     time.Duration
+	bootstrapServers atomic.Pointer[[]string]
 	saslConfig       SASLConfig
+	tlsConfig        kafkaclient.TLSConfig
 	logger           *slog.Logger
 	clientFactory    ClientFactory
+	fdReader         procfs.FDUsageReader
+	clock            clock.Clock
+	startedAt        time.Time
+	clusterOnly      bool
+	standby          StandbyReporter
+	canary           CanaryReporter
+	certs            CertReporter
+	disks            DiskReporter
+	requestErrors    RequestErrorReporter
+	clusterID        ClusterIDVerifier
+	epoch            EpochReporter
+	advertisedHosts  map[string]bool
+	probes           ProbeObserver
+	tracer           trace.Tracer
+	retry            retry.Policy
+
+	thresholdsMu sync.Mutex
+	thresholds   atomic.Pointer[Thresholds]
+
+	mu      sync.RWMutex
+	lastURP *URPCounts
+	results Results
+}
+
+// Results are the outcomes of the latest health evaluations, whether by a
+// probe or by CheckLiveness and CheckReadiness. A nil result was never
+// evaluated; a check that errored keeps its previous result.
+type Results struct {
+	Live              *bool
+	Ready             *bool
+	BrokerRegistered  *bool
+	ControllerElected *bool
+	BrokerIDConflict  *bool
 }
 
 // NewChecker creates a new health checker
 func NewChecker(brokerID int32, bootstrapServers string, checkTimeout time.Duration, saslConfig SASLConfig, logger *slog.Logger) *Checker {
-	servers := strings.Split(bootstrapServers, ",")
-	for i := range servers {
-		servers[i] = strings.TrimSpace(servers[i])
-	}
 	c := &Checker{
-		brokerID:         brokerID,
-		bootstrapServers: servers,
-		checkTimeout:     checkTimeout,
-		saslConfig:       saslConfig,
-		logger:           logger,
+		brokerID:   brokerID,
+		saslConfig: saslConfig,
+		logger:     logger,
+		clock:      clock.Real,
+		tracer:     noop.NewTracerProvider().Tracer(tracerName),
 	}
+	c.startedAt = c.clock.Now()
+	c.SetBootstrapServers(kafkaclient.ParseBootstrapServers(bootstrapServers))
+	c.thresholds.Store(&Thresholds{CheckTimeout: checkTimeout})
 	// Set default client factory
 	c.clientFactory = c.defaultClientFactory
 	return c
@@ -64,70 +184,212 @@ func (c *Checker) SetClientFactory(factory ClientFactory) {
 	c.clientFactory = factory
 }
 
-// defaultClientFactory creates a new Kafka admin client using franz-go
-func (c *Checker) defaultClientFactory() (KafkaAdminClient, func(), error) {
-	opts := []kgo.Opt{
-		kgo.SeedBrokers(c.bootstrapServers...),
-	}
+// SetClock replaces the wall clock, for tests and simulations. The formation
+// grace period restarts from the clock's current time.
+func (c *Checker) SetClock(clk clock.Clock) {
+	c.clock = clk
+	c.startedAt = clk.Now()
+}
+
+// SetBootstrapServers replaces the bootstrap servers of the clients created
+// for the next checks, e.g. after the cluster was scaled
+func (c *Checker) SetBootstrapServers(servers []string) {
+	c.bootstrapServers.Store(&servers)
+}
+
+// BootstrapServers returns the bootstrap servers of new clients
+func (c *Checker) BootstrapServers() []string {
+	return *c.bootstrapServers.Load()
+}
 
-	// Add SASL authentication if enabled
-	if c.saslConfig.Enabled {
-		saslOpt, err := c.getSASLOpt()
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to configure SASL: %w", err)
+// SetTLS makes the checker's clients dial the brokers with TLS
+func (c *Checker) SetTLS(tlsConfig kafkaclient.TLSConfig) {
+	c.tlsConfig = tlsConfig
+}
+
+// SetFDUsageReader enables the file descriptor headroom check. Readiness reports
+// a degraded status when the broker's free FD ratio drops below minFreeRatio.
+func (c *Checker) SetFDUsageReader(reader procfs.FDUsageReader, minFreeRatio float64) {
+	c.fdReader = reader
+	c.updateThresholds(func(t *Thresholds) { t.FDMinFreeRatio = minFreeRatio })
+}
+
+// SetFormationGrace sets how long after startup an empty cluster (no registered
+// brokers) is reported as forming rather than unhealthy. Zero disables it.
+func (c *Checker) SetFormationGrace(grace time.Duration) {
+	c.updateThresholds(func(t *Thresholds) { t.FormationGrace = grace })
+}
+
+// Thresholds returns the current thresholds
+func (c *Checker) Thresholds() Thresholds {
+	return *c.thresholds.Load()
+}
+
+// SetThresholds replaces the thresholds. Checks that are running finish with
+// the previous ones.
+func (c *Checker) SetThresholds(thresholds Thresholds) {
+	c.updateThresholds(func(t *Thresholds) { *t = thresholds })
+}
+
+// updateThresholds replaces the thresholds with an updated copy
+func (c *Checker) updateThresholds(update func(*Thresholds)) {
+	c.thresholdsMu.Lock()
+	defer c.thresholdsMu.Unlock()
+	previous := *c.thresholds.Load()
+	t := previous
+	update(&t)
+	c.thresholds.Store(&t)
+
+	if t.Faults != previous.Faults {
+		if t.Faults.Enabled() {
+			c.logger.Warn("injecting faults into health checks", "faults", t.Faults.String())
+		} else {
+			c.logger.Info("stopped injecting faults into health checks")
 		}
-		opts = append(opts, saslOpt)
 	}
+}
 
-	cl, err := kgo.NewClient(opts...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create kafka client: %w", err)
-	}
+// SetClusterOnly restricts checks to the cluster as a whole, for nodes that are not
+// brokers themselves (KRaft controllers, MirrorMaker, Connect). Liveness then only
+// reports the sidecar itself, since restarting a client node cannot fix an
+// unreachable cluster, and readiness checks the cluster is reachable with an
+// elected controller.
+func (c *Checker) SetClusterOnly(clusterOnly bool) {
+	c.clusterOnly = clusterOnly
+}
 
-	adm := kadm.NewClient(cl)
-	return adm, cl.Close, nil
+// SetStandby marks the broker as a warm standby. While it is in standby, a passing
+// readiness check reports "standby-ready" instead of "healthy".
+func (c *Checker) SetStandby(standby StandbyReporter) {
+	c.standby = standby
 }
 
-// getSASLOpt returns the appropriate SASL option based on mechanism
-func (c *Checker) getSASLOpt() (kgo.Opt, error) {
-	mechanism := strings.ToUpper(c.saslConfig.Mechanism)
+// SetURPTopicFilter restricts the under-replicated partitions check to the topics
+// selected by the filter. Excluded topics are still counted, but only reported.
+func (c *Checker) SetURPTopicFilter(filter TopicFilter) {
+	c.updateThresholds(func(t *Thresholds) { t.URPFilter = filter })
+}
 
-	switch mechanism {
-	case "PLAIN":
-		auth := plain.Auth{
-			User: c.saslConfig.Username,
-			Pass: c.saslConfig.Password,
-		}
-		return kgo.SASL(auth.AsMechanism()), nil
+// SetCanary makes readiness fail while the last canary probe failed
+func (c *Checker) SetCanary(canary CanaryReporter) {
+	c.canary = canary
+}
 
-	case "SCRAM-SHA-256":
-		auth := scram.Auth{
-			User: c.saslConfig.Username,
-			Pass: c.saslConfig.Password,
-		}
-		return kgo.SASL(auth.AsSha256Mechanism()), nil
+// SetCerts makes readiness fail while a TLS certificate expires too soon
+func (c *Checker) SetCerts(certs CertReporter) {
+	c.certs = certs
+}
 
-	case "SCRAM-SHA-512":
-		auth := scram.Auth{
-			User: c.saslConfig.Username,
-			Pass: c.saslConfig.Password,
-		}
-		return kgo.SASL(auth.AsSha512Mechanism()), nil
+// SetDisks makes readiness fail while a data volume is too full, so traffic is
+// shifted away before the broker fails on a full disk
+func (c *Checker) SetDisks(disks DiskReporter) {
+	c.disks = disks
+}
+
+// SetRequestErrors makes readiness report degraded while clients are getting
+// too many errors from the broker
+func (c *Checker) SetRequestErrors(requestErrors RequestErrorReporter) {
+	c.requestErrors = requestErrors
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported SASL mechanism: %s (supported: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512)", mechanism)
+// SetClusterID makes readiness fail while the brokers report a cluster ID the
+// verifier rejects, e.g. after a replica attached to another cluster's volume
+func (c *Checker) SetClusterID(verifier ClusterIDVerifier) {
+	c.clusterID = verifier
+}
+
+// SetEpoch makes readiness fail while the local broker's registration epoch is
+// below one it registered with before, or the controller has fenced it
+func (c *Checker) SetEpoch(epoch EpochReporter) {
+	c.epoch = epoch
+}
+
+// SetAdvertisedHosts enables the broker ID conflict check: readiness fails
+// while the cluster metadata lists the broker's ID on a host other than these,
+// the hosts of the broker's own advertised listeners
+func (c *Checker) SetAdvertisedHosts(hosts []string) {
+	c.advertisedHosts = map[string]bool{}
+	for _, host := range hosts {
+		c.advertisedHosts[normalizeHost(host)] = true
 	}
 }
 
+// SetProbeObserver reports every liveness and readiness probe to the observer
+func (c *Checker) SetProbeObserver(observer ProbeObserver) {
+	c.probes = observer
+}
+
+// finishProbe ends the probe's span and reports the probe to the observer, if any
+func (c *Checker) finishProbe(span trace.Span, probe string, start time.Time, failedCheck string) {
+	if failedCheck != "" {
+		span.SetAttributes(attribute.String("health.failed_check", failedCheck))
+		span.SetStatus(codes.Error, probe+" failed on "+failedCheck)
+	}
+	span.End()
+	if c.probes != nil {
+		c.probes.ObserveProbe(probe, c.clock.Since(start), failedCheck)
+	}
+}
+
+// LastResults returns the outcomes of the latest health evaluations
+func (c *Checker) LastResults() Results {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.results
+}
+
+// recordResult stores the outcome of a health evaluation
+func (c *Checker) recordResult(result **bool, value bool) {
+	c.mu.Lock()
+	*result = &value
+	c.mu.Unlock()
+}
+
+// LastURPCounts returns the counts from the last under-replicated partitions
+// check, and false before the first check
+func (c *Checker) LastURPCounts() (URPCounts, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.lastURP == nil {
+		return URPCounts{}, false
+	}
+	return *c.lastURP, true
+}
+
+// InStandby reports whether the broker is a warm standby that has not been promoted
+func (c *Checker) InStandby() bool {
+	return c.standby != nil && c.standby.InStandby()
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *Checker) defaultClientFactory() (KafkaAdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(kafkaclient.Config{
+		BootstrapServers: c.BootstrapServers(),
+		SASL:             c.saslConfig,
+		TLS:              c.tlsConfig,
+		Retry:            c.retry,
+	})
+}
+
+// getSASLOpt returns the appropriate SASL option based on mechanism
+func (c *Checker) getSASLOpt() (kgo.Opt, error) {
+	return kafkaclient.SASLOpt(c.saslConfig)
+}
+
 // CheckResult represents the result of a health check
 type CheckResult struct {
-	Healthy bool   `json:"healthy"`
-	Message string `json:"message,omitempty"`
+	Healthy  bool   `json:"healthy"`
+	Degraded bool   `json:"degraded,omitempty"`
+	Forming  bool   `json:"forming,omitempty"`
+	Standby  bool   `json:"standby,omitempty"`
+	Message  string `json:"message,omitempty"`
 }
 
 // BrokerInMetadata checks if the broker is present in cluster metadata
-func (c *Checker) BrokerInMetadata(ctx context.Context, adm KafkaAdminClient) (bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+func (c *Checker) BrokerInMetadata(ctx context.Context, adm KafkaAdminClient) (_ bool, err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckBrokerRegistered)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
 	defer cancel()
 
 	metadata, err := adm.Metadata(ctx)
@@ -135,18 +397,23 @@ func (c *Checker) BrokerInMetadata(ctx context.Context, adm KafkaAdminClient) (b
 		return false, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 
+	registered := false
 	for _, broker := range metadata.Brokers {
 		if broker.NodeID == c.brokerID {
-			return true, nil
+			registered = true
+			break
 		}
 	}
+	c.recordResult(&c.results.BrokerRegistered, registered)
 
-	return false, nil
+	return registered, nil
 }
 
 // ControllerElected checks if a controller has been elected
-func (c *Checker) ControllerElected(ctx context.Context, adm KafkaAdminClient) (bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+func (c *Checker) ControllerElected(ctx context.Context, adm KafkaAdminClient) (_ bool, err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckController)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
 	defer cancel()
 
 	metadata, err := adm.Metadata(ctx)
@@ -154,22 +421,90 @@ func (c *Checker) ControllerElected(ctx context.Context, adm KafkaAdminClient) (
 		return false, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 
-	return metadata.Controller >= 0, nil
+	elected := metadata.Controller >= 0
+	c.recordResult(&c.results.ControllerElected, elected)
+	return elected, nil
+}
+
+// VerifyClusterID checks the cluster ID the brokers report in their metadata
+func (c *Checker) VerifyClusterID(ctx context.Context, adm KafkaAdminClient) (err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckClusterID)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
+	defer cancel()
+
+	metadata, err := adm.Metadata(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	return c.clusterID.VerifyClusterID(metadata.Cluster)
 }
 
-// UnderReplicatedPartitions returns the count of under-replicated partitions for this broker
+// BrokerIDConflict returns the host the cluster metadata lists the broker's ID
+// on when it is not one of the advertised hosts, or "" when it is or the ID is
+// not registered. A replica started on another replica's volume, or with a
+// copied BROKER_ID, registers the ID from another host.
+func (c *Checker) BrokerIDConflict(ctx context.Context, adm KafkaAdminClient) (_ string, err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckBrokerIDConflict)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
+	defer cancel()
+
+	metadata, err := adm.Metadata(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	conflict := ""
+	for _, broker := range metadata.Brokers {
+		if broker.NodeID == c.brokerID && !c.advertisedHosts[normalizeHost(broker.Host)] {
+			conflict = broker.Host
+			break
+		}
+	}
+	c.recordResult(&c.results.BrokerIDConflict, conflict != "")
+	return conflict, nil
+}
+
+// normalizeHost returns host in the form hosts are compared in: lowercase,
+// without the trailing dot of a fully qualified name
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// UnderReplicatedPartitions returns the count of under-replicated partitions for
+// this broker, ignoring topics excluded by the URP topic filter
 func (c *Checker) UnderReplicatedPartitions(ctx context.Context, adm KafkaAdminClient) (int, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	counts, err := c.CountUnderReplicated(ctx, adm)
+	if err != nil {
+		return -1, err
+	}
+	return counts.Counted, nil
+}
+
+// CountUnderReplicated counts the under-replicated partitions for this broker,
+// split by whether the URP topic filter counts them against readiness, along
+// with the partitions the broker hosts and leads
+func (c *Checker) CountUnderReplicated(ctx context.Context, adm KafkaAdminClient) (_ URPCounts, err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckURP)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
 	defer cancel()
 
 	metadata, err := adm.Metadata(ctx)
 	if err != nil {
-		return -1, fmt.Errorf("failed to fetch metadata: %w", err)
+		return URPCounts{}, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 
-	underReplicated := 0
-	for _, topic := range metadata.Topics {
+	filter := c.Thresholds().URPFilter
+	counts := URPCounts{CheckedAt: c.clock.Now()}
+	for name, topic := range metadata.Topics {
+		counted := filter.Counts(name)
 		for _, partition := range topic.Partitions {
+			if partition.Leader == c.brokerID {
+				counts.Leaders++
+			}
+
 			// Check if this broker is a replica for this partition
 			isReplica := false
 			for _, replica := range partition.Replicas {
@@ -182,6 +517,7 @@ func (c *Checker) UnderReplicatedPartitions(ctx context.Context, adm KafkaAdminC
 			if !isReplica {
 				continue
 			}
+			counts.Partitions++
 
 			// Check if this broker is in the ISR
 			inISR := false
@@ -192,18 +528,29 @@ func (c *Checker) UnderReplicatedPartitions(ctx context.Context, adm KafkaAdminC
 				}
 			}
 
-			if !inISR {
-				underReplicated++
+			if inISR {
+				continue
+			}
+			if counted {
+				counts.Counted++
+			} else {
+				counts.Excluded++
 			}
 		}
 	}
 
-	return underReplicated, nil
+	c.mu.Lock()
+	c.lastURP = &counts
+	c.mu.Unlock()
+
+	return counts, nil
 }
 
 // LogDirsHealthy checks if log directories are healthy (no future partitions)
-func (c *Checker) LogDirsHealthy(ctx context.Context, adm KafkaAdminClient) (bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+func (c *Checker) LogDirsHealthy(ctx context.Context, adm KafkaAdminClient) (_ bool, err error) {
+	ctx, span := c.startSpan(ctx, "health."+CheckLogDirs)
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
 	defer cancel()
 
 	// DescribeBrokerLogDirs returns DescribedLogDirs which is map[string]DescribedLogDir
@@ -214,7 +561,7 @@ func (c *Checker) LogDirsHealthy(ctx context.Context, adm KafkaAdminClient) (boo
 
 	// Check if there was an error for any directory
 	if err := logDirs.Error(); err != nil {
-		c.logger.Warn("error describing log dirs", "error", err)
+		c.logger.WarnContext(ctx, "error describing log dirs", "error", err)
 		return false, nil
 	}
 
@@ -222,7 +569,7 @@ func (c *Checker) LogDirsHealthy(ctx context.Context, adm KafkaAdminClient) (boo
 	var foundFuture bool
 	logDirs.EachPartition(func(p kadm.DescribedLogDirPartition) {
 		if p.IsFuture {
-			c.logger.Warn("found future partition",
+			c.logger.WarnContext(ctx, "found future partition",
 				"topic", p.Topic,
 				"partition", p.Partition,
 				"dir", p.Dir)
@@ -232,3 +579,53 @@ func (c *Checker) LogDirsHealthy(ctx context.Context, adm KafkaAdminClient) (boo
 
 	return !foundFuture, nil
 }
+
+// BrokerFDUsage returns the broker process's file descriptor usage. The second
+// return value is false when FD usage is unavailable (no /proc access to the
+// broker, or the check is not configured).
+func (c *Checker) BrokerFDUsage() (procfs.FDUsage, bool) {
+	if c.fdReader == nil {
+		return procfs.FDUsage{}, false
+	}
+
+	usage, err := c.fdReader.FDUsage()
+	if err != nil {
+		c.logger.Debug("broker file descriptor usage unavailable", "error", err)
+		return procfs.FDUsage{}, false
+	}
+	return usage, true
+}
+
+// FDHeadroomLow reports whether the broker's free FD ratio is below the threshold
+func (c *Checker) FDHeadroomLow(usage procfs.FDUsage) bool {
+	return usage.FreeRatio() < c.Thresholds().FDMinFreeRatio
+}
+
+// FormationGraceActive reports whether the sidecar is still within the formation
+// grace period after startup. Alerting should stay quiet while this is true and
+// the cluster is forming.
+func (c *Checker) FormationGraceActive() bool {
+	grace := c.Thresholds().FormationGrace
+	return grace > 0 && c.clock.Since(c.startedAt) < grace
+}
+
+// ClusterForming reports whether the cluster looks like it is forming after a
+// full restart: within the grace period and with no brokers registered (or none
+// reachable at all).
+func (c *Checker) ClusterForming(ctx context.Context, adm KafkaAdminClient) bool {
+	if !c.FormationGraceActive() {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(ctx))
+	defer cancel()
+
+	metadata, err := adm.Metadata(ctx)
+	return err != nil || len(metadata.Brokers) == 0
+}
+
+// formingMessage describes why readiness reports the cluster as forming
+func (c *Checker) formingMessage() string {
+	return fmt.Sprintf("cluster forming: no brokers registered (uptime %s, formation grace %s)",
+		c.clock.Since(c.startedAt).Truncate(time.Second), c.Thresholds().FormationGrace)
+}
@@ -80,17 +80,17 @@ func TestNewChecker(t *testing.T) {
 				t.Errorf("expected brokerID %d, got %d", tt.brokerID, checker.brokerID)
 			}
 
-			if checker.checkTimeout != tt.checkTimeout {
-				t.Errorf("expected checkTimeout %v, got %v", tt.checkTimeout, checker.checkTimeout)
+			if checker.Thresholds().CheckTimeout != tt.checkTimeout {
+				t.Errorf("expected checkTimeout %v, got %v", tt.checkTimeout, checker.Thresholds().CheckTimeout)
 			}
 
-			if len(checker.bootstrapServers) != len(tt.expectedServers) {
-				t.Errorf("expected %d servers, got %d", len(tt.expectedServers), len(checker.bootstrapServers))
+			if len(checker.BootstrapServers()) != len(tt.expectedServers) {
+				t.Errorf("expected %d servers, got %d", len(tt.expectedServers), len(checker.BootstrapServers()))
 			}
 
 			for i, server := range tt.expectedServers {
-				if checker.bootstrapServers[i] != server {
-					t.Errorf("expected server[%d] to be %q, got %q", i, server, checker.bootstrapServers[i])
+				if checker.BootstrapServers()[i] != server {
+					t.Errorf("expected server[%d] to be %q, got %q", i, server, checker.BootstrapServers()[i])
 				}
 			}
 
@@ -443,6 +443,115 @@ func TestUnderReplicatedPartitions(t *testing.T) {
 	}
 }
 
+func TestUnderReplicatedPartitionsTopicFilter(t *testing.T) {
+	logger := testLogger()
+	ctx := context.Background()
+
+	mockClient := &MockKafkaAdminClient{
+		MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+			return kadm.Metadata{Topics: kadm.TopicDetails{
+				"orders": kadm.TopicDetail{
+					Partitions: kadm.PartitionDetails{
+						0: {Partition: 0, Leader: 1, Replicas: []int32{0, 1}, ISR: []int32{1}},
+						1: {Partition: 1, Leader: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+						2: {Partition: 2, Leader: 1, Replicas: []int32{1}, ISR: []int32{1}},
+					},
+				},
+				"scratch-1": kadm.TopicDetail{
+					Partitions: kadm.PartitionDetails{
+						0: {Partition: 0, Leader: -1, Replicas: []int32{0}, ISR: []int32{}},
+						1: {Partition: 1, Leader: -1, Replicas: []int32{0}, ISR: []int32{}},
+					},
+				},
+				"__consumer_offsets": kadm.TopicDetail{
+					Partitions: kadm.PartitionDetails{
+						0: {Partition: 0, Leader: 1, Replicas: []int32{0, 1}, ISR: []int32{1}},
+					},
+				},
+			}}, nil
+		},
+	}
+
+	tests := []struct {
+		name           string
+		include        string
+		exclude        string
+		expectCounted  int
+		expectExcluded int
+	}{
+		{
+			name:          "no filter",
+			expectCounted: 4,
+		},
+		{
+			name:           "exclude patterns",
+			exclude:        "scratch-.*, __.*",
+			expectCounted:  1,
+			expectExcluded: 3,
+		},
+		{
+			name:           "include patterns",
+			include:        "orders",
+			expectCounted:  1,
+			expectExcluded: 3,
+		},
+		{
+			name:           "exclude wins over include",
+			include:        "orders,scratch-.*",
+			exclude:        "scratch-1",
+			expectCounted:  1,
+			expectExcluded: 3,
+		},
+		{
+			name:          "patterns match the whole name",
+			exclude:       "scratch",
+			expectCounted: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewTopicFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checker := NewChecker(0, "localhost:9092", 10*time.Second, SASLConfig{}, logger)
+			checker.SetURPTopicFilter(filter)
+
+			if _, ok := checker.LastURPCounts(); ok {
+				t.Fatal("expected no counts before the first check")
+			}
+
+			count, err := checker.UnderReplicatedPartitions(ctx, mockClient)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != tt.expectCounted {
+				t.Errorf("expected count=%d, got %d", tt.expectCounted, count)
+			}
+
+			counts, ok := checker.LastURPCounts()
+			if !ok {
+				t.Fatal("expected counts after the check")
+			}
+			if counts.Counted != tt.expectCounted || counts.Excluded != tt.expectExcluded {
+				t.Errorf("expected counted=%d excluded=%d, got counted=%d excluded=%d",
+					tt.expectCounted, tt.expectExcluded, counts.Counted, counts.Excluded)
+			}
+			// The filter only applies to readiness
+			if counts.Partitions != 5 || counts.Leaders != 1 {
+				t.Errorf("expected 5 partitions and 1 leader, got %d and %d", counts.Partitions, counts.Leaders)
+			}
+		})
+	}
+}
+
+func TestNewTopicFilterInvalidPattern(t *testing.T) {
+	if _, err := NewTopicFilter("", "scratch-(["); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+}
+
 func TestLogDirsHealthy(t *testing.T) {
 	logger := testLogger()
 	ctx := context.Background()
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/controlplane-com/libs-go/pkg/web"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // LivenessResponse represents the response for the liveness endpoint
@@ -17,48 +18,89 @@ type LivenessResponse struct {
 
 // LivenessHandler handles GET /health/live requests
 func (c *Checker) LivenessHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	start := c.clock.Now()
+	ctx, span := c.startSpan(r.Context(), "health."+ProbeLiveness)
+	failed := c.liveness(w, r.WithContext(ctx))
+	// A malformed request says nothing about the broker
+	if failed != CheckRequest {
+		c.recordResult(&c.results.Live, failed == "")
+	}
+	c.finishProbe(span, ProbeLiveness, start, failed)
+}
+
+// liveness writes the liveness response and returns the check that failed, or
+// "" when the broker is alive
+func (c *Checker) liveness(w http.ResponseWriter, r *http.Request) string {
+	ctx, cancel, err := c.requestContext(r)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return CheckRequest
+	}
+	defer cancel()
 
 	response := LivenessResponse{
 		BrokerID: c.brokerID,
 	}
 
-	adm, cleanup, err := c.clientFactory()
+	if c.clusterOnly {
+		response.Status = "healthy"
+		_, _ = web.ReturnResponse(w, response)
+		return ""
+	}
+
+	adm, cleanup, err := c.adminClient()
 	if err != nil {
-		c.logger.Error("failed to create kafka client", "error", err)
+		c.logger.ErrorContext(ctx, "failed to create kafka client", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckClient
 	}
 	defer cleanup()
 
 	brokerFound, err := c.BrokerInMetadata(ctx, adm)
 	if err != nil {
-		c.logger.Error("failed to check broker in metadata", "error", err)
+		c.logger.ErrorContext(ctx, "failed to check broker in metadata", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckBrokerRegistered
 	}
 
 	response.BrokerFound = brokerFound
 
 	if !brokerFound {
-		c.logger.Warn("broker not found in cluster metadata", "brokerId", c.brokerID)
+		c.logger.WarnContext(ctx, "broker not found in cluster metadata", "brokerId", c.brokerID)
 		response.Status = "unhealthy"
 		response.ErrorMessage = "broker not found in cluster metadata"
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckBrokerRegistered
 	}
 
 	response.Status = "healthy"
 	_, _ = web.ReturnResponse(w, response)
+	return ""
 }
 
 // CheckLiveness performs a liveness check and returns the result
 func (c *Checker) CheckLiveness(ctx context.Context) CheckResult {
-	adm, cleanup, err := c.clientFactory()
+	ctx, span := c.startSpan(ctx, "health."+ProbeLiveness)
+	result := c.checkLiveness(ctx)
+	if !result.Healthy {
+		span.SetStatus(codes.Error, result.Message)
+	}
+	span.End()
+	c.recordResult(&c.results.Live, result.Healthy)
+	return result
+}
+
+// checkLiveness runs the liveness checks of the handler
+func (c *Checker) checkLiveness(ctx context.Context) CheckResult {
+	if c.clusterOnly {
+		return CheckResult{Healthy: true}
+	}
+
+	adm, cleanup, err := c.adminClient()
 	if err != nil {
 		return CheckResult{
 			Healthy: false,
@@ -250,3 +250,25 @@ func TestLivenessResponseWithError(t *testing.T) {
 		t.Error("expected 'error' field in JSON when ErrorMessage is set")
 	}
 }
+
+func TestLivenessClusterOnly(t *testing.T) {
+	checker := NewChecker(7, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+	checker.SetClusterOnly(true)
+	checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+		return nil, nil, errors.New("connection refused")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/health/live", nil)
+	w := httptest.NewRecorder()
+	checker.LivenessHandler(w, req)
+
+	// An unreachable cluster must not restart a node that is not a broker
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	result := checker.CheckLiveness(context.Background())
+	if !result.Healthy {
+		t.Errorf("expected healthy result, got %+v", result)
+	}
+}
@@ -5,122 +5,402 @@ import (
 	"net/http"
 
 	"github.com/controlplane-com/libs-go/pkg/web"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+const (
+	fdHeadroomWarning   = "broker file descriptor headroom below threshold"
+	canaryFailedMessage = "canary produce/consume failed"
+	certExpiringMessage = "tls certificate expiring"
+	diskFullMessage     = "data volume usage above threshold"
+	requestErrorWarning = "request error ratio above threshold"
+	clusterIDMessage    = "cluster ID mismatch"
+	idConflictMessage   = "broker ID registered from another host"
+	epochMessage        = "broker epoch unhealthy"
 )
 
 // ReadinessResponse represents the response for the readiness endpoint
 type ReadinessResponse struct {
-	Status                    string `json:"status"`
-	BrokerID                  int32  `json:"brokerId"`
-	BrokerRegistered          bool   `json:"brokerRegistered"`
-	ControllerElected         bool   `json:"controllerElected"`
-	UnderReplicatedPartitions int    `json:"underReplicatedPartitions"`
-	LogDirsHealthy            bool   `json:"logDirsHealthy"`
-	ErrorMessage              string `json:"error,omitempty"`
+	Status                            string          `json:"status"`
+	BrokerID                          int32           `json:"brokerId"`
+	BrokerRegistered                  bool            `json:"brokerRegistered"`
+	ClusterIDMatches                  *bool           `json:"clusterIdMatches,omitempty"`
+	BrokerEpochHealthy                *bool           `json:"brokerEpochHealthy,omitempty"`
+	BrokerIDConflictHost              string          `json:"brokerIdConflictHost,omitempty"`
+	ControllerElected                 bool            `json:"controllerElected"`
+	UnderReplicatedPartitions         int             `json:"underReplicatedPartitions"`
+	ExcludedUnderReplicatedPartitions int             `json:"excludedUnderReplicatedPartitions,omitempty"`
+	LogDirsHealthy                    bool            `json:"logDirsHealthy"`
+	CanaryHealthy                     *bool           `json:"canaryHealthy,omitempty"`
+	CertsHealthy                      *bool           `json:"certsHealthy,omitempty"`
+	DisksHealthy                      *bool           `json:"disksHealthy,omitempty"`
+	FileDescriptors                   *procfs.FDUsage `json:"fileDescriptors,omitempty"`
+	Warnings                          []string        `json:"warnings,omitempty"`
+	ErrorMessage                      string          `json:"error,omitempty"`
 }
 
 // ReadinessHandler handles GET /health/ready requests
 func (c *Checker) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	start := c.clock.Now()
+	ctx, span := c.startSpan(r.Context(), "health."+ProbeReadiness)
+	failed := c.readiness(w, r.WithContext(ctx))
+	// A malformed request says nothing about the broker
+	if failed != CheckRequest {
+		c.recordResult(&c.results.Ready, failed == "")
+	}
+	c.finishProbe(span, ProbeReadiness, start, failed)
+}
+
+// readiness writes the readiness response and returns the check that failed,
+// or "" when the broker is ready
+func (c *Checker) readiness(w http.ResponseWriter, r *http.Request) string {
+	ctx, cancel, err := c.requestContext(r)
+	if err != nil {
+		_, _ = web.ReturnError(w, err)
+		return CheckRequest
+	}
+	defer cancel()
 
 	response := ReadinessResponse{
 		BrokerID: c.brokerID,
 	}
 
-	adm, cleanup, err := c.clientFactory()
+	adm, cleanup, err := c.adminClient()
 	if err != nil {
-		c.logger.Error("failed to create kafka client", "error", err)
+		c.logger.ErrorContext(ctx, "failed to create kafka client", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckClient
 	}
 	defer cleanup()
 
+	if c.clusterOnly {
+		return c.clusterReadiness(ctx, w, adm, response)
+	}
+
 	// Check 1: Broker registered in cluster metadata
 	brokerRegistered, err := c.BrokerInMetadata(ctx, adm)
+	if (err != nil || !brokerRegistered) && c.ClusterForming(ctx, adm) {
+		c.logger.InfoContext(ctx, "cluster forming, broker not yet registered", "brokerId", c.brokerID)
+		response.Status = "forming"
+		response.ErrorMessage = c.formingMessage()
+		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+		return CheckForming
+	}
 	if err != nil {
-		c.logger.Error("failed to check broker in metadata", "error", err)
+		c.logger.ErrorContext(ctx, "failed to check broker in metadata", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckBrokerRegistered
 	}
 	response.BrokerRegistered = brokerRegistered
 
+	// The cluster ID (when verified) comes first: a broker attached to another
+	// cluster's volume never registers
+	if c.clusterIDReadiness(ctx, w, adm, &response) {
+		return CheckClusterID
+	}
+	// A fenced broker drops out of the metadata, so the epoch check comes
+	// before the registration one to say why
+	if c.epochReadiness(ctx, w, &response) {
+		return CheckBrokerEpoch
+	}
+
 	if !brokerRegistered {
-		c.logger.Warn("broker not registered in cluster metadata", "brokerId", c.brokerID)
+		c.logger.WarnContext(ctx, "broker not registered in cluster metadata", "brokerId", c.brokerID)
 		response.Status = "unhealthy"
 		response.ErrorMessage = "broker not registered in cluster metadata"
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckBrokerRegistered
+	}
+	if c.brokerIDConflictReadiness(ctx, w, adm, &response) {
+		return CheckBrokerIDConflict
 	}
 
 	// Check 2: Controller elected
 	controllerElected, err := c.ControllerElected(ctx, adm)
 	if err != nil {
-		c.logger.Error("failed to check controller election", "error", err)
+		c.logger.ErrorContext(ctx, "failed to check controller election", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckController
 	}
 	response.ControllerElected = controllerElected
 
 	if !controllerElected {
-		c.logger.Warn("no controller elected")
+		c.logger.WarnContext(ctx, "no controller elected")
 		response.Status = "unhealthy"
 		response.ErrorMessage = "no controller elected"
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckController
 	}
 
-	// Check 3: Zero under-replicated partitions
-	underReplicated, err := c.UnderReplicatedPartitions(ctx, adm)
+	// Check 3: Zero under-replicated partitions (outside excluded topics)
+	urp, err := c.CountUnderReplicated(ctx, adm)
 	if err != nil {
-		c.logger.Error("failed to check under-replicated partitions", "error", err)
+		c.logger.ErrorContext(ctx, "failed to check under-replicated partitions", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckURP
 	}
+	underReplicated := urp.Counted
 	response.UnderReplicatedPartitions = underReplicated
+	response.ExcludedUnderReplicatedPartitions = urp.Excluded
 
 	if underReplicated > 0 {
-		c.logger.Warn("broker has under-replicated partitions",
+		c.logger.WarnContext(ctx, "broker has under-replicated partitions",
 			"brokerId", c.brokerID,
 			"count", underReplicated)
 		response.Status = "unhealthy"
 		response.ErrorMessage = "broker has under-replicated partitions"
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckURP
 	}
 
 	// Check 4: Log directories healthy
 	logDirsHealthy, err := c.LogDirsHealthy(ctx, adm)
 	if err != nil {
-		c.logger.Error("failed to check log directories", "error", err)
+		c.logger.ErrorContext(ctx, "failed to check log directories", "error", err)
 		response.Status = "unhealthy"
 		response.ErrorMessage = err.Error()
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
-		return
+		return CheckLogDirs
 	}
 	response.LogDirsHealthy = logDirsHealthy
 
 	if !logDirsHealthy {
-		c.logger.Warn("log directories unhealthy", "brokerId", c.brokerID)
+		c.logger.WarnContext(ctx, "log directories unhealthy", "brokerId", c.brokerID)
 		response.Status = "unhealthy"
 		response.ErrorMessage = "log directories unhealthy (future partitions detected)"
 		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+		return CheckLogDirs
+	}
+
+	// Check 5: Canary produce/consume (when enabled)
+	if c.canary != nil {
+		canaryErr := c.canary.CanaryError()
+		canaryHealthy := canaryErr == nil
+		response.CanaryHealthy = &canaryHealthy
+
+		if canaryErr != nil {
+			c.logger.WarnContext(ctx, "canary produce/consume failed", "brokerId", c.brokerID, "error", canaryErr)
+			response.Status = "unhealthy"
+			response.ErrorMessage = canaryFailedMessage + ": " + canaryErr.Error()
+			_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+			return CheckCanary
+		}
+	}
+
+	// Check 6: TLS certificate expiry (when enabled)
+	if c.certReadiness(ctx, w, &response) {
+		return CheckCerts
+	}
+
+	// Check 7: Data volume usage (when enabled)
+	if c.diskReadiness(ctx, w, &response) {
+		return CheckDisks
+	}
+
+	// Check 8: File descriptor headroom (degrades, but does not fail readiness)
+	c.passReadiness(ctx, w, response)
+	return ""
+}
+
+// clusterReadiness reports readiness for cluster-only roles: the cluster is
+// reachable and has an elected controller. It returns the check that failed.
+func (c *Checker) clusterReadiness(ctx context.Context, w http.ResponseWriter, adm KafkaAdminClient, response ReadinessResponse) string {
+	controllerElected, err := c.ControllerElected(ctx, adm)
+	if (err != nil || !controllerElected) && c.ClusterForming(ctx, adm) {
+		response.Status = "forming"
+		response.ErrorMessage = c.formingMessage()
+		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+		return CheckForming
+	}
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to reach kafka cluster", "error", err)
+		response.Status = "unhealthy"
+		response.ErrorMessage = err.Error()
+		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+		return CheckController
+	}
+	response.ControllerElected = controllerElected
+
+	if !controllerElected {
+		c.logger.WarnContext(ctx, "no controller elected")
+		response.Status = "unhealthy"
+		response.ErrorMessage = "no controller elected"
+		_, _ = web.ReturnResponseWithCode(w, response, http.StatusServiceUnavailable)
+		return CheckController
+	}
+
+	if c.certReadiness(ctx, w, &response) {
+		return CheckCerts
+	}
+	if c.diskReadiness(ctx, w, &response) {
+		return CheckDisks
+	}
+
+	c.passReadiness(ctx, w, response)
+	return ""
+}
+
+// clusterIDReadiness records whether the brokers report the expected cluster
+// ID, and writes a failed response and returns true when they do not
+func (c *Checker) clusterIDReadiness(ctx context.Context, w http.ResponseWriter, adm KafkaAdminClient, response *ReadinessResponse) bool {
+	if c.clusterID == nil {
+		return false
+	}
+	clusterIDErr := c.VerifyClusterID(ctx, adm)
+	matches := clusterIDErr == nil
+	response.ClusterIDMatches = &matches
+	if clusterIDErr == nil {
+		return false
+	}
+
+	c.logger.ErrorContext(ctx, "cluster ID mismatch", "brokerId", c.brokerID, "error", clusterIDErr)
+	response.Status = "unhealthy"
+	response.ErrorMessage = clusterIDMessage + ": " + clusterIDErr.Error()
+	_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+	return true
+}
+
+// epochReadiness records whether the local broker's registration is healthy,
+// and writes a failed response and returns true when it is not
+func (c *Checker) epochReadiness(ctx context.Context, w http.ResponseWriter, response *ReadinessResponse) bool {
+	if c.epoch == nil {
+		return false
+	}
+	epochErr := c.epoch.EpochError()
+	epochHealthy := epochErr == nil
+	response.BrokerEpochHealthy = &epochHealthy
+	if epochErr == nil {
+		return false
+	}
+
+	c.logger.ErrorContext(ctx, "broker epoch unhealthy", "brokerId", c.brokerID, "error", epochErr)
+	response.Status = "unhealthy"
+	response.ErrorMessage = epochMessage + ": " + epochErr.Error()
+	_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+	return true
+}
+
+// brokerIDConflictReadiness writes a failed response and returns true when the
+// broker's ID is registered from another host
+func (c *Checker) brokerIDConflictReadiness(ctx context.Context, w http.ResponseWriter, adm KafkaAdminClient, response *ReadinessResponse) bool {
+	if c.advertisedHosts == nil {
+		return false
+	}
+	host, err := c.BrokerIDConflict(ctx, adm)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to check broker ID conflicts", "error", err)
+		response.Status = "unhealthy"
+		response.ErrorMessage = err.Error()
+		_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+		return true
+	}
+	if host == "" {
+		return false
+	}
+
+	c.logger.ErrorContext(ctx, "broker ID registered from another host", "brokerId", c.brokerID, "host", host)
+	response.Status = "unhealthy"
+	response.BrokerIDConflictHost = host
+	response.ErrorMessage = idConflictMessage + ": " + host
+	_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+	return true
+}
+
+// certReadiness records whether the TLS certificates are valid for long enough,
+// and writes a failed response and returns true when one is not
+func (c *Checker) certReadiness(ctx context.Context, w http.ResponseWriter, response *ReadinessResponse) bool {
+	if c.certs == nil {
+		return false
+	}
+	certErr := c.certs.CertError()
+	certsHealthy := certErr == nil
+	response.CertsHealthy = &certsHealthy
+	if certErr == nil {
+		return false
+	}
+
+	c.logger.WarnContext(ctx, "tls certificate expiring", "brokerId", c.brokerID, "error", certErr)
+	response.Status = "unhealthy"
+	response.ErrorMessage = certExpiringMessage + ": " + certErr.Error()
+	_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+	return true
+}
+
+// diskReadiness records whether the data volumes have room left, and writes a
+// failed response and returns true when one is above the usage threshold
+func (c *Checker) diskReadiness(ctx context.Context, w http.ResponseWriter, response *ReadinessResponse) bool {
+	if c.disks == nil {
+		return false
+	}
+	diskErr := c.disks.DiskError()
+	disksHealthy := diskErr == nil
+	response.DisksHealthy = &disksHealthy
+	if diskErr == nil {
+		return false
+	}
+
+	c.logger.WarnContext(ctx, "data volume usage above threshold", "brokerId", c.brokerID, "error", diskErr)
+	response.Status = "unhealthy"
+	response.ErrorMessage = diskFullMessage + ": " + diskErr.Error()
+	_, _ = web.ReturnResponseWithCode(w, *response, http.StatusServiceUnavailable)
+	return true
+}
+
+// passReadiness finishes a passing readiness response, degrading it when the
+// node's file descriptor headroom is low or clients get too many errors
+func (c *Checker) passReadiness(ctx context.Context, w http.ResponseWriter, response ReadinessResponse) {
+	if usage, ok := c.BrokerFDUsage(); ok {
+		response.FileDescriptors = &usage
+		if c.FDHeadroomLow(usage) {
+			c.logger.WarnContext(ctx, "broker file descriptor headroom low",
+				"brokerId", c.brokerID,
+				"used", usage.Used,
+				"limit", usage.Limit)
+			response.Warnings = append(response.Warnings, fdHeadroomWarning)
+		}
+	}
+	if err := c.requestErrorRate(); err != nil {
+		c.logger.WarnContext(ctx, "broker request error ratio above threshold", "brokerId", c.brokerID, "error", err)
+		response.Warnings = append(response.Warnings, requestErrorWarning+": "+err.Error())
+	}
+	if len(response.Warnings) > 0 {
+		response.Status = "degraded"
+		_, _ = web.ReturnResponse(w, response)
 		return
 	}
 
 	response.Status = "healthy"
+	if c.InStandby() {
+		response.Status = "standby-ready"
+	}
 	_, _ = web.ReturnResponse(w, response)
 }
 
 // CheckReadiness performs a full readiness check and returns the result
 func (c *Checker) CheckReadiness(ctx context.Context) CheckResult {
-	adm, cleanup, err := c.clientFactory()
+	ctx, span := c.startSpan(ctx, "health."+ProbeReadiness)
+	result := c.checkReadiness(ctx)
+	if !result.Healthy {
+		span.SetStatus(codes.Error, result.Message)
+	}
+	span.End()
+	c.recordResult(&c.results.Ready, result.Healthy)
+	return result
+}
+
+// checkReadiness runs the readiness checks in the order of the handler
+func (c *Checker) checkReadiness(ctx context.Context) CheckResult {
+	adm, cleanup, err := c.adminClient()
 	if err != nil {
 		return CheckResult{
 			Healthy: false,
@@ -129,14 +409,46 @@ func (c *Checker) CheckReadiness(ctx context.Context) CheckResult {
 	}
 	defer cleanup()
 
+	if c.clusterOnly {
+		controllerElected, err := c.ControllerElected(ctx, adm)
+		if (err != nil || !controllerElected) && c.ClusterForming(ctx, adm) {
+			return CheckResult{Healthy: false, Forming: true, Message: c.formingMessage()}
+		}
+		if err != nil {
+			return CheckResult{Healthy: false, Message: err.Error()}
+		}
+		if !controllerElected {
+			return CheckResult{Healthy: false, Message: "no controller elected"}
+		}
+		if result, failed := c.certResult(); failed {
+			return result
+		}
+		if result, failed := c.diskResult(); failed {
+			return result
+		}
+		return c.passResult()
+	}
+
 	// Check 1: Broker registered
 	brokerRegistered, err := c.BrokerInMetadata(ctx, adm)
+	if (err != nil || !brokerRegistered) && c.ClusterForming(ctx, adm) {
+		return CheckResult{Healthy: false, Forming: true, Message: c.formingMessage()}
+	}
 	if err != nil {
 		return CheckResult{Healthy: false, Message: err.Error()}
 	}
+	if result, failed := c.clusterIDResult(ctx, adm); failed {
+		return result
+	}
+	if result, failed := c.epochResult(); failed {
+		return result
+	}
 	if !brokerRegistered {
 		return CheckResult{Healthy: false, Message: "broker not registered in cluster metadata"}
 	}
+	if result, failed := c.brokerIDConflictResult(ctx, adm); failed {
+		return result
+	}
 
 	// Check 2: Controller elected
 	controllerElected, err := c.ControllerElected(ctx, adm)
@@ -147,7 +459,7 @@ func (c *Checker) CheckReadiness(ctx context.Context) CheckResult {
 		return CheckResult{Healthy: false, Message: "no controller elected"}
 	}
 
-	// Check 3: No under-replicated partitions
+	// Check 3: No under-replicated partitions (outside excluded topics)
 	underReplicated, err := c.UnderReplicatedPartitions(ctx, adm)
 	if err != nil {
 		return CheckResult{Healthy: false, Message: err.Error()}
@@ -165,5 +477,105 @@ func (c *Checker) CheckReadiness(ctx context.Context) CheckResult {
 		return CheckResult{Healthy: false, Message: "log directories unhealthy"}
 	}
 
-	return CheckResult{Healthy: true}
+	// Check 5: Canary produce/consume (when enabled)
+	if c.canary != nil {
+		if err := c.canary.CanaryError(); err != nil {
+			return CheckResult{Healthy: false, Message: canaryFailedMessage + ": " + err.Error()}
+		}
+	}
+
+	// Check 6: TLS certificate expiry (when enabled)
+	if result, failed := c.certResult(); failed {
+		return result
+	}
+
+	// Check 7: Data volume usage (when enabled)
+	if result, failed := c.diskResult(); failed {
+		return result
+	}
+
+	// Check 8: File descriptor headroom
+	return c.passResult()
+}
+
+// clusterIDResult returns a failed result when the brokers report another
+// cluster ID than the expected one
+func (c *Checker) clusterIDResult(ctx context.Context, adm KafkaAdminClient) (CheckResult, bool) {
+	if c.clusterID == nil {
+		return CheckResult{}, false
+	}
+	if err := c.VerifyClusterID(ctx, adm); err != nil {
+		return CheckResult{Healthy: false, Message: clusterIDMessage + ": " + err.Error()}, true
+	}
+	return CheckResult{}, false
+}
+
+// epochResult returns a failed result when the local broker's epoch regressed
+// or the controller fenced it
+func (c *Checker) epochResult() (CheckResult, bool) {
+	if c.epoch == nil {
+		return CheckResult{}, false
+	}
+	if err := c.epoch.EpochError(); err != nil {
+		return CheckResult{Healthy: false, Message: epochMessage + ": " + err.Error()}, true
+	}
+	return CheckResult{}, false
+}
+
+// brokerIDConflictResult returns a failed result when the broker's ID is
+// registered from another host
+func (c *Checker) brokerIDConflictResult(ctx context.Context, adm KafkaAdminClient) (CheckResult, bool) {
+	if c.advertisedHosts == nil {
+		return CheckResult{}, false
+	}
+	host, err := c.BrokerIDConflict(ctx, adm)
+	if err != nil {
+		return CheckResult{Healthy: false, Message: err.Error()}, true
+	}
+	if host != "" {
+		return CheckResult{Healthy: false, Message: idConflictMessage + ": " + host}, true
+	}
+	return CheckResult{}, false
+}
+
+// certResult returns a failed result when a TLS certificate expires too soon
+func (c *Checker) certResult() (CheckResult, bool) {
+	if c.certs == nil {
+		return CheckResult{}, false
+	}
+	if err := c.certs.CertError(); err != nil {
+		return CheckResult{Healthy: false, Message: certExpiringMessage + ": " + err.Error()}, true
+	}
+	return CheckResult{}, false
+}
+
+// diskResult returns a failed result when a data volume is too full
+func (c *Checker) diskResult() (CheckResult, bool) {
+	if c.disks == nil {
+		return CheckResult{}, false
+	}
+	if err := c.disks.DiskError(); err != nil {
+		return CheckResult{Healthy: false, Message: diskFullMessage + ": " + err.Error()}, true
+	}
+	return CheckResult{}, false
+}
+
+// passResult returns a passing result, degraded when file descriptor headroom
+// is low or clients get too many errors
+func (c *Checker) passResult() CheckResult {
+	if usage, ok := c.BrokerFDUsage(); ok && c.FDHeadroomLow(usage) {
+		return CheckResult{Healthy: true, Degraded: true, Standby: c.InStandby(), Message: fdHeadroomWarning}
+	}
+	if err := c.requestErrorRate(); err != nil {
+		return CheckResult{Healthy: true, Degraded: true, Standby: c.InStandby(), Message: requestErrorWarning + ": " + err.Error()}
+	}
+	return CheckResult{Healthy: true, Standby: c.InStandby()}
+}
+
+// requestErrorRate returns the request types above their error ratio threshold
+func (c *Checker) requestErrorRate() error {
+	if c.requestErrors == nil {
+		return nil
+	}
+	return c.requestErrors.ErrorRateError()
 }
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
 )
 
 func TestReadinessHandler(t *testing.T) {
@@ -547,3 +551,943 @@ func TestReadinessResponseWithError(t *testing.T) {
 		t.Error("expected 'error' field in JSON when ErrorMessage is set")
 	}
 }
+
+// MockFDUsageReader is a mock implementation of procfs.FDUsageReader for testing
+type MockFDUsageReader struct {
+	Usage procfs.FDUsage
+	Err   error
+}
+
+func (m *MockFDUsageReader) FDUsage() (procfs.FDUsage, error) {
+	return m.Usage, m.Err
+}
+
+func TestReadinessFDHeadroom(t *testing.T) {
+	healthyFactory := func() (KafkaAdminClient, func(), error) {
+		return &MockKafkaAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return kadm.Metadata{
+					Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+					Controller: 0,
+				}, nil
+			},
+		}, func() {}, nil
+	}
+
+	tests := []struct {
+		name           string
+		reader         *MockFDUsageReader
+		expectedStatus string
+		expectDegraded bool
+		expectFDs      bool
+	}{
+		{
+			name:           "plenty of headroom",
+			reader:         &MockFDUsageReader{Usage: procfs.FDUsage{Used: 100, Limit: 1000}},
+			expectedStatus: "healthy",
+			expectFDs:      true,
+		},
+		{
+			name:           "headroom below threshold",
+			reader:         &MockFDUsageReader{Usage: procfs.FDUsage{Used: 950, Limit: 1000}},
+			expectedStatus: "degraded",
+			expectDegraded: true,
+			expectFDs:      true,
+		},
+		{
+			name:           "no proc access",
+			reader:         &MockFDUsageReader{Err: procfs.ErrProcessNotFound},
+			expectedStatus: "healthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetClientFactory(healthyFactory)
+			checker.SetFDUsageReader(tt.reader, 0.1)
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			// Degraded still serves traffic
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if (response.FileDescriptors != nil) != tt.expectFDs {
+				t.Errorf("expected fileDescriptors present=%v, got %+v", tt.expectFDs, response.FileDescriptors)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if !result.Healthy {
+				t.Errorf("expected healthy result, got %+v", result)
+			}
+			if result.Degraded != tt.expectDegraded {
+				t.Errorf("expected degraded=%v, got %v", tt.expectDegraded, result.Degraded)
+			}
+		})
+	}
+}
+
+func TestReadinessFormationGrace(t *testing.T) {
+	tests := []struct {
+		name           string
+		grace          time.Duration
+		startedAgo     time.Duration
+		brokers        []kadm.BrokerDetail
+		metadataErr    error
+		expectedStatus string
+		expectForming  bool
+	}{
+		{
+			name:           "no brokers reachable within grace",
+			grace:          5 * time.Minute,
+			startedAgo:     time.Minute,
+			metadataErr:    errors.New("unable to dial"),
+			expectedStatus: "forming",
+			expectForming:  true,
+		},
+		{
+			name:           "no brokers registered within grace",
+			grace:          5 * time.Minute,
+			startedAgo:     time.Minute,
+			expectedStatus: "forming",
+			expectForming:  true,
+		},
+		{
+			name:           "grace expired",
+			grace:          5 * time.Minute,
+			startedAgo:     10 * time.Minute,
+			metadataErr:    errors.New("unable to dial"),
+			expectedStatus: "unhealthy",
+		},
+		{
+			name:           "grace disabled",
+			startedAgo:     time.Second,
+			expectedStatus: "unhealthy",
+		},
+		{
+			name:           "other brokers registered",
+			grace:          5 * time.Minute,
+			startedAgo:     time.Minute,
+			brokers:        []kadm.BrokerDetail{{NodeID: 1}},
+			expectedStatus: "unhealthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetFormationGrace(tt.grace)
+			clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			checker.SetClock(clk)
+			clk.Advance(tt.startedAgo)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{Brokers: tt.brokers, Controller: -1}, tt.metadataErr
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Forming != tt.expectForming {
+				t.Errorf("expected forming=%v, got %+v", tt.expectForming, result)
+			}
+		})
+	}
+}
+
+func TestReadinessClusterOnly(t *testing.T) {
+	tests := []struct {
+		name           string
+		controller     int32
+		metadataErr    error
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "controller elected",
+			controller:     1,
+			expectedCode:   http.StatusOK,
+			expectedStatus: "healthy",
+		},
+		{
+			name:           "no controller",
+			controller:     -1,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "unhealthy",
+		},
+		{
+			name:           "cluster unreachable",
+			metadataErr:    errors.New("unable to dial"),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "unhealthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Broker 7 is not registered: cluster-only roles must not require it
+			checker := NewChecker(7, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetClusterOnly(true)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}, {NodeID: 1}},
+							Controller: tt.controller,
+						}, tt.metadataErr
+					},
+					DescribeBrokerLogDirsFunc: func(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+						t.Error("log dirs must not be checked for cluster-only roles")
+						return kadm.DescribedLogDirs{}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != (tt.expectedCode == http.StatusOK) {
+				t.Errorf("expected healthy=%v, got %+v", tt.expectedCode == http.StatusOK, result)
+			}
+		})
+	}
+}
+
+// MockStandbyReporter is a mock implementation of StandbyReporter for testing
+type MockStandbyReporter struct {
+	Standby bool
+}
+
+func (m *MockStandbyReporter) InStandby() bool {
+	return m.Standby
+}
+
+func TestReadinessStandby(t *testing.T) {
+	tests := []struct {
+		name           string
+		standby        bool
+		expectedStatus string
+	}{
+		{name: "in standby", standby: true, expectedStatus: "standby-ready"},
+		{name: "promoted", standby: false, expectedStatus: "healthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetStandby(&MockStandbyReporter{Standby: tt.standby})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if !result.Healthy || result.Standby != tt.standby {
+				t.Errorf("expected healthy result with standby=%v, got %+v", tt.standby, result)
+			}
+		})
+	}
+}
+
+// MockCanaryReporter is a mock implementation of CanaryReporter for testing
+type MockCanaryReporter struct {
+	Err error
+}
+
+func (m *MockCanaryReporter) CanaryError() error {
+	return m.Err
+}
+
+func TestReadinessCanary(t *testing.T) {
+	tests := []struct {
+		name           string
+		canaryErr      error
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "canary passing", expectedCode: http.StatusOK, expectedStatus: "healthy"},
+		{name: "canary failing", canaryErr: errors.New("NOT_ENOUGH_REPLICAS"), expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetCanary(&MockCanaryReporter{Err: tt.canaryErr})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if response.CanaryHealthy == nil || *response.CanaryHealthy != (tt.canaryErr == nil) {
+				t.Errorf("expected canaryHealthy=%v, got %v", tt.canaryErr == nil, response.CanaryHealthy)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != (tt.canaryErr == nil) {
+				t.Errorf("expected healthy=%v, got %+v", tt.canaryErr == nil, result)
+			}
+		})
+	}
+}
+
+// MockCertReporter is a mock implementation of CertReporter for testing
+type MockCertReporter struct {
+	Err error
+}
+
+func (m *MockCertReporter) CertError() error {
+	return m.Err
+}
+
+func TestReadinessCerts(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterOnly    bool
+		certErr        error
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "certificates valid", expectedCode: http.StatusOK, expectedStatus: "healthy"},
+		{name: "certificate expiring", certErr: errors.New("client certificate CN=sidecar expires soon"), expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+		{name: "cluster only expiring", clusterOnly: true, certErr: errors.New("client certificate CN=sidecar expires soon"), expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetClusterOnly(tt.clusterOnly)
+			checker.SetCerts(&MockCertReporter{Err: tt.certErr})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if response.CertsHealthy == nil || *response.CertsHealthy != (tt.certErr == nil) {
+				t.Errorf("expected certsHealthy=%v, got %v", tt.certErr == nil, response.CertsHealthy)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != (tt.certErr == nil) {
+				t.Errorf("expected healthy=%v, got %+v", tt.certErr == nil, result)
+			}
+		})
+	}
+}
+
+func TestReadinessURPTopicFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		exclude        string
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "scratch topic blocks readiness", expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+		{name: "excluded scratch topic", exclude: "scratch-.*", expectedCode: http.StatusOK, expectedStatus: "healthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewTopicFilter("", tt.exclude)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetURPTopicFilter(filter)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+							Topics: kadm.TopicDetails{
+								"scratch-1": kadm.TopicDetail{
+									Partitions: kadm.PartitionDetails{
+										0: {Partition: 0, Replicas: []int32{0, 1}, ISR: []int32{1}},
+									},
+								},
+							},
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if tt.exclude != "" && response.ExcludedUnderReplicatedPartitions != 1 {
+				t.Errorf("expected 1 excluded under-replicated partition, got %d", response.ExcludedUnderReplicatedPartitions)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != (tt.expectedCode == http.StatusOK) {
+				t.Errorf("unexpected readiness result %+v", result)
+			}
+		})
+	}
+}
+
+// MockDiskReporter is a mock implementation of DiskReporter for testing
+type MockDiskReporter struct {
+	Err error
+}
+
+func (m *MockDiskReporter) DiskError() error {
+	return m.Err
+}
+
+func TestReadinessDisks(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterOnly    bool
+		diskErr        error
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "volumes have room", expectedCode: http.StatusOK, expectedStatus: "healthy"},
+		{name: "volume too full", diskErr: errors.New("/var/lib/kafka is 96.0% used"), expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+		{name: "cluster only volume too full", clusterOnly: true, diskErr: errors.New("/var/lib/kafka is 96.0% used"), expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetClusterOnly(tt.clusterOnly)
+			checker.SetDisks(&MockDiskReporter{Err: tt.diskErr})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if response.DisksHealthy == nil || *response.DisksHealthy != (tt.diskErr == nil) {
+				t.Errorf("expected disksHealthy=%v, got %v", tt.diskErr == nil, response.DisksHealthy)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != (tt.diskErr == nil) {
+				t.Errorf("expected healthy=%v, got %+v", tt.diskErr == nil, result)
+			}
+		})
+	}
+}
+
+// MockClusterIDVerifier is a mock implementation of ClusterIDVerifier for testing
+type MockClusterIDVerifier struct {
+	Expected string
+}
+
+func (m *MockClusterIDVerifier) VerifyClusterID(reported string) error {
+	if reported != m.Expected {
+		return errors.New("the broker reports cluster " + reported + ", expected " + m.Expected)
+	}
+	return nil
+}
+
+func TestReadinessClusterID(t *testing.T) {
+	tests := []struct {
+		name           string
+		reported       string
+		registered     bool
+		expectedCode   int
+		expectedStatus string
+	}{
+		{name: "expected cluster", reported: "MkU3OEVBNTcwNTJENDM2Qg", registered: true, expectedCode: http.StatusOK, expectedStatus: "healthy"},
+		{name: "another cluster", reported: "AAAAAAAAAAAAAAAAAAAAAA", registered: true, expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+		{name: "another cluster before registration", reported: "AAAAAAAAAAAAAAAAAAAAAA", expectedCode: http.StatusServiceUnavailable, expectedStatus: "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetClusterID(&MockClusterIDVerifier{Expected: "MkU3OEVBNTcwNTJENDM2Qg"})
+			observer := &recordingProbeObserver{}
+			checker.SetProbeObserver(observer)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						metadata := kadm.Metadata{Cluster: tt.reported, Controller: 1, Brokers: []kadm.BrokerDetail{{NodeID: 1}}}
+						if tt.registered {
+							metadata.Brokers = append(metadata.Brokers, kadm.BrokerDetail{NodeID: 0})
+						}
+						return metadata, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			matches := tt.expectedCode == http.StatusOK
+			if response.ClusterIDMatches == nil || *response.ClusterIDMatches != matches {
+				t.Errorf("expected clusterIdMatches=%v, got %v", matches, response.ClusterIDMatches)
+			}
+			if !matches && (len(observer.failed) != 1 || observer.failed[0] != CheckClusterID) {
+				t.Errorf("expected the probe to fail on %s, got %v", CheckClusterID, observer.failed)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != matches {
+				t.Errorf("expected healthy=%v, got %+v", matches, result)
+			}
+		})
+	}
+}
+
+func TestReadinessBrokerIDConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		conflict bool
+	}{
+		{name: "own host", host: "kafka-0.kafka.abc123.svc.cluster.local"},
+		{name: "own host fully qualified", host: "Kafka-0.kafka.abc123.svc.cluster.local."},
+		{name: "own external host", host: "kafka-0.example.com"},
+		{name: "another host", host: "kafka-3.kafka.abc123.svc.cluster.local", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetAdvertisedHosts([]string{"kafka-0.kafka.abc123.svc.cluster.local", "kafka-0.example.com"})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0, Host: tt.host}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if tt.conflict {
+				if w.Code != http.StatusServiceUnavailable || response.BrokerIDConflictHost != tt.host {
+					t.Errorf("expected a conflict with %s, got %d %+v", tt.host, w.Code, response)
+				}
+			} else if w.Code != http.StatusOK || response.BrokerIDConflictHost != "" {
+				t.Errorf("expected no conflict, got %d %+v", w.Code, response)
+			}
+
+			results := checker.LastResults()
+			if results.BrokerIDConflict == nil || *results.BrokerIDConflict != tt.conflict {
+				t.Errorf("expected conflict result %v, got %v", tt.conflict, results.BrokerIDConflict)
+			}
+			if result := checker.CheckReadiness(context.Background()); result.Healthy == tt.conflict {
+				t.Errorf("expected healthy=%v, got %+v", !tt.conflict, result)
+			}
+		})
+	}
+}
+
+// MockEpochReporter is a mock implementation of EpochReporter for testing
+type MockEpochReporter struct {
+	Err error
+}
+
+func (m *MockEpochReporter) EpochError() error {
+	return m.Err
+}
+
+func TestReadinessBrokerEpoch(t *testing.T) {
+	tests := []struct {
+		name       string
+		epochErr   error
+		registered bool
+	}{
+		{name: "healthy registration", registered: true},
+		{name: "epoch regressed", epochErr: errors.New("broker epoch regressed from 12 to 8"), registered: true},
+		// A fenced broker drops out of the metadata
+		{name: "fenced", epochErr: errors.New("broker 0 is fenced by the controller at epoch 12")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetEpoch(&MockEpochReporter{Err: tt.epochErr})
+			observer := &recordingProbeObserver{}
+			checker.SetProbeObserver(observer)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						metadata := kadm.Metadata{Controller: 1, Brokers: []kadm.BrokerDetail{{NodeID: 1}}}
+						if tt.registered {
+							metadata.Brokers = append(metadata.Brokers, kadm.BrokerDetail{NodeID: 0})
+						}
+						return metadata, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			healthy := tt.epochErr == nil
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.BrokerEpochHealthy == nil || *response.BrokerEpochHealthy != healthy {
+				t.Errorf("expected brokerEpochHealthy=%v, got %v", healthy, response.BrokerEpochHealthy)
+			}
+			if healthy {
+				if w.Code != http.StatusOK {
+					t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+				}
+			} else {
+				if w.Code != http.StatusServiceUnavailable || !strings.Contains(response.ErrorMessage, tt.epochErr.Error()) {
+					t.Errorf("expected the epoch error, got %d %+v", w.Code, response)
+				}
+				if len(observer.failed) != 1 || observer.failed[0] != CheckBrokerEpoch {
+					t.Errorf("expected the probe to fail on %s, got %v", CheckBrokerEpoch, observer.failed)
+				}
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if result.Healthy != healthy || (!healthy && !strings.Contains(result.Message, epochMessage)) {
+				t.Errorf("expected healthy=%v, got %+v", healthy, result)
+			}
+		})
+	}
+}
+
+// MockRequestErrorReporter is a mock implementation of RequestErrorReporter for testing
+type MockRequestErrorReporter struct {
+	Err error
+}
+
+func (m *MockRequestErrorReporter) ErrorRateError() error {
+	return m.Err
+}
+
+func TestReadinessRequestErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus string
+	}{
+		{name: "error ratio below threshold", expectedStatus: "healthy"},
+		{name: "error ratio above threshold", err: errors.New("Produce 12.5%"), expectedStatus: "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetRequestErrors(&MockRequestErrorReporter{Err: tt.err})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+						}, nil
+					},
+				}, func() {}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+			checker.ReadinessHandler(w, req)
+
+			// Degraded still serves traffic
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, response.Status)
+			}
+			if tt.err != nil && (len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "Produce 12.5%")) {
+				t.Errorf("expected the error ratio warning, got %v", response.Warnings)
+			}
+
+			result := checker.CheckReadiness(context.Background())
+			if !result.Healthy || result.Degraded != (tt.err != nil) {
+				t.Errorf("expected healthy with degraded=%v, got %+v", tt.err != nil, result)
+			}
+		})
+	}
+}
+
+// recordingProbeObserver collects the probes it observes
+type recordingProbeObserver struct {
+	probes []string
+	failed []string
+}
+
+func (o *recordingProbeObserver) ObserveProbe(probe string, _ time.Duration, failedCheck string) {
+	o.probes = append(o.probes, probe)
+	o.failed = append(o.failed, failedCheck)
+}
+
+func TestProbeObserver(t *testing.T) {
+	tests := []struct {
+		name       string
+		urp        bool
+		canaryErr  error
+		clientErr  error
+		wantFailed string
+	}{
+		{name: "passing"},
+		{name: "client error", clientErr: errors.New("connection refused"), wantFailed: CheckClient},
+		{name: "under-replicated", urp: true, wantFailed: CheckURP},
+		{name: "canary failing", canaryErr: errors.New("NOT_ENOUGH_REPLICAS"), wantFailed: CheckCanary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := &recordingProbeObserver{}
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetProbeObserver(observer)
+			checker.SetCanary(&MockCanaryReporter{Err: tt.canaryErr})
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				if tt.clientErr != nil {
+					return nil, nil, tt.clientErr
+				}
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						metadata := kadm.Metadata{
+							Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+							Controller: 0,
+							Topics:     kadm.TopicDetails{},
+						}
+						if tt.urp {
+							metadata.Topics["orders"] = kadm.TopicDetail{
+								Topic: "orders",
+								Partitions: kadm.PartitionDetails{
+									0: {Partition: 0, Leader: 1, Replicas: []int32{0, 1}, ISR: []int32{1}},
+								},
+							}
+						}
+						return metadata, nil
+					},
+				}, func() {}, nil
+			})
+
+			checker.LivenessHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/live", nil))
+			checker.ReadinessHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			if len(observer.probes) != 2 || observer.probes[0] != ProbeLiveness || observer.probes[1] != ProbeReadiness {
+				t.Fatalf("expected a liveness and a readiness probe, got %v", observer.probes)
+			}
+			// Only the client error fails liveness too
+			wantLive := ""
+			if tt.clientErr != nil {
+				wantLive = CheckClient
+			}
+			if observer.failed[0] != wantLive {
+				t.Errorf("expected liveness to fail on %q, got %q", wantLive, observer.failed[0])
+			}
+			if observer.failed[1] != tt.wantFailed {
+				t.Errorf("expected readiness to fail on %q, got %q", tt.wantFailed, observer.failed[1])
+			}
+		})
+	}
+}
+
+func TestLastResults(t *testing.T) {
+	registered := true
+	checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+	checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+		return &MockKafkaAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				metadata := kadm.Metadata{Controller: -1}
+				if registered {
+					metadata.Brokers = []kadm.BrokerDetail{{NodeID: 0}}
+				}
+				return metadata, nil
+			},
+		}, func() {}, nil
+	})
+
+	if results := checker.LastResults(); results.Live != nil || results.Ready != nil {
+		t.Errorf("expected no results before the first check, got %+v", results)
+	}
+
+	checker.LivenessHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	checker.ReadinessHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	results := checker.LastResults()
+	if results.Live == nil || !*results.Live {
+		t.Errorf("expected liveness to pass, got %v", results.Live)
+	}
+	if results.Ready == nil || *results.Ready {
+		t.Errorf("expected readiness to fail without a controller, got %v", results.Ready)
+	}
+	if results.BrokerRegistered == nil || !*results.BrokerRegistered {
+		t.Errorf("expected the broker to be registered, got %v", results.BrokerRegistered)
+	}
+	if results.ControllerElected == nil || *results.ControllerElected {
+		t.Errorf("expected no controller, got %v", results.ControllerElected)
+	}
+
+	// Checks run outside the handlers count too
+	registered = false
+	if result := checker.CheckLiveness(context.Background()); result.Healthy {
+		t.Errorf("expected liveness to fail, got %+v", result)
+	}
+	results = checker.LastResults()
+	if *results.Live || *results.BrokerRegistered {
+		t.Errorf("expected the failed liveness check to be recorded, got live=%v registered=%v", *results.Live, *results.BrokerRegistered)
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
+)
+
+// SetRetryPolicy retries admin calls that fail transiently, so one dropped
+// packet does not flip liveness or readiness. Retries stay within the check
+// timeout. The zero policy makes every call once.
+func (c *Checker) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// retryingAdminClient retries the admin calls that fail transiently
+type retryingAdminClient struct {
+	adm    KafkaAdminClient
+	policy retry.Policy
+}
+
+// Metadata implements KafkaAdminClient
+func (r *retryingAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	var metadata kadm.Metadata
+	err := r.policy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		metadata, err = r.adm.Metadata(ctx, topics...)
+		return err
+	})
+	return metadata, err
+}
+
+// DescribeBrokerLogDirs implements KafkaAdminClient
+func (r *retryingAdminClient) DescribeBrokerLogDirs(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+	var logDirs kadm.DescribedLogDirs
+	err := r.policy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		logDirs, err = r.adm.DescribeBrokerLogDirs(ctx, broker, topics)
+		return err
+	})
+	return logDirs, err
+}
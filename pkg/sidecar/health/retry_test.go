@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        retry.Policy
+		expectHealthy bool
+		expectCalls   int
+	}{
+		{name: "no retries", expectHealthy: false, expectCalls: 1},
+		{name: "retried", policy: retry.Policy{Attempts: 3, BaseDelay: time.Millisecond}, expectHealthy: true, expectCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+			checker.SetRetryPolicy(tt.policy)
+			checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+				return &MockKafkaAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						calls++
+						// One dropped connection, then the broker answers
+						if calls == 1 {
+							return kadm.Metadata{}, fmt.Errorf("failed to fetch metadata: %w", io.ErrUnexpectedEOF)
+						}
+						return kadm.Metadata{Brokers: []kadm.BrokerDetail{{NodeID: 0}}}, nil
+					},
+				}, func() {}, nil
+			})
+
+			result := checker.CheckLiveness(context.Background())
+			if result.Healthy != tt.expectHealthy {
+				t.Errorf("expected healthy=%v, got %+v", tt.expectHealthy, result)
+			}
+			if calls != tt.expectCalls {
+				t.Errorf("expected %d metadata calls, got %d", tt.expectCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+)
+
+// timeoutKey carries a per-request check timeout override in the context
+type timeoutKey struct{}
+
+// SetMaxTimeout bounds the ?timeout= override of the health endpoints. Longer
+// requested timeouts are capped at maxTimeout; zero ignores the parameter.
+func (c *Checker) SetMaxTimeout(maxTimeout time.Duration) {
+	c.updateThresholds(func(t *Thresholds) { t.MaxTimeout = maxTimeout })
+}
+
+// timeout returns the timeout of each check, honouring a request override
+func (c *Checker) timeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return c.Thresholds().CheckTimeout
+}
+
+// requestContext applies the request's ?timeout= override, so fast load balancer
+// probes and thorough orchestration checks can share the endpoints with different
+// time budgets. The override bounds the whole request as well as each check.
+func (c *Checker) requestContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	ctx := r.Context()
+	raw := r.URL.Query().Get("timeout")
+	maxTimeout := c.Thresholds().MaxTimeout
+	if raw == "" || maxTimeout <= 0 {
+		return ctx, func() {}, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return nil, nil, cplnErrors.Validationf("invalid timeout: %q (expected a positive duration such as 3s)", raw)
+	}
+	if d > maxTimeout {
+		d = maxTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, timeoutKey{}, d), d)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func TestTimeoutOverride(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		maxTimeout    time.Duration
+		expectedCode  int
+		expectedLimit time.Duration
+	}{
+		{name: "default timeout", maxTimeout: 20 * time.Second, expectedCode: http.StatusOK, expectedLimit: 5 * time.Second},
+		{name: "shorter timeout", query: "?timeout=1s", maxTimeout: 20 * time.Second, expectedCode: http.StatusOK, expectedLimit: time.Second},
+		{name: "longer timeout", query: "?timeout=15s", maxTimeout: 20 * time.Second, expectedCode: http.StatusOK, expectedLimit: 15 * time.Second},
+		{name: "capped at the maximum", query: "?timeout=1m", maxTimeout: 20 * time.Second, expectedCode: http.StatusOK, expectedLimit: 20 * time.Second},
+		{name: "overrides disabled", query: "?timeout=1s", expectedCode: http.StatusOK, expectedLimit: 5 * time.Second},
+		{name: "invalid timeout", query: "?timeout=soon", maxTimeout: 20 * time.Second, expectedCode: http.StatusBadRequest},
+		{name: "negative timeout", query: "?timeout=-1s", maxTimeout: 20 * time.Second, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		for _, endpoint := range []string{"/health/live", "/health/ready"} {
+			t.Run(tt.name+" "+endpoint, func(t *testing.T) {
+				var remaining time.Duration
+				checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+				checker.SetMaxTimeout(tt.maxTimeout)
+				checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+					return &MockKafkaAdminClient{
+						MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+							if deadline, ok := ctx.Deadline(); ok {
+								remaining = time.Until(deadline)
+							}
+							return kadm.Metadata{
+								Brokers:    []kadm.BrokerDetail{{NodeID: 0}},
+								Controller: 0,
+							}, nil
+						},
+					}, func() {}, nil
+				})
+
+				req := httptest.NewRequest(http.MethodGet, endpoint+tt.query, nil)
+				w := httptest.NewRecorder()
+				if endpoint == "/health/live" {
+					checker.LivenessHandler(w, req)
+				} else {
+					checker.ReadinessHandler(w, req)
+				}
+
+				if w.Code != tt.expectedCode {
+					t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+				}
+				if tt.expectedCode != http.StatusOK {
+					return
+				}
+				// The deadline has been running since the request started
+				if remaining > tt.expectedLimit || remaining < tt.expectedLimit-time.Second {
+					t.Errorf("expected a check deadline of about %s, got %s", tt.expectedLimit, remaining)
+				}
+			})
+		}
+	}
+}
+
+func TestSetThresholds(t *testing.T) {
+	checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+	checker.SetMaxTimeout(20 * time.Second)
+	checker.SetFormationGrace(time.Minute)
+
+	checker.SetThresholds(Thresholds{CheckTimeout: 2 * time.Second, MaxTimeout: 10 * time.Second})
+	if got := checker.timeout(context.Background()); got != 2*time.Second {
+		t.Errorf("expected the reloaded check timeout, got %v", got)
+	}
+	if checker.FormationGraceActive() {
+		t.Error("expected the formation grace to be replaced too")
+	}
+
+	// The setters keep the other thresholds
+	checker.SetMaxTimeout(30 * time.Second)
+	if got := checker.Thresholds(); got.CheckTimeout != 2*time.Second || got.MaxTimeout != 30*time.Second {
+		t.Errorf("unexpected thresholds %+v", got)
+	}
+}
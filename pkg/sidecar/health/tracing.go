@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the health check spans
+const tracerName = "github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+
+// SetTracerProvider traces every probe, its sub-checks and the admin calls
+// they make. Without one, spans are not recorded.
+func (c *Checker) SetTracerProvider(provider trace.TracerProvider) {
+	c.tracer = provider.Tracer(tracerName)
+}
+
+// startSpan starts the span of a probe or sub-check of this broker
+func (c *Checker) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name, trace.WithAttributes(attribute.Int("kafka.broker.id", int(c.brokerID))))
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// adminClient creates an admin client whose calls are traced, every attempt
+// in its own span, and retried under the retry policy. Injected faults act
+// like the broker's own responses, so they are traced and retried too.
+func (c *Checker) adminClient() (KafkaAdminClient, func(), error) {
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Thresholds().Faults.Enabled() {
+		adm = &faultyAdminClient{adm: adm, brokerID: c.brokerID, faults: func() Faults { return c.Thresholds().Faults }}
+	}
+	return &retryingAdminClient{adm: &tracedAdminClient{adm: adm, tracer: c.tracer}, policy: c.retry}, cleanup, nil
+}
+
+// tracedAdminClient records a client span for every admin call, so a slow probe
+// shows which request to which broker it waited on
+type tracedAdminClient struct {
+	adm    KafkaAdminClient
+	tracer trace.Tracer
+}
+
+// Metadata implements KafkaAdminClient
+func (t *tracedAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	ctx, span := t.tracer.Start(ctx, "kadm.Metadata", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("kafka.topics", len(topics))))
+	metadata, err := t.adm.Metadata(ctx, topics...)
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int("kafka.brokers", len(metadata.Brokers)),
+			attribute.Int("kafka.controller.id", int(metadata.Controller)))
+	}
+	endSpan(span, err)
+	return metadata, err
+}
+
+// DescribeBrokerLogDirs implements KafkaAdminClient
+func (t *tracedAdminClient) DescribeBrokerLogDirs(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+	ctx, span := t.tracer.Start(ctx, "kadm.DescribeBrokerLogDirs", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("kafka.broker.id", int(broker))))
+	logDirs, err := t.adm.DescribeBrokerLogDirs(ctx, broker, topics)
+	endSpan(span, err)
+	return logDirs, err
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestReadinessTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	checker := NewChecker(0, "localhost:9092", 5*time.Second, SASLConfig{}, testLogger())
+	checker.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	checker.SetClientFactory(func() (KafkaAdminClient, func(), error) {
+		return &MockKafkaAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return kadm.Metadata{Brokers: []kadm.BrokerDetail{{NodeID: 0}}, Controller: 0}, nil
+			},
+			DescribeBrokerLogDirsFunc: func(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+				return nil, errors.New("log dir error")
+			},
+		}, func() {}, nil
+	})
+
+	checker.ReadinessHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	probe, ok := spans["health.readiness"]
+	if !ok {
+		t.Fatalf("expected a readiness span, got %v", spans)
+	}
+	if probe.Status().Code != codes.Error {
+		t.Errorf("expected the failed probe to be marked as an error, got %v", probe.Status())
+	}
+
+	// Every sub-check up to the failing one is a child of the probe, and the
+	// admin calls are children of the sub-checks
+	for _, name := range []string{"health.broker_registered", "health.controller", "health.under_replicated", "health.log_dirs"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("expected a %s span", name)
+			continue
+		}
+		if span.Parent().SpanID() != probe.SpanContext().SpanID() {
+			t.Errorf("expected %s to be a child of the probe", name)
+		}
+	}
+	logDirs, ok := spans["kadm.DescribeBrokerLogDirs"]
+	if !ok {
+		t.Fatal("expected a kadm.DescribeBrokerLogDirs span")
+	}
+	if logDirs.Parent().SpanID() != spans["health.log_dirs"].SpanContext().SpanID() {
+		t.Error("expected the admin call to be a child of its sub-check")
+	}
+	if logDirs.Status().Code != codes.Error || len(logDirs.Events()) == 0 {
+		t.Errorf("expected the admin call's error to be recorded, got %v", logDirs.Status())
+	}
+}
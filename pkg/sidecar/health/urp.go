@@ -0,0 +1,78 @@
+package health
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TopicFilter selects the topics whose under-replicated partitions count against
+// readiness. Patterns are regular expressions matched against the whole topic name.
+type TopicFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewTopicFilter parses comma-separated include and exclude patterns. An empty
+// include list includes every topic; exclude takes precedence over include.
+func NewTopicFilter(include, exclude string) (TopicFilter, error) {
+	var f TopicFilter
+	var err error
+	if f.include, err = compilePatterns(include); err != nil {
+		return TopicFilter{}, err
+	}
+	if f.exclude, err = compilePatterns(exclude); err != nil {
+		return TopicFilter{}, err
+	}
+	return f, nil
+}
+
+// Counts reports whether under-replicated partitions of the topic count against readiness
+func (f TopicFilter) Counts(topic string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(topic) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePatterns compiles a comma-separated list of patterns, anchored to match
+// the whole topic name
+func compilePatterns(patterns string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// URPCounts is the outcome of the last under-replicated partitions check
+type URPCounts struct {
+	// Counted are under-replicated partitions that fail readiness
+	Counted int
+	// Excluded are under-replicated partitions of topics excluded by the filter
+	Excluded int
+	// Partitions are the partitions with a replica on this broker, in every topic
+	Partitions int
+	// Leaders are the partitions this broker leads, in every topic
+	Leaders   int
+	CheckedAt time.Time
+}
@@ -0,0 +1,223 @@
+package inflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// CacheHeader is set on responses served from cache instead of running the handler
+const CacheHeader = "X-Served-From-Cache"
+
+// Stats are the request counts of one handler
+type Stats struct {
+	Handler  string
+	InFlight int
+	// Shed counts requests served from cache, or refused, because the handler
+	// was at its concurrency cap
+	Shed uint64
+}
+
+type handler struct {
+	inFlight int
+	shed     uint64
+}
+
+// response is a handler response kept to serve shed requests
+type response struct {
+	status   int
+	header   http.Header
+	body     []byte
+	cachedAt time.Time
+}
+
+// Tracker counts the requests each handler is serving, and sheds probe requests
+// beyond a concurrency cap by serving the last response from cache, so probe
+// latency stays bounded when checks slow down under a storm of probes or scrapes
+type Tracker struct {
+	clock clock.Clock
+
+	mu       sync.Mutex
+	handlers map[string]*handler
+	cache    map[string]*response
+	// shedding are the handlers counted by Shed rather than Middleware
+	shedding map[string]bool
+}
+
+// NewTracker creates a new in-flight request tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		clock:    clock.Real,
+		handlers: make(map[string]*handler),
+		cache:    make(map[string]*response),
+		shedding: make(map[string]bool),
+	}
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (t *Tracker) SetClock(clk clock.Clock) {
+	t.clock = clk
+}
+
+// Middleware counts in-flight requests by route path template
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				name = tpl
+			}
+		}
+		t.mu.Lock()
+		shedding := t.shedding[name]
+		t.mu.Unlock()
+		if shedding {
+			next.ServeHTTP(w, r)
+			return
+		}
+		t.acquire(name, 0)
+		defer t.release(name)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Shed caps how many requests run the handler of the route path at once. Beyond
+// the cap the last response is served again, marked with CacheHeader and, for
+// JSON objects, "servedFromCache" and "cachedAt" fields. Without a cached
+// response the request is refused with 503. A cap of zero or less only counts
+// requests.
+func (t *Tracker) Shed(name string, limit int, next http.HandlerFunc) http.HandlerFunc {
+	t.mu.Lock()
+	t.shedding[name] = true
+	t.mu.Unlock()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !t.acquire(name, limit) {
+			t.serveCached(w, name)
+			return
+		}
+		defer t.release(name)
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		t.store(name, rec)
+	}
+}
+
+// Stats returns the counts of every handler seen so far, sorted by name
+func (t *Tracker) Stats() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make([]Stats, 0, len(t.handlers))
+	for name, h := range t.handlers {
+		stats = append(stats, Stats{Handler: name, InFlight: h.inFlight, Shed: h.shed})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Handler < stats[j].Handler
+	})
+	return stats
+}
+
+// acquire counts a request in, unless the handler is at limit
+func (t *Tracker) acquire(name string, limit int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.get(name)
+	if limit > 0 && h.inFlight >= limit {
+		h.shed++
+		return false
+	}
+	h.inFlight++
+	return true
+}
+
+func (t *Tracker) release(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(name).inFlight--
+}
+
+// get returns the named handler, creating it if needed. Callers must hold mu.
+func (t *Tracker) get(name string) *handler {
+	h, ok := t.handlers[name]
+	if !ok {
+		h = &handler{}
+		t.handlers[name] = h
+	}
+	return h
+}
+
+func (t *Tracker) store(name string, rec *recorder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cache[name] = &response{
+		status:   rec.status,
+		header:   rec.Header().Clone(),
+		body:     rec.body.Bytes(),
+		cachedAt: t.clock.Now(),
+	}
+}
+
+func (t *Tracker) serveCached(w http.ResponseWriter, name string) {
+	t.mu.Lock()
+	cached := t.cache[name]
+	t.mu.Unlock()
+
+	if cached == nil {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many concurrent requests and no cached response yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	for key, values := range cached.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set(CacheHeader, "true")
+	body := markCached(cached.body, cached.cachedAt)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(cached.status)
+	_, _ = w.Write(body)
+}
+
+// markCached adds servedFromCache and cachedAt to a JSON object body. Other
+// bodies are returned unchanged, marked by the header alone.
+func markCached(body []byte, cachedAt time.Time) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	fields["servedFromCache"] = json.RawMessage("true")
+	at, _ := json.Marshal(cachedAt)
+	fields["cachedAt"] = at
+	marked, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return marked
+}
+
+// recorder keeps a copy of the response written through it
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *recorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,120 @@
+package inflight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// blockingHandler responds with a JSON status once released
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	b.started <- struct{}{}
+	<-b.release
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write([]byte(`{"status":"unhealthy"}`))
+}
+
+func serve(h http.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	return w
+}
+
+func TestShedServesFromCache(t *testing.T) {
+	tracker := NewTracker()
+	backend := newBlockingHandler()
+	h := tracker.Shed("/health/ready", 1, backend.ServeHTTP)
+
+	// Nothing cached yet: the request beyond the cap is refused
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve(h) }()
+	<-backend.started
+	if w := serve(h); w.Code != http.StatusServiceUnavailable || w.Header().Get(CacheHeader) != "" {
+		t.Errorf("expected a refusal without a cached response, got %d", w.Code)
+	}
+	backend.release <- struct{}{}
+	if w := <-done; w.Code != http.StatusServiceUnavailable || w.Body.String() != `{"status":"unhealthy"}` {
+		t.Errorf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	// Now the completed response is served to requests beyond the cap
+	go func() { done <- serve(h) }()
+	<-backend.started
+	w := serve(h)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get(CacheHeader) != "true" {
+		t.Errorf("expected the cached status with the cache header, got %d %v", w.Code, w.Header())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid cached body: %v", err)
+	}
+	if body["status"] != "unhealthy" || body["servedFromCache"] != true || body["cachedAt"] == nil {
+		t.Errorf("expected the cached body to be marked, got %v", body)
+	}
+	close(backend.release)
+	<-done
+
+	stats := tracker.Stats()
+	if len(stats) != 1 || stats[0].Shed != 2 || stats[0].InFlight != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestShedWithoutLimit(t *testing.T) {
+	tracker := NewTracker()
+	backend := newBlockingHandler()
+	close(backend.release)
+	h := tracker.Shed("/health/live", 0, backend.ServeHTTP)
+
+	for i := 0; i < 3; i++ {
+		if w := serve(h); w.Header().Get(CacheHeader) != "" {
+			t.Error("expected every request to run without a limit")
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tracker := NewTracker()
+	backend := newBlockingHandler()
+
+	router := mux.NewRouter()
+	router.Use(tracker.Middleware)
+	router.Handle("/topics/{name}", backend).Methods("GET")
+	router.HandleFunc("/health/ready", tracker.Shed("/health/ready", 1, func(w http.ResponseWriter, _ *http.Request) {})).Methods("GET")
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/topics/orders", nil))
+		close(done)
+	}()
+	<-backend.started
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	stats := map[string]Stats{}
+	for _, s := range tracker.Stats() {
+		stats[s.Handler] = s
+	}
+	if stats["/topics/{name}"].InFlight != 1 {
+		t.Errorf("expected the topic request in flight under its route template, got %+v", stats)
+	}
+	close(backend.release)
+	<-done
+
+	// Shed routes are counted once, by Shed
+	if len(stats) != 2 {
+		t.Errorf("expected 2 handlers, got %+v", stats)
+	}
+}
@@ -0,0 +1,225 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+const (
+	// defaultImage is the Kafka image the clusters run, overridden by KAFKA_IMAGE
+	defaultImage = "apache/kafka:3.9.0"
+	// clusterID is the KRaft cluster ID every test cluster is formatted with
+	clusterID = "4L6g3nShT-eMCtK--X86sw"
+	// startTimeout bounds how long a cluster may take to register every broker
+	startTimeout = 2 * time.Minute
+)
+
+// cluster is a KRaft cluster of combined broker/controller nodes, each in its
+// own container on a private network. Clients on the host reach node N on
+// localhost through a published port; the nodes talk to each other by name.
+type cluster struct {
+	t          *testing.T
+	network    string
+	containers []string
+	config     kafkaclient.Config
+}
+
+// startCluster starts a cluster of the given size with node IDs 1..brokers and
+// waits until every broker is registered. The cluster is removed when the test
+// finishes, after dumping the container logs if the test failed.
+func startCluster(t *testing.T, brokers int) *cluster {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not available")
+	}
+
+	image := os.Getenv("KAFKA_IMAGE")
+	if image == "" {
+		image = defaultImage
+	}
+	c := &cluster{t: t, network: "kafka-it-" + randomSuffix(t)}
+	t.Cleanup(c.remove)
+	c.docker("network", "create", c.network)
+
+	var voters []string
+	for id := 1; id <= brokers; id++ {
+		voters = append(voters, fmt.Sprintf("%d@%s:9093", id, c.node(id)))
+	}
+	rf := min(brokers, 3)
+
+	for id := 1; id <= brokers; id++ {
+		port := freePort(t)
+		c.docker("run", "-d",
+			"--name", c.node(id),
+			"--network", c.network,
+			"-p", fmt.Sprintf("%d:%d", port, port),
+			"-e", fmt.Sprintf("KAFKA_NODE_ID=%d", id),
+			"-e", "KAFKA_PROCESS_ROLES=broker,controller",
+			"-e", fmt.Sprintf("KAFKA_LISTENERS=INTERNAL://:19092,EXTERNAL://:%d,CONTROLLER://:9093", port),
+			"-e", fmt.Sprintf("KAFKA_ADVERTISED_LISTENERS=INTERNAL://%s:19092,EXTERNAL://localhost:%d", c.node(id), port),
+			"-e", "KAFKA_LISTENER_SECURITY_PROTOCOL_MAP=INTERNAL:PLAINTEXT,EXTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT",
+			"-e", "KAFKA_INTER_BROKER_LISTENER_NAME=INTERNAL",
+			"-e", "KAFKA_CONTROLLER_LISTENER_NAMES=CONTROLLER",
+			"-e", "KAFKA_CONTROLLER_QUORUM_VOTERS="+strings.Join(voters, ","),
+			"-e", "CLUSTER_ID="+clusterID,
+			"-e", fmt.Sprintf("KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR=%d", rf),
+			"-e", fmt.Sprintf("KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR=%d", rf),
+			"-e", "KAFKA_TRANSACTION_STATE_LOG_MIN_ISR=1",
+			"-e", "KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS=0",
+			image)
+		c.containers = append(c.containers, c.node(id))
+		c.config.BootstrapServers = append(c.config.BootstrapServers, fmt.Sprintf("localhost:%d", port))
+	}
+
+	c.waitForBrokers(brokers)
+	return c
+}
+
+// node is the container name, and host name on the network, of a node
+func (c *cluster) node(id int) string {
+	return fmt.Sprintf("%s-%d", c.network, id)
+}
+
+// bootstrap is the bootstrap server list in the sidecar's BOOTSTRAP_SERVERS form
+func (c *cluster) bootstrap() string {
+	return strings.Join(c.config.BootstrapServers, ",")
+}
+
+// admin returns an admin client of the cluster, closed when the test finishes
+func (c *cluster) admin() *kadm.Client {
+	c.t.Helper()
+	adm, cleanup, err := kafkaclient.NewAdminClient(c.config)
+	if err != nil {
+		c.t.Fatalf("failed to create admin client: %v", err)
+	}
+	c.t.Cleanup(cleanup)
+	return adm
+}
+
+// createTopic creates a topic and waits until every partition has a leader
+func (c *cluster) createTopic(name string, partitions int32, rf int16) {
+	c.t.Helper()
+	adm := c.admin()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := adm.CreateTopic(ctx, partitions, rf, nil, name)
+	if err == nil {
+		err = resp.Err
+	}
+	if err != nil {
+		c.t.Fatalf("failed to create topic %s: %v", name, err)
+	}
+	eventually(c.t, 30*time.Second, func() error {
+		md, err := adm.Metadata(ctx, name)
+		if err != nil {
+			return err
+		}
+		for _, p := range md.Topics[name].Partitions {
+			if p.Leader < 0 || len(p.ISR) < int(rf) {
+				return fmt.Errorf("partition %d not fully in sync yet", p.Partition)
+			}
+		}
+		return nil
+	})
+}
+
+// waitForBrokers waits until the given number of brokers is registered
+func (c *cluster) waitForBrokers(brokers int) {
+	c.t.Helper()
+	adm := c.admin()
+	eventually(c.t, startTimeout, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		md, err := adm.Metadata(ctx)
+		if err != nil {
+			return err
+		}
+		if len(md.Brokers) < brokers || md.Controller < 0 {
+			return fmt.Errorf("%d of %d brokers registered", len(md.Brokers), brokers)
+		}
+		return nil
+	})
+}
+
+// stop stops a node's container, keeping it for its logs
+func (c *cluster) stop(id int) {
+	c.t.Helper()
+	c.docker("stop", c.node(id))
+}
+
+func (c *cluster) remove() {
+	if c.t.Failed() {
+		for _, name := range c.containers {
+			out, _ := exec.Command("docker", "logs", "--tail", "200", name).CombinedOutput()
+			c.t.Logf("logs of %s:\n%s", name, out)
+		}
+	}
+	for _, name := range c.containers {
+		_ = exec.Command("docker", "rm", "-f", name).Run()
+	}
+	_ = exec.Command("docker", "network", "rm", c.network).Run()
+}
+
+func (c *cluster) docker(args ...string) {
+	c.t.Helper()
+	if out, err := exec.Command("docker", args...).CombinedOutput(); err != nil {
+		c.t.Fatalf("docker %s failed: %v\n%s", args[0], err, out)
+	}
+}
+
+// eventually retries check until it succeeds or the timeout passes
+func eventually(t *testing.T, timeout time.Duration, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s: %v", timeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// freePort returns a host port that was free a moment ago
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func randomSuffix(t *testing.T) string {
+	t.Helper()
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
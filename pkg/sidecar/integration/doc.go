@@ -0,0 +1,10 @@
+// Package integration holds end-to-end tests of the sidecar's checks and
+// workflows against real KRaft clusters started in Docker. They are opt-in,
+// behind the integration build tag, and need a Docker daemon:
+//
+//	go test -tags integration -v ./pkg/sidecar/integration/
+//
+// KAFKA_IMAGE overrides the apache/kafka image the clusters run. The
+// conformance test also runs its scenario against a pkg/kafkatest fake cluster,
+// which the non-integration tests use in place of Docker.
+package integration
@@ -0,0 +1,61 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+func TestSingleBrokerHealth(t *testing.T) {
+	c := startCluster(t, 1)
+	checker := health.NewChecker(1, c.bootstrap(), 10*time.Second, health.SASLConfig{}, testLogger())
+	ctx := context.Background()
+
+	if result := checker.CheckLiveness(ctx); !result.Healthy {
+		t.Errorf("expected a live broker, got %+v", result)
+	}
+	c.createTopic("orders", 3, 1)
+	if result := checker.CheckReadiness(ctx); !result.Healthy {
+		t.Errorf("expected a ready broker, got %+v", result)
+	}
+
+	// A broker ID the cluster does not have is never registered
+	missing := health.NewChecker(7, c.bootstrap(), 10*time.Second, health.SASLConfig{}, testLogger())
+	if result := missing.CheckReadiness(ctx); result.Healthy {
+		t.Errorf("expected an unregistered broker not to be ready, got %+v", result)
+	}
+}
+
+func TestUnderReplicatedReadiness(t *testing.T) {
+	c := startCluster(t, 3)
+	c.createTopic("orders", 6, 3)
+
+	checker := health.NewChecker(1, c.bootstrap(), 10*time.Second, health.SASLConfig{}, testLogger())
+	ctx := context.Background()
+	if result := checker.CheckReadiness(ctx); !result.Healthy {
+		t.Fatalf("expected a ready broker, got %+v", result)
+	}
+
+	// Broker 1 replicates every partition, so stopping a peer leaves it
+	// with under-replicated partitions once the ISR shrinks
+	c.stop(3)
+	adm := c.admin()
+	eventually(t, time.Minute, func() error {
+		counts, err := checker.CountUnderReplicated(ctx, adm)
+		if err != nil {
+			return err
+		}
+		if counts.Counted == 0 {
+			return errors.New("no under-replicated partitions yet")
+		}
+		return nil
+	})
+	if result := checker.CheckReadiness(ctx); result.Healthy {
+		t.Errorf("expected under-replicated partitions to fail readiness, got %+v", result)
+	}
+}
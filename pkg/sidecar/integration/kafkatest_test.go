@@ -0,0 +1,162 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/kafkatest"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// TestFakeConformance runs the same admin scenario against a Docker cluster
+// and a kafkatest fake, so unit tests written against the fake keep relying
+// on behavior real brokers have
+func TestFakeConformance(t *testing.T) {
+	t.Run("docker", func(t *testing.T) {
+		c := startCluster(t, 3)
+		adminScenario(t, c.config)
+	})
+	t.Run("kafkatest", func(t *testing.T) {
+		c := kafkatest.Start(t, kafkatest.Options{Brokers: 3, FirstBrokerID: 1, AutoCompleteReassignments: true})
+		adminScenario(t, c.Config())
+	})
+}
+
+// adminScenario exercises the admin requests the sidecar sends against a
+// 3-broker cluster with node IDs 1..3
+func adminScenario(t *testing.T, config kafkaclient.Config) {
+	adm, cleanup, err := kafkaclient.NewAdminClient(config)
+	if err != nil {
+		t.Fatalf("failed to create admin client: %v", err)
+	}
+	t.Cleanup(cleanup)
+	ctx, cancel := context.WithTimeout(context.Background(), workflowTimeout)
+	defer cancel()
+
+	const topic = "conformance"
+	created, err := adm.CreateTopic(ctx, 3, 2, nil, topic)
+	if err == nil {
+		err = created.Err
+	}
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	var md kadm.Metadata
+	eventually(t, 30*time.Second, func() error {
+		if md, err = adm.Metadata(ctx, topic); err != nil {
+			return err
+		}
+		for _, p := range md.Topics[topic].Partitions {
+			if p.Leader < 0 || len(p.ISR) < 2 {
+				return fmt.Errorf("partition %d not fully in sync yet", p.Partition)
+			}
+		}
+		return nil
+	})
+	if len(md.Brokers) != 3 || md.Controller < 1 {
+		t.Errorf("expected 3 brokers and a controller, got %d brokers and controller %d", len(md.Brokers), md.Controller)
+	}
+	if n := len(md.Topics[topic].Partitions); n != 3 {
+		t.Fatalf("expected 3 partitions, got %d", n)
+	}
+
+	// Every replica has a log in a log dir of its broker
+	dirs, err := adm.DescribeAllLogDirs(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to describe log dirs: %v", err)
+	}
+	for _, p := range md.Topics[topic].Partitions {
+		for _, r := range p.Replicas {
+			found := false
+			dirs[r].Each(func(d kadm.DescribedLogDir) {
+				if _, ok := d.Topics.Lookup(topic, p.Partition); ok {
+					found = true
+				}
+			})
+			if !found {
+				t.Errorf("expected a log of partition %d on broker %d", p.Partition, r)
+			}
+		}
+	}
+
+	// Topic configs report defaults and dynamic overrides by source
+	retention := "3600000"
+	altered, err := adm.AlterTopicConfigs(ctx, []kadm.AlterConfig{{Op: kadm.SetConfig, Name: "retention.ms", Value: &retention}}, topic)
+	if err == nil {
+		_, err = altered.On(topic, nil)
+	}
+	if err != nil {
+		t.Fatalf("failed to alter topic config: %v", err)
+	}
+	configs, err := adm.DescribeTopicConfigs(ctx, topic)
+	if err != nil {
+		t.Fatalf("failed to describe topic configs: %v", err)
+	}
+	rc, err := configs.On(topic, nil)
+	if err != nil {
+		t.Fatalf("failed to describe topic configs: %v", err)
+	}
+	sources := map[string]kmsg.ConfigSource{}
+	for _, c := range rc.Configs {
+		sources[c.Key] = c.Source
+		if c.Key == "retention.ms" && c.MaybeValue() != retention {
+			t.Errorf("expected retention.ms %s, got %s", retention, c.MaybeValue())
+		}
+	}
+	if sources["retention.ms"] != kmsg.ConfigSourceDynamicTopicConfig {
+		t.Errorf("expected retention.ms to be a topic override, got %v", sources["retention.ms"])
+	}
+	if source, ok := sources["min.insync.replicas"]; !ok || source == kmsg.ConfigSourceDynamicTopicConfig {
+		t.Errorf("expected min.insync.replicas to be inherited, got %v", source)
+	}
+
+	// Reassigning a partition onto every broker completes and is listed
+	// until it does
+	req := kadm.AlterPartitionAssignmentsReq{}
+	req.Assign(topic, 0, []int32{1, 2, 3})
+	reassigned, err := adm.AlterPartitionAssignments(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to reassign partition: %v", err)
+	}
+	if err := reassigned.Error(); err != nil {
+		t.Fatalf("failed to reassign partition: %v", err)
+	}
+	eventually(t, workflowTimeout, func() error {
+		listed, err := adm.ListPartitionReassignments(ctx, kadm.TopicsSet{topic: {0: {}}})
+		if err != nil {
+			return err
+		}
+		if len(listed[topic]) > 0 {
+			return fmt.Errorf("reassignment of partition 0 in progress")
+		}
+		md, err := adm.Metadata(ctx, topic)
+		if err != nil {
+			return err
+		}
+		if p := md.Topics[topic].Partitions[0]; len(p.Replicas) != 3 || len(p.ISR) != 3 {
+			return fmt.Errorf("expected 3 in-sync replicas of partition 0, got replicas %v and ISR %v", p.Replicas, p.ISR)
+		}
+		return nil
+	})
+
+	// Preferred leader election succeeds for partitions that need it and
+	// is not needed for the rest
+	elected, err := adm.ElectLeaders(ctx, kadm.ElectPreferredReplica, kadm.TopicsSet{topic: {0: {}, 1: {}, 2: {}}})
+	if err != nil {
+		t.Fatalf("failed to elect leaders: %v", err)
+	}
+	for p, r := range elected[topic] {
+		if r.Err != nil && !errors.Is(r.Err, kerr.ElectionNotNeeded) {
+			t.Errorf("expected partition %d election to succeed or not be needed, got %v", p, r.Err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/catalog"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/decommission"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/replication"
+)
+
+// workflowTimeout bounds a drain or reassignment of the small test topics
+const workflowTimeout = 2 * time.Minute
+
+func TestDecommissionDrainsBroker(t *testing.T) {
+	c := startCluster(t, 3)
+	c.createTopic("orders", 6, 2)
+
+	d := decommission.NewDecommissioner(c.config, decommission.Options{
+		BatchSize:    2,
+		PollInterval: time.Second,
+		Timeout:      10 * time.Second,
+		MinISRPolicy: decommission.MinISRPolicyReject,
+	}, testLogger())
+	ctx := context.Background()
+
+	plan, err := d.Start(ctx, 3, decommission.StartOptions{Actor: "integration"})
+	if err != nil {
+		t.Fatalf("failed to start decommission: %v", err)
+	}
+	if len(plan.Moves) == 0 {
+		t.Fatal("expected broker 3 to hold replicas to move")
+	}
+
+	eventually(t, workflowTimeout, func() error {
+		switch s := d.Status(); s.State {
+		case decommission.StateCompleted:
+			return nil
+		case decommission.StateFailed:
+			t.Fatalf("decommission failed: %s", s.Message)
+		}
+		return fmt.Errorf("decommission %s", d.Status().State)
+	})
+
+	md, err := c.admin().Metadata(ctx, "orders")
+	if err != nil {
+		t.Fatalf("failed to fetch metadata: %v", err)
+	}
+	for _, p := range md.Topics["orders"].Partitions {
+		if len(p.Replicas) != 2 {
+			t.Errorf("partition %d lost a replica: %v", p.Partition, p.Replicas)
+		}
+		for _, r := range p.Replicas {
+			if r == 3 {
+				t.Errorf("partition %d still on broker 3: %v", p.Partition, p.Replicas)
+			}
+		}
+	}
+}
+
+func TestReplicationFactorChange(t *testing.T) {
+	c := startCluster(t, 3)
+	c.createTopic("payments", 4, 1)
+
+	changer := replication.NewChanger(c.config, replication.Options{
+		BatchSize:    2,
+		PollInterval: time.Second,
+		Timeout:      10 * time.Second,
+	}, testLogger())
+	ctx := context.Background()
+
+	if _, err := changer.Start(ctx, "payments", 3, replication.StartOptions{Actor: "integration"}); err != nil {
+		t.Fatalf("failed to start replication factor change: %v", err)
+	}
+	eventually(t, workflowTimeout, func() error {
+		switch s := changer.Status(); s.State {
+		case replication.StateCompleted:
+			return nil
+		case replication.StateFailed:
+			t.Fatalf("replication factor change failed: %s", s.Message)
+		}
+		return fmt.Errorf("replication factor change %s", changer.Status().State)
+	})
+
+	md, err := c.admin().Metadata(ctx, "payments")
+	if err != nil {
+		t.Fatalf("failed to fetch metadata: %v", err)
+	}
+	for _, p := range md.Topics["payments"].Partitions {
+		if len(p.Replicas) != 3 {
+			t.Errorf("expected 3 replicas of partition %d, got %v", p.Partition, p.Replicas)
+		}
+	}
+}
+
+func TestCatalogListsTopics(t *testing.T) {
+	c := startCluster(t, 1)
+	c.createTopic("orders", 3, 1)
+
+	cat := catalog.NewCatalog(c.config, catalog.Options{
+		RefreshInterval: time.Minute,
+		Timeout:         10 * time.Second,
+	}, testLogger())
+	if err := cat.Step(context.Background()); err != nil {
+		t.Fatalf("failed to refresh catalog: %v", err)
+	}
+
+	var found *catalog.Topic
+	snapshot := cat.Snapshot()
+	for i, topic := range snapshot.Topics {
+		if topic.Name == "orders" {
+			found = &snapshot.Topics[i]
+		}
+	}
+	if found == nil || found.Partitions != 3 || found.ReplicationFactor != 1 {
+		t.Errorf("expected orders with 3 partitions and RF 1, got %+v", found)
+	}
+}
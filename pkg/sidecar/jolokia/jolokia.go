@@ -0,0 +1,150 @@
+package jolokia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no MBean matches the requested name or pattern
+var ErrNotFound = errors.New("no matching MBean")
+
+// Client reads MBean attributes from a Jolokia agent attached to the Kafka broker JVM
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Jolokia client for the given agent URL
+// (for example http://localhost:8778/jolokia)
+func NewClient(url string, timeout time.Duration) *Client {
+	return &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// readRequest is the body of a Jolokia read request
+type readRequest struct {
+	Type      string `json:"type"`
+	MBean     string `json:"mbean"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+// readResponse is the body of a Jolokia read response
+type readResponse struct {
+	Status int             `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// Read reads a single attribute of an MBean and returns the raw JSON value
+func (c *Client) Read(ctx context.Context, mbean, attribute string) (json.RawMessage, error) {
+	body, err := json.Marshal(readRequest{
+		Type:      "read",
+		MBean:     mbean,
+		Attribute: attribute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jolokia request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jolokia response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jolokia returned HTTP %d", resp.StatusCode)
+	}
+
+	var parsed readResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse jolokia response: %w", err)
+	}
+	if parsed.Status == http.StatusNotFound {
+		return nil, fmt.Errorf("jolokia read of %s: %w", mbean, ErrNotFound)
+	}
+	if parsed.Status != http.StatusOK {
+		return nil, fmt.Errorf("jolokia read of %s failed (status %d): %s", mbean, parsed.Status, parsed.Error)
+	}
+
+	return parsed.Value, nil
+}
+
+// ReadFloat reads a single numeric attribute of an MBean
+func (c *Client) ReadFloat(ctx context.Context, mbean, attribute string) (float64, error) {
+	raw, err := c.Read(ctx, mbean, attribute)
+	if err != nil {
+		return 0, err
+	}
+
+	var value float64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return 0, fmt.Errorf("attribute %s of %s is not numeric: %w", attribute, mbean, err)
+	}
+	return value, nil
+}
+
+// ReadPattern reads a numeric attribute from every MBean matching a wildcard
+// pattern and returns the values keyed by full MBean name. A pattern matching
+// no MBean returns no values.
+func (c *Client) ReadPattern(ctx context.Context, pattern, attribute string) (map[string]float64, error) {
+	raw, err := c.Read(ctx, pattern, attribute)
+	if errors.Is(err, ErrNotFound) {
+		return map[string]float64{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Pattern reads return {"<mbean>": {"<attribute>": value}}
+	var byMBean map[string]map[string]any
+	if err := json.Unmarshal(raw, &byMBean); err != nil {
+		return nil, fmt.Errorf("unexpected jolokia pattern response for %s: %w", pattern, err)
+	}
+
+	values := make(map[string]float64, len(byMBean))
+	for name, attrs := range byMBean {
+		if v, ok := attrs[attribute].(float64); ok {
+			values[name] = v
+		}
+	}
+	return values, nil
+}
+
+// ParseObjectName splits a JMX object name into its domain and key properties.
+// Example: "kafka.server:type=Produce,user=alice" -> "kafka.server", {type: Produce, user: alice}
+func ParseObjectName(name string) (string, map[string]string) {
+	domain, rest, found := strings.Cut(name, ":")
+	props := make(map[string]string)
+	if !found {
+		return name, props
+	}
+
+	for _, pair := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		props[key] = value
+	}
+	return domain, props
+}
@@ -0,0 +1,143 @@
+package jolokia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler func(req readRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req readRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		code, body := handler(req)
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestReadFloat(t *testing.T) {
+	server := newTestServer(t, func(req readRequest) (int, any) {
+		if req.Type != "read" {
+			t.Errorf("expected read request, got %q", req.Type)
+		}
+		if req.MBean != "kafka.server:type=ReplicaManager,name=UnderReplicatedPartitions" {
+			t.Errorf("unexpected mbean %q", req.MBean)
+		}
+		return http.StatusOK, map[string]any{"status": 200, "value": 3}
+	})
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", time.Second)
+	value, err := client.ReadFloat(context.Background(), "kafka.server:type=ReplicaManager,name=UnderReplicatedPartitions", "Value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != 3 {
+		t.Errorf("expected 3, got %v", value)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		body any
+	}{
+		{
+			name: "HTTP error",
+			code: http.StatusInternalServerError,
+			body: map[string]any{},
+		},
+		{
+			name: "jolokia error status",
+			code: http.StatusOK,
+			body: map[string]any{"status": 404, "error": "javax.management.InstanceNotFoundException"},
+		},
+		{
+			name: "non-numeric value",
+			code: http.StatusOK,
+			body: map[string]any{"status": 200, "value": "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, func(readRequest) (int, any) { return tt.code, tt.body })
+			defer server.Close()
+
+			client := NewClient(server.URL, time.Second)
+			if _, err := client.ReadFloat(context.Background(), "kafka.server:type=Test", "Value"); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestReadPattern(t *testing.T) {
+	server := newTestServer(t, func(req readRequest) (int, any) {
+		return http.StatusOK, map[string]any{
+			"status": 200,
+			"value": map[string]any{
+				"kafka.server:client-id=app1,type=Produce,user=alice": map[string]any{"byte-rate": 1024.5},
+				"kafka.server:client-id=app2,type=Produce,user=bob":   map[string]any{"byte-rate": 2048},
+				"kafka.server:client-id=app3,type=Produce,user=carol": map[string]any{"other": 1},
+			},
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(server.URL, time.Second)
+	values, err := client.ReadPattern(context.Background(), "kafka.server:type=Produce,user=*,client-id=*", "byte-rate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("expected 2 values, got %d", len(values))
+	}
+	if values["kafka.server:client-id=app1,type=Produce,user=alice"] != 1024.5 {
+		t.Errorf("unexpected value for alice: %v", values)
+	}
+}
+
+func TestReadPatternNoMatch(t *testing.T) {
+	server := newTestServer(t, func(readRequest) (int, any) {
+		return http.StatusOK, map[string]any{"status": 404, "error": "javax.management.InstanceNotFoundException"}
+	})
+	defer server.Close()
+
+	client := NewClient(server.URL, time.Second)
+	values, err := client.ReadPattern(context.Background(), "kafka.server:type=Produce,client-id=*", "byte-rate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 0 {
+		t.Errorf("expected no values, got %v", values)
+	}
+
+	if _, err := client.ReadFloat(context.Background(), "kafka.server:type=Test", "Value"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseObjectName(t *testing.T) {
+	domain, props := ParseObjectName("kafka.server:type=Produce,user=alice,client-id=app1")
+	if domain != "kafka.server" {
+		t.Errorf("expected domain kafka.server, got %q", domain)
+	}
+	if props["type"] != "Produce" || props["user"] != "alice" || props["client-id"] != "app1" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+
+	domain, props = ParseObjectName("no-properties")
+	if domain != "no-properties" || len(props) != 0 {
+		t.Errorf("unexpected parse of name without properties: %q %v", domain, props)
+	}
+}
@@ -0,0 +1,126 @@
+package kafkaclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// caPools shares a CAPool between the clients using the same bundles, so the
+// bundles are only read again when they change
+var caPools = struct {
+	mu    sync.Mutex
+	pools map[string]*CAPool
+}{pools: map[string]*CAPool{}}
+
+// sharedCAPool returns the CAPool of the bundles, creating it on first use
+func sharedCAPool(files []string) *CAPool {
+	key := strings.Join(files, ",")
+	caPools.mu.Lock()
+	defer caPools.mu.Unlock()
+	if p, ok := caPools.pools[key]; ok {
+		return p
+	}
+	p := NewCAPool(files)
+	caPools.pools[key] = p
+	return p
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// CAPool holds the system roots plus the certificates of one or more PEM CA
+// bundles, and reloads them when a bundle changes on disk (e.g. a rotated
+// secret mount)
+type CAPool struct {
+	files []string
+
+	mu     sync.Mutex
+	pool   *x509.CertPool
+	stamps []fileStamp
+}
+
+// NewCAPool creates a pool for the CA bundles. The bundles are read on first use.
+func NewCAPool(files []string) *CAPool {
+	return &CAPool{files: files}
+}
+
+// Get returns the current pool, reloading the bundles if any has changed. When
+// a reload fails, e.g. while a bundle is being replaced, the previous pool is
+// kept.
+func (p *CAPool) Get() (*x509.CertPool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stamps := make([]fileStamp, len(p.files))
+	changed := p.pool == nil
+	for i, file := range p.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return p.previous(fmt.Errorf("failed to read CA bundle %s: %w", file, err))
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if !changed && stamps[i] != p.stamps[i] {
+			changed = true
+		}
+	}
+	if !changed {
+		return p.pool, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, file := range p.files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return p.previous(fmt.Errorf("failed to read CA bundle %s: %w", file, err))
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return p.previous(fmt.Errorf("no certificates in CA bundle %s", file))
+		}
+	}
+	p.pool = pool
+	p.stamps = stamps
+	return pool, nil
+}
+
+// previous returns the last loaded pool, or err before the first load
+func (p *CAPool) previous(err error) (*x509.CertPool, error) {
+	if p.pool != nil {
+		return p.pool, nil
+	}
+	return nil, err
+}
+
+// verifyConnection verifies the broker's certificate chain against the pool as
+// it is when each connection is made, so long-lived clients pick up rotated
+// bundles
+func (p *CAPool) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("no certificate served")
+	}
+	roots, err := p.Get()
+	if err != nil {
+		return err
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
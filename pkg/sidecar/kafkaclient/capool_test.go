@@ -0,0 +1,139 @@
+package kafkaclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a private CA that issues broker certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return testCA{cert: cert, key: key}
+}
+
+// issue returns a broker certificate for the host signed by the CA
+func (ca testCA) issue(t *testing.T, host string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+// writeBundle writes the CA as a PEM bundle with the given modification time
+func writeBundle(t *testing.T, path string, ca testCA, modTime time.Time) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write bundle: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set bundle time: %v", err)
+	}
+}
+
+func connection(cert *x509.Certificate) tls.ConnectionState {
+	return tls.ConnectionState{ServerName: "kafka-1", PeerCertificates: []*x509.Certificate{cert}}
+}
+
+func TestCAPoolReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	first, second := newTestCA(t, "first"), newTestCA(t, "second")
+	writeBundle(t, path, first, time.Now().Add(-time.Minute))
+
+	pool := NewCAPool([]string{path})
+	if err := pool.verifyConnection(connection(first.issue(t, "kafka-1"))); err != nil {
+		t.Fatalf("expected a certificate of the bundled CA to verify, got %v", err)
+	}
+	if err := pool.verifyConnection(connection(second.issue(t, "kafka-1"))); err == nil {
+		t.Fatal("expected a certificate of another CA to fail")
+	}
+	if err := pool.verifyConnection(tls.ConnectionState{ServerName: "kafka-2", PeerCertificates: []*x509.Certificate{first.issue(t, "kafka-1")}}); err == nil {
+		t.Fatal("expected a certificate for another host to fail")
+	}
+
+	// The rotated bundle is picked up by the next connection
+	writeBundle(t, path, second, time.Now())
+	if err := pool.verifyConnection(connection(second.issue(t, "kafka-1"))); err != nil {
+		t.Fatalf("expected the rotated bundle to be loaded, got %v", err)
+	}
+
+	// A bundle missing mid-rotation keeps the last pool
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove bundle: %v", err)
+	}
+	if err := pool.verifyConnection(connection(second.issue(t, "kafka-1"))); err != nil {
+		t.Errorf("expected the last pool to be kept, got %v", err)
+	}
+}
+
+func TestNewTLSConfigCAFiles(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write bundle: %v", err)
+	}
+	if _, err := NewTLSConfig(TLSConfig{Enabled: true, CAFiles: []string{empty}}); err == nil {
+		t.Error("expected an error for a bundle without certificates")
+	}
+	if _, err := NewTLSConfig(TLSConfig{Enabled: true, CAFiles: []string{filepath.Join(dir, "missing.pem")}}); err == nil {
+		t.Error("expected an error for a missing bundle")
+	}
+
+	bundle := filepath.Join(dir, "ca.pem")
+	writeBundle(t, bundle, newTestCA(t, "ca"), time.Now())
+	cfg, err := NewTLSConfig(TLSConfig{Enabled: true, CAFiles: []string{bundle}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.VerifyConnection == nil {
+		t.Error("expected connections to be verified against the bundle")
+	}
+}
@@ -0,0 +1,59 @@
+package kafkaclient
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// Credentials are SASL credentials that can be rotated while clients use them.
+// Clients read them whenever they authenticate a new connection; connections
+// that are already open keep their session.
+type Credentials struct {
+	v atomic.Pointer[[2]string]
+}
+
+// NewCredentials returns credentials holding username and password
+func NewCredentials(username, password string) *Credentials {
+	c := &Credentials{}
+	c.Set(username, password)
+	return c
+}
+
+// Set replaces the credentials
+func (c *Credentials) Set(username, password string) {
+	c.v.Store(&[2]string{username, password})
+}
+
+// Get returns the current credentials
+func (c *Credentials) Get() (username, password string) {
+	if v := c.v.Load(); v != nil {
+		return v[0], v[1]
+	}
+	return "", ""
+}
+
+// mechanism returns the SASL mechanism authenticating with the current
+// credentials, or nil for an unsupported mechanism
+func (c *Credentials) mechanism(name string) sasl.Mechanism {
+	scramAuth := func(context.Context) (scram.Auth, error) {
+		user, pass := c.Get()
+		return scram.Auth{User: user, Pass: pass}, nil
+	}
+	switch name {
+	case "PLAIN":
+		return plain.Plain(func(context.Context) (plain.Auth, error) {
+			user, pass := c.Get()
+			return plain.Auth{User: user, Pass: pass}, nil
+		})
+	case "SCRAM-SHA-256":
+		return scram.Sha256(scramAuth)
+	case "SCRAM-SHA-512":
+		return scram.Sha512(scramAuth)
+	default:
+		return nil
+	}
+}
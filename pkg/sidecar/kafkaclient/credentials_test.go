@@ -0,0 +1,33 @@
+package kafkaclient
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	creds := NewCredentials("sidecar", "old")
+	mechanism := creds.mechanism("PLAIN")
+	creds.Set("sidecar", "rotated")
+
+	// The mechanism reads the credentials when a connection authenticates
+	_, message, err := mechanism.Authenticate(context.Background(), "broker:9092")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(message) != "\x00sidecar\x00rotated" {
+		t.Errorf("expected the rotated credentials, got %q", message)
+	}
+}
+
+func TestSASLOptWithCredentials(t *testing.T) {
+	creds := NewCredentials("user", "pass")
+	for _, mechanism := range []string{"PLAIN", "SCRAM-SHA-256", "scram-sha-512"} {
+		if _, err := SASLOpt(SASLConfig{Enabled: true, Mechanism: mechanism, Credentials: creds}); err != nil {
+			t.Errorf("%s: unexpected error: %v", mechanism, err)
+		}
+	}
+	if _, err := SASLOpt(SASLConfig{Enabled: true, Mechanism: "GSSAPI", Credentials: creds}); err == nil {
+		t.Error("expected error for an unsupported mechanism")
+	}
+}
@@ -0,0 +1,184 @@
+package kafkaclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// SASLConfig holds SASL authentication configuration
+type SASLConfig struct {
+	Enabled   bool
+	Mechanism string // PLAIN, SCRAM-SHA-256, SCRAM-SHA-512
+	Username  string
+	Password  string
+	// Credentials, when set, replace Username and Password and are read on
+	// every new connection, so rotated credentials need no new clients
+	Credentials *Credentials
+}
+
+// TLSConfig holds TLS configuration for connections to the brokers
+type TLSConfig struct {
+	Enabled bool
+	// CertFile and KeyFile hold the client certificate presented to the brokers
+	// (optional, for mutual TLS)
+	CertFile string
+	KeyFile  string
+	// CAFiles are PEM bundles trusted in addition to the system roots
+	CAFiles []string
+}
+
+// Config holds the connection settings shared by every Kafka client the sidecar creates
+type Config struct {
+	BootstrapServers []string
+	SASL             SASLConfig
+	TLS              TLSConfig
+	// Retry sets the backoff between retried requests. The zero value keeps
+	// the franz-go default.
+	Retry retry.Policy
+}
+
+// ParseBootstrapServers splits a comma-separated bootstrap server list and trims whitespace
+func ParseBootstrapServers(bootstrapServers string) []string {
+	servers := strings.Split(bootstrapServers, ",")
+	for i := range servers {
+		servers[i] = strings.TrimSpace(servers[i])
+	}
+	return servers
+}
+
+// ParseCAFiles splits a comma-separated list of CA bundle paths, dropping empty entries
+func ParseCAFiles(caFiles string) []string {
+	var files []string
+	for _, file := range strings.Split(caFiles, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Options returns the franz-go client options for the given configuration
+func Options(cfg Config) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.BootstrapServers...),
+	}
+	if cfg.Retry.BaseDelay > 0 {
+		opts = append(opts, kgo.RetryBackoffFn(cfg.Retry.Delay))
+	}
+
+	// Dial with TLS if enabled
+	if cfg.TLS.Enabled {
+		tlsConfig, err := NewTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	// Add SASL authentication if enabled
+	if cfg.SASL.Enabled {
+		saslOpt, err := SASLOpt(cfg.SASL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SASL: %w", err)
+		}
+		opts = append(opts, saslOpt)
+	}
+
+	return opts, nil
+}
+
+// NewTLSConfig returns the TLS configuration for dialing the brokers. The client
+// certificate is read on every call, so rotated certificates are picked up by
+// the next client. CA bundles are reloaded whenever they change.
+func NewTLSConfig(tlsConfig TLSConfig) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if tlsConfig.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if len(tlsConfig.CAFiles) > 0 {
+		pool := sharedCAPool(tlsConfig.CAFiles)
+		// Fail fast on unreadable bundles rather than on every connection
+		if _, err := pool.Get(); err != nil {
+			return nil, err
+		}
+		// The chain is verified in VerifyConnection instead, against the bundles
+		// as they are when each connection is made
+		cfg.InsecureSkipVerify = true //nolint:gosec
+		cfg.VerifyConnection = pool.verifyConnection
+	}
+	return cfg, nil
+}
+
+// SASLOpt returns the appropriate SASL option based on mechanism
+func SASLOpt(saslConfig SASLConfig) (kgo.Opt, error) {
+	mechanism := strings.ToUpper(saslConfig.Mechanism)
+	if saslConfig.Credentials != nil {
+		if m := saslConfig.Credentials.mechanism(mechanism); m != nil {
+			return kgo.SASL(m), nil
+		}
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		auth := plain.Auth{
+			User: saslConfig.Username,
+			Pass: saslConfig.Password,
+		}
+		return kgo.SASL(auth.AsMechanism()), nil
+
+	case "SCRAM-SHA-256":
+		auth := scram.Auth{
+			User: saslConfig.Username,
+			Pass: saslConfig.Password,
+		}
+		return kgo.SASL(auth.AsSha256Mechanism()), nil
+
+	case "SCRAM-SHA-512":
+		auth := scram.Auth{
+			User: saslConfig.Username,
+			Pass: saslConfig.Password,
+		}
+		return kgo.SASL(auth.AsSha512Mechanism()), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism: %s (supported: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512)", mechanism)
+	}
+}
+
+// NewClient creates a new Kafka client and returns it with its cleanup function.
+// The client is tracked in Clients until the cleanup function is called.
+func NewClient(cfg Config, extra ...kgo.Opt) (*kgo.Client, func(), error) {
+	opts, err := Options(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, extra...)
+
+	cl, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+
+	return cl, Clients.Track(cl.Close), nil
+}
+
+// NewAdminClient creates a new Kafka admin client and returns it with its cleanup function
+func NewAdminClient(cfg Config, extra ...kgo.Opt) (*kadm.Client, func(), error) {
+	cl, cleanup, err := NewClient(cfg, extra...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kadm.NewClient(cl), cleanup, nil
+}
@@ -0,0 +1,141 @@
+package kafkaclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
+)
+
+func TestParseBootstrapServers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single server",
+			input:    "localhost:9092",
+			expected: []string{"localhost:9092"},
+		},
+		{
+			name:     "multiple servers with whitespace",
+			input:    "  broker1:9092  , broker2:9092,broker3:9092 ",
+			expected: []string{"broker1:9092", "broker2:9092", "broker3:9092"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := ParseBootstrapServers(tt.input)
+			if len(servers) != len(tt.expected) {
+				t.Fatalf("expected %d servers, got %d", len(tt.expected), len(servers))
+			}
+			for i, server := range tt.expected {
+				if servers[i] != server {
+					t.Errorf("expected server[%d] to be %q, got %q", i, server, servers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCAFiles(t *testing.T) {
+	files := ParseCAFiles(" /etc/ssl/ca.pem, ,/etc/ssl/intermediate.pem,")
+	if len(files) != 2 || files[0] != "/etc/ssl/ca.pem" || files[1] != "/etc/ssl/intermediate.pem" {
+		t.Errorf("unexpected files: %q", files)
+	}
+	if files := ParseCAFiles(""); files != nil {
+		t.Errorf("expected no files, got %q", files)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		sasl         SASLConfig
+		tls          TLSConfig
+		retry        retry.Policy
+		expectedOpts int
+		expectError  bool
+	}{
+		{
+			name:         "no SASL",
+			sasl:         SASLConfig{},
+			expectedOpts: 1,
+		},
+		{
+			name:         "SASL PLAIN",
+			sasl:         SASLConfig{Enabled: true, Mechanism: "PLAIN", Username: "user", Password: "pass"},
+			expectedOpts: 2,
+		},
+		{
+			name:        "unsupported SASL mechanism",
+			sasl:        SASLConfig{Enabled: true, Mechanism: "GSSAPI"},
+			expectError: true,
+		},
+		{
+			name:         "disabled SASL ignores mechanism",
+			sasl:         SASLConfig{Enabled: false, Mechanism: "GSSAPI"},
+			expectedOpts: 1,
+		},
+		{
+			name:         "TLS and SASL",
+			sasl:         SASLConfig{Enabled: true, Mechanism: "PLAIN", Username: "user", Password: "pass"},
+			tls:          TLSConfig{Enabled: true},
+			expectedOpts: 3,
+		},
+		{
+			name:         "retry backoff",
+			retry:        retry.Policy{Attempts: 3, BaseDelay: 100 * time.Millisecond},
+			expectedOpts: 2,
+		},
+		{
+			name:        "missing client certificate",
+			tls:         TLSConfig{Enabled: true, CertFile: "/nonexistent/tls.crt", KeyFile: "/nonexistent/tls.key"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := Options(Config{
+				BootstrapServers: []string{"localhost:9092"},
+				SASL:             tt.sasl,
+				TLS:              tt.tls,
+				Retry:            tt.retry,
+			})
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(opts) != tt.expectedOpts {
+				t.Errorf("expected %d options, got %d", tt.expectedOpts, len(opts))
+			}
+		})
+	}
+}
+
+func TestSASLOpt(t *testing.T) {
+	for _, mechanism := range []string{"PLAIN", "plain", "SCRAM-SHA-256", "scram-sha-512"} {
+		t.Run(mechanism, func(t *testing.T) {
+			opt, err := SASLOpt(SASLConfig{Enabled: true, Mechanism: mechanism, Username: "user", Password: "pass"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opt == nil {
+				t.Error("expected SASL option but got nil")
+			}
+		})
+	}
+
+	if _, err := SASLOpt(SASLConfig{Enabled: true, Mechanism: ""}); err == nil {
+		t.Error("expected error for empty mechanism")
+	}
+}
@@ -0,0 +1,154 @@
+package kafkaclient
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// Clients tracks every client created by NewClient and NewAdminClient
+var Clients = NewRegistry()
+
+// OpenClient is a tracked client that has not been closed yet
+type OpenClient struct {
+	ID       uint64
+	OpenedAt time.Time
+	// Stack is where the client was created
+	Stack string
+}
+
+// ClientStats counts the clients created and closed since startup
+type ClientStats struct {
+	Created uint64
+	Closed  uint64
+	Open    int
+}
+
+// Registry tracks open Kafka clients so that clients whose cleanup function is
+// never called show up before they exhaust broker connections
+type Registry struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	nextID  uint64
+	created uint64
+	closed  uint64
+	open    map[uint64]OpenClient
+}
+
+// NewRegistry creates an empty client registry
+func NewRegistry() *Registry {
+	return &Registry{clock: clock.Real, open: map[uint64]OpenClient{}}
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (r *Registry) SetClock(clk clock.Clock) {
+	r.clock = clk
+}
+
+// Track records a newly created client and wraps its close function. The
+// returned function untracks the client and closes it; calling it again is a no-op.
+func (r *Registry) Track(closeFn func()) func() {
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	r.created++
+	r.open[id] = OpenClient{ID: id, OpenedAt: r.clock.Now(), Stack: string(debug.Stack())}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.open, id)
+			r.closed++
+			r.mu.Unlock()
+			closeFn()
+		})
+	}
+}
+
+// Stats returns the number of clients created, closed and still open
+func (r *Registry) Stats() ClientStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ClientStats{Created: r.created, Closed: r.closed, Open: len(r.open)}
+}
+
+// OpenLongerThan returns the clients open for longer than age, oldest first
+func (r *Registry) OpenLongerThan(age time.Duration) []OpenClient {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.clock.Now().Add(-age)
+	var clients []OpenClient
+	for _, c := range r.open {
+		if c.OpenedAt.Before(cutoff) {
+			clients = append(clients, c)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	return clients
+}
+
+// LeakDetector periodically logs clients open longer than a threshold, with the
+// stack trace of where each was created
+type LeakDetector struct {
+	registry  *Registry
+	threshold time.Duration
+	logger    *slog.Logger
+
+	// reported holds the clients already logged, so each leak is logged once
+	reported map[uint64]bool
+}
+
+// NewLeakDetector creates a leak detector for clients of the registry
+func NewLeakDetector(registry *Registry, threshold time.Duration, logger *slog.Logger) *LeakDetector {
+	return &LeakDetector{
+		registry:  registry,
+		threshold: threshold,
+		logger:    logger,
+		reported:  map[uint64]bool{},
+	}
+}
+
+// Run checks for leaks every interval until the context is cancelled
+func (d *LeakDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := d.registry.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			d.Step()
+		}
+	}
+}
+
+// Step logs every client newly found open beyond the threshold and returns how
+// many clients are open beyond it
+func (d *LeakDetector) Step() int {
+	leaked := d.registry.OpenLongerThan(d.threshold)
+	current := make(map[uint64]bool, len(leaked))
+	for _, c := range leaked {
+		current[c.ID] = true
+		if d.reported[c.ID] {
+			continue
+		}
+		d.logger.Warn("kafka client open longer than leak threshold",
+			"clientId", c.ID,
+			"openedAt", c.OpenedAt,
+			"threshold", d.threshold,
+			"stack", c.Stack)
+	}
+	// Forget clients closed since, so the map does not grow
+	d.reported = current
+	return len(leaked)
+}
@@ -0,0 +1,82 @@
+package kafkaclient
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func TestRegistryTrack(t *testing.T) {
+	r := NewRegistry()
+
+	closed := 0
+	cleanup := r.Track(func() { closed++ })
+	r.Track(func() {})
+
+	if stats := r.Stats(); stats != (ClientStats{Created: 2, Closed: 0, Open: 2}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	cleanup()
+	// A second cleanup call must not close the client again or skew the counts
+	cleanup()
+
+	if closed != 1 {
+		t.Errorf("expected client to be closed once, got %d", closed)
+	}
+	if stats := r.Stats(); stats != (ClientStats{Created: 2, Closed: 1, Open: 1}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestRegistryOpenLongerThan(t *testing.T) {
+	r := NewRegistry()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	r.SetClock(clk)
+	r.Track(func() {})
+	clk.Advance(30 * time.Minute)
+	r.Track(func() {})
+	clk.Advance(45 * time.Minute)
+
+	if open := r.OpenLongerThan(time.Hour); len(open) != 1 || open[0].ID != 1 {
+		t.Errorf("expected only the first client open longer than an hour, got %+v", open)
+	}
+
+	open := r.OpenLongerThan(time.Minute)
+	if len(open) != 2 || open[0].ID != 1 || open[1].ID != 2 {
+		t.Fatalf("expected both clients oldest first, got %+v", open)
+	}
+	if !strings.Contains(open[0].Stack, "TestRegistryOpenLongerThan") {
+		t.Errorf("expected the stack to show where the client was created, got %s", open[0].Stack)
+	}
+}
+
+func TestLeakDetectorStep(t *testing.T) {
+	r := NewRegistry()
+	var logs bytes.Buffer
+	d := NewLeakDetector(r, 0, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	cleanup := r.Track(func() {})
+	r.Track(func() {})
+
+	if leaked := d.Step(); leaked != 2 {
+		t.Errorf("expected 2 leaked clients, got %d", leaked)
+	}
+	if n := strings.Count(logs.String(), "leak threshold"); n != 2 {
+		t.Errorf("expected 2 leaks logged, got %d", n)
+	}
+
+	// Leaks already reported are not logged again
+	cleanup()
+	logs.Reset()
+	if leaked := d.Step(); leaked != 1 {
+		t.Errorf("expected 1 leaked client, got %d", leaked)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no new leaks logged, got %s", logs.String())
+	}
+}
@@ -0,0 +1,77 @@
+package kraft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/serverprops"
+)
+
+// ClusterIDStore holds the cluster ID the broker must report: the configured
+// one, or else the first one it reported, kept in a file so that a replica
+// attached to another cluster's volume is caught after a restart too
+type ClusterIDStore struct {
+	file string
+
+	mu       sync.Mutex
+	expected string
+}
+
+// NewClusterIDStore returns a store expecting configured, or the ID stored in
+// file. Either may be empty. A stored ID other than the configured one is an
+// error: the file belongs to another cluster.
+func NewClusterIDStore(configured, file string) (*ClusterIDStore, error) {
+	s := &ClusterIDStore{file: file, expected: configured}
+	if file == "" {
+		return s, nil
+	}
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster ID file: %w", err)
+	}
+	stored := strings.TrimSpace(string(content))
+	if err := ValidateClusterID(stored); err != nil {
+		return nil, fmt.Errorf("invalid cluster ID file %s: %w", file, err)
+	}
+	if configured != "" && stored != configured {
+		return nil, fmt.Errorf("cluster ID file %s holds %s, expected %s", file, stored, configured)
+	}
+	s.expected = stored
+	return s, nil
+}
+
+// Expected returns the expected cluster ID, empty until one is known
+func (s *ClusterIDStore) Expected() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expected
+}
+
+// VerifyClusterID returns an error unless reported is the expected cluster ID.
+// Without one, reported becomes the expected ID and is stored.
+func (s *ClusterIDStore) VerifyClusterID(reported string) error {
+	if reported == "" {
+		return errors.New("the broker reports no cluster ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expected == "" {
+		if s.file != "" {
+			if err := serverprops.WriteFile(s.file, reported+"\n"); err != nil {
+				return fmt.Errorf("failed to store cluster ID: %w", err)
+			}
+		}
+		s.expected = reported
+		return nil
+	}
+	if reported != s.expected {
+		return fmt.Errorf("the broker reports cluster %s, expected %s", reported, s.expected)
+	}
+	return nil
+}
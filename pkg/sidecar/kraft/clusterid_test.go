@@ -0,0 +1,65 @@
+package kraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const otherClusterID = "AAAAAAAAAAAAAAAAAAAAAA"
+
+func TestClusterIDStoreConfigured(t *testing.T) {
+	store, err := NewClusterIDStore(testClusterID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.VerifyClusterID(testClusterID); err != nil {
+		t.Errorf("expected a match, got %v", err)
+	}
+	if err := store.VerifyClusterID(otherClusterID); err == nil {
+		t.Error("expected an error for another cluster")
+	}
+	if err := store.VerifyClusterID(""); err == nil {
+		t.Error("expected an error without a cluster ID")
+	}
+}
+
+func TestClusterIDStoreFirstBoot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cluster-id")
+	store, err := NewClusterIDStore("", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Expected() != "" {
+		t.Errorf("expected no cluster ID before the first boot, got %q", store.Expected())
+	}
+	if err := store.VerifyClusterID(testClusterID); err != nil {
+		t.Fatalf("expected the first ID to be stored, got %v", err)
+	}
+	if content, _ := os.ReadFile(file); strings.TrimSpace(string(content)) != testClusterID {
+		t.Errorf("expected %s stored, got %q", testClusterID, content)
+	}
+	if err := store.VerifyClusterID(otherClusterID); err == nil {
+		t.Error("expected an error for another cluster")
+	}
+
+	// The stored ID survives a restart
+	restarted, err := NewClusterIDStore("", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Expected() != testClusterID {
+		t.Errorf("expected %s after a restart, got %q", testClusterID, restarted.Expected())
+	}
+
+	if _, err := NewClusterIDStore(otherClusterID, file); err == nil {
+		t.Error("expected an error for a file of another cluster")
+	}
+	if err := os.WriteFile(file, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClusterIDStore("", file); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
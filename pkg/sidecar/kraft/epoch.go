@@ -0,0 +1,340 @@
+package kraft
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// EpochCheckName identifies the broker epoch loop in the freshness tracker
+const EpochCheckName = "broker_epoch"
+
+// EpochOptions configures the broker epoch tracker
+type EpochOptions struct {
+	// Dir is the node's metadata.log.dir, mounted into the sidecar
+	Dir string
+	// Interval is how often new metadata records are read
+	Interval time.Duration
+}
+
+// Registration is a broker's registration as recorded in the metadata log
+type Registration struct {
+	Registered bool  `json:"registered"`
+	Epoch      int64 `json:"epoch"`
+	Fenced     bool  `json:"fenced"`
+}
+
+// EpochReport is the local broker's registration as of the last read
+type EpochReport struct {
+	BrokerID int32 `json:"brokerId"`
+	Registration
+	// HighestEpoch is the highest epoch the broker registered with since the
+	// sidecar started, -1 before any registration
+	HighestEpoch int64 `json:"highestEpoch"`
+	// Offset is the metadata log offset records were read up to
+	Offset    int64     `json:"offset"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// EpochTracker follows the registration records of the local broker in the
+// KRaft metadata log, so a registration epoch that goes backwards or a broker
+// the controller has fenced shows up before clients start failing. Kafka
+// exposes neither to clients; fenced brokers merely drop out of the metadata.
+type EpochTracker struct {
+	brokerID int32
+	opts     EpochOptions
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+	clock    clock.Clock
+
+	// The read position and the state built from the records read, only used
+	// by Step
+	next      int64
+	positions map[string]int64
+	reg       Registration
+	highest   int64
+
+	mu     sync.RWMutex
+	report *EpochReport
+}
+
+// NewEpochTracker creates a new broker epoch tracker for the local broker
+func NewEpochTracker(brokerID int32, opts EpochOptions, logger *slog.Logger) *EpochTracker {
+	return &EpochTracker{
+		brokerID:  brokerID,
+		opts:      opts,
+		logger:    logger,
+		clock:     clock.Real,
+		positions: map[string]int64{},
+		highest:   -1,
+	}
+}
+
+// SetTracker records every read with the freshness tracker
+func (t *EpochTracker) SetTracker(tracker *freshness.Tracker) {
+	t.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (t *EpochTracker) SetClock(clk clock.Clock) {
+	t.clock = clk
+}
+
+// LastReport returns the report of the last read, and false before the first one
+func (t *EpochTracker) LastReport() (EpochReport, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.report == nil {
+		return EpochReport{}, false
+	}
+	return *t.report, true
+}
+
+// EpochError returns why the broker's registration is unhealthy: its epoch is
+// below one it registered with before, or the controller has fenced it. A
+// broker that is not registered is left to the metadata registration check.
+func (t *EpochTracker) EpochError() error {
+	report, ok := t.LastReport()
+	if !ok || !report.Registered {
+		return nil
+	}
+	if report.Epoch < report.HighestEpoch {
+		return fmt.Errorf("broker epoch regressed from %d to %d", report.HighestEpoch, report.Epoch)
+	}
+	if report.Fenced {
+		return fmt.Errorf("broker %d is fenced by the controller at epoch %d", t.brokerID, report.Epoch)
+	}
+	return nil
+}
+
+// Run reads new records every Interval until the context is cancelled
+func (t *EpochTracker) Run(ctx context.Context) {
+	ticker := t.clock.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+	t.tracker.Register(EpochCheckName)
+
+	for {
+		t.tracker.Record(EpochCheckName, t.Step())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step reads the records appended since the last step, logging when the
+// broker becomes fenced or its epoch regresses. A failed read keeps the
+// registration read before.
+func (t *EpochTracker) Step() error {
+	previous := t.EpochError()
+	err := t.read(filepath.Join(t.opts.Dir, PartitionDir))
+	report := EpochReport{
+		BrokerID:     t.brokerID,
+		Registration: t.reg,
+		HighestEpoch: t.highest,
+		Offset:       t.next,
+		CheckedAt:    t.clock.Now(),
+	}
+	if err != nil {
+		report.Error = err.Error()
+		t.logger.Warn("kraft: failed to read broker registration from metadata log", "dir", t.opts.Dir, "error", err)
+	}
+
+	t.mu.Lock()
+	t.report = &report
+	t.mu.Unlock()
+
+	current := t.EpochError()
+	switch {
+	case current != nil && (previous == nil || current.Error() != previous.Error()):
+		t.logger.Error("kraft: broker registration unhealthy", "brokerId", t.brokerID, "error", current)
+	case current == nil && previous != nil:
+		t.logger.Info("kraft: broker registration healthy again", "brokerId", t.brokerID, "epoch", report.Epoch)
+	}
+	return err
+}
+
+// segment is a log segment file and the offset of its first record
+type segment struct {
+	name       string
+	baseOffset int64
+	size       int64
+}
+
+// read applies the records of the metadata log past the ones already read.
+// A snapshot past them holds the whole state up to its end offset, so it
+// replaces the state instead. When a segment is truncated, as after a leader
+// change, the log is read again from the start.
+func (t *EpochTracker) read(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var segments []segment
+	var snapshot string
+	snapshotEnd := int64(-1)
+	for _, entry := range entries {
+		name := entry.Name()
+		if match := snapshotName.FindStringSubmatch(name); match != nil {
+			end, _ := strconv.ParseInt(match[1], 10, 64)
+			if end > snapshotEnd {
+				snapshot, snapshotEnd = name, end
+			}
+			continue
+		}
+		base, ok := strings.CutSuffix(name, ".log")
+		if !ok {
+			continue
+		}
+		baseOffset, err := strconv.ParseInt(base, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Kafka deletes segments as it truncates the log
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		segments = append(segments, segment{name: name, baseOffset: baseOffset, size: info.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].baseOffset < segments[j].baseOffset })
+
+	for _, s := range segments {
+		if s.size < t.positions[s.name] {
+			t.logger.Info("kraft: metadata log truncated, reading it again", "segment", s.name)
+			t.next, t.positions, t.reg = 0, map[string]int64{}, Registration{}
+			break
+		}
+	}
+
+	if snapshot != "" && snapshotEnd > t.next {
+		reg := Registration{}
+		if _, err := t.readFile(filepath.Join(dir, snapshot), 0, func(change brokerChange) {
+			reg = t.apply(reg, change)
+		}, nil); err != nil {
+			return fmt.Errorf("failed to read snapshot %s: %w", snapshot, err)
+		}
+		t.reg, t.next = reg, snapshotEnd
+	}
+
+	present := map[string]bool{}
+	for _, s := range segments {
+		present[s.name] = true
+		pos, err := t.readFile(filepath.Join(dir, s.name), t.positions[s.name], func(change brokerChange) {
+			t.reg = t.apply(t.reg, change)
+		}, &t.next)
+		if err != nil {
+			return fmt.Errorf("failed to read segment %s: %w", s.name, err)
+		}
+		t.positions[s.name] = pos
+	}
+	for name := range t.positions {
+		if !present[name] {
+			delete(t.positions, name)
+		}
+	}
+	return nil
+}
+
+// readFile passes the broker changes in the batches of the file from pos on
+// to apply, and returns the position after the last complete batch. With
+// next, records below it are skipped and it is advanced past every record
+// applied.
+func (t *EpochTracker) readFile(path string, pos int64, apply func(brokerChange), next *int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		// Kafka deletes segments as it truncates the log
+		if os.IsNotExist(err) {
+			return pos, nil
+		}
+		return pos, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(pos, io.SeekStart); err != nil {
+		return pos, err
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		size, records, err := readBatch(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, errPartialBatch) {
+			return pos, nil
+		}
+		if err != nil {
+			return pos, err
+		}
+		for _, record := range records {
+			if next != nil {
+				if record.offset < *next {
+					continue
+				}
+				*next = record.offset + 1
+			}
+			change, ok, err := decodeBrokerChange(record.value)
+			if err != nil {
+				return pos, fmt.Errorf("offset %d: %w", record.offset, err)
+			}
+			if ok {
+				apply(change)
+			}
+		}
+		pos += size
+	}
+}
+
+// apply returns reg changed by a record, recording the highest epoch the
+// local broker registered with
+func (t *EpochTracker) apply(reg Registration, change brokerChange) Registration {
+	if change.brokerID != t.brokerID {
+		return reg
+	}
+	switch change.apiKey {
+	case registerBrokerRecord:
+		reg = Registration{Registered: true, Epoch: change.epoch, Fenced: *change.fenced}
+		t.highest = max(t.highest, change.epoch)
+	case unregisterBrokerRecord:
+		reg = Registration{}
+	default:
+		if change.fenced != nil && reg.Registered {
+			reg.Fenced = *change.fenced
+		}
+	}
+	return reg
+}
+
+// StatusHandler handles GET /kraft/broker-epoch requests. It responds 503
+// when the broker's registration is unhealthy.
+func (t *EpochTracker) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := t.LastReport()
+	if !ok {
+		report = EpochReport{BrokerID: t.brokerID, HighestEpoch: -1, Error: "metadata log not read yet"}
+	}
+	code := http.StatusOK
+	if t.EpochError() != nil {
+		code = http.StatusServiceUnavailable
+	}
+	_, _ = web.ReturnResponseWithCode(w, report, code)
+}
@@ -0,0 +1,229 @@
+package kraft
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeBatch returns a record batch holding values from baseOffset on
+func encodeBatch(baseOffset int64, control bool, values ...[]byte) []byte {
+	var records []byte
+	for i, v := range values {
+		r := []byte{0}                       // attributes
+		r = binary.AppendVarint(r, 0)        // timestamp delta
+		r = binary.AppendVarint(r, int64(i)) // offset delta
+		r = binary.AppendVarint(r, -1)       // null key
+		r = binary.AppendVarint(r, int64(len(v)))
+		r = append(r, v...)
+		r = binary.AppendVarint(r, 0) // headers
+		records = binary.AppendVarint(records, int64(len(r)))
+		records = append(records, r...)
+	}
+
+	body := make([]byte, batchHeaderBytes)
+	body[4] = 2 // magic
+	if control {
+		binary.BigEndian.PutUint16(body[9:11], 0x20)
+	}
+	binary.BigEndian.PutUint32(body[11:15], uint32(len(values)-1))
+	binary.BigEndian.PutUint32(body[45:49], uint32(len(values)))
+	body = append(body, records...)
+	binary.BigEndian.PutUint32(body[5:9], crc32.Checksum(body[9:], castagnoli))
+
+	out := binary.BigEndian.AppendUint64(nil, uint64(baseOffset))
+	out = binary.BigEndian.AppendUint32(out, uint32(len(body)))
+	return append(out, body...)
+}
+
+func frame(apiKey, version uint64) []byte {
+	b := binary.AppendUvarint(nil, 1)
+	b = binary.AppendUvarint(b, apiKey)
+	return binary.AppendUvarint(b, version)
+}
+
+func compactString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)+1))
+	return append(b, s...)
+}
+
+// registerRecord returns a version 3 RegisterBrokerRecord
+func registerRecord(id int32, epoch int64, fenced bool) []byte {
+	b := frame(registerBrokerRecord, 3)
+	b = binary.BigEndian.AppendUint32(b, uint32(id))
+	b = append(b, 0)                   // IsMigratingZkBroker
+	b = append(b, make([]byte, 16)...) // IncarnationId
+	b = binary.BigEndian.AppendUint64(b, uint64(epoch))
+	b = binary.AppendUvarint(b, 2) // one endpoint
+	b = compactString(b, "INTERNAL")
+	b = compactString(b, "kafka-0.kafka")
+	b = binary.BigEndian.AppendUint16(b, 9092)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = append(b, 0)
+	b = binary.AppendUvarint(b, 2) // one feature
+	b = compactString(b, "metadata.version")
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, 20)
+	b = append(b, 0)
+	b = compactString(b, "aws-us-west-2")
+	if fenced {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = append(b, 0)               // InControlledShutdown
+	b = binary.AppendUvarint(b, 1) // no LogDirs
+	return append(b, 0)
+}
+
+// idEpochRecord returns an UnregisterBrokerRecord, FenceBrokerRecord or UnfenceBrokerRecord
+func idEpochRecord(apiKey uint64, id int32, epoch int64) []byte {
+	b := frame(apiKey, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(id))
+	b = binary.BigEndian.AppendUint64(b, uint64(epoch))
+	return append(b, 0)
+}
+
+// changeRecord returns a BrokerRegistrationChangeRecord with the tagged Fenced field
+func changeRecord(id int32, epoch int64, fenced int8) []byte {
+	b := frame(brokerRegistrationChangeRecord, 2)
+	b = binary.BigEndian.AppendUint32(b, uint32(id))
+	b = binary.BigEndian.AppendUint64(b, uint64(epoch))
+	b = binary.AppendUvarint(b, 1) // one tagged field
+	b = binary.AppendUvarint(b, 0)
+	b = binary.AppendUvarint(b, 1)
+	return append(b, byte(fenced))
+}
+
+func appendFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectEpochError(t *testing.T, tracker *EpochTracker, contains string) {
+	t.Helper()
+	if err := tracker.Step(); err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	err := tracker.EpochError()
+	switch {
+	case contains == "" && err != nil:
+		t.Errorf("expected a healthy registration, got %v", err)
+	case contains != "" && (err == nil || !strings.Contains(err.Error(), contains)):
+		t.Errorf("expected an error with %q, got %v", contains, err)
+	}
+}
+
+func TestEpochTracker(t *testing.T) {
+	dir := t.TempDir()
+	tracker := NewEpochTracker(1, EpochOptions{Dir: dir, Interval: time.Minute}, testLogger())
+
+	if err := tracker.Step(); err == nil {
+		t.Error("expected an error without a metadata log")
+	}
+	if err := tracker.EpochError(); err != nil {
+		t.Errorf("expected no error before the broker registered, got %v", err)
+	}
+
+	partition := filepath.Join(dir, PartitionDir)
+	if err := os.Mkdir(partition, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(partition, "00000000000000000000.log")
+
+	// A new registration is fenced until the broker catches up
+	appendFile(t, log, encodeBatch(0, true, []byte{0, 0}))
+	appendFile(t, log, encodeBatch(1, false, registerRecord(1, 10, true), registerRecord(2, 11, false)))
+	expectEpochError(t, tracker, "fenced")
+
+	appendFile(t, log, encodeBatch(3, false, idEpochRecord(unfenceBrokerRecord, 1, 10)))
+	expectEpochError(t, tracker, "")
+	report, _ := tracker.LastReport()
+	if !report.Registered || report.Epoch != 10 || report.HighestEpoch != 10 || report.Offset != 4 {
+		t.Errorf("expected epoch 10 read up to offset 4, got %+v", report)
+	}
+
+	// A batch still being written is left for the next step
+	batch := encodeBatch(4, false, changeRecord(1, 10, 1))
+	appendFile(t, log, batch[:len(batch)-3])
+	expectEpochError(t, tracker, "")
+	appendFile(t, log, batch[len(batch)-3:])
+	expectEpochError(t, tracker, "fenced by the controller at epoch 10")
+
+	appendFile(t, log, encodeBatch(5, false, changeRecord(1, 10, -1), idEpochRecord(fenceBrokerRecord, 2, 11)))
+	expectEpochError(t, tracker, "")
+
+	appendFile(t, log, encodeBatch(7, false, registerRecord(1, 5, false)))
+	expectEpochError(t, tracker, "regressed from 10 to 5")
+
+	appendFile(t, log, encodeBatch(8, false, registerRecord(1, 8, false)))
+	expectEpochError(t, tracker, "regressed from 10 to 8")
+
+	appendFile(t, log, encodeBatch(9, false, registerRecord(1, 12, false)))
+	expectEpochError(t, tracker, "")
+
+	// An unregistered broker is left to the metadata registration check
+	appendFile(t, log, encodeBatch(10, false, idEpochRecord(unregisterBrokerRecord, 1, 12)))
+	expectEpochError(t, tracker, "")
+	if report, _ := tracker.LastReport(); report.Registered || report.HighestEpoch != 12 {
+		t.Errorf("expected the broker unregistered, got %+v", report)
+	}
+}
+
+func TestEpochTrackerSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	partition := filepath.Join(dir, PartitionDir)
+	if err := os.Mkdir(partition, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The snapshot holds the state up to offset 50; records below it are not applied again
+	appendFile(t, filepath.Join(partition, "00000000000000000050-0000000003.checkpoint"),
+		append(encodeBatch(0, true, []byte{0, 0}), encodeBatch(1, false, registerRecord(1, 30, false))...))
+	log := filepath.Join(partition, "00000000000000000040.log")
+	appendFile(t, log, encodeBatch(40, false, registerRecord(1, 45, true)))
+	appendFile(t, log, encodeBatch(50, false, idEpochRecord(fenceBrokerRecord, 1, 30)))
+
+	tracker := NewEpochTracker(1, EpochOptions{Dir: dir, Interval: time.Minute}, testLogger())
+	expectEpochError(t, tracker, "fenced by the controller at epoch 30")
+	if report, _ := tracker.LastReport(); report.HighestEpoch != 30 || report.Offset != 51 {
+		t.Errorf("expected epoch 30 read up to offset 51, got %+v", report)
+	}
+
+	// A truncated log is read again
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := len(encodeBatch(40, false, registerRecord(1, 45, true)))
+	if err := os.WriteFile(log, data[:first], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectEpochError(t, tracker, "")
+	if report, _ := tracker.LastReport(); report.Epoch != 30 || report.Fenced {
+		t.Errorf("expected the snapshot's registration, got %+v", report)
+	}
+}
+
+func TestDecodeBrokerChange(t *testing.T) {
+	if _, ok, err := decodeBrokerChange(frame(3, 0)); ok || err != nil {
+		t.Errorf("expected other records to be ignored, got %v, %v", ok, err)
+	}
+	if _, _, err := decodeBrokerChange(registerRecord(1, 10, false)[:20]); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+	if _, _, err := decodeBrokerChange(append([]byte{2}, frame(0, 0)[1:]...)); err == nil {
+		t.Error("expected an error for an unknown frame version")
+	}
+}
@@ -0,0 +1,239 @@
+package kraft
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the metadata log inspection loop in the freshness tracker
+const CheckName = "kraft_metadata_log"
+
+// PartitionDir is the directory of the metadata log under metadata.log.dir
+const PartitionDir = "__cluster_metadata-0"
+
+// snapshotName matches a completed snapshot: <end offset>-<epoch>.checkpoint.
+// In-progress (.checkpoint.part) and deleted snapshots do not match.
+var snapshotName = regexp.MustCompile(`^(\d{20})-(\d{10})\.checkpoint$`)
+
+// Options configures the metadata log monitor
+type Options struct {
+	// Dir is the node's metadata.log.dir, mounted into the sidecar
+	Dir string
+	// Interval is how often the log directory is inspected
+	Interval time.Duration
+	// MaxBytes is the metadata log size above which growth is reported as
+	// runaway. Zero disables the check.
+	MaxBytes int64
+	// MaxSnapshotAge is how long without a new snapshot counts as runaway
+	// growth, since the log is only truncated after a snapshot. Zero disables it.
+	MaxSnapshotAge time.Duration
+}
+
+// Stats describes the metadata log and its snapshots
+type Stats struct {
+	// LogBytes is the size of the log segments, excluding indexes and snapshots
+	LogBytes int64 `json:"logBytes"`
+	Segments int   `json:"segments"`
+	// SnapshotBytes is the size of the completed snapshots
+	SnapshotBytes int64 `json:"snapshotBytes"`
+	Snapshots     int   `json:"snapshots"`
+	// LatestSnapshotOffset is the end offset of the newest snapshot, -1 without one
+	LatestSnapshotOffset int64 `json:"latestSnapshotOffset"`
+	// OldestSnapshotAt and LatestSnapshotAt are the snapshots' modification
+	// times, zero without snapshots
+	OldestSnapshotAt time.Time `json:"oldestSnapshotAt"`
+	LatestSnapshotAt time.Time `json:"latestSnapshotAt"`
+}
+
+// Report is the outcome of the last inspection
+type Report struct {
+	Stats
+	CheckedAt time.Time `json:"checkedAt"`
+	// Runaway lists why the log is considered to be growing out of control
+	Runaway []string `json:"runaway,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Monitor periodically inspects the KRaft metadata log on disk. Kafka exposes
+// no API for the log or snapshot sizes, so the sidecar reads the directory.
+type Monitor struct {
+	opts    Options
+	logger  *slog.Logger
+	tracker *freshness.Tracker
+	clock   clock.Clock
+
+	mu     sync.RWMutex
+	report *Report
+	// alerted is whether runaway growth has been logged and not yet recovered
+	alerted bool
+}
+
+// NewMonitor creates a new metadata log monitor
+func NewMonitor(opts Options, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		opts:   opts,
+		logger: logger,
+		clock:  clock.Real,
+	}
+}
+
+// SetTracker records every inspection with the freshness tracker
+func (m *Monitor) SetTracker(tracker *freshness.Tracker) {
+	m.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (m *Monitor) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
+
+// LastReport returns the last inspection, and false before the first one
+func (m *Monitor) LastReport() (Report, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.report == nil {
+		return Report{}, false
+	}
+	return *m.report, true
+}
+
+// Run inspects the log every Interval until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	m.tracker.Register(CheckName)
+
+	for {
+		m.tracker.Record(CheckName, m.Step())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step inspects the log once, logging an alert when growth becomes runaway and
+// again when it recovers
+func (m *Monitor) Step() error {
+	now := m.clock.Now()
+	stats, err := ReadStats(filepath.Join(m.opts.Dir, PartitionDir))
+	report := Report{Stats: stats, CheckedAt: now}
+	if err != nil {
+		report.Error = err.Error()
+		m.logger.Warn("kraft: failed to inspect metadata log", "dir", m.opts.Dir, "error", err)
+	} else {
+		report.Runaway = m.runaway(stats, now)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.report = &report
+	runaway := len(report.Runaway) > 0
+	switch {
+	case runaway && !m.alerted:
+		m.logger.Error("kraft: metadata log growth is runaway",
+			"reasons", report.Runaway,
+			"logBytes", stats.LogBytes,
+			"latestSnapshotAt", stats.LatestSnapshotAt)
+	case !runaway && m.alerted && err == nil:
+		m.logger.Info("kraft: metadata log growth recovered", "logBytes", stats.LogBytes)
+	}
+	if err == nil {
+		m.alerted = runaway
+	}
+	return err
+}
+
+// runaway lists the configured bounds the log exceeds
+func (m *Monitor) runaway(stats Stats, now time.Time) []string {
+	var reasons []string
+	if m.opts.MaxBytes > 0 && stats.LogBytes > m.opts.MaxBytes {
+		reasons = append(reasons, fmt.Sprintf("log is %d bytes, above %d", stats.LogBytes, m.opts.MaxBytes))
+	}
+	// Without a snapshot yet the log is young and bounded by MaxBytes alone
+	if m.opts.MaxSnapshotAge > 0 && !stats.LatestSnapshotAt.IsZero() {
+		if age := now.Sub(stats.LatestSnapshotAt); age > m.opts.MaxSnapshotAge {
+			reasons = append(reasons, fmt.Sprintf("no snapshot for %s, above %s", age.Round(time.Second), m.opts.MaxSnapshotAge))
+		}
+	}
+	return reasons
+}
+
+// ReadStats reads the segment and snapshot files of the metadata log partition
+// directory
+func ReadStats(dir string) (Stats, error) {
+	stats := Stats{LatestSnapshotOffset: -1}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		isSegment := strings.HasSuffix(name, ".log")
+		match := snapshotName.FindStringSubmatch(name)
+		if !isSegment && match == nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Kafka deletes segments and snapshots as it truncates the log
+			if os.IsNotExist(err) {
+				continue
+			}
+			return stats, err
+		}
+
+		if isSegment {
+			stats.Segments++
+			stats.LogBytes += info.Size()
+			continue
+		}
+
+		stats.Snapshots++
+		stats.SnapshotBytes += info.Size()
+		modified := info.ModTime()
+		if stats.OldestSnapshotAt.IsZero() || modified.Before(stats.OldestSnapshotAt) {
+			stats.OldestSnapshotAt = modified
+		}
+		offset, _ := strconv.ParseInt(match[1], 10, 64)
+		if offset > stats.LatestSnapshotOffset {
+			stats.LatestSnapshotOffset = offset
+			stats.LatestSnapshotAt = modified
+		}
+	}
+	return stats, nil
+}
+
+// StatusHandler handles GET /kraft/metadata-log requests. It responds 503 when
+// the log could not be inspected.
+func (m *Monitor) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := m.LastReport()
+	if !ok {
+		report = Report{Error: "metadata log not inspected yet"}
+	}
+	code := http.StatusOK
+	if report.Error != "" {
+		code = http.StatusServiceUnavailable
+	}
+	_, _ = web.ReturnResponseWithCode(w, report, code)
+}
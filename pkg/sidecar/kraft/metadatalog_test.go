@@ -0,0 +1,132 @@
+package kraft
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeFile creates a file of size bytes modified at mtime
+func writeFile(t *testing.T, dir, name string, size int, mtime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// metadataDir creates a metadata.log.dir with two segments and two snapshots,
+// the newest taken at epoch
+func metadataDir(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, PartitionDir)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "00000000000000000000.log", 100, epoch)
+	writeFile(t, dir, "00000000000000000000.index", 10, epoch)
+	writeFile(t, dir, "00000000000000005000.log", 50, epoch)
+	writeFile(t, dir, "00000000000000004000-0000000002.checkpoint", 20, epoch.Add(-time.Hour))
+	writeFile(t, dir, "00000000000000006000-0000000003.checkpoint", 30, epoch)
+	writeFile(t, dir, "00000000000000007000-0000000003.checkpoint.part", 5, epoch)
+	writeFile(t, dir, "quorum-state", 1, epoch)
+	return root
+}
+
+func TestReadStats(t *testing.T) {
+	stats, err := ReadStats(filepath.Join(metadataDir(t), PartitionDir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.LogBytes != 150 || stats.Segments != 2 {
+		t.Errorf("expected 2 segments of 150 bytes, got %d of %d", stats.Segments, stats.LogBytes)
+	}
+	if stats.Snapshots != 2 || stats.SnapshotBytes != 50 {
+		t.Errorf("expected 2 completed snapshots of 50 bytes, got %d of %d", stats.Snapshots, stats.SnapshotBytes)
+	}
+	if stats.LatestSnapshotOffset != 6000 || !stats.LatestSnapshotAt.Equal(epoch) {
+		t.Errorf("unexpected latest snapshot: %d at %v", stats.LatestSnapshotOffset, stats.LatestSnapshotAt)
+	}
+	if !stats.OldestSnapshotAt.Equal(epoch.Add(-time.Hour)) {
+		t.Errorf("unexpected oldest snapshot time: %v", stats.OldestSnapshotAt)
+	}
+}
+
+func TestReadStatsMissingDir(t *testing.T) {
+	if _, err := ReadStats(filepath.Join(t.TempDir(), PartitionDir)); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestStepRunaway(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		advance time.Duration
+		expect  int
+	}{
+		{
+			name:   "within bounds",
+			opts:   Options{MaxBytes: 1000, MaxSnapshotAge: 2 * time.Hour},
+			expect: 0,
+		},
+		{
+			name:   "log too large",
+			opts:   Options{MaxBytes: 100},
+			expect: 1,
+		},
+		{
+			name:    "snapshot too old",
+			opts:    Options{MaxSnapshotAge: 2 * time.Hour},
+			advance: 3 * time.Hour,
+			expect:  1,
+		},
+		{
+			name:    "checks disabled",
+			advance: 30 * 24 * time.Hour,
+			expect:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = metadataDir(t)
+			m := NewMonitor(tt.opts, testLogger())
+			clk := clock.NewFake(epoch)
+			clk.Advance(tt.advance)
+			m.SetClock(clk)
+
+			if err := m.Step(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			report, ok := m.LastReport()
+			if !ok || len(report.Runaway) != tt.expect {
+				t.Errorf("expected %d runaway reasons, got %v", tt.expect, report.Runaway)
+			}
+		})
+	}
+}
+
+func TestStepError(t *testing.T) {
+	m := NewMonitor(Options{Dir: t.TempDir()}, testLogger())
+	if err := m.Step(); err == nil {
+		t.Fatal("expected an error without a metadata log")
+	}
+	if report, _ := m.LastReport(); report.Error == "" {
+		t.Error("expected the error in the report")
+	}
+}
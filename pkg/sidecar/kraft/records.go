@@ -0,0 +1,295 @@
+package kraft
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Metadata record API keys of the broker registration records
+const (
+	registerBrokerRecord           = 0
+	unregisterBrokerRecord         = 1
+	fenceBrokerRecord              = 7
+	unfenceBrokerRecord            = 8
+	brokerRegistrationChangeRecord = 17
+)
+
+// batchHeaderBytes is the size of a record batch header after the base
+// offset and length
+const batchHeaderBytes = 49
+
+// maxBatchBytes bounds the record batches read from the log
+const maxBatchBytes = 64 << 20
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// errPartialBatch is returned for a batch cut off by the end of the file,
+// which Kafka may still be writing
+var errPartialBatch = errors.New("partial record batch")
+
+// logRecord is the offset and value of a record of a batch
+type logRecord struct {
+	offset int64
+	value  []byte
+}
+
+// readBatch reads the next record batch from r and returns its size and its
+// records, or none for control batches. It returns io.EOF at the end of the
+// log and errPartialBatch for a batch that is not completely written yet.
+func readBatch(r *bufio.Reader) (int64, []logRecord, error) {
+	var prefix [12]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, errPartialBatch
+		}
+		return 0, nil, err
+	}
+	baseOffset := int64(binary.BigEndian.Uint64(prefix[:8]))
+	length := int32(binary.BigEndian.Uint32(prefix[8:]))
+	// Preallocated segments end in zeros
+	if length == 0 {
+		return 0, nil, io.EOF
+	}
+	if length < batchHeaderBytes || length > maxBatchBytes {
+		return 0, nil, fmt.Errorf("invalid record batch length %d at offset %d", length, baseOffset)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return 0, nil, errPartialBatch
+		}
+		return 0, nil, err
+	}
+	size := int64(len(prefix)) + int64(length)
+
+	if magic := body[4]; magic != 2 {
+		return 0, nil, fmt.Errorf("unsupported record batch magic %d at offset %d", magic, baseOffset)
+	}
+	if crc := binary.BigEndian.Uint32(body[5:9]); crc != crc32.Checksum(body[9:], castagnoli) {
+		return 0, nil, fmt.Errorf("corrupt record batch at offset %d", baseOffset)
+	}
+	attributes := binary.BigEndian.Uint16(body[9:11])
+	if attributes&0x20 != 0 {
+		return size, nil, nil
+	}
+	if codec := attributes & 0x07; codec != 0 {
+		return 0, nil, fmt.Errorf("unsupported compressed record batch at offset %d", baseOffset)
+	}
+
+	count := int32(binary.BigEndian.Uint32(body[45:49]))
+	d := decoder{b: body[batchHeaderBytes:]}
+	records := make([]logRecord, 0, count)
+	for i := int32(0); i < count && d.err == nil; i++ {
+		rd := decoder{b: d.bytes(int(d.varint()))}
+		rd.int8() // attributes
+		rd.varint()
+		offsetDelta := rd.varint()
+		if keyLen := rd.varint(); keyLen > 0 {
+			rd.bytes(int(keyLen))
+		}
+		var value []byte
+		if valueLen := rd.varint(); valueLen >= 0 {
+			value = rd.bytes(int(valueLen))
+		}
+		if rd.err != nil {
+			d.err = rd.err
+			break
+		}
+		records = append(records, logRecord{offset: baseOffset + offsetDelta, value: value})
+	}
+	if d.err != nil {
+		return 0, nil, fmt.Errorf("invalid record batch at offset %d: %w", baseOffset, d.err)
+	}
+	return size, records, nil
+}
+
+// brokerChange is how a metadata record changes a broker's registration
+type brokerChange struct {
+	// apiKey is the type of the record
+	apiKey   uint64
+	brokerID int32
+	epoch    int64
+	// fenced is the fencing state after the record, nil when it is unchanged
+	fenced *bool
+}
+
+// decodeBrokerChange decodes the registration change in the value of a
+// metadata record, and returns false for records of other types
+func decodeBrokerChange(value []byte) (brokerChange, bool, error) {
+	d := decoder{b: value}
+	if frame := d.uvarint(); d.err == nil && frame != 1 {
+		return brokerChange{}, false, fmt.Errorf("unsupported metadata record frame version %d", frame)
+	}
+	apiKey := d.uvarint()
+	version := d.uvarint()
+	if d.err != nil {
+		return brokerChange{}, false, d.err
+	}
+
+	change := brokerChange{apiKey: apiKey}
+	fenced, unfenced := true, false
+	switch apiKey {
+	case registerBrokerRecord:
+		change.brokerID = d.int32()
+		if version >= 2 {
+			d.int8() // IsMigratingZkBroker
+		}
+		d.bytes(16) // IncarnationId
+		change.epoch = d.int64()
+		for n := d.compactArrayLen(); n > 0 && d.err == nil; n-- { // EndPoints
+			d.compactString()
+			d.compactString()
+			d.int16()
+			d.int16()
+			d.taggedFields(nil)
+		}
+		for n := d.compactArrayLen(); n > 0 && d.err == nil; n-- { // Features
+			d.compactString()
+			d.int16()
+			d.int16()
+			d.taggedFields(nil)
+		}
+		d.compactString() // Rack
+		isFenced := d.int8() != 0
+		change.fenced = &isFenced
+	case unregisterBrokerRecord:
+		change.brokerID = d.int32()
+		change.epoch = d.int64()
+	case fenceBrokerRecord:
+		change.brokerID = d.int32()
+		change.epoch = d.int64()
+		change.fenced = &fenced
+	case unfenceBrokerRecord:
+		change.brokerID = d.int32()
+		change.epoch = d.int64()
+		change.fenced = &unfenced
+	case brokerRegistrationChangeRecord:
+		change.brokerID = d.int32()
+		change.epoch = d.int64()
+		// Fenced is tagged: 1 fences, -1 unfences and 0 leaves it unchanged
+		d.taggedFields(func(tag uint64, field *decoder) {
+			if tag != 0 {
+				return
+			}
+			switch field.int8() {
+			case 1:
+				change.fenced = &fenced
+			case -1:
+				change.fenced = &unfenced
+			}
+		})
+	default:
+		return brokerChange{}, false, nil
+	}
+	if d.err != nil {
+		return brokerChange{}, false, fmt.Errorf("invalid metadata record %d: %w", apiKey, d.err)
+	}
+	return change, true, nil
+}
+
+// decoder reads Kafka's wire encoding, keeping the first error
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.b) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	out := d.b[:n]
+	d.b = d.b[n:]
+	return out
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.bytes(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.bytes(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.bytes(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.bytes(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.err = errors.New("invalid varint")
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errors.New("invalid unsigned varint")
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+// compactArrayLen reads the length of a compact array, zero for a null one
+func (d *decoder) compactArrayLen() int {
+	n := d.uvarint()
+	if n == 0 {
+		return 0
+	}
+	return int(n - 1)
+}
+
+// compactString reads a compact, possibly null, string
+func (d *decoder) compactString() string {
+	n := d.uvarint()
+	if n == 0 {
+		return ""
+	}
+	return string(d.bytes(int(n - 1)))
+}
+
+// taggedFields reads the tagged fields of a flexible structure, passing each
+// to fn when set
+func (d *decoder) taggedFields(fn func(tag uint64, field *decoder)) {
+	for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+		tag := d.uvarint()
+		field := decoder{b: d.bytes(int(d.uvarint()))}
+		if fn != nil && d.err == nil {
+			fn(tag, &field)
+		}
+	}
+}
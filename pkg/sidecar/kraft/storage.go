@@ -0,0 +1,177 @@
+package kraft
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/serverprops"
+)
+
+// MetaPropertiesFile is the file identifying the cluster and node a log
+// directory belongs to
+const MetaPropertiesFile = "meta.properties"
+
+// MetaPropertiesVersion is the meta.properties version of KRaft nodes. Version
+// 0 is written by ZooKeeper brokers.
+const MetaPropertiesVersion = 1
+
+// MetaProperties is the content of a log directory's meta.properties
+type MetaProperties struct {
+	Version   int    `json:"version"`
+	ClusterID string `json:"clusterId"`
+	NodeID    int32  `json:"nodeId"`
+	// DirectoryID identifies the log directory within the node, for JBOD
+	DirectoryID string `json:"directoryId,omitempty"`
+}
+
+// String returns the content of the meta.properties file, as Kafka writes it
+func (m MetaProperties) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "version=%d\n", m.Version)
+	fmt.Fprintf(&b, "cluster.id=%s\n", m.ClusterID)
+	fmt.Fprintf(&b, "node.id=%d\n", m.NodeID)
+	if m.DirectoryID != "" {
+		fmt.Fprintf(&b, "directory.id=%s\n", m.DirectoryID)
+	}
+	return b.String()
+}
+
+// Check returns an error unless m is a KRaft node's meta.properties for
+// clusterID and nodeID. Kafka refuses to start on such a mismatch, with an
+// InconsistentClusterIdException for the cluster ID, and restarts forever.
+func (m MetaProperties) Check(clusterID string, nodeID int32) error {
+	if m.Version != MetaPropertiesVersion {
+		return fmt.Errorf("version %d is not a KRaft node's", m.Version)
+	}
+	if m.ClusterID != clusterID {
+		return fmt.Errorf("cluster.id is %s, expected %s: the volume belongs to another cluster", m.ClusterID, clusterID)
+	}
+	if m.NodeID != nodeID {
+		return fmt.Errorf("node.id is %d, expected %d: the volume belongs to another node", m.NodeID, nodeID)
+	}
+	return nil
+}
+
+// ParseMetaProperties reads the content of a meta.properties file
+func ParseMetaProperties(content string) (MetaProperties, error) {
+	props, err := serverprops.Parse(content)
+	if err != nil {
+		return MetaProperties{}, err
+	}
+	var m MetaProperties
+	if m.Version, err = strconv.Atoi(props["version"]); err != nil {
+		return MetaProperties{}, fmt.Errorf("invalid version %q", props["version"])
+	}
+	if m.ClusterID = props["cluster.id"]; m.ClusterID == "" {
+		return MetaProperties{}, errors.New("cluster.id is missing")
+	}
+	// ZooKeeper brokers wrote broker.id instead
+	id := props["node.id"]
+	if id == "" {
+		id = props["broker.id"]
+	}
+	nodeID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return MetaProperties{}, fmt.Errorf("invalid node.id %q", id)
+	}
+	m.NodeID = int32(nodeID)
+	m.DirectoryID = props["directory.id"]
+	return m, nil
+}
+
+// ReadMetaProperties reads the meta.properties of the log directory dir. The
+// error wraps os.ErrNotExist when the directory is unformatted.
+func ReadMetaProperties(dir string) (MetaProperties, error) {
+	content, err := os.ReadFile(filepath.Join(dir, MetaPropertiesFile))
+	if err != nil {
+		return MetaProperties{}, err
+	}
+	m, err := ParseMetaProperties(string(content))
+	if err != nil {
+		return MetaProperties{}, fmt.Errorf("invalid %s: %w", MetaPropertiesFile, err)
+	}
+	return m, nil
+}
+
+// ValidateClusterID checks that id is a Kafka cluster ID: a UUID in unpadded
+// URL-safe base64, as kafka-storage random-uuid prints it
+func ValidateClusterID(id string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(decoded) != 16 {
+		return fmt.Errorf("%q is not a base64 UUID of 22 characters", id)
+	}
+	return nil
+}
+
+// NewDirectoryID returns a random directory.id. Like Kafka, it avoids IDs
+// starting with a dash, which would read as a command-line flag.
+func NewDirectoryID() (string, error) {
+	for {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if id := base64.RawURLEncoding.EncodeToString(b); id[0] != '-' {
+			return id, nil
+		}
+	}
+}
+
+// StorageResult is the outcome of PrepareStorage for one log directory
+type StorageResult struct {
+	Dir string `json:"dir"`
+	// Formatted reports whether meta.properties was written, the directory
+	// being fresh
+	Formatted bool `json:"formatted"`
+}
+
+// PrepareStorage checks the meta.properties of every log directory against
+// clusterID and nodeID, and writes one to the directories without, so a fresh
+// volume needs no kafka-storage format. Without bootstrap.checkpoint, Kafka
+// bootstraps the metadata version from inter.broker.protocol.version. A
+// mismatch is reported before anything is written.
+func PrepareStorage(dirs []string, clusterID string, nodeID int32) ([]StorageResult, error) {
+	fresh := map[string]bool{}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("log directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("log directory %s is not a directory", dir)
+		}
+		m, err := ReadMetaProperties(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			fresh[dir] = true
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("log directory %s: %w", dir, err)
+		}
+		if err := m.Check(clusterID, nodeID); err != nil {
+			return nil, fmt.Errorf("log directory %s: %w", dir, err)
+		}
+	}
+
+	results := make([]StorageResult, 0, len(dirs))
+	for _, dir := range dirs {
+		if fresh[dir] {
+			directoryID, err := NewDirectoryID()
+			if err != nil {
+				return nil, err
+			}
+			m := MetaProperties{Version: MetaPropertiesVersion, ClusterID: clusterID, NodeID: nodeID, DirectoryID: directoryID}
+			if err := serverprops.WriteFile(filepath.Join(dir, MetaPropertiesFile), m.String()); err != nil {
+				return nil, fmt.Errorf("log directory %s: %w", dir, err)
+			}
+		}
+		results = append(results, StorageResult{Dir: dir, Formatted: fresh[dir]})
+	}
+	return results, nil
+}
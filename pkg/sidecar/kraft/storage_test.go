@@ -0,0 +1,121 @@
+package kraft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testClusterID = "MkU3OEVBNTcwNTJENDM2Qg"
+
+func TestParseMetaProperties(t *testing.T) {
+	m, err := ParseMetaProperties("#\n#Thu Jan 01 00:00:00 UTC 2026\nnode.id=3\ndirectory.id=J8aAPcfLQt2bqs1JT_rMgQ\nversion=1\ncluster.id=" + testClusterID + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MetaProperties{Version: 1, ClusterID: testClusterID, NodeID: 3, DirectoryID: "J8aAPcfLQt2bqs1JT_rMgQ"}
+	if m != expected {
+		t.Errorf("expected %+v, got %+v", expected, m)
+	}
+
+	// ZooKeeper brokers wrote version 0 with broker.id
+	m, err = ParseMetaProperties("version=0\nbroker.id=2\ncluster.id=" + testClusterID + "\n")
+	if err != nil || m.NodeID != 2 || m.Version != 0 {
+		t.Errorf("expected a version 0 file of broker 2, got %+v, %v", m, err)
+	}
+
+	for _, content := range []string{"cluster.id=x\nnode.id=1\n", "version=1\nnode.id=1\n", "version=1\ncluster.id=x\nnode.id=one\n"} {
+		if _, err := ParseMetaProperties(content); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestMetaPropertiesRoundTrip(t *testing.T) {
+	m := MetaProperties{Version: 1, ClusterID: testClusterID, NodeID: 7, DirectoryID: "J8aAPcfLQt2bqs1JT_rMgQ"}
+	parsed, err := ParseMetaProperties(m.String())
+	if err != nil || parsed != m {
+		t.Errorf("expected %+v, got %+v, %v", m, parsed, err)
+	}
+}
+
+func TestMetaPropertiesCheck(t *testing.T) {
+	m := MetaProperties{Version: 1, ClusterID: testClusterID, NodeID: 1}
+	if err := m.Check(testClusterID, 1); err != nil {
+		t.Errorf("expected a match, got %v", err)
+	}
+	if err := m.Check("AAAAAAAAAAAAAAAAAAAAAA", 1); err == nil {
+		t.Error("expected an error for another cluster")
+	}
+	if err := m.Check(testClusterID, 2); err == nil {
+		t.Error("expected an error for another node")
+	}
+	m.Version = 0
+	if err := m.Check(testClusterID, 1); err == nil {
+		t.Error("expected an error for a ZooKeeper broker's directory")
+	}
+}
+
+func TestValidateClusterID(t *testing.T) {
+	if err := ValidateClusterID(testClusterID); err != nil {
+		t.Errorf("expected valid, got %v", err)
+	}
+	for _, id := range []string{"", "kafka-cluster", testClusterID + "==", "MkU3OEVBNTcwNTJENDM2"} {
+		if err := ValidateClusterID(id); err == nil {
+			t.Errorf("expected an error for %q", id)
+		}
+	}
+}
+
+func TestNewDirectoryID(t *testing.T) {
+	a, err := NewDirectoryID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewDirectoryID()
+	if a == b {
+		t.Error("expected distinct IDs")
+	}
+	if err := ValidateClusterID(a); err != nil {
+		t.Errorf("expected a base64 UUID, got %v", err)
+	}
+}
+
+func TestPrepareStorage(t *testing.T) {
+	formatted, fresh := t.TempDir(), t.TempDir()
+	existing := MetaProperties{Version: 1, ClusterID: testClusterID, NodeID: 1, DirectoryID: "J8aAPcfLQt2bqs1JT_rMgQ"}
+	if err := os.WriteFile(filepath.Join(formatted, MetaPropertiesFile), []byte(existing.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := PrepareStorage([]string{formatted, fresh}, testClusterID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Formatted || !results[1].Formatted {
+		t.Errorf("expected only the fresh directory formatted, got %+v", results)
+	}
+	written, err := ReadMetaProperties(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := written.Check(testClusterID, 1); err != nil || written.DirectoryID == "" || written.DirectoryID == existing.DirectoryID {
+		t.Errorf("unexpected meta.properties %+v, %v", written, err)
+	}
+	if kept, _ := ReadMetaProperties(formatted); kept != existing {
+		t.Errorf("expected the existing meta.properties kept, got %+v", kept)
+	}
+
+	// A mismatch leaves fresh directories alone
+	other := t.TempDir()
+	if _, err := PrepareStorage([]string{other, formatted}, testClusterID, 2); err == nil {
+		t.Error("expected an error for another node's directory")
+	}
+	if _, err := os.Stat(filepath.Join(other, MetaPropertiesFile)); !os.IsNotExist(err) {
+		t.Error("expected nothing written after a mismatch")
+	}
+
+	if _, err := PrepareStorage([]string{filepath.Join(fresh, "missing")}, testClusterID, 1); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,183 @@
+package latency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the request latency loop in the freshness tracker
+const CheckName = "request_latency"
+
+// Requester sends requests to a single broker. *kgo.Broker implements it.
+type Requester interface {
+	Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error)
+}
+
+// ClientFactory creates a requester for the local broker. Allows injection for testing.
+type ClientFactory func() (Requester, func(), error)
+
+// Observer receives the round-trip time of every probed request, or why it failed
+type Observer interface {
+	ObserveRequest(api string, rtt time.Duration, err error)
+}
+
+// Options configures the request latency prober
+type Options struct {
+	// Interval is how often the requests are probed
+	Interval time.Duration
+	// Timeout bounds each round of probes
+	Timeout time.Duration
+}
+
+// probe is a request whose round trip is timed
+type probe struct {
+	api     string
+	request func() kmsg.Request
+}
+
+// probes are cheap requests served by every broker without touching the log
+// (ApiVersions), from the metadata cache (Metadata) and by the partition
+// managers (ListOffsets). Empty topic lists keep the responses small.
+var probes = []probe{
+	{api: "ApiVersions", request: func() kmsg.Request {
+		return kmsg.NewPtrApiVersionsRequest()
+	}},
+	{api: "Metadata", request: func() kmsg.Request {
+		req := kmsg.NewPtrMetadataRequest()
+		// A nil topic list requests every topic
+		req.Topics = []kmsg.MetadataRequestTopic{}
+		return req
+	}},
+	{api: "ListOffsets", request: func() kmsg.Request {
+		return kmsg.NewPtrListOffsetsRequest()
+	}},
+}
+
+// Prober periodically times requests against the local broker, giving a
+// control-plane latency signal independent of the produce/consume canary
+type Prober struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	observer      Observer
+	clock         clock.Clock
+}
+
+// NewProber creates a new request latency prober for the local broker
+func NewProber(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Prober {
+	p := &Prober{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+	}
+	// Set default client factory
+	p.clientFactory = p.defaultClientFactory
+	return p
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (p *Prober) SetClientFactory(factory ClientFactory) {
+	p.clientFactory = factory
+}
+
+// SetTracker records every round of probes with the freshness tracker
+func (p *Prober) SetTracker(tracker *freshness.Tracker) {
+	p.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (p *Prober) SetClock(clk clock.Clock) {
+	p.clock = clk
+}
+
+// SetObserver reports every probed request to the observer
+func (p *Prober) SetObserver(observer Observer) {
+	p.observer = observer
+}
+
+// defaultClientFactory creates a franz-go client and addresses the local broker
+func (p *Prober) defaultClientFactory() (Requester, func(), error) {
+	cl, cleanup, err := kafkaclient.NewClient(p.kafkaConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cl.Broker(int(p.brokerID)), cleanup, nil
+}
+
+// Run probes every Interval until the context is cancelled
+func (p *Prober) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	p.tracker.Register(CheckName)
+
+	for {
+		p.tracker.Record(CheckName, p.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step times one request of every probed API against the local broker and
+// returns the failures
+func (p *Prober) Step(ctx context.Context) error {
+	broker, cleanup, err := p.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	// The first request dials and authenticates; keep that out of the samples
+	if _, err := broker.Request(ctx, kmsg.NewPtrApiVersionsRequest()); err != nil {
+		err = fmt.Errorf("failed to connect to broker %d: %w", p.brokerID, err)
+		p.logger.Warn("request latency: probe failed", "error", err)
+		return err
+	}
+
+	var errs []error
+	for _, pr := range probes {
+		start := p.clock.Now()
+		err := check(broker.Request(ctx, pr.request()))
+		rtt := p.clock.Since(start)
+		if p.observer != nil {
+			p.observer.ObserveRequest(pr.api, rtt, err)
+		}
+		if err != nil {
+			p.logger.Warn("request latency: probe failed", "api", pr.api, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", pr.api, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check turns a top-level error code in the response into an error
+func check(resp kmsg.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if r, ok := resp.(*kmsg.ApiVersionsResponse); ok {
+		return kerr.ErrorForCode(r.ErrorCode)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package latency
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockRequester is a mock implementation of Requester for testing
+type MockRequester struct {
+	RequestFunc func(ctx context.Context, req kmsg.Request) (kmsg.Response, error)
+}
+
+func (m *MockRequester) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	if m.RequestFunc != nil {
+		return m.RequestFunc(ctx, req)
+	}
+	return req.ResponseKind(), nil
+}
+
+// recordingObserver collects the requests it observes
+type recordingObserver struct {
+	apis   []string
+	failed []string
+}
+
+func (o *recordingObserver) ObserveRequest(api string, _ time.Duration, err error) {
+	o.apis = append(o.apis, api)
+	if err != nil {
+		o.failed = append(o.failed, api)
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func newTestProber(requester Requester) (*Prober, *recordingObserver) {
+	p := NewProber(2, kafkaclient.Config{}, Options{Interval: time.Minute, Timeout: 10 * time.Second}, testLogger())
+	p.SetClientFactory(func() (Requester, func(), error) {
+		return requester, func() {}, nil
+	})
+	observer := &recordingObserver{}
+	p.SetObserver(observer)
+	return p, observer
+}
+
+func TestStep(t *testing.T) {
+	var sent []int16
+	p, observer := newTestProber(&MockRequester{
+		RequestFunc: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+			sent = append(sent, req.Key())
+			if md, ok := req.(*kmsg.MetadataRequest); ok && md.Topics == nil {
+				t.Error("expected metadata for no topics, got a request for every topic")
+			}
+			return req.ResponseKind(), nil
+		},
+	})
+
+	if err := p.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// One unobserved ApiVersions request opens the connection
+	if len(sent) != 4 {
+		t.Errorf("expected 4 requests, got %d", len(sent))
+	}
+	if strings.Join(observer.apis, ",") != "ApiVersions,Metadata,ListOffsets" {
+		t.Errorf("unexpected observed requests: %v", observer.apis)
+	}
+}
+
+func TestStepFailures(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestFunc    func(ctx context.Context, req kmsg.Request) (kmsg.Response, error)
+		expectObserved int
+		expectFailed   []string
+	}{
+		{
+			name: "connection fails",
+			requestFunc: func(context.Context, kmsg.Request) (kmsg.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+		{
+			name: "request fails",
+			requestFunc: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+				if _, ok := req.(*kmsg.ListOffsetsRequest); ok {
+					return nil, context.DeadlineExceeded
+				}
+				return req.ResponseKind(), nil
+			},
+			expectObserved: 3,
+			expectFailed:   []string{"ListOffsets"},
+		},
+		{
+			name: "error code",
+			requestFunc: func() func(context.Context, kmsg.Request) (kmsg.Response, error) {
+				calls := 0
+				return func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+					calls++
+					if calls == 2 {
+						resp := kmsg.NewPtrApiVersionsResponse()
+						resp.ErrorCode = kerr.UnsupportedVersion.Code
+						return resp, nil
+					}
+					return req.ResponseKind(), nil
+				}
+			}(),
+			expectObserved: 3,
+			expectFailed:   []string{"ApiVersions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, observer := newTestProber(&MockRequester{RequestFunc: tt.requestFunc})
+
+			if err := p.Step(context.Background()); err == nil {
+				t.Error("expected an error")
+			}
+			if len(observer.apis) != tt.expectObserved {
+				t.Errorf("expected %d observed requests, got %v", tt.expectObserved, observer.apis)
+			}
+			if strings.Join(observer.failed, ",") != strings.Join(tt.expectFailed, ",") {
+				t.Errorf("expected failed requests %v, got %v", tt.expectFailed, observer.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package listeners computes the broker's advertised.listeners: the replica's
+// own DNS name for clients and brokers inside the GVC, and optionally an
+// external endpoint with a port per broker, as behind one load balancer.
+package listeners
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Default listener names
+const (
+	DefaultInternalName = "INTERNAL"
+	DefaultExternalName = "EXTERNAL"
+)
+
+// EnvVar is the variable of the env file that holds advertised.listeners
+const EnvVar = "KAFKA_ADVERTISED_LISTENERS"
+
+// listenerName matches a Kafka listener name
+var listenerName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Listener is one entry of advertised.listeners
+type Listener struct {
+	Name string `json:"name"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// String returns the listener as listed in advertised.listeners: NAME://host:port
+func (l Listener) String() string {
+	return l.Name + "://" + net.JoinHostPort(l.Host, strconv.Itoa(l.Port))
+}
+
+// External is an endpoint reaching the brokers from outside the GVC
+type External struct {
+	Name string
+	// Host is the external hostname. {id} is replaced by the broker ID and
+	// {index} by the replica index, for a hostname per broker. Empty disables
+	// the external listener.
+	Host string
+	// Port is the external port of every broker not in Ports
+	Port int
+	// Ports maps broker IDs to their own external port, for brokers sharing a host
+	Ports map[int32]int
+}
+
+// Options describes the listeners of a broker
+type Options struct {
+	// InternalName is the name of the listener on the replica's own DNS name
+	InternalName string
+	// InternalHost is the replica's own DNS name, e.g. its headless Service record
+	InternalHost string
+	InternalPort int
+	External     External
+}
+
+// Validate checks the listener names and ports
+func (o Options) Validate() error {
+	if !listenerName.MatchString(o.InternalName) {
+		return fmt.Errorf("invalid listener name %q", o.InternalName)
+	}
+	if o.External.Host == "" {
+		return nil
+	}
+	if !listenerName.MatchString(o.External.Name) {
+		return fmt.Errorf("invalid listener name %q", o.External.Name)
+	}
+	if o.External.Name == o.InternalName {
+		return fmt.Errorf("the internal and external listeners are both named %s", o.InternalName)
+	}
+	if !validPort(o.External.Port) {
+		return fmt.Errorf("invalid external port %d", o.External.Port)
+	}
+	return nil
+}
+
+// Advertised returns the advertised listeners of the broker with the given ID
+// and replica index: the internal listener, then the external one if enabled
+func Advertised(brokerID int32, index int, opts Options) ([]Listener, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.InternalHost == "" {
+		return nil, errors.New("the replica's hostname is unknown")
+	}
+	listeners := []Listener{{Name: opts.InternalName, Host: opts.InternalHost, Port: opts.InternalPort}}
+	if opts.External.Host == "" {
+		return listeners, nil
+	}
+
+	port := opts.External.Port
+	if mapped, ok := opts.External.Ports[brokerID]; ok {
+		port = mapped
+	}
+	return append(listeners, Listener{
+		Name: opts.External.Name,
+		Host: ExpandHost(opts.External.Host, brokerID, index),
+		Port: port,
+	}), nil
+}
+
+// ExpandHost replaces {id} with the broker ID and {index} with the replica index in host
+func ExpandHost(host string, brokerID int32, index int) string {
+	return strings.NewReplacer(
+		"{id}", strconv.Itoa(int(brokerID)),
+		"{index}", strconv.Itoa(index),
+	).Replace(host)
+}
+
+// Format joins listeners into an advertised.listeners value
+func Format(listeners []Listener) string {
+	entries := make([]string, len(listeners))
+	for i, l := range listeners {
+		entries[i] = l.String()
+	}
+	return strings.Join(entries, ",")
+}
+
+// ParsePortMap parses a comma-separated list of brokerID=port pairs, e.g.
+// 0=31090,1=31091
+func ParsePortMap(s string) (map[int32]int, error) {
+	ports := map[int32]int{}
+	owners := map[int]int32{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		idValue, portValue, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected brokerID=port", pair)
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(idValue), 10, 32)
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid broker ID %q", idValue)
+		}
+		port, err := strconv.Atoi(strings.TrimSpace(portValue))
+		if err != nil || !validPort(port) {
+			return nil, fmt.Errorf("invalid port for broker %d: %q", id, portValue)
+		}
+		if _, dup := ports[int32(id)]; dup {
+			return nil, fmt.Errorf("duplicate broker ID %d", id)
+		}
+		if other, dup := owners[port]; dup {
+			return nil, fmt.Errorf("port %d is mapped to both broker %d and %d", port, other, id)
+		}
+		ports[int32(id)] = port
+		owners[port] = int32(id)
+	}
+	return ports, nil
+}
+
+// validPort reports whether port is a TCP port number
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
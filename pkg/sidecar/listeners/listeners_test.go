@@ -0,0 +1,82 @@
+package listeners
+
+import "testing"
+
+func TestAdvertised(t *testing.T) {
+	internal := Options{
+		InternalName: DefaultInternalName,
+		InternalHost: "kafka-1.kafka.abc123.svc.cluster.local",
+		InternalPort: 9092,
+	}
+	tests := []struct {
+		name        string
+		opts        Options
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "internal only",
+			opts:     internal,
+			expected: "INTERNAL://kafka-1.kafka.abc123.svc.cluster.local:9092",
+		},
+		{
+			name: "external host per broker",
+			opts: Options{
+				InternalName: internal.InternalName, InternalHost: internal.InternalHost, InternalPort: internal.InternalPort,
+				External: External{Name: DefaultExternalName, Host: "kafka-{id}.example.com", Port: 9094},
+			},
+			expected: "INTERNAL://kafka-1.kafka.abc123.svc.cluster.local:9092,EXTERNAL://kafka-101.example.com:9094",
+		},
+		{
+			name: "external port per broker",
+			opts: Options{
+				InternalName: internal.InternalName, InternalHost: internal.InternalHost, InternalPort: internal.InternalPort,
+				External: External{Name: DefaultExternalName, Host: "kafka.example.com", Port: 9094, Ports: map[int32]int{101: 31091}},
+			},
+			expected: "INTERNAL://kafka-1.kafka.abc123.svc.cluster.local:9092,EXTERNAL://kafka.example.com:31091",
+		},
+		{
+			name: "same names",
+			opts: Options{
+				InternalName: internal.InternalName, InternalHost: internal.InternalHost, InternalPort: internal.InternalPort,
+				External: External{Name: DefaultInternalName, Host: "kafka.example.com", Port: 9094},
+			},
+			expectError: true,
+		},
+		{name: "invalid name", opts: Options{InternalName: "internal", InternalHost: "kafka-1", InternalPort: 9092}, expectError: true},
+		{name: "unknown host", opts: Options{InternalName: DefaultInternalName, InternalPort: 9092}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listeners, err := Advertised(101, 1, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %v", listeners)
+				}
+				return
+			}
+			if err != nil || Format(listeners) != tt.expected {
+				t.Errorf("expected %q, got %q, %v", tt.expected, Format(listeners), err)
+			}
+		})
+	}
+}
+
+func TestExpandHost(t *testing.T) {
+	if got := ExpandHost("b{index}-{id}.example.com", 102, 2); got != "b2-102.example.com" {
+		t.Errorf("expected b2-102.example.com, got %s", got)
+	}
+}
+
+func TestParsePortMap(t *testing.T) {
+	ports, err := ParsePortMap("0=31090, 1=31091")
+	if err != nil || len(ports) != 2 || ports[1] != 31091 {
+		t.Errorf("expected two ports, got %v, %v", ports, err)
+	}
+	for _, invalid := range []string{"0", "x=31090", "0=70000", "0=31090,0=31091", "0=31090,1=31090"} {
+		if _, err := ParsePortMap(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package logdirs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the partition size sampling loop in the freshness tracker
+const CheckName = "partition_sizes"
+
+// AdminClient defines the Kafka admin operations needed to size partitions.
+// This enables mocking in tests.
+type AdminClient interface {
+	DescribeBrokerLogDirs(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the partition size sampler
+type Options struct {
+	// Interval is how often the broker's log directories are described
+	Interval time.Duration
+	// TopN keeps only the N largest partitions. Zero keeps every partition up
+	// to MaxSeries.
+	TopN int
+	// MaxSeries caps how many partitions are kept, guarding the metrics
+	// endpoint against brokers hosting many thousands of partitions
+	MaxSeries int
+	// Timeout bounds each request
+	Timeout time.Duration
+}
+
+// Partition is the size of one partition replica on the broker
+type Partition struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Dir       string `json:"dir"`
+	Bytes     int64  `json:"bytes"`
+	// Future is set for a replica being moved into this directory
+	Future bool `json:"future,omitempty"`
+}
+
+// Dir is the total size of a log directory's partitions
+type Dir struct {
+	Dir        string `json:"dir"`
+	Bytes      int64  `json:"bytes"`
+	Partitions int    `json:"partitions"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Sample is the outcome of the last description of the broker's log directories
+type Sample struct {
+	// Dirs cover every partition, including those left out of Partitions
+	Dirs []Dir `json:"dirs"`
+	// Partitions are the largest partitions, largest first
+	Partitions []Partition `json:"partitions"`
+	// Omitted counts the partitions left out by TopN or MaxSeries
+	Omitted   int       `json:"omitted"`
+	SampledAt time.Time `json:"sampledAt"`
+}
+
+// Sampler periodically describes the local broker's log directories to size
+// every partition replica it hosts
+type Sampler struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	sample *Sample
+}
+
+// NewSampler creates a new partition size sampler for the local broker
+func NewSampler(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Sampler {
+	s := &Sampler{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+	}
+	// Set default client factory
+	s.clientFactory = s.defaultClientFactory
+	return s
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (s *Sampler) SetClientFactory(factory ClientFactory) {
+	s.clientFactory = factory
+}
+
+// SetTracker records every sample with the freshness tracker
+func (s *Sampler) SetTracker(tracker *freshness.Tracker) {
+	s.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Sampler) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (s *Sampler) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(s.kafkaConfig)
+}
+
+// LastSample returns the last sample, and false before the first successful one
+func (s *Sampler) LastSample() (Sample, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.sample == nil {
+		return Sample{}, false
+	}
+	return *s.sample, true
+}
+
+// Run samples every Interval until the context is cancelled
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	s.tracker.Register(CheckName)
+
+	for {
+		s.tracker.Record(CheckName, s.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step describes the broker's log directories once. A failed sample keeps the
+// previous one, so gauges do not flap to absent on a transient error.
+func (s *Sampler) Step(ctx context.Context) error {
+	adm, cleanup, err := s.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	described, err := adm.DescribeBrokerLogDirs(ctx, s.brokerID, nil)
+	if err != nil {
+		s.logger.Warn("logdirs: failed to describe log directories", "broker", s.brokerID, "error", err)
+		return fmt.Errorf("failed to describe log directories: %w", err)
+	}
+
+	sample := s.summarize(described)
+	sample.SampledAt = s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sample = &sample
+	return nil
+}
+
+// summarize totals every directory and keeps the largest partitions
+func (s *Sampler) summarize(described kadm.DescribedLogDirs) Sample {
+	var sample Sample
+	for _, d := range described.Sorted() {
+		dir := Dir{Dir: d.Dir}
+		if d.Err != nil {
+			dir.Error = d.Err.Error()
+		}
+		d.Topics.Each(func(p kadm.DescribedLogDirPartition) {
+			dir.Bytes += p.Size
+			dir.Partitions++
+			sample.Partitions = append(sample.Partitions, Partition{
+				Topic:     p.Topic,
+				Partition: p.Partition,
+				Dir:       p.Dir,
+				Bytes:     p.Size,
+				Future:    p.IsFuture,
+			})
+		})
+		sample.Dirs = append(sample.Dirs, dir)
+	}
+
+	sort.Slice(sample.Partitions, func(i, j int) bool {
+		a, b := sample.Partitions[i], sample.Partitions[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		return a.Dir < b.Dir
+	})
+
+	limit := s.opts.MaxSeries
+	if s.opts.TopN > 0 && (limit <= 0 || s.opts.TopN < limit) {
+		limit = s.opts.TopN
+	}
+	if limit > 0 && len(sample.Partitions) > limit {
+		sample.Omitted = len(sample.Partitions) - limit
+		sample.Partitions = sample.Partitions[:limit]
+	}
+	return sample
+}
@@ -0,0 +1,138 @@
+package logdirs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	DescribeBrokerLogDirsFunc func(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error)
+}
+
+func (m *MockAdminClient) DescribeBrokerLogDirs(ctx context.Context, broker int32, topics kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+	if m.DescribeBrokerLogDirsFunc != nil {
+		return m.DescribeBrokerLogDirsFunc(ctx, broker, topics)
+	}
+	return kadm.DescribedLogDirs{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// logDirs returns two directories on broker 1: /data/a with orders-0 (300
+// bytes) and orders-1 (100 bytes), and /data/b with events-0 (200 bytes)
+func logDirs() kadm.DescribedLogDirs {
+	partition := func(dir, topic string, p int32, size int64) kadm.DescribedLogDirPartition {
+		return kadm.DescribedLogDirPartition{Broker: 1, Dir: dir, Topic: topic, Partition: p, Size: size}
+	}
+	return kadm.DescribedLogDirs{
+		"/data/a": {Broker: 1, Dir: "/data/a", Topics: kadm.DescribedLogDirTopics{
+			"orders": {0: partition("/data/a", "orders", 0, 300), 1: partition("/data/a", "orders", 1, 100)},
+		}},
+		"/data/b": {Broker: 1, Dir: "/data/b", Topics: kadm.DescribedLogDirTopics{
+			"events": {0: partition("/data/b", "events", 0, 200)},
+		}},
+	}
+}
+
+func newTestSampler(opts Options, mock *MockAdminClient) *Sampler {
+	opts.Timeout = time.Second
+	s := NewSampler(1, kafkaclient.Config{}, opts, testLogger())
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return mock, func() {}, nil
+	})
+	return s
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		expectLargest []int64
+		expectOmitted int
+	}{
+		{
+			name:          "every partition",
+			expectLargest: []int64{300, 200, 100},
+		},
+		{
+			name:          "top N",
+			opts:          Options{TopN: 2, MaxSeries: 1000},
+			expectLargest: []int64{300, 200},
+			expectOmitted: 1,
+		},
+		{
+			name:          "series cap below top N",
+			opts:          Options{TopN: 2, MaxSeries: 1},
+			expectLargest: []int64{300},
+			expectOmitted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSampler(tt.opts, &MockAdminClient{
+				DescribeBrokerLogDirsFunc: func(_ context.Context, broker int32, _ kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+					if broker != 1 {
+						t.Errorf("expected the local broker to be described, got %d", broker)
+					}
+					return logDirs(), nil
+				},
+			})
+
+			if err := s.Step(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sample, ok := s.LastSample()
+			if !ok {
+				t.Fatal("expected a sample")
+			}
+			if len(sample.Partitions) != len(tt.expectLargest) || sample.Omitted != tt.expectOmitted {
+				t.Fatalf("expected %d partitions and %d omitted, got %+v", len(tt.expectLargest), tt.expectOmitted, sample)
+			}
+			for i, bytes := range tt.expectLargest {
+				if sample.Partitions[i].Bytes != bytes {
+					t.Errorf("expected partition %d to be %d bytes, got %+v", i, bytes, sample.Partitions[i])
+				}
+			}
+			// Directory totals include omitted partitions
+			if len(sample.Dirs) != 2 || sample.Dirs[0].Bytes != 400 || sample.Dirs[1].Bytes != 200 {
+				t.Errorf("unexpected directory totals: %+v", sample.Dirs)
+			}
+		})
+	}
+}
+
+func TestStepErrorKeepsLastSample(t *testing.T) {
+	fail := false
+	s := newTestSampler(Options{}, &MockAdminClient{
+		DescribeBrokerLogDirsFunc: func(context.Context, int32, kadm.TopicsSet) (kadm.DescribedLogDirs, error) {
+			if fail {
+				return nil, errors.New("connection refused")
+			}
+			return logDirs(), nil
+		},
+	})
+
+	if _, ok := s.LastSample(); ok {
+		t.Fatal("expected no sample before the first step")
+	}
+	_ = s.Step(context.Background())
+	fail = true
+	if err := s.Step(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if sample, ok := s.LastSample(); !ok || len(sample.Partitions) != 3 {
+		t.Errorf("expected the previous sample to be kept, got %+v", sample)
+	}
+}
@@ -0,0 +1,46 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// defaultReadDisks asks every broker for its log directories. Volume sizes are
+// only in DescribeLogDirs v4+, which kadm does not surface, so the request is
+// issued directly. An empty topic list describes the directories without
+// listing their partitions.
+func (s *Scorer) defaultReadDisks(ctx context.Context) ([]DirUsage, error) {
+	cl, cleanup, err := kafkaclient.NewClient(s.kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	req := kmsg.NewPtrDescribeLogDirsRequest()
+	req.Topics = []kmsg.DescribeLogDirsRequestTopic{}
+
+	var dirs []DirUsage
+	for _, shard := range cl.RequestSharded(ctx, req) {
+		if shard.Err != nil {
+			return nil, fmt.Errorf("broker %d: %w", shard.Meta.NodeID, shard.Err)
+		}
+		resp := shard.Resp.(*kmsg.DescribeLogDirsResponse)
+		for _, d := range resp.Dirs {
+			if err := kerr.ErrorForCode(d.ErrorCode); err != nil {
+				return nil, fmt.Errorf("broker %d log dir %s: %w", shard.Meta.NodeID, d.Dir, err)
+			}
+			dirs = append(dirs, DirUsage{
+				Broker:      shard.Meta.NodeID,
+				Dir:         d.Dir,
+				TotalBytes:  d.TotalBytes,
+				UsableBytes: d.UsableBytes,
+			})
+		}
+	}
+	return dirs, nil
+}
@@ -0,0 +1,402 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the maintenance safety loop in the freshness tracker
+const CheckName = "maintenance_safety"
+
+// Component names of the score breakdown
+const (
+	ComponentUnderReplicated = "under_replicated"
+	ComponentOffline         = "offline"
+	ComponentReassignments   = "reassignments"
+	ComponentZoneSpread      = "zone_spread"
+	ComponentController      = "controller"
+	ComponentDiskHeadroom    = "disk_headroom"
+)
+
+// AdminClient defines the Kafka admin operations needed to score the cluster.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// DiskReader returns the volume usage of every broker's log directories
+type DiskReader func(ctx context.Context) ([]DirUsage, error)
+
+// DirUsage is the volume usage of a broker's log directory
+type DirUsage struct {
+	Broker int32
+	Dir    string
+	// TotalBytes and UsableBytes are -1 when the broker does not report them
+	TotalBytes  int64
+	UsableBytes int64
+}
+
+// Options configures the maintenance safety score
+type Options struct {
+	// Interval is how often the score is recomputed
+	Interval time.Duration
+	// ControllerStablePeriod is how long the controller must stay put after a
+	// change before it counts as stable
+	ControllerStablePeriod time.Duration
+	// DiskMinFreeRatio is the free share of every log directory volume below
+	// which disk headroom is too low for maintenance
+	DiskMinFreeRatio float64
+	// SafeScore is the lowest score reported as safe
+	SafeScore int
+	// Timeout bounds each computation
+	Timeout time.Duration
+}
+
+// Component is one signal of the score
+type Component struct {
+	Name   string `json:"name"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// Report is the maintenance safety score with its breakdown
+type Report struct {
+	// Score is 0-100: the lowest component score, since a single unsafe signal
+	// makes the whole cluster unsafe to take a broker down
+	Score      int         `json:"score"`
+	Safe       bool        `json:"safe"`
+	CheckedAt  time.Time   `json:"checkedAt"`
+	Components []Component `json:"components"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Scorer periodically combines cluster health signals into a single score that
+// change-management tooling can gate maintenance on
+type Scorer struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	readDisks     DiskReader
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	report *Report
+	// controller is the last controller seen, -1 before the first computation
+	controller int32
+	// controllerChangedAt is when the controller was last seen changing
+	controllerChangedAt time.Time
+}
+
+// NewScorer creates a new maintenance safety scorer
+func NewScorer(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Scorer {
+	s := &Scorer{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		controller:  -1,
+	}
+	// Set default client factory and disk reader
+	s.clientFactory = s.defaultClientFactory
+	s.readDisks = s.defaultReadDisks
+	return s
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (s *Scorer) SetClientFactory(factory ClientFactory) {
+	s.clientFactory = factory
+}
+
+// SetDiskReader allows overriding the disk reader for testing
+func (s *Scorer) SetDiskReader(reader DiskReader) {
+	s.readDisks = reader
+}
+
+// SetTracker records every computation with the freshness tracker
+func (s *Scorer) SetTracker(tracker *freshness.Tracker) {
+	s.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Scorer) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (s *Scorer) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(s.kafkaConfig)
+}
+
+// LastReport returns the last computed score, and false before the first one
+func (s *Scorer) LastReport() (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.report == nil {
+		return Report{}, false
+	}
+	return *s.report, true
+}
+
+// Run recomputes the score every Interval until the context is cancelled
+func (s *Scorer) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	s.tracker.Register(CheckName)
+
+	for {
+		s.tracker.Record(CheckName, s.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step recomputes the score. When the cluster cannot be described the score is
+// 0, as nothing is known to be safe.
+func (s *Scorer) Step(ctx context.Context) error {
+	report, err := s.compute(ctx)
+	if err != nil {
+		report = Report{Error: err.Error()}
+		s.logger.Warn("maintenance: failed to compute safety score", "error", err)
+	}
+	report.CheckedAt = s.clock.Now()
+	report.Safe = err == nil && report.Score >= s.opts.SafeScore
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report = &report
+	return err
+}
+
+func (s *Scorer) compute(ctx context.Context) (Report, error) {
+	adm, cleanup, err := s.clientFactory()
+	if err != nil {
+		return Report{}, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	reassignments, err := adm.ListPartitionReassignments(ctx, md.Topics.TopicsSet())
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list partition reassignments: %w", err)
+	}
+	dirs, disksErr := s.readDisks(ctx)
+
+	components := []Component{
+		underReplicated(md),
+		offline(md),
+		reassigning(md, reassignments),
+		zoneSpread(md),
+		s.controllerStability(md.Controller),
+		diskHeadroom(dirs, disksErr, s.opts.DiskMinFreeRatio),
+	}
+	report := Report{Score: 100, Components: components}
+	for _, c := range components {
+		if c.Score < report.Score {
+			report.Score = c.Score
+		}
+	}
+	return report, nil
+}
+
+// partial scores a signal where bad of total items are unhealthy: 100 when
+// none are, and at most 50 falling to 0 as more are
+func partial(bad, total int) int {
+	if bad == 0 {
+		return 100
+	}
+	if total <= 0 || bad >= total {
+		return 0
+	}
+	return 50 * (total - bad) / total
+}
+
+func underReplicated(md kadm.Metadata) Component {
+	bad, total := 0, 0
+	md.Topics.EachPartition(func(p kadm.PartitionDetail) {
+		total++
+		if len(p.ISR) < len(p.Replicas) {
+			bad++
+		}
+	})
+	return Component{
+		Name:   ComponentUnderReplicated,
+		Score:  partial(bad, total),
+		Detail: fmt.Sprintf("%d of %d partitions under-replicated", bad, total),
+	}
+}
+
+// offline scores 0 on any partition without a leader, since its data is
+// already unavailable
+func offline(md kadm.Metadata) Component {
+	bad, total := 0, 0
+	md.Topics.EachPartition(func(p kadm.PartitionDetail) {
+		total++
+		if p.Leader < 0 {
+			bad++
+		}
+	})
+	score := 100
+	if bad > 0 {
+		score = 0
+	}
+	return Component{
+		Name:   ComponentOffline,
+		Score:  score,
+		Detail: fmt.Sprintf("%d of %d partitions offline", bad, total),
+	}
+}
+
+func reassigning(md kadm.Metadata, rs kadm.ListPartitionReassignmentsResponses) Component {
+	total := 0
+	md.Topics.EachPartition(func(kadm.PartitionDetail) { total++ })
+	bad := 0
+	rs.Each(func(r kadm.ListPartitionReassignmentsResponse) {
+		if len(r.AddingReplicas) > 0 || len(r.RemovingReplicas) > 0 {
+			bad++
+		}
+	})
+	return Component{
+		Name:   ComponentReassignments,
+		Score:  partial(bad, total),
+		Detail: fmt.Sprintf("%d partitions being reassigned", bad),
+	}
+}
+
+// zoneSpread counts partitions whose replicas span fewer racks than they could,
+// which lose more than one replica when a single zone goes down for maintenance
+func zoneSpread(md kadm.Metadata) Component {
+	racks := map[int32]string{}
+	distinct := map[string]bool{}
+	for _, b := range md.Brokers {
+		if b.Rack != nil && *b.Rack != "" {
+			racks[b.NodeID] = *b.Rack
+			distinct[*b.Rack] = true
+		}
+	}
+	if len(distinct) < 2 {
+		return Component{
+			Name:   ComponentZoneSpread,
+			Score:  100,
+			Detail: fmt.Sprintf("brokers span %d rack(s); spread not applicable", len(distinct)),
+		}
+	}
+
+	bad, total := 0, 0
+	md.Topics.EachPartition(func(p kadm.PartitionDetail) {
+		total++
+		spanned := map[string]bool{}
+		for _, r := range p.Replicas {
+			if rack, ok := racks[r]; ok {
+				spanned[rack] = true
+			}
+		}
+		if len(spanned) < min(len(p.Replicas), len(distinct)) {
+			bad++
+		}
+	})
+	return Component{
+		Name:   ComponentZoneSpread,
+		Score:  partial(bad, total),
+		Detail: fmt.Sprintf("%d of %d partitions not spread across %d racks", bad, total, len(distinct)),
+	}
+}
+
+// controllerStability scores 0 without an active controller and 50 while a
+// controller change is recent
+func (s *Scorer) controllerStability(controller int32) Component {
+	now := s.clock.Now()
+	s.mu.Lock()
+	if s.controller >= 0 && controller != s.controller {
+		s.controllerChangedAt = now
+	}
+	s.controller = controller
+	changedAt := s.controllerChangedAt
+	s.mu.Unlock()
+
+	switch {
+	case controller < 0:
+		return Component{Name: ComponentController, Score: 0, Detail: "no active controller"}
+	case !changedAt.IsZero() && now.Sub(changedAt) < s.opts.ControllerStablePeriod:
+		return Component{
+			Name:   ComponentController,
+			Score:  50,
+			Detail: fmt.Sprintf("controller %d elected %s ago", controller, now.Sub(changedAt).Truncate(time.Second)),
+		}
+	default:
+		return Component{Name: ComponentController, Score: 100, Detail: fmt.Sprintf("controller %d stable", controller)}
+	}
+}
+
+// diskHeadroom scores the fullest log directory volume: 100 at or above the
+// minimum free ratio, falling from 50 to 0 as it fills up
+func diskHeadroom(dirs []DirUsage, err error, minFreeRatio float64) Component {
+	if err != nil {
+		return Component{Name: ComponentDiskHeadroom, Score: 0, Detail: fmt.Sprintf("failed to describe log dirs: %v", err)}
+	}
+
+	lowest := -1.0
+	var fullest DirUsage
+	for _, d := range dirs {
+		if d.TotalBytes <= 0 || d.UsableBytes < 0 {
+			continue
+		}
+		free := float64(d.UsableBytes) / float64(d.TotalBytes)
+		if lowest < 0 || free < lowest {
+			lowest, fullest = free, d
+		}
+	}
+	if lowest < 0 {
+		return Component{Name: ComponentDiskHeadroom, Score: 100, Detail: "volume usage not reported by brokers (requires Kafka 3.3+)"}
+	}
+
+	score := 100
+	if lowest < minFreeRatio {
+		score = int(50 * lowest / minFreeRatio)
+	}
+	return Component{
+		Name:   ComponentDiskHeadroom,
+		Score:  score,
+		Detail: fmt.Sprintf("broker %d %s has %.1f%% free", fullest.Broker, fullest.Dir, lowest*100),
+	}
+}
+
+// StatusHandler handles GET /admin/maintenance/safety requests. It responds 503
+// when the cluster is not safe for maintenance, so tooling can gate on the code.
+func (s *Scorer) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := s.LastReport()
+	if !ok {
+		report = Report{Error: "safety score not computed yet"}
+	}
+	code := http.StatusOK
+	if !report.Safe {
+		code = http.StatusServiceUnavailable
+	}
+	_, _ = web.ReturnResponseWithCode(w, report, code)
+}
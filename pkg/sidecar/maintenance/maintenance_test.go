@@ -0,0 +1,318 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// cluster returns three brokers in racks a, b and c, controlled by broker 1,
+// with topic orders whose partitions have the given replicas, all in sync
+func cluster(replicas ...[]int32) kadm.Metadata {
+	rack := func(r string) *string { return &r }
+	md := kadm.Metadata{
+		Controller: 1,
+		Brokers: kadm.BrokerDetails{
+			{NodeID: 1, Rack: rack("a")},
+			{NodeID: 2, Rack: rack("b")},
+			{NodeID: 3, Rack: rack("c")},
+		},
+		Topics: kadm.TopicDetails{},
+	}
+	detail := kadm.TopicDetail{Topic: "orders", Partitions: kadm.PartitionDetails{}}
+	for i, r := range replicas {
+		detail.Partitions[int32(i)] = kadm.PartitionDetail{Topic: "orders", Partition: int32(i), Leader: r[0], Replicas: r, ISR: r}
+	}
+	md.Topics["orders"] = detail
+	return md
+}
+
+func healthyDisks(context.Context) ([]DirUsage, error) {
+	return []DirUsage{
+		{Broker: 1, Dir: "/var/lib/kafka", TotalBytes: 100, UsableBytes: 60},
+		{Broker: 2, Dir: "/var/lib/kafka", TotalBytes: 100, UsableBytes: 40},
+	}, nil
+}
+
+func newTestScorer(md *kadm.Metadata) (*Scorer, *clock.Fake) {
+	s := NewScorer(kafkaclient.Config{}, Options{
+		Interval:               time.Minute,
+		ControllerStablePeriod: 10 * time.Minute,
+		DiskMinFreeRatio:       0.2,
+		SafeScore:              100,
+		Timeout:                10 * time.Second,
+	}, testLogger())
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+				return *md, nil
+			},
+		}, func() {}, nil
+	})
+	s.SetDiskReader(healthyDisks)
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.SetClock(clk)
+	return s, clk
+}
+
+// component returns the named component of the report
+func component(t *testing.T, report Report, name string) Component {
+	t.Helper()
+	for _, c := range report.Components {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("component %s missing from %+v", name, report.Components)
+	return Component{}
+}
+
+func TestStepHealthyCluster(t *testing.T) {
+	md := cluster([]int32{1, 2, 3}, []int32{2, 3, 1})
+	s, _ := newTestScorer(&md)
+
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := s.LastReport()
+	if report.Score != 100 || !report.Safe || len(report.Components) != 6 {
+		t.Errorf("expected a safe cluster, got %+v", report)
+	}
+}
+
+func TestStepComponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(md *kadm.Metadata)
+		disks     DiskReader
+		component string
+		expected  int
+	}{
+		{
+			name: "under-replicated partition",
+			modify: func(md *kadm.Metadata) {
+				p := md.Topics["orders"].Partitions[0]
+				p.ISR = []int32{1, 2}
+				md.Topics["orders"].Partitions[0] = p
+			},
+			component: ComponentUnderReplicated,
+			expected:  25,
+		},
+		{
+			name: "offline partition",
+			modify: func(md *kadm.Metadata) {
+				p := md.Topics["orders"].Partitions[1]
+				p.Leader = -1
+				md.Topics["orders"].Partitions[1] = p
+			},
+			component: ComponentOffline,
+			expected:  0,
+		},
+		{
+			name: "replicas in one rack",
+			modify: func(md *kadm.Metadata) {
+				rack := "a"
+				md.Brokers[1].Rack = &rack
+				p := md.Topics["orders"].Partitions[0]
+				p.Replicas, p.ISR = []int32{1, 2}, []int32{1, 2}
+				md.Topics["orders"].Partitions[0] = p
+			},
+			component: ComponentZoneSpread,
+			expected:  25,
+		},
+		{
+			name: "no rack information",
+			modify: func(md *kadm.Metadata) {
+				for i := range md.Brokers {
+					md.Brokers[i].Rack = nil
+				}
+			},
+			component: ComponentZoneSpread,
+			expected:  100,
+		},
+		{
+			name: "no controller",
+			modify: func(md *kadm.Metadata) {
+				md.Controller = -1
+			},
+			component: ComponentController,
+			expected:  0,
+		},
+		{
+			name: "disk nearly full",
+			disks: func(context.Context) ([]DirUsage, error) {
+				return []DirUsage{{Broker: 3, Dir: "/var/lib/kafka", TotalBytes: 100, UsableBytes: 10}}, nil
+			},
+			component: ComponentDiskHeadroom,
+			expected:  25,
+		},
+		{
+			name: "disk usage not reported",
+			disks: func(context.Context) ([]DirUsage, error) {
+				return []DirUsage{{Broker: 3, Dir: "/var/lib/kafka", TotalBytes: -1, UsableBytes: -1}}, nil
+			},
+			component: ComponentDiskHeadroom,
+			expected:  100,
+		},
+		{
+			name: "disk usage unavailable",
+			disks: func(context.Context) ([]DirUsage, error) {
+				return nil, errors.New("CLUSTER_AUTHORIZATION_FAILED")
+			},
+			component: ComponentDiskHeadroom,
+			expected:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := cluster([]int32{1, 2, 3}, []int32{2, 3, 1})
+			if tt.modify != nil {
+				tt.modify(&md)
+			}
+			s, _ := newTestScorer(&md)
+			if tt.disks != nil {
+				s.SetDiskReader(tt.disks)
+			}
+
+			if err := s.Step(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			report, _ := s.LastReport()
+			if c := component(t, report, tt.component); c.Score != tt.expected {
+				t.Errorf("expected %s score %d, got %+v", tt.component, tt.expected, c)
+			}
+			if report.Score != tt.expected || report.Safe != (tt.expected == 100) {
+				t.Errorf("expected overall score %d, got %+v", tt.expected, report)
+			}
+		})
+	}
+}
+
+func TestStepReassignments(t *testing.T) {
+	md := cluster([]int32{1, 2, 3}, []int32{2, 3, 1})
+	s, _ := newTestScorer(&md)
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+				return md, nil
+			},
+			ListPartitionReassignmentsFunc: func(_ context.Context, set kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+				if len(set["orders"]) != 2 {
+					t.Errorf("expected both partitions to be listed, got %v", set)
+				}
+				return kadm.ListPartitionReassignmentsResponses{
+					"orders": {0: {Topic: "orders", Partition: 0, AddingReplicas: []int32{4}}},
+				}, nil
+			},
+		}, func() {}, nil
+	})
+
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := s.LastReport()
+	if c := component(t, report, ComponentReassignments); c.Score != 25 {
+		t.Errorf("expected reassignments score 25, got %+v", c)
+	}
+}
+
+func TestStepControllerChange(t *testing.T) {
+	md := cluster([]int32{1, 2, 3})
+	s, clk := newTestScorer(&md)
+
+	_ = s.Step(context.Background())
+	md.Controller = 2
+	clk.Advance(time.Minute)
+	_ = s.Step(context.Background())
+	report, _ := s.LastReport()
+	if c := component(t, report, ComponentController); c.Score != 50 {
+		t.Errorf("expected a recent controller change to score 50, got %+v", c)
+	}
+
+	clk.Advance(10 * time.Minute)
+	_ = s.Step(context.Background())
+	report, _ = s.LastReport()
+	if c := component(t, report, ComponentController); c.Score != 100 {
+		t.Errorf("expected the controller to be stable again, got %+v", c)
+	}
+}
+
+func TestStepMetadataError(t *testing.T) {
+	s, _ := newTestScorer(nil)
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+				return kadm.Metadata{}, errors.New("connection refused")
+			},
+		}, func() {}, nil
+	})
+
+	if err := s.Step(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	report, _ := s.LastReport()
+	if report.Score != 0 || report.Safe || report.Error == "" {
+		t.Errorf("expected an unsafe report with the error, got %+v", report)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	md := cluster([]int32{1, 2, 3})
+	s, _ := newTestScorer(&md)
+
+	w := httptest.NewRecorder()
+	s.StatusHandler(w, httptest.NewRequest(http.MethodGet, "/admin/maintenance/safety", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the first computation, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	_ = s.Step(context.Background())
+	w = httptest.NewRecorder()
+	s.StatusHandler(w, httptest.NewRequest(http.MethodGet, "/admin/maintenance/safety", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if report.Score != 100 || !report.Safe {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
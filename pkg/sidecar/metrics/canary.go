@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/canary"
+)
+
+// canaryLatencyBuckets span 1ms to ~8s in doublings, fine enough for p50/p99 estimates
+var canaryLatencyBuckets = prometheus.ExponentialBuckets(0.001, 2, 14)
+
+// CanaryReader provides the result of the last canary probe
+type CanaryReader interface {
+	LastResult() (canary.Result, bool)
+}
+
+// CanaryCollector implements prometheus.Collector for the canary produce/consume
+// probe. It also implements canary.Observer to record the latency of every probe.
+type CanaryCollector struct {
+	reader CanaryReader
+
+	successDesc *prometheus.Desc
+	produce     prometheus.Histogram
+	endToEnd    prometheus.Histogram
+}
+
+// NewCanaryCollector creates a new Prometheus collector for the canary
+func NewCanaryCollector(reader CanaryReader) *CanaryCollector {
+	return &CanaryCollector{
+		reader: reader,
+		successDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "canary", "success"),
+			"1 if the last canary record was produced through this broker and consumed back, 0 if it failed",
+			nil, nil,
+		),
+		produce: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "canary",
+			Name:      "produce_latency_seconds",
+			Help:      "Time the broker took to acknowledge canary records",
+			Buckets:   canaryLatencyBuckets,
+		}),
+		endToEnd: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "canary",
+			Name:      "end_to_end_latency_seconds",
+			Help:      "Time from producing canary records until they were consumed back",
+			Buckets:   canaryLatencyBuckets,
+		}),
+	}
+}
+
+// ObserveProbe implements canary.Observer
+func (c *CanaryCollector) ObserveProbe(probe canary.Probe) {
+	c.produce.Observe(probe.Produce.Seconds())
+	c.endToEnd.Observe(probe.EndToEnd.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (c *CanaryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.successDesc
+	c.produce.Describe(ch)
+	c.endToEnd.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *CanaryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.produce.Collect(ch)
+	c.endToEnd.Collect(ch)
+
+	result, ok := c.reader.LastResult()
+	// Nothing is known before the first probe, or when the broker leads no canary partition
+	if !ok || result.Skipped != "" {
+		return
+	}
+
+	success := 0.0
+	if result.Success {
+		success = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.successDesc, prometheus.GaugeValue, success)
+}
+
+// Register registers the collector with Prometheus
+func (c *CanaryCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/canary"
+)
+
+// MockCanaryReader is a mock implementation of CanaryReader for testing
+type MockCanaryReader struct {
+	Result canary.Result
+	Probed bool
+}
+
+func (m *MockCanaryReader) LastResult() (canary.Result, bool) {
+	return m.Result, m.Probed
+}
+
+func TestCanaryCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockCanaryReader
+		expected int
+	}{
+		{
+			name:     "never probed",
+			reader:   &MockCanaryReader{},
+			expected: 2,
+		},
+		{
+			name:     "skipped",
+			reader:   &MockCanaryReader{Result: canary.Result{Skipped: "broker 1 leads no partition of canary"}, Probed: true},
+			expected: 2,
+		},
+		{
+			name:     "failed",
+			reader:   &MockCanaryReader{Result: canary.Result{Error: "timeout"}, Probed: true},
+			expected: 3,
+		},
+		{
+			name:     "passed",
+			reader:   &MockCanaryReader{Result: canary.Result{Success: true, ProduceLatencyMs: 3, EndToEndLatencyMs: 8}, Probed: true},
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewCanaryCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestCanaryCollectorObserveProbe(t *testing.T) {
+	collector := NewCanaryCollector(&MockCanaryReader{})
+	collector.ObserveProbe(canary.Probe{Produce: 3 * time.Millisecond, EndToEnd: 12 * time.Millisecond})
+	collector.ObserveProbe(canary.Probe{Produce: 5 * time.Millisecond, EndToEnd: 40 * time.Millisecond})
+
+	for name, histogram := range map[string]prometheus.Histogram{"produce": collector.produce, "endToEnd": collector.endToEnd} {
+		var m dto.Metric
+		if err := histogram.Write(&m); err != nil {
+			t.Fatalf("%s: failed to write histogram: %v", name, err)
+		}
+		if count := m.GetHistogram().GetSampleCount(); count != 2 {
+			t.Errorf("%s: expected 2 samples, got %d", name, count)
+		}
+	}
+
+	var m dto.Metric
+	if err := collector.endToEnd.Write(&m); err != nil {
+		t.Fatalf("failed to write histogram: %v", err)
+	}
+	if sum := m.GetHistogram().GetSampleSum(); sum < 0.051 || sum > 0.053 {
+		t.Errorf("expected a sum of 0.052s, got %v", sum)
+	}
+}
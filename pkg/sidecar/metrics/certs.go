@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+)
+
+// CertReader provides the last inspected TLS certificates
+type CertReader interface {
+	Certificates() []certs.Certificate
+}
+
+// CertCollector implements prometheus.Collector for TLS certificate expiry
+type CertCollector struct {
+	reader CertReader
+
+	expiryDesc *prometheus.Desc
+}
+
+// NewCertCollector creates a new Prometheus collector for TLS certificate expiry
+func NewCertCollector(reader CertReader) *CertCollector {
+	return &CertCollector{
+		reader: reader,
+		expiryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "tls", "cert_expiry_seconds"),
+			"Seconds until the certificate expires, negative once it has expired",
+			[]string{"source", "subject"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *CertCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expiryDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *CertCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, cert := range c.reader.Certificates() {
+		// A certificate that could not be inspected has no known expiry
+		if cert.Error != "" {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.expiryDesc, prometheus.GaugeValue, time.Until(cert.NotAfter).Seconds(), cert.Source, cert.Subject)
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *CertCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/certs"
+)
+
+// MockCertReader is a mock implementation of CertReader for testing
+type MockCertReader struct {
+	Certs []certs.Certificate
+}
+
+func (m *MockCertReader) Certificates() []certs.Certificate {
+	return m.Certs
+}
+
+func TestCertCollectorCollect(t *testing.T) {
+	collector := NewCertCollector(&MockCertReader{Certs: []certs.Certificate{
+		{Source: certs.SourceBroker, Subject: "CN=kafka-1", NotAfter: time.Now().Add(time.Hour)},
+		{Source: certs.SourceClient, Error: "no such file"},
+	}})
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+
+	if len(ch) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(ch))
+	}
+	var m dto.Metric
+	if err := (<-ch).Write(&m); err != nil {
+		t.Fatalf("failed to write metric: %v", err)
+	}
+	if expiry := m.GetGauge().GetValue(); expiry < 3500 || expiry > 3600 {
+		t.Errorf("expected about 3600 seconds until expiry, got %v", expiry)
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckStatusReader provides the freshness of the sidecar's checks and background loops
+type CheckStatusReader interface {
+	Statuses() []freshness.CheckStatus
+}
+
+// CheckCollector implements prometheus.Collector for check freshness
+type CheckCollector struct {
+	reader CheckStatusReader
+
+	lastAttemptDesc *prometheus.Desc
+	lastSuccessDesc *prometheus.Desc
+	staleDesc       *prometheus.Desc
+}
+
+// NewCheckCollector creates a new Prometheus collector for check freshness
+func NewCheckCollector(reader CheckStatusReader) *CheckCollector {
+	return &CheckCollector{
+		reader: reader,
+		lastAttemptDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "check_last_attempt_timestamp_seconds"),
+			"Unix time the check or background loop last ran",
+			[]string{"check"}, nil,
+		),
+		lastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "check_last_success_timestamp_seconds"),
+			"Unix time the check or background loop last succeeded",
+			[]string{"check"}, nil,
+		),
+		staleDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "check_stale"),
+			"1 if the check has not succeeded within the staleness bound",
+			[]string{"check"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *CheckCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastAttemptDesc
+	ch <- c.lastSuccessDesc
+	ch <- c.staleDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *CheckCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.reader.Statuses() {
+		if s.LastAttempt != nil {
+			ch <- prometheus.MustNewConstMetric(c.lastAttemptDesc, prometheus.GaugeValue, float64(s.LastAttempt.UnixMilli())/1000, s.Name)
+		}
+		if s.LastSuccess != nil {
+			ch <- prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, float64(s.LastSuccess.UnixMilli())/1000, s.Name)
+		}
+		stale := 0.0
+		if s.Stale {
+			stale = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.staleDesc, prometheus.GaugeValue, stale, s.Name)
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *CheckCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// MockCheckStatusReader is a mock implementation of CheckStatusReader for testing
+type MockCheckStatusReader struct {
+	Checks []freshness.CheckStatus
+}
+
+func (m *MockCheckStatusReader) Statuses() []freshness.CheckStatus {
+	return m.Checks
+}
+
+func TestCheckCollectorCollect(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		checks   []freshness.CheckStatus
+		expected int
+	}{
+		{
+			name:     "no checks",
+			expected: 0,
+		},
+		{
+			name: "succeeded",
+			checks: []freshness.CheckStatus{
+				{Name: "readiness", LastAttempt: &now, LastSuccess: &now},
+			},
+			expected: 3,
+		},
+		{
+			name: "never ran",
+			checks: []freshness.CheckStatus{
+				{Name: "standby", Stale: true},
+			},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewCheckCollector(&MockCheckStatusReader{Checks: tt.checks})
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
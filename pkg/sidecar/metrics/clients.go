@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// ClientReader provides the Kafka clients the sidecar has created and not closed
+type ClientReader interface {
+	Stats() kafkaclient.ClientStats
+	OpenLongerThan(age time.Duration) []kafkaclient.OpenClient
+}
+
+// ClientCollector implements prometheus.Collector for the sidecar's own Kafka clients
+type ClientCollector struct {
+	reader        ClientReader
+	leakThreshold time.Duration
+
+	openDesc    *prometheus.Desc
+	createdDesc *prometheus.Desc
+	closedDesc  *prometheus.Desc
+	leakedDesc  *prometheus.Desc
+}
+
+// NewClientCollector creates a new Prometheus collector for Kafka clients. Clients
+// open longer than leakThreshold are counted as leaked; zero disables the count.
+func NewClientCollector(reader ClientReader, leakThreshold time.Duration) *ClientCollector {
+	return &ClientCollector{
+		reader:        reader,
+		leakThreshold: leakThreshold,
+		openDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "kafka_clients_open"),
+			"Kafka clients the sidecar has created and not yet closed",
+			nil, nil,
+		),
+		createdDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "kafka_clients_created_total"),
+			"Kafka clients the sidecar has created",
+			nil, nil,
+		),
+		closedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "kafka_clients_closed_total"),
+			"Kafka clients the sidecar has closed",
+			nil, nil,
+		),
+		leakedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "kafka_clients_leaked"),
+			"Kafka clients open longer than the leak threshold",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *ClientCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openDesc
+	ch <- c.createdDesc
+	ch <- c.closedDesc
+	ch <- c.leakedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *ClientCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.reader.Stats()
+	ch <- prometheus.MustNewConstMetric(c.openDesc, prometheus.GaugeValue, float64(stats.Open))
+	ch <- prometheus.MustNewConstMetric(c.createdDesc, prometheus.CounterValue, float64(stats.Created))
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.Closed))
+	if c.leakThreshold > 0 {
+		ch <- prometheus.MustNewConstMetric(c.leakedDesc, prometheus.GaugeValue, float64(len(c.reader.OpenLongerThan(c.leakThreshold))))
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *ClientCollector) Register() error {
+	return prometheus.Register(c)
+}
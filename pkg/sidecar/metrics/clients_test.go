@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+func TestClientCollectorCollect(t *testing.T) {
+	registry := kafkaclient.NewRegistry()
+	cleanup := registry.Track(func() {})
+	registry.Track(func() {})
+	cleanup()
+
+	tests := []struct {
+		name          string
+		leakThreshold time.Duration
+		expected      int
+	}{
+		{name: "leak detection disabled", expected: 3},
+		{name: "leak detection enabled", leakThreshold: time.Hour, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewClientCollector(registry, tt.leakThreshold)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
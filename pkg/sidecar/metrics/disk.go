@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DiskUsage is the usage of the filesystem holding a path
+type DiskUsage struct {
+	// TotalBytes is the size of the filesystem
+	TotalBytes uint64
+	// FreeBytes is the free space, including blocks reserved for root
+	FreeBytes uint64
+	// AvailableBytes is the free space available to unprivileged users, which
+	// is what the Kafka process can still write
+	AvailableBytes uint64
+}
+
+// UsageRatio is the used share of the space usable by unprivileged users, as
+// reported by df. Reserved blocks count as neither used nor usable. It returns
+// false for an empty filesystem.
+func (u DiskUsage) UsageRatio() (float64, bool) {
+	used := u.TotalBytes - u.FreeBytes
+	usable := used + u.AvailableBytes
+	if usable == 0 {
+		return 0, false
+	}
+	return float64(used) / float64(usable), true
+}
+
+// DiskUsageReader returns the usage of the filesystem holding a path
+type DiskUsageReader func(path string) (DiskUsage, error)
+
+// StatfsUsage reads filesystem usage with statfs(2)
+func StatfsUsage(path string) (DiskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return DiskUsage{}, err
+	}
+	bsize := uint64(st.Bsize)
+	return DiskUsage{
+		TotalBytes:     st.Blocks * bsize,
+		FreeBytes:      st.Bfree * bsize,
+		AvailableBytes: st.Bavail * bsize,
+	}, nil
+}
+
+// ParseDiskPaths splits a comma-separated list of paths, dropping empty entries
+func ParseDiskPaths(paths string) []string {
+	var out []string
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// DiskCollector implements prometheus.Collector for the filesystems holding
+// the Kafka data directories. With a maximum usage ratio it also reports the
+// volumes above it, for the readiness check.
+type DiskCollector struct {
+	paths         []string
+	read          DiskUsageReader
+	logger        *slog.Logger
+	maxUsageRatio float64
+	failures      atomic.Uint64
+
+	totalDesc     *prometheus.Desc
+	usedDesc      *prometheus.Desc
+	availableDesc *prometheus.Desc
+	ratioDesc     *prometheus.Desc
+	fullDesc      *prometheus.Desc
+}
+
+// NewDiskCollector creates a new Prometheus collector for the filesystems holding paths
+func NewDiskCollector(logger *slog.Logger, paths []string, read DiskUsageReader) *DiskCollector {
+	return &DiskCollector{
+		paths:  paths,
+		read:   read,
+		logger: logger,
+		totalDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk", "total_bytes"),
+			"Size of the filesystem holding the path",
+			[]string{"path"}, nil,
+		),
+		usedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk", "used_bytes"),
+			"Used space of the filesystem holding the path",
+			[]string{"path"}, nil,
+		),
+		availableDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk", "available_bytes"),
+			"Space available to unprivileged users on the filesystem holding the path",
+			[]string{"path"}, nil,
+		),
+		ratioDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk", "usage_ratio"),
+			"Used share of the space usable by unprivileged users, as reported by df (0.0-1.0)",
+			[]string{"path"}, nil,
+		),
+		fullDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "disk", "usage_above_threshold"),
+			"Whether the filesystem's usage ratio is above the readiness threshold (1=above, 0=below)",
+			[]string{"path"}, nil,
+		),
+	}
+}
+
+// SetMaxUsageRatio sets the usage ratio above which a volume fails readiness.
+// Zero disables the threshold.
+func (c *DiskCollector) SetMaxUsageRatio(ratio float64) {
+	c.maxUsageRatio = ratio
+}
+
+// DiskError returns an error naming the volumes whose usage is above the
+// threshold, or that cannot be read
+func (c *DiskCollector) DiskError() error {
+	if c.maxUsageRatio <= 0 {
+		return nil
+	}
+	var problems []string
+	for _, path := range c.paths {
+		usage, err := c.read(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if ratio, ok := usage.UsageRatio(); ok && ratio > c.maxUsageRatio {
+			problems = append(problems, fmt.Sprintf("%s is %.1f%% used, above %.1f%%", path, ratio*100, c.maxUsageRatio*100))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// Describe implements prometheus.Collector
+func (c *DiskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.totalDesc
+	ch <- c.usedDesc
+	ch <- c.availableDesc
+	ch <- c.ratioDesc
+	ch <- c.fullDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *DiskCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, path := range c.paths {
+		usage, err := c.read(path)
+		if err != nil {
+			c.logger.Warn("failed to read disk usage", "path", path, "error", err)
+			c.failures.Add(1)
+			continue
+		}
+
+		used := usage.TotalBytes - usage.FreeBytes
+		ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.GaugeValue, float64(usage.TotalBytes), path)
+		ch <- prometheus.MustNewConstMetric(c.usedDesc, prometheus.GaugeValue, float64(used), path)
+		ch <- prometheus.MustNewConstMetric(c.availableDesc, prometheus.GaugeValue, float64(usage.AvailableBytes), path)
+		if ratio, ok := usage.UsageRatio(); ok {
+			ch <- prometheus.MustNewConstMetric(c.ratioDesc, prometheus.GaugeValue, ratio, path)
+			if c.maxUsageRatio > 0 {
+				ch <- prometheus.MustNewConstMetric(c.fullDesc, prometheus.GaugeValue, boolValue(ratio > c.maxUsageRatio), path)
+			}
+		}
+	}
+}
+
+// Failures counts the failed reads of filesystem usage on scrapes
+func (c *DiskCollector) Failures() uint64 {
+	return c.failures.Load()
+}
+
+// Register registers the collector with Prometheus
+func (c *DiskCollector) Register() error {
+	return prometheus.Register(c)
+}
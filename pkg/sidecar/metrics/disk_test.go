@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestDiskCollectorCollect(t *testing.T) {
+	read := func(path string) (DiskUsage, error) {
+		if path == "/missing" {
+			return DiskUsage{}, errors.New("no such file or directory")
+		}
+		// 100 bytes, 10 of them reserved for root and 30 available
+		return DiskUsage{TotalBytes: 100, FreeBytes: 40, AvailableBytes: 30}, nil
+	}
+	collector := NewDiskCollector(testLogger(), []string{"/var/lib/kafka", "/missing"}, read)
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+
+	count := 0
+	for m := range ch {
+		count++
+		if m.Desc() != collector.ratioDesc {
+			continue
+		}
+		var out dto.Metric
+		if err := m.Write(&out); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.GetGauge().GetValue(); got != 60.0/90.0 {
+			t.Errorf("expected usage ratio %v, got %v", 60.0/90.0, got)
+		}
+	}
+	// Four gauges for the readable path, none for the missing one
+	if count != 4 {
+		t.Errorf("expected 4 metrics, got %d", count)
+	}
+}
+
+func TestStatfsUsage(t *testing.T) {
+	usage, err := StatfsUsage(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.TotalBytes == 0 || usage.AvailableBytes > usage.FreeBytes || usage.FreeBytes > usage.TotalBytes {
+		t.Errorf("implausible usage: %+v", usage)
+	}
+}
+
+func TestParseDiskPaths(t *testing.T) {
+	paths := ParseDiskPaths(" /var/lib/kafka/data-0, ,/var/lib/kafka/data-1")
+	if len(paths) != 2 || paths[0] != "/var/lib/kafka/data-0" || paths[1] != "/var/lib/kafka/data-1" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
+
+func TestDiskCollectorThreshold(t *testing.T) {
+	usages := map[string]DiskUsage{
+		// 60% used
+		"/var/lib/kafka/data-0": {TotalBytes: 100, FreeBytes: 40, AvailableBytes: 40},
+		// 96% used
+		"/var/lib/kafka/data-1": {TotalBytes: 100, FreeBytes: 4, AvailableBytes: 4},
+	}
+	read := func(path string) (DiskUsage, error) {
+		return usages[path], nil
+	}
+	collector := NewDiskCollector(testLogger(), []string{"/var/lib/kafka/data-0", "/var/lib/kafka/data-1"}, read)
+
+	if err := collector.DiskError(); err != nil {
+		t.Errorf("expected no error without a threshold, got %v", err)
+	}
+
+	collector.SetMaxUsageRatio(0.9)
+	err := collector.DiskError()
+	if err == nil || !strings.Contains(err.Error(), "/var/lib/kafka/data-1 is 96.0% used") || strings.Contains(err.Error(), "data-0") {
+		t.Errorf("expected only data-1 to be reported, got %v", err)
+	}
+
+	ch := make(chan prometheus.Metric, 20)
+	collector.Collect(ch)
+	close(ch)
+
+	above := map[float64]int{}
+	for m := range ch {
+		if m.Desc() != collector.fullDesc {
+			continue
+		}
+		var out dto.Metric
+		if err := m.Write(&out); err != nil {
+			t.Fatal(err)
+		}
+		above[out.GetGauge().GetValue()]++
+	}
+	if above[0] != 1 || above[1] != 1 {
+		t.Errorf("expected one volume above and one below the threshold, got %v", above)
+	}
+
+	usages["/var/lib/kafka/data-1"] = DiskUsage{TotalBytes: 100, FreeBytes: 50, AvailableBytes: 50}
+	if err := collector.DiskError(); err != nil {
+		t.Errorf("expected recovery once usage drops, got %v", err)
+	}
+}
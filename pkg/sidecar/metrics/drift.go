@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/drift"
+)
+
+// DriftReader provides the last config drift comparison
+type DriftReader interface {
+	Report() drift.Report
+}
+
+// DriftCollector implements prometheus.Collector for config drift
+type DriftCollector struct {
+	reader DriftReader
+
+	driftDesc   *prometheus.Desc
+	driftedDesc *prometheus.Desc
+}
+
+// NewDriftCollector creates a new Prometheus collector for config drift
+func NewDriftCollector(reader DriftReader) *DriftCollector {
+	return &DriftCollector{
+		reader: reader,
+		driftDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "config", "drift"),
+			"1 for every config whose actual value differs from the desired spec",
+			[]string{"resource_type", "resource", "config"}, nil,
+		),
+		driftedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "config", "drifted_configs"),
+			"Number of configs whose actual value differs from the desired spec",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *DriftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.driftDesc
+	ch <- c.driftedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *DriftCollector) Collect(ch chan<- prometheus.Metric) {
+	report := c.reader.Report()
+	// Nothing is known before the first successful comparison
+	if report.CheckedAt == nil {
+		return
+	}
+
+	for _, d := range report.Drift {
+		ch <- prometheus.MustNewConstMetric(c.driftDesc, prometheus.GaugeValue, 1, d.ResourceType, d.Resource, d.Config)
+	}
+	ch <- prometheus.MustNewConstMetric(c.driftedDesc, prometheus.GaugeValue, float64(report.Drifted))
+}
+
+// Register registers the collector with Prometheus
+func (c *DriftCollector) Register() error {
+	return prometheus.Register(c)
+}
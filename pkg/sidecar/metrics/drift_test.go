@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/drift"
+)
+
+// MockDriftReader is a mock implementation of DriftReader for testing
+type MockDriftReader struct {
+	Last drift.Report
+}
+
+func (m *MockDriftReader) Report() drift.Report {
+	return m.Last
+}
+
+func TestDriftCollectorCollect(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		report   drift.Report
+		expected int
+	}{
+		{
+			name:     "never compared",
+			expected: 0,
+		},
+		{
+			name:     "no drift",
+			report:   drift.Report{CheckedAt: &now},
+			expected: 1,
+		},
+		{
+			name: "drift",
+			report: drift.Report{
+				CheckedAt: &now,
+				Drifted:   2,
+				Drift: []drift.Drift{
+					{ResourceType: drift.ResourceTopic, Resource: "orders", Config: "retention.ms"},
+					{ResourceType: drift.ResourceBroker, Resource: "0", Config: "log.retention.hours"},
+				},
+			},
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewDriftCollector(&MockDriftReader{Last: tt.report})
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
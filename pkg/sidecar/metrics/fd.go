@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// FDCollector implements prometheus.Collector for the file descriptor usage of
+// the broker and, optionally, the sidecar itself
+type FDCollector struct {
+	reader  procfs.FDUsageReader
+	sidecar procfs.FDUsageReader
+	logger  *slog.Logger
+
+	usedDesc         *prometheus.Desc
+	limitDesc        *prometheus.Desc
+	sidecarUsedDesc  *prometheus.Desc
+	sidecarLimitDesc *prometheus.Desc
+}
+
+// NewFDCollector creates a new Prometheus collector for broker file descriptor usage
+func NewFDCollector(logger *slog.Logger, reader procfs.FDUsageReader) *FDCollector {
+	return &FDCollector{
+		reader: reader,
+		logger: logger,
+		usedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "fd_used"),
+			"Open file descriptors of the Kafka broker process",
+			nil, nil,
+		),
+		limitDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "fd_limit"),
+			"File descriptor soft limit of the Kafka broker process (0 if unlimited)",
+			nil, nil,
+		),
+		sidecarUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "fd_used"),
+			"Open file descriptors of the sidecar process",
+			nil, nil,
+		),
+		sidecarLimitDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "fd_limit"),
+			"File descriptor soft limit of the sidecar process (0 if unlimited)",
+			nil, nil,
+		),
+	}
+}
+
+// SetSidecarReader also exports the sidecar's own file descriptor usage, which
+// grows with every Kafka client it keeps open
+func (c *FDCollector) SetSidecarReader(reader procfs.FDUsageReader) {
+	c.sidecar = reader
+}
+
+// Describe implements prometheus.Collector
+func (c *FDCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usedDesc
+	ch <- c.limitDesc
+	if c.sidecar != nil {
+		ch <- c.sidecarUsedDesc
+		ch <- c.sidecarLimitDesc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *FDCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.sidecar != nil {
+		if usage, err := c.sidecar.FDUsage(); err != nil {
+			c.logger.Debug("failed to read sidecar file descriptor usage", "error", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.sidecarUsedDesc, prometheus.GaugeValue, float64(usage.Used))
+			ch <- prometheus.MustNewConstMetric(c.sidecarLimitDesc, prometheus.GaugeValue, float64(usage.Limit))
+		}
+	}
+
+	usage, err := c.reader.FDUsage()
+	if err != nil {
+		// Expected when the broker process is not visible (no shared PID namespace)
+		c.logger.Debug("failed to read broker file descriptor usage", "error", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.usedDesc, prometheus.GaugeValue, float64(usage.Used))
+	ch <- prometheus.MustNewConstMetric(c.limitDesc, prometheus.GaugeValue, float64(usage.Limit))
+}
+
+// Register registers the collector with Prometheus
+func (c *FDCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// MockFDUsageReader is a mock implementation of procfs.FDUsageReader for testing
+type MockFDUsageReader struct {
+	Usage procfs.FDUsage
+	Err   error
+}
+
+func (m *MockFDUsageReader) FDUsage() (procfs.FDUsage, error) {
+	return m.Usage, m.Err
+}
+
+func TestFDCollectorDescribe(t *testing.T) {
+	collector := NewFDCollector(testLogger(), &MockFDUsageReader{})
+
+	ch := make(chan *prometheus.Desc, 10)
+	collector.Describe(ch)
+	close(ch)
+
+	count := 0
+	for range ch {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 descriptors, got %d", count)
+	}
+}
+
+func TestFDCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockFDUsageReader
+		expected int
+	}{
+		{
+			name:     "success",
+			reader:   &MockFDUsageReader{Usage: procfs.FDUsage{Used: 100, Limit: 1024}},
+			expected: 2,
+		},
+		{
+			name:     "broker process not visible",
+			reader:   &MockFDUsageReader{Err: procfs.ErrProcessNotFound},
+			expected: 0,
+		},
+		{
+			name:     "permission denied",
+			reader:   &MockFDUsageReader{Err: errors.New("permission denied")},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewFDCollector(testLogger(), tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestFDCollectorSidecar(t *testing.T) {
+	collector := NewFDCollector(testLogger(), &MockFDUsageReader{Err: procfs.ErrProcessNotFound})
+	collector.SetSidecarReader(&MockFDUsageReader{Usage: procfs.FDUsage{Used: 12, Limit: 1048576}})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather: %v", err)
+	}
+
+	byName := familiesByName(mfs)
+	if _, ok := byName["kafka_broker_fd_used"]; ok {
+		t.Error("expected no broker metrics while the broker is not visible")
+	}
+	if v := byName["kafka_sidecar_fd_used"].GetMetric()[0].GetGauge().GetValue(); v != 12 {
+		t.Errorf("expected 12 sidecar fds, got %v", v)
+	}
+	if v := byName["kafka_sidecar_fd_limit"].GetMetric()[0].GetGauge().GetValue(); v != 1048576 {
+		t.Errorf("expected the sidecar limit, got %v", v)
+	}
+}
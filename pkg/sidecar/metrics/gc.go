@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/gclog"
+)
+
+// gcPauseBuckets span 1ms to ~16s in doublings; pauses over a few seconds
+// outlast replica.lag.time.max.ms and drop the broker out of the ISR
+var gcPauseBuckets = prometheus.ExponentialBuckets(0.001, 2, 15)
+
+// GCCollector implements prometheus.Collector for the broker JVM's GC pauses,
+// parsed from its GC log. It implements gclog.Observer to record every pause.
+type GCCollector struct {
+	pauses *prometheus.HistogramVec
+	// The heap gauges have no labels; as vectors they are only exported once
+	// a pause has been seen, rather than as zero
+	heapAfter     *prometheus.GaugeVec
+	heapCommitted *prometheus.GaugeVec
+}
+
+// NewGCCollector creates a new Prometheus collector for GC pauses
+func NewGCCollector() *GCCollector {
+	return &GCCollector{
+		pauses: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "jvm",
+			Name:      "gc_pause_seconds",
+			Help:      "Stop-the-world GC pauses of the broker JVM, from its GC log",
+			Buckets:   gcPauseBuckets,
+		}, []string{"type"}),
+		heapAfter: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "jvm",
+			Name:      "heap_after_gc_bytes",
+			Help:      "Heap occupancy of the broker JVM after its last GC pause",
+		}, nil),
+		heapCommitted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "jvm",
+			Name:      "heap_committed_bytes",
+			Help:      "Committed heap of the broker JVM after its last GC pause",
+		}, nil),
+	}
+}
+
+// ObservePause implements gclog.Observer
+func (c *GCCollector) ObservePause(p gclog.Pause) {
+	c.pauses.WithLabelValues(p.Type).Observe(p.Duration.Seconds())
+	c.heapAfter.WithLabelValues().Set(float64(p.HeapAfter))
+	c.heapCommitted.WithLabelValues().Set(float64(p.HeapCommitted))
+}
+
+// Describe implements prometheus.Collector
+func (c *GCCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.pauses.Describe(ch)
+	c.heapAfter.Describe(ch)
+	c.heapCommitted.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *GCCollector) Collect(ch chan<- prometheus.Metric) {
+	c.pauses.Collect(ch)
+	c.heapAfter.Collect(ch)
+	c.heapCommitted.Collect(ch)
+}
+
+// Register registers the collector with Prometheus
+func (c *GCCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/gclog"
+)
+
+func TestGCCollector(t *testing.T) {
+	collector := NewGCCollector()
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no metrics before the first pause, got %d", len(ch))
+	}
+
+	collector.ObservePause(gclog.Pause{Type: gclog.TypeYoung, Duration: 10 * time.Millisecond, HeapAfter: 128 << 20, HeapCommitted: 1 << 30})
+	collector.ObservePause(gclog.Pause{Type: gclog.TypeYoung, Duration: 20 * time.Millisecond, HeapAfter: 256 << 20, HeapCommitted: 1 << 30})
+	collector.ObservePause(gclog.Pause{Type: gclog.TypeFull, Duration: 2 * time.Second, HeapAfter: 64 << 20, HeapCommitted: 1 << 30})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	pauses := map[string]uint64{}
+	for _, m := range byName["kafka_jvm_gc_pause_seconds"].GetMetric() {
+		pauses[labelMap(m)["type"]] = m.GetHistogram().GetSampleCount()
+	}
+	if pauses["young"] != 2 || pauses["full"] != 1 {
+		t.Errorf("expected 2 young and 1 full pause, got %v", pauses)
+	}
+
+	var m dto.Metric
+	if err := collector.heapAfter.WithLabelValues().Write(&m); err != nil {
+		t.Fatalf("failed to write gauge: %v", err)
+	}
+	if v := m.GetGauge().GetValue(); v != 64<<20 {
+		t.Errorf("expected the heap after the last pause, got %v", v)
+	}
+	if v := byName["kafka_jvm_heap_committed_bytes"].GetMetric()[0].GetGauge().GetValue(); v != 1<<30 {
+		t.Errorf("expected 1GiB committed, got %v", v)
+	}
+}
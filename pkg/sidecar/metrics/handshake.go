@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// handshakeBuckets span 0.25ms to ~2s in doublings; a full TLS handshake on a
+// healthy listener takes a few milliseconds
+var handshakeBuckets = prometheus.ExponentialBuckets(0.00025, 2, 14)
+
+// HandshakeCollector implements prometheus.Collector for the phases of the
+// connections probed against every broker listener. It implements
+// handshake.Observer to record every probe.
+type HandshakeCollector struct {
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+// NewHandshakeCollector creates a new Prometheus collector for listener handshake probes
+func NewHandshakeCollector() *HandshakeCollector {
+	return &HandshakeCollector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "listener",
+			Name:      "probe_duration_seconds",
+			Help:      "Duration of the TCP connect, TLS handshake and first request probed against a broker listener",
+			Buckets:   handshakeBuckets,
+		}, []string{"listener", "phase"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "listener",
+			Name:      "probe_failures_total",
+			Help:      "Phases of broker listener probes that failed",
+		}, []string{"listener", "phase"}),
+	}
+}
+
+// ObserveHandshake implements handshake.Observer. Failed phases only count as
+// failures, since a timeout would skew the duration distribution.
+func (c *HandshakeCollector) ObserveHandshake(listener, phase string, d time.Duration, err error) {
+	if err != nil {
+		c.failures.WithLabelValues(listener, phase).Inc()
+		return
+	}
+	c.duration.WithLabelValues(listener, phase).Observe(d.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (c *HandshakeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.failures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *HandshakeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.failures.Collect(ch)
+}
+
+// Register registers the collector with Prometheus
+func (c *HandshakeCollector) Register() error {
+	return prometheus.Register(c)
+}
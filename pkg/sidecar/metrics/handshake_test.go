@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestHandshakeCollector(t *testing.T) {
+	collector := NewHandshakeCollector()
+
+	collector.ObserveHandshake("internal", "connect", time.Millisecond, nil)
+	collector.ObserveHandshake("internal", "handshake", 40*time.Millisecond, nil)
+	collector.ObserveHandshake("external", "connect", time.Millisecond, nil)
+	collector.ObserveHandshake("external", "handshake", 5*time.Second, errors.New("timeout"))
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	// Three histograms and one failure counter
+	if len(ch) != 4 {
+		t.Errorf("expected 4 metrics, got %d", len(ch))
+	}
+
+	var m dto.Metric
+	if err := collector.duration.WithLabelValues("internal", "handshake").(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatalf("failed to write histogram: %v", err)
+	}
+	if sum := m.GetHistogram().GetSampleSum(); sum != 0.04 {
+		t.Errorf("expected the handshake to be observed apart from the connect, got %v", sum)
+	}
+	m.Reset()
+	if err := collector.failures.WithLabelValues("external", "handshake").Write(&m); err != nil {
+		t.Fatalf("failed to write counter: %v", err)
+	}
+	if failures := m.GetCounter().GetValue(); failures != 1 {
+		t.Errorf("expected 1 failure, got %v", failures)
+	}
+}
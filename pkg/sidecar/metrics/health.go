@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// HealthReader provides the outcomes of the latest health evaluations
+type HealthReader interface {
+	LastResults() health.Results
+	LastURPCounts() (health.URPCounts, bool)
+}
+
+// HealthCollector implements prometheus.Collector for the latest liveness and
+// readiness evaluations, so alerts need not probe the JSON endpoints
+type HealthCollector struct {
+	reader HealthReader
+
+	liveDesc       *prometheus.Desc
+	readyDesc      *prometheus.Desc
+	registeredDesc *prometheus.Desc
+	controllerDesc *prometheus.Desc
+	conflictDesc   *prometheus.Desc
+	urpDesc        *prometheus.Desc
+}
+
+// NewHealthCollector creates a new Prometheus collector for health results
+func NewHealthCollector(reader HealthReader) *HealthCollector {
+	return &HealthCollector{
+		reader: reader,
+		liveDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "live"),
+			"Whether the last liveness check passed (1) or failed (0)",
+			nil, nil,
+		),
+		readyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "ready"),
+			"Whether the last readiness check passed (1), degraded included, or failed (0)",
+			nil, nil,
+		),
+		registeredDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "broker_registered"),
+			"Whether the broker was in the cluster metadata at the last check",
+			nil, nil,
+		),
+		controllerDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "controller_elected"),
+			"Whether the cluster had an elected controller at the last check",
+			nil, nil,
+		),
+		conflictDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "broker_id_conflict"),
+			"Whether the broker's ID was registered from another host at the last check",
+			nil, nil,
+		),
+		urpDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "under_replicated_partitions"),
+			"Under-replicated partitions of this broker that fail readiness, as of the last readiness check",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *HealthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.liveDesc
+	ch <- c.readyDesc
+	ch <- c.registeredDesc
+	ch <- c.controllerDesc
+	ch <- c.conflictDesc
+	ch <- c.urpDesc
+}
+
+// Collect implements prometheus.Collector. Checks that were never evaluated,
+// e.g. before the first probe, are left out.
+func (c *HealthCollector) Collect(ch chan<- prometheus.Metric) {
+	results := c.reader.LastResults()
+	for _, r := range []struct {
+		desc  *prometheus.Desc
+		value *bool
+	}{
+		{c.liveDesc, results.Live},
+		{c.readyDesc, results.Ready},
+		{c.registeredDesc, results.BrokerRegistered},
+		{c.controllerDesc, results.ControllerElected},
+		{c.conflictDesc, results.BrokerIDConflict},
+	} {
+		if r.value != nil {
+			ch <- prometheus.MustNewConstMetric(r.desc, prometheus.GaugeValue, boolValue(*r.value))
+		}
+	}
+	if counts, ok := c.reader.LastURPCounts(); ok {
+		ch <- prometheus.MustNewConstMetric(c.urpDesc, prometheus.GaugeValue, float64(counts.Counted))
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *HealthCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// MockHealthReader is a mock implementation of HealthReader for testing
+type MockHealthReader struct {
+	MockURPReader
+	Results health.Results
+}
+
+func (m *MockHealthReader) LastResults() health.Results {
+	return m.Results
+}
+
+func TestHealthCollector(t *testing.T) {
+	yes, no := true, false
+	reader := &MockHealthReader{}
+	collector := NewHealthCollector(reader)
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no metrics before the first check, got %d", len(ch))
+	}
+
+	reader.Results = health.Results{Live: &yes, Ready: &no, BrokerRegistered: &yes, ControllerElected: &yes, BrokerIDConflict: &no}
+	reader.Counts = health.URPCounts{Counted: 3, Excluded: 1}
+	reader.Checked = true
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	expected := map[string]float64{
+		"kafka_sidecar_live":                        1,
+		"kafka_sidecar_ready":                       0,
+		"kafka_sidecar_broker_registered":           1,
+		"kafka_sidecar_controller_elected":          1,
+		"kafka_sidecar_broker_id_conflict":          0,
+		"kafka_sidecar_under_replicated_partitions": 3,
+	}
+	for name, want := range expected {
+		mf, ok := byName[name]
+		if !ok {
+			t.Errorf("expected %s", name)
+			continue
+		}
+		if got := mf.GetMetric()[0].GetGauge().GetValue(); got != want {
+			t.Errorf("expected %s %v, got %v", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/inflight"
+)
+
+// InFlightReader provides the request counts of the sidecar's HTTP handlers
+type InFlightReader interface {
+	Stats() []inflight.Stats
+}
+
+// InFlightCollector implements prometheus.Collector for in-flight requests
+type InFlightCollector struct {
+	reader InFlightReader
+
+	inFlightDesc *prometheus.Desc
+	shedDesc     *prometheus.Desc
+}
+
+// NewInFlightCollector creates a new Prometheus collector for in-flight requests
+func NewInFlightCollector(reader InFlightReader) *InFlightCollector {
+	return &InFlightCollector{
+		reader: reader,
+		inFlightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "inflight_requests"),
+			"Requests the handler is currently serving",
+			[]string{"handler"}, nil,
+		),
+		shedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "shed_requests_total"),
+			"Requests served from cache or refused because the handler was at its concurrency cap",
+			[]string{"handler"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *InFlightCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inFlightDesc
+	ch <- c.shedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *InFlightCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.reader.Stats() {
+		ch <- prometheus.MustNewConstMetric(c.inFlightDesc, prometheus.GaugeValue, float64(s.InFlight), s.Handler)
+		ch <- prometheus.MustNewConstMetric(c.shedDesc, prometheus.CounterValue, float64(s.Shed), s.Handler)
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *InFlightCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/inflight"
+)
+
+// MockInFlightReader is a mock implementation of InFlightReader for testing
+type MockInFlightReader struct {
+	stats []inflight.Stats
+}
+
+func (m *MockInFlightReader) Stats() []inflight.Stats {
+	return m.stats
+}
+
+func TestInFlightCollectorCollect(t *testing.T) {
+	collector := NewInFlightCollector(&MockInFlightReader{stats: []inflight.Stats{
+		{Handler: "/health/ready", InFlight: 4, Shed: 17},
+		{Handler: "/metrics", InFlight: 1},
+	}})
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+
+	count := 0
+	for range ch {
+		count++
+	}
+	if count != 4 {
+		t.Errorf("expected 4 metrics, got %d", count)
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorNames are the names of the sidecar's collectors, as used in
+// METRICS_DISABLED_COLLECTORS and the collector label of the scrape metrics
+var CollectorNames = []string{
+	"memory", "oom_prediction", "network", "fd", "process", "disk", "urp", "jmx", "request_errors", "clients", "checks", "inflight", "canary",
+	"request_latency", "handshake", "partition_sizes", "peers", "tls", "maintenance", "kraft", "drift", "gc", "safe_mode", "probes", "health",
+}
+
+// ParseCollectorNames parses a comma-separated list of collector names into a set,
+// rejecting unknown names
+func ParseCollectorNames(names string) (map[string]bool, error) {
+	known := map[string]bool{}
+	for _, name := range CollectorNames {
+		known[name] = true
+	}
+
+	set := map[string]bool{}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			valid := append([]string(nil), CollectorNames...)
+			sort.Strings(valid)
+			return nil, fmt.Errorf("unknown collector %q (valid: %s)", name, strings.Join(valid, ", "))
+		}
+		set[name] = true
+	}
+	return set, nil
+}
+
+// failureCounter is implemented by collectors whose reads can fail on a scrape.
+// Failures counts the failed reads since the collector was created.
+type failureCounter interface {
+	Failures() uint64
+}
+
+// instrumentedCollector wraps a collector with metrics on the cost of collecting it
+type instrumentedCollector struct {
+	collector    prometheus.Collector
+	durationDesc *prometheus.Desc
+	errorsDesc   *prometheus.Desc
+}
+
+// Instrument wraps a collector so every scrape also reports how long the
+// collector took and, for collectors whose reads can fail, how many failed.
+// The collector label is constant, so wrapped collectors register side by side.
+func Instrument(name string, collector prometheus.Collector) prometheus.Collector {
+	labels := prometheus.Labels{"collector": name}
+	return &instrumentedCollector{
+		collector: collector,
+		durationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "collector", "scrape_duration_seconds"),
+			"Time the collector took on the last scrape",
+			nil, labels,
+		),
+		errorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "collector", "errors_total"),
+			"Failed reads of the collector's source, e.g. cgroup files, statfs or Jolokia",
+			nil, labels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *instrumentedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	ch <- c.durationDesc
+	if _, ok := c.collector.(failureCounter); ok {
+		ch <- c.errorsDesc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *instrumentedCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	c.collector.Collect(ch)
+	ch <- prometheus.MustNewConstMetric(c.durationDesc, prometheus.GaugeValue, time.Since(start).Seconds())
+	if counter, ok := c.collector.(failureCounter); ok {
+		ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, float64(counter.Failures()))
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestInstrument(t *testing.T) {
+	read := func(string) (DiskUsage, error) {
+		return DiskUsage{}, errors.New("no such file or directory")
+	}
+	disk := NewDiskCollector(testLogger(), []string{"/missing"}, read)
+	fd := NewFDCollector(testLogger(), &MockFDUsageReader{})
+
+	registry := prometheus.NewRegistry()
+	// Both report the scrape metrics, told apart by the collector label
+	if err := registry.Register(Instrument("disk", disk)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Register(Instrument("fd", fd)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	if n := len(byName["kafka_collector_scrape_duration_seconds"].GetMetric()); n != 2 {
+		t.Errorf("expected a scrape duration per collector, got %d", n)
+	}
+	// Only the disk collector counts failures
+	errs := byName["kafka_collector_errors_total"].GetMetric()
+	if len(errs) != 1 || labelMap(errs[0])["collector"] != "disk" || errs[0].GetCounter().GetValue() != 1 {
+		t.Errorf("expected one failed disk read, got %v", errs)
+	}
+}
+
+func TestParseCollectorNames(t *testing.T) {
+	set, err := ParseCollectorNames(" jmx, ,partition_sizes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(set) != 2 || !set["jmx"] || !set["partition_sizes"] {
+		t.Errorf("unexpected set: %v", set)
+	}
+
+	if _, err := ParseCollectorNames("jmx,lag"); err == nil {
+		t.Error("expected an error for an unknown collector")
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/handshake"
+)
+
+// InterBrokerTLSReader provides the last inter-broker TLS report
+type InterBrokerTLSReader interface {
+	LastReport() (handshake.InterBrokerReport, bool)
+}
+
+// InterBrokerTLSCollector implements prometheus.Collector for the handshakes
+// with the peers' inter-broker listeners
+type InterBrokerTLSCollector struct {
+	reader InterBrokerTLSReader
+
+	verifiedDesc *prometheus.Desc
+	mixedDesc    *prometheus.Desc
+}
+
+// NewInterBrokerTLSCollector creates a new Prometheus collector for inter-broker TLS checks
+func NewInterBrokerTLSCollector(reader InterBrokerTLSReader) *InterBrokerTLSCollector {
+	return &InterBrokerTLSCollector{
+		reader: reader,
+		verifiedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "inter_broker_tls", "verified"),
+			"1 if the handshake with the peer's inter-broker listener completed and its chain verified",
+			[]string{"broker"}, nil,
+		),
+		mixedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "inter_broker_tls", "mixed"),
+			"1 if peers serve chains of different issuers, or only some chains verify",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *InterBrokerTLSCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.verifiedDesc
+	ch <- c.mixedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *InterBrokerTLSCollector) Collect(ch chan<- prometheus.Metric) {
+	report, ok := c.reader.LastReport()
+	if !ok {
+		return
+	}
+
+	for _, peer := range report.Peers {
+		ch <- prometheus.MustNewConstMetric(c.verifiedDesc, prometheus.GaugeValue, boolValue(peer.Verified), strconv.Itoa(int(peer.BrokerID)))
+	}
+	ch <- prometheus.MustNewConstMetric(c.mixedDesc, prometheus.GaugeValue, boolValue(report.Mixed))
+}
+
+// Register registers the collector with Prometheus
+func (c *InterBrokerTLSCollector) Register() error {
+	return prometheus.Register(c)
+}
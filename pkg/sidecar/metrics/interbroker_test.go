@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/handshake"
+)
+
+// MockInterBrokerTLSReader is a mock implementation of InterBrokerTLSReader for testing
+type MockInterBrokerTLSReader struct {
+	Report  handshake.InterBrokerReport
+	Checked bool
+}
+
+func (m *MockInterBrokerTLSReader) LastReport() (handshake.InterBrokerReport, bool) {
+	return m.Report, m.Checked
+}
+
+func TestInterBrokerTLSCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockInterBrokerTLSReader
+		expected int
+	}{
+		{
+			name:     "never checked",
+			reader:   &MockInterBrokerTLSReader{},
+			expected: 0,
+		},
+		{
+			name: "peers",
+			reader: &MockInterBrokerTLSReader{
+				Report: handshake.InterBrokerReport{
+					Peers: []handshake.PeerTLS{{BrokerID: 2, Verified: true}, {BrokerID: 3}},
+					Mixed: true,
+				},
+				Checked: true,
+			},
+			// 2 peers and the mixed flag
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewInterBrokerTLSCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
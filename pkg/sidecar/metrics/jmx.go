@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JMXReader reads numeric MBean attributes from the broker JVM. *jolokia.Client implements it.
+type JMXReader interface {
+	ReadFloat(ctx context.Context, mbean, attribute string) (float64, error)
+}
+
+// jmxMetric maps an MBean attribute to a sidecar metric
+type jmxMetric struct {
+	mbean     string
+	attribute string
+	valueType prometheus.ValueType
+	desc      *prometheus.Desc
+}
+
+// JMXCollector implements prometheus.Collector for key broker MBeans, read
+// through Jolokia on every scrape and re-exported under the sidecar's naming
+type JMXCollector struct {
+	reader   JMXReader
+	timeout  time.Duration
+	logger   *slog.Logger
+	failures atomic.Uint64
+
+	metrics []jmxMetric
+	upDesc  *prometheus.Desc
+}
+
+// NewJMXCollector creates a new Prometheus collector for broker MBeans. Each
+// scrape reads every MBean within timeout.
+func NewJMXCollector(reader JMXReader, timeout time.Duration, logger *slog.Logger) *JMXCollector {
+	metric := func(mbean, attribute string, valueType prometheus.ValueType, name, help string) jmxMetric {
+		return jmxMetric{
+			mbean:     mbean,
+			attribute: attribute,
+			valueType: valueType,
+			desc:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "broker", name), help, nil, nil),
+		}
+	}
+
+	return &JMXCollector{
+		reader:  reader,
+		timeout: timeout,
+		logger:  logger,
+		metrics: []jmxMetric{
+			metric("kafka.server:type=BrokerTopicMetrics,name=BytesInPerSec", "Count", prometheus.CounterValue,
+				"bytes_in_total", "Bytes received from clients by the broker (BytesInPerSec)"),
+			metric("kafka.server:type=BrokerTopicMetrics,name=BytesOutPerSec", "Count", prometheus.CounterValue,
+				"bytes_out_total", "Bytes sent to clients by the broker (BytesOutPerSec)"),
+			metric("kafka.server:type=BrokerTopicMetrics,name=MessagesInPerSec", "Count", prometheus.CounterValue,
+				"messages_in_total", "Records appended by the broker (MessagesInPerSec)"),
+			metric("kafka.server:type=KafkaRequestHandlerPool,name=RequestHandlerAvgIdlePercent", "OneMinuteRate", prometheus.GaugeValue,
+				"request_handler_idle_ratio", "Fraction of time the request handler threads were idle over the last minute (RequestHandlerAvgIdlePercent)"),
+			metric("kafka.network:type=SocketServer,name=NetworkProcessorAvgIdlePercent", "Value", prometheus.GaugeValue,
+				"network_processor_idle_ratio", "Fraction of time the network processor threads were idle (NetworkProcessorAvgIdlePercent)"),
+			metric("kafka.server:type=ReplicaManager,name=UnderMinIsrPartitionCount", "Value", prometheus.GaugeValue,
+				"under_min_isr_partitions", "Partitions led by the broker with fewer in-sync replicas than min.insync.replicas (UnderMinIsrPartitionCount)"),
+		},
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "jmx_up"),
+			"1 if the broker's MBeans could be read through Jolokia",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *JMXCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+	ch <- c.upDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *JMXCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	read := 0
+	for _, m := range c.metrics {
+		value, err := c.reader.ReadFloat(ctx, m.mbean, m.attribute)
+		if err != nil {
+			// MBeans differ between Kafka versions and node types; skip the missing ones
+			c.logger.Debug("failed to read broker mbean", "mbean", m.mbean, "attribute", m.attribute, "error", err)
+			c.failures.Add(1)
+			continue
+		}
+		read++
+		ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, value)
+	}
+
+	up := 0.0
+	if read > 0 {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, up)
+}
+
+// Failures counts the failed MBean reads, including MBeans the broker does not have
+func (c *JMXCollector) Failures() uint64 {
+	return c.failures.Load()
+}
+
+// Register registers the collector with Prometheus
+func (c *JMXCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MockJMXReader is a mock implementation of JMXReader for testing
+type MockJMXReader struct {
+	ReadFloatFunc func(ctx context.Context, mbean, attribute string) (float64, error)
+}
+
+func (m *MockJMXReader) ReadFloat(ctx context.Context, mbean, attribute string) (float64, error) {
+	if m.ReadFloatFunc != nil {
+		return m.ReadFloatFunc(ctx, mbean, attribute)
+	}
+	return 0, nil
+}
+
+// collectJMX returns the collected metrics by name
+func collectJMX(t *testing.T, collector *JMXCollector) map[string]*dto.Metric {
+	t.Helper()
+	ch := make(chan prometheus.Metric, 20)
+	collector.Collect(ch)
+	close(ch)
+
+	metrics := map[string]*dto.Metric{}
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		// Desc only exposes its name through String: Desc{fqName: "...", ...}
+		_, name, _ := strings.Cut(metric.Desc().String(), `fqName: "`)
+		name, _, _ = strings.Cut(name, `"`)
+		metrics[name] = &m
+	}
+	return metrics
+}
+
+func TestJMXCollectorCollect(t *testing.T) {
+	reader := &MockJMXReader{
+		ReadFloatFunc: func(_ context.Context, mbean, attribute string) (float64, error) {
+			switch {
+			case strings.Contains(mbean, "BytesInPerSec") && attribute == "Count":
+				return 1024, nil
+			case strings.Contains(mbean, "RequestHandlerAvgIdlePercent") && attribute == "OneMinuteRate":
+				return 0.85, nil
+			case strings.Contains(mbean, "UnderMinIsrPartitionCount") && attribute == "Value":
+				return 2, nil
+			}
+			return 0, errors.New("javax.management.InstanceNotFoundException")
+		},
+	}
+
+	metrics := collectJMX(t, NewJMXCollector(reader, time.Second, testLogger()))
+	if len(metrics) != 4 {
+		t.Fatalf("expected 3 mbeans and jmx_up, got %d metrics", len(metrics))
+	}
+	if v := metrics["kafka_broker_bytes_in_total"].GetCounter().GetValue(); v != 1024 {
+		t.Errorf("expected bytes in 1024, got %v", v)
+	}
+	if v := metrics["kafka_broker_request_handler_idle_ratio"].GetGauge().GetValue(); v != 0.85 {
+		t.Errorf("expected idle ratio 0.85, got %v", v)
+	}
+	if v := metrics["kafka_broker_under_min_isr_partitions"].GetGauge().GetValue(); v != 2 {
+		t.Errorf("expected 2 under min ISR partitions, got %v", v)
+	}
+	if v := metrics["kafka_broker_jmx_up"].GetGauge().GetValue(); v != 1 {
+		t.Errorf("expected jmx_up 1, got %v", v)
+	}
+}
+
+func TestJMXCollectorUnreachable(t *testing.T) {
+	reader := &MockJMXReader{
+		ReadFloatFunc: func(context.Context, string, string) (float64, error) {
+			return 0, errors.New("connection refused")
+		},
+	}
+
+	metrics := collectJMX(t, NewJMXCollector(reader, time.Second, testLogger()))
+	if len(metrics) != 1 {
+		t.Fatalf("expected only jmx_up, got %d metrics", len(metrics))
+	}
+	if v := metrics["kafka_broker_jmx_up"].GetGauge().GetValue(); v != 0 {
+		t.Errorf("expected jmx_up 0, got %v", v)
+	}
+}
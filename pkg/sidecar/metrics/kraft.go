@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kraft"
+)
+
+// KRaftReader provides the last inspection of the KRaft metadata log
+type KRaftReader interface {
+	LastReport() (kraft.Report, bool)
+}
+
+// KRaftCollector implements prometheus.Collector for the KRaft metadata log and
+// its snapshots
+type KRaftCollector struct {
+	reader KRaftReader
+
+	logBytesDesc       *prometheus.Desc
+	segmentsDesc       *prometheus.Desc
+	snapshotsDesc      *prometheus.Desc
+	snapshotBytesDesc  *prometheus.Desc
+	snapshotOffsetDesc *prometheus.Desc
+	oldestAgeDesc      *prometheus.Desc
+	sinceLatestDesc    *prometheus.Desc
+	runawayDesc        *prometheus.Desc
+}
+
+// NewKRaftCollector creates a new Prometheus collector for the KRaft metadata log
+func NewKRaftCollector(reader KRaftReader) *KRaftCollector {
+	return &KRaftCollector{
+		reader: reader,
+		logBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "metadata_log_bytes"),
+			"Size of the __cluster_metadata log segments",
+			nil, nil,
+		),
+		segmentsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "metadata_log_segments"),
+			"Number of __cluster_metadata log segments",
+			nil, nil,
+		),
+		snapshotsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "snapshots"),
+			"Number of completed metadata snapshots",
+			nil, nil,
+		),
+		snapshotBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "snapshot_bytes"),
+			"Size of the completed metadata snapshots",
+			nil, nil,
+		),
+		snapshotOffsetDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "latest_snapshot_offset"),
+			"End offset of the newest metadata snapshot",
+			nil, nil,
+		),
+		oldestAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "oldest_snapshot_age_seconds"),
+			"Age of the oldest metadata snapshot still on disk",
+			nil, nil,
+		),
+		sinceLatestDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "seconds_since_last_snapshot"),
+			"Time since the newest metadata snapshot was written",
+			nil, nil,
+		),
+		runawayDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kraft", "metadata_log_runaway"),
+			"1 if the metadata log exceeds KRAFT_METADATA_LOG_MAX_BYTES or has gone KRAFT_SNAPSHOT_MAX_AGE without a snapshot",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *KRaftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.logBytesDesc
+	ch <- c.segmentsDesc
+	ch <- c.snapshotsDesc
+	ch <- c.snapshotBytesDesc
+	ch <- c.snapshotOffsetDesc
+	ch <- c.oldestAgeDesc
+	ch <- c.sinceLatestDesc
+	ch <- c.runawayDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *KRaftCollector) Collect(ch chan<- prometheus.Metric) {
+	report, ok := c.reader.LastReport()
+	// Nothing is known before the first successful inspection
+	if !ok || report.Error != "" {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.logBytesDesc, prometheus.GaugeValue, float64(report.LogBytes))
+	ch <- prometheus.MustNewConstMetric(c.segmentsDesc, prometheus.GaugeValue, float64(report.Segments))
+	ch <- prometheus.MustNewConstMetric(c.snapshotsDesc, prometheus.GaugeValue, float64(report.Snapshots))
+	ch <- prometheus.MustNewConstMetric(c.snapshotBytesDesc, prometheus.GaugeValue, float64(report.SnapshotBytes))
+	if report.Snapshots > 0 {
+		ch <- prometheus.MustNewConstMetric(c.snapshotOffsetDesc, prometheus.GaugeValue, float64(report.LatestSnapshotOffset))
+		ch <- prometheus.MustNewConstMetric(c.oldestAgeDesc, prometheus.GaugeValue, report.CheckedAt.Sub(report.OldestSnapshotAt).Seconds())
+		ch <- prometheus.MustNewConstMetric(c.sinceLatestDesc, prometheus.GaugeValue, report.CheckedAt.Sub(report.LatestSnapshotAt).Seconds())
+	}
+	ch <- prometheus.MustNewConstMetric(c.runawayDesc, prometheus.GaugeValue, boolValue(len(report.Runaway) > 0))
+}
+
+// Register registers the collector with Prometheus
+func (c *KRaftCollector) Register() error {
+	return prometheus.Register(c)
+}
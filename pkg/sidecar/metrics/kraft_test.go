@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kraft"
+)
+
+// MockKRaftReader is a mock implementation of KRaftReader for testing
+type MockKRaftReader struct {
+	Report    kraft.Report
+	Inspected bool
+}
+
+func (m *MockKRaftReader) LastReport() (kraft.Report, bool) {
+	return m.Report, m.Inspected
+}
+
+func TestKRaftCollectorCollect(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		reader   *MockKRaftReader
+		expected int
+	}{
+		{
+			name:     "never inspected",
+			reader:   &MockKRaftReader{},
+			expected: 0,
+		},
+		{
+			name:     "inspection failed",
+			reader:   &MockKRaftReader{Report: kraft.Report{Error: "no such file or directory"}, Inspected: true},
+			expected: 0,
+		},
+		{
+			name: "no snapshots yet",
+			reader: &MockKRaftReader{
+				Report:    kraft.Report{Stats: kraft.Stats{LogBytes: 1024, Segments: 1, LatestSnapshotOffset: -1}, CheckedAt: now},
+				Inspected: true,
+			},
+			expected: 5,
+		},
+		{
+			name: "with snapshots",
+			reader: &MockKRaftReader{
+				Report: kraft.Report{
+					Stats: kraft.Stats{
+						LogBytes:             1024,
+						Segments:             1,
+						Snapshots:            2,
+						SnapshotBytes:        512,
+						LatestSnapshotOffset: 6000,
+						OldestSnapshotAt:     now.Add(-2 * time.Hour),
+						LatestSnapshotAt:     now.Add(-time.Hour),
+					},
+					CheckedAt: now,
+				},
+				Inspected: true,
+			},
+			expected: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewKRaftCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
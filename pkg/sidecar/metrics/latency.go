@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// requestLatencyBuckets span 0.5ms to ~4s in doublings; control-plane requests
+// are answered well below the canary's produce latencies
+var requestLatencyBuckets = prometheus.ExponentialBuckets(0.0005, 2, 14)
+
+// RequestLatencyCollector implements prometheus.Collector for the round-trip
+// times of requests probed against the local broker. It implements
+// latency.Observer to record every probe.
+type RequestLatencyCollector struct {
+	latency  *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+// NewRequestLatencyCollector creates a new Prometheus collector for request latency probes
+func NewRequestLatencyCollector() *RequestLatencyCollector {
+	return &RequestLatencyCollector{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "broker",
+			Name:      "request_latency_seconds",
+			Help:      "Round-trip time of requests probed against the local broker",
+			Buckets:   requestLatencyBuckets,
+		}, []string{"api"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "broker",
+			Name:      "request_probe_failures_total",
+			Help:      "Requests probed against the local broker that failed",
+		}, []string{"api"}),
+	}
+}
+
+// ObserveRequest implements latency.Observer. Failed requests only count as
+// failures, since a timeout would skew the latency distribution.
+func (c *RequestLatencyCollector) ObserveRequest(api string, rtt time.Duration, err error) {
+	if err != nil {
+		c.failures.WithLabelValues(api).Inc()
+		return
+	}
+	c.latency.WithLabelValues(api).Observe(rtt.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (c *RequestLatencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.latency.Describe(ch)
+	c.failures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *RequestLatencyCollector) Collect(ch chan<- prometheus.Metric) {
+	c.latency.Collect(ch)
+	c.failures.Collect(ch)
+}
+
+// Register registers the collector with Prometheus
+func (c *RequestLatencyCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestRequestLatencyCollector(t *testing.T) {
+	collector := NewRequestLatencyCollector()
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no metrics before the first probe, got %d", len(ch))
+	}
+
+	collector.ObserveRequest("Metadata", 2*time.Millisecond, nil)
+	collector.ObserveRequest("Metadata", 4*time.Millisecond, nil)
+	collector.ObserveRequest("ListOffsets", 3*time.Millisecond, nil)
+	collector.ObserveRequest("ListOffsets", 10*time.Second, errors.New("timeout"))
+
+	ch = make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	// Two histograms and one failure counter
+	if len(ch) != 3 {
+		t.Errorf("expected 3 metrics, got %d", len(ch))
+	}
+
+	var m dto.Metric
+	if err := collector.latency.WithLabelValues("ListOffsets").(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatalf("failed to write histogram: %v", err)
+	}
+	if count := m.GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected the failed request not to be observed, got %d samples", count)
+	}
+	m.Reset()
+	if err := collector.failures.WithLabelValues("ListOffsets").Write(&m); err != nil {
+		t.Fatalf("failed to write counter: %v", err)
+	}
+	if failures := m.GetCounter().GetValue(); failures != 1 {
+		t.Errorf("expected 1 failure, got %v", failures)
+	}
+}
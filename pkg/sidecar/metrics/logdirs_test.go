@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/logdirs"
+)
+
+// MockLogDirsReader is a mock implementation of LogDirsReader for testing
+type MockLogDirsReader struct {
+	Sample  logdirs.Sample
+	Sampled bool
+}
+
+func (m *MockLogDirsReader) LastSample() (logdirs.Sample, bool) {
+	return m.Sample, m.Sampled
+}
+
+func TestLogDirsCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockLogDirsReader
+		expected int
+	}{
+		{
+			name:     "never sampled",
+			reader:   &MockLogDirsReader{},
+			expected: 0,
+		},
+		{
+			name: "sampled",
+			reader: &MockLogDirsReader{
+				Sample: logdirs.Sample{
+					Dirs: []logdirs.Dir{{Dir: "/data/a", Bytes: 400, Partitions: 2}},
+					Partitions: []logdirs.Partition{
+						{Topic: "orders", Partition: 0, Dir: "/data/a", Bytes: 300},
+						{Topic: "orders", Partition: 1, Dir: "/data/a", Bytes: 100},
+					},
+				},
+				Sampled: true,
+			},
+			// 2 partitions, 1 directory and the omitted count
+			expected: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewLogDirsCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/maintenance"
+)
+
+// MaintenanceReader provides the last maintenance safety score
+type MaintenanceReader interface {
+	LastReport() (maintenance.Report, bool)
+}
+
+// MaintenanceCollector implements prometheus.Collector for the maintenance safety score
+type MaintenanceCollector struct {
+	reader MaintenanceReader
+
+	scoreDesc     *prometheus.Desc
+	componentDesc *prometheus.Desc
+	safeDesc      *prometheus.Desc
+}
+
+// NewMaintenanceCollector creates a new Prometheus collector for the maintenance safety score
+func NewMaintenanceCollector(reader MaintenanceReader) *MaintenanceCollector {
+	return &MaintenanceCollector{
+		reader: reader,
+		scoreDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "maintenance", "safety_score"),
+			"0-100 score of how safe the cluster is for maintenance (the lowest component score)",
+			nil, nil,
+		),
+		componentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "maintenance", "safety_component_score"),
+			"0-100 score of a single signal of the maintenance safety score",
+			[]string{"component"}, nil,
+		),
+		safeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "maintenance", "safe"),
+			"1 if the safety score is at or above the safe threshold",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *MaintenanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.scoreDesc
+	ch <- c.componentDesc
+	ch <- c.safeDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *MaintenanceCollector) Collect(ch chan<- prometheus.Metric) {
+	report, ok := c.reader.LastReport()
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scoreDesc, prometheus.GaugeValue, float64(report.Score))
+	for _, component := range report.Components {
+		ch <- prometheus.MustNewConstMetric(c.componentDesc, prometheus.GaugeValue, float64(component.Score), component.Name)
+	}
+	ch <- prometheus.MustNewConstMetric(c.safeDesc, prometheus.GaugeValue, boolValue(report.Safe))
+}
+
+// Register registers the collector with Prometheus
+func (c *MaintenanceCollector) Register() error {
+	return prometheus.Register(c)
+}
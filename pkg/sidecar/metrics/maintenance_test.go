@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/maintenance"
+)
+
+// MockMaintenanceReader is a mock implementation of MaintenanceReader for testing
+type MockMaintenanceReader struct {
+	Report   maintenance.Report
+	Computed bool
+}
+
+func (m *MockMaintenanceReader) LastReport() (maintenance.Report, bool) {
+	return m.Report, m.Computed
+}
+
+func TestMaintenanceCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockMaintenanceReader
+		expected int
+	}{
+		{
+			name:     "never computed",
+			reader:   &MockMaintenanceReader{},
+			expected: 0,
+		},
+		{
+			name: "score with components",
+			reader: &MockMaintenanceReader{
+				Report: maintenance.Report{
+					Score: 25,
+					Components: []maintenance.Component{
+						{Name: maintenance.ComponentUnderReplicated, Score: 25},
+						{Name: maintenance.ComponentOffline, Score: 100},
+					},
+				},
+				Computed: true,
+			},
+			// score, 2 components and the safe flag
+			expected: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewMaintenanceCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// loopback is left out of the network metrics; broker and sidecar traffic to
+// localhost never leaves the host
+const loopback = "lo"
+
+// NetworkCollector implements prometheus.Collector for the traffic counters of
+// the pod's network interfaces, so network saturation can be tracked per broker
+type NetworkCollector struct {
+	reader   procfs.NetDevReader
+	logger   *slog.Logger
+	failures atomic.Uint64
+
+	rxBytesDesc   *prometheus.Desc
+	txBytesDesc   *prometheus.Desc
+	rxPacketsDesc *prometheus.Desc
+	txPacketsDesc *prometheus.Desc
+	rxDroppedDesc *prometheus.Desc
+	txDroppedDesc *prometheus.Desc
+}
+
+// NewNetworkCollector creates a new Prometheus collector for network interface counters
+func NewNetworkCollector(logger *slog.Logger, reader procfs.NetDevReader) *NetworkCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "network", name),
+			help,
+			[]string{"interface"}, nil,
+		)
+	}
+	return &NetworkCollector{
+		reader:        reader,
+		logger:        logger,
+		rxBytesDesc:   desc("receive_bytes_total", "Bytes received on the network interface"),
+		txBytesDesc:   desc("transmit_bytes_total", "Bytes transmitted on the network interface"),
+		rxPacketsDesc: desc("receive_packets_total", "Packets received on the network interface"),
+		txPacketsDesc: desc("transmit_packets_total", "Packets transmitted on the network interface"),
+		rxDroppedDesc: desc("receive_dropped_total", "Received packets dropped on the network interface"),
+		txDroppedDesc: desc("transmit_dropped_total", "Transmitted packets dropped on the network interface"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *NetworkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rxBytesDesc
+	ch <- c.txBytesDesc
+	ch <- c.rxPacketsDesc
+	ch <- c.txPacketsDesc
+	ch <- c.rxDroppedDesc
+	ch <- c.txDroppedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *NetworkCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.reader.NetDev()
+	if err != nil {
+		c.logger.Error("failed to read network interface counters", "error", err)
+		c.failures.Add(1)
+		return
+	}
+
+	for _, s := range stats {
+		if s.Interface == loopback {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.rxBytesDesc, prometheus.CounterValue, float64(s.RxBytes), s.Interface)
+		ch <- prometheus.MustNewConstMetric(c.txBytesDesc, prometheus.CounterValue, float64(s.TxBytes), s.Interface)
+		ch <- prometheus.MustNewConstMetric(c.rxPacketsDesc, prometheus.CounterValue, float64(s.RxPackets), s.Interface)
+		ch <- prometheus.MustNewConstMetric(c.txPacketsDesc, prometheus.CounterValue, float64(s.TxPackets), s.Interface)
+		ch <- prometheus.MustNewConstMetric(c.rxDroppedDesc, prometheus.CounterValue, float64(s.RxDropped), s.Interface)
+		ch <- prometheus.MustNewConstMetric(c.txDroppedDesc, prometheus.CounterValue, float64(s.TxDropped), s.Interface)
+	}
+}
+
+// Failures counts the failed reads of the network interface counters
+func (c *NetworkCollector) Failures() uint64 {
+	return c.failures.Load()
+}
+
+// Register registers the collector with Prometheus
+func (c *NetworkCollector) Register() error {
+	return prometheus.Register(c)
+}
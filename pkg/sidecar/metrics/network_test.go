@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// MockNetDevReader is a mock implementation of procfs.NetDevReader for testing
+type MockNetDevReader struct {
+	Stats []procfs.NetDevStats
+	Err   error
+}
+
+func (m *MockNetDevReader) NetDev() ([]procfs.NetDevStats, error) {
+	return m.Stats, m.Err
+}
+
+func TestNetworkCollectorCollect(t *testing.T) {
+	collector := NewNetworkCollector(testLogger(), &MockNetDevReader{Stats: []procfs.NetDevStats{
+		{Interface: "lo", RxBytes: 100, TxBytes: 100},
+		{Interface: "eth0", RxBytes: 2048, TxBytes: 4096, RxPackets: 2, TxPackets: 4},
+	}})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather: %v", err)
+	}
+
+	byName := familiesByName(mfs)
+	if len(byName) != 6 {
+		t.Errorf("expected 6 families, got %d", len(byName))
+	}
+	rx := byName["kafka_network_receive_bytes_total"].GetMetric()
+	if len(rx) != 1 {
+		t.Fatalf("expected loopback to be left out, got %d series", len(rx))
+	}
+	if l := labelMap(rx[0]); l["interface"] != "eth0" {
+		t.Errorf("expected interface eth0, got %v", l)
+	}
+	if v := rx[0].GetCounter().GetValue(); v != 2048 {
+		t.Errorf("expected 2048 received bytes, got %v", v)
+	}
+	if v := byName["kafka_network_transmit_packets_total"].GetMetric()[0].GetCounter().GetValue(); v != 4 {
+		t.Errorf("expected 4 transmitted packets, got %v", v)
+	}
+}
+
+func TestNetworkCollectorReadError(t *testing.T) {
+	collector := NewNetworkCollector(testLogger(), &MockNetDevReader{Err: errors.New("no such file")})
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no metrics, got %d", len(ch))
+	}
+	if collector.Failures() != 1 {
+		t.Errorf("expected 1 failure, got %d", collector.Failures())
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OOMPredictionReader provides the predicted time until the container is OOM killed
+type OOMPredictionReader interface {
+	PredictedSeconds() (float64, bool)
+}
+
+// OOMPredictionCollector implements prometheus.Collector for the OOM prediction
+type OOMPredictionCollector struct {
+	reader OOMPredictionReader
+
+	predictedDesc *prometheus.Desc
+}
+
+// NewOOMPredictionCollector creates a new Prometheus collector for the OOM prediction
+func NewOOMPredictionCollector(reader OOMPredictionReader) *OOMPredictionCollector {
+	return &OOMPredictionCollector{
+		reader: reader,
+		predictedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "oom_predicted_seconds"),
+			"Estimated seconds until the OOM ratio crosses 1.0 at the current growth rate (+Inf when not growing)",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *OOMPredictionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.predictedDesc
+}
+
+// Collect implements prometheus.Collector. Nothing is exported until the
+// predictor has enough samples.
+func (c *OOMPredictionCollector) Collect(ch chan<- prometheus.Metric) {
+	seconds, ok := c.reader.PredictedSeconds()
+	if !ok {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.predictedDesc, prometheus.GaugeValue, seconds)
+}
+
+// Register registers the collector with Prometheus
+func (c *OOMPredictionCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MockOOMPredictionReader is a mock implementation of OOMPredictionReader for testing
+type MockOOMPredictionReader struct {
+	Seconds float64
+	OK      bool
+}
+
+func (m *MockOOMPredictionReader) PredictedSeconds() (float64, bool) {
+	return m.Seconds, m.OK
+}
+
+func TestOOMPredictionCollector(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockOOMPredictionReader
+		expected []float64
+	}{
+		{name: "too few samples", reader: &MockOOMPredictionReader{}},
+		{name: "growing", reader: &MockOOMPredictionReader{Seconds: 1800, OK: true}, expected: []float64{1800}},
+		{name: "not growing", reader: &MockOOMPredictionReader{Seconds: math.Inf(1), OK: true}, expected: []float64{math.Inf(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(NewOOMPredictionCollector(tt.reader))
+			families, err := registry.Gather()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var values []float64
+			for _, f := range families {
+				if f.GetName() != "kafka_memory_oom_predicted_seconds" {
+					t.Errorf("unexpected metric %s", f.GetName())
+				}
+				for _, m := range f.GetMetric() {
+					values = append(values, m.GetGauge().GetValue())
+				}
+			}
+			if len(values) != len(tt.expected) || (len(values) == 1 && values[0] != tt.expected[0]) {
+				t.Errorf("expected %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/peers"
+)
+
+// PeerReader provides the last peer reachability report
+type PeerReader interface {
+	LastReport() (peers.Report, bool)
+}
+
+// PeerCollector implements prometheus.Collector for the peer reachability matrix
+type PeerCollector struct {
+	reader PeerReader
+
+	reachableDesc   *prometheus.Desc
+	sidecarDesc     *prometheus.Desc
+	partitionedDesc *prometheus.Desc
+}
+
+// NewPeerCollector creates a new Prometheus collector for peer reachability
+func NewPeerCollector(reader PeerReader) *PeerCollector {
+	return &PeerCollector{
+		reader: reader,
+		reachableDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "peer", "reachable"),
+			"1 if the sidecar of broker from reached the Kafka port of broker to",
+			[]string{"from", "to"}, nil,
+		),
+		sidecarDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "peer", "sidecar_reachable"),
+			"1 if this sidecar reached the sidecar of the peer broker",
+			[]string{"broker"}, nil,
+		),
+		partitionedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "network", "partitioned"),
+			"1 if asymmetric connectivity between brokers was detected",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *PeerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.reachableDesc
+	ch <- c.sidecarDesc
+	ch <- c.partitionedDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *PeerCollector) Collect(ch chan<- prometheus.Metric) {
+	report, ok := c.reader.LastReport()
+	if !ok {
+		return
+	}
+
+	for from, view := range report.Matrix {
+		for to, reachable := range view {
+			ch <- prometheus.MustNewConstMetric(c.reachableDesc, prometheus.GaugeValue, boolValue(reachable),
+				strconv.Itoa(int(from)), strconv.Itoa(int(to)))
+		}
+	}
+	for broker, reachable := range report.Sidecars {
+		ch <- prometheus.MustNewConstMetric(c.sidecarDesc, prometheus.GaugeValue, boolValue(reachable), strconv.Itoa(int(broker)))
+	}
+	ch <- prometheus.MustNewConstMetric(c.partitionedDesc, prometheus.GaugeValue, boolValue(report.Partitioned))
+}
+
+// Register registers the collector with Prometheus
+func (c *PeerCollector) Register() error {
+	return prometheus.Register(c)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/peers"
+)
+
+// MockPeerReader is a mock implementation of PeerReader for testing
+type MockPeerReader struct {
+	Report  peers.Report
+	Checked bool
+}
+
+func (m *MockPeerReader) LastReport() (peers.Report, bool) {
+	return m.Report, m.Checked
+}
+
+func TestPeerCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockPeerReader
+		expected int
+	}{
+		{
+			name:     "never checked",
+			reader:   &MockPeerReader{},
+			expected: 0,
+		},
+		{
+			name: "matrix",
+			reader: &MockPeerReader{
+				Report: peers.Report{
+					Sidecars: map[int32]bool{2: true},
+					Matrix: map[int32]map[int32]bool{
+						1: {1: true, 2: false},
+						2: {1: true, 2: true},
+					},
+					Partitioned: true,
+				},
+				Checked: true,
+			},
+			// 4 reachable, 1 sidecar and the partitioned flag
+			expected: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewPeerCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// probeDurationBuckets span 5ms to ~10s in doublings, up to the default CHECK_TIMEOUT
+var probeDurationBuckets = prometheus.ExponentialBuckets(0.005, 2, 12)
+
+// ProbeCollector implements prometheus.Collector for the liveness and
+// readiness probes the sidecar answers. It implements health.ProbeObserver to
+// record every probe.
+type ProbeCollector struct {
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+// NewProbeCollector creates a new Prometheus collector for health probes
+func NewProbeCollector() *ProbeCollector {
+	return &ProbeCollector{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "sidecar",
+			Name:      "probe_duration_seconds",
+			Help:      "Time taken to answer liveness and readiness probes, passed or failed",
+			Buckets:   probeDurationBuckets,
+		}, []string{"probe"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sidecar",
+			Name:      "probe_failures_total",
+			Help:      "Liveness and readiness probes that failed, by the check they failed on",
+		}, []string{"probe", "check"}),
+	}
+}
+
+// ObserveProbe implements health.ProbeObserver. Failed probes are timed too,
+// since a probe failing on a timeout is exactly the slowness to look for.
+func (c *ProbeCollector) ObserveProbe(probe string, duration time.Duration, failedCheck string) {
+	c.duration.WithLabelValues(probe).Observe(duration.Seconds())
+	if failedCheck != "" {
+		c.failures.WithLabelValues(probe, failedCheck).Inc()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *ProbeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.failures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *ProbeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.duration.Collect(ch)
+	c.failures.Collect(ch)
+}
+
+// Register registers the collector with Prometheus
+func (c *ProbeCollector) Register() error {
+	return prometheus.Register(c)
+}
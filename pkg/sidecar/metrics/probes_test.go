@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestProbeCollector(t *testing.T) {
+	collector := NewProbeCollector()
+	collector.ObserveProbe("liveness", 5*time.Millisecond, "")
+	collector.ObserveProbe("readiness", 20*time.Millisecond, "")
+	collector.ObserveProbe("readiness", 2*time.Second, "under_replicated")
+	collector.ObserveProbe("readiness", 10*time.Second, "client")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	counts := map[string]uint64{}
+	for _, m := range byName["kafka_sidecar_probe_duration_seconds"].GetMetric() {
+		counts[labelMap(m)["probe"]] = m.GetHistogram().GetSampleCount()
+	}
+	// Failed probes are timed too
+	if counts["liveness"] != 1 || counts["readiness"] != 3 {
+		t.Errorf("unexpected probe counts: %v", counts)
+	}
+
+	failures := map[string]float64{}
+	for _, m := range byName["kafka_sidecar_probe_failures_total"].GetMetric() {
+		l := labelMap(m)
+		if l["probe"] != "readiness" {
+			t.Errorf("expected only readiness failures, got %v", l)
+		}
+		failures[l["check"]] = m.GetCounter().GetValue()
+	}
+	if len(failures) != 2 || failures["under_replicated"] != 1 || failures["client"] != 1 {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+}
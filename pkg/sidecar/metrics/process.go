@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// ProcessCollector implements prometheus.Collector for the resource usage of
+// the Kafka broker JVM, read from /proc through a shared PID namespace. Unlike
+// the cgroup memory metrics, which cover every process in the container and
+// the page cache charged to it, these cover the broker process alone.
+type ProcessCollector struct {
+	reader   procfs.ProcessStatsReader
+	logger   *slog.Logger
+	failures atomic.Uint64
+
+	residentDesc     *prometheus.Desc
+	residentAnonDesc *prometheus.Desc
+	residentFileDesc *prometheus.Desc
+	virtualDesc      *prometheus.Desc
+	cpuDesc          *prometheus.Desc
+	threadsDesc      *prometheus.Desc
+}
+
+// NewProcessCollector creates a new Prometheus collector for the broker process
+func NewProcessCollector(logger *slog.Logger, reader procfs.ProcessStatsReader) *ProcessCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker_process", name),
+			help,
+			nil, nil,
+		)
+	}
+	return &ProcessCollector{
+		reader:           reader,
+		logger:           logger,
+		residentDesc:     desc("resident_memory_bytes", "Resident set size of the Kafka broker process"),
+		residentAnonDesc: desc("resident_anon_memory_bytes", "Anonymous resident memory of the Kafka broker process (heap and off-heap allocations)"),
+		residentFileDesc: desc("resident_file_memory_bytes", "File-backed resident memory of the Kafka broker process (memory-mapped files)"),
+		virtualDesc:      desc("virtual_memory_bytes", "Virtual memory size of the Kafka broker process"),
+		cpuDesc:          desc("cpu_seconds_total", "User and system CPU time consumed by the Kafka broker process"),
+		threadsDesc:      desc("threads", "Threads of the Kafka broker process"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *ProcessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.residentDesc
+	ch <- c.residentAnonDesc
+	ch <- c.residentFileDesc
+	ch <- c.virtualDesc
+	ch <- c.cpuDesc
+	ch <- c.threadsDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *ProcessCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.reader.ProcessStats()
+	if err != nil {
+		c.logger.Error("failed to read broker process stats", "error", err)
+		c.failures.Add(1)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.residentDesc, prometheus.GaugeValue, float64(stats.ResidentBytes))
+	ch <- prometheus.MustNewConstMetric(c.residentAnonDesc, prometheus.GaugeValue, float64(stats.ResidentAnonBytes))
+	ch <- prometheus.MustNewConstMetric(c.residentFileDesc, prometheus.GaugeValue, float64(stats.ResidentFileBytes))
+	ch <- prometheus.MustNewConstMetric(c.virtualDesc, prometheus.GaugeValue, float64(stats.VirtualBytes))
+	ch <- prometheus.MustNewConstMetric(c.cpuDesc, prometheus.CounterValue, stats.CPUSeconds)
+	ch <- prometheus.MustNewConstMetric(c.threadsDesc, prometheus.GaugeValue, float64(stats.Threads))
+}
+
+// Failures counts the failed reads of the broker process stats
+func (c *ProcessCollector) Failures() uint64 {
+	return c.failures.Load()
+}
+
+// Register registers the collector with Prometheus
+func (c *ProcessCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/procfs"
+)
+
+// MockProcessStatsReader is a mock implementation of procfs.ProcessStatsReader for testing
+type MockProcessStatsReader struct {
+	Stats procfs.ProcessStats
+	Err   error
+}
+
+func (m *MockProcessStatsReader) ProcessStats() (procfs.ProcessStats, error) {
+	return m.Stats, m.Err
+}
+
+func TestProcessCollectorCollect(t *testing.T) {
+	collector := NewProcessCollector(testLogger(), &MockProcessStatsReader{Stats: procfs.ProcessStats{
+		ResidentBytes:     2 << 30,
+		ResidentAnonBytes: 1 << 30,
+		ResidentFileBytes: 1 << 30,
+		VirtualBytes:      8 << 30,
+		CPUSeconds:        12.5,
+		Threads:           142,
+	}})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather: %v", err)
+	}
+
+	byName := familiesByName(mfs)
+	if len(byName) != 6 {
+		t.Errorf("expected 6 families, got %d", len(byName))
+	}
+	if v := byName["kafka_broker_process_resident_memory_bytes"].GetMetric()[0].GetGauge().GetValue(); v != 2<<30 {
+		t.Errorf("expected 2GiB resident, got %v", v)
+	}
+	if v := byName["kafka_broker_process_cpu_seconds_total"].GetMetric()[0].GetCounter().GetValue(); v != 12.5 {
+		t.Errorf("expected 12.5 CPU seconds, got %v", v)
+	}
+	if v := byName["kafka_broker_process_threads"].GetMetric()[0].GetGauge().GetValue(); v != 142 {
+		t.Errorf("expected 142 threads, got %v", v)
+	}
+}
+
+func TestProcessCollectorReadError(t *testing.T) {
+	collector := NewProcessCollector(testLogger(), &MockProcessStatsReader{Err: procfs.ErrProcessNotFound})
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+	if len(ch) != 0 {
+		t.Errorf("expected no metrics, got %d", len(ch))
+	}
+	if collector.Failures() != 1 {
+		t.Errorf("expected 1 failure, got %d", collector.Failures())
+	}
+}
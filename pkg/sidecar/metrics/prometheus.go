@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"log/slog"
+	"sync/atomic"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -13,8 +14,9 @@ const (
 
 // Collector implements prometheus.Collector for Kafka memory metrics
 type Collector struct {
-	reader CgroupReader
-	logger *slog.Logger
+	reader   CgroupReader
+	logger   *slog.Logger
+	failures atomic.Uint64
 
 	usageDesc          *prometheus.Desc
 	limitDesc          *prometheus.Desc
@@ -97,6 +99,7 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	metrics, err := c.reader.ReadMemoryMetrics()
 	if err != nil {
 		c.logger.Error("failed to read memory metrics", "error", err)
+		c.failures.Add(1)
 		return
 	}
 
@@ -110,6 +113,11 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.oomFloorRatioDesc, prometheus.GaugeValue, metrics.OOMFloorRatio)
 }
 
+// Failures counts the failed reads of the cgroup memory files
+func (c *Collector) Failures() uint64 {
+	return c.failures.Load()
+}
+
 // Register registers the collector with Prometheus
 func (c *Collector) Register() error {
 	return prometheus.Register(c)
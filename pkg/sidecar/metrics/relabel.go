@@ -0,0 +1,281 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// Relabel actions
+const (
+	// ActionDrop removes the matching series
+	ActionDrop = "drop"
+	// ActionRename renames the matching metrics
+	ActionRename = "rename"
+)
+
+// RelabelRule drops or renames the metrics whose name matches Metric
+type RelabelRule struct {
+	// Metric is a regular expression matched against the whole metric name
+	Metric string `json:"metric"`
+	// Action is drop or rename
+	Action string `json:"action"`
+	// Labels restricts a drop to the series whose label values match, by
+	// label name to a regular expression matched against the whole value. A
+	// missing label matches as an empty value.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Name is the new name of a renamed metric, and may refer to groups of
+	// Metric as $1, ${1} or ${name}
+	Name string `json:"name,omitempty"`
+
+	metric *regexp.Regexp
+	labels map[string]*regexp.Regexp
+}
+
+// RelabelConfig is the relabeling of /metrics from the mounted file
+type RelabelConfig struct {
+	// Labels are added to every series; a series that already has a label
+	// keeps its own value
+	Labels map[string]string `json:"labels,omitempty"`
+	// Rules are applied in order, so a rule matches the names given by the
+	// renames before it
+	Rules []RelabelRule `json:"rules,omitempty"`
+}
+
+// LoadRelabelConfig reads and validates a relabel file
+func LoadRelabelConfig(path string) (RelabelConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RelabelConfig{}, fmt.Errorf("failed to read relabel config: %w", err)
+	}
+	return ParseRelabelConfig(data)
+}
+
+// ParseRelabelConfig parses and validates a relabel config, compiling its
+// regular expressions
+func ParseRelabelConfig(data []byte) (RelabelConfig, error) {
+	var cfg RelabelConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return RelabelConfig{}, fmt.Errorf("invalid relabel config: %w", err)
+	}
+
+	for name := range cfg.Labels {
+		if !model.LabelName(name).IsValidLegacy() || model.LabelName(name) == model.MetricNameLabel {
+			return RelabelConfig{}, fmt.Errorf("invalid relabel config: invalid label name %q", name)
+		}
+	}
+	for i := range cfg.Rules {
+		if err := cfg.Rules[i].compile(); err != nil {
+			return RelabelConfig{}, fmt.Errorf("invalid relabel config: rule %d: %w", i+1, err)
+		}
+	}
+	return cfg, nil
+}
+
+// compile validates the rule and compiles its regular expressions
+func (r *RelabelRule) compile() error {
+	if r.Metric == "" {
+		return fmt.Errorf("metric is required")
+	}
+	metric, err := regexp.Compile("^(?:" + r.Metric + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid metric pattern: %w", err)
+	}
+	r.metric = metric
+
+	switch r.Action {
+	case ActionDrop:
+		if r.Name != "" {
+			return fmt.Errorf("name is only used by %s", ActionRename)
+		}
+		r.labels = make(map[string]*regexp.Regexp, len(r.Labels))
+		for name, pattern := range r.Labels {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return fmt.Errorf("invalid pattern for label %s: %w", name, err)
+			}
+			r.labels[name] = re
+		}
+	case ActionRename:
+		if r.Name == "" {
+			return fmt.Errorf("name is required to %s", ActionRename)
+		}
+		if len(r.Labels) > 0 {
+			// Renaming some series of a metric would split it in two
+			return fmt.Errorf("labels are only used by %s", ActionDrop)
+		}
+	default:
+		return fmt.Errorf("unknown action %q (valid: %s, %s)", r.Action, ActionDrop, ActionRename)
+	}
+	return nil
+}
+
+// rename returns the new name of a metric the rule matches
+func (r *RelabelRule) rename(name string) (string, error) {
+	match := r.metric.FindStringSubmatchIndex(name)
+	renamed := string(r.metric.ExpandString(nil, r.Name, name, match))
+	if !model.IsValidLegacyMetricName(renamed) {
+		return "", fmt.Errorf("rule renames %s to invalid name %q", name, renamed)
+	}
+	return renamed, nil
+}
+
+// dropped reports whether a drop rule removes the series
+func (r *RelabelRule) dropped(m *dto.Metric) bool {
+	for name, re := range r.labels {
+		value := ""
+		for _, p := range m.Label {
+			if p.GetName() == name {
+				value = p.GetValue()
+				break
+			}
+		}
+		if !re.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// RelabelGatherer applies a relabel config to everything another gatherer
+// returns, so unwanted or high-cardinality series are suppressed before they
+// reach Prometheus rather than by every scrape config
+type RelabelGatherer struct {
+	gatherer prometheus.Gatherer
+	cfg      RelabelConfig
+}
+
+// NewRelabelGatherer wraps gatherer with the relabel config
+func NewRelabelGatherer(gatherer prometheus.Gatherer, cfg RelabelConfig) *RelabelGatherer {
+	return &RelabelGatherer{gatherer: gatherer, cfg: cfg}
+}
+
+// Gather implements prometheus.Gatherer. A metric renamed onto another of a
+// different type, or onto series the other already has, is dropped and
+// reported as an error, which the handler logs without failing the scrape.
+func (g *RelabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	var errs prometheus.MultiError
+	if err != nil {
+		errs.Append(err)
+	}
+
+	for _, rule := range g.cfg.Rules {
+		kept := families[:0]
+		for _, mf := range families {
+			if !rule.metric.MatchString(mf.GetName()) {
+				kept = append(kept, mf)
+				continue
+			}
+			switch rule.Action {
+			case ActionDrop:
+				series := mf.Metric[:0]
+				for _, m := range mf.Metric {
+					if !rule.dropped(m) {
+						series = append(series, m)
+					}
+				}
+				mf.Metric = series
+				if len(series) == 0 {
+					continue
+				}
+			case ActionRename:
+				name, err := rule.rename(mf.GetName())
+				if err != nil {
+					errs.Append(err)
+					continue
+				}
+				mf.Name = proto.String(name)
+			}
+			kept = append(kept, mf)
+		}
+		families = kept
+	}
+
+	if len(g.cfg.Labels) > 0 {
+		for _, mf := range families {
+			for _, m := range mf.Metric {
+				m.Label = addLabels(m.Label, g.cfg.Labels)
+			}
+		}
+	}
+
+	families, mergeErrs := mergeFamilies(families)
+	for _, err := range mergeErrs {
+		errs.Append(err)
+	}
+	return families, errs.MaybeUnwrap()
+}
+
+// addLabels adds the labels a series does not have yet, keeping the pairs
+// sorted by name as the exposition format expects
+func addLabels(pairs []*dto.LabelPair, labels map[string]string) []*dto.LabelPair {
+	have := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		have[p.GetName()] = true
+	}
+	out := pairs
+	for name, value := range labels {
+		if !have[name] {
+			out = append(out, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].GetName() < out[j].GetName() })
+	return out
+}
+
+// mergeFamilies merges the families that renames gave the same name and sorts
+// them by name. A family of a different type than the first of its name, and a
+// series with the same labels as one already merged, are dropped.
+func mergeFamilies(families []*dto.MetricFamily) ([]*dto.MetricFamily, []error) {
+	var errs []error
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	merged := families[:0]
+	for _, mf := range families {
+		first, ok := byName[mf.GetName()]
+		if !ok {
+			byName[mf.GetName()] = mf
+			merged = append(merged, mf)
+			continue
+		}
+		if first.GetType() != mf.GetType() {
+			errs = append(errs, fmt.Errorf("relabeled metric %s is both %s and %s, dropping the %s series",
+				mf.GetName(), first.GetType(), mf.GetType(), mf.GetType()))
+			continue
+		}
+
+		seen := make(map[string]bool, len(first.Metric))
+		for _, m := range first.Metric {
+			seen[labelKey(m)] = true
+		}
+		for _, m := range mf.Metric {
+			if key := labelKey(m); seen[key] {
+				errs = append(errs, fmt.Errorf("relabeled metric %s has duplicate series {%s}, dropping it", mf.GetName(), key))
+				continue
+			}
+			first.Metric = append(first.Metric, m)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].GetName() < merged[j].GetName() })
+	return merged, errs
+}
+
+// labelKey identifies a series by its sorted label pairs
+func labelKey(m *dto.Metric) string {
+	var b strings.Builder
+	for i, p := range m.Label {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", p.GetName(), p.GetValue())
+	}
+	return b.String()
+}
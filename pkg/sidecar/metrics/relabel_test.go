@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+const relabelBody = `# TYPE kafka_partition_size_bytes gauge
+kafka_partition_size_bytes{topic="orders",partition="0"} 1024
+kafka_partition_size_bytes{topic="__consumer_offsets",partition="7"} 2048
+# TYPE kafka_jmx_bytes_in_total counter
+kafka_jmx_bytes_in_total 10
+# TYPE kafka_jmx_bytes_out_total counter
+kafka_jmx_bytes_out_total 20
+# TYPE kafka_network_receive_bytes_total counter
+kafka_network_receive_bytes_total{interface="eth0"} 30
+# TYPE kafka_handshake_duration_seconds gauge
+kafka_handshake_duration_seconds{listener="INTERNAL",cluster="own"} 0.1
+`
+
+// textGatherer gathers families parsed from a text exposition, sorted by name
+// as a registry gathers them
+func textGatherer(t *testing.T, body string) prometheus.Gatherer {
+	t.Helper()
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		parser := expfmt.NewTextParser(model.LegacyValidation)
+		parsed, err := parser.TextToMetricFamilies(strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var families []*dto.MetricFamily
+		for _, mf := range parsed {
+			families = append(families, mf)
+		}
+		sort.Slice(families, func(i, j int) bool { return families[i].GetName() < families[j].GetName() })
+		return families, nil
+	})
+}
+
+func TestRelabelGatherer(t *testing.T) {
+	cfg, err := ParseRelabelConfig([]byte(`{
+		"labels": {"cluster": "prod", "team": "streaming"},
+		"rules": [
+			{"metric": "kafka_partition_size_bytes", "action": "drop", "labels": {"topic": "__.*"}},
+			{"metric": "kafka_jmx_(.*)", "action": "rename", "name": "kafka_broker_$1"},
+			{"metric": "kafka_network_.*", "action": "drop"}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mfs, err := NewRelabelGatherer(textGatherer(t, relabelBody), cfg).Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	if sizes := byName["kafka_partition_size_bytes"].GetMetric(); len(sizes) != 1 || labelMap(sizes[0])["topic"] != "orders" {
+		t.Errorf("expected only the internal topic's series to be dropped, got %v", sizes)
+	}
+	if _, ok := byName["kafka_network_receive_bytes_total"]; ok {
+		t.Error("expected the dropped metric to be removed")
+	}
+	for _, name := range []string{"kafka_jmx_bytes_in_total", "kafka_jmx_bytes_out_total"} {
+		if _, ok := byName[name]; ok {
+			t.Errorf("expected %s to be renamed", name)
+		}
+	}
+	if _, ok := byName["kafka_broker_bytes_in_total"]; !ok {
+		t.Errorf("expected kafka_broker_bytes_in_total, got %v", byName)
+	}
+
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			l := labelMap(m)
+			if l["team"] != "streaming" {
+				t.Errorf("expected the team label on %s, got %v", mf.GetName(), l)
+			}
+			// Series keep a label they already have
+			want := "prod"
+			if mf.GetName() == "kafka_handshake_duration_seconds" {
+				want = "own"
+			}
+			if l["cluster"] != want {
+				t.Errorf("expected cluster=%s on %s, got %v", want, mf.GetName(), l)
+			}
+			for i := 1; i < len(m.GetLabel()); i++ {
+				if m.GetLabel()[i-1].GetName() > m.GetLabel()[i].GetName() {
+					t.Errorf("expected sorted labels on %s, got %v", mf.GetName(), m.GetLabel())
+				}
+			}
+		}
+	}
+	for i := 1; i < len(mfs); i++ {
+		if mfs[i-1].GetName() > mfs[i].GetName() {
+			t.Errorf("expected families sorted by name, got %s before %s", mfs[i-1].GetName(), mfs[i].GetName())
+		}
+	}
+}
+
+func TestRelabelGathererMergesRenames(t *testing.T) {
+	cfg, err := ParseRelabelConfig([]byte(`{"rules": [
+		{"metric": "kafka_jmx_bytes_(in|out)_total", "action": "rename", "name": "kafka_jmx_bytes_total"},
+		{"metric": "kafka_partition_size_bytes", "action": "rename", "name": "kafka_network_receive_bytes_total"}
+	]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mfs, err := NewRelabelGatherer(textGatherer(t, relabelBody), cfg).Gather()
+	if err == nil {
+		t.Fatal("expected the conflicting renames to be reported")
+	}
+	if !strings.Contains(err.Error(), "kafka_network_receive_bytes_total") {
+		t.Errorf("expected the type conflict to be reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "duplicate series") {
+		t.Errorf("expected the duplicate series to be reported, got %v", err)
+	}
+	byName := familiesByName(mfs)
+	if n := len(byName["kafka_jmx_bytes_total"].GetMetric()); n != 1 {
+		t.Errorf("expected the duplicate series to be dropped, got %d series", n)
+	}
+	if mf := byName["kafka_network_receive_bytes_total"]; mf.GetType() != dto.MetricType_COUNTER || len(mf.GetMetric()) != 1 {
+		t.Errorf("expected the gauge renamed onto a counter to be dropped, got %v", mf)
+	}
+}
+
+func TestParseRelabelConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "not json", config: `rules:`},
+		{name: "missing metric", config: `{"rules": [{"action": "drop"}]}`},
+		{name: "bad pattern", config: `{"rules": [{"metric": "kafka_(", "action": "drop"}]}`},
+		{name: "bad label pattern", config: `{"rules": [{"metric": "kafka_.*", "action": "drop", "labels": {"topic": "["}}]}`},
+		{name: "unknown action", config: `{"rules": [{"metric": "kafka_.*", "action": "keep"}]}`},
+		{name: "rename without name", config: `{"rules": [{"metric": "kafka_.*", "action": "rename"}]}`},
+		{name: "rename with labels", config: `{"rules": [{"metric": "kafka_.*", "action": "rename", "name": "x", "labels": {"topic": "a"}}]}`},
+		{name: "invalid label", config: `{"labels": {"cluster-name": "prod"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRelabelConfig([]byte(tt.config)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requesterrors"
+)
+
+// RequestErrorsReader provides the last sample of the broker's request errors
+type RequestErrorsReader interface {
+	LastReport() (requesterrors.Report, bool)
+}
+
+// RequestErrorsCollector implements prometheus.Collector for the broker's
+// request and error counts by API
+type RequestErrorsCollector struct {
+	reader RequestErrorsReader
+
+	requestsDesc       *prometheus.Desc
+	errorsDesc         *prometheus.Desc
+	errorRateDesc      *prometheus.Desc
+	errorRatioDesc     *prometheus.Desc
+	aboveThresholdDesc *prometheus.Desc
+}
+
+// NewRequestErrorsCollector creates a new Prometheus collector for request errors
+func NewRequestErrorsCollector(reader RequestErrorsReader) *RequestErrorsCollector {
+	return &RequestErrorsCollector{
+		reader: reader,
+		requestsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "requests_total"),
+			"Requests handled by the broker by API (RequestsPerSec)",
+			[]string{"request"}, nil,
+		),
+		errorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "request_errors_total"),
+			"Requests answered with an error by API and error code (ErrorsPerSec)",
+			[]string{"request", "error"}, nil,
+		),
+		errorRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "request_error_rate"),
+			"Requests answered with an error per second over the last sampling interval",
+			[]string{"request"}, nil,
+		),
+		errorRatioDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "request_error_ratio"),
+			"Fraction of requests answered with an error over the last sampling interval",
+			[]string{"request"}, nil,
+		),
+		aboveThresholdDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "request_error_ratio_above_threshold"),
+			"1 if the API's error ratio is above its threshold, degrading readiness",
+			[]string{"request"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *RequestErrorsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.requestsDesc
+	ch <- c.errorsDesc
+	ch <- c.errorRateDesc
+	ch <- c.errorRatioDesc
+	ch <- c.aboveThresholdDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *RequestErrorsCollector) Collect(ch chan<- prometheus.Metric) {
+	report, ok := c.reader.LastReport()
+	if !ok {
+		return
+	}
+
+	for _, api := range report.APIs {
+		ch <- prometheus.MustNewConstMetric(c.requestsDesc, prometheus.CounterValue, api.Requests, api.API)
+		for code, n := range api.Errors {
+			ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, n, api.API, code)
+		}
+		ch <- prometheus.MustNewConstMetric(c.errorRateDesc, prometheus.GaugeValue, api.ErrorRate, api.API)
+		ch <- prometheus.MustNewConstMetric(c.errorRatioDesc, prometheus.GaugeValue, api.ErrorRatio, api.API)
+		ch <- prometheus.MustNewConstMetric(c.aboveThresholdDesc, prometheus.GaugeValue, boolValue(api.AboveThreshold), api.API)
+	}
+}
+
+// Register registers the collector with Prometheus
+func (c *RequestErrorsCollector) Register() error {
+	return prometheus.Register(c)
+}
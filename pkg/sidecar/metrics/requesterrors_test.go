@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requesterrors"
+)
+
+// MockRequestErrorsReader is a mock implementation of RequestErrorsReader for testing
+type MockRequestErrorsReader struct {
+	Report  requesterrors.Report
+	Sampled bool
+}
+
+func (m *MockRequestErrorsReader) LastReport() (requesterrors.Report, bool) {
+	return m.Report, m.Sampled
+}
+
+func TestRequestErrorsCollector(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewRequestErrorsCollector(&MockRequestErrorsReader{}))
+	if mfs, err := registry.Gather(); err != nil || len(mfs) != 0 {
+		t.Fatalf("expected no metrics before the first sample, got %v %v", mfs, err)
+	}
+
+	reader := &MockRequestErrorsReader{
+		Report: requesterrors.Report{
+			SampledAt: time.Now(),
+			APIs: []requesterrors.API{
+				{
+					API:            "Produce",
+					Requests:       1200,
+					Errors:         map[string]float64{"NOT_LEADER_OR_FOLLOWER": 60},
+					RequestRate:    20,
+					ErrorRate:      5,
+					ErrorRatio:     0.25,
+					AboveThreshold: true,
+				},
+				{API: "Fetch", Requests: 5000, RequestRate: 100},
+			},
+		},
+		Sampled: true,
+	}
+	registry = prometheus.NewRegistry()
+	registry.MustRegister(NewRequestErrorsCollector(reader))
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := familiesByName(mfs)
+
+	errs := byName["kafka_broker_request_errors_total"]
+	if errs == nil || len(errs.GetMetric()) != 1 {
+		t.Fatalf("expected one error series, got %v", errs)
+	}
+	labels := labelMap(errs.GetMetric()[0])
+	if labels["request"] != "Produce" || labels["error"] != "NOT_LEADER_OR_FOLLOWER" || errs.GetMetric()[0].GetCounter().GetValue() != 60 {
+		t.Errorf("unexpected error series %v", errs.GetMetric()[0])
+	}
+	if n := len(byName["kafka_broker_requests_total"].GetMetric()); n != 2 {
+		t.Errorf("expected 2 request series, got %d", n)
+	}
+	for _, m := range byName["kafka_broker_request_error_ratio_above_threshold"].GetMetric() {
+		want := 0.0
+		if labelMap(m)["request"] == "Produce" {
+			want = 1
+		}
+		if m.GetGauge().GetValue() != want {
+			t.Errorf("unexpected threshold series %v", m)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SafeModeReader reports whether the sidecar is in safe mode
+type SafeModeReader interface {
+	Active() bool
+}
+
+// SafeModeCollector implements prometheus.Collector for the safe mode status
+type SafeModeCollector struct {
+	reader SafeModeReader
+
+	safeModeDesc *prometheus.Desc
+}
+
+// NewSafeModeCollector creates a new Prometheus collector for the safe mode status
+func NewSafeModeCollector(reader SafeModeReader) *SafeModeCollector {
+	return &SafeModeCollector{
+		reader: reader,
+		safeModeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sidecar", "safe_mode"),
+			"Whether the sidecar is in safe mode because its persistent state is corrupted (1) or not (0)",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *SafeModeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.safeModeDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *SafeModeCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.safeModeDesc, prometheus.GaugeValue, boolValue(c.reader.Active()))
+}
+
+// Register registers the collector with Prometheus
+func (c *SafeModeCollector) Register() error {
+	return prometheus.Register(c)
+}
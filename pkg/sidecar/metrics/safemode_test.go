@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MockSafeModeReader is a mock implementation of SafeModeReader for testing
+type MockSafeModeReader struct {
+	SafeMode bool
+}
+
+func (m *MockSafeModeReader) Active() bool {
+	return m.SafeMode
+}
+
+func TestSafeModeCollector(t *testing.T) {
+	for _, active := range []bool{false, true} {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(NewSafeModeCollector(&MockSafeModeReader{SafeMode: active}))
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(families) != 1 || families[0].GetName() != "kafka_sidecar_safe_mode" {
+			t.Fatalf("expected kafka_sidecar_safe_mode, got %v", families)
+		}
+		if got := families[0].GetMetric()[0].GetGauge().GetValue(); got != boolValue(active) {
+			t.Errorf("expected %v for active=%v, got %v", boolValue(active), active, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// UpstreamGatherer fetches the Prometheus text exposition of another exporter
+// on the same replica (e.g. the JMX exporter in the Kafka container) and adds
+// identifying labels to every series, so it can be merged into the sidecar's
+// /metrics and scrapers need a single target per replica
+type UpstreamGatherer struct {
+	url     string
+	labels  map[string]string
+	timeout time.Duration
+	client  *http.Client
+	logger  *slog.Logger
+}
+
+// NewUpstreamGatherer creates a gatherer for the exporter at url. Every series
+// gets labels, overriding any label of the same name set upstream; empty
+// values are skipped.
+func NewUpstreamGatherer(url string, labels map[string]string, timeout time.Duration, logger *slog.Logger) *UpstreamGatherer {
+	set := map[string]string{}
+	for name, value := range labels {
+		if value != "" {
+			set[name] = value
+		}
+	}
+	return &UpstreamGatherer{
+		url:     url,
+		labels:  set,
+		timeout: timeout,
+		client:  &http.Client{},
+		logger:  logger,
+	}
+}
+
+// Gather implements prometheus.Gatherer. A failed fetch never fails the
+// sidecar's own scrape; it is reported as kafka_upstream_metrics_up 0.
+func (g *UpstreamGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.fetch()
+	if err != nil {
+		g.logger.Warn("failed to fetch upstream metrics", "url", g.url, "error", err)
+	}
+	for _, mf := range families {
+		for _, m := range mf.Metric {
+			m.Label = relabel(m.Label, g.labels)
+		}
+	}
+	return append(families, g.upFamily(err == nil)), nil
+}
+
+func (g *UpstreamGatherer) fetch() ([]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Ask for the classic text format; OpenMetrics would need a different parser
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	parsed, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	families := make([]*dto.MetricFamily, 0, len(parsed))
+	for _, mf := range parsed {
+		families = append(families, mf)
+	}
+	return families, nil
+}
+
+// relabel sets the labels on a series, replacing existing values, and keeps
+// the pairs sorted by name as the exposition format expects
+func relabel(pairs []*dto.LabelPair, labels map[string]string) []*dto.LabelPair {
+	out := make([]*dto.LabelPair, 0, len(pairs)+len(labels))
+	for _, p := range pairs {
+		if _, ok := labels[p.GetName()]; !ok {
+			out = append(out, p)
+		}
+	}
+	for name, value := range labels {
+		out = append(out, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].GetName() < out[j].GetName() })
+	return out
+}
+
+func (g *UpstreamGatherer) upFamily(up bool) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name: proto.String(prometheus.BuildFQName(namespace, "upstream", "metrics_up")),
+		Help: proto.String("1 if the upstream metrics endpoint could be scraped and parsed"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Gauge: &dto.Gauge{Value: proto.Float64(boolValue(up))},
+		}},
+	}
+}
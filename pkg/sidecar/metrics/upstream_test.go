@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const upstreamBody = `# HELP kafka_server_brokertopicmetrics_bytesin_total Bytes in
+# TYPE kafka_server_brokertopicmetrics_bytesin_total counter
+kafka_server_brokertopicmetrics_bytesin_total{topic="orders"} 1024
+kafka_server_brokertopicmetrics_bytesin_total{broker_id="stale",topic="refunds"} 512
+# HELP jvm_threads_current Current thread count
+# TYPE jvm_threads_current gauge
+jvm_threads_current 87
+`
+
+// familiesByName indexes gathered families by name
+func familiesByName(mfs []*dto.MetricFamily) map[string]*dto.MetricFamily {
+	out := map[string]*dto.MetricFamily{}
+	for _, mf := range mfs {
+		out[mf.GetName()] = mf
+	}
+	return out
+}
+
+// labelMap flattens a series' label pairs
+func labelMap(m *dto.Metric) map[string]string {
+	out := map[string]string{}
+	for _, p := range m.GetLabel() {
+		out[p.GetName()] = p.GetValue()
+	}
+	return out
+}
+
+func TestUpstreamGathererRelabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(upstreamBody))
+	}))
+	defer server.Close()
+
+	g := NewUpstreamGatherer(server.URL, map[string]string{"broker_id": "2", "location": "aws-us-west-2"}, time.Second, testLogger())
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byName := familiesByName(mfs)
+	if len(byName) != 3 {
+		t.Fatalf("expected 2 upstream families and the up gauge, got %d", len(byName))
+	}
+	for _, m := range byName["kafka_server_brokertopicmetrics_bytesin_total"].GetMetric() {
+		l := labelMap(m)
+		if l["broker_id"] != "2" || l["location"] != "aws-us-west-2" || l["topic"] == "" {
+			t.Errorf("unexpected labels: %v", l)
+		}
+		// The exposition format expects label pairs sorted by name
+		pairs := m.GetLabel()
+		for i := 1; i < len(pairs); i++ {
+			if pairs[i-1].GetName() > pairs[i].GetName() {
+				t.Errorf("labels not sorted: %v", pairs)
+			}
+		}
+	}
+	if l := labelMap(byName["jvm_threads_current"].GetMetric()[0]); len(l) != 2 {
+		t.Errorf("expected only the added labels, got %v", l)
+	}
+	if up := byName["kafka_upstream_metrics_up"].GetMetric()[0].GetGauge().GetValue(); up != 1 {
+		t.Errorf("expected upstream up, got %v", up)
+	}
+}
+
+func TestUpstreamGathererSkipsEmptyLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(upstreamBody))
+	}))
+	defer server.Close()
+
+	g := NewUpstreamGatherer(server.URL, map[string]string{"broker_id": "2", "location": ""}, time.Second, testLogger())
+	mfs, _ := g.Gather()
+	if l := labelMap(familiesByName(mfs)["jvm_threads_current"].GetMetric()[0]); len(l) != 1 || l["broker_id"] != "2" {
+		t.Errorf("expected only broker_id, got %v", l)
+	}
+}
+
+func TestUpstreamGathererDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	g := NewUpstreamGatherer(server.URL, nil, time.Second, testLogger())
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("expected a failed fetch not to fail the scrape, got %v", err)
+	}
+	if len(mfs) != 1 || mfs[0].GetMetric()[0].GetGauge().GetValue() != 0 {
+		t.Errorf("expected only kafka_upstream_metrics_up 0, got %v", mfs)
+	}
+}
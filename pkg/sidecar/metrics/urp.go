@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// URPReader provides the counts from the last under-replicated partitions check
+type URPReader interface {
+	LastURPCounts() (health.URPCounts, bool)
+}
+
+// URPCollector implements prometheus.Collector for the broker's under-replicated
+// partitions, including those of topics excluded from readiness, and the
+// partitions it hosts and leads
+type URPCollector struct {
+	reader URPReader
+
+	countedDesc    *prometheus.Desc
+	excludedDesc   *prometheus.Desc
+	partitionsDesc *prometheus.Desc
+	leadersDesc    *prometheus.Desc
+}
+
+// NewURPCollector creates a new Prometheus collector for under-replicated partitions
+func NewURPCollector(reader URPReader) *URPCollector {
+	return &URPCollector{
+		reader: reader,
+		countedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "under_replicated_partitions"),
+			"Under-replicated partitions of this broker that fail readiness, as of the last readiness check",
+			nil, nil,
+		),
+		excludedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "excluded_under_replicated_partitions"),
+			"Under-replicated partitions of this broker in topics excluded from readiness, as of the last readiness check",
+			nil, nil,
+		),
+		partitionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "partition_count"),
+			"Partitions with a replica on this broker, as of the last readiness check",
+			nil, nil,
+		),
+		leadersDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "broker", "leader_count"),
+			"Partitions led by this broker, as of the last readiness check",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *URPCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.countedDesc
+	ch <- c.excludedDesc
+	ch <- c.partitionsDesc
+	ch <- c.leadersDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *URPCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, ok := c.reader.LastURPCounts()
+	// Nothing is known before the first readiness check
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.countedDesc, prometheus.GaugeValue, float64(counts.Counted))
+	ch <- prometheus.MustNewConstMetric(c.excludedDesc, prometheus.GaugeValue, float64(counts.Excluded))
+	ch <- prometheus.MustNewConstMetric(c.partitionsDesc, prometheus.GaugeValue, float64(counts.Partitions))
+	ch <- prometheus.MustNewConstMetric(c.leadersDesc, prometheus.GaugeValue, float64(counts.Leaders))
+}
+
+// Register registers the collector with Prometheus
+func (c *URPCollector) Register() error {
+	return prometheus.Register(c)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// MockURPReader is a mock implementation of URPReader for testing
+type MockURPReader struct {
+	Counts  health.URPCounts
+	Checked bool
+}
+
+func (m *MockURPReader) LastURPCounts() (health.URPCounts, bool) {
+	return m.Counts, m.Checked
+}
+
+func TestURPCollectorCollect(t *testing.T) {
+	tests := []struct {
+		name     string
+		reader   *MockURPReader
+		expected int
+	}{
+		{
+			name:     "never checked",
+			reader:   &MockURPReader{},
+			expected: 0,
+		},
+		{
+			name:     "checked",
+			reader:   &MockURPReader{Counts: health.URPCounts{Counted: 0, Excluded: 3, Partitions: 12, Leaders: 4}, Checked: true},
+			expected: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewURPCollector(tt.reader)
+
+			ch := make(chan prometheus.Metric, 10)
+			collector.Collect(ch)
+			close(ch)
+
+			count := 0
+			for range ch {
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("expected %d metrics, got %d", tt.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package offsets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	DescribeGroupsFunc func(ctx context.Context, groups ...string) (kadm.DescribedGroups, error)
+	FetchOffsetsFunc   func(ctx context.Context, group string) (kadm.OffsetResponses, error)
+	ListStartFunc      func(ctx context.Context, topics ...string) (kadm.ListedOffsets, error)
+	ListEndFunc        func(ctx context.Context, topics ...string) (kadm.ListedOffsets, error)
+	ListAfterMilliFunc func(ctx context.Context, millisecond int64, topics ...string) (kadm.ListedOffsets, error)
+	CommitOffsetsFunc  func(ctx context.Context, group string, commit kadm.Offsets) (kadm.OffsetResponses, error)
+}
+
+func (m *MockAdminClient) DescribeGroups(ctx context.Context, groups ...string) (kadm.DescribedGroups, error) {
+	if m.DescribeGroupsFunc != nil {
+		return m.DescribeGroupsFunc(ctx, groups...)
+	}
+	return kadm.DescribedGroups{}, nil
+}
+
+func (m *MockAdminClient) FetchOffsets(ctx context.Context, group string) (kadm.OffsetResponses, error) {
+	if m.FetchOffsetsFunc != nil {
+		return m.FetchOffsetsFunc(ctx, group)
+	}
+	return kadm.OffsetResponses{}, nil
+}
+
+func (m *MockAdminClient) ListStartOffsets(ctx context.Context, topics ...string) (kadm.ListedOffsets, error) {
+	if m.ListStartFunc != nil {
+		return m.ListStartFunc(ctx, topics...)
+	}
+	return kadm.ListedOffsets{}, nil
+}
+
+func (m *MockAdminClient) ListEndOffsets(ctx context.Context, topics ...string) (kadm.ListedOffsets, error) {
+	if m.ListEndFunc != nil {
+		return m.ListEndFunc(ctx, topics...)
+	}
+	return kadm.ListedOffsets{}, nil
+}
+
+func (m *MockAdminClient) ListOffsetsAfterMilli(ctx context.Context, millisecond int64, topics ...string) (kadm.ListedOffsets, error) {
+	if m.ListAfterMilliFunc != nil {
+		return m.ListAfterMilliFunc(ctx, millisecond, topics...)
+	}
+	return kadm.ListedOffsets{}, nil
+}
+
+func (m *MockAdminClient) CommitOffsets(ctx context.Context, group string, commit kadm.Offsets) (kadm.OffsetResponses, error) {
+	if m.CommitOffsetsFunc != nil {
+		return m.CommitOffsetsFunc(ctx, group, commit)
+	}
+	return kadm.OffsetResponses{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func offset(topic string, partition int32, at int64, metadata string) kadm.OffsetResponse {
+	return kadm.OffsetResponse{Offset: kadm.Offset{Topic: topic, Partition: partition, At: at, Metadata: metadata}}
+}
+
+// groupMock describes the payments group in the given state with committed offsets
+// on orders and refunds
+func groupMock(state string) *MockAdminClient {
+	return &MockAdminClient{
+		DescribeGroupsFunc: func(_ context.Context, groups ...string) (kadm.DescribedGroups, error) {
+			described := kadm.DescribedGroups{}
+			for _, g := range groups {
+				if g != "payments" {
+					described[g] = kadm.DescribedGroup{Group: g, State: "Dead"}
+					continue
+				}
+				described[g] = kadm.DescribedGroup{Group: g, State: state}
+			}
+			return described, nil
+		},
+		FetchOffsetsFunc: func(context.Context, string) (kadm.OffsetResponses, error) {
+			return kadm.OffsetResponses{
+				"refunds": {0: offset("refunds", 0, 7, "")},
+				"orders": {
+					1: offset("orders", 1, 42, "host-b"),
+					0: offset("orders", 0, 100, "host-a"),
+					2: offset("orders", 2, -1, ""),
+				},
+			}, nil
+		},
+	}
+}
+
+func newTestManager(adm AdminClient) *Manager {
+	m := NewManager(kafkaclient.Config{}, 10*time.Second, testLogger())
+	m.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	return m
+}
+
+func TestExport(t *testing.T) {
+	m := newTestManager(groupMock("Empty"))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.SetClock(clock.NewFake(now))
+	export, err := m.Export(context.Background(), "payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if export.Version != ExportVersion || export.Group != "payments" || export.State != "Empty" || !export.ExportedAt.Equal(now) {
+		t.Errorf("unexpected export header: %+v", export)
+	}
+	expected := []Offset{
+		{Topic: "orders", Partition: 0, Offset: 100, Metadata: "host-a"},
+		{Topic: "orders", Partition: 1, Offset: 42, Metadata: "host-b"},
+		{Topic: "refunds", Partition: 0, Offset: 7},
+	}
+	if len(export.Offsets) != len(expected) {
+		t.Fatalf("expected %d offsets, got %+v", len(expected), export.Offsets)
+	}
+	for i, o := range expected {
+		if export.Offsets[i] != o {
+			t.Errorf("offset %d: expected %+v, got %+v", i, o, export.Offsets[i])
+		}
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		group        string
+		adm          *MockAdminClient
+		expectedCode int
+	}{
+		{
+			name:         "unknown group",
+			group:        "missing",
+			adm:          groupMock("Empty"),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:  "group not found error",
+			group: "payments",
+			adm: &MockAdminClient{
+				DescribeGroupsFunc: func(context.Context, ...string) (kadm.DescribedGroups, error) {
+					return kadm.DescribedGroups{"payments": {Group: "payments", Err: kerr.GroupIDNotFound}}, nil
+				},
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:  "describe fails",
+			group: "payments",
+			adm: &MockAdminClient{
+				DescribeGroupsFunc: func(context.Context, ...string) (kadm.DescribedGroups, error) {
+					return nil, errors.New("connection lost")
+				},
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "partition fetch fails",
+			group: "payments",
+			adm: func() *MockAdminClient {
+				m := groupMock("Stable")
+				m.FetchOffsetsFunc = func(context.Context, string) (kadm.OffsetResponses, error) {
+					return kadm.OffsetResponses{"orders": {0: {Offset: kadm.Offset{Topic: "orders"}, Err: kerr.NotCoordinator}}}, nil
+				}
+				return m
+			}(),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(tt.adm)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/consumer-groups/"+tt.group+"/offsets/export", nil)
+			req = mux.SetURLVars(req, map[string]string{"group": tt.group})
+			w := httptest.NewRecorder()
+			m.ExportHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	m := newTestManager(groupMock("Empty"))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/consumer-groups/payments/offsets/export", nil)
+	req = mux.SetURLVars(req, map[string]string{"group": "payments"})
+	w := httptest.NewRecorder()
+	m.ExportHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var export Export
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if export.Group != "payments" || len(export.Offsets) != 3 {
+		t.Errorf("unexpected export: %+v", export)
+	}
+}
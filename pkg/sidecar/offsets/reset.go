@@ -0,0 +1,320 @@
+package offsets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// Reset strategies
+const (
+	StrategyEarliest  = "earliest"
+	StrategyLatest    = "latest"
+	StrategyTimestamp = "timestamp"
+	StrategySnapshot  = "snapshot"
+)
+
+// ResetRequest is the body of a consumer group offsets reset request
+type ResetRequest struct {
+	// Strategy is one of earliest, latest, timestamp or snapshot
+	Strategy string `json:"strategy"`
+	// Timestamp is the time to reset to with the timestamp strategy
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Topics limits the reset to these topics. Defaults to the topics the group has
+	// committed offsets for, or the snapshot's topics.
+	Topics []string `json:"topics,omitempty"`
+	// Snapshot is an export to restore with the snapshot strategy. It may come from
+	// another group or cluster.
+	Snapshot *Export `json:"snapshot,omitempty"`
+	// DryRun previews the changes without committing them
+	DryRun bool `json:"dryRun,omitempty"`
+	// RequestedBy identifies the operator for the log
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// Change is the planned offset change of a single partition
+type Change struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	// Current is the committed offset, unset when the group has none
+	Current *int64 `json:"current,omitempty"`
+	Target  int64  `json:"target"`
+	// Warning is set when the requested offset was outside the log and was clamped
+	Warning string `json:"warning,omitempty"`
+}
+
+// ResetResult describes a reset and whether it was applied
+type ResetResult struct {
+	Group    string   `json:"group"`
+	Strategy string   `json:"strategy"`
+	DryRun   bool     `json:"dryRun"`
+	Applied  bool     `json:"applied"`
+	Changes  []Change `json:"changes"`
+}
+
+// target is a requested offset before it is checked against the log
+type target struct {
+	offset   int64
+	metadata string
+}
+
+// Reset moves the committed offsets of a consumer group. The group must have no
+// active members; a group that does not exist yet is created by the commit.
+func (m *Manager) Reset(ctx context.Context, group string, r ResetRequest, actor string) (ResetResult, error) {
+	if err := validateReset(r); err != nil {
+		return ResetResult{}, err
+	}
+
+	adm, cleanup, err := m.clientFactory()
+	if err != nil {
+		return ResetResult{}, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	described, err := adm.DescribeGroups(ctx, group)
+	if err != nil {
+		return ResetResult{}, fmt.Errorf("failed to describe group: %w", err)
+	}
+	// Kafka describes unknown groups as Dead rather than failing
+	if dg, ok := described[group]; ok && !errors.Is(dg.Err, kerr.GroupIDNotFound) {
+		if dg.Err != nil {
+			return ResetResult{}, fmt.Errorf("failed to describe group %s: %w", group, dg.Err)
+		}
+		if dg.State != "Empty" && dg.State != "Dead" {
+			return ResetResult{}, cplnErrors.Conflictf("consumer group %s is %s; stop its consumers before resetting offsets", group, dg.State)
+		}
+	}
+
+	fetched, err := adm.FetchOffsets(ctx, group)
+	if err != nil {
+		return ResetResult{}, fmt.Errorf("failed to fetch offsets of group %s: %w", group, err)
+	}
+	if err := fetched.Error(); err != nil {
+		return ResetResult{}, fmt.Errorf("failed to fetch offsets of group %s: %w", group, err)
+	}
+
+	topics := scope(r, fetched)
+	if len(topics) == 0 {
+		return ResetResult{}, cplnErrors.Validationf("consumer group %s has no committed offsets; topics are required", group)
+	}
+
+	starts, err := listOffsets(ctx, topics, adm.ListStartOffsets)
+	if err != nil {
+		return ResetResult{}, err
+	}
+	ends, err := listOffsets(ctx, topics, adm.ListEndOffsets)
+	if err != nil {
+		return ResetResult{}, err
+	}
+
+	targets, err := resolveTargets(ctx, adm, r, topics, starts, ends)
+	if err != nil {
+		return ResetResult{}, err
+	}
+
+	result := ResetResult{Group: group, Strategy: r.Strategy, DryRun: r.DryRun, Changes: []Change{}}
+	commit := kadm.Offsets{}
+	for _, topic := range topics {
+		partitions := make([]int32, 0, len(targets[topic]))
+		for p := range targets[topic] {
+			partitions = append(partitions, p)
+		}
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+		for _, p := range partitions {
+			t := targets[topic][p]
+			change := Change{Topic: topic, Partition: p, Target: t.offset}
+			if current, ok := fetched.Lookup(topic, p); ok && current.At >= 0 {
+				at := current.At
+				change.Current = &at
+			}
+			start, end := starts[topic][p].Offset, ends[topic][p].Offset
+			switch {
+			case change.Target < start:
+				change.Warning = fmt.Sprintf("offset %d is before the log start; clamped to %d", change.Target, start)
+				change.Target = start
+			case change.Target > end:
+				change.Warning = fmt.Sprintf("offset %d is beyond the log end; clamped to %d", change.Target, end)
+				change.Target = end
+			}
+			result.Changes = append(result.Changes, change)
+			commit.Add(kadm.Offset{Topic: topic, Partition: p, At: change.Target, LeaderEpoch: -1, Metadata: t.metadata})
+		}
+	}
+
+	if r.DryRun {
+		return result, nil
+	}
+
+	committed, err := adm.CommitOffsets(ctx, group, commit)
+	if err != nil {
+		return ResetResult{}, fmt.Errorf("failed to commit offsets of group %s: %w", group, err)
+	}
+	if err := committed.Error(); err != nil {
+		return ResetResult{}, fmt.Errorf("failed to commit offsets of group %s: %w", group, err)
+	}
+	result.Applied = true
+
+	m.logger.Info("reset consumer group offsets", "group", group, "strategy", r.Strategy, "partitions", len(result.Changes), "actor", actor)
+	return result, nil
+}
+
+// resolveTargets resolves the requested offset of every partition in scope
+func resolveTargets(ctx context.Context, adm AdminClient, r ResetRequest, topics []string, starts, ends kadm.ListedOffsets) (map[string]map[int32]target, error) {
+	targets := map[string]map[int32]target{}
+	set := func(topic string, partition int32, t target) {
+		if targets[topic] == nil {
+			targets[topic] = map[int32]target{}
+		}
+		targets[topic][partition] = t
+	}
+
+	switch r.Strategy {
+	case StrategyEarliest:
+		starts.Each(func(o kadm.ListedOffset) { set(o.Topic, o.Partition, target{offset: o.Offset}) })
+	case StrategyLatest:
+		ends.Each(func(o kadm.ListedOffset) { set(o.Topic, o.Partition, target{offset: o.Offset}) })
+	case StrategyTimestamp:
+		after, err := listOffsets(ctx, topics, func(ctx context.Context, topics ...string) (kadm.ListedOffsets, error) {
+			return adm.ListOffsetsAfterMilli(ctx, r.Timestamp.UnixMilli(), topics...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		after.Each(func(o kadm.ListedOffset) {
+			// Partitions without records after the timestamp resume from the end
+			if o.Offset < 0 {
+				o.Offset = ends[o.Topic][o.Partition].Offset
+			}
+			set(o.Topic, o.Partition, target{offset: o.Offset})
+		})
+	case StrategySnapshot:
+		inScope := map[string]bool{}
+		for _, topic := range topics {
+			inScope[topic] = true
+		}
+		for _, o := range r.Snapshot.Offsets {
+			if !inScope[o.Topic] {
+				continue
+			}
+			if _, ok := starts[o.Topic][o.Partition]; !ok {
+				return nil, cplnErrors.Validationf("snapshot partition %s-%d does not exist", o.Topic, o.Partition)
+			}
+			set(o.Topic, o.Partition, target{offset: o.Offset, metadata: o.Metadata})
+		}
+	}
+	return targets, nil
+}
+
+// ResetHandler handles POST /admin/consumer-groups/{group}/offsets requests
+func (m *Manager) ResetHandler(w http.ResponseWriter, req *http.Request) {
+	body, err := web.ParseJsonRequestBody[ResetRequest](req)
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+		return
+	}
+
+	result, err := m.Reset(req.Context(), mux.Vars(req)["group"], body, actor(req, body.RequestedBy))
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to reset consumer group offsets", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, result)
+}
+
+func validateReset(r ResetRequest) error {
+	switch r.Strategy {
+	case StrategyEarliest, StrategyLatest:
+	case StrategyTimestamp:
+		if r.Timestamp == nil {
+			return cplnErrors.Validationf("timestamp is required with the %s strategy", StrategyTimestamp)
+		}
+	case StrategySnapshot:
+		if r.Snapshot == nil {
+			return cplnErrors.Validationf("snapshot is required with the %s strategy", StrategySnapshot)
+		}
+		if r.Snapshot.Version != ExportVersion {
+			return cplnErrors.Validationf("unsupported snapshot version %d (supported: %d)", r.Snapshot.Version, ExportVersion)
+		}
+	default:
+		return cplnErrors.Validationf("unsupported strategy: %q (supported: earliest, latest, timestamp, snapshot)", r.Strategy)
+	}
+	if r.Strategy != StrategyTimestamp && r.Timestamp != nil {
+		return cplnErrors.Validationf("timestamp is only supported with the %s strategy", StrategyTimestamp)
+	}
+	if r.Strategy != StrategySnapshot && r.Snapshot != nil {
+		return cplnErrors.Validationf("snapshot is only supported with the %s strategy", StrategySnapshot)
+	}
+	return nil
+}
+
+// scope returns the sorted topics to reset
+func scope(r ResetRequest, fetched kadm.OffsetResponses) []string {
+	seen := map[string]bool{}
+	switch {
+	case len(r.Topics) > 0:
+		for _, topic := range r.Topics {
+			seen[topic] = true
+		}
+	case r.Strategy == StrategySnapshot:
+		for _, o := range r.Snapshot.Offsets {
+			seen[o.Topic] = true
+		}
+	default:
+		fetched.Each(func(o kadm.OffsetResponse) {
+			if o.At >= 0 {
+				seen[o.Topic] = true
+			}
+		})
+	}
+
+	topics := make([]string, 0, len(seen))
+	for topic := range seen {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// listOffsets lists offsets of the topics, reporting unknown topics as validation errors
+func listOffsets(ctx context.Context, topics []string, list func(context.Context, ...string) (kadm.ListedOffsets, error)) (kadm.ListedOffsets, error) {
+	listed, err := list(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+	for _, topic := range topics {
+		partitions, ok := listed[topic]
+		if !ok {
+			return nil, cplnErrors.Validationf("unknown topic %q", topic)
+		}
+		for _, o := range partitions {
+			if errors.Is(o.Err, kerr.UnknownTopicOrPartition) {
+				return nil, cplnErrors.Validationf("unknown topic %q", topic)
+			}
+		}
+	}
+	if err := listed.Error(); err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+	return listed, nil
+}
+
+// actor identifies the requester, falling back to the remote address
+func actor(req *http.Request, requestedBy string) string {
+	if requestedBy != "" {
+		return requestedBy
+	}
+	return req.RemoteAddr
+}
@@ -0,0 +1,249 @@
+package offsets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// listed returns offsets of orders (partitions 0-2) and refunds (partition 0) from
+// the given offset for each topic
+func listed(orders, refunds int64) func(context.Context, ...string) (kadm.ListedOffsets, error) {
+	return func(_ context.Context, topics ...string) (kadm.ListedOffsets, error) {
+		out := kadm.ListedOffsets{}
+		for _, topic := range topics {
+			switch topic {
+			case "orders":
+				out[topic] = map[int32]kadm.ListedOffset{}
+				for p := int32(0); p < 3; p++ {
+					out[topic][p] = kadm.ListedOffset{Topic: topic, Partition: p, Offset: orders}
+				}
+			case "refunds":
+				out[topic] = map[int32]kadm.ListedOffset{0: {Topic: topic, Partition: 0, Offset: refunds}}
+			default:
+				out[topic] = map[int32]kadm.ListedOffset{0: {Topic: topic, Partition: 0, Offset: -1, Err: kerr.UnknownTopicOrPartition}}
+			}
+		}
+		return out, nil
+	}
+}
+
+// resetMock is groupMock with log offsets: orders spans 10-200 and refunds 0-50
+func resetMock(state string) (*MockAdminClient, *kadm.Offsets) {
+	m := groupMock(state)
+	m.ListStartFunc = listed(10, 0)
+	m.ListEndFunc = listed(200, 50)
+	committed := &kadm.Offsets{}
+	m.CommitOffsetsFunc = func(_ context.Context, _ string, commit kadm.Offsets) (kadm.OffsetResponses, error) {
+		*committed = commit
+		return kadm.OffsetResponses{}, nil
+	}
+	return m, committed
+}
+
+func TestReset(t *testing.T) {
+	m, committed := resetMock("Empty")
+	result, err := newTestManager(m).Reset(context.Background(), "payments", ResetRequest{Strategy: StrategyEarliest}, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Applied || len(result.Changes) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	first := result.Changes[0]
+	if first.Topic != "orders" || first.Partition != 0 || first.Current == nil || *first.Current != 100 || first.Target != 10 {
+		t.Errorf("unexpected first change: %+v", first)
+	}
+	// orders-2 has no committed offset
+	if result.Changes[2].Current != nil {
+		t.Errorf("expected no current offset for orders-2, got %d", *result.Changes[2].Current)
+	}
+	if o, ok := committed.Lookup("refunds", 0); !ok || o.At != 0 || o.LeaderEpoch != -1 {
+		t.Errorf("unexpected committed refunds-0: %+v", o)
+	}
+}
+
+func TestResetStrategies(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		req      ResetRequest
+		expected map[string]int64
+		warning  string
+	}{
+		{
+			name:     "latest limited to topics",
+			req:      ResetRequest{Strategy: StrategyLatest, Topics: []string{"refunds"}},
+			expected: map[string]int64{"refunds-0": 50},
+		},
+		{
+			name: "timestamp",
+			req:  ResetRequest{Strategy: StrategyTimestamp, Timestamp: &ts, Topics: []string{"refunds"}},
+			// No records after the timestamp resumes from the end
+			expected: map[string]int64{"refunds-0": 50},
+		},
+		{
+			name: "snapshot",
+			req: ResetRequest{Strategy: StrategySnapshot, Snapshot: &Export{
+				Version: ExportVersion,
+				Group:   "payments-dr",
+				Offsets: []Offset{
+					{Topic: "orders", Partition: 1, Offset: 150, Metadata: "host-b"},
+					{Topic: "refunds", Partition: 0, Offset: 999},
+				},
+			}},
+			expected: map[string]int64{"orders-1": 150, "refunds-0": 50},
+			warning:  "offset 999 is beyond the log end; clamped to 50",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := resetMock("Empty")
+			m.ListAfterMilliFunc = func(ctx context.Context, millisecond int64, topics ...string) (kadm.ListedOffsets, error) {
+				if millisecond != ts.UnixMilli() {
+					t.Errorf("expected timestamp %d, got %d", ts.UnixMilli(), millisecond)
+				}
+				return listed(-1, -1)(ctx, topics...)
+			}
+			tt.req.DryRun = true
+
+			result, err := newTestManager(m).Reset(context.Background(), "payments", tt.req, "alice")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Applied {
+				t.Error("expected a dry run not to be applied")
+			}
+			if len(result.Changes) != len(tt.expected) {
+				t.Fatalf("expected %d changes, got %+v", len(tt.expected), result.Changes)
+			}
+			var warning string
+			for _, c := range result.Changes {
+				key := fmt.Sprintf("%s-%d", c.Topic, c.Partition)
+				if tt.expected[key] != c.Target {
+					t.Errorf("%s: expected target %d, got %d", key, tt.expected[key], c.Target)
+				}
+				if c.Warning != "" {
+					warning = c.Warning
+				}
+			}
+			if warning != tt.warning {
+				t.Errorf("expected warning %q, got %q", tt.warning, warning)
+			}
+		})
+	}
+}
+
+func TestResetDryRunDoesNotCommit(t *testing.T) {
+	m, _ := resetMock("Empty")
+	m.CommitOffsetsFunc = func(context.Context, string, kadm.Offsets) (kadm.OffsetResponses, error) {
+		t.Fatal("dry run committed offsets")
+		return nil, nil
+	}
+
+	if _, err := newTestManager(m).Reset(context.Background(), "payments", ResetRequest{Strategy: StrategyLatest, DryRun: true}, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestResetHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "active group",
+			state:        "Stable",
+			body:         `{"strategy":"earliest"}`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "unsupported strategy",
+			state:        "Empty",
+			body:         `{"strategy":"oldest"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "timestamp missing",
+			state:        "Empty",
+			body:         `{"strategy":"timestamp"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "snapshot version",
+			state:        "Empty",
+			body:         `{"strategy":"snapshot","snapshot":{"version":99,"offsets":[]}}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown topic",
+			state:        "Empty",
+			body:         `{"strategy":"latest","topics":["missing"]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown snapshot partition",
+			state:        "Empty",
+			body:         `{"strategy":"snapshot","snapshot":{"version":1,"offsets":[{"topic":"refunds","partition":5,"offset":1}]}}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "new group without topics",
+			state:        "Dead",
+			body:         `{"strategy":"latest"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := resetMock(tt.state)
+			if tt.state == "Dead" {
+				m.FetchOffsetsFunc = nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/consumer-groups/payments/offsets", bytes.NewBufferString(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"group": "payments"})
+			w := httptest.NewRecorder()
+			newTestManager(m).ResetHandler(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResetHandler(t *testing.T) {
+	m, _ := resetMock("Dead")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/consumer-groups/payments/offsets",
+		bytes.NewBufferString(`{"strategy":"earliest","topics":["refunds"],"requestedBy":"alice"}`))
+	req = mux.SetURLVars(req, map[string]string{"group": "payments"})
+	w := httptest.NewRecorder()
+	newTestManager(m).ResetHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result ResetResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if !result.Applied || len(result.Changes) != 1 || result.Changes[0].Target != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
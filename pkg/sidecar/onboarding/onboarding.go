@@ -0,0 +1,255 @@
+package onboarding
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// CheckName identifies the onboarding loop in the freshness tracker
+const CheckName = "onboarding"
+
+// State is the state of the onboarding workflow
+type State string
+
+const (
+	StateWaiting   State = "waiting"
+	StateNotNeeded State = "not-needed"
+	StateMoving    State = "moving"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+)
+
+// AdminClient defines the Kafka admin operations needed for onboarding.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	reassign.AdminClient
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the onboarding workflow
+type Options struct {
+	// CheckInterval is how often the broker is checked while waiting to register
+	CheckInterval time.Duration
+	// BatchSize is the number of partitions moved per reassignment batch
+	BatchSize int
+	// PollInterval is how often in-flight reassignments are polled
+	PollInterval time.Duration
+	// Timeout bounds each metadata request
+	Timeout time.Duration
+	// IncludeInternal allows moving internal topic partitions
+	IncludeInternal bool
+}
+
+// Status is the observable progress of the onboarding workflow
+type Status struct {
+	State          State      `json:"state"`
+	BrokerID       int32      `json:"brokerId"`
+	Message        string     `json:"message,omitempty"`
+	PlannedMoves   int        `json:"plannedMoves"`
+	CompletedMoves int        `json:"completedMoves"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Onboarder moves a proportional share of partitions onto a freshly added broker
+type Onboarder struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewOnboarder creates a new onboarding workflow for the local broker
+func NewOnboarder(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Onboarder {
+	o := &Onboarder{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		status:      Status{State: StateWaiting, BrokerID: brokerID},
+	}
+	// Set default client factory
+	o.clientFactory = o.defaultClientFactory
+	return o
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (o *Onboarder) SetClientFactory(factory ClientFactory) {
+	o.clientFactory = factory
+}
+
+// SetTracker records every onboarding attempt with the freshness tracker
+func (o *Onboarder) SetTracker(tracker *freshness.Tracker) {
+	o.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (o *Onboarder) SetClock(clk clock.Clock) {
+	o.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (o *Onboarder) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(o.kafkaConfig)
+}
+
+// Status returns a snapshot of the workflow status
+func (o *Onboarder) Status() Status {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.status
+}
+
+// Run waits for the broker to register and then onboards it once. It returns when
+// onboarding has finished, was not needed, failed, or the context is cancelled.
+func (o *Onboarder) Run(ctx context.Context) {
+	ticker := o.clock.NewTicker(o.opts.CheckInterval)
+	defer ticker.Stop()
+	o.tracker.Register(CheckName)
+
+	for {
+		if o.Step(ctx) {
+			o.tracker.Finish(CheckName)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step performs one onboarding attempt and reports whether the workflow is finished
+func (o *Onboarder) Step(ctx context.Context) bool {
+	adm, cleanup, err := o.clientFactory()
+	if err != nil {
+		o.logger.Warn("onboarding: failed to create kafka client", "error", err)
+		o.tracker.Record(CheckName, err)
+		return false
+	}
+	defer cleanup()
+
+	mdCtx, cancel := context.WithTimeout(ctx, o.opts.Timeout)
+	md, err := adm.Metadata(mdCtx)
+	cancel()
+	if err != nil {
+		o.logger.Warn("onboarding: failed to fetch metadata", "error", err)
+		o.tracker.Record(CheckName, err)
+		return false
+	}
+	o.tracker.Record(CheckName, nil)
+
+	counts := reassign.ReplicaCounts(md)
+	hosted, registered := counts[o.brokerID]
+	if !registered {
+		o.setStatus(StateWaiting, "broker not yet registered in cluster metadata")
+		return false
+	}
+	if hosted > 0 {
+		o.setStatus(StateNotNeeded, "broker already hosts partitions")
+		return true
+	}
+
+	moves := reassign.PlanOnboarding(md, o.brokerID, reassign.PlanOptions{IncludeInternal: o.opts.IncludeInternal})
+	if len(moves) == 0 {
+		o.setStatus(StateNotNeeded, "no partitions to move onto this broker")
+		return true
+	}
+
+	o.start(len(moves))
+	o.logger.Info("onboarding: moving partitions onto new broker",
+		"brokerId", o.brokerID,
+		"moves", len(moves))
+
+	for start := 0; start < len(moves); start += o.opts.BatchSize {
+		end := start + o.opts.BatchSize
+		if end > len(moves) {
+			end = len(moves)
+		}
+		batch := moves[start:end]
+
+		if err := reassign.Execute(ctx, adm, batch); err != nil {
+			o.fail(err)
+			return true
+		}
+		if err := reassign.WaitForCompletion(ctx, o.clock, adm, batch, o.opts.PollInterval); err != nil {
+			o.fail(err)
+			return true
+		}
+		o.progress(len(batch))
+		o.tracker.Record(CheckName, nil)
+	}
+
+	o.finish()
+	o.logger.Info("onboarding: completed", "brokerId", o.brokerID, "moves", len(moves))
+	return true
+}
+
+func (o *Onboarder) setStatus(state State, message string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.status.State = state
+	o.status.Message = message
+}
+
+func (o *Onboarder) start(planned int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.clock.Now()
+	o.status.State = StateMoving
+	o.status.Message = ""
+	o.status.PlannedMoves = planned
+	o.status.StartedAt = &now
+}
+
+func (o *Onboarder) progress(completed int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.status.CompletedMoves += completed
+}
+
+func (o *Onboarder) finish() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.clock.Now()
+	o.status.State = StateCompleted
+	o.status.FinishedAt = &now
+}
+
+func (o *Onboarder) fail(err error) {
+	o.logger.Error("onboarding: failed", "brokerId", o.brokerID, "error", err)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.clock.Now()
+	o.status.State = StateFailed
+	o.status.Message = err.Error()
+	o.status.FinishedAt = &now
+}
+
+// StatusHandler handles GET /admin/onboarding requests
+func (o *Onboarder) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, o.Status())
+}
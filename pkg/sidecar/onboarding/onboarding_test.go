@@ -0,0 +1,202 @@
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func testOptions() Options {
+	return Options{
+		CheckInterval: time.Millisecond,
+		BatchSize:     2,
+		PollInterval:  time.Millisecond,
+		Timeout:       time.Second,
+	}
+}
+
+// clusterMetadata returns a 3-broker cluster where brokers 0 and 1 host 6 partitions at RF=2
+func clusterMetadata(brokers ...int32) kadm.Metadata {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{}}
+	for _, b := range brokers {
+		md.Brokers = append(md.Brokers, kadm.BrokerDetail{NodeID: b})
+	}
+	partitions := kadm.PartitionDetails{}
+	for i := int32(0); i < 6; i++ {
+		partitions[i] = kadm.PartitionDetail{Topic: "orders", Partition: i, Replicas: []int32{0, 1}, ISR: []int32{0, 1}}
+	}
+	md.Topics["orders"] = kadm.TopicDetail{Topic: "orders", Partitions: partitions}
+	return md
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name          string
+		brokerID      int32
+		metadata      kadm.Metadata
+		metadataErr   error
+		alterErr      error
+		expectDone    bool
+		expectState   State
+		expectBatches int
+	}{
+		{
+			name:        "metadata error keeps waiting",
+			brokerID:    2,
+			metadataErr: errors.New("connection refused"),
+			expectDone:  false,
+			expectState: StateWaiting,
+		},
+		{
+			name:        "broker not registered yet",
+			brokerID:    2,
+			metadata:    clusterMetadata(0, 1),
+			expectDone:  false,
+			expectState: StateWaiting,
+		},
+		{
+			name:        "broker already hosts partitions",
+			brokerID:    0,
+			metadata:    clusterMetadata(0, 1, 2),
+			expectDone:  true,
+			expectState: StateNotNeeded,
+		},
+		{
+			name:          "new broker is onboarded in batches",
+			brokerID:      2,
+			metadata:      clusterMetadata(0, 1, 2),
+			expectDone:    true,
+			expectState:   StateCompleted,
+			expectBatches: 2,
+		},
+		{
+			name:          "reassignment rejected",
+			brokerID:      2,
+			metadata:      clusterMetadata(0, 1, 2),
+			alterErr:      errors.New("not controller"),
+			expectDone:    true,
+			expectState:   StateFailed,
+			expectBatches: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOnboarder(tt.brokerID, kafkaclient.Config{}, testOptions(), testLogger())
+
+			batches := 0
+			o.SetClientFactory(func() (AdminClient, func(), error) {
+				return &MockAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return tt.metadata, tt.metadataErr
+					},
+					AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+						batches++
+						return kadm.AlterPartitionAssignmentsResponses{}, tt.alterErr
+					},
+				}, func() {}, nil
+			})
+
+			done := o.Step(context.Background())
+			if done != tt.expectDone {
+				t.Errorf("expected done=%v, got %v", tt.expectDone, done)
+			}
+
+			status := o.Status()
+			if status.State != tt.expectState {
+				t.Errorf("expected state %q, got %q (%s)", tt.expectState, status.State, status.Message)
+			}
+			if batches != tt.expectBatches {
+				t.Errorf("expected %d batches, got %d", tt.expectBatches, batches)
+			}
+			if tt.expectState == StateCompleted && status.CompletedMoves != status.PlannedMoves {
+				t.Errorf("expected all %d moves completed, got %d", status.PlannedMoves, status.CompletedMoves)
+			}
+		})
+	}
+}
+
+func TestRunStopsWhenFinished(t *testing.T) {
+	o := NewOnboarder(0, kafkaclient.Config{}, testOptions(), testLogger())
+	o.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return clusterMetadata(0, 1), nil
+			},
+		}, func() {}, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		o.Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after onboarding was not needed")
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	o := NewOnboarder(4, kafkaclient.Config{}, testOptions(), testLogger())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/onboarding", nil)
+	w := httptest.NewRecorder()
+	o.StatusHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var status Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if status.State != StateWaiting || status.BrokerID != 4 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
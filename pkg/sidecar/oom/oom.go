@@ -0,0 +1,166 @@
+package oom
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+)
+
+// CheckName identifies the OOM prediction loop in the freshness tracker
+const CheckName = "oom_prediction"
+
+// minSamples is how many samples the trend needs before it predicts anything,
+// so a couple of noisy samples after startup do not raise an alert
+const minSamples = 5
+
+// Options configures the OOM predictor
+type Options struct {
+	// Interval is how often the working set is sampled
+	Interval time.Duration
+	// Window is how far back samples are kept for the trend
+	Window time.Duration
+}
+
+// sample is one reading of the OOM ratio (working set / limit)
+type sample struct {
+	at    time.Time
+	ratio float64
+}
+
+// Predictor samples the container's OOM ratio and fits a linear trend to the
+// samples in the window, to estimate when the working set will reach the
+// memory limit at the current growth rate
+type Predictor struct {
+	reader  metrics.CgroupReader
+	opts    Options
+	logger  *slog.Logger
+	tracker *freshness.Tracker
+	clock   clock.Clock
+
+	mu      sync.RWMutex
+	samples []sample
+	// predicted is the seconds until the ratio reaches 1.0, +Inf when it is
+	// not growing; ok is false until the window holds enough samples
+	predicted float64
+	ok        bool
+}
+
+// NewPredictor creates a new OOM predictor
+func NewPredictor(reader metrics.CgroupReader, opts Options, logger *slog.Logger) *Predictor {
+	return &Predictor{
+		reader: reader,
+		opts:   opts,
+		logger: logger,
+		clock:  clock.Real,
+	}
+}
+
+// SetTracker records every sample with the freshness tracker
+func (p *Predictor) SetTracker(tracker *freshness.Tracker) {
+	p.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (p *Predictor) SetClock(clk clock.Clock) {
+	p.clock = clk
+}
+
+// PredictedSeconds returns the estimated seconds until the OOM ratio crosses
+// 1.0, +Inf when it is flat or shrinking, and false while there are too few
+// samples to tell
+func (p *Predictor) PredictedSeconds() (float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.predicted, p.ok
+}
+
+// Run samples every Interval until the context is cancelled
+func (p *Predictor) Run(ctx context.Context) {
+	ticker := p.clock.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	p.tracker.Register(CheckName)
+
+	for {
+		p.tracker.Record(CheckName, p.Step())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step samples the OOM ratio once and updates the prediction. A container
+// without a memory limit cannot be OOM killed by one, so it predicts nothing.
+func (p *Predictor) Step() error {
+	m, err := p.reader.ReadMemoryMetrics()
+	if err != nil {
+		return fmt.Errorf("failed to read memory metrics: %w", err)
+	}
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if m.Limit == 0 {
+		p.samples = nil
+		p.ok = false
+		return nil
+	}
+
+	p.samples = append(p.samples, sample{at: now, ratio: m.OOMRatio})
+	cutoff := now.Add(-p.opts.Window)
+	first := 0
+	for first < len(p.samples) && p.samples[first].at.Before(cutoff) {
+		first++
+	}
+	p.samples = append(p.samples[:0], p.samples[first:]...)
+
+	p.predicted, p.ok = predict(p.samples)
+	return nil
+}
+
+// predict fits a least-squares line to the samples and returns the seconds
+// until it crosses 1.0, measured from the last sample
+func predict(samples []sample) (float64, bool) {
+	if len(samples) < minSamples {
+		return 0, false
+	}
+	origin := samples[0].at
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.at.Sub(origin).Seconds()
+		sumY += s.ratio
+	}
+	n := float64(len(samples))
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for _, s := range samples {
+		dx := s.at.Sub(origin).Seconds() - meanX
+		covariance += dx * (s.ratio - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	slope := covariance / variance
+
+	last := samples[len(samples)-1].at.Sub(origin).Seconds()
+	current := meanY + slope*(last-meanX)
+	switch {
+	case current >= 1:
+		return 0, true
+	case slope <= 0:
+		return math.Inf(1), true
+	default:
+		return (1 - current) / slope, true
+	}
+}
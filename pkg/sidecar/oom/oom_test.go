@@ -0,0 +1,121 @@
+package oom
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+)
+
+// ratioReader reads the next of a sequence of OOM ratios
+type ratioReader struct {
+	ratios []float64
+	limit  uint64
+	err    error
+}
+
+func (r *ratioReader) ReadMemoryMetrics() (*metrics.MemoryMetrics, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	ratio := r.ratios[0]
+	if len(r.ratios) > 1 {
+		r.ratios = r.ratios[1:]
+	}
+	return &metrics.MemoryMetrics{Limit: r.limit, OOMRatio: ratio}, nil
+}
+
+func newTestPredictor(reader metrics.CgroupReader) (*Predictor, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	p := NewPredictor(reader, Options{Interval: time.Minute, Window: 10 * time.Minute}, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	p.SetClock(clk)
+	return p, clk
+}
+
+// steps samples n times, a minute apart
+func steps(t *testing.T, p *Predictor, clk *clock.Fake, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := p.Step(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		clk.Advance(time.Minute)
+	}
+}
+
+func TestPredictGrowing(t *testing.T) {
+	// One percent of the limit per minute, reaching 0.6 at the fifth sample
+	p, clk := newTestPredictor(&ratioReader{ratios: []float64{0.56, 0.57, 0.58, 0.59, 0.60}, limit: 1 << 30})
+
+	steps(t, p, clk, minSamples-1)
+	if _, ok := p.PredictedSeconds(); ok {
+		t.Fatal("expected no prediction before enough samples")
+	}
+
+	steps(t, p, clk, 1)
+	seconds, ok := p.PredictedSeconds()
+	if !ok {
+		t.Fatal("expected a prediction")
+	}
+	// 0.4 left at 0.01 per minute
+	if want := 40 * time.Minute.Seconds(); math.Abs(seconds-want) > 1 {
+		t.Errorf("expected %.0fs, got %.0fs", want, seconds)
+	}
+}
+
+func TestPredictNotGrowing(t *testing.T) {
+	p, clk := newTestPredictor(&ratioReader{ratios: []float64{0.7, 0.6, 0.7, 0.6, 0.5}, limit: 1 << 30})
+
+	steps(t, p, clk, minSamples)
+	if seconds, ok := p.PredictedSeconds(); !ok || !math.IsInf(seconds, 1) {
+		t.Errorf("expected +Inf for a shrinking working set, got %v %v", seconds, ok)
+	}
+}
+
+func TestPredictAtLimit(t *testing.T) {
+	p, clk := newTestPredictor(&ratioReader{ratios: []float64{0.96, 0.98, 1.0, 1.02, 1.04}, limit: 1 << 30})
+
+	steps(t, p, clk, minSamples)
+	if seconds, ok := p.PredictedSeconds(); !ok || seconds != 0 {
+		t.Errorf("expected 0 once the limit is crossed, got %v %v", seconds, ok)
+	}
+}
+
+func TestPredictWindow(t *testing.T) {
+	// Growth that stopped ages out of the window
+	ratios := []float64{0.1, 0.2, 0.3, 0.4, 0.5}
+	for i := 0; i < 11; i++ {
+		ratios = append(ratios, 0.5)
+	}
+	p, clk := newTestPredictor(&ratioReader{ratios: ratios, limit: 1 << 30})
+
+	steps(t, p, clk, minSamples)
+	if seconds, _ := p.PredictedSeconds(); math.IsInf(seconds, 1) {
+		t.Fatal("expected growth to be predicted")
+	}
+	steps(t, p, clk, 11)
+	if seconds, ok := p.PredictedSeconds(); !ok || !math.IsInf(seconds, 1) {
+		t.Errorf("expected +Inf once the growth left the window, got %v %v", seconds, ok)
+	}
+}
+
+func TestPredictWithoutLimit(t *testing.T) {
+	p, clk := newTestPredictor(&ratioReader{ratios: []float64{0}})
+
+	steps(t, p, clk, minSamples)
+	if _, ok := p.PredictedSeconds(); ok {
+		t.Error("expected no prediction without a memory limit")
+	}
+}
+
+func TestStepReadError(t *testing.T) {
+	p, _ := newTestPredictor(&ratioReader{err: errors.New("no such file")})
+	if err := p.Step(); err == nil {
+		t.Error("expected the read error")
+	}
+}
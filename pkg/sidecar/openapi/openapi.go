@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// Version is the OpenAPI specification version of the generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI 3 document, limited to what the sidecar describes
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Servers []Server            `json:"servers,omitempty"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+// Info is the metadata of the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL the paths are relative to
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations of one path
+type PathItem map[string]*Operation
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path parameter of an operation
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   Schema `json:"schema"`
+}
+
+// Schema is the type of a parameter
+type Schema struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Response is a possible response of an operation
+type Response struct {
+	Description string `json:"description"`
+}
+
+// FromRouter generates a document from the routes registered on router. Only
+// routes with both a path template and methods are described; prefix routes
+// such as /debug/pprof/ are left out. Summaries are keyed by "METHOD path"
+// with the path as registered, e.g. "GET /admin/decommission/{brokerId:[0-9]+}".
+func FromRouter(router *mux.Router, info Info, servers []Server, summaries map[string]string) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   map[string]PathItem{},
+	}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		path, params, err := parseTemplate(template)
+		if err != nil {
+			return err
+		}
+		item := doc.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, method := range methods {
+			item[strings.ToLower(method)] = &Operation{
+				OperationID: operationID(method, path),
+				Summary:     summaries[method+" "+template],
+				Tags:        tags(path),
+				Parameters:  params,
+				Responses:   responses(),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parseTemplate turns a mux path template into an OpenAPI path and its
+// parameters, keeping the variable patterns
+func parseTemplate(template string) (string, []Parameter, error) {
+	var path strings.Builder
+	var params []Parameter
+	for i := 0; i < len(template); i++ {
+		if template[i] != '{' {
+			path.WriteByte(template[i])
+			continue
+		}
+		// Patterns may hold braces of their own, e.g. {id:[0-9]{3}}
+		depth, end := 0, -1
+		for j := i; j < len(template) && end < 0; j++ {
+			switch template[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return "", nil, fmt.Errorf("unbalanced braces in route %s", template)
+		}
+		name, pattern, _ := strings.Cut(template[i+1:end], ":")
+		param := Parameter{Name: name, In: "path", Required: true, Schema: Schema{Type: "string"}}
+		if pattern != "" {
+			param.Schema.Pattern = "^" + pattern + "$"
+		}
+		params = append(params, param)
+		path.WriteString("{" + name + "}")
+		i = end
+	}
+	return path.String(), params, nil
+}
+
+// operationID derives a stable camel-case ID from the method and path, e.g.
+// getAdminDecommissionBrokerIdPlan
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tags groups operations by the first path segment, or the second for /admin
+func tags(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if segments[0] == "admin" && len(segments) > 1 {
+		return []string{segments[1]}
+	}
+	return []string{segments[0]}
+}
+
+// responses describes the outcomes every sidecar handler shares. Errors are
+// JSON objects with an error message.
+func responses() map[string]Response {
+	return map[string]Response{
+		"200":     {Description: "Success"},
+		"default": {Description: "Error"},
+	}
+}
+
+// Operations returns "METHOD path" for every operation, sorted
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
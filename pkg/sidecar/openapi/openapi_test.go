@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func noop(http.ResponseWriter, *http.Request) {}
+
+func TestFromRouter(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/health/live", noop).Methods("GET")
+	router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}/plan", noop).Methods("GET")
+	router.HandleFunc("/admin/decommission/{brokerId:[0-9]+}", noop).Methods("POST")
+	router.PathPrefix("/debug/pprof/").HandlerFunc(noop)
+
+	doc, err := FromRouter(router, Info{Title: "test", Version: "dev"}, []Server{{URL: "/v1"}}, map[string]string{
+		"GET /health/live": "Liveness",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"GET /admin/decommission/{brokerId}/plan",
+		"GET /health/live",
+		"POST /admin/decommission/{brokerId}",
+	}
+	if got := doc.Operations(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected operations %v, got %v", want, got)
+	}
+
+	live := doc.Paths["/health/live"]["get"]
+	if live.Summary != "Liveness" || live.OperationID != "getHealthLive" || live.Tags[0] != "health" {
+		t.Errorf("unexpected liveness operation %+v", live)
+	}
+	plan := doc.Paths["/admin/decommission/{brokerId}/plan"]["get"]
+	if plan.OperationID != "getAdminDecommissionBrokerIdPlan" || plan.Tags[0] != "decommission" {
+		t.Errorf("unexpected plan operation %+v", plan)
+	}
+	wantParams := []Parameter{{Name: "brokerId", In: "path", Required: true, Schema: Schema{Type: "string", Pattern: "^[0-9]+$"}}}
+	if !reflect.DeepEqual(plan.Parameters, wantParams) {
+		t.Errorf("expected parameters %+v, got %+v", wantParams, plan.Parameters)
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		params   []string
+		wantErr  bool
+	}{
+		{template: "/status", path: "/status"},
+		{template: "/topics/{name}/replication-factor", path: "/topics/{name}/replication-factor", params: []string{"name"}},
+		{template: "/a/{id:[0-9]{3}}/b/{x}", path: "/a/{id}/b/{x}", params: []string{"id", "x"}},
+		{template: "/a/{id", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			path, params, err := parseTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if path != tt.path {
+				t.Errorf("expected path %s, got %s", tt.path, path)
+			}
+			var names []string
+			for _, p := range params {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.params) {
+				t.Errorf("expected params %v, got %v", tt.params, names)
+			}
+		})
+	}
+}
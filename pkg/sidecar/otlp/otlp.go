@@ -0,0 +1,203 @@
+// Package otlp pushes the sidecar's Prometheus metrics and traces to an
+// OpenTelemetry collector over OTLP, for environments that collect everything
+// through one rather than scraping every replica.
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	prometheusbridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the OTLP export in the freshness tracker
+const CheckName = "otlp_export"
+
+// ServiceName is the service.name resource attribute of the exported metrics
+const ServiceName = "kafka-sidecar"
+
+// shutdownTimeout bounds the final export when the sidecar stops
+const shutdownTimeout = 5 * time.Second
+
+// Protocol is the OTLP transport
+type Protocol string
+
+const (
+	// ProtocolGRPC exports over gRPC, by default to port 4317
+	ProtocolGRPC Protocol = "grpc"
+	// ProtocolHTTP exports protobuf over HTTP, by default to port 4318
+	ProtocolHTTP Protocol = "http"
+)
+
+// ParseProtocol parses an OTLP transport name
+func ParseProtocol(s string) (Protocol, error) {
+	switch p := Protocol(strings.ToLower(strings.TrimSpace(s))); p {
+	case ProtocolGRPC, ProtocolHTTP:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown OTLP protocol %q (valid: %s, %s)", s, ProtocolGRPC, ProtocolHTTP)
+	}
+}
+
+// ParseHeaders parses a comma-separated list of name=value pairs, e.g.
+// "authorization=Bearer abc,x-tenant=kafka". A value may contain "=".
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q (expected name=value)", pair)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// Options configures the OTLP exporter
+type Options struct {
+	// Endpoint is the collector as host:port, or a URL whose scheme selects
+	// TLS and, for HTTP, whose path replaces /v1/metrics
+	Endpoint string
+	Protocol Protocol
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+	// Insecure disables TLS for a host:port endpoint
+	Insecure bool
+	// Interval is how often the metrics are exported
+	Interval time.Duration
+	// Timeout bounds a single export
+	Timeout time.Duration
+	// Attributes are added to the exported resource; empty values are skipped
+	Attributes map[string]string
+}
+
+// Exporter periodically gathers the Prometheus metrics and pushes them to
+// the collector. Series keep their Prometheus names and labels; the labels
+// become data point attributes.
+type Exporter struct {
+	gatherer prometheus.Gatherer
+	opts     Options
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+}
+
+// NewExporter creates an exporter of everything the gatherer returns
+func NewExporter(gatherer prometheus.Gatherer, opts Options, logger *slog.Logger) *Exporter {
+	return &Exporter{
+		gatherer: gatherer,
+		opts:     opts,
+		logger:   logger,
+	}
+}
+
+// SetTracker records every export with the freshness tracker
+func (e *Exporter) SetTracker(tracker *freshness.Tracker) {
+	e.tracker = tracker
+}
+
+// Run exports every Interval until the context is cancelled, then flushes a
+// final export
+func (e *Exporter) Run(ctx context.Context) {
+	e.tracker.Register(CheckName)
+
+	exporter, err := e.newExporter(ctx)
+	if err != nil {
+		e.tracker.Record(CheckName, err)
+		e.logger.Error("otlp: failed to create exporter", "endpoint", e.opts.Endpoint, "error", err)
+		return
+	}
+	reader := sdkmetric.NewPeriodicReader(&trackedExporter{Exporter: exporter, tracker: e.tracker, logger: e.logger},
+		sdkmetric.WithInterval(e.opts.Interval),
+		sdkmetric.WithTimeout(e.opts.Timeout),
+		sdkmetric.WithProducer(prometheusbridge.NewMetricProducer(prometheusbridge.WithGatherer(e.gatherer))))
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(newResource(e.opts.Attributes)))
+	e.logger.Info("otlp: exporting metrics", "endpoint", e.opts.Endpoint, "protocol", e.opts.Protocol, "interval", e.opts.Interval)
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+	if err := provider.Shutdown(shutdownCtx); err != nil {
+		e.logger.Warn("otlp: failed to flush metrics on shutdown", "error", err)
+	}
+}
+
+// newExporter creates the OTLP client for the configured protocol
+func (e *Exporter) newExporter(ctx context.Context) (sdkmetric.Exporter, error) {
+	isURL := strings.Contains(e.opts.Endpoint, "://")
+	switch e.opts.Protocol {
+	case ProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(e.opts.Headers), otlpmetrichttp.WithTimeout(e.opts.Timeout)}
+		if isURL {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(e.opts.Endpoint))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(e.opts.Endpoint))
+			if e.opts.Insecure {
+				opts = append(opts, otlpmetrichttp.WithInsecure())
+			}
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	case ProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(e.opts.Headers), otlpmetricgrpc.WithTimeout(e.opts.Timeout)}
+		if isURL {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(e.opts.Endpoint))
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(e.opts.Endpoint))
+			if e.opts.Insecure {
+				opts = append(opts, otlpmetricgrpc.WithInsecure())
+			}
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", e.opts.Protocol)
+	}
+}
+
+// newResource identifies the sidecar the metrics and traces come from; empty
+// attribute values are skipped
+func newResource(attributes map[string]string) *resource.Resource {
+	attrs := []attribute.KeyValue{attribute.String("service.name", ServiceName)}
+	for name, value := range attributes {
+		if value != "" {
+			attrs = append(attrs, attribute.String(name, value))
+		}
+	}
+	return resource.NewSchemaless(attrs...)
+}
+
+// trackedExporter records the outcome of every export, which the periodic
+// reader would otherwise only report to the global OpenTelemetry error handler
+type trackedExporter struct {
+	sdkmetric.Exporter
+	tracker *freshness.Tracker
+	logger  *slog.Logger
+}
+
+// Export implements sdkmetric.Exporter
+func (t *trackedExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := t.Exporter.Export(ctx, rm)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.logger.Warn("otlp: failed to export metrics", "error", err)
+	}
+	t.tracker.Record(CheckName, err)
+	return err
+}
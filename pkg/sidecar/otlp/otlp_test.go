@@ -0,0 +1,140 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders(" authorization=Bearer a=b= , x-tenant=kafka,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(headers) != 2 || headers["authorization"] != "Bearer a=b=" || headers["x-tenant"] != "kafka" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	for _, invalid := range []string{"authorization", "=value"} {
+		if _, err := ParseHeaders(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	if p, err := ParseProtocol(" HTTP "); err != nil || p != ProtocolHTTP {
+		t.Errorf("expected http, got %q, %v", p, err)
+	}
+	if _, err := ParseProtocol("thrift"); err == nil {
+		t.Error("expected an error for an unknown protocol")
+	}
+}
+
+func TestExporterPushesGatheredMetrics(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*collectormetrics.ExportMetricsServiceRequest
+		tenants  []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req collectormetrics.ExportMetricsServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid export request: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, &req)
+		tenants = append(tenants, r.Header.Get("x-tenant"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		out, _ := proto.Marshal(&collectormetrics.ExportMetricsServiceResponse{})
+		_, _ = w.Write(out)
+	}))
+	defer collector.Close()
+
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kafka_memory_oom_ratio", Help: "test"})
+	gauge.Set(0.5)
+	registry.MustRegister(gauge)
+
+	tracker := freshness.NewTracker(time.Minute, testLogger())
+	e := NewExporter(registry, Options{
+		Endpoint:   strings.TrimPrefix(collector.URL, "http://"),
+		Protocol:   ProtocolHTTP,
+		Headers:    map[string]string{"x-tenant": "kafka"},
+		Insecure:   true,
+		Interval:   time.Hour,
+		Timeout:    time.Second,
+		Attributes: map[string]string{"broker_id": "2", "location": ""},
+	}, testLogger())
+	e.SetTracker(tracker)
+
+	// Cancelling flushes a final export
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("expected one export, got %d", len(requests))
+	}
+	if tenants[0] != "kafka" {
+		t.Errorf("expected the configured headers, got %q", tenants[0])
+	}
+	rm := requests[0].GetResourceMetrics()
+	if len(rm) != 1 {
+		t.Fatalf("expected one resource, got %d", len(rm))
+	}
+	attrs := map[string]string{}
+	for _, kv := range rm[0].GetResource().GetAttributes() {
+		attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	if attrs["service.name"] != ServiceName || attrs["broker_id"] != "2" {
+		t.Errorf("unexpected resource attributes: %v", attrs)
+	}
+	if _, ok := attrs["location"]; ok {
+		t.Error("expected the empty location to be skipped")
+	}
+
+	var value float64
+	found := false
+	for _, sm := range rm[0].GetScopeMetrics() {
+		for _, m := range sm.GetMetrics() {
+			if m.GetName() == "kafka_memory_oom_ratio" {
+				found = true
+				value = m.GetGauge().GetDataPoints()[0].GetAsDouble()
+			}
+		}
+	}
+	if !found || value != 0.5 {
+		t.Errorf("expected kafka_memory_oom_ratio 0.5 to be exported, got %v %v", found, value)
+	}
+
+	statuses := tracker.Statuses()
+	if len(statuses) != 1 || statuses[0].Name != CheckName || statuses[0].LastSuccess == nil {
+		t.Errorf("expected the export to be recorded, got %+v", statuses)
+	}
+}
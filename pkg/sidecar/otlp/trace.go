@@ -0,0 +1,76 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TraceOptions configures the trace exporter
+type TraceOptions struct {
+	// Endpoint is the collector as host:port, or a URL whose scheme selects
+	// TLS and, for HTTP, whose path replaces /v1/traces
+	Endpoint string
+	Protocol Protocol
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+	// Insecure disables TLS for a host:port endpoint
+	Insecure bool
+	// Timeout bounds a single export
+	Timeout time.Duration
+	// SampleRatio is the fraction of traces kept, from 0 to 1
+	SampleRatio float64
+	// Attributes are added to the exported resource; empty values are skipped
+	Attributes map[string]string
+}
+
+// NewTracerProvider creates a tracer provider that exports spans to the
+// collector in batches. Shutting the provider down flushes pending spans.
+// Connecting is lazy, so an unreachable collector only fails the exports.
+func NewTracerProvider(ctx context.Context, opts TraceOptions) (*sdktrace.TracerProvider, error) {
+	exporter, err := newTraceExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithExportTimeout(opts.Timeout)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(newResource(opts.Attributes)),
+	), nil
+}
+
+// newTraceExporter creates the OTLP trace client for the configured protocol
+func newTraceExporter(ctx context.Context, opts TraceOptions) (sdktrace.SpanExporter, error) {
+	isURL := strings.Contains(opts.Endpoint, "://")
+	switch opts.Protocol {
+	case ProtocolHTTP:
+		httpOpts := []otlptracehttp.Option{otlptracehttp.WithHeaders(opts.Headers), otlptracehttp.WithTimeout(opts.Timeout)}
+		if isURL {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		} else {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+			if opts.Insecure {
+				httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+			}
+		}
+		return otlptracehttp.New(ctx, httpOpts...)
+	case ProtocolGRPC:
+		grpcOpts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(opts.Headers), otlptracegrpc.WithTimeout(opts.Timeout)}
+		if isURL {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpointURL(opts.Endpoint))
+		} else {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(opts.Endpoint))
+			if opts.Insecure {
+				grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+			}
+		}
+		return otlptracegrpc.New(ctx, grpcOpts...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q", opts.Protocol)
+	}
+}
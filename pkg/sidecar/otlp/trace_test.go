@@ -0,0 +1,71 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracerProviderExportsSpans(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+		spans []string
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req collectortrace.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid export request: %v", err)
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					spans = append(spans, span.GetName())
+				}
+			}
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		out, _ := proto.Marshal(&collectortrace.ExportTraceServiceResponse{})
+		_, _ = w.Write(out)
+	}))
+	defer collector.Close()
+
+	provider, err := NewTracerProvider(context.Background(), TraceOptions{
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Protocol:    ProtocolHTTP,
+		Insecure:    true,
+		Timeout:     time.Second,
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, span := provider.Tracer("test").Start(context.Background(), "health.readiness")
+	span.End()
+
+	// Shutting down flushes the batch
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) != 1 || paths[0] != "/v1/traces" {
+		t.Errorf("expected one export to /v1/traces, got %v", paths)
+	}
+	if len(spans) != 1 || spans[0] != "health.readiness" {
+		t.Errorf("expected the span to be exported, got %v", spans)
+	}
+}
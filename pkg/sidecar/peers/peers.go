@@ -0,0 +1,360 @@
+package peers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// CheckName identifies the peer reachability loop in the freshness tracker
+const CheckName = "peers"
+
+// AdminClient defines the Kafka admin operations needed to discover peers.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Dialer opens and closes a TCP connection to the address
+type Dialer func(ctx context.Context, address string) error
+
+// Fetcher reads a peer sidecar's report
+type Fetcher func(ctx context.Context, url string) (Report, error)
+
+// Options configures the peer reachability checks
+type Options struct {
+	// Interval is how often peers are checked
+	Interval time.Duration
+	// SidecarPort is the HTTP port of every peer sidecar
+	SidecarPort int
+	// Timeout bounds each round of checks
+	Timeout time.Duration
+	// TLSConfig, when set, fetches peer reports over HTTPS with it
+	TLSConfig *tls.Config
+}
+
+// Report is one sidecar's view of the cluster's connectivity
+type Report struct {
+	BrokerID  int32     `json:"brokerId"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// Local is whether this sidecar reached each broker's Kafka port, its own included
+	Local map[int32]bool `json:"local"`
+	// Sidecars is whether this sidecar reached each peer sidecar
+	Sidecars map[int32]bool `json:"sidecars"`
+	// Matrix holds the Local view of every sidecar that shared it, by observer
+	Matrix map[int32]map[int32]bool `json:"matrix"`
+	// Down lists brokers nobody reaches and that do not reach their own Kafka port
+	Down []int32 `json:"down,omitempty"`
+	// PartitionedBrokers lists brokers that are up but only reached by some peers
+	PartitionedBrokers []int32 `json:"partitionedBrokers,omitempty"`
+	// Partitioned is true when connectivity is asymmetric, as opposed to brokers being down
+	Partitioned bool `json:"partitioned"`
+}
+
+// Checker periodically dials every broker's Kafka port and every peer sidecar,
+// collects the peers' views into a reachability matrix, and tells network
+// partitions apart from crashed brokers
+type Checker struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	dial          Dialer
+	fetch         Fetcher
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	mu sync.RWMutex
+	// hosts holds every broker seen in metadata, so brokers that drop out of the
+	// metadata after crashing are still checked
+	hosts  map[int32]string
+	report *Report
+}
+
+// NewChecker creates a new peer reachability checker for the local broker
+func NewChecker(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Checker {
+	c := &Checker{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		hosts:       map[int32]string{},
+	}
+	// Set default client factory, dialer and fetcher
+	c.clientFactory = c.defaultClientFactory
+	c.dial = defaultDial
+	c.fetch = newFetcher(&http.Client{Transport: &http.Transport{TLSClientConfig: opts.TLSConfig}})
+	return c
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (c *Checker) SetClientFactory(factory ClientFactory) {
+	c.clientFactory = factory
+}
+
+// SetDialer allows overriding the dialer for testing
+func (c *Checker) SetDialer(dial Dialer) {
+	c.dial = dial
+}
+
+// SetFetcher allows overriding the fetcher for testing
+func (c *Checker) SetFetcher(fetch Fetcher) {
+	c.fetch = fetch
+}
+
+// SetTracker records every round of checks with the freshness tracker
+func (c *Checker) SetTracker(tracker *freshness.Tracker) {
+	c.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (c *Checker) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *Checker) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(c.kafkaConfig)
+}
+
+func defaultDial(ctx context.Context, address string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// newFetcher returns a Fetcher that reads reports with the client
+func newFetcher(client *http.Client) Fetcher {
+	return func(ctx context.Context, url string) (Report, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return Report{}, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return Report{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Report{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		var report Report
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			return Report{}, fmt.Errorf("failed to decode report: %w", err)
+		}
+		return report, nil
+	}
+}
+
+// LastReport returns the report of the last round, and false before the first one
+func (c *Checker) LastReport() (Report, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.report == nil {
+		return Report{}, false
+	}
+	return *c.report, true
+}
+
+// Run checks every Interval until the context is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	c.tracker.Register(CheckName)
+
+	for {
+		c.tracker.Record(CheckName, c.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step refreshes the broker list, dials every broker and peer sidecar, and
+// rebuilds the matrix from this sidecar's view and the views peers shared
+func (c *Checker) Step(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	// Metadata is unavailable when this broker is cut off; keep checking the
+	// brokers seen before
+	if err := c.refreshHosts(ctx); err != nil {
+		c.logger.Warn("peers: failed to refresh brokers from metadata", "error", err)
+	}
+
+	c.mu.RLock()
+	hosts := make(map[int32]string, len(c.hosts))
+	for id, host := range c.hosts {
+		hosts[id] = host
+	}
+	c.mu.RUnlock()
+	if len(hosts) == 0 {
+		return errors.New("no brokers known yet")
+	}
+
+	report := Report{
+		BrokerID: c.brokerID,
+		Local:    map[int32]bool{},
+		Sidecars: map[int32]bool{},
+		Matrix:   map[int32]map[int32]bool{},
+	}
+	views := map[int32]map[int32]bool{}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for id, address := range hosts {
+		wg.Add(1)
+		go func(id int32, address string) {
+			defer wg.Done()
+			reachable := c.dial(ctx, address) == nil
+
+			var view map[int32]bool
+			sidecar := false
+			if id != c.brokerID {
+				view, sidecar = c.peerView(ctx, id, address)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Local[id] = reachable
+			if id != c.brokerID {
+				report.Sidecars[id] = sidecar
+			}
+			if view != nil {
+				views[id] = view
+			}
+		}(id, address)
+	}
+	wg.Wait()
+
+	views[c.brokerID] = report.Local
+	report.Matrix = views
+	report.Down, report.PartitionedBrokers = classify(hosts, views)
+	report.Partitioned = len(report.PartitionedBrokers) > 0
+	report.CheckedAt = c.clock.Now()
+
+	c.mu.Lock()
+	c.report = &report
+	c.mu.Unlock()
+
+	if report.Partitioned {
+		c.logger.Warn("peers: asymmetric connectivity detected", "partitioned", report.PartitionedBrokers, "down", report.Down)
+	}
+	return nil
+}
+
+// refreshHosts adds the brokers in cluster metadata to the known hosts
+func (c *Checker) refreshHosts(ctx context.Context) error {
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range md.Brokers {
+		c.hosts[b.NodeID] = net.JoinHostPort(b.Host, strconv.Itoa(int(b.Port)))
+	}
+	return nil
+}
+
+// peerView fetches the view of the sidecar running next to the broker at address
+func (c *Checker) peerView(ctx context.Context, id int32, address string) (map[int32]bool, bool) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, false
+	}
+	scheme := "http"
+	if c.opts.TLSConfig != nil {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s/admin/peers", scheme, net.JoinHostPort(host, strconv.Itoa(c.opts.SidecarPort)))
+	report, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, false
+	}
+	// A peer still in its first round has no view yet
+	if report.BrokerID != id || report.Local == nil {
+		return nil, true
+	}
+	return report.Local, true
+}
+
+// classify finds the brokers nobody reaches and that cannot reach themselves
+// (down), and those reached by some observers but not others, or by nobody
+// while reaching themselves (partitioned)
+func classify(hosts map[int32]string, views map[int32]map[int32]bool) (down, partitioned []int32) {
+	for target := range hosts {
+		reached, missed := 0, 0
+		for observer, view := range views {
+			if observer == target {
+				continue
+			}
+			ok, seen := view[target]
+			if !seen {
+				continue
+			}
+			if ok {
+				reached++
+			} else {
+				missed++
+			}
+		}
+		if missed == 0 {
+			continue
+		}
+		self, known := views[target][target]
+		switch {
+		case reached > 0, known && self:
+			partitioned = append(partitioned, target)
+		default:
+			down = append(down, target)
+		}
+	}
+	sort.Slice(down, func(i, j int) bool { return down[i] < down[j] })
+	sort.Slice(partitioned, func(i, j int) bool { return partitioned[i] < partitioned[j] })
+	return down, partitioned
+}
+
+// ReportHandler handles GET /admin/peers requests. Peer sidecars read the
+// Local view from it.
+func (c *Checker) ReportHandler(w http.ResponseWriter, _ *http.Request) {
+	report, ok := c.LastReport()
+	if !ok {
+		_, _ = web.ReturnResponse(w, Report{BrokerID: c.brokerID})
+		return
+	}
+	_, _ = web.ReturnResponse(w, report)
+}
@@ -0,0 +1,209 @@
+package peers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// threeBrokers returns metadata of brokers 1-3 on kafka-<id>:9092
+func threeBrokers(context.Context, ...string) (kadm.Metadata, error) {
+	return kadm.Metadata{Brokers: kadm.BrokerDetails{
+		{NodeID: 1, Host: "kafka-1", Port: 9092},
+		{NodeID: 2, Host: "kafka-2", Port: 9092},
+		{NodeID: 3, Host: "kafka-3", Port: 9092},
+	}}, nil
+}
+
+// network describes which broker ports and sidecars broker 1 reaches and what
+// the peer sidecars report
+type network struct {
+	unreachable map[string]bool
+	views       map[string]Report
+}
+
+func newTestChecker(adm AdminClient, n network) *Checker {
+	c := NewChecker(1, kafkaclient.Config{}, Options{Interval: time.Minute, SidecarPort: 8080, Timeout: 10 * time.Second}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return adm, func() {}, nil
+	})
+	c.SetDialer(func(_ context.Context, address string) error {
+		if n.unreachable[address] {
+			return errors.New("i/o timeout")
+		}
+		return nil
+	})
+	c.SetFetcher(func(_ context.Context, url string) (Report, error) {
+		host := strings.TrimSuffix(strings.TrimPrefix(url, "http://"), ":8080/admin/peers")
+		report, ok := n.views[host]
+		if !ok {
+			return Report{}, errors.New("connection refused")
+		}
+		return report, nil
+	})
+	return c
+}
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name              string
+		network           network
+		expectDown        []int32
+		expectPartitioned []int32
+	}{
+		{
+			name: "healthy",
+			network: network{views: map[string]Report{
+				"kafka-2": {BrokerID: 2, Local: map[int32]bool{1: true, 2: true, 3: true}},
+				"kafka-3": {BrokerID: 3, Local: map[int32]bool{1: true, 2: true, 3: true}},
+			}},
+		},
+		{
+			name: "broker crashed",
+			network: network{
+				unreachable: map[string]bool{"kafka-3:9092": true},
+				views: map[string]Report{
+					"kafka-2": {BrokerID: 2, Local: map[int32]bool{1: true, 2: true, 3: false}},
+					// The sidecar survives the broker and cannot reach its own port either
+					"kafka-3": {BrokerID: 3, Local: map[int32]bool{1: true, 2: true, 3: false}},
+				},
+			},
+			expectDown: []int32{3},
+		},
+		{
+			name: "pod gone",
+			network: network{
+				unreachable: map[string]bool{"kafka-3:9092": true},
+				views: map[string]Report{
+					"kafka-2": {BrokerID: 2, Local: map[int32]bool{1: true, 2: true, 3: false}},
+				},
+			},
+			expectDown: []int32{3},
+		},
+		{
+			name: "asymmetric",
+			network: network{
+				unreachable: map[string]bool{"kafka-3:9092": true},
+				views: map[string]Report{
+					"kafka-2": {BrokerID: 2, Local: map[int32]bool{1: true, 2: true, 3: true}},
+					"kafka-3": {BrokerID: 3, Local: map[int32]bool{1: false, 2: true, 3: true}},
+				},
+			},
+			expectPartitioned: []int32{1, 3},
+		},
+		{
+			name: "isolated but alive",
+			network: network{
+				unreachable: map[string]bool{"kafka-3:9092": true},
+				views: map[string]Report{
+					"kafka-2": {BrokerID: 2, Local: map[int32]bool{1: true, 2: true, 3: false}},
+					"kafka-3": {BrokerID: 3, Local: map[int32]bool{1: false, 2: false, 3: true}},
+				},
+			},
+			expectPartitioned: []int32{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestChecker(&MockAdminClient{MetadataFunc: threeBrokers}, tt.network)
+			if err := c.Step(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			report, ok := c.LastReport()
+			if !ok {
+				t.Fatal("expected a report")
+			}
+			if !reflect.DeepEqual(report.Down, tt.expectDown) {
+				t.Errorf("expected down %v, got %v", tt.expectDown, report.Down)
+			}
+			if !reflect.DeepEqual(report.PartitionedBrokers, tt.expectPartitioned) {
+				t.Errorf("expected partitioned %v, got %v", tt.expectPartitioned, report.PartitionedBrokers)
+			}
+			if report.Partitioned != (len(tt.expectPartitioned) > 0) {
+				t.Errorf("unexpected partitioned flag: %v", report.Partitioned)
+			}
+		})
+	}
+}
+
+func TestStepKeepsKnownBrokers(t *testing.T) {
+	calls := 0
+	adm := &MockAdminClient{MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+		calls++
+		if calls == 1 {
+			return threeBrokers(ctx, topics...)
+		}
+		return kadm.Metadata{}, errors.New("no brokers reachable")
+	}}
+	c := newTestChecker(adm, network{unreachable: map[string]bool{"kafka-2:9092": true, "kafka-3:9092": true}})
+
+	_ = c.Step(context.Background())
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := c.LastReport()
+	if len(report.Local) != 3 || report.Local[2] || report.Sidecars[2] {
+		t.Errorf("expected every known broker to be checked, got %+v", report)
+	}
+}
+
+func TestStepNoBrokers(t *testing.T) {
+	adm := &MockAdminClient{MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+		return kadm.Metadata{}, errors.New("no brokers reachable")
+	}}
+	if err := newTestChecker(adm, network{}).Step(context.Background()); err == nil {
+		t.Error("expected an error before any broker is known")
+	}
+}
+
+func TestReportHandler(t *testing.T) {
+	c := newTestChecker(&MockAdminClient{MetadataFunc: threeBrokers}, network{})
+	if err := c.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c.ReportHandler(w, httptest.NewRequest(http.MethodGet, "/admin/peers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Peers decode the report to read the local view
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if report.BrokerID != 1 || len(report.Local) != 3 || !report.Local[3] {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,89 @@
+package procfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// NetDevStats holds the traffic counters of a network interface
+type NetDevStats struct {
+	Interface string `json:"interface"`
+	RxBytes   uint64 `json:"rxBytes"`
+	RxPackets uint64 `json:"rxPackets"`
+	RxErrors  uint64 `json:"rxErrors"`
+	RxDropped uint64 `json:"rxDropped"`
+	TxBytes   uint64 `json:"txBytes"`
+	TxPackets uint64 `json:"txPackets"`
+	TxErrors  uint64 `json:"txErrors"`
+	TxDropped uint64 `json:"txDropped"`
+}
+
+// NetDevReader provides the traffic counters of the network interfaces
+type NetDevReader interface {
+	NetDev() ([]NetDevStats, error)
+}
+
+// NetDev parses <root>/net/dev, the counters of every interface in the
+// reader's network namespace. Containers of a pod share one, so the sidecar
+// sees the broker's traffic.
+func (fs FS) NetDev() ([]NetDevStats, error) {
+	file, err := os.Open(filepath.Join(fs.root, "net", "dev"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network device stats: %w", err)
+	}
+	defer file.Close()
+
+	var stats []NetDevStats
+	scanner := bufio.NewScanner(file)
+	for line := 0; scanner.Scan(); line++ {
+		// The first two lines are column headers
+		if line < 2 {
+			continue
+		}
+		s, err := parseNetDevLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// parseNetDevLine parses "iface: rx bytes packets errs drop fifo frame
+// compressed multicast tx bytes packets errs drop fifo colls carrier compressed"
+func parseNetDevLine(line string) (NetDevStats, error) {
+	name, counters, ok := strings.Cut(line, ":")
+	if !ok {
+		return NetDevStats{}, fmt.Errorf("invalid network device line %q", line)
+	}
+	fields := strings.Fields(counters)
+	if len(fields) < 16 {
+		return NetDevStats{}, fmt.Errorf("expected 16 counters for %s, got %d", strings.TrimSpace(name), len(fields))
+	}
+	values := make([]uint64, 16)
+	for i := range values {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return NetDevStats{}, fmt.Errorf("invalid counter for %s: %w", strings.TrimSpace(name), err)
+		}
+		values[i] = v
+	}
+	return NetDevStats{
+		Interface: strings.TrimSpace(name),
+		RxBytes:   values[0],
+		RxPackets: values[1],
+		RxErrors:  values[2],
+		RxDropped: values[3],
+		TxBytes:   values[8],
+		TxPackets: values[9],
+		TxErrors:  values[10],
+		TxDropped: values[11],
+	}, nil
+}
@@ -0,0 +1,57 @@
+package procfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  123456     789    0    0    0     0          0         0   123456     789    0    0    0     0       0          0
+  eth0: 98765432  65432    1    2    0     0          0         0 87654321   54321    3    4    0     0       0          0
+`
+
+func TestNetDev(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "net", "dev"), []byte(testNetDev), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := NewFS(root).NetDev()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 interfaces, got %d", len(stats))
+	}
+	want := NetDevStats{
+		Interface: "eth0",
+		RxBytes:   98765432, RxPackets: 65432, RxErrors: 1, RxDropped: 2,
+		TxBytes: 87654321, TxPackets: 54321, TxErrors: 3, TxDropped: 4,
+	}
+	if stats[1] != want {
+		t.Errorf("expected %+v, got %+v", want, stats[1])
+	}
+}
+
+func TestNetDevInvalid(t *testing.T) {
+	root := t.TempDir()
+	if _, err := NewFS(root).NetDev(); err == nil {
+		t.Error("expected an error without net/dev")
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	truncated := testNetDev + "  eth1: 1 2 3 4\n"
+	if err := os.WriteFile(filepath.Join(root, "net", "dev"), []byte(truncated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFS(root).NetDev(); err == nil {
+		t.Error("expected an error for a truncated line")
+	}
+}
@@ -0,0 +1,226 @@
+package procfs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultRoot is the mount point of the proc filesystem
+	DefaultRoot = "/proc"
+
+	// DefaultBrokerMatch matches the Kafka broker JVM's main class on its command line
+	DefaultBrokerMatch = "kafka.Kafka"
+)
+
+// ErrProcessNotFound is returned when no process matches the locator
+var ErrProcessNotFound = errors.New("process not found")
+
+// FDUsage holds file descriptor usage for a process
+type FDUsage struct {
+	Used  uint64 `json:"used"`
+	Limit uint64 `json:"limit"`
+}
+
+// FreeRatio returns the fraction of the FD limit still available (1 when unlimited)
+func (u FDUsage) FreeRatio() float64 {
+	if u.Limit == 0 {
+		return 1
+	}
+	if u.Used >= u.Limit {
+		return 0
+	}
+	return float64(u.Limit-u.Used) / float64(u.Limit)
+}
+
+// FDUsageReader provides file descriptor usage for a process
+type FDUsageReader interface {
+	FDUsage() (FDUsage, error)
+}
+
+// FS reads process information from a proc filesystem
+type FS struct {
+	root string
+}
+
+// NewFS creates a new proc filesystem reader rooted at root
+func NewFS(root string) FS {
+	return FS{root: root}
+}
+
+// FindPID returns the lowest PID whose command line contains match
+func (fs FS) FindPID(match string) (int, error) {
+	entries, err := os.ReadDir(fs.root)
+	if err != nil {
+		return 0, err
+	}
+
+	found := 0
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := fs.Cmdline(pid)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(cmdline, match) && (found == 0 || pid < found) {
+			found = pid
+		}
+	}
+
+	if found == 0 {
+		return 0, ErrProcessNotFound
+	}
+	return found, nil
+}
+
+// Cmdline returns the command line of a process with arguments separated by spaces
+func (fs FS) Cmdline(pid int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(fs.root, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " ")), nil
+}
+
+// FDUsage returns the open file descriptor count and soft limit of a process
+func (fs FS) FDUsage(pid int) (FDUsage, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.root, strconv.Itoa(pid), "fd"))
+	if err != nil {
+		return FDUsage{}, fmt.Errorf("failed to list fds for pid %d: %w", pid, err)
+	}
+
+	limit, err := fs.maxOpenFiles(pid)
+	if err != nil {
+		return FDUsage{}, err
+	}
+
+	return FDUsage{Used: uint64(len(entries)), Limit: limit}, nil
+}
+
+// maxOpenFiles parses the soft "Max open files" limit from /proc/<pid>/limits.
+// An unlimited soft limit is reported as 0.
+func (fs FS) maxOpenFiles(pid int) (uint64, error) {
+	file, err := os.Open(filepath.Join(fs.root, strconv.Itoa(pid), "limits"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read limits for pid %d: %w", pid, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, nil
+		}
+		return strconv.ParseUint(fields[0], 10, 64)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, fmt.Errorf("max open files not found in limits for pid %d", pid)
+}
+
+// Process locates a process by PID file or command line match. A PID found by
+// command line match is cached and re-resolved when the process restarts.
+type Process struct {
+	fs      FS
+	pidFile string
+	match   string
+
+	mu  sync.Mutex
+	pid int
+}
+
+// NewProcess creates a process locator. When pidFile is set it takes precedence
+// over command line matching.
+func NewProcess(fs FS, pidFile, match string) *Process {
+	return &Process{
+		fs:      fs,
+		pidFile: pidFile,
+		match:   match,
+	}
+}
+
+// PID returns the current PID of the process
+func (p *Process) PID() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pidFile == "" && p.pid != 0 && p.stillMatches(p.pid) {
+		return p.pid, nil
+	}
+
+	pid, err := p.resolve()
+	if err != nil {
+		p.pid = 0
+		return 0, err
+	}
+	p.pid = pid
+	return pid, nil
+}
+
+// FDUsage implements FDUsageReader
+func (p *Process) FDUsage() (FDUsage, error) {
+	pid, err := p.PID()
+	if err != nil {
+		return FDUsage{}, err
+	}
+	return p.fs.FDUsage(pid)
+}
+
+// resolve finds the process from scratch
+func (p *Process) resolve() (int, error) {
+	if p.pidFile != "" {
+		data, err := os.ReadFile(p.pidFile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read pid file: %w", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid pid file %s: %w", p.pidFile, err)
+		}
+		return pid, nil
+	}
+	return p.fs.FindPID(p.match)
+}
+
+// stillMatches reports whether a cached PID still refers to the located process
+func (p *Process) stillMatches(pid int) bool {
+	cmdline, err := p.fs.Cmdline(pid)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(cmdline, p.match)
+}
+
+// Self reads the usage of the calling process, the sidecar itself
+type Self struct {
+	fs FS
+}
+
+// NewSelf creates a reader for the calling process
+func NewSelf(fs FS) Self {
+	return Self{fs: fs}
+}
+
+// FDUsage implements FDUsageReader
+func (s Self) FDUsage() (FDUsage, error) {
+	return s.fs.FDUsage(os.Getpid())
+}
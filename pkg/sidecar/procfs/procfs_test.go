@@ -0,0 +1,182 @@
+package procfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const testLimits = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            1024                 524288               files
+Max locked memory         65536                65536                bytes
+`
+
+// writeProcess creates a fake /proc/<pid> entry with the given command line and fd count
+func writeProcess(t *testing.T, root string, pid int, cmdline string, fds int, limits string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0o755); err != nil {
+		t.Fatalf("failed to create proc dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644); err != nil {
+		t.Fatalf("failed to write cmdline: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "limits"), []byte(limits), 0o644); err != nil {
+		t.Fatalf("failed to write limits: %v", err)
+	}
+	for i := 0; i < fds; i++ {
+		if err := os.WriteFile(filepath.Join(dir, "fd", fmt.Sprint(i)), nil, 0o644); err != nil {
+			t.Fatalf("failed to write fd: %v", err)
+		}
+	}
+}
+
+func TestFindPID(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, 1, "/pause", 3, testLimits)
+	writeProcess(t, root, 42, "java\x00-Xmx1g\x00kafka.Kafka\x00/config/server.properties", 10, testLimits)
+	writeProcess(t, root, 77, "java\x00kafka.Kafka", 10, testLimits)
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewFS(root)
+	pid, err := fs.FindPID(DefaultBrokerMatch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pid != 42 {
+		t.Errorf("expected lowest matching pid 42, got %d", pid)
+	}
+
+	if _, err := fs.FindPID("does-not-exist"); !errors.Is(err, ErrProcessNotFound) {
+		t.Errorf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func TestFDUsage(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, 42, "kafka.Kafka", 5, testLimits)
+	writeProcess(t, root, 43, "other", 2, "Max open files            unlimited            unlimited            files\n")
+	writeProcess(t, root, 44, "broken", 2, "Max cpu time unlimited unlimited seconds\n")
+
+	fs := NewFS(root)
+
+	usage, err := fs.FDUsage(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Used != 5 || usage.Limit != 1024 {
+		t.Errorf("expected 5/1024, got %d/%d", usage.Used, usage.Limit)
+	}
+
+	usage, err = fs.FDUsage(43)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Limit != 0 {
+		t.Errorf("expected unlimited (0), got %d", usage.Limit)
+	}
+
+	if _, err := fs.FDUsage(44); err == nil {
+		t.Error("expected error when max open files is missing")
+	}
+	if _, err := fs.FDUsage(99); err == nil {
+		t.Error("expected error for missing process")
+	}
+}
+
+func TestSelfFDUsage(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, os.Getpid(), "sidecar", 7, testLimits)
+
+	usage, err := NewSelf(NewFS(root)).FDUsage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Used != 7 || usage.Limit != 1024 {
+		t.Errorf("expected 7/1024, got %d/%d", usage.Used, usage.Limit)
+	}
+}
+
+func TestFDUsageFreeRatio(t *testing.T) {
+	tests := []struct {
+		usage    FDUsage
+		expected float64
+	}{
+		{FDUsage{Used: 10, Limit: 100}, 0.9},
+		{FDUsage{Used: 100, Limit: 100}, 0},
+		{FDUsage{Used: 200, Limit: 100}, 0},
+		{FDUsage{Used: 10, Limit: 0}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.usage.FreeRatio(); got != tt.expected {
+			t.Errorf("FreeRatio(%+v) = %v, expected %v", tt.usage, got, tt.expected)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, 42, "kafka.Kafka", 5, testLimits)
+	fs := NewFS(root)
+
+	p := NewProcess(fs, "", DefaultBrokerMatch)
+	pid, err := p.PID()
+	if err != nil || pid != 42 {
+		t.Fatalf("expected pid 42, got %d (%v)", pid, err)
+	}
+
+	// Broker restarts with a new PID
+	if err := os.RemoveAll(filepath.Join(root, "42")); err != nil {
+		t.Fatal(err)
+	}
+	writeProcess(t, root, 50, "kafka.Kafka", 7, testLimits)
+
+	usage, err := p.FDUsage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Used != 7 {
+		t.Errorf("expected re-resolved process with 7 fds, got %d", usage.Used)
+	}
+
+	// Broker gone entirely
+	if err := os.RemoveAll(filepath.Join(root, "50")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.PID(); !errors.Is(err, ErrProcessNotFound) {
+		t.Errorf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func TestProcessPIDFile(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, 42, "anything", 5, testLimits)
+	pidFile := filepath.Join(t.TempDir(), "kafka.pid")
+
+	p := NewProcess(NewFS(root), pidFile, DefaultBrokerMatch)
+	if _, err := p.PID(); err == nil {
+		t.Error("expected error when pid file is missing")
+	}
+
+	if err := os.WriteFile(pidFile, []byte("42\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pid, err := p.PID()
+	if err != nil || pid != 42 {
+		t.Errorf("expected pid 42 from pid file, got %d (%v)", pid, err)
+	}
+
+	if err := os.WriteFile(pidFile, []byte("not-a-pid"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.PID(); err == nil {
+		t.Error("expected error for invalid pid file")
+	}
+}
@@ -0,0 +1,135 @@
+package procfs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// userHZ is the unit of the CPU times in /proc/<pid>/stat. The kernel exports
+// them in USER_HZ, which is 100 on every architecture Kafka runs on.
+const userHZ = 100
+
+// ProcessStats holds the resource usage of a process
+type ProcessStats struct {
+	// ResidentBytes is the resident set size (VmRSS)
+	ResidentBytes uint64 `json:"residentBytes"`
+	// ResidentAnonBytes is the anonymous part of the resident set (RssAnon): for
+	// a JVM, the heap and off-heap allocations
+	ResidentAnonBytes uint64 `json:"residentAnonBytes"`
+	// ResidentFileBytes is the file-backed part of the resident set (RssFile),
+	// such as memory-mapped log indexes
+	ResidentFileBytes uint64 `json:"residentFileBytes"`
+	// VirtualBytes is the virtual memory size (VmSize)
+	VirtualBytes uint64 `json:"virtualBytes"`
+	// CPUSeconds is the user and system CPU time consumed
+	CPUSeconds float64 `json:"cpuSeconds"`
+	// Threads is the number of threads
+	Threads uint64 `json:"threads"`
+}
+
+// ProcessStatsReader provides the resource usage of a process
+type ProcessStatsReader interface {
+	ProcessStats() (ProcessStats, error)
+}
+
+// ProcessStats returns the memory, CPU and thread usage of a process from
+// /proc/<pid>/status and /proc/<pid>/stat
+func (fs FS) ProcessStats(pid int) (ProcessStats, error) {
+	stats, err := fs.status(pid)
+	if err != nil {
+		return ProcessStats{}, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(fs.root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return ProcessStats{}, fmt.Errorf("failed to read stat for pid %d: %w", pid, err)
+	}
+	cpu, err := parseCPUSeconds(string(data))
+	if err != nil {
+		return ProcessStats{}, fmt.Errorf("invalid stat for pid %d: %w", pid, err)
+	}
+	stats.CPUSeconds = cpu
+	return stats, nil
+}
+
+// status parses the memory and thread fields of /proc/<pid>/status
+func (fs FS) status(pid int) (ProcessStats, error) {
+	file, err := os.Open(filepath.Join(fs.root, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return ProcessStats{}, fmt.Errorf("failed to read status for pid %d: %w", pid, err)
+	}
+	defer file.Close()
+
+	var stats ProcessStats
+	fields := map[string]*uint64{
+		"VmRSS":   &stats.ResidentBytes,
+		"RssAnon": &stats.ResidentAnonBytes,
+		"RssFile": &stats.ResidentFileBytes,
+		"VmSize":  &stats.VirtualBytes,
+		"Threads": &stats.Threads,
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		target, known := fields[key]
+		if !ok || !known {
+			continue
+		}
+		parts := strings.Fields(value)
+		if len(parts) == 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return ProcessStats{}, fmt.Errorf("invalid %s in status for pid %d: %w", key, pid, err)
+		}
+		// Memory fields are in kB
+		if len(parts) > 1 && parts[1] == "kB" {
+			n *= 1024
+		}
+		*target = n
+	}
+	if err := scanner.Err(); err != nil {
+		return ProcessStats{}, err
+	}
+	return stats, nil
+}
+
+// parseCPUSeconds returns utime + stime from the contents of /proc/<pid>/stat.
+// The command name may contain spaces and parentheses, so fields are counted
+// from its closing parenthesis.
+func parseCPUSeconds(stat string) (float64, error) {
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, errors.New("missing command name")
+	}
+	// Field 3 (state) is the first after the command name; utime and stime are 14 and 15
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("expected at least 15 fields, got %d", len(fields)+2)
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid utime: %w", err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stime: %w", err)
+	}
+	return float64(utime+stime) / userHZ, nil
+}
+
+// ProcessStats implements ProcessStatsReader
+func (p *Process) ProcessStats() (ProcessStats, error) {
+	pid, err := p.PID()
+	if err != nil {
+		return ProcessStats{}, err
+	}
+	return p.fs.ProcessStats(pid)
+}
@@ -0,0 +1,81 @@
+package procfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testStatus = `Name:	java
+State:	S (sleeping)
+VmSize:	 8388608 kB
+VmRSS:	 2097152 kB
+RssAnon:	 1572864 kB
+RssFile:	  524288 kB
+RssShmem:	       0 kB
+Threads:	142
+`
+
+// The command name contains a space and a parenthesis
+const testStat = `42 (java (main)) S 1 42 42 0 -1 4194560 5000 0 0 0 1250 250 0 0 20 0 142 0 100 8589934592 524288 18446744073709551615`
+
+func writeStats(t *testing.T, root string, pid, status, stat string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessStats(t *testing.T) {
+	root := t.TempDir()
+	writeStats(t, root, "42", testStatus, testStat)
+
+	stats, err := NewFS(root).ProcessStats(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ProcessStats{
+		ResidentBytes:     2 << 30,
+		ResidentAnonBytes: 1536 << 20,
+		ResidentFileBytes: 512 << 20,
+		VirtualBytes:      8 << 30,
+		CPUSeconds:        15,
+		Threads:           142,
+	}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+}
+
+func TestProcessStatsInvalid(t *testing.T) {
+	root := t.TempDir()
+	writeStats(t, root, "42", testStatus, "42 (java) S 1")
+
+	if _, err := NewFS(root).ProcessStats(42); err == nil {
+		t.Error("expected an error for a truncated stat file")
+	}
+	if _, err := NewFS(root).ProcessStats(43); err == nil {
+		t.Error("expected an error for a missing process")
+	}
+}
+
+func TestProcessProcessStats(t *testing.T) {
+	root := t.TempDir()
+	writeProcess(t, root, 42, "java kafka.Kafka server.properties", 0, testLimits)
+	writeStats(t, root, "42", testStatus, testStat)
+
+	stats, err := NewProcess(NewFS(root), "", DefaultBrokerMatch).ProcessStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Threads != 142 {
+		t.Errorf("expected 142 threads, got %d", stats.Threads)
+	}
+}
@@ -0,0 +1,77 @@
+package quotas
+
+import (
+	"net/http"
+	"strconv"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+)
+
+// RecommendationsResponse represents the response for the recommendations endpoint
+type RecommendationsResponse struct {
+	HeadroomFactor  float64          `json:"headroomFactor"`
+	Window          string           `json:"window"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// ApplyRequest selects which principals to apply recommendations for.
+// An empty request applies every recommendation.
+type ApplyRequest struct {
+	Principals []Principal `json:"principals,omitempty"`
+}
+
+// ApplyResponse represents the response for the apply endpoint
+type ApplyResponse struct {
+	DryRun  bool          `json:"dryRun"`
+	Applied []ApplyResult `json:"applied"`
+	Failed  int           `json:"failed"`
+}
+
+// RecommendationsHandler handles GET /admin/quotas/recommendations requests
+func (r *Recommender) RecommendationsHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, RecommendationsResponse{
+		HeadroomFactor:  r.opts.HeadroomFactor,
+		Window:          r.opts.Window.String(),
+		Recommendations: r.Recommendations(),
+	})
+}
+
+// ApplyHandler handles POST /admin/quotas/recommendations/apply requests
+func (r *Recommender) ApplyHandler(w http.ResponseWriter, req *http.Request) {
+	dryRun, _ := strconv.ParseBool(req.URL.Query().Get("dryRun"))
+
+	var body ApplyRequest
+	if req.ContentLength > 0 {
+		parsed, err := web.ParseJsonRequestBody[ApplyRequest](req)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+			return
+		}
+		body = parsed
+	}
+
+	results, err := r.Apply(req.Context(), body.Principals, dryRun)
+	if err != nil {
+		r.logger.ErrorContext(req.Context(), "failed to apply quota recommendations", "error", err)
+		_, _ = web.ReturnError(w, cplnErrors.Internal("failed to apply quota recommendations", err))
+		return
+	}
+
+	response := ApplyResponse{
+		DryRun:  dryRun,
+		Applied: results,
+	}
+	for _, res := range results {
+		if res.Error != "" {
+			response.Failed++
+		}
+	}
+
+	if !dryRun {
+		r.logger.InfoContext(req.Context(), "applied quota recommendations",
+			"count", len(results),
+			"failed", response.Failed)
+	}
+	_, _ = web.ReturnResponse(w, response)
+}
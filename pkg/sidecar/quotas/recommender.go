@@ -0,0 +1,351 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scheduler"
+)
+
+// CheckName identifies the usage sampler in the freshness tracker
+const CheckName = "quota-sampler"
+
+const (
+	// recommendationPercentile is the percentile of observed throughput used as the
+	// baseline for a recommendation. Using a high percentile rather than the peak
+	// keeps a single burst from inflating the quota.
+	recommendationPercentile = 0.95
+
+	producerByteRateKey = "producer_byte_rate"
+	consumerByteRateKey = "consumer_byte_rate"
+)
+
+// AdminClient defines the Kafka admin operations needed to apply quotas.
+// This enables mocking in tests.
+type AdminClient interface {
+	AlterClientQuotas(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error)
+	ValidateAlterClientQuotas(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the recommendation engine
+type Options struct {
+	// SampleInterval is how often usage is sampled
+	SampleInterval time.Duration
+	// Window is how long samples are retained for recommendations
+	Window time.Duration
+	// HeadroomFactor multiplies the observed baseline (e.g. 1.5 = 50% headroom)
+	HeadroomFactor float64
+	// MinByteRate is the lowest quota ever recommended, in bytes/sec
+	MinByteRate float64
+	// Timeout bounds each sample and apply call
+	Timeout time.Duration
+}
+
+// sample is a single usage observation kept in the window
+type sample struct {
+	at      time.Time
+	produce float64
+	fetch   float64
+}
+
+// Recommendation is a recommended quota for a single principal
+type Recommendation struct {
+	Principal
+	Samples                 int     `json:"samples"`
+	ObservedProduceByteRate float64 `json:"observedProduceByteRate"`
+	ObservedFetchByteRate   float64 `json:"observedFetchByteRate"`
+	PeakProduceByteRate     float64 `json:"peakProduceByteRate"`
+	PeakFetchByteRate       float64 `json:"peakFetchByteRate"`
+	ProducerByteRate        float64 `json:"producerByteRate"`
+	ConsumerByteRate        float64 `json:"consumerByteRate"`
+}
+
+// Recommender samples per-principal throughput and derives quota recommendations
+type Recommender struct {
+	source        UsageSource
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+	scheduler     *scheduler.Scheduler
+
+	mu      sync.RWMutex
+	samples map[Principal][]sample
+}
+
+// NewRecommender creates a new quota recommender
+func NewRecommender(source UsageSource, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Recommender {
+	r := &Recommender{
+		source:      source,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		scheduler:   scheduler.New(scheduler.Options{}),
+		samples:     make(map[Principal][]sample),
+	}
+	// Set default client factory
+	r.clientFactory = r.defaultClientFactory
+	return r
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (r *Recommender) SetClientFactory(factory ClientFactory) {
+	r.clientFactory = factory
+}
+
+// SetTracker records every sample with the freshness tracker
+func (r *Recommender) SetTracker(tracker *freshness.Tracker) {
+	r.tracker = tracker
+}
+
+// SetClock replaces the wall clock that schedules and timestamps samples
+func (r *Recommender) SetClock(clk clock.Clock) {
+	r.clock = clk
+}
+
+// SetScheduler batches the quota alters with the scheduler shared by every
+// reconciler
+func (r *Recommender) SetScheduler(s *scheduler.Scheduler) {
+	r.scheduler = s
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (r *Recommender) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(r.kafkaConfig)
+}
+
+// Run samples usage every SampleInterval until the context is cancelled
+func (r *Recommender) Run(ctx context.Context) {
+	ticker := r.clock.NewTicker(r.opts.SampleInterval)
+	defer ticker.Stop()
+	r.tracker.Register(CheckName)
+
+	for {
+		err := r.Sample(ctx, r.clock.Now())
+		if err != nil {
+			r.logger.Warn("failed to sample quota usage", "error", err)
+		}
+		r.tracker.Record(CheckName, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Sample records one usage observation per principal and prunes samples outside the window
+func (r *Recommender) Sample(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	usage, err := r.source.SampleUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range usage {
+		r.samples[u.Principal] = append(r.samples[u.Principal], sample{
+			at:      now,
+			produce: u.ProduceByteRate,
+			fetch:   u.FetchByteRate,
+		})
+	}
+
+	cutoff := now.Add(-r.opts.Window)
+	for p, s := range r.samples {
+		i := 0
+		for i < len(s) && s[i].at.Before(cutoff) {
+			i++
+		}
+		if i == len(s) {
+			delete(r.samples, p)
+			continue
+		}
+		r.samples[p] = s[i:]
+	}
+
+	return nil
+}
+
+// Recommendations returns the current recommendation for every observed principal
+func (r *Recommender) Recommendations() []Recommendation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recommendations := make([]Recommendation, 0, len(r.samples))
+	for p, s := range r.samples {
+		produce := make([]float64, len(s))
+		fetch := make([]float64, len(s))
+		for i := range s {
+			produce[i] = s[i].produce
+			fetch[i] = s[i].fetch
+		}
+
+		rec := Recommendation{
+			Principal:               p,
+			Samples:                 len(s),
+			ObservedProduceByteRate: percentile(produce, recommendationPercentile),
+			ObservedFetchByteRate:   percentile(fetch, recommendationPercentile),
+			PeakProduceByteRate:     percentile(produce, 1),
+			PeakFetchByteRate:       percentile(fetch, 1),
+		}
+		rec.ProducerByteRate = r.withHeadroom(rec.ObservedProduceByteRate)
+		rec.ConsumerByteRate = r.withHeadroom(rec.ObservedFetchByteRate)
+		recommendations = append(recommendations, rec)
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Principal.String() < recommendations[j].Principal.String()
+	})
+	return recommendations
+}
+
+// withHeadroom applies the headroom factor and floor, rounded up to a whole KiB
+func (r *Recommender) withHeadroom(observed float64) float64 {
+	value := math.Max(observed*r.opts.HeadroomFactor, r.opts.MinByteRate)
+	return math.Ceil(value/1024) * 1024
+}
+
+// ApplyResult is the outcome of applying a recommendation to a single principal
+type ApplyResult struct {
+	Principal
+	ProducerByteRate float64 `json:"producerByteRate"`
+	ConsumerByteRate float64 `json:"consumerByteRate"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// Apply writes the recommended quotas for the selected principals (all when empty).
+// When dryRun is set the request is only validated by the controller.
+func (r *Recommender) Apply(ctx context.Context, principals []Principal, dryRun bool) ([]ApplyResult, error) {
+	selected := make(map[Principal]bool, len(principals))
+	for _, p := range principals {
+		selected[p] = true
+	}
+
+	var entries []kadm.AlterClientQuotaEntry
+	var results []ApplyResult
+	for _, rec := range r.Recommendations() {
+		if len(selected) > 0 && !selected[rec.Principal] {
+			continue
+		}
+		entity := entityFor(rec.Principal)
+		if len(entity) == 0 {
+			// Never alter the default entity from observed usage
+			continue
+		}
+		entries = append(entries, kadm.AlterClientQuotaEntry{
+			Entity: entity,
+			Ops: []kadm.AlterClientQuotaOp{
+				{Key: producerByteRateKey, Value: rec.ProducerByteRate},
+				{Key: consumerByteRateKey, Value: rec.ConsumerByteRate},
+			},
+		})
+		results = append(results, ApplyResult{
+			Principal:        rec.Principal,
+			ProducerByteRate: rec.ProducerByteRate,
+			ConsumerByteRate: rec.ConsumerByteRate,
+		})
+	}
+
+	if len(entries) == 0 {
+		return results, nil
+	}
+	byEntity := make(map[string]*ApplyResult, len(results))
+	for i := range results {
+		byEntity[entityFor(results[i].Principal).String()] = &results[i]
+	}
+
+	adm, cleanup, err := r.clientFactory()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	alter := adm.AlterClientQuotas
+	if dryRun {
+		alter = adm.ValidateAlterClientQuotas
+	}
+	var (
+		mu      sync.Mutex
+		altered kadm.AlteredClientQuotas
+	)
+	err = scheduler.Run(ctx, r.scheduler, entries, func(ctx context.Context, batch []kadm.AlterClientQuotaEntry) error {
+		ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+		resp, err := alter(ctx, batch)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		altered = append(altered, resp...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to alter client quotas: %w", err)
+	}
+
+	for _, a := range altered {
+		if a.Err == nil {
+			continue
+		}
+		if res, ok := byEntity[a.Entity.String()]; ok {
+			res.Error = a.Err.Error()
+			if a.ErrMessage != "" {
+				res.Error = a.ErrMessage
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// entityFor builds the quota entity for a principal, omitting empty components
+func entityFor(p Principal) kadm.ClientQuotaEntity {
+	var entity kadm.ClientQuotaEntity
+	if p.User != "" {
+		user := p.User
+		entity = append(entity, kadm.ClientQuotaEntityComponent{Type: "user", Name: &user})
+	}
+	if p.ClientID != "" {
+		clientID := p.ClientID
+		entity = append(entity, kadm.ClientQuotaEntityComponent{Type: "client-id", Name: &clientID})
+	}
+	return entity
+}
+
+// percentile returns the nearest-rank percentile (0 < q <= 1) of the values
+func percentile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,366 @@
+package quotas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scheduler"
+)
+
+// MockUsageSource is a mock implementation of UsageSource for testing
+type MockUsageSource struct {
+	SampleUsageFunc func(ctx context.Context) ([]Usage, error)
+}
+
+func (m *MockUsageSource) SampleUsage(ctx context.Context) ([]Usage, error) {
+	if m.SampleUsageFunc != nil {
+		return m.SampleUsageFunc(ctx)
+	}
+	return nil, nil
+}
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	AlterClientQuotasFunc         func(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error)
+	ValidateAlterClientQuotasFunc func(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error)
+}
+
+func (m *MockAdminClient) AlterClientQuotas(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error) {
+	if m.AlterClientQuotasFunc != nil {
+		return m.AlterClientQuotasFunc(ctx, entries)
+	}
+	return nil, nil
+}
+
+func (m *MockAdminClient) ValidateAlterClientQuotas(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error) {
+	if m.ValidateAlterClientQuotasFunc != nil {
+		return m.ValidateAlterClientQuotasFunc(ctx, entries)
+	}
+	return nil, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func testOptions() Options {
+	return Options{
+		SampleInterval: time.Second,
+		Window:         time.Hour,
+		HeadroomFactor: 2,
+		MinByteRate:    1024,
+		Timeout:        time.Second,
+	}
+}
+
+func newTestRecommender(usage ...[]Usage) *Recommender {
+	i := 0
+	source := &MockUsageSource{
+		SampleUsageFunc: func(ctx context.Context) ([]Usage, error) {
+			u := usage[i%len(usage)]
+			i++
+			return u, nil
+		},
+	}
+	return NewRecommender(source, kafkaclient.Config{}, testOptions(), testLogger())
+}
+
+func TestRecommendations(t *testing.T) {
+	alice := Principal{User: "alice", ClientID: "app1"}
+	r := newTestRecommender(
+		[]Usage{{Principal: alice, ProduceByteRate: 10 * 1024, FetchByteRate: 100}},
+		[]Usage{{Principal: alice, ProduceByteRate: 20 * 1024, FetchByteRate: 100}},
+	)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := r.Sample(context.Background(), now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	recs := r.Recommendations()
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 recommendation, got %d", len(recs))
+	}
+	rec := recs[0]
+	if rec.Samples != 2 {
+		t.Errorf("expected 2 samples, got %d", rec.Samples)
+	}
+	if rec.PeakProduceByteRate != 20*1024 {
+		t.Errorf("expected peak produce 20480, got %v", rec.PeakProduceByteRate)
+	}
+	// p95 of two samples is the larger one; doubled by headroom
+	if rec.ProducerByteRate != 40*1024 {
+		t.Errorf("expected producer quota 40960, got %v", rec.ProducerByteRate)
+	}
+	// fetch usage is below the floor
+	if rec.ConsumerByteRate != 1024 {
+		t.Errorf("expected consumer quota at floor 1024, got %v", rec.ConsumerByteRate)
+	}
+}
+
+func TestSamplePrunesWindow(t *testing.T) {
+	alice := Principal{User: "alice"}
+	bob := Principal{User: "bob"}
+	r := newTestRecommender(
+		[]Usage{{Principal: alice, ProduceByteRate: 1}},
+		[]Usage{{Principal: bob, ProduceByteRate: 1}},
+	)
+
+	start := time.Now()
+	_ = r.Sample(context.Background(), start)
+	_ = r.Sample(context.Background(), start.Add(2*time.Hour))
+
+	recs := r.Recommendations()
+	if len(recs) != 1 || recs[0].Principal != bob {
+		t.Errorf("expected only bob to remain in window, got %+v", recs)
+	}
+}
+
+func TestRunSamplesOnClockTicks(t *testing.T) {
+	alice := Principal{User: "alice"}
+	sampled := make(chan struct{})
+	source := &MockUsageSource{
+		SampleUsageFunc: func(ctx context.Context) ([]Usage, error) {
+			sampled <- struct{}{}
+			return []Usage{{Principal: alice, ProduceByteRate: 1}}, nil
+		},
+	}
+	r := NewRecommender(source, kafkaclient.Config{}, testOptions(), testLogger())
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	r.SetClock(clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	<-sampled
+	clk.Advance(time.Second)
+	<-sampled
+	// Both earlier samples fall out of the window
+	clk.Advance(2 * time.Hour)
+	<-sampled
+	cancel()
+	<-done
+
+	recs := r.Recommendations()
+	if len(recs) != 1 || recs[0].Samples != 1 {
+		t.Errorf("expected a single sample in the window, got %+v", recs)
+	}
+}
+
+func TestSampleError(t *testing.T) {
+	source := &MockUsageSource{
+		SampleUsageFunc: func(ctx context.Context) ([]Usage, error) {
+			return nil, errors.New("jolokia unavailable")
+		},
+	}
+	r := NewRecommender(source, kafkaclient.Config{}, testOptions(), testLogger())
+	if err := r.Sample(context.Background(), time.Now()); err == nil {
+		t.Error("expected error but got none")
+	}
+}
+
+func TestApply(t *testing.T) {
+	alice := Principal{User: "alice"}
+	bob := Principal{User: "bob", ClientID: "app2"}
+	anonymous := Principal{}
+
+	tests := []struct {
+		name          string
+		principals    []Principal
+		dryRun        bool
+		alterErr      error
+		entityErr     bool
+		expectEntries int
+		expectError   bool
+		expectFailed  int
+	}{
+		{
+			name:          "apply all skips default entity",
+			expectEntries: 2,
+		},
+		{
+			name:          "apply selected principal",
+			principals:    []Principal{bob},
+			expectEntries: 1,
+		},
+		{
+			name:          "dry run validates",
+			dryRun:        true,
+			expectEntries: 2,
+		},
+		{
+			name:        "alter error",
+			alterErr:    errors.New("controller unavailable"),
+			expectError: true,
+		},
+		{
+			name:          "per-entity error",
+			entityErr:     true,
+			expectEntries: 2,
+			expectFailed:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRecommender([]Usage{
+				{Principal: alice, ProduceByteRate: 4096},
+				{Principal: bob, FetchByteRate: 4096},
+				{Principal: anonymous, FetchByteRate: 4096},
+			})
+			_ = r.Sample(context.Background(), time.Now())
+
+			var gotEntries []kadm.AlterClientQuotaEntry
+			var validated bool
+			alter := func(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error) {
+				gotEntries = entries
+				if tt.alterErr != nil {
+					return nil, tt.alterErr
+				}
+				var altered kadm.AlteredClientQuotas
+				if tt.entityErr {
+					altered = append(altered, kadm.AlteredClientQuota{Entity: entries[0].Entity, Err: errors.New("invalid quota")})
+				}
+				return altered, nil
+			}
+			r.SetClientFactory(func() (AdminClient, func(), error) {
+				return &MockAdminClient{
+					AlterClientQuotasFunc: alter,
+					ValidateAlterClientQuotasFunc: func(ctx context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error) {
+						validated = true
+						return alter(ctx, entries)
+					},
+				}, func() {}, nil
+			})
+
+			results, err := r.Apply(context.Background(), tt.principals, tt.dryRun)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(gotEntries) != tt.expectEntries {
+				t.Errorf("expected %d entries, got %d", tt.expectEntries, len(gotEntries))
+			}
+			if validated != tt.dryRun {
+				t.Errorf("expected validate=%v, got %v", tt.dryRun, validated)
+			}
+
+			failed := 0
+			for _, res := range results {
+				if res.Error != "" {
+					failed++
+				}
+			}
+			if failed != tt.expectFailed {
+				t.Errorf("expected %d failed results, got %d", tt.expectFailed, failed)
+			}
+		})
+	}
+}
+
+func TestApplyBatches(t *testing.T) {
+	var usage []Usage
+	for _, user := range []string{"a", "b", "c", "d", "e"} {
+		usage = append(usage, Usage{Principal: Principal{User: user}, ProduceByteRate: 4096})
+	}
+	r := newTestRecommender(usage)
+	_ = r.Sample(context.Background(), time.Now())
+	r.SetScheduler(scheduler.New(scheduler.Options{Concurrency: 2, BatchSize: 2}))
+
+	var mu sync.Mutex
+	var sizes []int
+	r.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			AlterClientQuotasFunc: func(_ context.Context, entries []kadm.AlterClientQuotaEntry) (kadm.AlteredClientQuotas, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				sizes = append(sizes, len(entries))
+				var altered kadm.AlteredClientQuotas
+				for _, e := range entries {
+					if e.Entity.String() == entityFor(Principal{User: "e"}).String() {
+						altered = append(altered, kadm.AlteredClientQuota{Entity: e.Entity, Err: errors.New("invalid quota")})
+					}
+				}
+				return altered, nil
+			},
+		}, func() {}, nil
+	})
+
+	results, err := r.Apply(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Ints(sizes)
+	if fmt.Sprint(sizes) != "[1 2 2]" {
+		t.Errorf("expected batches of at most 2 entries, got %v", sizes)
+	}
+	for _, res := range results {
+		if (res.Error != "") != (res.User == "e") {
+			t.Errorf("unexpected result for %s: %+v", res.User, res)
+		}
+	}
+}
+
+func TestApplyHandler(t *testing.T) {
+	r := newTestRecommender([]Usage{{Principal: Principal{User: "alice"}, ProduceByteRate: 4096}})
+	_ = r.Sample(context.Background(), time.Now())
+	r.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{}, func() {}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/quotas/recommendations/apply?dryRun=true", strings.NewReader(`{"principals":[{"user":"alice"}]}`))
+	w := httptest.NewRecorder()
+	r.ApplyHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"dryRun":true`) {
+		t.Errorf("expected dry run response, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/quotas/recommendations/apply", strings.NewReader(`{not json`))
+	w = httptest.NewRecorder()
+	r.ApplyHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid body, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	if got := percentile(values, 0.95); got != 10 {
+		t.Errorf("expected p95=10, got %v", got)
+	}
+	if got := percentile(values, 0.5); got != 5 {
+		t.Errorf("expected p50=5, got %v", got)
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("expected 0 for empty input, got %v", got)
+	}
+}
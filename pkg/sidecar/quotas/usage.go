@@ -0,0 +1,102 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/jolokia"
+)
+
+const byteRateAttribute = "byte-rate"
+
+// quotaMBeanShapes are the key properties of the quota sensors, which the
+// broker names after the quota entity: user and client ID, user only or client
+// ID only. A JMX pattern only matches names with exactly its keys, so each
+// shape is read separately.
+var quotaMBeanShapes = []string{"user=*,client-id=*", "user=*", "client-id=*"}
+
+// Principal identifies a quota entity (user and/or client ID)
+type Principal struct {
+	User     string `json:"user,omitempty"`
+	ClientID string `json:"clientId,omitempty"`
+}
+
+// String returns a stable key for the principal
+func (p Principal) String() string {
+	return fmt.Sprintf("user=%s,client-id=%s", p.User, p.ClientID)
+}
+
+// Usage is a single throughput observation for a principal
+type Usage struct {
+	Principal       Principal
+	ProduceByteRate float64
+	FetchByteRate   float64
+}
+
+// UsageSource provides point-in-time per-principal throughput observations
+type UsageSource interface {
+	SampleUsage(ctx context.Context) ([]Usage, error)
+}
+
+// JolokiaUsageSource reads per-principal byte rates from the broker's quota MBeans
+type JolokiaUsageSource struct {
+	client *jolokia.Client
+}
+
+// NewJolokiaUsageSource creates a usage source backed by a Jolokia client
+func NewJolokiaUsageSource(client *jolokia.Client) *JolokiaUsageSource {
+	return &JolokiaUsageSource{client: client}
+}
+
+// SampleUsage implements UsageSource
+func (s *JolokiaUsageSource) SampleUsage(ctx context.Context) ([]Usage, error) {
+	produce, err := s.readByteRates(ctx, "Produce")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read produce byte rates: %w", err)
+	}
+	fetch, err := s.readByteRates(ctx, "Fetch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fetch byte rates: %w", err)
+	}
+
+	byPrincipal := make(map[Principal]*Usage)
+	get := func(mbean string) *Usage {
+		_, props := jolokia.ParseObjectName(mbean)
+		p := Principal{User: props["user"], ClientID: props["client-id"]}
+		u, ok := byPrincipal[p]
+		if !ok {
+			u = &Usage{Principal: p}
+			byPrincipal[p] = u
+		}
+		return u
+	}
+
+	for mbean, rate := range produce {
+		get(mbean).ProduceByteRate = rate
+	}
+	for mbean, rate := range fetch {
+		get(mbean).FetchByteRate = rate
+	}
+
+	usage := make([]Usage, 0, len(byPrincipal))
+	for _, u := range byPrincipal {
+		usage = append(usage, *u)
+	}
+	return usage, nil
+}
+
+// readByteRates reads the byte rates of every quota sensor shape of a request
+// type, keyed by MBean name
+func (s *JolokiaUsageSource) readByteRates(ctx context.Context, requestType string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, shape := range quotaMBeanShapes {
+		values, err := s.client.ReadPattern(ctx, "kafka.server:type="+requestType+","+shape, byteRateAttribute)
+		if err != nil {
+			return nil, err
+		}
+		for mbean, rate := range values {
+			rates[mbean] = rate
+		}
+	}
+	return rates, nil
+}
@@ -0,0 +1,83 @@
+package quotas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/jolokia"
+)
+
+func TestJolokiaUsageSource(t *testing.T) {
+	// Quota sensors keyed by user and client ID, user only and client ID only
+	mbeans := map[string]map[string]float64{
+		"kafka.server:type=Produce,user=*,client-id=*": {
+			"kafka.server:client-id=app1,type=Produce,user=alice": 100,
+		},
+		"kafka.server:type=Fetch,user=*,client-id=*": {
+			"kafka.server:client-id=app1,type=Fetch,user=alice": 200,
+			"kafka.server:client-id=,type=Fetch,user=bob":       300,
+		},
+		"kafka.server:type=Produce,user=*": {
+			"kafka.server:type=Produce,user=carol": 400,
+		},
+		"kafka.server:type=Produce,client-id=*": {
+			"kafka.server:client-id=app2,type=Produce": 500,
+		},
+		"kafka.server:type=Fetch,client-id=*": {
+			"kafka.server:client-id=app2,type=Fetch": 600,
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			MBean string `json:"mbean"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		rates, ok := mbeans[req.MBean]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": 404, "error": "javax.management.InstanceNotFoundException"})
+			return
+		}
+		value := map[string]any{}
+		for mbean, rate := range rates {
+			value[mbean] = map[string]any{"byte-rate": rate}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": 200, "value": value})
+	}))
+	defer server.Close()
+
+	source := NewJolokiaUsageSource(jolokia.NewClient(server.URL, time.Second))
+	usage, err := source.SampleUsage(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usage) != 4 {
+		t.Fatalf("expected 4 principals, got %d", len(usage))
+	}
+
+	byPrincipal := make(map[Principal]Usage)
+	for _, u := range usage {
+		byPrincipal[u.Principal] = u
+	}
+
+	alice := byPrincipal[Principal{User: "alice", ClientID: "app1"}]
+	if alice.ProduceByteRate != 100 || alice.FetchByteRate != 200 {
+		t.Errorf("unexpected usage for alice: %+v", alice)
+	}
+	bob := byPrincipal[Principal{User: "bob"}]
+	if bob.FetchByteRate != 300 {
+		t.Errorf("unexpected usage for bob: %+v", bob)
+	}
+	carol := byPrincipal[Principal{User: "carol"}]
+	if carol.ProduceByteRate != 400 {
+		t.Errorf("unexpected usage for carol: %+v", carol)
+	}
+	app2 := byPrincipal[Principal{ClientID: "app2"}]
+	if app2.ProduceByteRate != 500 || app2.FetchByteRate != 600 {
+		t.Errorf("unexpected usage for app2: %+v", app2)
+	}
+}
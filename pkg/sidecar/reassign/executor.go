@@ -0,0 +1,128 @@
+package reassign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// AdminClient defines the Kafka admin operations needed to execute reassignments.
+// This enables mocking in tests.
+type AdminClient interface {
+	AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+// LeaderElector defines the Kafka admin operation needed to elect partition leaders.
+// This enables mocking in tests.
+type LeaderElector interface {
+	ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+}
+
+// Request converts moves into an AlterPartitionAssignments request
+func Request(moves []Move) kadm.AlterPartitionAssignmentsReq {
+	req := make(kadm.AlterPartitionAssignmentsReq)
+	for _, m := range moves {
+		req.Assign(m.Topic, m.Partition, m.Target)
+	}
+	return req
+}
+
+// TopicsSet returns the partitions touched by the moves
+func TopicsSet(moves []Move) kadm.TopicsSet {
+	s := make(kadm.TopicsSet)
+	for _, m := range moves {
+		if s[m.Topic] == nil {
+			s[m.Topic] = make(map[int32]struct{})
+		}
+		s[m.Topic][m.Partition] = struct{}{}
+	}
+	return s
+}
+
+// Execute submits the moves to the controller
+func Execute(ctx context.Context, adm AdminClient, moves []Move) error {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	resp, err := adm.AlterPartitionAssignments(ctx, Request(moves))
+	if err != nil {
+		return fmt.Errorf("failed to alter partition assignments: %w", err)
+	}
+	if err := resp.Error(); err != nil {
+		return fmt.Errorf("partition reassignment rejected: %w", err)
+	}
+	return nil
+}
+
+// WaitForCompletion polls until none of the moved partitions are still reassigning
+func WaitForCompletion(ctx context.Context, clk clock.Clock, adm AdminClient, moves []Move, interval time.Duration) error {
+	set := TopicsSet(moves)
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		inProgress, err := adm.ListPartitionReassignments(ctx, set)
+		if err != nil {
+			return fmt.Errorf("failed to list partition reassignments: %w", err)
+		}
+		if countReassigning(inProgress) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// countReassigning counts partitions that still have replicas being added or removed
+func countReassigning(rs kadm.ListPartitionReassignmentsResponses) int {
+	n := 0
+	rs.Each(func(r kadm.ListPartitionReassignmentsResponse) {
+		if len(r.AddingReplicas) > 0 || len(r.RemovingReplicas) > 0 {
+			n++
+		}
+	})
+	return n
+}
+
+// ElectPreferredLeaders hands leadership of the partitions to their preferred (first)
+// replica. Partitions already led by it are not an error.
+func ElectPreferredLeaders(ctx context.Context, adm LeaderElector, s kadm.TopicsSet) error {
+	if len(s) == 0 {
+		return nil
+	}
+
+	results, err := adm.ElectLeaders(ctx, kadm.ElectPreferredReplica, s)
+	if err != nil {
+		return fmt.Errorf("failed to elect preferred leaders: %w", err)
+	}
+
+	failed := 0
+	var first error
+	for _, partitions := range results {
+		for _, r := range partitions {
+			if r.Err == nil || errors.Is(r.Err, kerr.ElectionNotNeeded) {
+				continue
+			}
+			failed++
+			if first == nil {
+				first = fmt.Errorf("%s-%d: %w", r.Topic, r.Partition, r.Err)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("preferred leader election failed for %d partitions: %w", failed, first)
+	}
+	return nil
+}
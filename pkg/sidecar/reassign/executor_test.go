@@ -0,0 +1,195 @@
+package reassign
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+var testMoves = []Move{
+	{Topic: "orders", Partition: 0, Current: []int32{0, 1}, Target: []int32{2, 1}},
+	{Topic: "orders", Partition: 1, Current: []int32{1, 0}, Target: []int32{1, 2}},
+}
+
+func TestExecute(t *testing.T) {
+	var got kadm.AlterPartitionAssignmentsReq
+	adm := &MockAdminClient{
+		AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+			got = req
+			return kadm.AlterPartitionAssignmentsResponses{}, nil
+		},
+	}
+
+	if err := Execute(context.Background(), adm, testMoves); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got["orders"]) != 2 || got["orders"][0][0] != 2 {
+		t.Errorf("unexpected request: %v", got)
+	}
+
+	if err := Execute(context.Background(), adm, nil); err != nil {
+		t.Errorf("expected no-op for empty moves, got %v", err)
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		adm  *MockAdminClient
+	}{
+		{
+			name: "request error",
+			adm: &MockAdminClient{
+				AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+					return nil, errors.New("connection refused")
+				},
+			},
+		},
+		{
+			name: "partition error",
+			adm: &MockAdminClient{
+				AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+					return kadm.AlterPartitionAssignmentsResponses{
+						"orders": {0: {Topic: "orders", Partition: 0, Err: errors.New("invalid replica assignment")}},
+					}, nil
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Execute(context.Background(), tt.adm, testMoves); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestWaitForCompletion(t *testing.T) {
+	calls := 0
+	adm := &MockAdminClient{
+		ListPartitionReassignmentsFunc: func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+			calls++
+			if !s.Lookup("orders", 0) || !s.Lookup("orders", 1) {
+				t.Errorf("unexpected topics set: %v", s)
+			}
+			if calls < 3 {
+				return kadm.ListPartitionReassignmentsResponses{
+					"orders": {0: {Topic: "orders", Partition: 0, AddingReplicas: []int32{2}}},
+				}, nil
+			}
+			return kadm.ListPartitionReassignmentsResponses{}, nil
+		},
+	}
+
+	if err := WaitForCompletion(context.Background(), clock.Real, adm, testMoves, time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 polls, got %d", calls)
+	}
+}
+
+func TestWaitForCompletionCancelled(t *testing.T) {
+	adm := &MockAdminClient{
+		ListPartitionReassignmentsFunc: func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+			return kadm.ListPartitionReassignmentsResponses{
+				"orders": {0: {Topic: "orders", Partition: 0, RemovingReplicas: []int32{0}}},
+			}, nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitForCompletion(ctx, clock.Real, adm, testMoves, time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+// MockLeaderElector is a mock implementation of LeaderElector for testing
+type MockLeaderElector struct {
+	ElectLeadersFunc func(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+}
+
+func (m *MockLeaderElector) ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+	return m.ElectLeadersFunc(ctx, how, s)
+}
+
+func TestElectPreferredLeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		results     kadm.ElectLeadersResults
+		err         error
+		expectError bool
+	}{
+		{
+			name: "elected",
+			results: kadm.ElectLeadersResults{
+				"orders": {0: {Topic: "orders", Partition: 0}},
+			},
+		},
+		{
+			name: "election not needed",
+			results: kadm.ElectLeadersResults{
+				"orders": {1: {Topic: "orders", Partition: 1, Err: kerr.ElectionNotNeeded}},
+			},
+		},
+		{
+			name: "preferred leader not available",
+			results: kadm.ElectLeadersResults{
+				"orders": {0: {Topic: "orders", Partition: 0, Err: kerr.PreferredLeaderNotAvailable}},
+			},
+			expectError: true,
+		},
+		{
+			name:        "request error",
+			err:         errors.New("connection refused"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adm := &MockLeaderElector{
+				ElectLeadersFunc: func(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+					if how != kadm.ElectPreferredReplica {
+						t.Errorf("expected preferred replica election, got %v", how)
+					}
+					return tt.results, tt.err
+				},
+			}
+
+			err := ElectPreferredLeaders(context.Background(), adm, TopicsSet(testMoves))
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package reassign
+
+import (
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// Move is a single partition reassignment from its current replica set to a target
+type Move struct {
+	Topic     string  `json:"topic"`
+	Partition int32   `json:"partition"`
+	Current   []int32 `json:"current"`
+	Target    []int32 `json:"target"`
+}
+
+// PlanOptions controls which partitions a planner may move
+type PlanOptions struct {
+	// IncludeInternal allows moving partitions of internal topics (__consumer_offsets etc.)
+	IncludeInternal bool
+	// MaxMoves caps the number of moves in a plan (0 = unlimited)
+	MaxMoves int
+}
+
+// ReplicaCounts returns the number of partition replicas hosted by every broker in
+// the metadata, including brokers that host none
+func ReplicaCounts(md kadm.Metadata) map[int32]int {
+	counts := make(map[int32]int, len(md.Brokers))
+	for _, b := range md.Brokers {
+		counts[b.NodeID] = 0
+	}
+	for _, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			for _, r := range p.Replicas {
+				counts[r]++
+			}
+		}
+	}
+	return counts
+}
+
+// PlanOnboarding plans moving a proportional share of replicas onto newBroker.
+// Replicas are taken from the most loaded brokers first, never placing two
+// replicas of a partition on the same broker, never narrowing the racks a
+// partition spans and never touching partitions that are currently
+// under-replicated or offline.
+func PlanOnboarding(md kadm.Metadata, newBroker int32, opts PlanOptions) []Move {
+	counts := ReplicaCounts(md)
+	if _, ok := counts[newBroker]; !ok || len(counts) < 2 {
+		return nil
+	}
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	target := total / len(counts)
+
+	racks := Racks(md)
+	candidates := movablePartitions(md, opts, false)
+	moved := make(map[string]map[int32]bool)
+	var moves []Move
+
+	for counts[newBroker] < target {
+		if opts.MaxMoves > 0 && len(moves) >= opts.MaxMoves {
+			break
+		}
+
+		move, ok := nextOnboardingMove(candidates, counts, racks, moved, newBroker)
+		if !ok {
+			break
+		}
+
+		if moved[move.Topic] == nil {
+			moved[move.Topic] = make(map[int32]bool)
+		}
+		moved[move.Topic][move.Partition] = true
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+// PlanDecommission plans moving every replica off broker onto the least loaded
+// remaining brokers, preferring brokers in racks the partition's other replicas
+// are not in. Every partition hosted by the broker is included, internal or not,
+// since the broker is going away. When no eligible broker is left for a
+// partition the replica is dropped, shrinking that partition's replication factor.
+func PlanDecommission(md kadm.Metadata, broker int32, opts PlanOptions) []Move {
+	counts := ReplicaCounts(md)
+	delete(counts, broker)
+	racks := Racks(md)
+
+	all := movablePartitions(md, PlanOptions{IncludeInternal: true}, true)
+	var moves []Move
+	for _, p := range all {
+		if !contains(p.Replicas, broker) {
+			continue
+		}
+		if opts.MaxMoves > 0 && len(moves) >= opts.MaxMoves {
+			break
+		}
+
+		move := Move{Topic: p.Topic, Partition: p.Partition, Current: p.Replicas}
+		if target, ok := leastLoadedExcluding(counts, p.Replicas, RemoveReplica(p.Replicas, broker), racks); ok {
+			counts[target]++
+			move.Target = ReplaceReplica(p.Replicas, broker, target)
+		} else {
+			move.Target = RemoveReplica(p.Replicas, broker)
+		}
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+// PlanLeadershipExclusion plans reordering every replica list that prefers broker
+// as leader so the broker comes last. Only the order changes, so no data moves;
+// a preferred leader election afterwards hands leadership to the new first replica.
+func PlanLeadershipExclusion(md kadm.Metadata, broker int32) []Move {
+	var moves []Move
+	for _, p := range movablePartitions(md, PlanOptions{IncludeInternal: true}, true) {
+		if len(p.Replicas) < 2 || p.Replicas[0] != broker {
+			continue
+		}
+		moves = append(moves, Move{
+			Topic:     p.Topic,
+			Partition: p.Partition,
+			Current:   p.Replicas,
+			Target:    MoveReplicaLast(p.Replicas, broker),
+		})
+	}
+	return moves
+}
+
+// PlanPreferredLeadership plans making broker the preferred leader of a fair share
+// of the partitions it already replicates, taking preference away from the brokers
+// preferred for the most partitions first. Only replica order changes, and
+// under-replicated or offline partitions are left alone.
+func PlanPreferredLeadership(md kadm.Metadata, broker int32, opts PlanOptions) []Move {
+	preferred := make(map[int32]int, len(md.Brokers))
+	for _, b := range md.Brokers {
+		preferred[b.NodeID] = 0
+	}
+	if _, ok := preferred[broker]; !ok {
+		return nil
+	}
+
+	total := 0
+	for _, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			if len(p.Replicas) > 0 {
+				preferred[p.Replicas[0]]++
+				total++
+			}
+		}
+	}
+	target := total / len(preferred)
+
+	candidates := movablePartitions(md, opts, false)
+	var moves []Move
+	for preferred[broker] < target {
+		if opts.MaxMoves > 0 && len(moves) >= opts.MaxMoves {
+			break
+		}
+
+		found := false
+		for _, donor := range brokersByLoad(preferred) {
+			if donor == broker || preferred[donor] <= preferred[broker]+1 {
+				break
+			}
+			for i, p := range candidates {
+				if len(p.Replicas) == 0 || p.Replicas[0] != donor || !contains(p.Replicas, broker) {
+					continue
+				}
+				preferred[donor]--
+				preferred[broker]++
+				moves = append(moves, Move{
+					Topic:     p.Topic,
+					Partition: p.Partition,
+					Current:   p.Replicas,
+					Target:    MoveReplicaFirst(p.Replicas, broker),
+				})
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				found = true
+				break
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	return moves
+}
+
+// PlanReplicationFactor plans changing every partition of topic to rf replicas.
+// New replicas go to the least loaded brokers not already hosting the partition,
+// preferring racks it does not span yet, and are appended, so preferred
+// leadership is kept. Removals take out-of-sync replicas first, then those
+// sharing a rack with another replica, then those on the most loaded brokers,
+// and never the preferred leader. Partitions already at rf are left alone.
+func PlanReplicationFactor(md kadm.Metadata, topic string, rf int) []Move {
+	detail, ok := md.Topics[topic]
+	if !ok || rf < 1 {
+		return nil
+	}
+	counts := ReplicaCounts(md)
+	racks := Racks(md)
+
+	partitions := make([]kadm.PartitionDetail, 0, len(detail.Partitions))
+	for _, p := range detail.Partitions {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].Partition < partitions[j].Partition
+	})
+
+	var moves []Move
+	for _, p := range partitions {
+		if len(p.Replicas) == rf || len(p.Replicas) == 0 {
+			continue
+		}
+
+		target := append([]int32(nil), p.Replicas...)
+		for len(target) < rf {
+			broker, ok := leastLoadedExcluding(counts, target, target, racks)
+			if !ok {
+				break
+			}
+			counts[broker]++
+			target = append(target, broker)
+		}
+		for len(target) > rf {
+			victim := removalCandidate(target, p.ISR, counts, racks)
+			counts[victim]--
+			target = RemoveReplica(target, victim)
+		}
+
+		if len(target) != len(p.Replicas) {
+			moves = append(moves, Move{Topic: p.Topic, Partition: p.Partition, Current: p.Replicas, Target: target})
+		}
+	}
+
+	return moves
+}
+
+// PlanSpread plans placing every partition of topic round-robin over brokers:
+// partition i is preferred by the i-th broker in ID order, wrapping around, and
+// followed by the next rf-1. With at least as many partitions as brokers, every
+// broker is the preferred leader of a partition. Partitions already placed so
+// are left alone.
+func PlanSpread(md kadm.Metadata, topic string, brokers []int32, rf int) []Move {
+	detail, ok := md.Topics[topic]
+	if !ok || len(brokers) == 0 || rf < 1 {
+		return nil
+	}
+	sorted := append([]int32(nil), brokers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if rf > len(sorted) {
+		rf = len(sorted)
+	}
+
+	partitions := make([]kadm.PartitionDetail, 0, len(detail.Partitions))
+	for _, p := range detail.Partitions {
+		partitions = append(partitions, p)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].Partition < partitions[j].Partition
+	})
+
+	var moves []Move
+	for _, p := range partitions {
+		target := make([]int32, rf)
+		for k := range target {
+			target[k] = sorted[(int(p.Partition)+k)%len(sorted)]
+		}
+		if !equalReplicas(p.Replicas, target) {
+			moves = append(moves, Move{Topic: p.Topic, Partition: p.Partition, Current: p.Replicas, Target: target})
+		}
+	}
+	return moves
+}
+
+// equalReplicas reports whether two replica lists are the same, in order
+func equalReplicas(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// removalCandidate picks the replica to drop when shrinking a partition: an
+// out-of-sync replica if there is one, otherwise the one on the most loaded
+// broker among those sharing a rack with another replica, and failing that
+// among all. The preferred leader (first replica) is never picked.
+func removalCandidate(replicas, isr []int32, counts map[int32]int, racks map[int32]string) int32 {
+	followers := replicas[1:]
+	for i := len(followers) - 1; i >= 0; i-- {
+		if !contains(isr, followers[i]) {
+			return followers[i]
+		}
+	}
+	brokers := brokersByLoad(counts)
+	for _, b := range brokers {
+		if contains(followers, b) && sharesRack(b, replicas, racks) {
+			return b
+		}
+	}
+	for _, b := range brokers {
+		if contains(followers, b) {
+			return b
+		}
+	}
+	return followers[len(followers)-1]
+}
+
+// ShrinksReplication reports whether the move lowers the partition's replication factor
+func (m Move) ShrinksReplication() bool {
+	return len(m.Target) < len(m.Current)
+}
+
+// leastLoadedExcluding returns the broker with the fewest replicas that is not in
+// exclude, preferring one that shares no rack with peers
+func leastLoadedExcluding(counts map[int32]int, exclude, peers []int32, racks map[int32]string) (int32, bool) {
+	brokers := brokersByLoad(counts)
+	for i := len(brokers) - 1; i >= 0; i-- {
+		if !contains(exclude, brokers[i]) && !sharesRack(brokers[i], peers, racks) {
+			return brokers[i], true
+		}
+	}
+	for i := len(brokers) - 1; i >= 0; i-- {
+		if !contains(exclude, brokers[i]) {
+			return brokers[i], true
+		}
+	}
+	return 0, false
+}
+
+// nextOnboardingMove picks the partition whose replica lives on the most loaded
+// donor broker and moves that replica to newBroker, unless that would narrow the
+// racks the partition spans
+func nextOnboardingMove(candidates []kadm.PartitionDetail, counts map[int32]int, racks map[int32]string, moved map[string]map[int32]bool, newBroker int32) (Move, bool) {
+	for _, donor := range brokersByLoad(counts) {
+		// Moving from a broker that is not more loaded than the new one only shuffles skew around
+		if donor == newBroker || counts[donor] <= counts[newBroker]+1 {
+			break
+		}
+
+		for _, p := range candidates {
+			if moved[p.Topic][p.Partition] || !contains(p.Replicas, donor) || contains(p.Replicas, newBroker) {
+				continue
+			}
+			target := ReplaceReplica(p.Replicas, donor, newBroker)
+			if rackSpread(target, racks) < rackSpread(p.Replicas, racks) {
+				continue
+			}
+
+			counts[donor]--
+			counts[newBroker]++
+			return Move{
+				Topic:     p.Topic,
+				Partition: p.Partition,
+				Current:   p.Replicas,
+				Target:    target,
+			}, true
+		}
+	}
+
+	return Move{}, false
+}
+
+// movablePartitions returns partitions in a deterministic order. Unless
+// includeUnhealthy is set, under-replicated and offline partitions are skipped.
+func movablePartitions(md kadm.Metadata, opts PlanOptions, includeUnhealthy bool) []kadm.PartitionDetail {
+	var partitions []kadm.PartitionDetail
+	for _, topic := range md.Topics {
+		if topic.Err != nil || (topic.IsInternal && !opts.IncludeInternal) {
+			continue
+		}
+		for _, p := range topic.Partitions {
+			unhealthy := p.Err != nil || len(p.OfflineReplicas) > 0 || len(p.ISR) < len(p.Replicas)
+			if unhealthy && !includeUnhealthy {
+				continue
+			}
+			partitions = append(partitions, p)
+		}
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Topic != partitions[j].Topic {
+			return partitions[i].Topic < partitions[j].Topic
+		}
+		return partitions[i].Partition < partitions[j].Partition
+	})
+	return partitions
+}
+
+// brokersByLoad returns broker IDs ordered from most to least replicas
+func brokersByLoad(counts map[int32]int) []int32 {
+	brokers := make([]int32, 0, len(counts))
+	for b := range counts {
+		brokers = append(brokers, b)
+	}
+	sort.Slice(brokers, func(i, j int) bool {
+		if counts[brokers[i]] != counts[brokers[j]] {
+			return counts[brokers[i]] > counts[brokers[j]]
+		}
+		return brokers[i] < brokers[j]
+	})
+	return brokers
+}
+
+// ReplaceReplica returns a copy of replicas with from replaced by to, keeping its
+// position so preferred leadership is preserved
+func ReplaceReplica(replicas []int32, from, to int32) []int32 {
+	out := make([]int32, len(replicas))
+	for i, r := range replicas {
+		if r == from {
+			r = to
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// RemoveReplica returns a copy of replicas without id
+func RemoveReplica(replicas []int32, id int32) []int32 {
+	out := make([]int32, 0, len(replicas))
+	for _, r := range replicas {
+		if r != id {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// MoveReplicaFirst returns a copy of replicas with id moved to the front, making
+// it the preferred leader
+func MoveReplicaFirst(replicas []int32, id int32) []int32 {
+	return append([]int32{id}, RemoveReplica(replicas, id)...)
+}
+
+// MoveReplicaLast returns a copy of replicas with id moved to the end, so it is
+// the last choice for preferred leadership
+func MoveReplicaLast(replicas []int32, id int32) []int32 {
+	return append(RemoveReplica(replicas, id), id)
+}
+
+// contains reports whether id is in ids
+func contains(ids []int32, id int32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package reassign
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// testMetadata builds metadata for one topic with the given replica assignments
+func testMetadata(brokers []int32, assignments ...[]int32) kadm.Metadata {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{}}
+	for _, b := range brokers {
+		md.Brokers = append(md.Brokers, kadm.BrokerDetail{NodeID: b})
+	}
+
+	partitions := kadm.PartitionDetails{}
+	for i, replicas := range assignments {
+		partitions[int32(i)] = kadm.PartitionDetail{
+			Topic:     "orders",
+			Partition: int32(i),
+			Leader:    replicas[0],
+			Replicas:  replicas,
+			ISR:       replicas,
+		}
+	}
+	md.Topics["orders"] = kadm.TopicDetail{Topic: "orders", Partitions: partitions}
+	return md
+}
+
+func TestReplicaCounts(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2, 3}, []int32{0, 1}, []int32{1, 2}, []int32{2, 0})
+	counts := ReplicaCounts(md)
+
+	expected := map[int32]int{0: 2, 1: 2, 2: 2, 3: 0}
+	for b, c := range expected {
+		if counts[b] != c {
+			t.Errorf("broker %d: expected %d replicas, got %d", b, c, counts[b])
+		}
+	}
+}
+
+func TestPlanOnboarding(t *testing.T) {
+	// 3 brokers with 6 partitions at RF=2 (12 replicas); broker 3 joins empty.
+	md := testMetadata([]int32{0, 1, 2, 3},
+		[]int32{0, 1}, []int32{1, 2}, []int32{2, 0},
+		[]int32{0, 1}, []int32{1, 2}, []int32{2, 0},
+	)
+
+	moves := PlanOnboarding(md, 3, PlanOptions{})
+	if len(moves) != 3 {
+		t.Fatalf("expected 3 moves (12 replicas / 4 brokers), got %d", len(moves))
+	}
+
+	counts := ReplicaCounts(md)
+	for _, m := range moves {
+		for _, r := range m.Current {
+			counts[r]--
+		}
+		for _, r := range m.Target {
+			counts[r]++
+		}
+		if !contains(m.Target, 3) {
+			t.Errorf("move %s-%d does not place a replica on the new broker", m.Topic, m.Partition)
+		}
+		if len(m.Target) != len(m.Current) {
+			t.Errorf("move %s-%d changes replication factor", m.Topic, m.Partition)
+		}
+	}
+	for b, c := range counts {
+		if c != 3 {
+			t.Errorf("broker %d: expected balanced 3 replicas after plan, got %d", b, c)
+		}
+	}
+}
+
+func TestPlanOnboardingSkipsUnsafePartitions(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2}, []int32{0, 1}, []int32{0, 1}, []int32{0, 1})
+
+	// Partition 0 is under-replicated, partition 1 has an offline replica
+	p0 := md.Topics["orders"].Partitions[0]
+	p0.ISR = []int32{0}
+	md.Topics["orders"].Partitions[0] = p0
+	p1 := md.Topics["orders"].Partitions[1]
+	p1.OfflineReplicas = []int32{1}
+	md.Topics["orders"].Partitions[1] = p1
+
+	// Internal topics are skipped by default
+	md.Topics["__consumer_offsets"] = kadm.TopicDetail{
+		Topic:      "__consumer_offsets",
+		IsInternal: true,
+		Partitions: kadm.PartitionDetails{
+			0: {Topic: "__consumer_offsets", Partition: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+		},
+	}
+
+	moves := PlanOnboarding(md, 2, PlanOptions{})
+	if len(moves) != 1 || moves[0].Partition != 2 || moves[0].Topic != "orders" {
+		t.Fatalf("expected only orders-2 to move, got %+v", moves)
+	}
+
+	moves = PlanOnboarding(md, 2, PlanOptions{IncludeInternal: true})
+	if len(moves) != 2 {
+		t.Errorf("expected internal partition to be movable when included, got %+v", moves)
+	}
+}
+
+func TestPlanOnboardingMaxMoves(t *testing.T) {
+	var assignments [][]int32
+	for i := 0; i < 20; i++ {
+		assignments = append(assignments, []int32{0, 1})
+	}
+	md := testMetadata([]int32{0, 1, 2}, assignments...)
+
+	moves := PlanOnboarding(md, 2, PlanOptions{MaxMoves: 5})
+	if len(moves) != 5 {
+		t.Errorf("expected plan capped at 5 moves, got %d", len(moves))
+	}
+}
+
+func TestPlanOnboardingNothingToDo(t *testing.T) {
+	tests := []struct {
+		name   string
+		md     kadm.Metadata
+		broker int32
+	}{
+		{
+			name:   "broker not registered",
+			md:     testMetadata([]int32{0, 1}, []int32{0, 1}),
+			broker: 5,
+		},
+		{
+			name:   "single broker cluster",
+			md:     testMetadata([]int32{0}),
+			broker: 0,
+		},
+		{
+			name:   "empty cluster",
+			md:     testMetadata([]int32{0, 1, 2}),
+			broker: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if moves := PlanOnboarding(tt.md, tt.broker, PlanOptions{}); len(moves) != 0 {
+				t.Errorf("expected no moves, got %d", len(moves))
+			}
+		})
+	}
+}
+
+func TestReplaceReplica(t *testing.T) {
+	got := ReplaceReplica([]int32{0, 1, 2}, 1, 5)
+	if fmt.Sprint(got) != "[0 5 2]" {
+		t.Errorf("expected [0 5 2], got %v", got)
+	}
+}
+
+func TestPlanDecommission(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2, 3},
+		[]int32{0, 1}, []int32{1, 2}, []int32{2, 0}, []int32{0, 3},
+	)
+
+	moves := PlanDecommission(md, 0, PlanOptions{})
+	if len(moves) != 3 {
+		t.Fatalf("expected 3 moves off broker 0, got %d", len(moves))
+	}
+	for _, m := range moves {
+		if contains(m.Target, 0) {
+			t.Errorf("move %s-%d keeps a replica on the decommissioned broker", m.Topic, m.Partition)
+		}
+		if m.ShrinksReplication() {
+			t.Errorf("move %s-%d shrinks replication despite spare brokers", m.Topic, m.Partition)
+		}
+	}
+}
+
+func TestPlanDecommissionShrinksWhenNoSpareBroker(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2}, []int32{0, 1, 2})
+
+	moves := PlanDecommission(md, 2, PlanOptions{})
+	if len(moves) != 1 {
+		t.Fatalf("expected 1 move, got %d", len(moves))
+	}
+	if !moves[0].ShrinksReplication() {
+		t.Fatal("expected replication factor to shrink")
+	}
+	if fmt.Sprint(moves[0].Target) != "[0 1]" {
+		t.Errorf("expected target [0 1], got %v", moves[0].Target)
+	}
+}
+
+func TestRemoveReplica(t *testing.T) {
+	got := RemoveReplica([]int32{0, 1, 2}, 1)
+	if fmt.Sprint(got) != "[0 2]" {
+		t.Errorf("expected [0 2], got %v", got)
+	}
+}
+
+func TestPlanLeadershipExclusion(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2},
+		[]int32{2, 0, 1}, []int32{0, 2}, []int32{1, 0}, []int32{2},
+	)
+
+	moves := PlanLeadershipExclusion(md, 2)
+	if len(moves) != 1 {
+		t.Fatalf("expected 1 move (single-replica partitions cannot exclude), got %d", len(moves))
+	}
+	if fmt.Sprint(moves[0].Target) != "[0 1 2]" {
+		t.Errorf("expected target [0 1 2], got %v", moves[0].Target)
+	}
+}
+
+func TestPlanPreferredLeadership(t *testing.T) {
+	// Broker 2 replicates every partition but is preferred for none
+	md := testMetadata([]int32{0, 1, 2},
+		[]int32{0, 1, 2}, []int32{0, 1, 2}, []int32{0, 1, 2},
+		[]int32{1, 0, 2}, []int32{1, 0, 2}, []int32{1, 0, 2},
+	)
+
+	moves := PlanPreferredLeadership(md, 2, PlanOptions{})
+	if len(moves) != 2 {
+		t.Fatalf("expected 2 moves (6 partitions / 3 brokers), got %d", len(moves))
+	}
+
+	preferred := map[int32]int{}
+	for _, m := range moves {
+		if m.Target[0] != 2 {
+			t.Errorf("move %s-%d does not prefer broker 2: %v", m.Topic, m.Partition, m.Target)
+		}
+		if len(m.Target) != len(m.Current) {
+			t.Errorf("move %s-%d changes replication factor", m.Topic, m.Partition)
+		}
+		preferred[m.Current[0]]++
+	}
+	if preferred[0] != 1 || preferred[1] != 1 {
+		t.Errorf("expected preference taken from brokers 0 and 1 evenly, got %v", preferred)
+	}
+}
+
+func TestMoveReplica(t *testing.T) {
+	if got := MoveReplicaFirst([]int32{0, 1, 2}, 2); fmt.Sprint(got) != "[2 0 1]" {
+		t.Errorf("expected [2 0 1], got %v", got)
+	}
+	if got := MoveReplicaLast([]int32{0, 1, 2}, 0); fmt.Sprint(got) != "[1 2 0]" {
+		t.Errorf("expected [1 2 0], got %v", got)
+	}
+}
+
+func TestPlanReplicationFactorRaise(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2, 3}, []int32{0, 1}, []int32{1, 2})
+
+	moves := PlanReplicationFactor(md, "orders", 3)
+	if len(moves) != 2 {
+		t.Fatalf("expected 2 moves, got %d", len(moves))
+	}
+	for _, m := range moves {
+		if len(m.Target) != 3 {
+			t.Errorf("partition %d: expected 3 replicas, got %v", m.Partition, m.Target)
+		}
+		if m.Target[0] != m.Current[0] {
+			t.Errorf("partition %d: preferred leader changed from %d to %d", m.Partition, m.Current[0], m.Target[0])
+		}
+	}
+	// Broker 3 hosts nothing, so it takes the first new replica
+	if fmt.Sprint(moves[0].Target) != "[0 1 3]" {
+		t.Errorf("expected target [0 1 3], got %v", moves[0].Target)
+	}
+}
+
+func TestPlanReplicationFactorLower(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2}, []int32{0, 1, 2}, []int32{1, 2, 0})
+	// Partition 1 has an out-of-sync replica on broker 0
+	p := md.Topics["orders"].Partitions[1]
+	p.ISR = []int32{1, 2}
+	md.Topics["orders"].Partitions[1] = p
+
+	moves := PlanReplicationFactor(md, "orders", 2)
+	if len(moves) != 2 {
+		t.Fatalf("expected 2 moves, got %d", len(moves))
+	}
+	for _, m := range moves {
+		if !m.ShrinksReplication() || m.Target[0] != m.Current[0] {
+			t.Errorf("partition %d: expected shrink keeping the preferred leader, got %v", m.Partition, m.Target)
+		}
+	}
+	if fmt.Sprint(moves[1].Target) != "[1 2]" {
+		t.Errorf("expected the out-of-sync replica to be removed, got %v", moves[1].Target)
+	}
+}
+
+func TestPlanReplicationFactorNothingToDo(t *testing.T) {
+	md := testMetadata([]int32{0, 1, 2}, []int32{0, 1})
+
+	if moves := PlanReplicationFactor(md, "orders", 2); len(moves) != 0 {
+		t.Errorf("expected no moves at the current replication factor, got %d", len(moves))
+	}
+	if moves := PlanReplicationFactor(md, "missing", 3); len(moves) != 0 {
+		t.Errorf("expected no moves for an unknown topic, got %d", len(moves))
+	}
+}
+
+func TestPlanSpread(t *testing.T) {
+	// Broker 2 left and broker 3 joined; partition 3 was added for broker 3
+	md := testMetadata([]int32{0, 1, 3},
+		[]int32{0, 1}, []int32{1, 2}, []int32{2, 0}, []int32{0, 1},
+	)
+
+	moves := PlanSpread(md, "orders", []int32{3, 0, 1}, 2)
+	expected := map[int32][]int32{1: {1, 3}, 2: {3, 0}}
+	if len(moves) != 2 {
+		t.Fatalf("expected 2 moves, got %+v", moves)
+	}
+	for _, m := range moves {
+		if fmt.Sprint(m.Target) != fmt.Sprint(expected[m.Partition]) {
+			t.Errorf("partition %d: expected %v, got %v", m.Partition, expected[m.Partition], m.Target)
+		}
+	}
+}
+
+func TestPlanSpreadCapsReplicationFactor(t *testing.T) {
+	md := testMetadata([]int32{0, 1}, []int32{0}, []int32{1})
+
+	if moves := PlanSpread(md, "orders", []int32{0, 1}, 1); len(moves) != 0 {
+		t.Errorf("expected no moves for a spread topic, got %+v", moves)
+	}
+	moves := PlanSpread(md, "orders", []int32{0, 1}, 3)
+	if len(moves) != 2 || len(moves[0].Target) != 2 {
+		t.Errorf("expected both partitions raised to 2 replicas, got %+v", moves)
+	}
+}
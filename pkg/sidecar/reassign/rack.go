@@ -0,0 +1,40 @@
+package reassign
+
+import "github.com/twmb/franz-go/pkg/kadm"
+
+// Racks returns the broker.rack of every broker in the metadata that has one
+func Racks(md kadm.Metadata) map[int32]string {
+	racks := make(map[int32]string, len(md.Brokers))
+	for _, b := range md.Brokers {
+		if b.Rack != nil && *b.Rack != "" {
+			racks[b.NodeID] = *b.Rack
+		}
+	}
+	return racks
+}
+
+// rackSpread returns the number of distinct racks replicas span. Brokers without
+// a rack are not counted.
+func rackSpread(replicas []int32, racks map[int32]string) int {
+	spanned := map[string]bool{}
+	for _, r := range replicas {
+		if rack, ok := racks[r]; ok {
+			spanned[rack] = true
+		}
+	}
+	return len(spanned)
+}
+
+// sharesRack reports whether broker is in the same rack as any of peers
+func sharesRack(broker int32, peers []int32, racks map[int32]string) bool {
+	rack, ok := racks[broker]
+	if !ok {
+		return false
+	}
+	for _, p := range peers {
+		if p != broker && racks[p] == rack {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package reassign
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// withRacks sets the broker.rack of the brokers in md
+func withRacks(md kadm.Metadata, racks map[int32]string) kadm.Metadata {
+	for i, b := range md.Brokers {
+		if rack, ok := racks[b.NodeID]; ok {
+			md.Brokers[i].Rack = &rack
+		}
+	}
+	return md
+}
+
+func TestRacks(t *testing.T) {
+	md := withRacks(testMetadata([]int32{0, 1, 2}), map[int32]string{0: "a", 1: ""})
+	if racks := Racks(md); len(racks) != 1 || racks[0] != "a" {
+		t.Errorf("expected only broker 0's rack, got %v", racks)
+	}
+}
+
+func TestPlanOnboardingKeepsRackSpread(t *testing.T) {
+	// Broker 2 joins rack a, where broker 0 already is
+	md := withRacks(testMetadata([]int32{0, 1, 2},
+		[]int32{0, 1}, []int32{0, 1}, []int32{0, 1}, []int32{0, 1},
+	), map[int32]string{0: "a", 1: "b", 2: "a"})
+
+	moves := PlanOnboarding(md, 2, PlanOptions{})
+	if len(moves) == 0 {
+		t.Fatal("expected moves onto the new broker")
+	}
+	for _, m := range moves {
+		if !contains(m.Target, 1) {
+			t.Errorf("move %s-%d leaves rack b: %v", m.Topic, m.Partition, m.Target)
+		}
+	}
+}
+
+func TestPlanDecommissionPrefersOtherRacks(t *testing.T) {
+	md := withRacks(testMetadata([]int32{0, 1, 2, 3}, []int32{0, 1}, []int32{3}),
+		map[int32]string{0: "a", 1: "b", 2: "a", 3: "b"})
+
+	moves := PlanDecommission(md, 1, PlanOptions{})
+	if len(moves) != 1 {
+		t.Fatalf("expected 1 move, got %d", len(moves))
+	}
+	// Broker 2 is less loaded but in broker 0's rack
+	if fmt.Sprint(moves[0].Target) != "[0 3]" {
+		t.Errorf("expected target [0 3], got %v", moves[0].Target)
+	}
+}
+
+func TestPlanReplicationFactorRackAware(t *testing.T) {
+	racks := map[int32]string{0: "a", 1: "a", 2: "b"}
+
+	md := withRacks(testMetadata([]int32{0, 1, 2}, []int32{0}, []int32{2, 0}), racks)
+	moves := PlanReplicationFactor(md, "orders", 2)
+	if len(moves) != 1 || fmt.Sprint(moves[0].Target) != "[0 2]" {
+		t.Errorf("expected the new replica in rack b, got %v", moves)
+	}
+
+	md = withRacks(testMetadata([]int32{0, 1, 2}, []int32{0, 1, 2}, []int32{2, 0}), racks)
+	moves = PlanReplicationFactor(md, "orders", 2)
+	if len(moves) != 1 || fmt.Sprint(moves[0].Target) != "[0 2]" {
+		t.Errorf("expected the replica sharing rack a to be removed, got %v", moves)
+	}
+}
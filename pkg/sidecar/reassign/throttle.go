@@ -0,0 +1,178 @@
+package reassign
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+const (
+	leaderThrottledRate       = "leader.replication.throttled.rate"
+	followerThrottledRate     = "follower.replication.throttled.rate"
+	leaderThrottledReplicas   = "leader.replication.throttled.replicas"
+	followerThrottledReplicas = "follower.replication.throttled.replicas"
+)
+
+// ThrottleClient defines the Kafka admin operations needed to throttle reassignment
+// traffic. This enables mocking in tests.
+type ThrottleClient interface {
+	AlterBrokerConfigs(ctx context.Context, configs []kadm.AlterConfig, brokers ...int32) (kadm.AlterConfigsResponses, error)
+	AlterTopicConfigs(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error)
+}
+
+// ApplyThrottle limits the replication traffic of the moves to rate bytes/sec per
+// broker, the same way kafka-reassign-partitions --throttle does: existing replicas
+// are throttled as leaders and added replicas as followers. Moves that add no
+// replicas copy no data and are not throttled. A rate of zero or less is a no-op.
+func ApplyThrottle(ctx context.Context, adm ThrottleClient, moves []Move, rate int64) error {
+	leaders, followers := throttledReplicas(moves)
+	if rate <= 0 || len(followers) == 0 {
+		return nil
+	}
+
+	value := strconv.FormatInt(rate, 10)
+	rates := []kadm.AlterConfig{
+		{Op: kadm.SetConfig, Name: leaderThrottledRate, Value: &value},
+		{Op: kadm.SetConfig, Name: followerThrottledRate, Value: &value},
+	}
+	if err := alterBrokers(ctx, adm, rates, throttledBrokers(moves)); err != nil {
+		return fmt.Errorf("failed to set replication throttle rate: %w", err)
+	}
+
+	for _, topic := range sortedKeys(followers) {
+		leaderValue := strings.Join(leaders[topic], ",")
+		followerValue := strings.Join(followers[topic], ",")
+		replicas := []kadm.AlterConfig{
+			{Op: kadm.SetConfig, Name: leaderThrottledReplicas, Value: &leaderValue},
+			{Op: kadm.SetConfig, Name: followerThrottledReplicas, Value: &followerValue},
+		}
+		if err := alterTopic(ctx, adm, replicas, topic); err != nil {
+			return fmt.Errorf("failed to set throttled replicas on topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// ClearThrottle removes the throttles set by ApplyThrottle for the moves
+func ClearThrottle(ctx context.Context, adm ThrottleClient, moves []Move) error {
+	_, followers := throttledReplicas(moves)
+	if len(followers) == 0 {
+		return nil
+	}
+
+	for _, topic := range sortedKeys(followers) {
+		replicas := []kadm.AlterConfig{
+			{Op: kadm.DeleteConfig, Name: leaderThrottledReplicas},
+			{Op: kadm.DeleteConfig, Name: followerThrottledReplicas},
+		}
+		if err := alterTopic(ctx, adm, replicas, topic); err != nil {
+			return fmt.Errorf("failed to clear throttled replicas on topic %s: %w", topic, err)
+		}
+	}
+
+	rates := []kadm.AlterConfig{
+		{Op: kadm.DeleteConfig, Name: leaderThrottledRate},
+		{Op: kadm.DeleteConfig, Name: followerThrottledRate},
+	}
+	if err := alterBrokers(ctx, adm, rates, throttledBrokers(moves)); err != nil {
+		return fmt.Errorf("failed to clear replication throttle rate: %w", err)
+	}
+	return nil
+}
+
+// throttledReplicas returns, per topic with added replicas, the "partition:broker"
+// entries to throttle as leaders (current replicas) and followers (added replicas)
+func throttledReplicas(moves []Move) (map[string][]string, map[string][]string) {
+	leaders := make(map[string][]string)
+	followers := make(map[string][]string)
+	for _, m := range moves {
+		var added []int32
+		for _, r := range m.Target {
+			if !contains(m.Current, r) {
+				added = append(added, r)
+			}
+		}
+		if len(added) == 0 {
+			continue
+		}
+		for _, r := range m.Current {
+			leaders[m.Topic] = append(leaders[m.Topic], fmt.Sprintf("%d:%d", m.Partition, r))
+		}
+		for _, r := range added {
+			followers[m.Topic] = append(followers[m.Topic], fmt.Sprintf("%d:%d", m.Partition, r))
+		}
+	}
+	return leaders, followers
+}
+
+// throttledBrokers returns every broker taking part in a move that adds replicas
+func throttledBrokers(moves []Move) []int32 {
+	seen := make(map[int32]bool)
+	var brokers []int32
+	for _, m := range moves {
+		if sameReplicas(m.Current, m.Target) {
+			continue
+		}
+		for _, r := range append(append([]int32(nil), m.Current...), m.Target...) {
+			if !seen[r] {
+				seen[r] = true
+				brokers = append(brokers, r)
+			}
+		}
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i] < brokers[j] })
+	return brokers
+}
+
+// sameReplicas reports whether every replica of target is already in current
+func sameReplicas(current, target []int32) bool {
+	for _, r := range target {
+		if !contains(current, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func alterBrokers(ctx context.Context, adm ThrottleClient, configs []kadm.AlterConfig, brokers []int32) error {
+	resp, err := adm.AlterBrokerConfigs(ctx, configs, brokers...)
+	if err != nil {
+		return err
+	}
+	return alterConfigsError(resp)
+}
+
+func alterTopic(ctx context.Context, adm ThrottleClient, configs []kadm.AlterConfig, topic string) error {
+	resp, err := adm.AlterTopicConfigs(ctx, configs, topic)
+	if err != nil {
+		return err
+	}
+	return alterConfigsError(resp)
+}
+
+// alterConfigsError returns the first error in an alter configs response
+func alterConfigsError(resp kadm.AlterConfigsResponses) error {
+	for _, r := range resp {
+		if r.Err == nil {
+			continue
+		}
+		if r.ErrMessage != "" {
+			return fmt.Errorf("%s: %w: %s", r.Name, r.Err, r.ErrMessage)
+		}
+		return fmt.Errorf("%s: %w", r.Name, r.Err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+package reassign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// MockThrottleClient is a mock implementation of ThrottleClient for testing
+type MockThrottleClient struct {
+	AlterBrokerConfigsFunc func(ctx context.Context, configs []kadm.AlterConfig, brokers ...int32) (kadm.AlterConfigsResponses, error)
+	AlterTopicConfigsFunc  func(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error)
+}
+
+func (m *MockThrottleClient) AlterBrokerConfigs(ctx context.Context, configs []kadm.AlterConfig, brokers ...int32) (kadm.AlterConfigsResponses, error) {
+	if m.AlterBrokerConfigsFunc != nil {
+		return m.AlterBrokerConfigsFunc(ctx, configs, brokers...)
+	}
+	return kadm.AlterConfigsResponses{}, nil
+}
+
+func (m *MockThrottleClient) AlterTopicConfigs(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error) {
+	if m.AlterTopicConfigsFunc != nil {
+		return m.AlterTopicConfigsFunc(ctx, configs, topics...)
+	}
+	return kadm.AlterConfigsResponses{}, nil
+}
+
+func TestApplyThrottle(t *testing.T) {
+	moves := []Move{
+		{Topic: "orders", Partition: 0, Current: []int32{0, 1}, Target: []int32{0, 1, 2}},
+		// Shrinking copies no data and is not throttled
+		{Topic: "orders", Partition: 1, Current: []int32{1, 2, 3}, Target: []int32{1, 2}},
+	}
+
+	var brokers []int32
+	topicConfigs := map[string]string{}
+	adm := &MockThrottleClient{
+		AlterBrokerConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, b ...int32) (kadm.AlterConfigsResponses, error) {
+			brokers = b
+			if *configs[0].Value != "1048576" {
+				t.Errorf("expected rate 1048576, got %s", *configs[0].Value)
+			}
+			return nil, nil
+		},
+		AlterTopicConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, _ ...string) (kadm.AlterConfigsResponses, error) {
+			for _, c := range configs {
+				topicConfigs[c.Name] = *c.Value
+			}
+			return nil, nil
+		},
+	}
+
+	if err := ApplyThrottle(context.Background(), adm, moves, 1048576); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(brokers) != "[0 1 2]" {
+		t.Errorf("expected brokers [0 1 2] throttled, got %v", brokers)
+	}
+	if topicConfigs[leaderThrottledReplicas] != "0:0,0:1" {
+		t.Errorf("unexpected leader throttled replicas: %q", topicConfigs[leaderThrottledReplicas])
+	}
+	if topicConfigs[followerThrottledReplicas] != "0:2" {
+		t.Errorf("unexpected follower throttled replicas: %q", topicConfigs[followerThrottledReplicas])
+	}
+}
+
+func TestApplyThrottleNoop(t *testing.T) {
+	adm := &MockThrottleClient{
+		AlterBrokerConfigsFunc: func(context.Context, []kadm.AlterConfig, ...int32) (kadm.AlterConfigsResponses, error) {
+			t.Error("expected no broker config changes")
+			return nil, nil
+		},
+	}
+
+	grow := []Move{{Topic: "orders", Partition: 0, Current: []int32{0}, Target: []int32{0, 1}}}
+	if err := ApplyThrottle(context.Background(), adm, grow, 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	shrink := []Move{{Topic: "orders", Partition: 0, Current: []int32{0, 1}, Target: []int32{0}}}
+	if err := ApplyThrottle(context.Background(), adm, shrink, 1024); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClearThrottle(t *testing.T) {
+	moves := []Move{{Topic: "orders", Partition: 0, Current: []int32{0, 1}, Target: []int32{0, 1, 2}}}
+
+	var deleted []string
+	adm := &MockThrottleClient{
+		AlterBrokerConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, _ ...int32) (kadm.AlterConfigsResponses, error) {
+			for _, c := range configs {
+				if c.Op != kadm.DeleteConfig {
+					t.Errorf("expected delete of %s", c.Name)
+				}
+				deleted = append(deleted, c.Name)
+			}
+			return nil, nil
+		},
+		AlterTopicConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, _ ...string) (kadm.AlterConfigsResponses, error) {
+			for _, c := range configs {
+				deleted = append(deleted, c.Name)
+			}
+			return nil, nil
+		},
+	}
+
+	if err := ClearThrottle(context.Background(), adm, moves); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deleted) != 4 {
+		t.Errorf("expected 4 throttle configs removed, got %v", deleted)
+	}
+}
+
+func TestApplyThrottleError(t *testing.T) {
+	moves := []Move{{Topic: "orders", Partition: 0, Current: []int32{0}, Target: []int32{0, 1}}}
+	adm := &MockThrottleClient{
+		AlterBrokerConfigsFunc: func(context.Context, []kadm.AlterConfig, ...int32) (kadm.AlterConfigsResponses, error) {
+			return kadm.AlterConfigsResponses{{Name: "0", Err: kerr.PolicyViolation}}, nil
+		},
+	}
+
+	err := ApplyThrottle(context.Background(), adm, moves, 1024)
+	if !errors.Is(err, kerr.PolicyViolation) {
+		t.Errorf("expected PolicyViolation, got %v", err)
+	}
+}
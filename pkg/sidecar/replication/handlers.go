@@ -0,0 +1,82 @@
+package replication
+
+import (
+	"net/http"
+	"strconv"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/gorilla/mux"
+)
+
+// StartRequest is the body of a replication factor change request
+type StartRequest struct {
+	ReplicationFactor int `json:"replicationFactor"`
+	// ThrottleBytesPerSec overrides the default replication throttle (0 = unthrottled)
+	ThrottleBytesPerSec *int64 `json:"throttleBytesPerSec,omitempty"`
+	// RequestedBy identifies the operator for the log
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// StartResponse represents the response for the start endpoint
+type StartResponse struct {
+	Plan   Plan   `json:"plan"`
+	Status Status `json:"status"`
+}
+
+// PlanHandler handles GET /topics/{name}/replication-factor/plan?replicationFactor=N requests
+func (c *Changer) PlanHandler(w http.ResponseWriter, req *http.Request) {
+	raw := req.URL.Query().Get("replicationFactor")
+	rf, err := strconv.Atoi(raw)
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid replicationFactor: %q", raw))
+		return
+	}
+
+	plan, err := c.Plan(req.Context(), mux.Vars(req)["name"], rf)
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to plan replication factor change", err))
+		return
+	}
+	_, _ = web.ReturnResponse(w, plan)
+}
+
+// StartHandler handles POST /topics/{name}/replication-factor requests
+func (c *Changer) StartHandler(w http.ResponseWriter, req *http.Request) {
+	body, err := web.ParseJsonRequestBody[StartRequest](req)
+	if err != nil {
+		_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+		return
+	}
+
+	plan, err := c.Start(req.Context(), mux.Vars(req)["name"], body.ReplicationFactor, StartOptions{
+		ThrottleRate: body.ThrottleBytesPerSec,
+		Actor:        actor(req, body.RequestedBy),
+	})
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to start replication factor change", err))
+		return
+	}
+	_, _ = web.ReturnResponseWithCode(w, StartResponse{Plan: plan, Status: c.Status()}, http.StatusAccepted)
+}
+
+// StatusHandler handles GET /topics/replication-factor requests
+func (c *Changer) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, c.Status())
+}
+
+// actor identifies the requester, falling back to the remote address
+func actor(req *http.Request, requestedBy string) string {
+	if requestedBy != "" {
+		return requestedBy
+	}
+	return req.RemoteAddr
+}
+
+// wrapError passes domain errors through and reports anything else as internal
+func wrapError(msg string, err error) error {
+	if cplnErrors.IsDomainError(err) {
+		return err
+	}
+	return cplnErrors.Internal(msg, err)
+}
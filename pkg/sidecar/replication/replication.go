@@ -0,0 +1,416 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+const minISRKey = "min.insync.replicas"
+
+// State is the state of the replication factor change workflow
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateMoving    State = "moving"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+)
+
+// AdminClient defines the Kafka admin operations needed to change replication factors.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	reassign.AdminClient
+	reassign.ThrottleClient
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the replication factor change workflow
+type Options struct {
+	// BatchSize is the number of partitions changed per reassignment batch
+	BatchSize int
+	// ThrottleRate is the default replication throttle in bytes/sec per broker
+	// while replicas are added (0 = unthrottled)
+	ThrottleRate int64
+	// PollInterval is how often in-flight reassignments are polled
+	PollInterval time.Duration
+	// Timeout bounds each metadata and config request
+	Timeout time.Duration
+}
+
+// Plan describes the work needed to change a topic's replication factor
+type Plan struct {
+	Topic                    string          `json:"topic"`
+	CurrentReplicationFactor int             `json:"currentReplicationFactor"`
+	TargetReplicationFactor  int             `json:"targetReplicationFactor"`
+	MinISR                   int             `json:"minInsyncReplicas"`
+	Moves                    []reassign.Move `json:"moves"`
+}
+
+// StartOptions carries the operator's decisions for a replication factor change
+type StartOptions struct {
+	// ThrottleRate overrides the default throttle in bytes/sec when set (0 = unthrottled)
+	ThrottleRate *int64
+	// Actor identifies who requested the change, for the log
+	Actor string
+}
+
+// Status is the observable progress of the replication factor change workflow
+type Status struct {
+	State                   State      `json:"state"`
+	Topic                   string     `json:"topic,omitempty"`
+	TargetReplicationFactor int        `json:"targetReplicationFactor,omitempty"`
+	ThrottleRate            int64      `json:"throttleRate,omitempty"`
+	Message                 string     `json:"message,omitempty"`
+	PlannedMoves            int        `json:"plannedMoves"`
+	CompletedMoves          int        `json:"completedMoves"`
+	StartedAt               *time.Time `json:"startedAt,omitempty"`
+	FinishedAt              *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Changer raises or lowers a topic's replication factor by reassigning its
+// partitions in throttled batches, checking min.insync.replicas before and
+// after every batch
+type Changer struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewChanger creates a new replication factor change workflow
+func NewChanger(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Changer {
+	c := &Changer{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		status:      Status{State: StateIdle},
+	}
+	// Set default client factory
+	c.clientFactory = c.defaultClientFactory
+	return c
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (c *Changer) SetClientFactory(factory ClientFactory) {
+	c.clientFactory = factory
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (c *Changer) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (c *Changer) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(c.kafkaConfig)
+}
+
+// Status returns a snapshot of the workflow status
+func (c *Changer) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Plan computes the moves needed to bring every partition of the topic to the
+// target replication factor
+func (c *Changer) Plan(ctx context.Context, topic string, rf int) (Plan, error) {
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return Plan{}, err
+	}
+	defer cleanup()
+
+	return c.plan(ctx, adm, topic, rf)
+}
+
+func (c *Changer) plan(ctx context.Context, adm AdminClient, topic string, rf int) (Plan, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx, topic)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	detail, ok := md.Topics[topic]
+	if !ok || detail.Err != nil {
+		return Plan{}, cplnErrors.NotFound("topic", topic)
+	}
+	if rf < 1 {
+		return Plan{}, cplnErrors.Validationf("replication factor must be at least 1, got %d", rf)
+	}
+	if rf > len(md.Brokers) {
+		return Plan{}, cplnErrors.Validationf("replication factor %d exceeds the %d brokers in the cluster", rf, len(md.Brokers))
+	}
+	for _, p := range detail.Partitions {
+		if p.Err != nil || p.Leader < 0 {
+			return Plan{}, cplnErrors.Conflictf("partition %s-%d is offline", topic, p.Partition)
+		}
+	}
+
+	minISR, err := c.minISR(ctx, adm, topic)
+	if err != nil {
+		return Plan{}, err
+	}
+	if rf < minISR {
+		return Plan{}, cplnErrors.Conflictf(
+			"replication factor %d is below min.insync.replicas %d of topic %s; lower min.insync.replicas first",
+			rf, minISR, topic)
+	}
+
+	return Plan{
+		Topic:                    topic,
+		CurrentReplicationFactor: replicationFactor(detail),
+		TargetReplicationFactor:  rf,
+		MinISR:                   minISR,
+		Moves:                    reassign.PlanReplicationFactor(md, topic, rf),
+	}, nil
+}
+
+// Start plans the change and executes it in the background
+func (c *Changer) Start(ctx context.Context, topic string, rf int, opts StartOptions) (Plan, error) {
+	if s := c.Status(); s.State == StateMoving {
+		return Plan{}, cplnErrors.Conflictf("replication factor change of topic %s is already in progress", s.Topic)
+	}
+
+	throttle := c.opts.ThrottleRate
+	if opts.ThrottleRate != nil {
+		throttle = *opts.ThrottleRate
+	}
+	if throttle < 0 {
+		return Plan{}, cplnErrors.Validationf("throttle must not be negative, got %d", throttle)
+	}
+
+	adm, cleanup, err := c.clientFactory()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan, err := c.plan(ctx, adm, topic, rf)
+	if err != nil {
+		cleanup()
+		return Plan{}, err
+	}
+
+	if !c.begin(plan, throttle) {
+		cleanup()
+		return Plan{}, cplnErrors.Conflictf("replication factor change of topic %s is already in progress", c.Status().Topic)
+	}
+	c.logger.Info("replication: change started",
+		"topic", topic,
+		"from", plan.CurrentReplicationFactor,
+		"to", rf,
+		"moves", len(plan.Moves),
+		"throttle", throttle,
+		"actor", opts.Actor)
+
+	// The change outlives the request that started it
+	go func() {
+		defer cleanup()
+		c.execute(context.WithoutCancel(ctx), adm, plan, throttle)
+	}()
+
+	return plan, nil
+}
+
+// execute throttles replication, moves the planned replicas in batches while
+// keeping min.insync.replicas satisfied, and always removes the throttle again
+func (c *Changer) execute(ctx context.Context, adm AdminClient, plan Plan, throttle int64) {
+	if throttle > 0 {
+		// Clear even a partially applied throttle
+		defer func() {
+			if err := reassign.ClearThrottle(ctx, adm, plan.Moves); err != nil {
+				c.logger.Error("replication: failed to clear throttle", "topic", plan.Topic, "error", err)
+			}
+		}()
+		if err := reassign.ApplyThrottle(ctx, adm, plan.Moves, throttle); err != nil {
+			c.fail(plan.Topic, err)
+			return
+		}
+	}
+
+	for start := 0; start < len(plan.Moves); start += c.opts.BatchSize {
+		end := start + c.opts.BatchSize
+		if end > len(plan.Moves) {
+			end = len(plan.Moves)
+		}
+		batch := plan.Moves[start:end]
+
+		if err := c.checkMinISR(ctx, adm, plan, batch, true); err != nil {
+			c.fail(plan.Topic, err)
+			return
+		}
+		if err := reassign.Execute(ctx, adm, batch); err != nil {
+			c.fail(plan.Topic, err)
+			return
+		}
+		if err := reassign.WaitForCompletion(ctx, c.clock, adm, batch, c.opts.PollInterval); err != nil {
+			c.fail(plan.Topic, err)
+			return
+		}
+		if err := c.checkMinISR(ctx, adm, plan, batch, false); err != nil {
+			c.fail(plan.Topic, err)
+			return
+		}
+		c.progress(len(batch))
+	}
+
+	c.finish()
+	c.logger.Info("replication: change completed",
+		"topic", plan.Topic,
+		"replicationFactor", plan.TargetReplicationFactor,
+		"moves", len(plan.Moves))
+}
+
+// checkMinISR verifies every partition of the batch keeps at least
+// min.insync.replicas in-sync replicas. Before the batch runs, only current ISR
+// members that stay in the target count, since added replicas are not in sync
+// yet and removed ones leave the ISR at once.
+func (c *Changer) checkMinISR(ctx context.Context, adm AdminClient, plan Plan, batch []reassign.Move, before bool) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx, plan.Topic)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	partitions := md.Topics[plan.Topic].Partitions
+
+	var violations []string
+	for _, m := range batch {
+		p, ok := partitions[m.Partition]
+		if !ok {
+			return fmt.Errorf("partition %s-%d disappeared from metadata", plan.Topic, m.Partition)
+		}
+		inSync := len(p.ISR)
+		if before {
+			inSync = countIn(p.ISR, m.Target)
+		}
+		if inSync < plan.MinISR {
+			violations = append(violations, fmt.Sprintf("%d (isr=%d)", m.Partition, inSync))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	when := "after"
+	if before {
+		when = "before"
+	}
+	return fmt.Errorf("min.insync.replicas %d not satisfied %s reassigning partitions of %s: %s",
+		plan.MinISR, when, plan.Topic, strings.Join(violations, ", "))
+}
+
+// minISR returns the effective min.insync.replicas of the topic
+func (c *Changer) minISR(ctx context.Context, adm AdminClient, topic string) (int, error) {
+	configs, err := adm.DescribeTopicConfigs(ctx, topic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	for _, rc := range configs {
+		if rc.Err != nil {
+			return 0, fmt.Errorf("failed to describe config of topic %s: %w", rc.Name, rc.Err)
+		}
+		for _, cfg := range rc.Configs {
+			if cfg.Key != minISRKey || cfg.Value == nil {
+				continue
+			}
+			v, err := strconv.Atoi(*cfg.Value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid min.insync.replicas %q on topic %s", *cfg.Value, topic)
+			}
+			return v, nil
+		}
+	}
+	// Kafka's default
+	return 1, nil
+}
+
+// begin transitions to moving unless a change is already running
+func (c *Changer) begin(plan Plan, throttle int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State == StateMoving {
+		return false
+	}
+	now := c.clock.Now()
+	c.status = Status{
+		State:                   StateMoving,
+		Topic:                   plan.Topic,
+		TargetReplicationFactor: plan.TargetReplicationFactor,
+		ThrottleRate:            throttle,
+		PlannedMoves:            len(plan.Moves),
+		StartedAt:               &now,
+	}
+	return true
+}
+
+func (c *Changer) progress(completed int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.CompletedMoves += completed
+}
+
+func (c *Changer) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	c.status.State = StateCompleted
+	c.status.FinishedAt = &now
+}
+
+func (c *Changer) fail(topic string, err error) {
+	c.logger.Error("replication: change failed", "topic", topic, "error", err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	c.status.State = StateFailed
+	c.status.Message = err.Error()
+	c.status.FinishedAt = &now
+}
+
+// replicationFactor returns the largest partition replication factor of a topic
+func replicationFactor(detail kadm.TopicDetail) int {
+	rf := 0
+	for _, p := range detail.Partitions {
+		rf = max(rf, len(p.Replicas))
+	}
+	return rf
+}
+
+// countIn counts the members of ids that are also in set
+func countIn(ids, set []int32) int {
+	n := 0
+	for _, id := range ids {
+		for _, s := range set {
+			if id == s {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
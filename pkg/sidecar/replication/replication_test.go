@@ -0,0 +1,346 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	DescribeTopicConfigsFunc       func(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error)
+	AlterTopicConfigsFunc          func(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error)
+	AlterBrokerConfigsFunc         func(ctx context.Context, configs []kadm.AlterConfig, brokers ...int32) (kadm.AlterConfigsResponses, error)
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) DescribeTopicConfigs(ctx context.Context, topics ...string) (kadm.ResourceConfigs, error) {
+	if m.DescribeTopicConfigsFunc != nil {
+		return m.DescribeTopicConfigsFunc(ctx, topics...)
+	}
+	return kadm.ResourceConfigs{}, nil
+}
+
+func (m *MockAdminClient) AlterTopicConfigs(ctx context.Context, configs []kadm.AlterConfig, topics ...string) (kadm.AlterConfigsResponses, error) {
+	if m.AlterTopicConfigsFunc != nil {
+		return m.AlterTopicConfigsFunc(ctx, configs, topics...)
+	}
+	return kadm.AlterConfigsResponses{}, nil
+}
+
+func (m *MockAdminClient) AlterBrokerConfigs(ctx context.Context, configs []kadm.AlterConfig, brokers ...int32) (kadm.AlterConfigsResponses, error) {
+	if m.AlterBrokerConfigsFunc != nil {
+		return m.AlterBrokerConfigsFunc(ctx, configs, brokers...)
+	}
+	return kadm.AlterConfigsResponses{}, nil
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// clusterMetadata returns a 4-broker cluster with one RF=2 topic of 2 partitions
+func clusterMetadata() kadm.Metadata {
+	md := kadm.Metadata{Topics: kadm.TopicDetails{}}
+	for _, b := range []int32{0, 1, 2, 3} {
+		md.Brokers = append(md.Brokers, kadm.BrokerDetail{NodeID: b})
+	}
+	md.Topics["orders"] = kadm.TopicDetail{Topic: "orders", Partitions: kadm.PartitionDetails{
+		0: {Topic: "orders", Partition: 0, Leader: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+		1: {Topic: "orders", Partition: 1, Leader: 1, Replicas: []int32{1, 2}, ISR: []int32{1, 2}},
+	}}
+	return md
+}
+
+// topicConfigs returns min.insync.replicas for the orders topic
+func topicConfigs(minISR string) kadm.ResourceConfigs {
+	return kadm.ResourceConfigs{{
+		Name:    "orders",
+		Configs: []kadm.Config{{Key: minISRKey, Value: &minISR, Source: kmsg.ConfigSourceDynamicTopicConfig}},
+	}}
+}
+
+// throttleRecorder records throttle config changes
+type throttleRecorder struct {
+	mu      sync.Mutex
+	set     []string
+	deleted []string
+}
+
+func (r *throttleRecorder) record(configs []kadm.AlterConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range configs {
+		if c.Op == kadm.DeleteConfig {
+			r.deleted = append(r.deleted, c.Name)
+		} else {
+			r.set = append(r.set, c.Name)
+		}
+	}
+}
+
+func (r *throttleRecorder) counts() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.set), len(r.deleted)
+}
+
+func (r *throttleRecorder) mock(md kadm.Metadata, minISR string) *MockAdminClient {
+	return &MockAdminClient{
+		MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+			return md, nil
+		},
+		DescribeTopicConfigsFunc: func(context.Context, ...string) (kadm.ResourceConfigs, error) {
+			return topicConfigs(minISR), nil
+		},
+		AlterTopicConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, _ ...string) (kadm.AlterConfigsResponses, error) {
+			r.record(configs)
+			return nil, nil
+		},
+		AlterBrokerConfigsFunc: func(_ context.Context, configs []kadm.AlterConfig, _ ...int32) (kadm.AlterConfigsResponses, error) {
+			r.record(configs)
+			return nil, nil
+		},
+	}
+}
+
+func newTestChanger(mock *MockAdminClient) *Changer {
+	c := NewChanger(kafkaclient.Config{}, Options{
+		BatchSize:    1,
+		ThrottleRate: 1048576,
+		PollInterval: time.Millisecond,
+		Timeout:      time.Second,
+	}, testLogger())
+	c.SetClientFactory(func() (AdminClient, func(), error) {
+		return mock, func() {}, nil
+	})
+	return c
+}
+
+func waitForState(t *testing.T, c *Changer, state State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c.Status().State == state {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected state %s, got %s", state, c.Status().State)
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name        string
+		topic       string
+		rf          int
+		minISR      string
+		expectErr   bool
+		expectMoves int
+	}{
+		{name: "raise", topic: "orders", rf: 3, minISR: "2", expectMoves: 2},
+		{name: "lower", topic: "orders", rf: 1, minISR: "1", expectMoves: 2},
+		{name: "unchanged", topic: "orders", rf: 2, minISR: "2"},
+		{name: "below min.isr", topic: "orders", rf: 1, minISR: "2", expectErr: true},
+		{name: "more than brokers", topic: "orders", rf: 5, minISR: "1", expectErr: true},
+		{name: "zero", topic: "orders", rf: 0, minISR: "1", expectErr: true},
+		{name: "unknown topic", topic: "payments", rf: 3, minISR: "1", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestChanger((&throttleRecorder{}).mock(clusterMetadata(), tt.minISR))
+
+			plan, err := c.Plan(context.Background(), tt.topic, tt.rf)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(plan.Moves) != tt.expectMoves {
+				t.Fatalf("expected %d moves, got %d", tt.expectMoves, len(plan.Moves))
+			}
+			if plan.CurrentReplicationFactor != 2 || plan.TargetReplicationFactor != tt.rf {
+				t.Errorf("unexpected replication factors: %+v", plan)
+			}
+		})
+	}
+}
+
+func TestStartRaisesWithThrottle(t *testing.T) {
+	recorder := &throttleRecorder{}
+	mock := recorder.mock(clusterMetadata(), "2")
+	var assigned int
+	var mu sync.Mutex
+	mock.AlterPartitionAssignmentsFunc = func(_ context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		assigned++
+		return kadm.AlterPartitionAssignmentsResponses{}, nil
+	}
+	c := newTestChanger(mock)
+
+	if _, err := c.Start(context.Background(), "orders", 3, StartOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForState(t, c, StateCompleted)
+
+	status := c.Status()
+	if status.CompletedMoves != 2 || status.ThrottleRate != 1048576 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	mu.Lock()
+	if assigned != 2 {
+		t.Errorf("expected 2 reassignment batches, got %d", assigned)
+	}
+	mu.Unlock()
+
+	// Throttle clearing runs after the status flips to completed
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if set, deleted := recorder.counts(); set == 4 && deleted == 4 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	set, deleted := recorder.counts()
+	t.Errorf("expected 4 throttle configs set and cleared, got %d set and %d cleared", set, deleted)
+}
+
+func TestStartUnthrottled(t *testing.T) {
+	recorder := &throttleRecorder{}
+	c := newTestChanger(recorder.mock(clusterMetadata(), "1"))
+
+	unthrottled := int64(0)
+	if _, err := c.Start(context.Background(), "orders", 3, StartOptions{ThrottleRate: &unthrottled}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForState(t, c, StateCompleted)
+
+	if set, deleted := recorder.counts(); set != 0 || deleted != 0 {
+		t.Errorf("expected no throttle changes, got %d set and %d cleared", set, deleted)
+	}
+}
+
+func TestStartStopsBelowMinISR(t *testing.T) {
+	md := clusterMetadata()
+	p := md.Topics["orders"].Partitions[0]
+	p.ISR = []int32{0}
+	md.Topics["orders"].Partitions[0] = p
+
+	mock := (&throttleRecorder{}).mock(md, "2")
+	mock.AlterPartitionAssignmentsFunc = func(context.Context, kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+		t.Error("expected no reassignment of a partition below min.insync.replicas")
+		return nil, nil
+	}
+	c := newTestChanger(mock)
+
+	if _, err := c.Start(context.Background(), "orders", 3, StartOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForState(t, c, StateFailed)
+	if !strings.Contains(c.Status().Message, "min.insync.replicas 2 not satisfied before") {
+		t.Errorf("unexpected message: %s", c.Status().Message)
+	}
+}
+
+func TestStartFailedReassignment(t *testing.T) {
+	mock := (&throttleRecorder{}).mock(clusterMetadata(), "1")
+	mock.AlterPartitionAssignmentsFunc = func(context.Context, kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+		return nil, errors.New("controller unavailable")
+	}
+	c := newTestChanger(mock)
+
+	if _, err := c.Start(context.Background(), "orders", 1, StartOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForState(t, c, StateFailed)
+	if !strings.Contains(c.Status().Message, "controller unavailable") {
+		t.Errorf("unexpected message: %s", c.Status().Message)
+	}
+}
+
+func TestStartHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		minISR     string
+		expectCode int
+	}{
+		{name: "accepted", body: `{"replicationFactor": 3, "requestedBy": "alice"}`, minISR: "2", expectCode: http.StatusAccepted},
+		{name: "below min.isr", body: `{"replicationFactor": 1}`, minISR: "2", expectCode: http.StatusConflict},
+		{name: "invalid body", body: `{`, minISR: "2", expectCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestChanger((&throttleRecorder{}).mock(clusterMetadata(), tt.minISR))
+
+			router := mux.NewRouter()
+			router.HandleFunc("/topics/{name}/replication-factor", c.StartHandler).Methods("POST")
+
+			req := httptest.NewRequest("POST", "/topics/orders/replication-factor", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPlanHandlerInvalidReplicationFactor(t *testing.T) {
+	c := newTestChanger(&MockAdminClient{})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/topics/{name}/replication-factor/plan", c.PlanHandler).Methods("GET")
+
+	req := httptest.NewRequest("GET", "/topics/orders/replication-factor/plan?replicationFactor=abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
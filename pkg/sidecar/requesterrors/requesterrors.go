@@ -0,0 +1,328 @@
+package requesterrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/jolokia"
+)
+
+// CheckName identifies the request error sampler in the freshness tracker
+const CheckName = "request_errors"
+
+const (
+	requestsMBean = "kafka.network:type=RequestMetrics,name=RequestsPerSec,request=%s,*"
+	errorsMBean   = "kafka.network:type=RequestMetrics,name=ErrorsPerSec,request=%s,*"
+	countAttr     = "Count"
+
+	// noError is the error code of successful requests, which the broker
+	// counts in ErrorsPerSec too
+	noError = "NONE"
+
+	// minRequests is how many requests an API needs in an interval for its error
+	// ratio to count against the threshold, so one failed request on an idle
+	// broker does not degrade it
+	minRequests = 20
+)
+
+// DefaultAPIs are the request types sampled when none are configured: the
+// ones whose errors clients see first
+var DefaultAPIs = []string{"Produce", "Fetch", "Metadata", "OffsetCommit"}
+
+// Reader reads MBean attributes matching a pattern. *jolokia.Client implements it.
+type Reader interface {
+	ReadPattern(ctx context.Context, pattern, attribute string) (map[string]float64, error)
+}
+
+// Options configures the request error sampler
+type Options struct {
+	// APIs are the request types sampled, as named by the broker's RequestMetrics
+	APIs []string
+	// Interval is how often the counters are sampled; rates are over one interval
+	Interval time.Duration
+	// Timeout bounds each sample
+	Timeout time.Duration
+	// MaxErrorRatio is the error ratio above which readiness is degraded. Zero
+	// disables the threshold.
+	MaxErrorRatio float64
+	// MaxErrorRatios overrides MaxErrorRatio per API
+	MaxErrorRatios map[string]float64
+}
+
+// API is the request and error counts of one request type
+type API struct {
+	API string `json:"api"`
+	// Requests and Errors are the broker's cumulative counts since it started
+	Requests float64            `json:"requests"`
+	Errors   map[string]float64 `json:"errors,omitempty"`
+	// RequestRate and ErrorRate are per second over the last interval
+	RequestRate float64 `json:"requestRate"`
+	ErrorRate   float64 `json:"errorRate"`
+	// ErrorRatio is the fraction of the interval's requests that failed
+	ErrorRatio float64 `json:"errorRatio"`
+	// AboveThreshold is set while the error ratio is above the API's threshold
+	AboveThreshold bool `json:"aboveThreshold,omitempty"`
+}
+
+// totalErrors sums the error counts
+func (a API) totalErrors() float64 {
+	var total float64
+	for _, n := range a.Errors {
+		total += n
+	}
+	return total
+}
+
+// Report is the last sample of every API
+type Report struct {
+	SampledAt time.Time `json:"sampledAt"`
+	APIs      []API     `json:"apis"`
+}
+
+// Sampler periodically reads the broker's per-API request and error counters
+// through Jolokia and derives error rates. Clients see these errors (e.g.
+// NOT_LEADER_OR_FOLLOWER on Produce) long before any partition check fails.
+type Sampler struct {
+	reader  Reader
+	opts    Options
+	logger  *slog.Logger
+	tracker *freshness.Tracker
+	clock   clock.Clock
+
+	mu     sync.RWMutex
+	report *Report
+}
+
+// NewSampler creates a new request error sampler
+func NewSampler(reader Reader, opts Options, logger *slog.Logger) *Sampler {
+	if len(opts.APIs) == 0 {
+		opts.APIs = DefaultAPIs
+	}
+	return &Sampler{
+		reader: reader,
+		opts:   opts,
+		logger: logger,
+		clock:  clock.Real,
+	}
+}
+
+// SetTracker records every sample with the freshness tracker
+func (s *Sampler) SetTracker(tracker *freshness.Tracker) {
+	s.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Sampler) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// LastReport returns the last sample, and false before the first one
+func (s *Sampler) LastReport() (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.report == nil {
+		return Report{}, false
+	}
+	return *s.report, true
+}
+
+// ErrorRateError returns an error naming the APIs whose error ratio is above
+// their threshold in the last sample
+func (s *Sampler) ErrorRateError() error {
+	report, ok := s.LastReport()
+	if !ok {
+		return nil
+	}
+	var above []string
+	for _, api := range report.APIs {
+		if api.AboveThreshold {
+			above = append(above, fmt.Sprintf("%s %.1f%%", api.API, api.ErrorRatio*100))
+		}
+	}
+	if len(above) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(above, ", "))
+}
+
+// Run samples every Interval until the context is cancelled
+func (s *Sampler) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	s.tracker.Register(CheckName)
+
+	for {
+		s.tracker.Record(CheckName, s.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step reads the counters once. Rates are computed against the previous
+// sample, so the first sample only records the counts.
+func (s *Sampler) Step(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	apis := make([]API, 0, len(s.opts.APIs))
+	for _, name := range s.opts.APIs {
+		api, err := s.read(ctx, name)
+		if err != nil {
+			return err
+		}
+		apis = append(apis, api)
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.report != nil {
+		s.rates(apis, *s.report, now.Sub(s.report.SampledAt))
+	}
+	s.report = &Report{SampledAt: now, APIs: apis}
+	return nil
+}
+
+// read sums the counters of one API over its request versions and error codes
+func (s *Sampler) read(ctx context.Context, name string) (API, error) {
+	api := API{API: name, Errors: map[string]float64{}}
+
+	requests, err := s.reader.ReadPattern(ctx, fmt.Sprintf(requestsMBean, name), countAttr)
+	if err != nil {
+		return API{}, fmt.Errorf("failed to read %s request counts: %w", name, err)
+	}
+	for _, n := range requests {
+		api.Requests += n
+	}
+
+	errorCounts, err := s.reader.ReadPattern(ctx, fmt.Sprintf(errorsMBean, name), countAttr)
+	if err != nil {
+		return API{}, fmt.Errorf("failed to read %s error counts: %w", name, err)
+	}
+	for mbean, n := range errorCounts {
+		_, props := jolokia.ParseObjectName(mbean)
+		if code := props["error"]; code != "" && code != noError {
+			api.Errors[code] += n
+		}
+	}
+	return api, nil
+}
+
+// rates fills in the rates of apis since the previous report, and flags the
+// APIs above their threshold
+func (s *Sampler) rates(apis []API, previous Report, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	before := make(map[string]API, len(previous.APIs))
+	for _, api := range previous.APIs {
+		before[api.API] = api
+	}
+
+	for i := range apis {
+		api := &apis[i]
+		prev, ok := before[api.API]
+		requests := api.Requests - prev.Requests
+		errs := api.totalErrors() - prev.totalErrors()
+		if !ok || requests < 0 || errs < 0 {
+			// The broker restarted and its counters were reset
+			continue
+		}
+		api.RequestRate = requests / elapsed.Seconds()
+		api.ErrorRate = errs / elapsed.Seconds()
+		if requests > 0 {
+			api.ErrorRatio = min(errs/requests, 1)
+		}
+
+		threshold := s.threshold(api.API)
+		api.AboveThreshold = threshold > 0 && requests >= minRequests && api.ErrorRatio > threshold
+		if api.AboveThreshold && !prev.AboveThreshold {
+			s.logger.Warn("requesterrors: error ratio above threshold",
+				"api", api.API,
+				"errorRatio", api.ErrorRatio,
+				"threshold", threshold,
+				"errors", topErrors(api.Errors, prev.Errors))
+		}
+	}
+}
+
+// threshold returns the maximum error ratio of an API, zero when unchecked
+func (s *Sampler) threshold(api string) float64 {
+	if ratio, ok := s.opts.MaxErrorRatios[api]; ok {
+		return ratio
+	}
+	return s.opts.MaxErrorRatio
+}
+
+// topErrors lists the error codes that grew since the previous sample, most
+// frequent first, for the log
+func topErrors(current, previous map[string]float64) string {
+	type count struct {
+		code string
+		n    float64
+	}
+	var counts []count
+	for code, n := range current {
+		if delta := n - previous[code]; delta > 0 {
+			counts = append(counts, count{code, delta})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].n != counts[j].n {
+			return counts[i].n > counts[j].n
+		}
+		return counts[i].code < counts[j].code
+	})
+	parts := make([]string, 0, len(counts))
+	for _, c := range counts {
+		parts = append(parts, c.code+"="+strconv.FormatFloat(c.n, 'f', 0, 64))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseThresholds parses per-API error ratio thresholds in the form
+// "Produce=0.01,Metadata=0.1"
+func ParseThresholds(s string) (map[string]float64, error) {
+	thresholds := map[string]float64{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		api, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(api) == "" {
+			return nil, fmt.Errorf("invalid threshold %q: expected <api>=<ratio>", pair)
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid threshold %q: ratio must be between 0 and 1", pair)
+		}
+		thresholds[strings.TrimSpace(api)] = ratio
+	}
+	return thresholds, nil
+}
+
+// ParseAPIs parses a comma-separated list of request types, e.g.
+// "Produce,Fetch". An empty list selects DefaultAPIs.
+func ParseAPIs(s string) []string {
+	var apis []string
+	for _, api := range strings.Split(s, ",") {
+		if api = strings.TrimSpace(api); api != "" {
+			apis = append(apis, api)
+		}
+	}
+	return apis
+}
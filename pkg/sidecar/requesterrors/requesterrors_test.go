@@ -0,0 +1,202 @@
+package requesterrors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// fakeReader serves RequestMetrics counters set by the test
+type fakeReader struct {
+	requests map[string]float64
+	errors   map[string]map[string]float64
+	err      error
+}
+
+func (f *fakeReader) ReadPattern(ctx context.Context, pattern, attribute string) (map[string]float64, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := map[string]float64{}
+	for api, n := range f.requests {
+		if strings.Contains(pattern, "name=RequestsPerSec,request="+api+",") {
+			// Split over two request versions
+			values["kafka.network:type=RequestMetrics,name=RequestsPerSec,request="+api+",version=8"] = n / 2
+			values["kafka.network:type=RequestMetrics,name=RequestsPerSec,request="+api+",version=9"] = n / 2
+		}
+	}
+	for api, byCode := range f.errors {
+		if strings.Contains(pattern, "name=ErrorsPerSec,request="+api+",") {
+			for code, n := range byCode {
+				values["kafka.network:type=RequestMetrics,name=ErrorsPerSec,request="+api+",error="+code] = n
+			}
+		}
+	}
+	return values, nil
+}
+
+func newTestSampler(reader Reader, opts Options) (*Sampler, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	opts.Interval = 10 * time.Second
+	opts.Timeout = time.Second
+	s := NewSampler(reader, opts, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	s.SetClock(clk)
+	return s, clk
+}
+
+func TestStepRates(t *testing.T) {
+	reader := &fakeReader{
+		requests: map[string]float64{"Produce": 1000, "Fetch": 5000},
+		errors: map[string]map[string]float64{
+			"Produce": {"NONE": 990, "NOT_LEADER_OR_FOLLOWER": 10},
+			"Fetch":   {"NONE": 5000},
+		},
+	}
+	s, clk := newTestSampler(reader, Options{APIs: []string{"Produce", "Fetch"}, MaxErrorRatio: 0.1})
+
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := s.LastReport()
+	if report.APIs[0].Requests != 1000 || report.APIs[0].Errors["NOT_LEADER_OR_FOLLOWER"] != 10 {
+		t.Errorf("unexpected first sample %+v", report.APIs[0])
+	}
+	if _, ok := report.APIs[0].Errors["NONE"]; ok {
+		t.Error("expected successful requests not to count as errors")
+	}
+
+	// 200 more Produce requests, 50 of them failed
+	reader.requests["Produce"] = 1200
+	reader.errors["Produce"]["NONE"] = 1140
+	reader.errors["Produce"]["NOT_LEADER_OR_FOLLOWER"] = 60
+	clk.Advance(10 * time.Second)
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, _ = s.LastReport()
+	produce := report.APIs[0]
+	if produce.RequestRate != 20 || produce.ErrorRate != 5 || produce.ErrorRatio != 0.25 {
+		t.Errorf("unexpected Produce rates %+v", produce)
+	}
+	if !produce.AboveThreshold {
+		t.Error("expected Produce above the threshold")
+	}
+	if report.APIs[1].AboveThreshold {
+		t.Error("expected idle Fetch below the threshold")
+	}
+	if err := s.ErrorRateError(); err == nil || err.Error() != "Produce 25.0%" {
+		t.Errorf("unexpected readiness error %v", err)
+	}
+}
+
+func TestStepThresholds(t *testing.T) {
+	reader := &fakeReader{
+		requests: map[string]float64{"Metadata": 0},
+		errors:   map[string]map[string]float64{"Metadata": {"UNKNOWN_TOPIC_OR_PARTITION": 0}},
+	}
+	s, clk := newTestSampler(reader, Options{
+		APIs:           []string{"Metadata"},
+		MaxErrorRatio:  0.01,
+		MaxErrorRatios: map[string]float64{"Metadata": 0.5},
+	})
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Clients probing for missing topics: 10% errors, under the Metadata override
+	reader.requests["Metadata"] = 100
+	reader.errors["Metadata"]["UNKNOWN_TOPIC_OR_PARTITION"] = 10
+	clk.Advance(10 * time.Second)
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.ErrorRateError(); err != nil {
+		t.Errorf("expected the per-API threshold to apply, got %v", err)
+	}
+}
+
+func TestStepFewRequests(t *testing.T) {
+	reader := &fakeReader{
+		requests: map[string]float64{"OffsetCommit": 0},
+		errors:   map[string]map[string]float64{"OffsetCommit": {"COORDINATOR_NOT_AVAILABLE": 0}},
+	}
+	s, clk := newTestSampler(reader, Options{APIs: []string{"OffsetCommit"}, MaxErrorRatio: 0.1})
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reader.requests["OffsetCommit"] = 2
+	reader.errors["OffsetCommit"]["COORDINATOR_NOT_AVAILABLE"] = 1
+	clk.Advance(10 * time.Second)
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.ErrorRateError(); err != nil {
+		t.Errorf("expected too few requests to be ignored, got %v", err)
+	}
+}
+
+func TestStepCounterReset(t *testing.T) {
+	reader := &fakeReader{
+		requests: map[string]float64{"Produce": 1000},
+		errors:   map[string]map[string]float64{"Produce": {"NOT_LEADER_OR_FOLLOWER": 500}},
+	}
+	s, clk := newTestSampler(reader, Options{APIs: []string{"Produce"}, MaxErrorRatio: 0.1})
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The broker restarted
+	reader.requests["Produce"] = 100
+	reader.errors["Produce"]["NOT_LEADER_OR_FOLLOWER"] = 50
+	clk.Advance(10 * time.Second)
+	if err := s.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _ := s.LastReport()
+	if report.APIs[0].AboveThreshold || report.APIs[0].ErrorRate != 0 {
+		t.Errorf("expected no rates across a counter reset, got %+v", report.APIs[0])
+	}
+}
+
+func TestStepReadError(t *testing.T) {
+	s, _ := newTestSampler(&fakeReader{err: errors.New("connection refused")}, Options{})
+	if err := s.Step(context.Background()); err == nil {
+		t.Error("expected the read error")
+	}
+	if _, ok := s.LastReport(); ok {
+		t.Error("expected no report after a failed sample")
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(" Produce=0.01, Metadata=0.5,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(thresholds) != 2 || thresholds["Produce"] != 0.01 || thresholds["Metadata"] != 0.5 {
+		t.Errorf("unexpected thresholds %v", thresholds)
+	}
+	for _, invalid := range []string{"Produce", "Produce=high", "Fetch=2", "=0.1"} {
+		if _, err := ParseThresholds(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestParseAPIs(t *testing.T) {
+	apis := ParseAPIs(" Produce, ,JoinGroup")
+	if len(apis) != 2 || apis[0] != "Produce" || apis[1] != "JoinGroup" {
+		t.Errorf("unexpected APIs %v", apis)
+	}
+	if apis := ParseAPIs(""); apis != nil {
+		t.Errorf("expected no APIs, got %v", apis)
+	}
+}
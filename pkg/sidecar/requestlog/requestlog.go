@@ -0,0 +1,167 @@
+// Package requestlog logs every HTTP request the sidecar serves, tagged with a
+// request ID that the handler's own log lines carry too, so a probe failure or
+// an admin call can be followed through the logs.
+package requestlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Header carries the request ID, both on the request and on the response
+const Header = "X-Request-ID"
+
+// maxIDLength bounds a request ID taken from a client
+const maxIDLength = 128
+
+type contextKey struct{}
+
+// ID returns the request ID of the context, or "" outside a logged request
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// WithID returns a context carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// Middleware logs requests once they are served, with their method, path,
+// route, status, latency and request ID. Failed requests (4xx and 5xx) are
+// always logged, successful ones as the sampler decides; a nil sampler logs
+// every request. The ID is taken from the X-Request-ID header when the client
+// sent a usable one, generated otherwise, and echoed on the response.
+func Middleware(logger *slog.Logger, sampler *Sampler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(Header)
+			if !validID(id) {
+				id = newID()
+			}
+			w.Header().Set(Header, id)
+			ctx := WithID(r.Context(), id)
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			route := routeName(r)
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", route),
+				slog.Int("status", rec.status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remoteAddr", r.RemoteAddr),
+			}
+			if rec.status < http.StatusBadRequest && sampler != nil {
+				rate, ok := sampler.Sample(route)
+				if !ok {
+					return
+				}
+				// Each logged success stands for rate requests
+				if rate > 1 {
+					attrs = append(attrs, slog.Uint64("sampleRate", rate))
+				}
+			}
+			logger.LogAttrs(ctx, slog.LevelInfo, "http request", attrs...)
+		})
+	}
+}
+
+// routeName returns the path template of the matched route, which groups
+// requests to parameterized paths, or the path when no route matched
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return r.URL.Path
+}
+
+// validID reports whether a client-supplied request ID is safe to log: not
+// empty, bounded, and without characters that could forge log fields
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newID generates a random 128-bit request ID
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// recorder captures the status code and size of a response
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *recorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Handler is a slog.Handler that adds the request ID of the context to every
+// record logged with one, e.g. by Logger.InfoContext in a handler
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps a slog handler
+func NewHandler(handler slog.Handler) *Handler {
+	return &Handler{Handler: handler}
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if id := ID(ctx); id != "" {
+		record.AddAttrs(slog.String("requestId", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,85 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// testLogger returns a logger whose JSON records are decoded by the returned function
+func testLogger() (*slog.Logger, func() []map[string]any) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+	return logger, func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				panic(err)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	logger, records := testLogger()
+	router := mux.NewRouter()
+	router.Use(Middleware(logger, nil))
+	router.HandleFunc("/admin/topics/{topic}", func(w http.ResponseWriter, r *http.Request) {
+		logger.WarnContext(r.Context(), "topic not found")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/topics/orders", nil)
+	req.Header.Set(Header, "trace-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get(Header); got != "trace-42" {
+		t.Errorf("expected the client's request ID to be echoed, got %q", got)
+	}
+	logged := records()
+	if len(logged) != 2 {
+		t.Fatalf("expected a handler line and an access line, got %v", logged)
+	}
+	if logged[0]["msg"] != "topic not found" || logged[0]["requestId"] != "trace-42" {
+		t.Errorf("expected the handler line to carry the request ID, got %v", logged[0])
+	}
+	access := logged[1]
+	if access["requestId"] != "trace-42" || access["method"] != "GET" || access["path"] != "/admin/topics/orders" ||
+		access["route"] != "/admin/topics/{topic}" || access["status"] != float64(404) || access["bytes"] != float64(9) {
+		t.Errorf("unexpected access line: %v", access)
+	}
+}
+
+func TestMiddlewareGeneratesID(t *testing.T) {
+	logger, records := testLogger()
+	handler := Middleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, sent := range []string{"", "forged\" level=ERROR", strings.Repeat("a", maxIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/health/live", nil)
+		if sent != "" {
+			req.Header.Set(Header, sent)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		id := w.Header().Get(Header)
+		if len(id) != 32 || id == sent {
+			t.Errorf("expected a generated ID for %q, got %q", sent, id)
+		}
+	}
+	logged := records()
+	if len(logged) != 3 || logged[0]["requestId"] == logged[1]["requestId"] {
+		t.Errorf("expected a distinct ID per request, got %v", logged)
+	}
+}
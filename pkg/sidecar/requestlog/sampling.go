@@ -0,0 +1,82 @@
+package requestlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultSampling logs one in 60 successful probes (one line every ten
+// minutes at the kubelet's 10s period) and one in 20 successful scrapes
+const DefaultSampling = "/health/live=60,/health/ready=60,/metrics=20"
+
+// Wildcard sets the rate of routes that have none of their own
+const Wildcard = "*"
+
+// Sampler decides which successful requests are logged, per route: 1 in N
+// requests, where N=1 logs every request and N=0 none. Routes without a rate
+// of their own are all logged, unless a Wildcard rate says otherwise.
+type Sampler struct {
+	rates    map[string]uint64
+	fallback uint64
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// ParseSampling parses a comma-separated list of route=N rates, where the
+// route is a path template such as /admin/decommission/{brokerId} or "*".
+// Empty selects DefaultSampling.
+func ParseSampling(s string) (*Sampler, error) {
+	if strings.TrimSpace(s) == "" {
+		s = DefaultSampling
+	}
+	sampler := &Sampler{rates: map[string]uint64{}, fallback: 1, counts: map[string]uint64{}}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		route = strings.TrimSpace(route)
+		if !ok || route == "" {
+			return nil, fmt.Errorf("invalid sampling rate %q (expected route=N)", entry)
+		}
+		if route != Wildcard && !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid sampling route %q (expected a path template or %s)", route, Wildcard)
+		}
+		rate, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling rate %q for %s (expected a whole number)", value, route)
+		}
+		if route == Wildcard {
+			sampler.fallback = rate
+			continue
+		}
+		sampler.rates[route] = rate
+	}
+	return sampler, nil
+}
+
+// Sample counts a successful request to the route and reports whether it is
+// logged, along with the route's rate. The first request of a route is
+// logged, then every Nth.
+func (s *Sampler) Sample(route string) (uint64, bool) {
+	rate, ok := s.rates[route]
+	if !ok {
+		rate = s.fallback
+	}
+	switch rate {
+	case 0:
+		return 0, false
+	case 1:
+		return 1, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.counts[route]
+	s.counts[route] = (n + 1) % rate
+	return rate, n == 0
+}
@@ -0,0 +1,89 @@
+package requestlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseSampling(t *testing.T) {
+	sampler, err := ParseSampling("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sampler.rates["/health/live"] != 60 || sampler.rates["/metrics"] != 20 || sampler.fallback != 1 {
+		t.Errorf("expected the default rates, got %v fallback %d", sampler.rates, sampler.fallback)
+	}
+
+	sampler, err = ParseSampling(" /health/ready=0, *=5 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate, ok := sampler.rates["/health/ready"]; !ok || rate != 0 || sampler.fallback != 5 {
+		t.Errorf("unexpected rates: %v fallback %d", sampler.rates, sampler.fallback)
+	}
+
+	for _, invalid := range []string{"/metrics", "metrics=2", "/metrics=-1", "=3", "/metrics=often"} {
+		if _, err := ParseSampling(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	sampler, err := ParseSampling("/health/live=3,/metrics=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged []bool
+	for i := 0; i < 7; i++ {
+		_, ok := sampler.Sample("/health/live")
+		logged = append(logged, ok)
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if logged[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, logged)
+		}
+	}
+	if _, ok := sampler.Sample("/metrics"); ok {
+		t.Error("expected a zero rate to log nothing")
+	}
+	if rate, ok := sampler.Sample("/about"); !ok || rate != 1 {
+		t.Errorf("expected unlisted routes to be logged, got %d %v", rate, ok)
+	}
+}
+
+func TestMiddlewareSampling(t *testing.T) {
+	logger, records := testLogger()
+	sampler, err := ParseSampling("/health/ready=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := http.StatusOK
+	handler := Middleware(logger, sampler)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	}
+
+	// Successes 1 and 3 of four are logged, every failure is
+	for i := 0; i < 4; i++ {
+		serve()
+	}
+	status = http.StatusServiceUnavailable
+	serve()
+	serve()
+
+	logged := records()
+	if len(logged) != 4 {
+		t.Fatalf("expected 2 sampled successes and 2 failures, got %d: %v", len(logged), logged)
+	}
+	if logged[0]["sampleRate"] != float64(2) {
+		t.Errorf("expected the sample rate on a sampled success, got %v", logged[0])
+	}
+	if _, ok := logged[3]["sampleRate"]; ok || logged[3]["status"] != float64(503) {
+		t.Errorf("expected an unsampled failure, got %v", logged[3])
+	}
+}
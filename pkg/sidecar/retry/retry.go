@@ -0,0 +1,95 @@
+// Package retry retries Kafka calls that failed transiently, with exponential
+// backoff and jitter, so that one dropped packet does not fail a health check
+// or an admin request.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// Policy is how a failed call is retried
+type Policy struct {
+	// Attempts is how many times a call is made in total. Less than two
+	// disables retries.
+	Attempts int
+	// BaseDelay is the backoff before the first retry. It doubles for every
+	// retry after.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. Zero leaves it uncapped.
+	MaxDelay time.Duration
+	// Jitter randomizes every backoff by up to this fraction (0.0-1.0), so
+	// callers that failed together do not retry together
+	Jitter float64
+}
+
+// Delay returns the backoff before retry n, counting from 1. Its signature
+// matches kgo.RetryBackoffFn.
+func (p Policy) Delay(n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+	delay := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Do calls fn until it succeeds, fails with an error that is not Retriable, or
+// the attempts run out, and returns the last error. A retry whose backoff would
+// end after ctx's deadline is not made.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= p.Attempts || !Retriable(err) {
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Retriable reports whether an error is transient: a network error, a dropped
+// connection, or a Kafka error the protocol marks retriable. Cancellation and
+// expired deadlines are not, as the caller has given up.
+func Retriable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if kerr.IsRetriable(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestDelay(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for n, want := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		60: time.Second,
+	} {
+		if got := p.Delay(n); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.Delay(2); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("expected 200ms ± 50%%, got %v", got)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{Attempts: 3, BaseDelay: time.Millisecond}
+	transient := fmt.Errorf("failed to fetch metadata: %w", io.ErrUnexpectedEOF)
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "transient then success", errs: []error{transient, nil}, wantCalls: 2},
+		{name: "attempts exhausted", errs: []error{transient, transient, transient, nil}, wantCalls: 3, wantErr: true},
+		{name: "permanent", errs: []error{kerr.TopicAuthorizationFailed, nil}, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := p.Do(context.Background(), func(context.Context) error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestDoStopsAtDeadline(t *testing.T) {
+	p := Policy{Attempts: 5, BaseDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := p.Do(ctx, func(context.Context) error {
+		calls++
+		return syscall.ECONNRESET
+	})
+	if !errors.Is(err, syscall.ECONNRESET) || calls != 1 {
+		t.Errorf("expected one call returning the error, got %d calls and %v", calls, err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("expected no wait for a backoff past the deadline")
+	}
+}
+
+func TestRetriable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("unsupported SASL mechanism"), false},
+		{context.Canceled, false},
+		{fmt.Errorf("failed to fetch metadata: %w", context.DeadlineExceeded), false},
+		{kerr.NotLeaderForPartition, true},
+		{kerr.TopicAuthorizationFailed, false},
+		{io.EOF, true},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+	}
+	for _, tt := range tests {
+		if got := Retriable(tt.err); got != tt.want {
+			t.Errorf("Retriable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package safemode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// ResetPath is the endpoint that discards corrupted state and leaves safe mode.
+// It stays available while every other mutating endpoint is refused.
+const ResetPath = "/admin/state/reset"
+
+// Store is a piece of persistent state that failed its integrity check
+type Store struct {
+	Name       string    `json:"name"`
+	Error      string    `json:"error"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Status reports whether the sidecar is in safe mode and why
+type Status struct {
+	SafeMode bool    `json:"safeMode"`
+	Stores   []Store `json:"stores,omitempty"`
+	// ResetAt is when corrupted state was last discarded through ResetPath
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+}
+
+// corruptStore is a failed store and how to discard it
+type corruptStore struct {
+	Store
+	reset func() error
+}
+
+// remediation is a background loop held back while in safe mode
+type remediation struct {
+	ctx context.Context
+	run func(ctx context.Context)
+}
+
+// Guard puts the sidecar in safe mode when its persistent state is corrupted.
+// In safe mode health and metrics are served as usual, but remediation loops
+// are held back and mutating endpoints are refused, since acting on state that
+// cannot be trusted could undo or repeat cluster changes. An operator leaves
+// safe mode by discarding the corrupted state through ResetPath.
+type Guard struct {
+	logger *slog.Logger
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	stores  map[string]*corruptStore
+	pending []remediation
+	resetAt *time.Time
+}
+
+// NewGuard creates a new safe mode guard, initially not in safe mode
+func NewGuard(logger *slog.Logger) *Guard {
+	return &Guard{
+		logger: logger,
+		clock:  clock.Real,
+		stores: make(map[string]*corruptStore),
+	}
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (g *Guard) SetClock(clk clock.Clock) {
+	g.clock = clk
+}
+
+// Enter puts the sidecar in safe mode because the named store failed its
+// integrity check. reset discards the store's state when an operator asks to.
+func (g *Guard) Enter(name string, err error, reset func() error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stores[name] = &corruptStore{
+		Store: Store{Name: name, Error: err.Error(), DetectedAt: g.clock.Now()},
+		reset: reset,
+	}
+	g.logger.Error("safemode: persistent state failed its integrity check, remediation and mutating endpoints are disabled until it is reset",
+		"store", name,
+		"error", err,
+		"reset", "POST "+ResetPath)
+}
+
+// Active reports whether the sidecar is in safe mode
+func (g *Guard) Active() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.stores) > 0
+}
+
+// Status returns the safe mode status
+func (g *Guard) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	status := Status{SafeMode: len(g.stores) > 0, ResetAt: g.resetAt}
+	for _, s := range g.stores {
+		status.Stores = append(status.Stores, s.Store)
+	}
+	sort.Slice(status.Stores, func(i, j int) bool {
+		return status.Stores[i].Name < status.Stores[j].Name
+	})
+	return status
+}
+
+// Remediate starts a remediation loop now, or once safe mode is left
+func (g *Guard) Remediate(ctx context.Context, run func(ctx context.Context)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.stores) > 0 {
+		g.pending = append(g.pending, remediation{ctx: ctx, run: run})
+		return
+	}
+	go run(ctx)
+}
+
+// Reset discards the state of every corrupted store and, when all succeed,
+// leaves safe mode and starts the remediation held back
+func (g *Guard) Reset() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.stores) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for name, s := range g.stores {
+		if err := s.reset(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reset %s state: %w", name, err))
+			continue
+		}
+		g.logger.Warn("safemode: discarded corrupted state", "store", name)
+		delete(g.stores, name)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	now := g.clock.Now()
+	g.resetAt = &now
+	for _, r := range g.pending {
+		go r.run(r.ctx)
+	}
+	g.pending = nil
+	return nil
+}
+
+// Middleware refuses mutating requests other than ResetPath with 503 while in
+// safe mode. Reads, including health and metrics, are always served.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.URL.Path == ResetPath || !g.Active() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		_, _ = web.ReturnResponseWithCode(w, g.refusal(), http.StatusServiceUnavailable)
+	})
+}
+
+// refusal is the response to a mutating request in safe mode
+func (g *Guard) refusal() map[string]any {
+	return map[string]any{
+		"error": fmt.Sprintf("sidecar is in safe mode because its persistent state is corrupted; inspect GET /admin/state and discard it with POST %s", ResetPath),
+		"state": g.Status(),
+	}
+}
+
+// StatusHandler handles GET /admin/state requests
+func (g *Guard) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, g.Status())
+}
+
+// ResetHandler handles POST /admin/state/reset requests. The corrupted files
+// are kept aside for inspection rather than deleted.
+func (g *Guard) ResetHandler(w http.ResponseWriter, _ *http.Request) {
+	if err := g.Reset(); err != nil {
+		_, _ = web.ReturnResponseWithCode(w, map[string]any{"error": err.Error(), "state": g.Status()}, http.StatusInternalServerError)
+		return
+	}
+	_, _ = web.ReturnResponse(w, g.Status())
+}
@@ -0,0 +1,108 @@
+package safemode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestGuard() *Guard {
+	return NewGuard(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// okHandler responds 200 to every request
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestMiddleware(t *testing.T) {
+	g := newTestGuard()
+	h := g.Middleware(okHandler)
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	if code := serve(http.MethodPost, "/admin/decommission/2"); code != http.StatusOK {
+		t.Errorf("expected mutating requests to be served outside safe mode, got %d", code)
+	}
+
+	g.Enter("decommission", errors.New("checksum mismatch"), func() error { return nil })
+	if !g.Active() {
+		t.Fatal("expected safe mode")
+	}
+	tests := []struct {
+		method, path string
+		expected     int
+	}{
+		{http.MethodGet, "/health/ready", http.StatusOK},
+		{http.MethodGet, "/metrics", http.StatusOK},
+		{http.MethodGet, "/admin/state", http.StatusOK},
+		{http.MethodPost, ResetPath, http.StatusOK},
+		{http.MethodPost, "/admin/decommission/2", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/admin/offsets/group", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		if code := serve(tt.method, tt.path); code != tt.expected {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.expected, code)
+		}
+	}
+}
+
+func TestResetStartsHeldBackRemediation(t *testing.T) {
+	g := newTestGuard()
+	g.Enter("decommission", errors.New("checksum mismatch"), func() error { return nil })
+
+	started := make(chan struct{}, 1)
+	g.Remediate(context.Background(), func(context.Context) { started <- struct{}{} })
+	select {
+	case <-started:
+		t.Fatal("expected remediation to be held back in safe mode")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if err := g.Reset(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected remediation to start after the reset")
+	}
+	status := g.Status()
+	if status.SafeMode || len(status.Stores) != 0 || status.ResetAt == nil {
+		t.Errorf("expected safe mode to be left, got %+v", status)
+	}
+
+	// Outside safe mode remediation starts at once
+	g.Remediate(context.Background(), func(context.Context) { started <- struct{}{} })
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected remediation to start outside safe mode")
+	}
+}
+
+func TestResetFailureStaysInSafeMode(t *testing.T) {
+	g := newTestGuard()
+	g.Enter("decommission", errors.New("checksum mismatch"), func() error { return errors.New("read-only file system") })
+	g.Enter("other", errors.New("truncated"), func() error { return nil })
+
+	if err := g.Reset(); err == nil {
+		t.Fatal("expected the failed reset to be returned")
+	}
+	status := g.Status()
+	if !status.SafeMode || len(status.Stores) != 1 || status.Stores[0].Name != "decommission" {
+		t.Errorf("expected only the failed store to remain, got %+v", status)
+	}
+	if status.ResetAt != nil {
+		t.Errorf("expected no reset time, got %v", status.ResetAt)
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDeferred is returned by reconcilers that left work to the next cycle
+// because the cycle budget ran out
+var ErrDeferred = errors.New("work deferred to the next cycle by the cycle budget")
+
+// Options configures the scheduler
+type Options struct {
+	// Concurrency is how many admin requests run at once across every
+	// reconciler sharing the scheduler. Less than one runs them one at a time.
+	Concurrency int
+	// BatchSize is how many items go into one admin request. Zero sends every
+	// item in a single request.
+	BatchSize int
+	// CycleBudget is how many items a reconcile cycle may submit; the rest wait
+	// for the next cycle. Zero is unlimited.
+	CycleBudget int
+}
+
+// Scheduler spreads the admin requests of the reconcilers (SCRAM credentials,
+// quotas) over batches and a shared pool of concurrency slots, so a large
+// desired state does not flood the controller with simultaneous requests
+type Scheduler struct {
+	opts  Options
+	slots chan struct{}
+}
+
+// New creates a new scheduler
+func New(opts Options) *Scheduler {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	return &Scheduler{
+		opts:  opts,
+		slots: make(chan struct{}, opts.Concurrency),
+	}
+}
+
+// Budget splits items into those the cycle may submit and those deferred to
+// the next cycle
+func Budget[T any](s *Scheduler, items []T) ([]T, []T) {
+	if s.opts.CycleBudget <= 0 || len(items) <= s.opts.CycleBudget {
+		return items, nil
+	}
+	return items[:s.opts.CycleBudget], items[s.opts.CycleBudget:]
+}
+
+// Batches splits items into batches of at most BatchSize, in order
+func Batches[T any](s *Scheduler, items []T) [][]T {
+	if len(items) == 0 {
+		return nil
+	}
+	size := s.opts.BatchSize
+	if size <= 0 {
+		size = len(items)
+	}
+	batches := make([][]T, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		end := min(start+size, len(items))
+		batches = append(batches, items[start:end])
+	}
+	return batches
+}
+
+// Run calls fn for every batch of items, each holding one of the scheduler's
+// concurrency slots. It waits for every started batch and returns their errors
+// joined. Batches still waiting for a slot when the context is done are not run.
+func Run[T any](ctx context.Context, s *Scheduler, items []T, fn func(ctx context.Context, batch []T) error) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	for _, batch := range Batches(s, items) {
+		if !s.acquire(ctx) {
+			fail(ctx.Err())
+			break
+		}
+
+		wg.Add(1)
+		go func(batch []T) {
+			defer wg.Done()
+			defer func() { <-s.slots }()
+			if err := fn(ctx, batch); err != nil {
+				fail(err)
+			}
+		}(batch)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// acquire takes a concurrency slot, and reports false once the context is done.
+// A free slot and a done context may both be ready; no batch is started then.
+func (s *Scheduler) acquire(ctx context.Context) bool {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	if ctx.Err() != nil {
+		<-s.slots
+		return false
+	}
+	return true
+}
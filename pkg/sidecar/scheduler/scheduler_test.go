@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	now, deferred := Budget(New(Options{CycleBudget: 3}), items)
+	if fmt.Sprint(now) != "[1 2 3]" || fmt.Sprint(deferred) != "[4 5]" {
+		t.Errorf("expected [1 2 3] now and [4 5] deferred, got %v and %v", now, deferred)
+	}
+
+	now, deferred = Budget(New(Options{}), items)
+	if len(now) != 5 || deferred != nil {
+		t.Errorf("expected no budget to defer nothing, got %v and %v", now, deferred)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	if got := fmt.Sprint(Batches(New(Options{BatchSize: 2}), items)); got != "[[1 2] [3 4] [5]]" {
+		t.Errorf("unexpected batches: %s", got)
+	}
+	if got := fmt.Sprint(Batches(New(Options{}), items)); got != "[[1 2 3 4 5]]" {
+		t.Errorf("expected a single batch without a batch size, got %s", got)
+	}
+	if got := Batches(New(Options{BatchSize: 2}), []int{}); got != nil {
+		t.Errorf("expected no batches for no items, got %v", got)
+	}
+}
+
+func TestRunSharesConcurrency(t *testing.T) {
+	s := New(Options{Concurrency: 2, BatchSize: 1})
+
+	var running, peak atomic.Int32
+	fn := func(context.Context, []int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+
+	// Two reconcilers share the scheduler's slots
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Run(context.Background(), s, []int{1, 2, 3, 4}, fn); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent batches, got %d", peak.Load())
+	}
+}
+
+func TestRunJoinsErrors(t *testing.T) {
+	s := New(Options{Concurrency: 4, BatchSize: 1})
+	errOdd := errors.New("odd")
+
+	var calls atomic.Int32
+	err := Run(context.Background(), s, []int{1, 2, 3}, func(_ context.Context, batch []int) error {
+		calls.Add(1)
+		if batch[0]%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+	if !errors.Is(err, errOdd) {
+		t.Errorf("expected the batch errors, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected every batch to run despite failures, got %d", calls.Load())
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	s := New(Options{Concurrency: 1, BatchSize: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+	err := Run(ctx, s, []int{1, 2, 3}, func(context.Context, []int) error {
+		calls.Add(1)
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected the remaining batches to be skipped, got %d calls", calls.Load())
+	}
+}
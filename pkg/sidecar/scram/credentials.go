@@ -0,0 +1,112 @@
+package scram
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+const (
+	defaultIterations = 8192
+	minIterations     = 4096
+	maxIterations     = 16384
+)
+
+// Credential is a desired SCRAM credential from the mounted secret
+type Credential struct {
+	User      string `json:"user"`
+	Mechanism string `json:"mechanism"`
+	Password  string `json:"password"`
+	// Iterations is the SCRAM iteration count (4096-16384, default 8192)
+	Iterations int32 `json:"iterations,omitempty"`
+	// Replaces lists users whose credentials are removed once this credential
+	// has been verified, completing a rotation to a new username
+	Replaces []string `json:"replaces,omitempty"`
+}
+
+// File is the format of the mounted credentials secret
+type File struct {
+	Users []Credential `json:"users"`
+}
+
+// LoadFile reads and validates the credentials file. It also returns a digest of
+// the file contents so unchanged files are not reconciled again.
+func LoadFile(path string) ([]Credential, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read SCRAM credentials file: %w", err)
+	}
+
+	creds, err := ParseFile(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return creds, hex.EncodeToString(sum[:]), nil
+}
+
+// ParseFile parses and validates credentials, applying defaults
+func ParseFile(data []byte) ([]Credential, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid SCRAM credentials file: %w", err)
+	}
+
+	desired := make(map[string]bool, len(f.Users))
+	seen := make(map[string]bool, len(f.Users))
+	for i := range f.Users {
+		c := &f.Users[i]
+		c.Mechanism = strings.ToUpper(strings.TrimSpace(c.Mechanism))
+
+		if c.User == "" {
+			return nil, fmt.Errorf("SCRAM credential %d: user is required", i)
+		}
+		if _, err := ParseMechanism(c.Mechanism); err != nil {
+			return nil, fmt.Errorf("SCRAM credential for %s: %w", c.User, err)
+		}
+		if c.Password == "" {
+			return nil, fmt.Errorf("SCRAM credential for %s: password is required", c.User)
+		}
+		if c.Iterations == 0 {
+			c.Iterations = defaultIterations
+		}
+		if c.Iterations < minIterations || c.Iterations > maxIterations {
+			return nil, fmt.Errorf("SCRAM credential for %s: iterations must be between %d and %d", c.User, minIterations, maxIterations)
+		}
+
+		key := c.User + "/" + c.Mechanism
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate SCRAM credential for %s (%s)", c.User, c.Mechanism)
+		}
+		seen[key] = true
+		desired[c.User] = true
+	}
+
+	for _, c := range f.Users {
+		for _, old := range c.Replaces {
+			if desired[old] {
+				return nil, fmt.Errorf("SCRAM credential for %s replaces %s, which is itself a desired user", c.User, old)
+			}
+		}
+	}
+
+	return f.Users, nil
+}
+
+// ParseMechanism converts a SASL mechanism name into its SCRAM mechanism
+func ParseMechanism(s string) (kadm.ScramMechanism, error) {
+	switch strings.ToUpper(s) {
+	case "SCRAM-SHA-256":
+		return kadm.ScramSha256, nil
+	case "SCRAM-SHA-512":
+		return kadm.ScramSha512, nil
+	default:
+		return 0, fmt.Errorf("unsupported SCRAM mechanism: %s (supported: SCRAM-SHA-256, SCRAM-SHA-512)", s)
+	}
+}
@@ -0,0 +1,97 @@
+package scram
+
+import (
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectError bool
+	}{
+		{
+			name: "valid",
+			data: `{"users": [{"user": "admin", "mechanism": "SCRAM-SHA-512", "password": "secret"}]}`,
+		},
+		{
+			name: "same user with both mechanisms",
+			data: `{"users": [
+				{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "secret"},
+				{"user": "admin", "mechanism": "SCRAM-SHA-512", "password": "secret"}
+			]}`,
+		},
+		{
+			name:        "invalid json",
+			data:        `{"users":`,
+			expectError: true,
+		},
+		{
+			name:        "missing user",
+			data:        `{"users": [{"mechanism": "SCRAM-SHA-256", "password": "secret"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "missing password",
+			data:        `{"users": [{"user": "admin", "mechanism": "SCRAM-SHA-256"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "iterations out of range",
+			data:        `{"users": [{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "secret", "iterations": 1000}]}`,
+			expectError: true,
+		},
+		{
+			name: "duplicate credential",
+			data: `{"users": [
+				{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "a"},
+				{"user": "admin", "mechanism": "scram-sha-256", "password": "b"}
+			]}`,
+			expectError: true,
+		},
+		{
+			name: "replaces a desired user",
+			data: `{"users": [
+				{"user": "app-v1", "mechanism": "SCRAM-SHA-256", "password": "a"},
+				{"user": "app-v2", "mechanism": "SCRAM-SHA-256", "password": "b", "replaces": ["app-v1"]}
+			]}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFile([]byte(tt.data))
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestParseFileDefaults(t *testing.T) {
+	creds, err := ParseFile([]byte(`{"users": [{"user": "admin", "mechanism": " scram-sha-256 ", "password": "secret"}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds[0].Mechanism != "SCRAM-SHA-256" {
+		t.Errorf("expected normalized mechanism, got %q", creds[0].Mechanism)
+	}
+	if creds[0].Iterations != defaultIterations {
+		t.Errorf("expected default iterations %d, got %d", defaultIterations, creds[0].Iterations)
+	}
+}
+
+func TestParseMechanism(t *testing.T) {
+	if m, err := ParseMechanism("SCRAM-SHA-256"); err != nil || m != kadm.ScramSha256 {
+		t.Errorf("expected ScramSha256, got %v, %v", m, err)
+	}
+	if m, err := ParseMechanism("scram-sha-512"); err != nil || m != kadm.ScramSha512 {
+		t.Errorf("expected ScramSha512, got %v, %v", m, err)
+	}
+	if _, err := ParseMechanism("PLAIN"); err == nil {
+		t.Error("expected error for PLAIN")
+	}
+}
@@ -0,0 +1,532 @@
+package scram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scheduler"
+)
+
+// CheckName identifies the credentials loop in the freshness tracker
+const CheckName = "scram"
+
+// State is the state of credential reconciliation
+type State string
+
+const (
+	StatePending    State = "pending"
+	StateReconciled State = "reconciled"
+	StateFailed     State = "failed"
+)
+
+// Action is what reconciliation did to a credential
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	// ActionDeferred is left to the next cycle by the reconcile budget
+	ActionDeferred Action = "deferred"
+)
+
+// AdminClient defines the Kafka admin operations needed to manage SCRAM credentials.
+// This enables mocking in tests.
+type AdminClient interface {
+	DescribeUserSCRAMs(ctx context.Context, users ...string) (kadm.DescribedUserSCRAMs, error)
+	AlterUserSCRAMs(ctx context.Context, del []kadm.DeleteSCRAM, upsert []kadm.UpsertSCRAM) (kadm.AlteredUserSCRAMs, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Verifier authenticates against the cluster with a credential. Allows injection for testing.
+type Verifier func(ctx context.Context, cred Credential) error
+
+// Options configures credential reconciliation
+type Options struct {
+	// Path is the mounted credentials file
+	Path string
+	// CheckInterval is how often the file is checked for changes
+	CheckInterval time.Duration
+	// Verify authenticates with each credential before old ones are removed
+	Verify bool
+	// VerifyTimeout bounds how long a new credential may take to propagate to the brokers
+	VerifyTimeout time.Duration
+	// VerifyInterval is how often verification is retried while it propagates
+	VerifyInterval time.Duration
+	// Timeout bounds each admin request
+	Timeout time.Duration
+}
+
+// UserStatus is the outcome of reconciling a single credential. Passwords are never reported.
+type UserStatus struct {
+	User      string   `json:"user"`
+	Mechanism string   `json:"mechanism"`
+	Action    Action   `json:"action"`
+	Verified  bool     `json:"verified"`
+	Removed   []string `json:"removed,omitempty"`
+}
+
+// Status is the observable state of credential reconciliation
+type Status struct {
+	State        State        `json:"state"`
+	Message      string       `json:"message,omitempty"`
+	Users        []UserStatus `json:"users"`
+	ReconciledAt *time.Time   `json:"reconciledAt,omitempty"`
+}
+
+// Manager creates and rotates SCRAM credentials from a mounted secret
+type Manager struct {
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	verifier      Verifier
+	tracker       *freshness.Tracker
+	scheduler     *scheduler.Scheduler
+	clock         clock.Clock
+
+	mu      sync.RWMutex
+	status  Status
+	applied string
+	// upserted holds the credentials written by cycles that deferred others,
+	// so the following cycles do not write them again
+	upserted map[string]bool
+}
+
+// NewManager creates a new SCRAM credential manager
+func NewManager(kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Manager {
+	m := &Manager{
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		status:      Status{State: StatePending, Users: []UserStatus{}},
+		scheduler:   scheduler.New(scheduler.Options{}),
+		upserted:    map[string]bool{},
+	}
+	// Set default client factory and verifier
+	m.clientFactory = m.defaultClientFactory
+	m.verifier = m.defaultVerifier
+	return m
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (m *Manager) SetClientFactory(factory ClientFactory) {
+	m.clientFactory = factory
+}
+
+// SetVerifier allows overriding the credential verifier for testing
+func (m *Manager) SetVerifier(verifier Verifier) {
+	m.verifier = verifier
+}
+
+// SetTracker records every check of the credentials file with the freshness tracker
+func (m *Manager) SetTracker(tracker *freshness.Tracker) {
+	m.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (m *Manager) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
+
+// SetScheduler batches and budgets the admin requests with the scheduler shared
+// by every reconciler
+func (m *Manager) SetScheduler(s *scheduler.Scheduler) {
+	m.scheduler = s
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (m *Manager) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(m.kafkaConfig)
+}
+
+// defaultVerifier authenticates a fresh client with the credential and fetches metadata
+func (m *Manager) defaultVerifier(ctx context.Context, cred Credential) error {
+	cfg := m.kafkaConfig
+	cfg.SASL = kafkaclient.SASLConfig{
+		Enabled:   true,
+		Mechanism: cred.Mechanism,
+		Username:  cred.User,
+		Password:  cred.Password,
+	}
+	adm, cleanup, err := kafkaclient.NewAdminClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, err = adm.Metadata(ctx)
+	return err
+}
+
+// Status returns a snapshot of the reconciliation status
+func (m *Manager) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Run reconciles credentials at startup and again whenever the file changes,
+// retrying failures every CheckInterval until the context is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.opts.CheckInterval)
+	defer ticker.Stop()
+	m.tracker.Register(CheckName)
+
+	for {
+		m.tracker.Record(CheckName, m.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step reconciles the credentials file unless it is unchanged since the last
+// successful reconciliation
+func (m *Manager) Step(ctx context.Context) error {
+	creds, digest, err := LoadFile(m.opts.Path)
+	if err != nil {
+		m.fail(err)
+		return err
+	}
+
+	m.mu.RLock()
+	unchanged := digest == m.applied
+	m.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	if err := m.Reconcile(ctx, creds); err != nil {
+		// The rest of the file is reconciled by the next cycles
+		if errors.Is(err, scheduler.ErrDeferred) {
+			return nil
+		}
+		return err
+	}
+
+	m.mu.Lock()
+	m.applied = digest
+	m.mu.Unlock()
+	return nil
+}
+
+// Reconcile creates or updates every credential, verifies each one by
+// authenticating with it, and only then removes the credentials of the users it
+// replaces. Credentials that already authenticate are left untouched.
+func (m *Manager) Reconcile(ctx context.Context, creds []Credential) error {
+	adm, cleanup, err := m.clientFactory()
+	if err != nil {
+		m.fail(err)
+		return err
+	}
+	defer cleanup()
+
+	existing, err := m.describe(ctx, adm, usersOf(creds)...)
+	if err != nil {
+		m.fail(err)
+		return err
+	}
+
+	statuses := make([]UserStatus, len(creds))
+	var upserts []pendingUpsert
+	for i, c := range creds {
+		mechanism, _ := ParseMechanism(c.Mechanism)
+		statuses[i] = UserStatus{User: c.User, Mechanism: c.Mechanism, Action: ActionCreated}
+
+		if hasMechanism(existing[c.User], mechanism) {
+			statuses[i].Action = ActionUpdated
+			// Skip rewriting a credential whose password already works
+			if m.opts.Verify && m.verify(ctx, c, 0) == nil {
+				statuses[i].Action = ActionUnchanged
+				statuses[i].Verified = true
+				continue
+			}
+		}
+		if m.wasUpserted(c) {
+			continue
+		}
+		upserts = append(upserts, pendingUpsert{index: i, upsert: kadm.UpsertSCRAM{
+			User:       c.User,
+			Mechanism:  mechanism,
+			Iterations: c.Iterations,
+			Password:   c.Password,
+		}})
+	}
+
+	upserts, deferred := scheduler.Budget(m.scheduler, upserts)
+	for _, u := range deferred {
+		statuses[u.index].Action = ActionDeferred
+	}
+
+	if len(upserts) > 0 {
+		err := scheduler.Run(ctx, m.scheduler, upserts, func(ctx context.Context, batch []pendingUpsert) error {
+			ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+			defer cancel()
+			resp, err := adm.AlterUserSCRAMs(ctx, nil, upsertsOf(batch))
+			if err == nil {
+				err = alterError(resp)
+			}
+			return err
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to upsert SCRAM credentials: %w", err)
+			m.fail(err)
+			return err
+		}
+		for _, u := range upserts {
+			s := statuses[u.index]
+			m.logger.Info("scram: credential upserted", "user", s.User, "mechanism", s.Mechanism, "action", s.Action)
+		}
+	}
+
+	if len(deferred) > 0 {
+		m.mu.Lock()
+		for _, u := range upserts {
+			m.upserted[credentialKey(creds[u.index])] = true
+		}
+		m.status = Status{
+			State:   StatePending,
+			Message: fmt.Sprintf("%d of %d credentials deferred to the next cycle by the reconcile budget", len(deferred), len(creds)),
+			Users:   statuses,
+		}
+		m.mu.Unlock()
+		m.logger.Info("scram: credentials deferred to the next cycle", "deferred", len(deferred))
+		return scheduler.ErrDeferred
+	}
+
+	for i, c := range creds {
+		if statuses[i].Verified || !m.opts.Verify {
+			continue
+		}
+		if err := m.verify(ctx, c, m.opts.VerifyTimeout); err != nil {
+			err = fmt.Errorf("credential for %s could not be verified, keeping replaced credentials: %w", c.User, err)
+			m.failWith(err, statuses)
+			return err
+		}
+		statuses[i].Verified = true
+	}
+
+	for i, c := range creds {
+		removed, err := m.removeReplaced(ctx, adm, c)
+		statuses[i].Removed = removed
+		if err != nil {
+			m.failWith(err, statuses)
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	now := m.clock.Now()
+	m.status = Status{State: StateReconciled, Users: statuses, ReconciledAt: &now}
+	m.upserted = map[string]bool{}
+	m.mu.Unlock()
+	return nil
+}
+
+// pendingUpsert is a credential to write and its index in the reconciled list
+type pendingUpsert struct {
+	index  int
+	upsert kadm.UpsertSCRAM
+}
+
+func upsertsOf(batch []pendingUpsert) []kadm.UpsertSCRAM {
+	upserts := make([]kadm.UpsertSCRAM, len(batch))
+	for i, u := range batch {
+		upserts[i] = u.upsert
+	}
+	return upserts
+}
+
+// wasUpserted reports whether an earlier, deferred cycle already wrote the credential
+func (m *Manager) wasUpserted(c Credential) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.upserted[credentialKey(c)]
+}
+
+// credentialKey identifies a credential including its password, so a changed
+// password is written again
+func credentialKey(c Credential) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%s", c.User, c.Mechanism, c.Iterations, c.Password)))
+	return hex.EncodeToString(sum[:])
+}
+
+// removeReplaced deletes every credential of the users the credential replaces
+func (m *Manager) removeReplaced(ctx context.Context, adm AdminClient, c Credential) ([]string, error) {
+	if len(c.Replaces) == 0 {
+		return nil, nil
+	}
+
+	old, err := m.describe(ctx, adm, c.Replaces...)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletes []kadm.DeleteSCRAM
+	var removed []string
+	for _, user := range c.Replaces {
+		infos := old[user]
+		if len(infos) == 0 {
+			continue
+		}
+		for _, info := range infos {
+			deletes = append(deletes, kadm.DeleteSCRAM{User: user, Mechanism: info.Mechanism})
+		}
+		removed = append(removed, user)
+	}
+	if len(deletes) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+	resp, err := adm.AlterUserSCRAMs(ctx, deletes, nil)
+	if err == nil {
+		err = alterError(resp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove credentials replaced by %s: %w", c.User, err)
+	}
+
+	for _, user := range removed {
+		m.logger.Info("scram: replaced credential removed", "user", user, "replacedBy", c.User)
+	}
+	return removed, nil
+}
+
+// verify authenticates with the credential, retrying every VerifyInterval until
+// it succeeds or timeout elapses. A zero timeout tries once.
+func (m *Manager) verify(ctx context.Context, c Credential, timeout time.Duration) error {
+	deadline := m.clock.Now().Add(timeout)
+	ticker := m.clock.NewTicker(m.opts.VerifyInterval)
+	defer ticker.Stop()
+
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+		err := m.verifier(attemptCtx, c)
+		cancel()
+		if err == nil || !m.clock.Now().Before(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// describe returns the SCRAM mechanisms each user has credentials for. Users
+// without credentials are omitted.
+func (m *Manager) describe(ctx context.Context, adm AdminClient, users ...string) (map[string][]kadm.CredInfo, error) {
+	// Describing no users would describe every user in the cluster
+	if len(users) == 0 {
+		return map[string][]kadm.CredInfo{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	described, err := adm.DescribeUserSCRAMs(ctx, users...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe SCRAM credentials: %w", err)
+	}
+
+	infos := make(map[string][]kadm.CredInfo, len(described))
+	for user, d := range described {
+		if errors.Is(d.Err, kerr.ResourceNotFound) {
+			continue
+		}
+		if d.Err != nil {
+			return nil, fmt.Errorf("failed to describe SCRAM credentials of %s: %w", user, d.Err)
+		}
+		infos[user] = d.CredInfos
+	}
+	return infos, nil
+}
+
+func (m *Manager) fail(err error) {
+	m.failWith(err, m.Status().Users)
+}
+
+func (m *Manager) failWith(err error, users []UserStatus) {
+	m.logger.Error("scram: reconciliation failed", "error", err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.State = StateFailed
+	m.status.Message = err.Error()
+	m.status.Users = users
+}
+
+// StatusHandler handles GET /admin/scram requests
+func (m *Manager) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, m.Status())
+}
+
+// usersOf returns the distinct users of the credentials, sorted
+func usersOf(creds []Credential) []string {
+	seen := make(map[string]bool, len(creds))
+	var users []string
+	for _, c := range creds {
+		if !seen[c.User] {
+			seen[c.User] = true
+			users = append(users, c.User)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// hasMechanism reports whether the user has a credential for the mechanism
+func hasMechanism(infos []kadm.CredInfo, mechanism kadm.ScramMechanism) bool {
+	for _, info := range infos {
+		if info.Mechanism == mechanism {
+			return true
+		}
+	}
+	return false
+}
+
+// alterError returns the first error in an alter user SCRAM response
+func alterError(resp kadm.AlteredUserSCRAMs) error {
+	users := make([]string, 0, len(resp))
+	for user := range resp {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	for _, user := range users {
+		r := resp[user]
+		if r.Err == nil {
+			continue
+		}
+		if r.ErrMessage != "" {
+			return fmt.Errorf("%s: %w: %s", r.User, r.Err, r.ErrMessage)
+		}
+		return fmt.Errorf("%s: %w", r.User, r.Err)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package scram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/scheduler"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	DescribeUserSCRAMsFunc func(ctx context.Context, users ...string) (kadm.DescribedUserSCRAMs, error)
+	AlterUserSCRAMsFunc    func(ctx context.Context, del []kadm.DeleteSCRAM, upsert []kadm.UpsertSCRAM) (kadm.AlteredUserSCRAMs, error)
+}
+
+func (m *MockAdminClient) DescribeUserSCRAMs(ctx context.Context, users ...string) (kadm.DescribedUserSCRAMs, error) {
+	if m.DescribeUserSCRAMsFunc != nil {
+		return m.DescribeUserSCRAMsFunc(ctx, users...)
+	}
+	return kadm.DescribedUserSCRAMs{}, nil
+}
+
+func (m *MockAdminClient) AlterUserSCRAMs(ctx context.Context, del []kadm.DeleteSCRAM, upsert []kadm.UpsertSCRAM) (kadm.AlteredUserSCRAMs, error) {
+	if m.AlterUserSCRAMsFunc != nil {
+		return m.AlterUserSCRAMsFunc(ctx, del, upsert)
+	}
+	return kadm.AlteredUserSCRAMs{}, nil
+}
+
+// fakeCluster stores SCRAM passwords by user and mechanism
+type fakeCluster struct {
+	mu        sync.Mutex
+	passwords map[string]map[kadm.ScramMechanism]string
+	alters    int
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{passwords: map[string]map[kadm.ScramMechanism]string{}}
+}
+
+func (f *fakeCluster) set(user string, mechanism kadm.ScramMechanism, password string) {
+	if f.passwords[user] == nil {
+		f.passwords[user] = map[kadm.ScramMechanism]string{}
+	}
+	f.passwords[user][mechanism] = password
+}
+
+func (f *fakeCluster) client() *MockAdminClient {
+	return &MockAdminClient{
+		DescribeUserSCRAMsFunc: func(_ context.Context, users ...string) (kadm.DescribedUserSCRAMs, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			described := kadm.DescribedUserSCRAMs{}
+			for _, user := range users {
+				d := kadm.DescribedUserSCRAM{User: user}
+				if len(f.passwords[user]) == 0 {
+					d.Err = kerr.ResourceNotFound
+				}
+				for mechanism := range f.passwords[user] {
+					d.CredInfos = append(d.CredInfos, kadm.CredInfo{Mechanism: mechanism, Iterations: defaultIterations})
+				}
+				described[user] = d
+			}
+			return described, nil
+		},
+		AlterUserSCRAMsFunc: func(_ context.Context, del []kadm.DeleteSCRAM, upsert []kadm.UpsertSCRAM) (kadm.AlteredUserSCRAMs, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.alters++
+			altered := kadm.AlteredUserSCRAMs{}
+			for _, d := range del {
+				delete(f.passwords[d.User], d.Mechanism)
+				altered[d.User] = kadm.AlteredUserSCRAM{User: d.User}
+			}
+			for _, u := range upsert {
+				f.set(u.User, u.Mechanism, u.Password)
+				altered[u.User] = kadm.AlteredUserSCRAM{User: u.User}
+			}
+			return altered, nil
+		},
+	}
+}
+
+func (f *fakeCluster) verify(_ context.Context, cred Credential) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	mechanism, _ := ParseMechanism(cred.Mechanism)
+	if password, ok := f.passwords[cred.User][mechanism]; ok && password == cred.Password {
+		return nil
+	}
+	return errors.New("authentication failed")
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func newTestManager(t *testing.T, cluster *fakeCluster, contents string) *Manager {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scram.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	m := NewManager(kafkaclient.Config{}, Options{
+		Path:           path,
+		CheckInterval:  time.Millisecond,
+		Verify:         true,
+		VerifyTimeout:  50 * time.Millisecond,
+		VerifyInterval: time.Millisecond,
+		Timeout:        time.Second,
+	}, testLogger())
+	m.SetClientFactory(func() (AdminClient, func(), error) {
+		return cluster.client(), func() {}, nil
+	})
+	m.SetVerifier(cluster.verify)
+	return m
+}
+
+func TestStepCreatesAndUpdates(t *testing.T) {
+	cluster := newFakeCluster()
+	cluster.set("app", kadm.ScramSha512, "old")
+	m := newTestManager(t, cluster, `{"users": [
+		{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "admin-secret"},
+		{"user": "app", "mechanism": "scram-sha-512", "password": "new"}
+	]}`)
+
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cluster.passwords["admin"][kadm.ScramSha256] != "admin-secret" {
+		t.Error("expected admin credential to be created")
+	}
+	if cluster.passwords["app"][kadm.ScramSha512] != "new" {
+		t.Error("expected app credential to be updated")
+	}
+
+	status := m.Status()
+	if status.State != StateReconciled || status.ReconciledAt == nil {
+		t.Fatalf("expected reconciled status, got %+v", status)
+	}
+	expected := map[string]Action{"admin": ActionCreated, "app": ActionUpdated}
+	for _, u := range status.Users {
+		if u.Action != expected[u.User] || !u.Verified {
+			t.Errorf("expected %s %s and verified, got %+v", u.User, expected[u.User], u)
+		}
+	}
+}
+
+func TestStepSkipsUnchangedFile(t *testing.T) {
+	cluster := newFakeCluster()
+	m := newTestManager(t, cluster, `{"users": [{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "secret"}]}`)
+
+	for i := 0; i < 3; i++ {
+		if err := m.Step(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if cluster.alters != 1 {
+		t.Errorf("expected a single alter for an unchanged file, got %d", cluster.alters)
+	}
+}
+
+func TestStepDefersOverBudget(t *testing.T) {
+	cluster := newFakeCluster()
+	m := newTestManager(t, cluster, `{"users": [
+		{"user": "a", "mechanism": "SCRAM-SHA-256", "password": "a"},
+		{"user": "b", "mechanism": "SCRAM-SHA-256", "password": "b"},
+		{"user": "c", "mechanism": "SCRAM-SHA-256", "password": "c"}
+	]}`)
+	m.SetScheduler(scheduler.New(scheduler.Options{Concurrency: 2, BatchSize: 1, CycleBudget: 2}))
+
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("expected a deferred cycle to succeed, got %v", err)
+	}
+	status := m.Status()
+	if status.State != StatePending || status.Users[2].Action != ActionDeferred {
+		t.Fatalf("expected the third credential to be deferred, got %+v", status)
+	}
+	if _, ok := cluster.passwords["c"]; ok {
+		t.Error("expected the deferred credential not to be written")
+	}
+	if cluster.alters != 2 {
+		t.Errorf("expected one alter per batch, got %d", cluster.alters)
+	}
+
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := m.Status(); status.State != StateReconciled {
+		t.Fatalf("expected the next cycle to finish, got %+v", status)
+	}
+	if cluster.alters != 3 {
+		t.Errorf("expected only the deferred credential to be written again, got %d alters", cluster.alters)
+	}
+}
+
+func TestReconcileSkipsUpsertedWithoutVerify(t *testing.T) {
+	cluster := newFakeCluster()
+	m := newTestManager(t, cluster, `{"users": []}`)
+	m.opts.Verify = false
+	m.SetScheduler(scheduler.New(scheduler.Options{CycleBudget: 1}))
+	creds := []Credential{
+		{User: "a", Mechanism: "SCRAM-SHA-256", Password: "a", Iterations: defaultIterations},
+		{User: "b", Mechanism: "SCRAM-SHA-256", Password: "b", Iterations: defaultIterations},
+	}
+
+	if err := m.Reconcile(context.Background(), creds); !errors.Is(err, scheduler.ErrDeferred) {
+		t.Fatalf("expected ErrDeferred, got %v", err)
+	}
+	if err := m.Reconcile(context.Background(), creds); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.alters != 2 {
+		t.Errorf("expected each credential to be written once, got %d alters", cluster.alters)
+	}
+}
+
+func TestReconcileLeavesWorkingCredentials(t *testing.T) {
+	cluster := newFakeCluster()
+	cluster.set("admin", kadm.ScramSha256, "secret")
+	m := newTestManager(t, cluster, `{"users": []}`)
+
+	err := m.Reconcile(context.Background(), []Credential{
+		{User: "admin", Mechanism: "SCRAM-SHA-256", Password: "secret", Iterations: defaultIterations},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.alters != 0 {
+		t.Errorf("expected no alters for a working credential, got %d", cluster.alters)
+	}
+	if users := m.Status().Users; len(users) != 1 || users[0].Action != ActionUnchanged {
+		t.Errorf("expected unchanged credential, got %+v", users)
+	}
+}
+
+func TestReconcileRotation(t *testing.T) {
+	cluster := newFakeCluster()
+	cluster.set("app-v1", kadm.ScramSha256, "old")
+	cluster.set("app-v1", kadm.ScramSha512, "old")
+	m := newTestManager(t, cluster, `{"users": []}`)
+
+	err := m.Reconcile(context.Background(), []Credential{
+		{User: "app-v2", Mechanism: "SCRAM-SHA-512", Password: "new", Iterations: defaultIterations, Replaces: []string{"app-v1", "app-v0"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cluster.passwords["app-v2"][kadm.ScramSha512] != "new" {
+		t.Error("expected new credential to be created")
+	}
+	if len(cluster.passwords["app-v1"]) != 0 {
+		t.Errorf("expected every replaced credential to be removed, got %v", cluster.passwords["app-v1"])
+	}
+	users := m.Status().Users
+	if len(users) != 1 || len(users[0].Removed) != 1 || users[0].Removed[0] != "app-v1" {
+		t.Errorf("expected app-v1 removed, got %+v", users)
+	}
+}
+
+func TestReconcileKeepsReplacedWhenVerificationFails(t *testing.T) {
+	cluster := newFakeCluster()
+	cluster.set("app-v1", kadm.ScramSha256, "old")
+	m := newTestManager(t, cluster, `{"users": []}`)
+	m.SetVerifier(func(context.Context, Credential) error {
+		return errors.New("authentication failed")
+	})
+
+	err := m.Reconcile(context.Background(), []Credential{
+		{User: "app-v2", Mechanism: "SCRAM-SHA-256", Password: "new", Iterations: defaultIterations, Replaces: []string{"app-v1"}},
+	})
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+	if cluster.passwords["app-v1"][kadm.ScramSha256] != "old" {
+		t.Error("expected replaced credential to be kept")
+	}
+
+	status := m.Status()
+	if status.State != StateFailed || !strings.Contains(status.Message, "app-v2") {
+		t.Errorf("expected failed status naming app-v2, got %+v", status)
+	}
+}
+
+func TestReconcileAlterError(t *testing.T) {
+	m := newTestManager(t, newFakeCluster(), `{"users": []}`)
+	m.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			AlterUserSCRAMsFunc: func(_ context.Context, _ []kadm.DeleteSCRAM, upsert []kadm.UpsertSCRAM) (kadm.AlteredUserSCRAMs, error) {
+				return kadm.AlteredUserSCRAMs{
+					upsert[0].User: {User: upsert[0].User, Err: kerr.UnsupportedSaslMechanism},
+				}, nil
+			},
+		}, func() {}, nil
+	})
+
+	err := m.Reconcile(context.Background(), []Credential{
+		{User: "admin", Mechanism: "SCRAM-SHA-256", Password: "secret", Iterations: defaultIterations},
+	})
+	if !errors.Is(err, kerr.UnsupportedSaslMechanism) {
+		t.Errorf("expected UnsupportedSaslMechanism, got %v", err)
+	}
+}
+
+func TestStepInvalidFile(t *testing.T) {
+	m := newTestManager(t, newFakeCluster(), `{"users": [{"user": "admin", "mechanism": "PLAIN", "password": "secret"}]}`)
+
+	if err := m.Step(context.Background()); err == nil {
+		t.Fatal("expected error for unsupported mechanism")
+	}
+	if m.Status().State != StateFailed {
+		t.Errorf("expected failed status, got %s", m.Status().State)
+	}
+}
+
+func TestStatusHandlerOmitsPasswords(t *testing.T) {
+	cluster := newFakeCluster()
+	m := newTestManager(t, cluster, `{"users": [{"user": "admin", "mechanism": "SCRAM-SHA-256", "password": "top-secret"}]}`)
+	if err := m.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	m.StatusHandler(w, httptest.NewRequest(http.MethodGet, "/admin/scram", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "top-secret") {
+		t.Error("status response must not contain passwords")
+	}
+	var status Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if status.State != StateReconciled || len(status.Users) != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
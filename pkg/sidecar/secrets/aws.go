@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSOptions configures the AWS Secrets Manager provider
+type AWSOptions struct {
+	// Region of the secrets, e.g. eu-west-1
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken (for temporary
+	// credentials) sign the requests
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint replaces the regional endpoint, e.g. for a VPC endpoint
+	Endpoint string
+}
+
+// AWS reads the current version of secrets from AWS Secrets Manager
+type AWS struct {
+	opts   AWSOptions
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewAWS creates an AWS Secrets Manager provider
+func NewAWS(opts AWSOptions, client *http.Client) (*AWS, error) {
+	if opts.Region == "" {
+		return nil, errors.New("aws: the region is required")
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("aws: an access key ID and secret access key are required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://secretsmanager." + opts.Region + ".amazonaws.com"
+	}
+	return &AWS{opts: opts, client: client, now: time.Now}, nil
+}
+
+// Fetch implements Provider. The reference is the name or ARN of the secret,
+// with the key of a JSON secret, e.g. "prod/kafka#password".
+func (a *AWS) Fetch(ctx context.Context, ref string) (string, error) {
+	id, key, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(a.opts.Endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	SignV4(req, body, a.opts, "secretsmanager", a.now())
+
+	var resp struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if err := doJSON(a.client, req, &resp); err != nil {
+		return "", fmt.Errorf("aws: %w", err)
+	}
+	secret := string(resp.SecretBinary)
+	if resp.SecretString != nil {
+		secret = *resp.SecretString
+	}
+	return selectKey(secret, key)
+}
+
+// SignV4 signs a request with AWS Signature Version 4, covering the host and
+// every header already set on the request
+func SignV4(req *http.Request, body []byte, opts AWSOptions, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", opts.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + opts.Region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+opts.SecretAccessKey), date)
+	key = hmacSHA256(key, opts.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		opts.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultGCPEndpoint is the Secret Manager API
+	DefaultGCPEndpoint = "https://secretmanager.googleapis.com"
+	// DefaultGCPMetadataURL is the metadata server the workload's access token
+	// is fetched from
+	DefaultGCPMetadataURL = "http://metadata.google.internal"
+)
+
+// GCPOptions configures the Google Secret Manager provider
+type GCPOptions struct {
+	// Project holds the secrets of references that are not full resource names
+	Project string
+	// Endpoint replaces DefaultGCPEndpoint
+	Endpoint string
+	// MetadataURL replaces DefaultGCPMetadataURL
+	MetadataURL string
+}
+
+// GCP reads secret versions from Google Secret Manager, authenticated as the
+// workload's service account
+type GCP struct {
+	opts   GCPOptions
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCP creates a Google Secret Manager provider
+func NewGCP(opts GCPOptions, client *http.Client) *GCP {
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultGCPEndpoint
+	}
+	if opts.MetadataURL == "" {
+		opts.MetadataURL = DefaultGCPMetadataURL
+	}
+	return &GCP{opts: opts, client: client, now: time.Now}
+}
+
+// Fetch implements Provider. The reference is a secret of the project, e.g.
+// "kafka-password", optionally with its version ("kafka-password/versions/3",
+// the latest otherwise), or a full resource name
+// ("projects/p/secrets/kafka-password/versions/latest"), with the key of a
+// JSON secret after #.
+func (g *GCP) Fetch(ctx context.Context, ref string) (string, error) {
+	name, key, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(name, "projects/") {
+		if g.opts.Project == "" {
+			return "", fmt.Errorf("gcp reference %q needs a project", ref)
+		}
+		name = "projects/" + g.opts.Project + "/secrets/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(g.opts.Endpoint, "/")+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(g.client, req, &resp); err != nil {
+		return "", fmt.Errorf("gcp: %w", err)
+	}
+	return selectKey(string(resp.Payload.Data), key)
+}
+
+// accessToken returns the service account's access token, fetched from the
+// metadata server and reused until shortly before it expires
+func (g *GCP) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && g.now().Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+
+	url := strings.TrimSuffix(g.opts.MetadataURL, "/") + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(g.client, req, &resp); err != nil {
+		return "", fmt.Errorf("gcp: failed to get an access token: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("gcp: the metadata server returned no access token")
+	}
+	g.token = resp.AccessToken
+	g.tokenExpiry = g.now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
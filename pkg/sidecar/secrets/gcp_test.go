@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGCPFetch(t *testing.T) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			tokenRequests++
+			_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3600,"token_type":"Bearer"}`))
+		case "/v1/projects/prod/secrets/kafka-password/versions/latest:access",
+			"/v1/projects/other/secrets/kafka-password/versions/2:access":
+			if r.Header.Get("Authorization") != "Bearer ya29.token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			data := base64.StdEncoding.EncodeToString([]byte("s3cret"))
+			_, _ = w.Write([]byte(`{"name":"x","payload":{"data":"` + data + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gcp := NewGCP(GCPOptions{Project: "prod", Endpoint: server.URL, MetadataURL: server.URL}, server.Client())
+	for _, ref := range []string{"kafka-password", "projects/other/secrets/kafka-password/versions/2"} {
+		got, err := gcp.Fetch(context.Background(), ref)
+		if err != nil || got != "s3cret" {
+			t.Errorf("%s: expected the secret, got %q, %v", ref, got, err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected the access token to be reused, got %d token requests", tokenRequests)
+	}
+	if _, err := gcp.Fetch(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+}
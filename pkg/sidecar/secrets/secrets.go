@@ -0,0 +1,241 @@
+// Package secrets fetches credentials from an external secret manager, such as
+// HashiCorp Vault, AWS Secrets Manager or Google Secret Manager, at startup and
+// periodically after, so rotated SASL credentials and client certificates
+// reach the sidecar without a restart.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the secret refresh in the freshness tracker
+const CheckName = "secrets"
+
+// maxResponseBytes bounds the responses read from a secret manager
+const maxResponseBytes = 1 << 20
+
+// Provider fetches secrets from a secret manager
+type Provider interface {
+	// Fetch returns the value of the secret a reference names
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+// Kind is a secret manager
+type Kind string
+
+const (
+	// KindVault reads KV version 2 secrets from HashiCorp Vault
+	KindVault Kind = "vault"
+	// KindAWS reads secrets from AWS Secrets Manager
+	KindAWS Kind = "aws"
+	// KindGCP reads secret versions from Google Secret Manager
+	KindGCP Kind = "gcp"
+)
+
+// ParseKind parses a secret manager name
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(strings.TrimSpace(s))); k {
+	case KindVault, KindAWS, KindGCP:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown secret provider %q (valid: %s, %s, %s)", s, KindVault, KindAWS, KindGCP)
+	}
+}
+
+// Options configures the provider
+type Options struct {
+	Kind  Kind
+	Vault VaultOptions
+	AWS   AWSOptions
+	GCP   GCPOptions
+	// Timeout bounds every request to the secret manager
+	Timeout time.Duration
+}
+
+// New creates the provider of opts.Kind
+func New(opts Options) (Provider, error) {
+	client := &http.Client{Timeout: opts.Timeout}
+	switch opts.Kind {
+	case KindVault:
+		return NewVault(opts.Vault, client)
+	case KindAWS:
+		return NewAWS(opts.AWS, client)
+	case KindGCP:
+		return NewGCP(opts.GCP, client), nil
+	default:
+		return nil, fmt.Errorf("unknown secret provider %q", opts.Kind)
+	}
+}
+
+// ParseRef splits a reference into the secret's name and the key of a JSON
+// secret, e.g. "kafka/sidecar#password". The key is empty for a plain secret.
+func ParseRef(ref string) (name, key string, err error) {
+	name, key, _ = strings.Cut(strings.TrimSpace(ref), "#")
+	if name == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q: missing the secret name", ref)
+	}
+	return name, key, nil
+}
+
+// selectKey returns the value of key in a JSON object secret, or the whole
+// secret when key is empty
+func selectKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so it has no key %q", key)
+	}
+	return field(fields, key)
+}
+
+// field returns a string field of a secret
+func field(fields map[string]any, key string) (string, error) {
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q of the secret is not a string", key)
+	}
+	return s, nil
+}
+
+// doJSON sends a request and decodes its JSON response
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		detail := strings.TrimSpace(string(data))
+		if len(detail) > 200 {
+			detail = detail[:200]
+		}
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, detail)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Refresher fetches a set of secrets and applies them whenever one changes
+type Refresher struct {
+	provider Provider
+	refs     map[string]string
+	apply    func(values map[string]string) error
+	interval time.Duration
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+	clock    clock.Clock
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewRefresher creates a refresher of the secrets refs names, by the name they
+// are applied under. apply receives every secret whenever any changed.
+func NewRefresher(provider Provider, refs map[string]string, apply func(values map[string]string) error, interval time.Duration, logger *slog.Logger) *Refresher {
+	return &Refresher{
+		provider: provider,
+		refs:     refs,
+		apply:    apply,
+		interval: interval,
+		logger:   logger,
+		clock:    clock.Real,
+	}
+}
+
+// SetTracker records every refresh with the freshness tracker
+func (r *Refresher) SetTracker(tracker *freshness.Tracker) {
+	r.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (r *Refresher) SetClock(clk clock.Clock) {
+	r.clock = clk
+}
+
+// Values returns the secrets last applied, by name
+func (r *Refresher) Values() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.values)
+}
+
+// Step fetches every secret and, when any changed, applies them all together,
+// so that a username and password rotated together are never applied half way.
+// Nothing is applied when a fetch fails.
+func (r *Refresher) Step(ctx context.Context) error {
+	values := make(map[string]string, len(r.refs))
+	for name, ref := range r.refs {
+		value, err := r.provider.Fetch(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s from %s: %w", name, ref, err)
+		}
+		values[name] = value
+	}
+
+	previous := r.Values()
+	if maps.Equal(values, previous) {
+		return nil
+	}
+	if err := r.apply(values); err != nil {
+		return fmt.Errorf("failed to apply secrets: %w", err)
+	}
+	r.mu.Lock()
+	r.values = values
+	r.mu.Unlock()
+
+	if previous != nil {
+		var rotated []string
+		for name, value := range values {
+			if previous[name] != value {
+				rotated = append(rotated, name)
+			}
+		}
+		slices.Sort(rotated)
+		r.logger.Info("secrets: applied rotated secrets", "secrets", rotated)
+	}
+	return nil
+}
+
+// Run refreshes the secrets every interval until the context is cancelled. The
+// first refresh is left to the caller, which needs the secrets before starting.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+	r.tracker.Register(CheckName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		err := r.Step(ctx)
+		if err != nil {
+			r.logger.Warn("secrets: refresh failed, keeping the current secrets", "error", err)
+		}
+		r.tracker.Record(CheckName, err)
+	}
+}
@@ -0,0 +1,128 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeProvider serves secrets from a map
+type fakeProvider struct {
+	secrets map[string]string
+	err     error
+}
+
+func (p *fakeProvider) Fetch(_ context.Context, ref string) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	value, ok := p.secrets[ref]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestParseRef(t *testing.T) {
+	name, key, err := ParseRef("kafka/sidecar#password")
+	if err != nil || name != "kafka/sidecar" || key != "password" {
+		t.Errorf("expected kafka/sidecar and password, got %q, %q, %v", name, key, err)
+	}
+	name, key, err = ParseRef("kafka-password")
+	if err != nil || name != "kafka-password" || key != "" {
+		t.Errorf("expected kafka-password without a key, got %q, %q, %v", name, key, err)
+	}
+	if _, _, err := ParseRef("#password"); err == nil {
+		t.Error("expected an error for a reference without a name")
+	}
+}
+
+func TestSelectKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain secret", secret: "s3cret", want: "s3cret"},
+		{name: "json key", secret: `{"username":"sidecar","password":"s3cret"}`, key: "password", want: "s3cret"},
+		{name: "missing key", secret: `{"username":"sidecar"}`, key: "password", wantErr: true},
+		{name: "not a string", secret: `{"password":42}`, key: "password", wantErr: true},
+		{name: "not json", secret: "s3cret", key: "password", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectKey(tt.secret, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRefresherStep(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"kafka#username": "sidecar", "kafka#password": "one"}}
+	var applied []map[string]string
+	r := NewRefresher(provider, map[string]string{
+		"SASL_USERNAME": "kafka#username",
+		"SASL_PASSWORD": "kafka#password",
+	}, func(values map[string]string) error {
+		applied = append(applied, values)
+		return nil
+	}, 0, testLogger())
+
+	if err := r.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 || applied[0]["SASL_PASSWORD"] != "one" || applied[0]["SASL_USERNAME"] != "sidecar" {
+		t.Fatalf("expected both secrets to be applied, got %v", applied)
+	}
+
+	// Unchanged secrets are not applied again
+	if err := r.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 {
+		t.Fatalf("expected no new apply, got %d", len(applied))
+	}
+
+	provider.secrets["kafka#password"] = "two"
+	if err := r.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 2 || applied[1]["SASL_PASSWORD"] != "two" || applied[1]["SASL_USERNAME"] != "sidecar" {
+		t.Fatalf("expected the rotated password to be applied with the username, got %v", applied)
+	}
+
+	// A failed fetch keeps the current secrets
+	provider.err = errors.New("unavailable")
+	if err := r.Step(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(applied) != 2 || r.Values()["SASL_PASSWORD"] != "two" {
+		t.Errorf("expected the current secrets to be kept, got %v", r.Values())
+	}
+}
+
+func TestRefresherApplyError(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"cert": "pem"}}
+	r := NewRefresher(provider, map[string]string{"TLS_CERT": "cert"}, func(map[string]string) error {
+		return errors.New("read-only file system")
+	}, 0, testLogger())
+	if err := r.Step(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if r.Values() != nil {
+		t.Errorf("expected nothing to be recorded as applied, got %v", r.Values())
+	}
+}
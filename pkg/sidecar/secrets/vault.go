@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultVaultMount is the path of Vault's default KV version 2 engine
+const DefaultVaultMount = "secret"
+
+// VaultOptions configures the Vault provider
+type VaultOptions struct {
+	// Address is the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token authenticates the requests
+	Token string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// Mount is the path of the KV version 2 secrets engine. Empty uses
+	// DefaultVaultMount.
+	Mount string
+}
+
+// Vault reads the latest version of KV version 2 secrets
+type Vault struct {
+	opts   VaultOptions
+	client *http.Client
+}
+
+// NewVault creates a Vault provider
+func NewVault(opts VaultOptions, client *http.Client) (*Vault, error) {
+	if opts.Address == "" {
+		return nil, errors.New("vault: the address is required")
+	}
+	if opts.Token == "" {
+		return nil, errors.New("vault: a token is required")
+	}
+	if opts.Mount == "" {
+		opts.Mount = DefaultVaultMount
+	}
+	return &Vault{opts: opts, client: client}, nil
+}
+
+// Fetch implements Provider. The reference is the path of the secret within
+// the mount and the key of the value, e.g. "kafka/sidecar#password".
+func (v *Vault) Fetch(ctx context.Context, ref string) (string, error) {
+	path, key, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("vault reference %q needs a #key", ref)
+	}
+
+	url := strings.TrimSuffix(v.opts.Address, "/") + "/v1/" + strings.Trim(v.opts.Mount, "/") + "/data/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.opts.Token)
+	if v.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.opts.Namespace)
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(v.client, req, &resp); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	return field(resp.Data.Data, key)
+}
@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/kafka/sidecar" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"username":"sidecar","password":"s3cret"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	vault, err := NewVault(VaultOptions{Address: server.URL, Token: "root", Namespace: "team", Mount: "kv"}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := vault.Fetch(context.Background(), "kafka/sidecar#password")
+	if err != nil || got != "s3cret" {
+		t.Errorf("expected s3cret, got %q, %v", got, err)
+	}
+	if _, err := vault.Fetch(context.Background(), "kafka/sidecar"); err == nil {
+		t.Error("expected an error for a reference without a key")
+	}
+	if _, err := vault.Fetch(context.Background(), "kafka/other#password"); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+
+	if _, err := NewVault(VaultOptions{Address: server.URL}, server.Client()); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
@@ -0,0 +1,177 @@
+package serverprops
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Properties are the settings of a properties file
+type Properties map[string]string
+
+// Parse reads the content of a properties file: key=value or key:value lines,
+// with # and ! starting comments and a trailing backslash continuing a line. A
+// key set twice is an error, as the second silently wins in Kafka.
+func Parse(content string) (Properties, error) {
+	props := Properties{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	var pending string
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if pending == "" && (text == "" || text[0] == '#' || text[0] == '!') {
+			continue
+		}
+		if strings.HasSuffix(text, `\`) {
+			pending += strings.TrimSuffix(text, `\`)
+			continue
+		}
+		text, pending = pending+text, ""
+
+		sep := strings.IndexAny(text, "=:")
+		if sep <= 0 {
+			return nil, fmt.Errorf("line %d: expected key=value", line)
+		}
+		key, value := strings.TrimSpace(text[:sep]), strings.TrimSpace(text[sep+1:])
+		if _, dup := props[key]; dup {
+			return nil, fmt.Errorf("line %d: %s is set twice", line, key)
+		}
+		props[key] = value
+	}
+	if pending != "" {
+		return nil, errors.New("the last line is continued")
+	}
+	return props, scanner.Err()
+}
+
+// intSettings and boolSettings are the settings whose values are typed
+var (
+	intSettings  = []string{"node.id", "num.partitions", "default.replication.factor", "min.insync.replicas"}
+	boolSettings = []string{"auto.create.topics.enable", "unclean.leader.election.enable", "delete.topic.enable"}
+)
+
+// required are the settings a KRaft node cannot start without
+var required = []string{"process.roles", "node.id", "controller.listener.names", "listeners", "log.dirs"}
+
+// protocols are the security protocols of listener.security.protocol.map
+var protocols = map[string]bool{"PLAINTEXT": true, "SSL": true, "SASL_PLAINTEXT": true, "SASL_SSL": true}
+
+// listenerEntry matches a listeners or advertised.listeners entry: NAME://host:port
+var listenerEntry = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)://(\[[^\]]*\]|[^:/]*):([0-9]+)$`)
+
+// voterEntry matches a controller.quorum.voters entry: id@host:port
+var voterEntry = regexp.MustCompile(`^[0-9]+@(\[[^\]]*\]|[^:@]+):[0-9]+$`)
+
+// Validate checks props as the configuration of a KRaft node: the required
+// settings, the types of known settings, and that the listener names agree.
+// Every problem is reported, not only the first.
+func Validate(props Properties) error {
+	var errs []error
+	for _, key := range required {
+		if props[key] == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+	if props["controller.quorum.voters"] == "" && props["controller.quorum.bootstrap.servers"] == "" {
+		errs = append(errs, errors.New("controller.quorum.voters or controller.quorum.bootstrap.servers is required"))
+	}
+	for _, key := range intSettings {
+		if value := props[key]; value != "" {
+			if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer: %q", key, value))
+			}
+		}
+	}
+	for _, key := range boolSettings {
+		if value := props[key]; value != "" && value != "true" && value != "false" {
+			errs = append(errs, fmt.Errorf("%s must be true or false: %q", key, value))
+		}
+	}
+
+	for _, role := range list(props["process.roles"]) {
+		if role != "broker" && role != "controller" {
+			errs = append(errs, fmt.Errorf("process.roles: unknown role %q", role))
+		}
+	}
+	for _, voter := range list(props["controller.quorum.voters"]) {
+		if !voterEntry.MatchString(voter) {
+			errs = append(errs, fmt.Errorf("controller.quorum.voters: invalid voter %q, expected id@host:port", voter))
+		}
+	}
+
+	var bound []string
+	names := map[string]bool{}
+	for _, entry := range list(props["listeners"]) {
+		name, err := listenerName(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listeners: %w", err))
+			continue
+		}
+		if names[name] {
+			errs = append(errs, fmt.Errorf("listeners: %s is listed twice", name))
+			continue
+		}
+		bound = append(bound, name)
+		names[name] = true
+	}
+	for _, entry := range list(props["advertised.listeners"]) {
+		name, err := listenerName(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("advertised.listeners: %w", err))
+		} else if !names[name] {
+			errs = append(errs, fmt.Errorf("advertised.listeners: %s is not in listeners", name))
+		}
+	}
+	for _, name := range list(props["controller.listener.names"]) {
+		if !names[name] {
+			errs = append(errs, fmt.Errorf("controller.listener.names: %s is not in listeners", name))
+		}
+	}
+	if name := props["inter.broker.listener.name"]; name != "" && !names[name] {
+		errs = append(errs, fmt.Errorf("inter.broker.listener.name: %s is not in listeners", name))
+	}
+
+	// Without a map, Kafka only knows listeners named after a protocol
+	mapped := protocols
+	if value, ok := props["listener.security.protocol.map"]; ok {
+		mapped = map[string]bool{}
+		for _, entry := range list(value) {
+			name, protocol, ok := strings.Cut(entry, ":")
+			if !ok || !protocols[protocol] {
+				errs = append(errs, fmt.Errorf("listener.security.protocol.map: invalid entry %q", entry))
+				continue
+			}
+			mapped[name] = true
+		}
+	}
+	for _, name := range bound {
+		if !mapped[name] {
+			errs = append(errs, fmt.Errorf("listener.security.protocol.map: no protocol for %s", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listenerName returns the name of a listeners entry
+func listenerName(entry string) (string, error) {
+	match := listenerEntry.FindStringSubmatch(entry)
+	if match == nil {
+		return "", fmt.Errorf("invalid listener %q, expected NAME://host:port", entry)
+	}
+	return match[1], nil
+}
+
+// list splits a comma-separated value, dropping empty entries
+func list(value string) []string {
+	var out []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+// Package serverprops renders the Kafka container's server.properties from a
+// template and the values the sidecar discovers (node ID, rack, listeners,
+// quorum voters, log directories), and validates the result, so that a node's
+// boot configuration is generated instead of templated by hand.
+package serverprops
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// ControllerListenerName is the listener KRaft controllers are reached on
+const ControllerListenerName = "CONTROLLER"
+
+// DefaultTemplate renders a KRaft node's configuration from Values alone
+//
+//go:embed server.properties.tmpl
+var DefaultTemplate string
+
+// Values are the discovered values a template renders. The list values are
+// already formatted as Kafka expects them, except LogDirs.
+type Values struct {
+	// NodeID is node.id, the broker ID
+	NodeID int32
+	// ProcessRoles is process.roles: broker, controller or broker,controller
+	ProcessRoles string
+	// Broker reports whether ProcessRoles includes broker
+	Broker bool
+	// Rack is broker.rack, empty when unknown
+	Rack string
+	// Listeners is listeners, the address every listener of the node binds
+	Listeners string
+	// ProtocolMap is listener.security.protocol.map, covering Listeners
+	ProtocolMap            string
+	AdvertisedListeners    string
+	InternalListenerName   string
+	ControllerListenerName string
+	QuorumVoters           string
+	LogDirs                []string
+}
+
+// funcs are the functions available to templates beyond the builtins
+var funcs = template.FuncMap{"join": strings.Join}
+
+// Render executes tmpl with values and validates the result. Referring to a
+// value that does not exist fails the rendering.
+func Render(tmpl string, values Values) (string, error) {
+	t, err := template.New("server.properties").Funcs(funcs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, values); err != nil {
+		return "", fmt.Errorf("failed to render: %w", err)
+	}
+	rendered := b.String()
+
+	props, err := Parse(rendered)
+	if err != nil {
+		return "", err
+	}
+	if err := Validate(props); err != nil {
+		return "", err
+	}
+	return rendered, nil
+}
+
+// WriteFile replaces path with content, readable by the Kafka container. The
+// file is renamed into place, so Kafka never reads it half written.
+func WriteFile(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp makes the file readable by its owner only, and Kafka runs as another user
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,178 @@
+package serverprops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func brokerValues() Values {
+	return Values{
+		NodeID:                 1,
+		ProcessRoles:           "broker,controller",
+		Broker:                 true,
+		Rack:                   "aws-us-west-2/us-west-2a",
+		Listeners:              "INTERNAL://:9092,CONTROLLER://:9093",
+		ProtocolMap:            "INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT",
+		AdvertisedListeners:    "INTERNAL://kafka-1.kafka.abc123.svc.cluster.local:9092",
+		InternalListenerName:   "INTERNAL",
+		ControllerListenerName: ControllerListenerName,
+		QuorumVoters:           "0@kafka-0.kafka:9093,1@kafka-1.kafka:9093,2@kafka-2.kafka:9093",
+		LogDirs:                []string{"/var/lib/kafka/data-0", "/var/lib/kafka/data-1"},
+	}
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	rendered, err := Render(DefaultTemplate, brokerValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := Parse(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"node.id":                    "1",
+		"process.roles":              "broker,controller",
+		"broker.rack":                "aws-us-west-2/us-west-2a",
+		"log.dirs":                   "/var/lib/kafka/data-0,/var/lib/kafka/data-1",
+		"inter.broker.listener.name": "INTERNAL",
+		"advertised.listeners":       "INTERNAL://kafka-1.kafka.abc123.svc.cluster.local:9092",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("expected %s=%s, got %q", key, value, props[key])
+		}
+	}
+
+	// A controller neither advertises broker listeners nor has a rack
+	values := brokerValues()
+	values.ProcessRoles, values.Broker, values.Rack = "controller", false, ""
+	values.Listeners, values.ProtocolMap = "CONTROLLER://:9093", "CONTROLLER:PLAINTEXT"
+	rendered, err = Render(DefaultTemplate, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"advertised.listeners", "inter.broker.listener.name", "broker.rack"} {
+		if strings.Contains(rendered, key+"=") {
+			t.Errorf("expected no %s for a controller, got:\n%s", key, rendered)
+		}
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	tmpl := DefaultTemplate + "num.partitions=6\nauto.create.topics.enable=false\n"
+	rendered, err := Render(tmpl, brokerValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(rendered, "auto.create.topics.enable=false\n") {
+		t.Errorf("expected the template's own settings, got:\n%s", rendered)
+	}
+
+	for name, tmpl := range map[string]string{
+		"syntax":        "node.id={{.NodeID",
+		"unknown value": DefaultTemplate + "x={{.Unknown}}\n",
+		"invalid":       DefaultTemplate + "num.partitions=many\n",
+		"duplicate":     DefaultTemplate + "node.id=2\n",
+	} {
+		if _, err := Render(tmpl, brokerValues()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	props, err := Parse("# comment\n! comment\n\nnode.id = 1\nlog.dirs: /a,\\\n  /b\nlisteners=PLAINTEXT://:9092\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["node.id"] != "1" || props["log.dirs"] != "/a,/b" || props["listeners"] != "PLAINTEXT://:9092" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+
+	for _, content := range []string{"node.id\n", "=1\n", "a=1\na=2\n", "a=1,\\\n"} {
+		if _, err := Parse(content); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Properties {
+		return Properties{
+			"process.roles":                  "broker,controller",
+			"node.id":                        "0",
+			"controller.listener.names":      "CONTROLLER",
+			"controller.quorum.voters":       "0@kafka-0:9093",
+			"listeners":                      "INTERNAL://:9092,CONTROLLER://:9093",
+			"listener.security.protocol.map": "INTERNAL:SASL_SSL,CONTROLLER:PLAINTEXT",
+			"advertised.listeners":           "INTERNAL://kafka-0:9092",
+			"inter.broker.listener.name":     "INTERNAL",
+			"log.dirs":                       "/var/lib/kafka/data",
+		}
+	}
+	if err := Validate(valid()); err != nil {
+		t.Fatalf("expected valid, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "missing node ID", key: "node.id", value: ""},
+		{name: "non-integer node ID", key: "node.id", value: "one"},
+		{name: "unknown role", key: "process.roles", value: "broker,observer"},
+		{name: "invalid voter", key: "controller.quorum.voters", value: "kafka-0:9093"},
+		{name: "invalid listener", key: "listeners", value: "INTERNAL://:9092,CONTROLLER"},
+		{name: "duplicate listener", key: "listeners", value: "INTERNAL://:9092,INTERNAL://:9094,CONTROLLER://:9093"},
+		{name: "unbound advertised listener", key: "advertised.listeners", value: "EXTERNAL://kafka:9094"},
+		{name: "unbound controller listener", key: "controller.listener.names", value: "KRAFT"},
+		{name: "unbound inter-broker listener", key: "inter.broker.listener.name", value: "REPLICATION"},
+		{name: "unknown protocol", key: "listener.security.protocol.map", value: "INTERNAL:TLS,CONTROLLER:PLAINTEXT"},
+		{name: "unmapped listener", key: "listener.security.protocol.map", value: "CONTROLLER:PLAINTEXT"},
+		{name: "invalid boolean", key: "auto.create.topics.enable", value: "yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := valid()
+			props[tt.key] = tt.value
+			if err := Validate(props); err == nil {
+				t.Errorf("expected an error for %s=%q", tt.key, tt.value)
+			}
+		})
+	}
+
+	// Without a protocol map, listeners must be named after their protocol
+	props := valid()
+	delete(props, "listener.security.protocol.map")
+	if err := Validate(props); err == nil {
+		t.Error("expected an error for listeners without a protocol")
+	}
+
+	// Every problem is reported
+	err := Validate(Properties{"node.id": "x"})
+	if err == nil || !strings.Contains(err.Error(), "log.dirs is required") || !strings.Contains(err.Error(), "node.id must be an integer") {
+		t.Errorf("expected every problem, got %v", err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	if err := WriteFile(path, "node.id=0\n"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "node.id=0\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
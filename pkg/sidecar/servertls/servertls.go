@@ -0,0 +1,144 @@
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+)
+
+// ClientAuth is how the listener treats client certificates
+type ClientAuth string
+
+const (
+	// ClientAuthNone does not ask for client certificates
+	ClientAuthNone ClientAuth = "none"
+	// ClientAuthOptional verifies a client certificate when one is presented,
+	// so that clients without one, like the kubelet, can still connect
+	ClientAuthOptional ClientAuth = "optional"
+	// ClientAuthRequire rejects the handshake without a verified client certificate
+	ClientAuthRequire ClientAuth = "require"
+)
+
+// ParseClientAuth parses a client authentication mode
+func ParseClientAuth(s string) (ClientAuth, error) {
+	switch mode := ClientAuth(s); mode {
+	case ClientAuthNone, ClientAuthOptional, ClientAuthRequire:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown client auth mode %q (want none, optional or require)", s)
+	}
+}
+
+// Options configures TLS on the sidecar's HTTP listener
+type Options struct {
+	// CertFile and KeyFile are the served certificate and its key
+	CertFile string
+	KeyFile  string
+	// ClientCAFiles are the PEM bundles client certificates are verified against.
+	// Unlike the Kafka client, system roots are not trusted.
+	ClientCAFiles []string
+	ClientAuth    ClientAuth
+}
+
+// NewConfig returns the TLS configuration of the listener. The served
+// certificate is reloaded when its files change, so renewals need no restart.
+func NewConfig(opts Options) (*tls.Config, error) {
+	pair := &keyPair{certFile: opts.CertFile, keyFile: opts.KeyFile}
+	if _, err := pair.get(nil); err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: pair.get,
+	}
+	switch opts.ClientAuth {
+	case ClientAuthOptional:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return cfg, nil
+	}
+	pool := x509.NewCertPool()
+	for _, file := range opts.ClientCAFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA bundle %s: %w", file, err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in client CA bundle %s", file)
+		}
+	}
+	cfg.ClientCAs = pool
+	return cfg, nil
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// keyPair is a served certificate, reloaded when either file changes. When a
+// reload fails, e.g. while a secret mount is being updated, the previous
+// certificate is kept.
+type keyPair struct {
+	certFile, keyFile string
+
+	mu     sync.Mutex
+	cert   *tls.Certificate
+	stamps [2]fileStamp
+}
+
+func (k *keyPair) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var stamps [2]fileStamp
+	for i, file := range []string{k.certFile, k.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return k.previous(fmt.Errorf("failed to read server certificate: %w", err))
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	if k.cert != nil && stamps == k.stamps {
+		return k.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return k.previous(fmt.Errorf("failed to load server certificate: %w", err))
+	}
+	k.cert = &cert
+	k.stamps = stamps
+	return k.cert, nil
+}
+
+// previous returns the last loaded certificate, or err before the first load
+func (k *keyPair) previous(err error) (*tls.Certificate, error) {
+	if k.cert != nil {
+		return k.cert, nil
+	}
+	return nil, err
+}
+
+// RequireClientCert rejects protected requests that did not come with a
+// verified client certificate. With ClientAuthOptional, this keeps probes open
+// to clients without certificates while the admin endpoints need one.
+func RequireClientCert(protected func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if protected(r) && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+				_, _ = web.ReturnResponseWithCode(w, map[string]string{"error": "a verified client certificate is required"}, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
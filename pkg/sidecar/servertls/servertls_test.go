@@ -0,0 +1,231 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA is a private CA that issues server and client certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return testCA{cert: cert, key: key}
+}
+
+// issue returns a certificate for name signed by the CA
+func (ca testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writePEM writes a certificate and its key, if it has one, and returns the paths
+func writePEM(t *testing.T, dir, name string, cert tls.Certificate) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cert.PrivateKey != nil {
+		der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile
+}
+
+// serve starts a TLS server with the options and returns its URL
+func serve(t *testing.T, opts Options) string {
+	t.Helper()
+	cfg, err := NewConfig(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := RequireClientCert(func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, "/admin/")
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = cfg
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// get requests the path with the client certificate, if any, trusting ca
+func get(ca testCA, url string, cert *tls.Certificate) (int, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots, ServerName: "sidecar"}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestNewConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "sidecar-ca")
+	otherCA := newTestCA(t, "other-ca")
+	certFile, keyFile := writePEM(t, dir, "server", ca.issue(t, "sidecar", x509.ExtKeyUsageServerAuth))
+	caFile, _ := writePEM(t, dir, "ca", tls.Certificate{Certificate: [][]byte{ca.cert.Raw}})
+	client := ca.issue(t, "orchestrator", x509.ExtKeyUsageClientAuth)
+	stranger := otherCA.issue(t, "stranger", x509.ExtKeyUsageClientAuth)
+
+	tests := []struct {
+		name       string
+		clientAuth ClientAuth
+		path       string
+		cert       *tls.Certificate
+		status     int
+		wantErr    bool
+	}{
+		{name: "plain TLS", clientAuth: ClientAuthNone, path: "/health/live", status: http.StatusOK},
+		{name: "admin needs a client certificate", clientAuth: ClientAuthNone, path: "/admin/config", status: http.StatusForbidden},
+		{name: "optional without certificate", clientAuth: ClientAuthOptional, path: "/health/live", status: http.StatusOK},
+		{name: "optional admin without certificate", clientAuth: ClientAuthOptional, path: "/admin/config", status: http.StatusForbidden},
+		{name: "optional admin with certificate", clientAuth: ClientAuthOptional, path: "/admin/config", cert: &client, status: http.StatusOK},
+		{name: "optional admin with untrusted certificate", clientAuth: ClientAuthOptional, path: "/admin/config", cert: &stranger, status: http.StatusForbidden},
+		{name: "require without certificate", clientAuth: ClientAuthRequire, path: "/health/live", wantErr: true},
+		{name: "require with certificate", clientAuth: ClientAuthRequire, path: "/admin/config", cert: &client, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := serve(t, Options{CertFile: certFile, KeyFile: keyFile, ClientCAFiles: []string{caFile}, ClientAuth: tt.clientAuth})
+			status, err := get(ca, url+tt.path, tt.cert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && status != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, status)
+			}
+		})
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "sidecar-ca")
+	certFile, keyFile := writePEM(t, dir, "server", ca.issue(t, "sidecar", x509.ExtKeyUsageServerAuth))
+
+	if _, err := NewConfig(Options{CertFile: filepath.Join(dir, "missing"), KeyFile: keyFile}); err == nil {
+		t.Error("expected an error for a missing certificate")
+	}
+	if _, err := NewConfig(Options{CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire, ClientCAFiles: []string{keyFile}}); err == nil {
+		t.Error("expected an error for a client CA bundle without certificates")
+	}
+}
+
+func TestKeyPairReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "sidecar-ca")
+	certFile, keyFile := writePEM(t, dir, "server", ca.issue(t, "first", x509.ExtKeyUsageServerAuth))
+	pair := &keyPair{certFile: certFile, keyFile: keyFile}
+
+	first, err := pair.get(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Leaf.Subject.CommonName != "first" {
+		t.Fatalf("expected the first certificate, got %s", first.Leaf.Subject.CommonName)
+	}
+
+	writePEM(t, dir, "server", ca.issue(t, "renewed", x509.ExtKeyUsageServerAuth))
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	renewed, err := pair.get(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renewed.Leaf.Subject.CommonName != "renewed" {
+		t.Errorf("expected the renewed certificate, got %s", renewed.Leaf.Subject.CommonName)
+	}
+
+	// A half-written secret keeps the previous certificate
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := pair.get(nil)
+	if err != nil || kept.Leaf.Subject.CommonName != "renewed" {
+		t.Errorf("expected the renewed certificate to be kept, got %v, %v", kept, err)
+	}
+}
+
+func TestParseClientAuth(t *testing.T) {
+	for _, mode := range []string{"none", "optional", "require"} {
+		if _, err := ParseClientAuth(mode); err != nil {
+			t.Errorf("ParseClientAuth(%q) failed: %v", mode, err)
+		}
+	}
+	if _, err := ParseClientAuth("verify"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
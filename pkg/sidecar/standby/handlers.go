@@ -0,0 +1,62 @@
+package standby
+
+import (
+	"net/http"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/controlplane-com/libs-go/pkg/web"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// PromoteRequest is the body of a promotion request
+type PromoteRequest struct {
+	// RequestedBy identifies the operator in logs and status
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// PromoteResponse represents the response for the promote endpoint
+type PromoteResponse struct {
+	Moves  []reassign.Move `json:"moves"`
+	Status Status          `json:"status"`
+}
+
+// StatusHandler handles GET /admin/standby requests
+func (s *Standby) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, s.Status())
+}
+
+// PromoteHandler handles POST /admin/standby/promote requests
+func (s *Standby) PromoteHandler(w http.ResponseWriter, req *http.Request) {
+	var body PromoteRequest
+	if req.ContentLength > 0 {
+		parsed, err := web.ParseJsonRequestBody[PromoteRequest](req)
+		if err != nil {
+			_, _ = web.ReturnError(w, cplnErrors.Validationf("invalid request body: %v", err))
+			return
+		}
+		body = parsed
+	}
+
+	actor := body.RequestedBy
+	if actor == "" {
+		actor = req.RemoteAddr
+	}
+	moves, err := s.Promote(req.Context(), actor)
+	if err != nil {
+		_, _ = web.ReturnError(w, wrapError("failed to promote standby broker", err))
+		return
+	}
+	if moves == nil {
+		moves = []reassign.Move{}
+	}
+	_, _ = web.ReturnResponseWithCode(w, PromoteResponse{Moves: moves, Status: s.Status()}, http.StatusAccepted)
+}
+
+// wrapError passes domain errors through and reports anything else as internal
+func wrapError(msg string, err error) error {
+	if cplnErrors.IsDomainError(err) {
+		return err
+	}
+	return cplnErrors.Internal(msg, err)
+}
@@ -0,0 +1,418 @@
+package standby
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	cplnErrors "github.com/controlplane-com/libs-go/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/reassign"
+)
+
+// CheckName identifies the leadership enforcement loop in the freshness tracker
+const CheckName = "standby"
+
+// State is the state of the standby workflow
+type State string
+
+const (
+	// StateWaiting means the broker has not registered with the cluster yet
+	StateWaiting State = "waiting"
+	// StateSyncing means the broker still leads or is preferred for some partitions
+	StateSyncing State = "syncing"
+	// StateStandby means the broker replicates as a follower only
+	StateStandby State = "standby"
+	// StatePromoting means leadership is being handed back to the broker
+	StatePromoting State = "promoting"
+	// StatePromoted means the broker is in full service
+	StatePromoted State = "promoted"
+	// StateFailed means the promotion failed; it can be retried
+	StateFailed State = "failed"
+)
+
+// AdminClient defines the Kafka admin operations needed for standby and promotion.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	reassign.AdminClient
+	reassign.LeaderElector
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Options configures the standby workflow
+type Options struct {
+	// CheckInterval is how often leadership exclusion is enforced while in standby
+	CheckInterval time.Duration
+	// BatchSize is the number of partitions reordered per reassignment batch
+	BatchSize int
+	// PollInterval is how often in-flight reassignments are polled
+	PollInterval time.Duration
+	// Timeout bounds each metadata and election request
+	Timeout time.Duration
+}
+
+// Status is the observable state of the standby workflow
+type Status struct {
+	State    State  `json:"state"`
+	BrokerID int32  `json:"brokerId"`
+	Message  string `json:"message,omitempty"`
+	// LeaderPartitions is the number of partitions the broker led at the last check
+	LeaderPartitions int `json:"leaderPartitions"`
+	// PlannedMoves and CompletedMoves track the running promotion
+	PlannedMoves   int        `json:"plannedMoves"`
+	CompletedMoves int        `json:"completedMoves"`
+	PromotedBy     string     `json:"promotedBy,omitempty"`
+	CheckedAt      *time.Time `json:"checkedAt,omitempty"`
+	PromotedAt     *time.Time `json:"promotedAt,omitempty"`
+}
+
+// Standby keeps a warm DR broker replicating as a follower only and promotes it
+// into full service on demand
+type Standby struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	clientFactory ClientFactory
+	tracker       *freshness.Tracker
+	clock         clock.Clock
+
+	// work serializes enforcement and promotion so they never reassign concurrently
+	work sync.Mutex
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewStandby creates a new standby workflow for the local broker
+func NewStandby(brokerID int32, kafkaConfig kafkaclient.Config, opts Options, logger *slog.Logger) *Standby {
+	s := &Standby{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		status:      Status{State: StateWaiting, BrokerID: brokerID},
+	}
+	// Set default client factory
+	s.clientFactory = s.defaultClientFactory
+	return s
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (s *Standby) SetClientFactory(factory ClientFactory) {
+	s.clientFactory = factory
+}
+
+// SetTracker records every enforcement pass with the freshness tracker
+func (s *Standby) SetTracker(tracker *freshness.Tracker) {
+	s.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Standby) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (s *Standby) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(s.kafkaConfig)
+}
+
+// Status returns a snapshot of the workflow status
+func (s *Standby) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// InStandby reports whether the broker is still held out of leadership, i.e. no
+// promotion has been requested
+func (s *Standby) InStandby() bool {
+	switch s.Status().State {
+	case StatePromoting, StatePromoted, StateFailed:
+		return false
+	default:
+		return true
+	}
+}
+
+// Run enforces leadership exclusion every CheckInterval until the broker is
+// promoted or the context is cancelled
+func (s *Standby) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+	s.tracker.Register(CheckName)
+
+	for {
+		if s.Step(ctx) {
+			s.tracker.Finish(CheckName)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step performs one enforcement pass: replica lists preferring the broker are
+// reordered so it comes last, and leadership of every partition it still leads
+// is handed to the preferred replica. It reports whether enforcement is over
+// because a promotion was requested.
+func (s *Standby) Step(ctx context.Context) bool {
+	s.work.Lock()
+	defer s.work.Unlock()
+
+	if !s.InStandby() {
+		return true
+	}
+
+	adm, cleanup, err := s.clientFactory()
+	if err != nil {
+		s.logger.Warn("standby: failed to create kafka client", "error", err)
+		s.tracker.Record(CheckName, err)
+		return false
+	}
+	defer cleanup()
+
+	md, err := s.metadata(ctx, adm)
+	if err != nil {
+		s.logger.Warn("standby: failed to fetch metadata", "error", err)
+		s.tracker.Record(CheckName, err)
+		return false
+	}
+	s.tracker.Record(CheckName, nil)
+	if !brokerRegistered(md, s.brokerID) {
+		s.check(StateWaiting, "broker not yet registered in cluster metadata", 0)
+		return false
+	}
+
+	moves := reassign.PlanLeadershipExclusion(md, s.brokerID)
+	if len(moves) > 0 {
+		s.logger.Info("standby: excluding broker from preferred leadership",
+			"brokerId", s.brokerID,
+			"partitions", len(moves))
+		if err := s.reassign(ctx, adm, moves, nil); err != nil {
+			s.check(StateSyncing, err.Error(), -1)
+			return false
+		}
+	}
+
+	led := ledPartitions(md, s.brokerID)
+	if len(led) > 0 {
+		electCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+		err := reassign.ElectPreferredLeaders(electCtx, adm, led)
+		cancel()
+		if err != nil {
+			s.check(StateSyncing, err.Error(), countPartitions(led))
+			return false
+		}
+
+		// Leadership only moves once the election completes; confirm on the next pass
+		s.check(StateSyncing, fmt.Sprintf("handing off leadership of %d partitions", countPartitions(led)), countPartitions(led))
+		return false
+	}
+
+	s.check(StateStandby, "", 0)
+	return false
+}
+
+// Promote stops leadership exclusion and, in the background, makes the broker
+// the preferred leader of a fair share of the partitions it replicates before
+// electing preferred leaders. It returns the planned moves.
+func (s *Standby) Promote(ctx context.Context, actor string) ([]reassign.Move, error) {
+	if !s.beginPromotion(actor) {
+		return nil, cplnErrors.Conflictf("broker %d is already %s", s.brokerID, s.Status().State)
+	}
+	s.logger.Info("standby: promotion requested", "brokerId", s.brokerID, "actor", actor)
+
+	// Wait for a running enforcement pass so it cannot undo the promotion
+	s.work.Lock()
+
+	adm, cleanup, err := s.clientFactory()
+	if err != nil {
+		s.work.Unlock()
+		s.fail(err)
+		return nil, err
+	}
+
+	md, err := s.metadata(ctx, adm)
+	if err != nil {
+		cleanup()
+		s.work.Unlock()
+		s.fail(err)
+		return nil, err
+	}
+	if !brokerRegistered(md, s.brokerID) {
+		cleanup()
+		s.work.Unlock()
+		err = cplnErrors.Conflictf("broker %d is not registered in cluster metadata", s.brokerID)
+		s.fail(err)
+		return nil, err
+	}
+
+	moves := reassign.PlanPreferredLeadership(md, s.brokerID, reassign.PlanOptions{IncludeInternal: true})
+	s.mu.Lock()
+	s.status.PlannedMoves = len(moves)
+	s.mu.Unlock()
+
+	// The promotion outlives the request that started it
+	go func() {
+		defer s.work.Unlock()
+		defer cleanup()
+		s.promote(context.WithoutCancel(ctx), adm, moves)
+	}()
+
+	return moves, nil
+}
+
+// promote applies the preferred leadership moves and elects preferred leaders
+func (s *Standby) promote(ctx context.Context, adm AdminClient, moves []reassign.Move) {
+	if err := s.reassign(ctx, adm, moves, s.progress); err != nil {
+		s.fail(err)
+		return
+	}
+
+	electCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	if err := reassign.ElectPreferredLeaders(electCtx, adm, reassign.TopicsSet(moves)); err != nil {
+		s.fail(err)
+		return
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	s.status.State = StatePromoted
+	s.status.Message = ""
+	s.status.PromotedAt = &now
+	s.mu.Unlock()
+	s.logger.Info("standby: promoted", "brokerId", s.brokerID, "moves", len(moves))
+}
+
+// reassign executes the moves in batches, reporting each completed batch to done
+func (s *Standby) reassign(ctx context.Context, adm AdminClient, moves []reassign.Move, done func(int)) error {
+	for start := 0; start < len(moves); start += s.opts.BatchSize {
+		end := start + s.opts.BatchSize
+		if end > len(moves) {
+			end = len(moves)
+		}
+		batch := moves[start:end]
+
+		if err := reassign.Execute(ctx, adm, batch); err != nil {
+			return err
+		}
+		if err := reassign.WaitForCompletion(ctx, s.clock, adm, batch, s.opts.PollInterval); err != nil {
+			return err
+		}
+		if done != nil {
+			done(len(batch))
+		}
+	}
+	return nil
+}
+
+func (s *Standby) metadata(ctx context.Context, adm AdminClient) (kadm.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return kadm.Metadata{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	return md, nil
+}
+
+// check records the outcome of an enforcement pass unless a promotion started
+// meanwhile. A negative leader count keeps the previous one.
+func (s *Standby) check(state State, message string, leaders int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.State == StatePromoting {
+		return
+	}
+	now := s.clock.Now()
+	s.status.State = state
+	s.status.Message = message
+	if leaders >= 0 {
+		s.status.LeaderPartitions = leaders
+	}
+	s.status.CheckedAt = &now
+}
+
+// beginPromotion transitions to promoting unless a promotion is running or done.
+// A failed promotion may be retried.
+func (s *Standby) beginPromotion(actor string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.State == StatePromoting || s.status.State == StatePromoted {
+		return false
+	}
+	s.status.State = StatePromoting
+	s.status.Message = ""
+	s.status.PlannedMoves = 0
+	s.status.CompletedMoves = 0
+	s.status.PromotedBy = actor
+	return true
+}
+
+func (s *Standby) progress(completed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.CompletedMoves += completed
+}
+
+func (s *Standby) fail(err error) {
+	s.logger.Error("standby: promotion failed", "brokerId", s.brokerID, "error", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.State = StateFailed
+	s.status.Message = err.Error()
+}
+
+// brokerRegistered reports whether the broker is present in cluster metadata
+func brokerRegistered(md kadm.Metadata, brokerID int32) bool {
+	for _, b := range md.Brokers {
+		if b.NodeID == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// ledPartitions returns the partitions currently led by the broker
+func ledPartitions(md kadm.Metadata, brokerID int32) kadm.TopicsSet {
+	s := make(kadm.TopicsSet)
+	for _, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			if p.Leader != brokerID {
+				continue
+			}
+			if s[topic.Topic] == nil {
+				s[topic.Topic] = make(map[int32]struct{})
+			}
+			s[topic.Topic][p.Partition] = struct{}{}
+		}
+	}
+	return s
+}
+
+// countPartitions counts the partitions in a topic set
+func countPartitions(s kadm.TopicsSet) int {
+	n := 0
+	for _, partitions := range s {
+		n += len(partitions)
+	}
+	return n
+}
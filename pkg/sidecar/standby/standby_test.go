@@ -0,0 +1,328 @@
+package standby
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc                   func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+	AlterPartitionAssignmentsFunc  func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error)
+	ListPartitionReassignmentsFunc func(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error)
+	ElectLeadersFunc               func(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+func (m *MockAdminClient) AlterPartitionAssignments(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+	if m.AlterPartitionAssignmentsFunc != nil {
+		return m.AlterPartitionAssignmentsFunc(ctx, req)
+	}
+	return kadm.AlterPartitionAssignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ListPartitionReassignments(ctx context.Context, s kadm.TopicsSet) (kadm.ListPartitionReassignmentsResponses, error) {
+	if m.ListPartitionReassignmentsFunc != nil {
+		return m.ListPartitionReassignmentsFunc(ctx, s)
+	}
+	return kadm.ListPartitionReassignmentsResponses{}, nil
+}
+
+func (m *MockAdminClient) ElectLeaders(ctx context.Context, how kadm.ElectLeadersHow, s kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+	if m.ElectLeadersFunc != nil {
+		return m.ElectLeadersFunc(ctx, how, s)
+	}
+	return kadm.ElectLeadersResults{}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func testOptions() Options {
+	return Options{
+		CheckInterval: time.Millisecond,
+		BatchSize:     2,
+		PollInterval:  time.Millisecond,
+		Timeout:       time.Second,
+	}
+}
+
+// clusterMetadata returns a 3-broker cluster with 6 partitions at RF=3. The
+// first replica of each partition leads it.
+func clusterMetadata(assignments ...[]int32) kadm.Metadata {
+	md := kadm.Metadata{
+		Brokers: []kadm.BrokerDetail{{NodeID: 0}, {NodeID: 1}, {NodeID: 2}},
+		Topics:  kadm.TopicDetails{},
+	}
+	partitions := kadm.PartitionDetails{}
+	for i, replicas := range assignments {
+		partitions[int32(i)] = kadm.PartitionDetail{
+			Topic:     "orders",
+			Partition: int32(i),
+			Leader:    replicas[0],
+			Replicas:  replicas,
+			ISR:       replicas,
+		}
+	}
+	md.Topics["orders"] = kadm.TopicDetail{Topic: "orders", Partitions: partitions}
+	return md
+}
+
+var (
+	balanced = [][]int32{
+		{0, 1, 2}, {1, 2, 0}, {2, 0, 1},
+		{0, 1, 2}, {1, 2, 0}, {2, 0, 1},
+	}
+	followerOnly = [][]int32{
+		{0, 1, 2}, {1, 0, 2}, {0, 1, 2},
+		{0, 1, 2}, {1, 0, 2}, {1, 0, 2},
+	}
+)
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      kadm.Metadata
+		metadataErr   error
+		electErr      error
+		expectState   State
+		expectBatches int
+		expectElect   bool
+	}{
+		{
+			name:        "metadata error keeps waiting",
+			metadataErr: errors.New("connection refused"),
+			expectState: StateWaiting,
+		},
+		{
+			name:        "broker not registered yet",
+			metadata:    kadm.Metadata{Brokers: []kadm.BrokerDetail{{NodeID: 0}}},
+			expectState: StateWaiting,
+		},
+		{
+			name:          "preferred and leading partitions are handed off",
+			metadata:      clusterMetadata(balanced...),
+			expectState:   StateSyncing,
+			expectBatches: 1,
+			expectElect:   true,
+		},
+		{
+			name:          "election failure keeps syncing",
+			metadata:      clusterMetadata(balanced...),
+			electErr:      errors.New("not controller"),
+			expectState:   StateSyncing,
+			expectBatches: 1,
+			expectElect:   true,
+		},
+		{
+			name:        "follower only",
+			metadata:    clusterMetadata(followerOnly...),
+			expectState: StateStandby,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStandby(2, kafkaclient.Config{}, testOptions(), testLogger())
+
+			batches := 0
+			elected := false
+			s.SetClientFactory(func() (AdminClient, func(), error) {
+				return &MockAdminClient{
+					MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+						return tt.metadata, tt.metadataErr
+					},
+					AlterPartitionAssignmentsFunc: func(ctx context.Context, req kadm.AlterPartitionAssignmentsReq) (kadm.AlterPartitionAssignmentsResponses, error) {
+						batches++
+						for _, partitions := range req {
+							for p, replicas := range partitions {
+								if replicas[0] == 2 {
+									t.Errorf("partition %d still prefers the standby broker: %v", p, replicas)
+								}
+							}
+						}
+						return kadm.AlterPartitionAssignmentsResponses{}, nil
+					},
+					ElectLeadersFunc: func(ctx context.Context, how kadm.ElectLeadersHow, set kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+						elected = true
+						if !set.Lookup("orders", 2) || !set.Lookup("orders", 5) {
+							t.Errorf("expected partitions led by the standby broker, got %v", set)
+						}
+						return kadm.ElectLeadersResults{}, tt.electErr
+					},
+				}, func() {}, nil
+			})
+
+			if done := s.Step(context.Background()); done {
+				t.Error("expected enforcement to continue")
+			}
+
+			status := s.Status()
+			if status.State != tt.expectState {
+				t.Errorf("expected state %q, got %q (%s)", tt.expectState, status.State, status.Message)
+			}
+			if batches != tt.expectBatches {
+				t.Errorf("expected %d batches, got %d", tt.expectBatches, batches)
+			}
+			if elected != tt.expectElect {
+				t.Errorf("expected election=%v, got %v", tt.expectElect, elected)
+			}
+			if !s.InStandby() {
+				t.Error("expected broker to stay in standby")
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	s := NewStandby(2, kafkaclient.Config{}, testOptions(), testLogger())
+
+	var mu sync.Mutex
+	var elected kadm.TopicsSet
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return clusterMetadata(followerOnly...), nil
+			},
+			ElectLeadersFunc: func(ctx context.Context, how kadm.ElectLeadersHow, set kadm.TopicsSet) (kadm.ElectLeadersResults, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				elected = set
+				return kadm.ElectLeadersResults{}, nil
+			},
+		}, func() {}, nil
+	})
+
+	moves, err := s.Promote(context.Background(), "oncall")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(moves) != 2 {
+		t.Fatalf("expected 2 moves (6 partitions / 3 brokers), got %d", len(moves))
+	}
+	for _, m := range moves {
+		if m.Target[0] != 2 {
+			t.Errorf("move %s-%d does not prefer the promoted broker: %v", m.Topic, m.Partition, m.Target)
+		}
+	}
+	if s.InStandby() {
+		t.Error("expected standby to end once promotion is requested")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for s.Status().State != StatePromoted {
+		if time.Now().After(deadline) {
+			t.Fatalf("promotion did not complete: %+v", s.Status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	status := s.Status()
+	if status.CompletedMoves != 2 || status.PromotedBy != "oncall" || status.PromotedAt == nil {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	mu.Lock()
+	if len(elected["orders"]) != 2 {
+		t.Errorf("expected preferred leader election on the moved partitions, got %v", elected)
+	}
+	mu.Unlock()
+
+	if _, err := s.Promote(context.Background(), "oncall"); err == nil {
+		t.Error("expected a second promotion to be rejected")
+	}
+	if !s.Step(context.Background()) {
+		t.Error("expected enforcement to stop after promotion")
+	}
+}
+
+func TestPromoteFailureCanBeRetried(t *testing.T) {
+	s := NewStandby(2, kafkaclient.Config{}, testOptions(), testLogger())
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return nil, nil, errors.New("connection refused")
+	})
+
+	if _, err := s.Promote(context.Background(), "oncall"); err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if status := s.Status(); status.State != StateFailed {
+		t.Errorf("expected state %q, got %q", StateFailed, status.State)
+	}
+
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return clusterMetadata(followerOnly...), nil
+			},
+		}, func() {}, nil
+	})
+	if _, err := s.Promote(context.Background(), "oncall"); err != nil {
+		t.Errorf("expected retry to start, got %v", err)
+	}
+}
+
+func TestPromoteHandler(t *testing.T) {
+	s := NewStandby(2, kafkaclient.Config{}, testOptions(), testLogger())
+	s.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+				return clusterMetadata(followerOnly...), nil
+			},
+		}, func() {}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/standby/promote", strings.NewReader(`{"requestedBy":"dr-runbook"}`))
+	w := httptest.NewRecorder()
+	s.PromoteHandler(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var response PromoteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(response.Moves) != 2 || response.Status.PromotedBy != "dr-runbook" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	s := NewStandby(4, kafkaclient.Config{}, testOptions(), testLogger())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/standby", nil)
+	w := httptest.NewRecorder()
+	s.StatusHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var status Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if status.State != StateWaiting || status.BrokerID != 4 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
@@ -0,0 +1,343 @@
+// Package statsd sends the sidecar's core metrics to a StatsD or DogStatsD
+// agent, for observability stacks built around an agent rather than Prometheus.
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+)
+
+// CheckName identifies the StatsD sink in the freshness tracker
+const CheckName = "statsd"
+
+// DefaultMetrics selects the memory metrics and the core health signals: under-
+// replicated partitions, disk usage, stale checks and safe mode
+const DefaultMetrics = `kafka_memory_.*|kafka_broker_under_replicated_partitions|kafka_disk_usage_ratio|kafka_sidecar_check_stale|kafka_sidecar_safe_mode`
+
+// maxPacketBytes keeps every datagram within a typical 1500 byte MTU
+const maxPacketBytes = 1432
+
+// TagFormat is how labels are encoded as tags
+type TagFormat string
+
+const (
+	// TagFormatDatadog appends tags as |#name:value,... (DogStatsD)
+	TagFormatDatadog TagFormat = "datadog"
+	// TagFormatInflux appends tags to the name as ,name=value... (Telegraf)
+	TagFormatInflux TagFormat = "influx"
+	// TagFormatGraphite appends tags to the name as ;name=value... (Graphite 1.1)
+	TagFormatGraphite TagFormat = "graphite"
+)
+
+// ParseTagFormat parses a tag format name
+func ParseTagFormat(s string) (TagFormat, error) {
+	switch f := TagFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case TagFormatDatadog, TagFormatInflux, TagFormatGraphite:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown tag format %q (valid: %s, %s, %s)", s, TagFormatDatadog, TagFormatInflux, TagFormatGraphite)
+	}
+}
+
+// ParseTags parses a comma-separated list of name:value tags, e.g. "env:prod,team:streaming"
+func ParseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(tag, ":")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid tag %q (expected name:value)", tag)
+		}
+		tags[name] = value
+	}
+	return tags, nil
+}
+
+// ParseMetrics compiles a regular expression selecting metrics by their whole
+// Prometheus name. Empty selects DefaultMetrics.
+func ParseMetrics(s string) (*regexp.Regexp, error) {
+	if s == "" {
+		s = DefaultMetrics
+	}
+	return regexp.Compile("^(?:" + s + ")$")
+}
+
+// Options configures the StatsD sink
+type Options struct {
+	// Address is the agent as host:port (UDP) or unix:///path (Unix datagram socket)
+	Address string
+	// Prefix is prepended to every metric name, e.g. "prod."
+	Prefix string
+	// TagFormat is how labels and Tags are encoded
+	TagFormat TagFormat
+	// Tags are added to every metric
+	Tags map[string]string
+	// Metrics selects the metrics sent, matched against the whole Prometheus name
+	Metrics *regexp.Regexp
+	// Interval is how often the metrics are sent
+	Interval time.Duration
+}
+
+// Sink periodically gathers the selected Prometheus metrics and sends them to
+// the agent. Gauges are sent as gauges, and counters as the increase since the
+// previous send. Histograms and summaries are not sent.
+type Sink struct {
+	gatherer prometheus.Gatherer
+	opts     Options
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+	clock    clock.Clock
+
+	// Only Step uses the connection and counter values, so they need no lock
+	conn     net.Conn
+	counters map[string]float64
+}
+
+// NewSink creates a sink for the metrics the gatherer returns
+func NewSink(gatherer prometheus.Gatherer, opts Options, logger *slog.Logger) *Sink {
+	return &Sink{
+		gatherer: gatherer,
+		opts:     opts,
+		logger:   logger,
+		clock:    clock.Real,
+		counters: make(map[string]float64),
+	}
+}
+
+// SetTracker records every send with the freshness tracker
+func (s *Sink) SetTracker(tracker *freshness.Tracker) {
+	s.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Sink) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// Run sends the metrics every Interval until the context is cancelled
+func (s *Sink) Run(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	defer s.Close()
+	s.tracker.Register(CheckName)
+
+	for {
+		s.tracker.Record(CheckName, s.Step())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step gathers and sends the metrics once
+func (s *Sink) Step() error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		// Gatherers return what they could gather along with the error
+		s.logger.Warn("statsd: failed to gather some metrics", "error", err)
+	}
+	lines := s.lines(families)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if s.conn == nil {
+		conn, err := dial(s.opts.Address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to statsd at %s: %w", s.opts.Address, err)
+		}
+		s.conn = conn
+	}
+	for _, packet := range packets(lines) {
+		if _, err := s.conn.Write(packet); err != nil {
+			// Reconnect on the next step, e.g. after the agent's socket is recreated
+			s.Close()
+			return fmt.Errorf("failed to send to statsd at %s: %w", s.opts.Address, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the agent
+func (s *Sink) Close() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// dial connects to a UDP address or a Unix datagram socket
+func dial(address string) (net.Conn, error) {
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return net.Dial("unixgram", path)
+	}
+	return net.Dial("udp", address)
+}
+
+// lines encodes the selected series, sorted so packets are stable
+func (s *Sink) lines(families []*dto.MetricFamily) []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, mf := range families {
+		if s.opts.Metrics != nil && !s.opts.Metrics.MatchString(mf.GetName()) {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			var (
+				value float64
+				kind  string
+			)
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				value, kind = m.GetGauge().GetValue(), "g"
+			case dto.MetricType_UNTYPED:
+				value, kind = m.GetUntyped().GetValue(), "g"
+			case dto.MetricType_COUNTER:
+				key := seriesKey(mf.GetName(), m)
+				seen[key] = true
+				var ok bool
+				if value, ok = s.increase(key, m.GetCounter().GetValue()); !ok {
+					continue
+				}
+				kind = "c"
+			default:
+				continue
+			}
+			// StatsD has no representation for NaN or infinity (e.g. the OOM
+			// prediction of a working set that is not growing)
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			lines = append(lines, s.format(mf.GetName(), m.GetLabel(), value, kind))
+		}
+	}
+	// Forget counters that are gone, so a series that comes back starts over
+	// from a new baseline
+	for key := range s.counters {
+		if !seen[key] {
+			delete(s.counters, key)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// increase returns how much a counter grew since the previous step. The first
+// value of a series only sets the baseline; a counter that went down was reset
+// and its whole value is new.
+func (s *Sink) increase(key string, value float64) (float64, bool) {
+	last, ok := s.counters[key]
+	s.counters[key] = value
+	if !ok {
+		return 0, false
+	}
+	if value < last {
+		return value, true
+	}
+	return value - last, true
+}
+
+// format encodes one series in the configured tag format
+func (s *Sink) format(name string, labels []*dto.LabelPair, value float64, kind string) string {
+	tags := make([][2]string, 0, len(labels)+len(s.opts.Tags))
+	for _, p := range labels {
+		tags = append(tags, [2]string{p.GetName(), p.GetValue()})
+	}
+	for tag, value := range s.opts.Tags {
+		tags = append(tags, [2]string{tag, value})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i][0] < tags[j][0] })
+
+	var b strings.Builder
+	b.WriteString(sanitize(s.opts.Prefix + name))
+	switch s.opts.TagFormat {
+	case TagFormatInflux:
+		for _, t := range tags {
+			fmt.Fprintf(&b, ",%s=%s", sanitize(t[0]), sanitize(t[1]))
+		}
+	case TagFormatGraphite:
+		for _, t := range tags {
+			fmt.Fprintf(&b, ";%s=%s", sanitize(t[0]), sanitize(t[1]))
+		}
+	}
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if s.opts.TagFormat == TagFormatDatadog && len(tags) > 0 {
+		b.WriteString("|#")
+		for i, t := range tags {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s:%s", sanitize(t[0]), sanitize(t[1]))
+		}
+	}
+	return b.String()
+}
+
+// sanitize replaces the characters that delimit the StatsD line protocol and
+// its tag extensions
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', ',', '=', ';', '#', '@', '\n', ' ':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// seriesKey identifies a series across steps
+func seriesKey(name string, m *dto.Metric) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, p := range m.GetLabel() {
+		fmt.Fprintf(&b, "\xff%s=%s", p.GetName(), p.GetValue())
+	}
+	return b.String()
+}
+
+// packets joins lines with newlines into datagrams of at most maxPacketBytes.
+// A single longer line is sent on its own.
+func packets(lines []string) [][]byte {
+	var (
+		out [][]byte
+		buf bytes.Buffer
+	)
+	for _, line := range lines {
+		if buf.Len() > 0 && buf.Len()+1+len(line) > maxPacketBytes {
+			out = append(out, bytes.Clone(buf.Bytes()))
+			buf.Reset()
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+	}
+	if buf.Len() > 0 {
+		out = append(out, bytes.Clone(buf.Bytes()))
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package statsd
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// listen returns a UDP agent and a function reading the lines of one datagram
+func listen(t *testing.T) (string, func() []string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn.LocalAddr().String(), func() []string {
+		buf := make([]byte, 64<<10)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("expected a datagram: %v", err)
+		}
+		return strings.Split(string(buf[:n]), "\n")
+	}
+}
+
+// testRegistry returns a registry with a labelled gauge, a counter, a
+// histogram and a gauge that is not selected
+func testRegistry() (*prometheus.Registry, prometheus.Counter) {
+	registry := prometheus.NewRegistry()
+	urp := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kafka_broker_under_replicated_partitions", Help: "test"}, []string{"topic"})
+	urp.WithLabelValues("orders").Set(2)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "kafka_memory_oom_kills_total", Help: "test"})
+	counter.Add(3)
+	predicted := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kafka_memory_oom_predicted_seconds", Help: "test"})
+	predicted.Set(math.Inf(1))
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "kafka_memory_pause_seconds", Help: "test"})
+	histogram.Observe(1)
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kafka_peer_reachable", Help: "test"})
+	registry.MustRegister(urp, counter, predicted, histogram, other)
+	return registry, counter
+}
+
+func mustParseMetrics(t *testing.T, s string) *regexp.Regexp {
+	t.Helper()
+	re, err := ParseMetrics(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return re
+}
+
+func TestSinkSendsSelectedMetrics(t *testing.T) {
+	addr, read := listen(t)
+	registry, counter := testRegistry()
+
+	s := NewSink(registry, Options{
+		Address:   addr,
+		Prefix:    "prod.",
+		TagFormat: TagFormatDatadog,
+		Tags:      map[string]string{"env": "prod"},
+		Metrics:   mustParseMetrics(t, ""),
+		Interval:  time.Minute,
+	}, testLogger())
+	defer s.Close()
+
+	// The first step only sets the counter's baseline
+	if err := s.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := read()
+	want := []string{"prod.kafka_broker_under_replicated_partitions:2|g|#env:prod,topic:orders"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %v, got %v", want, lines)
+	}
+
+	counter.Add(4)
+	if err := s.Step(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines = read()
+	want = []string{
+		"prod.kafka_broker_under_replicated_partitions:2|g|#env:prod,topic:orders",
+		"prod.kafka_memory_oom_kills_total:4|c|#env:prod",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %v, got %v", want, lines)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kafka_disk_usage_ratio", Help: "test"}, []string{"path"})
+	gauge.WithLabelValues("/var/lib/kafka").Set(0.25)
+	registry.MustRegister(gauge)
+	families, _ := registry.Gather()
+
+	tests := []struct {
+		format TagFormat
+		want   string
+	}{
+		{TagFormatDatadog, "kafka_disk_usage_ratio:0.25|g|#env:prod,path:/var/lib/kafka"},
+		{TagFormatInflux, "kafka_disk_usage_ratio,env=prod,path=/var/lib/kafka:0.25|g"},
+		{TagFormatGraphite, "kafka_disk_usage_ratio;env=prod;path=/var/lib/kafka:0.25|g"},
+	}
+	for _, tt := range tests {
+		s := NewSink(registry, Options{TagFormat: tt.format, Tags: map[string]string{"env": "prod"}}, testLogger())
+		if lines := s.lines(families); len(lines) != 1 || lines[0] != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.format, tt.want, lines)
+		}
+	}
+}
+
+func TestCounterReset(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "kafka_sidecar_shed_requests_total", Help: "test"})
+	counter.Add(10)
+	registry.MustRegister(counter)
+	s := NewSink(registry, Options{TagFormat: TagFormatDatadog}, testLogger())
+
+	families, _ := registry.Gather()
+	if lines := s.lines(families); len(lines) != 0 {
+		t.Errorf("expected only the baseline on the first step, got %v", lines)
+	}
+
+	// A restarted process starts its counters again from zero
+	s.counters["kafka_sidecar_shed_requests_total"] = 25
+	if lines := s.lines(families); len(lines) != 1 || lines[0] != "kafka_sidecar_shed_requests_total:10|c" {
+		t.Errorf("expected the whole value after a reset, got %v", lines)
+	}
+}
+
+func TestPackets(t *testing.T) {
+	line := strings.Repeat("x", 600)
+	packets := packets([]string{line, line, line, strings.Repeat("y", 2000)})
+	if len(packets) != 3 {
+		t.Fatalf("expected 3 packets, got %d", len(packets))
+	}
+	if len(packets[0]) != 2*600+1 || len(packets[1]) != 600 || len(packets[2]) != 2000 {
+		t.Errorf("unexpected packet sizes: %d, %d, %d", len(packets[0]), len(packets[1]), len(packets[2]))
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags("env:prod, team:streaming,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags["env"] != "prod" || tags["team"] != "streaming" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if _, err := ParseTags("env"); err == nil {
+		t.Error("expected an error for a tag without a value")
+	}
+	if _, err := ParseTagFormat("json"); err == nil {
+		t.Error("expected an error for an unknown tag format")
+	}
+}
@@ -0,0 +1,206 @@
+package statusfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// CheckName identifies the status file loop in the freshness tracker
+const CheckName = "status_file"
+
+// Version is the format version of the document. It only changes when a field
+// is removed or changes meaning; new fields may be added within a version.
+const Version = 1
+
+// Checker runs the liveness and readiness checks. *health.Checker implements it.
+type Checker interface {
+	CheckLiveness(ctx context.Context) health.CheckResult
+	CheckReadiness(ctx context.Context) health.CheckResult
+}
+
+// Options configures the status file writer
+type Options struct {
+	// Path is the file the document is written to
+	Path string
+	// Interval is how often the checks are run
+	Interval time.Duration
+	// RefreshInterval is the longest the file goes unwritten while the status
+	// is unchanged, so readers can tell a stopped sidecar from a steady one
+	RefreshInterval time.Duration
+	// Timeout bounds each check
+	Timeout time.Duration
+}
+
+// Document is the status written to the file
+type Document struct {
+	Version  int   `json:"version"`
+	BrokerID int32 `json:"brokerId"`
+	// Status is the readiness status as served by /health/ready: healthy,
+	// degraded, standby-ready, forming or unhealthy
+	Status    string             `json:"status"`
+	Liveness  health.CheckResult `json:"liveness"`
+	Readiness health.CheckResult `json:"readiness"`
+	// Stale lists the checks and background loops that have not succeeded
+	// within CHECK_STALE_AFTER
+	Stale []string `json:"stale,omitempty"`
+	// ChangedAt is when any of the fields above last changed
+	ChangedAt time.Time `json:"changedAt"`
+	// UpdatedAt is when the file was written. A file older than the refresh
+	// interval means the sidecar has stopped updating it.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Writer periodically runs the health checks and atomically writes the outcome
+// to a file, for node agents and init containers that cannot use HTTP
+type Writer struct {
+	brokerID int32
+	checker  Checker
+	opts     Options
+	logger   *slog.Logger
+	tracker  *freshness.Tracker
+	clock    clock.Clock
+
+	// last is the last document written, owned by the Run goroutine
+	last *Document
+}
+
+// NewWriter creates a new status file writer
+func NewWriter(brokerID int32, checker Checker, opts Options, logger *slog.Logger) *Writer {
+	return &Writer{
+		brokerID: brokerID,
+		checker:  checker,
+		opts:     opts,
+		logger:   logger,
+		clock:    clock.Real,
+	}
+}
+
+// SetTracker records every write with the freshness tracker. The document
+// reports the tracker's stale checks.
+func (w *Writer) SetTracker(tracker *freshness.Tracker) {
+	w.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (w *Writer) SetClock(clk clock.Clock) {
+	w.clock = clk
+}
+
+// Run checks every Interval until the context is cancelled
+func (w *Writer) Run(ctx context.Context) {
+	ticker := w.clock.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	w.tracker.Register(CheckName)
+
+	for {
+		w.tracker.Record(CheckName, w.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step runs the checks once and writes the file when the status changed or the
+// file is due for a refresh
+func (w *Writer) Step(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.Timeout)
+	defer cancel()
+
+	readiness := w.checker.CheckReadiness(ctx)
+	doc := Document{
+		Version:   Version,
+		BrokerID:  w.brokerID,
+		Status:    readinessStatus(readiness),
+		Liveness:  w.checker.CheckLiveness(ctx),
+		Readiness: readiness,
+		Stale:     w.tracker.StaleChecks(),
+	}
+
+	now := w.clock.Now()
+	changed := w.last == nil || !sameStatus(*w.last, doc)
+	if !changed && now.Sub(w.last.UpdatedAt) < w.opts.RefreshInterval {
+		return nil
+	}
+	doc.ChangedAt = now
+	if !changed {
+		doc.ChangedAt = w.last.ChangedAt
+	}
+	doc.UpdatedAt = now
+
+	if err := writeJSON(w.opts.Path, doc); err != nil {
+		w.logger.Warn("statusfile: failed to write status file", "path", w.opts.Path, "error", err)
+		return err
+	}
+	if changed && w.last != nil {
+		w.logger.Info("statusfile: status changed", "from", w.last.Status, "to", doc.Status)
+	}
+	w.last = &doc
+	return nil
+}
+
+// readinessStatus maps a readiness result to the status /health/ready reports
+func readinessStatus(result health.CheckResult) string {
+	switch {
+	case result.Forming:
+		return "forming"
+	case !result.Healthy:
+		return "unhealthy"
+	case result.Degraded:
+		return "degraded"
+	case result.Standby:
+		return "standby-ready"
+	default:
+		return "healthy"
+	}
+}
+
+// sameStatus reports whether two documents differ only in their timestamps
+func sameStatus(a, b Document) bool {
+	return a.Status == b.Status &&
+		a.Liveness == b.Liveness &&
+		a.Readiness == b.Readiness &&
+		slices.Equal(a.Stale, b.Stale)
+}
+
+// writeJSON atomically replaces path with the JSON encoding of v, so readers
+// never see a partly written file
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	// CreateTemp makes the file readable by its owner only
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
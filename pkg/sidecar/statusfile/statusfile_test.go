@@ -0,0 +1,137 @@
+package statusfile
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+)
+
+// MockChecker is a mock implementation of Checker for testing
+type MockChecker struct {
+	Liveness  health.CheckResult
+	Readiness health.CheckResult
+}
+
+func (m *MockChecker) CheckLiveness(context.Context) health.CheckResult {
+	return m.Liveness
+}
+
+func (m *MockChecker) CheckReadiness(context.Context) health.CheckResult {
+	return m.Readiness
+}
+
+func newTestWriter(t *testing.T, checker Checker) (*Writer, *clock.Fake, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "status.json")
+	w := NewWriter(2, checker, Options{
+		Path:            path,
+		Interval:        10 * time.Second,
+		RefreshInterval: time.Minute,
+		Timeout:         time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	w.SetClock(clk)
+	return w, clk, path
+}
+
+func readDocument(t *testing.T, path string) Document {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read status file: %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid status file: %v", err)
+	}
+	return doc
+}
+
+func TestStepWritesOnChange(t *testing.T) {
+	checker := &MockChecker{
+		Liveness:  health.CheckResult{Healthy: true},
+		Readiness: health.CheckResult{Healthy: false, Message: "broker has under-replicated partitions"},
+	}
+	w, clk, path := newTestWriter(t, checker)
+
+	if err := w.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := readDocument(t, path)
+	if first.Version != Version || first.BrokerID != 2 || first.Status != "unhealthy" || !first.Liveness.Healthy {
+		t.Errorf("unexpected document: %+v", first)
+	}
+	if !first.UpdatedAt.Equal(clk.Now()) || !first.ChangedAt.Equal(clk.Now()) {
+		t.Errorf("expected both timestamps at the first write, got %+v", first)
+	}
+
+	// Unchanged within the refresh interval: not rewritten
+	clk.Advance(10 * time.Second)
+	if err := w.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc := readDocument(t, path); !doc.UpdatedAt.Equal(first.UpdatedAt) {
+		t.Errorf("expected no write while unchanged, got %+v", doc)
+	}
+
+	// A change is written at once
+	checker.Readiness = health.CheckResult{Healthy: true}
+	clk.Advance(10 * time.Second)
+	if err := w.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed := readDocument(t, path)
+	if changed.Status != "healthy" || !changed.ChangedAt.Equal(clk.Now()) {
+		t.Errorf("expected the change to be written, got %+v", changed)
+	}
+
+	// Unchanged past the refresh interval: rewritten, keeping ChangedAt
+	clk.Advance(time.Minute)
+	if err := w.Step(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	refreshed := readDocument(t, path)
+	if !refreshed.UpdatedAt.Equal(clk.Now()) || !refreshed.ChangedAt.Equal(changed.ChangedAt) {
+		t.Errorf("expected a refresh keeping the change time, got %+v", refreshed)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the status file, got %d entries", len(entries))
+	}
+}
+
+func TestStepWriteError(t *testing.T) {
+	w, _, _ := newTestWriter(t, &MockChecker{})
+	w.opts.Path = filepath.Join(t.TempDir(), "missing", "status.json")
+	if err := w.Step(context.Background()); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
+
+func TestReadinessStatus(t *testing.T) {
+	tests := []struct {
+		result health.CheckResult
+		want   string
+	}{
+		{health.CheckResult{Healthy: true}, "healthy"},
+		{health.CheckResult{Healthy: true, Degraded: true, Standby: true}, "degraded"},
+		{health.CheckResult{Healthy: true, Standby: true}, "standby-ready"},
+		{health.CheckResult{Healthy: false, Forming: true}, "forming"},
+		{health.CheckResult{Healthy: false}, "unhealthy"},
+	}
+	for _, tt := range tests {
+		if got := readinessStatus(tt.result); got != tt.want {
+			t.Errorf("readinessStatus(%+v) = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+}
@@ -1,20 +1,61 @@
 package types
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/alerting"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/auth"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/backup"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/cors"
 	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
-	"github.com/controlplane-com/libs-go/pkg/config"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/handshake"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kraft"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/listeners"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/otlp"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requesterrors"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/requestlog"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/retry"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/secrets"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/serverprops"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/servertls"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/statsd"
 )
 
 // Config holds the configuration for the Kafka sidecar
 type ConfigSchema struct {
+	// Role selects the node type the sidecar runs alongside (broker, controller,
+	// standby, mirrormaker, connect) and with it which subsystems start. See Profile.
+	Role string `cpln:"default:broker;env:ROLE"`
+
 	// BrokerID is the Kafka broker ID
 	// Auto-discovered from $HOSTNAME if not set (format: workload-N -> N)
 	BrokerID int32 `cpln:"default:0;env:BROKER_ID"`
 
+	// BrokerIDOffset is added to the replica index of an auto-discovered broker
+	// ID, for workloads sharing one cluster or clusters with non-zero-based IDs
+	BrokerIDOffset int32 `cpln:"default:0;env:BROKER_ID_OFFSET"`
+
+	// BrokerIDMap maps hostnames to broker IDs as a comma-separated list of
+	// hostname=brokerID pairs, e.g. kafka-0=100,kafka-1=101. A mapped hostname
+	// ignores BrokerIDOffset.
+	BrokerIDMap string `cpln:"env:BROKER_ID_MAP"`
+
+	// BrokerIDConflictCheckEnabled fails readiness while the cluster metadata
+	// lists the broker ID on a host other than the broker's advertised ones
+	BrokerIDConflictCheckEnabled bool `cpln:"default:false;env:BROKER_ID_CONFLICT_CHECK_ENABLED"`
+
 	// WorkloadName is the name of the workload for building per-pod hostnames.
 	// Auto-discovered from CPLN_WORKLOAD if not set.
 	WorkloadName string `cpln:"env:WORKLOAD_NAME"`
@@ -26,16 +67,147 @@ type ConfigSchema struct {
 	// ReplicaCount is the number of Kafka replicas for building bootstrap server list
 	ReplicaCount int `cpln:"default:1;env:REPLICA_COUNT"`
 
+	// ReplicaCountFromAPI reads ReplicaCount from the workload's scale in the
+	// Control Plane API, falling back to REPLICA_COUNT when the API is unavailable
+	ReplicaCountFromAPI bool `cpln:"default:false;env:REPLICA_COUNT_FROM_API"`
+
+	// CplnEndpoint is the Control Plane API, injected into workloads
+	CplnEndpoint string `cpln:"default:https://api.cpln.io;env:CPLN_ENDPOINT"`
+
+	// CplnToken is the workload's identity token for the Control Plane API,
+	// injected into workloads or read from a mounted file with CPLN_TOKEN_FILE
+	CplnToken string `cpln:"env:CPLN_TOKEN;sensitive"`
+
+	// Locations lists every location of a stretch cluster, e.g.
+	// aws-us-west-2,aws-us-east-1, so the bootstrap servers include the replicas
+	// of the other locations, reached through the Control Plane mesh. Empty for
+	// a cluster in one location.
+	Locations string `cpln:"env:LOCATIONS"`
+
+	// GvcName is the GVC's name, which the mesh hostnames of the replicas in
+	// other locations are under. Auto-discovered from CPLN_GVC if not set.
+	GvcName string `cpln:"env:GVC_NAME"`
+
+	// Rack is the broker's broker.rack. Derived from CPLN_LOCATION and the zone in
+	// RackZoneFile if not set.
+	Rack string `cpln:"env:RACK"`
+
+	// RackZoneFile is a downward API file holding the zone the replica runs in,
+	// alone or among the pod's labels as topology.kubernetes.io/zone
+	RackZoneFile string `cpln:"env:RACK_ZONE_FILE"`
+
+	// RackEnvFile is an env file the rack is written to as KAFKA_BROKER_RACK at
+	// startup, for the Kafka container to source for broker.rack
+	RackEnvFile string `cpln:"env:RACK_ENV_FILE"`
+
+	// KRaftControllerPort is the port of the controllers in the generated
+	// controller.quorum.voters
+	KRaftControllerPort int `cpln:"default:9093;env:KRAFT_CONTROLLER_PORT"`
+
+	// KRaftControllerWorkload is the workload running the controllers, for a
+	// cluster whose controllers run apart from the brokers. Defaults to this
+	// workload, whose replicas are then the voters, with the broker IDs of
+	// BrokerIDOffset and BrokerIDMap.
+	KRaftControllerWorkload string `cpln:"env:KRAFT_CONTROLLER_WORKLOAD"`
+
+	// KRaftControllerCount is the number of replicas of KRaftControllerWorkload
+	// in every location
+	KRaftControllerCount int `cpln:"default:3;env:KRAFT_CONTROLLER_COUNT"`
+
+	// KRaftControllerIDOffset is added to the replica index of the replicas of
+	// KRaftControllerWorkload to get their node IDs
+	KRaftControllerIDOffset int32 `cpln:"default:0;env:KRAFT_CONTROLLER_ID_OFFSET"`
+
+	// KRaftVotersFile is an env file the generated controller.quorum.voters is
+	// written to as KAFKA_CONTROLLER_QUORUM_VOTERS, at startup and by --init
+	KRaftVotersFile string `cpln:"env:KRAFT_VOTERS_FILE"`
+
+	// InternalListenerName is the listener advertised on the replica's own
+	// headless Service record and KafkaPort
+	InternalListenerName string `cpln:"default:INTERNAL;env:INTERNAL_LISTENER_NAME"`
+
+	// ExternalListenerHost is the hostname advertised to clients outside the
+	// GVC, with {id} replaced by the broker ID and {index} by the replica index.
+	// Empty advertises no external listener.
+	ExternalListenerHost string `cpln:"env:EXTERNAL_LISTENER_HOST"`
+
+	// ExternalListenerName is the name of the external listener
+	ExternalListenerName string `cpln:"default:EXTERNAL;env:EXTERNAL_LISTENER_NAME"`
+
+	// ExternalListenerPort is the external port of every broker not in
+	// ExternalListenerPortMap
+	ExternalListenerPort int `cpln:"default:9094;env:EXTERNAL_LISTENER_PORT"`
+
+	// ExternalListenerPortMap maps broker IDs to their own external port as a
+	// comma-separated list of brokerID=port pairs, for brokers behind one host
+	ExternalListenerPortMap string `cpln:"env:EXTERNAL_LISTENER_PORT_MAP"`
+
+	// AdvertisedListenersFile is an env file the advertised listeners are written
+	// to as KAFKA_ADVERTISED_LISTENERS, at startup and by --init
+	AdvertisedListenersFile string `cpln:"env:ADVERTISED_LISTENERS_FILE"`
+
+	// ServerPropertiesFile is where --render-config writes the node's rendered
+	// server.properties
+	ServerPropertiesFile string `cpln:"env:SERVER_PROPERTIES_FILE"`
+
+	// ServerPropertiesTemplate is a text/template file server.properties is
+	// rendered from. Empty renders the built-in template.
+	ServerPropertiesTemplate string `cpln:"env:SERVER_PROPERTIES_TEMPLATE"`
+
+	// LogDirs is a comma-separated list of the Kafka container's data
+	// directories, rendered as log.dirs. Defaults to DiskUsagePaths.
+	LogDirs string `cpln:"env:LOG_DIRS"`
+
+	// ClusterID is the KRaft cluster ID every LogDirs meta.properties must hold,
+	// as printed by kafka-storage random-uuid
+	ClusterID string `cpln:"env:CLUSTER_ID"`
+
+	// ClusterIDFile stores the cluster ID the broker first reports, on a volume
+	// that outlives the pod, when ClusterID is not set. Readiness fails while the
+	// broker reports another cluster ID than either.
+	ClusterIDFile string `cpln:"env:CLUSTER_ID_FILE"`
+
+	// StorageInitEnabled checks the meta.properties of LogDirs against ClusterID
+	// and BrokerID at startup, as --init-storage does, and writes it to fresh
+	// volumes. The sidecar then needs LogDirs mounted writable.
+	StorageInitEnabled bool `cpln:"default:false;env:STORAGE_INIT_ENABLED"`
+
 	// KafkaPort is the Kafka broker port
 	KafkaPort int `cpln:"default:9092;env:KAFKA_PORT"`
 
+	// DNSSuffix is the cluster DNS zone of the per-pod hostnames, for clusters
+	// with a custom domain
+	DNSSuffix string `cpln:"default:svc.cluster.local;env:DNS_SUFFIX"`
+
+	// ReplicaHostnamePrefix precedes the replica index in the per-pod hostnames,
+	// e.g. replica- for replica-0. Defaults to the workload name and a hyphen.
+	ReplicaHostnamePrefix string `cpln:"env:REPLICA_HOSTNAME_PREFIX"`
+
+	// BootstrapRefreshInterval is how often the replica count is discovered
+	// again, from the Control Plane API with ReplicaCountFromAPI or else from
+	// DNS, to follow scaling in auto-built bootstrap servers. Zero disables it.
+	BootstrapRefreshInterval time.Duration `cpln:"default:1m;env:BOOTSTRAP_REFRESH_INTERVAL"`
+
+	// DNSPreflightEnabled fails GET /health/startup until the host of every
+	// bootstrap server resolves
+	DNSPreflightEnabled bool `cpln:"default:false;env:DNS_PREFLIGHT_ENABLED"`
+
+	// DNSPreflightInterval is how often unresolved bootstrap hosts are retried,
+	// by the startup check and by --wait-for-dns
+	DNSPreflightInterval time.Duration `cpln:"default:2s;env:DNS_PREFLIGHT_INTERVAL"`
+
+	// DNSPreflightTimeout is how long --wait-for-dns waits for the bootstrap
+	// hosts to resolve before it fails
+	DNSPreflightTimeout time.Duration `cpln:"default:10m;env:DNS_PREFLIGHT_TIMEOUT"`
+
 	// BootstrapServers is the Kafka bootstrap servers list. Auto-built from
-	// WorkloadName/GvcAlias/ReplicaCount via the StatefulSet's headless Service per-pod
+	// WorkloadName/GvcAlias/ReplicaCount/DNSSuffix via the StatefulSet's headless Service per-pod
 	// DNS if not set explicitly. We always use the in-cluster headless path because
 	// the orchestrator only ever talks to brokers it's co-located with — there's no
 	// cross-cluster or cross-location use case that would justify the cpln.local mesh
 	// path, and that path's `-ext` Service readiness gating creates a chicken-and-egg
-	// deadlock during cold start.
+	// deadlock during cold start. Only the replicas of the other locations of a
+	// stretch cluster (Locations) are reached through the mesh.
 	BootstrapServers string `cpln:"env:BOOTSTRAP_SERVERS"`
 
 	// SASL authentication configuration
@@ -51,71 +223,1943 @@ type ConfigSchema struct {
 	// SASLPassword is the SASL password
 	SASLPassword string `cpln:"env:SASL_PASSWORD;sensitive"`
 
+	// TLS configuration
+	// TLSEnabled dials the brokers with TLS and monitors certificate expiry
+	TLSEnabled bool `cpln:"default:false;env:TLS_ENABLED"`
+
+	// TLSCertFile is the client certificate presented to the brokers (mutual TLS)
+	TLSCertFile string `cpln:"env:TLS_CERT_FILE"`
+
+	// TLSKeyFile is the private key of TLSCertFile
+	TLSKeyFile string `cpln:"env:TLS_KEY_FILE"`
+
+	// TLSCAFiles is a comma-separated list of PEM CA bundles trusted in addition to
+	// the system roots. Bundles are reloaded when they change.
+	TLSCAFiles string `cpln:"env:TLS_CA_FILES"`
+
+	// TLSExpiryCheckInterval is how often certificate expiry is checked
+	TLSExpiryCheckInterval time.Duration `cpln:"default:1m;env:TLS_EXPIRY_CHECK_INTERVAL"`
+
+	// TLSExpiryBrokerAddress is the local broker's TLS listener whose certificate is
+	// checked. Defaults to localhost:KAFKA_PORT on nodes that run broker checks.
+	TLSExpiryBrokerAddress string `cpln:"env:TLS_EXPIRY_BROKER_ADDRESS"`
+
+	// TLSExpiryMinValidity fails readiness when a certificate expires sooner. Zero disables it.
+	TLSExpiryMinValidity time.Duration `cpln:"default:0s;env:TLS_EXPIRY_MIN_VALIDITY"`
+
+	// Secret manager configuration
+	// SecretProvider fetches the SASL credentials and the client certificate
+	// from a secret manager: vault, aws or gcp. Empty disables it.
+	SecretProvider string `cpln:"env:SECRET_PROVIDER"`
+
+	// SecretRefreshInterval is how often the secrets are fetched again to pick
+	// up rotations. Zero fetches them at startup only.
+	SecretRefreshInterval time.Duration `cpln:"default:5m;env:SECRET_REFRESH_INTERVAL"`
+
+	// SecretSASLUsernameRef and SecretSASLPasswordRef name the SASL credentials
+	// in the secret manager, as the secret and the key of a JSON secret, e.g.
+	// kafka/sidecar#password. They replace SASLUsername and SASLPassword.
+	SecretSASLUsernameRef string `cpln:"env:SECRET_SASL_USERNAME_REF"`
+	SecretSASLPasswordRef string `cpln:"env:SECRET_SASL_PASSWORD_REF"`
+
+	// SecretTLSCertRef and SecretTLSKeyRef name the PEM client certificate and
+	// key in the secret manager. They are written to TLSCertFile and TLSKeyFile.
+	SecretTLSCertRef string `cpln:"env:SECRET_TLS_CERT_REF"`
+	SecretTLSKeyRef  string `cpln:"env:SECRET_TLS_KEY_REF"`
+
+	// SecretTimeout bounds every request to the secret manager
+	SecretTimeout time.Duration `cpln:"default:10s;env:SECRET_TIMEOUT"`
+
+	// VaultAddr is the Vault server, e.g. https://vault.example.com:8200
+	VaultAddr string `cpln:"env:VAULT_ADDR"`
+
+	// VaultToken authenticates to Vault
+	VaultToken string `cpln:"env:VAULT_TOKEN;sensitive"`
+
+	// VaultNamespace is the Vault Enterprise namespace, if any
+	VaultNamespace string `cpln:"env:VAULT_NAMESPACE"`
+
+	// VaultKVMount is the path of the KV version 2 secrets engine
+	VaultKVMount string `cpln:"default:secret;env:VAULT_KV_MOUNT"`
+
+	// AWSRegion is the region of the AWS Secrets Manager secrets
+	AWSRegion string `cpln:"env:AWS_REGION"`
+
+	// AWSAccessKeyID, AWSSecretAccessKey and AWSSessionToken (for temporary
+	// credentials) sign the requests to AWS Secrets Manager
+	AWSAccessKeyID     string `cpln:"env:AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string `cpln:"env:AWS_SECRET_ACCESS_KEY;sensitive"`
+	AWSSessionToken    string `cpln:"env:AWS_SESSION_TOKEN;sensitive"`
+
+	// AWSSecretsManagerEndpoint replaces the regional endpoint, e.g. for a VPC endpoint
+	AWSSecretsManagerEndpoint string `cpln:"env:AWS_SECRETSMANAGER_ENDPOINT"`
+
+	// GCPProject holds the Google Secret Manager secrets named without a project
+	GCPProject string `cpln:"env:GCP_PROJECT"`
+
 	// CheckTimeout is the health check timeout duration
 	CheckTimeout time.Duration `cpln:"default:10s;env:CHECK_TIMEOUT"`
 
+	// CheckTimeoutMax bounds the ?timeout= override of the health endpoints. The
+	// HTTP server's 30s write timeout applies regardless. Zero ignores the parameter.
+	CheckTimeoutMax time.Duration `cpln:"default:20s;env:CHECK_TIMEOUT_MAX"`
+
+	// Kafka retry configuration
+	// KafkaRetryAttempts is how many times a health check's Kafka call is made
+	// before it fails, when the failure is transient (1 disables retries)
+	KafkaRetryAttempts int `cpln:"default:3;env:KAFKA_RETRY_ATTEMPTS"`
+
+	// KafkaRetryBaseDelay is the backoff before the first retry of a Kafka call.
+	// It doubles for every retry after, up to KafkaRetryMaxDelay.
+	KafkaRetryBaseDelay time.Duration `cpln:"default:100ms;env:KAFKA_RETRY_BASE_DELAY"`
+
+	// KafkaRetryMaxDelay caps the backoff between retries of a Kafka call
+	KafkaRetryMaxDelay time.Duration `cpln:"default:2s;env:KAFKA_RETRY_MAX_DELAY"`
+
+	// KafkaRetryJitter randomizes every backoff by up to this fraction (0.0-1.0)
+	KafkaRetryJitter float64 `cpln:"default:0.2;env:KAFKA_RETRY_JITTER"`
+
+	// FormationGrace is how long after sidecar startup readiness reports "forming"
+	// instead of "unhealthy" while no brokers are registered (full-cluster cold
+	// start). Zero disables the grace period.
+	FormationGrace time.Duration `cpln:"default:0s;env:FORMATION_GRACE"`
+
+	// URPIncludeTopics is a comma-separated list of topic patterns (regular
+	// expressions matching the whole name) whose under-replicated partitions fail
+	// readiness. Empty includes every topic.
+	URPIncludeTopics string `cpln:"env:URP_INCLUDE_TOPICS"`
+
+	// URPExcludeTopics is a comma-separated list of topic patterns whose
+	// under-replicated partitions do not fail readiness (e.g. RF=1 scratch topics).
+	// They are still exported as metrics. Takes precedence over URPIncludeTopics.
+	URPExcludeTopics string `cpln:"env:URP_EXCLUDE_TOPICS"`
+
+	// FaultInjection simulates failures inside the health checks, for validating
+	// probe thresholds and alerting against a healthy cluster: a comma-separated
+	// list of slow-metadata=DURATION, broker-missing, urp=COUNT and log-dir-error.
+	// For testing only. Empty injects nothing.
+	FaultInjection string `cpln:"env:FAULT_INJECTION"`
+
+	// CheckStaleAfter is how long a check or background loop may go without a
+	// success before it is reported stale on /status and in metrics. Zero disables it.
+	CheckStaleAfter time.Duration `cpln:"default:5m;env:CHECK_STALE_AFTER"`
+
+	// ProbeMaxConcurrency is how many liveness or readiness checks may run at once.
+	// Further probes are served the last result from cache. Zero disables the cap.
+	ProbeMaxConcurrency int `cpln:"default:4;env:PROBE_MAX_CONCURRENCY"`
+
+	// StatusFilePath is a file the liveness, readiness and stale checks are
+	// atomically written to as JSON, for node agents without HTTP access. Empty disables it.
+	StatusFilePath string `cpln:"env:STATUS_FILE_PATH"`
+
+	// StatusFileInterval is how often the checks behind the status file are run
+	StatusFileInterval time.Duration `cpln:"default:10s;env:STATUS_FILE_INTERVAL"`
+
+	// StatusFileRefreshInterval is the longest the status file goes unwritten while
+	// the status is unchanged, bounding the age of its updatedAt timestamp
+	StatusFileRefreshInterval time.Duration `cpln:"default:1m;env:STATUS_FILE_REFRESH_INTERVAL"`
+
+	// ClientLeakThreshold is how long a Kafka client created by the sidecar may stay
+	// open before it is logged as a leak with the stack that created it. Reassignment
+	// workflows hold one client for a whole batch. Zero disables leak detection.
+	ClientLeakThreshold time.Duration `cpln:"default:1h;env:CLIENT_LEAK_THRESHOLD"`
+
 	// Port is the HTTP server port
 	Port int `cpln:"default:8080;env:PORT"`
 
+	// HTTPReadTimeout and HTTPWriteTimeout bound reading a request and writing
+	// its response on Port
+	HTTPReadTimeout  time.Duration `cpln:"default:30s;env:HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout time.Duration `cpln:"default:30s;env:HTTP_WRITE_TIMEOUT"`
+
+	// AdminPort, when set, serves the endpoints that need authentication (admin,
+	// debug and mutating requests) on their own port, and Port answers 404 for them
+	AdminPort int `cpln:"default:0;env:ADMIN_PORT"`
+
+	// AdminReadTimeout and AdminWriteTimeout bound requests on AdminPort
+	AdminReadTimeout  time.Duration `cpln:"default:30s;env:ADMIN_READ_TIMEOUT"`
+	AdminWriteTimeout time.Duration `cpln:"default:2m;env:ADMIN_WRITE_TIMEOUT"`
+
+	// ConfigFile is a file of KEY=VALUE lines, e.g. a mounted ConfigMap or
+	// Secret, whose settings override the environment. It is read again on every
+	// reload (SIGHUP or POST /admin/reload); see Reload for what a reload applies.
+	ConfigFile string `cpln:"env:CONFIG_FILE"`
+
 	LogLevel string `cpln:"default:info;env:LOG_LEVEL"`
+
+	// RequestLogEnabled logs every HTTP request with its status, latency and
+	// X-Request-ID, which handler log lines carry too
+	RequestLogEnabled bool `cpln:"default:true;env:REQUEST_LOG_ENABLED"`
+
+	// RequestLogSampling logs 1 in N successful requests per route, as
+	// comma-separated route=N rates ("*" for other routes, 0 for none);
+	// failed requests are always logged. Empty uses requestlog.DefaultSampling.
+	RequestLogSampling string `cpln:"env:REQUEST_LOG_SAMPLING"`
+
+	// CORSAllowedOrigins is a comma-separated list of origins (scheme://host[:port],
+	// or * for any) whose browser scripts may call the API. Empty disables CORS.
+	CORSAllowedOrigins string `cpln:"env:CORS_ALLOWED_ORIGINS"`
+
+	// CORSAllowedMethods is a comma-separated list of the methods those scripts
+	// may use. Empty allows cors.DefaultMethods.
+	CORSAllowedMethods string `cpln:"env:CORS_ALLOWED_METHODS"`
+
+	// UIEnabled serves the embedded on-call dashboard at /ui/
+	UIEnabled bool `cpln:"default:true;env:UI_ENABLED"`
+
+	// PprofEnabled serves the Go runtime profiles of the sidecar under /debug/pprof
+	PprofEnabled bool `cpln:"default:false;env:PPROF_ENABLED"`
+
+	// Bearer-token authentication of the admin and debug endpoints and of every
+	// mutating request. Enabled when a token file or a JWKS URL is set.
+	// AuthTokenFile lists accepted static tokens, one per line
+	AuthTokenFile string `cpln:"env:AUTH_TOKEN_FILE"`
+
+	// AuthJWKSURL serves the keys that accepted JWTs are signed with
+	AuthJWKSURL string `cpln:"env:AUTH_JWKS_URL"`
+
+	// AuthJWTIssuer, when set, must match the iss claim of a JWT
+	AuthJWTIssuer string `cpln:"env:AUTH_JWT_ISSUER"`
+
+	// AuthJWTAudience, when set, must be in the aud claim of a JWT
+	AuthJWTAudience string `cpln:"env:AUTH_JWT_AUDIENCE"`
+
+	// TLS on the sidecar's own HTTP listener. Enabled when a certificate is set.
+	// ServerTLSCertFile is the certificate the listener serves
+	ServerTLSCertFile string `cpln:"env:SERVER_TLS_CERT_FILE"`
+
+	// ServerTLSKeyFile is the key of ServerTLSCertFile
+	ServerTLSKeyFile string `cpln:"env:SERVER_TLS_KEY_FILE"`
+
+	// ServerTLSClientCAFiles are comma-separated PEM bundles that client
+	// certificates are verified against
+	ServerTLSClientCAFiles string `cpln:"env:SERVER_TLS_CLIENT_CA_FILES"`
+
+	// ServerTLSClientAuth is none, optional (verify a client certificate when
+	// presented) or require (reject handshakes without one)
+	ServerTLSClientAuth string `cpln:"default:none;env:SERVER_TLS_CLIENT_AUTH"`
+
+	// AdminTLSClientAuth overrides ServerTLSClientAuth on AdminPort, e.g. require
+	// client certificates on the admin port only
+	AdminTLSClientAuth string `cpln:"env:ADMIN_TLS_CLIENT_AUTH"`
+
+	// AdminClientCertRequired rejects admin, debug and mutating requests without
+	// a verified client certificate, keeping probes open with optional client auth
+	AdminClientCertRequired bool `cpln:"default:false;env:ADMIN_CLIENT_CERT_REQUIRED"`
+
+	// Audit trail of the admin API. Enabled when a path is set.
+	// AuditLogPath is the file audit records are appended to as JSON lines
+	AuditLogPath string `cpln:"env:AUDIT_LOG_PATH"`
+
+	// AuditLogMaxSize is the size in bytes at which the audit log is rotated
+	AuditLogMaxSize int64 `cpln:"default:10485760;env:AUDIT_LOG_MAX_SIZE"`
+
+	// AuditLogMaxBackups is how many rotated audit logs are kept
+	AuditLogMaxBackups int `cpln:"default:5;env:AUDIT_LOG_MAX_BACKUPS"`
+
+	// AuditLogMaxBodyBytes bounds the request body recorded for a mutation.
+	// Longer bodies are only flagged as truncated.
+	AuditLogMaxBodyBytes int `cpln:"default:65536;env:AUDIT_LOG_MAX_BODY_BYTES"`
+
+	// AuditLogReads records successful reads of the admin and debug endpoints
+	// too. Mutations and denied requests are always recorded.
+	AuditLogReads bool `cpln:"default:false;env:AUDIT_LOG_READS"`
+
+	// Alerting on critical conditions. Enabled when a Slack webhook or a
+	// PagerDuty routing key is set.
+	// AlertSlackWebhookURL is the Slack incoming webhook notifications are posted to
+	AlertSlackWebhookURL string `cpln:"env:ALERT_SLACK_WEBHOOK_URL;sensitive"`
+
+	// AlertPagerDutyRoutingKey is the integration key PagerDuty incidents are
+	// triggered and resolved for
+	AlertPagerDutyRoutingKey string `cpln:"env:ALERT_PAGERDUTY_ROUTING_KEY;sensitive"`
+
+	// AlertPagerDutyURL is the PagerDuty Events API v2 endpoint
+	AlertPagerDutyURL string `cpln:"default:https://events.pagerduty.com/v2/enqueue;env:ALERT_PAGERDUTY_URL"`
+
+	// AlertInterval is how often the alert conditions are evaluated
+	AlertInterval time.Duration `cpln:"default:30s;env:ALERT_INTERVAL"`
+
+	// AlertBrokerUnreadyAfter is how long the broker must stay unready before it
+	// is alerted on. Waiting for a forming cluster does not count as unready.
+	// Zero disables the condition.
+	AlertBrokerUnreadyAfter time.Duration `cpln:"default:10m;env:ALERT_BROKER_UNREADY_AFTER"`
+
+	// AlertOfflinePartitions alerts on partitions without a leader, from the live
+	// broker with the lowest ID
+	AlertOfflinePartitions bool `cpln:"default:true;env:ALERT_OFFLINE_PARTITIONS"`
+
+	// AlertDiskUsageRatio alerts while a DiskUsagePaths volume is more used than
+	// this (0.0-1.0). Zero disables the condition.
+	AlertDiskUsageRatio float64 `cpln:"default:0.9;env:ALERT_DISK_USAGE_RATIO"`
+
+	// AlertCertMinValidity alerts on a TLS certificate expiring sooner. Zero
+	// disables the condition.
+	AlertCertMinValidity time.Duration `cpln:"default:168h;env:ALERT_CERT_MIN_VALIDITY"`
+
+	// AlertRepeatInterval repeats the notification of a condition that still
+	// holds. Zero notifies once until it is resolved.
+	AlertRepeatInterval time.Duration `cpln:"default:4h;env:ALERT_REPEAT_INTERVAL"`
+
+	// AlertTemplate is the Go template of the notification message, rendered
+	// with the alert's Key, Condition, Status, Cluster, Source, Summary and Since
+	AlertTemplate string `cpln:"env:ALERT_TEMPLATE"`
+
+	// Metadata backup to S3-compatible storage (AWS S3, Google Cloud Storage
+	// with HMAC keys, MinIO). Enabled when BackupBucket is set.
+	// BackupBucket is the bucket the snapshots are written to
+	BackupBucket string `cpln:"env:BACKUP_BUCKET"`
+
+	// BackupEndpoint is the storage API, e.g. https://storage.googleapis.com.
+	// Empty uses the regional AWS S3 endpoint.
+	BackupEndpoint string `cpln:"env:BACKUP_ENDPOINT"`
+
+	// BackupRegion signs the requests (auto for Google Cloud Storage)
+	BackupRegion string `cpln:"default:us-east-1;env:BACKUP_REGION"`
+
+	// BackupAccessKeyID, BackupSecretAccessKey and BackupSessionToken (for
+	// temporary credentials) sign the requests
+	BackupAccessKeyID     string `cpln:"env:BACKUP_ACCESS_KEY_ID"`
+	BackupSecretAccessKey string `cpln:"env:BACKUP_SECRET_ACCESS_KEY;sensitive"`
+	BackupSessionToken    string `cpln:"env:BACKUP_SESSION_TOKEN;sensitive"`
+
+	// BackupPrefix is prepended to the snapshot keys, which are
+	// <prefix>/<cluster ID>/<UTC timestamp>.json
+	BackupPrefix string `cpln:"default:kafka-metadata;env:BACKUP_PREFIX"`
+
+	// BackupInterval is how often the live broker with the lowest ID takes a snapshot
+	BackupInterval time.Duration `cpln:"default:1h;env:BACKUP_INTERVAL"`
+
+	// Broker process discovery (requires a shared PID namespace with the Kafka container)
+	// BrokerPIDFile is a file containing the broker PID. Takes precedence over BrokerProcessMatch.
+	BrokerPIDFile string `cpln:"env:BROKER_PID_FILE"`
+
+	// BrokerProcessMatch is a substring of the broker's command line used to find its PID.
+	// Defaults to the role's JVM main class when not set.
+	BrokerProcessMatch string `cpln:"default:kafka.Kafka;env:BROKER_PROCESS_MATCH"`
+
+	// ProcessMetricsEnabled exports the broker process's memory, CPU and thread
+	// usage from /proc on /metrics
+	ProcessMetricsEnabled bool `cpln:"default:false;env:PROCESS_METRICS_ENABLED"`
+
+	// FDMinFreeRatio is the free file descriptor ratio below which readiness reports degraded
+	FDMinFreeRatio float64 `cpln:"default:0.1;env:FD_MIN_FREE_RATIO"`
+
+	// DiskUsagePaths is a comma-separated list of Kafka data directories, mounted
+	// into the sidecar, whose filesystem usage is exported on /metrics
+	DiskUsagePaths string `cpln:"env:DISK_USAGE_PATHS"`
+
+	// DiskReadinessMaxUsageRatio fails readiness while any DiskUsagePaths volume
+	// is more used than this (0.0-1.0). Zero disables the check.
+	DiskReadinessMaxUsageRatio float64 `cpln:"default:0;env:DISK_READINESS_MAX_USAGE_RATIO"`
+
+	// Onboarding configuration
+	// OnboardingEnabled moves a proportional share of partitions onto this broker
+	// when it joins the cluster hosting no partitions (scale-up)
+	OnboardingEnabled bool `cpln:"default:false;env:ONBOARDING_ENABLED"`
+
+	// OnboardingCheckInterval is how often registration is checked while waiting to onboard
+	OnboardingCheckInterval time.Duration `cpln:"default:30s;env:ONBOARDING_CHECK_INTERVAL"`
+
+	// OnboardingBatchSize is the number of partitions reassigned per batch
+	OnboardingBatchSize int `cpln:"default:10;env:ONBOARDING_BATCH_SIZE"`
+
+	// OnboardingIncludeInternal allows moving internal topic partitions onto the new broker
+	OnboardingIncludeInternal bool `cpln:"default:false;env:ONBOARDING_INCLUDE_INTERNAL"`
+
+	// Post-restart verification configuration
+	// VerificationEnabled generates a verification report once the broker is ready
+	// after a restart
+	VerificationEnabled bool `cpln:"default:false;env:VERIFICATION_ENABLED"`
+
+	// VerificationSettleDelay is how long after the broker is ready the report is
+	// generated, giving preferred leadership time to move back
+	VerificationSettleDelay time.Duration `cpln:"default:5m;env:VERIFICATION_SETTLE_DELAY"`
+
+	// VerificationCanaryTopic is produced to for canary latency samples. Empty skips the canary.
+	VerificationCanaryTopic string `cpln:"env:VERIFICATION_CANARY_TOPIC"`
+
+	// VerificationWebhookURL receives every report as JSON
+	VerificationWebhookURL string `cpln:"env:VERIFICATION_WEBHOOK_URL;sensitive"`
+
+	// VerificationArtifactDir stores reports and the steady-state baseline the next
+	// report compares with. It must be on a volume that survives restarts.
+	VerificationArtifactDir string `cpln:"env:VERIFICATION_ARTIFACT_DIR"`
+
+	// VerificationBaselineInterval is how often the steady-state baseline is recorded
+	VerificationBaselineInterval time.Duration `cpln:"default:15m;env:VERIFICATION_BASELINE_INTERVAL"`
+
+	// VerificationMinLeadershipPercent is the share of preferred leadership that must be restored
+	VerificationMinLeadershipPercent float64 `cpln:"default:90;env:VERIFICATION_MIN_LEADERSHIP_PERCENT"`
+
+	// VerificationMaxBaselineRatio is how far canary latency and memory working set
+	// may exceed the baseline
+	VerificationMaxBaselineRatio float64 `cpln:"default:2;env:VERIFICATION_MAX_BASELINE_RATIO"`
+
+	// Webhook delivery configuration
+	// WebhookGzip compresses webhook bodies (Content-Encoding: gzip)
+	WebhookGzip bool `cpln:"default:false;env:WEBHOOK_GZIP"`
+
+	// WebhookBatchMaxEvents sends up to this many events together as a JSON array.
+	// One sends every event on its own.
+	WebhookBatchMaxEvents int `cpln:"default:1;env:WEBHOOK_BATCH_MAX_EVENTS"`
+
+	// WebhookBatchMaxBytes flushes a batch once its uncompressed size reaches it
+	WebhookBatchMaxBytes int `cpln:"default:1048576;env:WEBHOOK_BATCH_MAX_BYTES"`
+
+	// WebhookBatchFlushInterval is the longest a batched event waits to be sent
+	WebhookBatchFlushInterval time.Duration `cpln:"default:10s;env:WEBHOOK_BATCH_FLUSH_INTERVAL"`
+
+	// Canary configuration
+	// CanaryEnabled produces a record through the local broker to the canary topic
+	// and consumes it back every CanaryInterval
+	CanaryEnabled bool `cpln:"default:false;env:CANARY_ENABLED"`
+
+	// CanaryTopic is the canary topic, created when missing with a partition per broker
+	CanaryTopic string `cpln:"default:_kafka_orchestrator_canary;env:CANARY_TOPIC"`
+
+	// CanaryInterval is how often the canary is produced and consumed
+	CanaryInterval time.Duration `cpln:"default:30s;env:CANARY_INTERVAL"`
+
+	// CanaryReplicationFactor of the canary topic, capped at the number of brokers
+	CanaryReplicationFactor int `cpln:"default:3;env:CANARY_REPLICATION_FACTOR"`
+
+	// CanaryReadiness fails readiness while the last canary probe failed
+	CanaryReadiness bool `cpln:"default:false;env:CANARY_READINESS"`
+
+	// CanaryRebalanceGrace is how long a broker holding canary replicas may be
+	// gone before they are moved to the remaining brokers. Zero disables
+	// rebalancing the canary topic.
+	CanaryRebalanceGrace time.Duration `cpln:"default:15m;env:CANARY_REBALANCE_GRACE"`
+
+	// Request latency configuration
+	// RequestLatencyEnabled times ApiVersions, Metadata and ListOffsets requests
+	// against the local broker every RequestLatencyInterval
+	RequestLatencyEnabled bool `cpln:"default:false;env:REQUEST_LATENCY_ENABLED"`
+
+	// RequestLatencyInterval is how often the requests are timed
+	RequestLatencyInterval time.Duration `cpln:"default:15s;env:REQUEST_LATENCY_INTERVAL"`
+
+	// TLS handshake probe configuration
+	// TLSHandshakeListeners is a comma-separated list of name=host:port broker
+	// listeners whose TCP connect, TLS handshake and first request are timed
+	// every TLSHandshakeInterval. Empty disables the probes.
+	TLSHandshakeListeners string `cpln:"env:TLS_HANDSHAKE_LISTENERS"`
+
+	// TLSHandshakeInterval is how often the listeners are probed
+	TLSHandshakeInterval time.Duration `cpln:"default:30s;env:TLS_HANDSHAKE_INTERVAL"`
+
+	// InterBrokerTLSCheckEnabled completes a TLS handshake with the inter-broker
+	// listener of every peer every InterBrokerTLSInterval and verifies the
+	// served chains against TLSCAFiles
+	InterBrokerTLSCheckEnabled bool `cpln:"default:false;env:INTER_BROKER_TLS_CHECK_ENABLED"`
+
+	// InterBrokerTLSPort is the port of the peers' inter-broker listener. Zero
+	// uses the port of the listener the sidecar connects to.
+	InterBrokerTLSPort int `cpln:"default:0;env:INTER_BROKER_TLS_PORT"`
+
+	// InterBrokerTLSInterval is how often the peers are checked
+	InterBrokerTLSInterval time.Duration `cpln:"default:1m;env:INTER_BROKER_TLS_INTERVAL"`
+
+	// Peer reachability configuration
+	// PeerCheckEnabled dials every broker's Kafka port and peer sidecar every
+	// PeerCheckInterval and shares the results between sidecars
+	PeerCheckEnabled bool `cpln:"default:false;env:PEER_CHECK_ENABLED"`
+
+	// PeerCheckInterval is how often peers are checked
+	PeerCheckInterval time.Duration `cpln:"default:30s;env:PEER_CHECK_INTERVAL"`
+
+	// ConnectivityDiagnosticsEnabled serves GET /diagnostics/connectivity, which
+	// probes every broker from every sidecar on demand
+	ConnectivityDiagnosticsEnabled bool `cpln:"default:false;env:CONNECTIVITY_DIAGNOSTICS_ENABLED"`
+
+	// PartitionSizeMetricsEnabled exports the size of every partition replica on
+	// the local broker, from its log directories
+	PartitionSizeMetricsEnabled bool `cpln:"default:false;env:PARTITION_SIZE_METRICS_ENABLED"`
+
+	// PartitionSizeInterval is how often the log directories are described
+	PartitionSizeInterval time.Duration `cpln:"default:1m;env:PARTITION_SIZE_INTERVAL"`
+
+	// PartitionSizeTopN exports only the N largest partitions (0 for all, up to
+	// PartitionSizeMaxSeries)
+	PartitionSizeTopN int `cpln:"default:0;env:PARTITION_SIZE_TOP_N"`
+
+	// PartitionSizeMaxSeries caps the number of exported partition series
+	PartitionSizeMaxSeries int `cpln:"default:1000;env:PARTITION_SIZE_MAX_SERIES"`
+
+	// Decommission configuration
+	// DecommissionEnabled serves the admin endpoints that drain a broker ahead of its removal
+	// and unregister it afterwards
+	DecommissionEnabled bool `cpln:"default:false;env:DECOMMISSION_ENABLED"`
+
+	// DecommissionBatchSize is the number of partitions reassigned per batch
+	DecommissionBatchSize int `cpln:"default:10;env:DECOMMISSION_BATCH_SIZE"`
+
+	// DecommissionMinISRPolicy decides what happens when a decommission would leave topics
+	// unable to satisfy min.insync.replicas: "reject" refuses, "lower" temporarily lowers
+	// min.insync.replicas on affected topics after explicit operator confirmation
+	DecommissionMinISRPolicy string `cpln:"default:reject;env:DECOMMISSION_MIN_ISR_POLICY"`
+
+	// DecommissionStateFile is a file on a persistent volume where the decommission
+	// plan, progress, min.insync.replicas adjustments and audit trail are saved, so a
+	// drain interrupted by a sidecar restart is resumed
+	DecommissionStateFile string `cpln:"env:DECOMMISSION_STATE_FILE"`
+
+	// Replication factor change configuration
+	// ReplicationFactorEnabled serves the endpoints that raise or lower a topic's
+	// replication factor
+	ReplicationFactorEnabled bool `cpln:"default:false;env:REPLICATION_FACTOR_ENABLED"`
+
+	// ReplicationFactorBatchSize is the number of partitions reassigned per batch
+	ReplicationFactorBatchSize int `cpln:"default:10;env:REPLICATION_FACTOR_BATCH_SIZE"`
+
+	// ReplicationFactorThrottle is the default replication throttle in bytes/sec per
+	// broker while replicas are added. Zero disables throttling.
+	ReplicationFactorThrottle int64 `cpln:"default:10485760;env:REPLICATION_FACTOR_THROTTLE"`
+
+	// Standby configuration (ROLE=standby)
+	// StandbyCheckInterval is how often the standby broker is checked for, and
+	// stripped of, partition leadership
+	StandbyCheckInterval time.Duration `cpln:"default:30s;env:STANDBY_CHECK_INTERVAL"`
+
+	// StandbyBatchSize is the number of partitions reordered per reassignment batch
+	StandbyBatchSize int `cpln:"default:10;env:STANDBY_BATCH_SIZE"`
+
+	// SCRAM credential configuration
+	// SCRAMCredentialsFile is a mounted secret listing SCRAM users to create, update
+	// and rotate. Setting it enables credential reconciliation.
+	SCRAMCredentialsFile string `cpln:"env:SCRAM_CREDENTIALS_FILE"`
+
+	// SCRAMCheckInterval is how often the credentials file is checked for changes
+	SCRAMCheckInterval time.Duration `cpln:"default:1m;env:SCRAM_CHECK_INTERVAL"`
+
+	// SCRAMVerify authenticates with every new credential before the credentials it
+	// replaces are removed
+	SCRAMVerify bool `cpln:"default:true;env:SCRAM_VERIFY"`
+
+	// SCRAMVerifyTimeout is how long a new credential may take to propagate to the brokers
+	SCRAMVerifyTimeout time.Duration `cpln:"default:1m;env:SCRAM_VERIFY_TIMEOUT"`
+
+	// Reconcile scheduling configuration, shared by the SCRAM and quota reconcilers
+	// ReconcileConcurrency is how many admin requests the reconcilers run at once
+	ReconcileConcurrency int `cpln:"default:4;env:RECONCILE_CONCURRENCY"`
+
+	// ReconcileBatchSize is how many items go into one admin request (0 = all)
+	ReconcileBatchSize int `cpln:"default:100;env:RECONCILE_BATCH_SIZE"`
+
+	// ReconcileCycleBudget is how many items a reconcile cycle may write before
+	// the rest wait for the next cycle (0 = unlimited)
+	ReconcileCycleBudget int `cpln:"default:0;env:RECONCILE_CYCLE_BUDGET"`
+
+	// Config drift configuration
+	// ConfigDriftSpecFile is a mounted spec of desired topic and broker configs.
+	// Setting it enables drift detection.
+	ConfigDriftSpecFile string `cpln:"env:CONFIG_DRIFT_SPEC_FILE"`
+
+	// ConfigDriftCheckInterval is how often actual configs are compared with the spec
+	ConfigDriftCheckInterval time.Duration `cpln:"default:5m;env:CONFIG_DRIFT_CHECK_INTERVAL"`
+
+	// Topic catalog configuration
+	// CatalogEnabled serves the searchable topic catalog
+	CatalogEnabled bool `cpln:"default:false;env:CATALOG_ENABLED"`
+
+	// CatalogRefreshInterval is how often the catalog snapshot is rebuilt
+	CatalogRefreshInterval time.Duration `cpln:"default:1m;env:CATALOG_REFRESH_INTERVAL"`
+
+	// CatalogTagsFile is a mounted file of topic tags (e.g. owner) keyed by topic pattern
+	CatalogTagsFile string `cpln:"env:CATALOG_TAGS_FILE"`
+
+	// Maintenance safety configuration
+	// MaintenanceScoreEnabled combines URP, offline partitions, reassignments, zone
+	// spread, controller stability and disk headroom into a 0-100 safety score
+	MaintenanceScoreEnabled bool `cpln:"default:false;env:MAINTENANCE_SCORE_ENABLED"`
+
+	// MaintenanceScoreInterval is how often the score is recomputed
+	MaintenanceScoreInterval time.Duration `cpln:"default:1m;env:MAINTENANCE_SCORE_INTERVAL"`
+
+	// MaintenanceControllerStablePeriod is how long the controller must stay put
+	// after a change before it counts as stable
+	MaintenanceControllerStablePeriod time.Duration `cpln:"default:10m;env:MAINTENANCE_CONTROLLER_STABLE_PERIOD"`
+
+	// MaintenanceDiskMinFreeRatio is the free share of every log directory volume
+	// below which disk headroom lowers the score (0.0-1.0)
+	MaintenanceDiskMinFreeRatio float64 `cpln:"default:0.2;env:MAINTENANCE_DISK_MIN_FREE_RATIO"`
+
+	// MaintenanceSafeScore is the lowest score reported as safe (0-100)
+	MaintenanceSafeScore int `cpln:"default:100;env:MAINTENANCE_SAFE_SCORE"`
+
+	// KRaft metadata log configuration
+	// KRaftMetadataLogDir is the node's metadata.log.dir, mounted read-only into the
+	// sidecar. When set, the __cluster_metadata log and its snapshots are inspected.
+	KRaftMetadataLogDir string `cpln:"env:KRAFT_METADATA_LOG_DIR"`
+
+	// KRaftMetadataLogInterval is how often the metadata log is inspected
+	KRaftMetadataLogInterval time.Duration `cpln:"default:1m;env:KRAFT_METADATA_LOG_INTERVAL"`
+
+	// KRaftMetadataLogMaxBytes is the metadata log size above which its growth is
+	// reported as runaway (0 disables)
+	KRaftMetadataLogMaxBytes int64 `cpln:"default:1073741824;env:KRAFT_METADATA_LOG_MAX_BYTES"`
+
+	// KRaftSnapshotMaxAge is how long without a new metadata snapshot counts as
+	// runaway growth (0 disables)
+	KRaftSnapshotMaxAge time.Duration `cpln:"default:3h;env:KRAFT_SNAPSHOT_MAX_AGE"`
+
+	// BrokerEpochCheckEnabled follows the broker's registration records in the
+	// metadata log (KRAFT_METADATA_LOG_DIR) and fails readiness while its epoch
+	// regresses or the controller fences it
+	BrokerEpochCheckEnabled bool `cpln:"default:false;env:BROKER_EPOCH_CHECK_ENABLED"`
+
+	// BrokerEpochCheckInterval is how often new metadata records are read
+	BrokerEpochCheckInterval time.Duration `cpln:"default:15s;env:BROKER_EPOCH_CHECK_INTERVAL"`
+
+	// GC log configuration
+	// GCLogPath is the broker JVM's unified GC log (-Xlog:gc), mounted into the
+	// sidecar. When set, its pauses are exported on /metrics.
+	GCLogPath string `cpln:"env:GC_LOG_PATH"`
+
+	// GCLogInterval is how often the GC log is read for new pauses
+	GCLogInterval time.Duration `cpln:"default:5s;env:GC_LOG_INTERVAL"`
+
+	// OffsetsExportEnabled serves the endpoint that exports a consumer group's
+	// committed offsets
+	OffsetsExportEnabled bool `cpln:"default:false;env:OFFSETS_EXPORT_ENABLED"`
+
+	// OffsetsResetEnabled serves the endpoint that resets a consumer group's
+	// committed offsets or restores them from an export
+	OffsetsResetEnabled bool `cpln:"default:false;env:OFFSETS_RESET_ENABLED"`
+
+	// JolokiaURL is the Jolokia agent endpoint attached to the Kafka broker JVM
+	// (e.g. http://localhost:8778/jolokia). JMX-backed features require it.
+	JolokiaURL string `cpln:"env:JOLOKIA_URL"`
+
+	// UpstreamMetricsURL is another exporter on the replica (e.g. the JMX exporter
+	// in the Kafka container, http://localhost:7071/metrics) whose metrics are merged
+	// into /metrics with broker_id and location labels added
+	UpstreamMetricsURL string `cpln:"env:UPSTREAM_METRICS_URL"`
+
+	// JMXMetricsEnabled re-exports key broker MBeans (throughput, request handler
+	// idle ratio, under-min-ISR partitions) on /metrics. Requires JolokiaURL.
+	JMXMetricsEnabled bool `cpln:"default:false;env:JMX_METRICS_ENABLED"`
+
+	// Request error rate configuration
+	// RequestErrorsEnabled samples the broker's per-API request and error counts
+	// through Jolokia and exports their error rates. Requires JolokiaURL.
+	RequestErrorsEnabled bool `cpln:"default:false;env:REQUEST_ERRORS_ENABLED"`
+
+	// RequestErrorsAPIs is a comma-separated list of request types to sample, as
+	// named by the broker's RequestMetrics MBeans. Empty samples Produce, Fetch,
+	// Metadata and OffsetCommit.
+	RequestErrorsAPIs string `cpln:"env:REQUEST_ERRORS_APIS"`
+
+	// RequestErrorsInterval is how often the counters are sampled; rates cover one interval
+	RequestErrorsInterval time.Duration `cpln:"default:30s;env:REQUEST_ERRORS_INTERVAL"`
+
+	// RequestErrorsMaxRatio is the error ratio above which readiness reports
+	// degraded. Zero only exports the rates.
+	RequestErrorsMaxRatio float64 `cpln:"default:0;env:REQUEST_ERRORS_MAX_RATIO"`
+
+	// RequestErrorsMaxRatios overrides RequestErrorsMaxRatio per request type,
+	// e.g. "Produce=0.01,Metadata=0.2"
+	RequestErrorsMaxRatios string `cpln:"env:REQUEST_ERRORS_MAX_RATIOS"`
+
+	// MetricsDisabledCollectors is a comma-separated list of collectors left off
+	// /metrics (see metrics.CollectorNames), e.g. expensive ones on huge clusters
+	MetricsDisabledCollectors string `cpln:"env:METRICS_DISABLED_COLLECTORS"`
+
+	// MetricsRelabelFile is a mounted JSON file of rules that drop or rename
+	// series on /metrics, and labels added to every series
+	MetricsRelabelFile string `cpln:"env:METRICS_RELABEL_FILE"`
+
+	// MetricsOpenMetricsEnabled serves the OpenMetrics format to scrapers that
+	// ask for it; disabled, every scraper gets the classic text format
+	MetricsOpenMetricsEnabled bool `cpln:"default:true;env:METRICS_OPENMETRICS_ENABLED"`
+
+	// OTLP metrics export configuration
+	// OTLPMetricsEndpoint is an OpenTelemetry collector that the metrics on
+	// /metrics are pushed to, as host:port or a URL. Empty disables the export.
+	OTLPMetricsEndpoint string `cpln:"env:OTLP_METRICS_ENDPOINT"`
+
+	// OTLPMetricsProtocol is grpc or http (protobuf over HTTP)
+	OTLPMetricsProtocol string `cpln:"default:grpc;env:OTLP_METRICS_PROTOCOL"`
+
+	// OTLPMetricsHeaders are sent with every export, e.g.
+	// "authorization=Bearer abc,x-tenant=kafka"
+	OTLPMetricsHeaders string `cpln:"env:OTLP_METRICS_HEADERS;sensitive"`
+
+	// OTLPMetricsInsecure disables TLS to a host:port endpoint
+	OTLPMetricsInsecure bool `cpln:"default:false;env:OTLP_METRICS_INSECURE"`
+
+	// OTLPMetricsInterval is how often the metrics are pushed
+	OTLPMetricsInterval time.Duration `cpln:"default:30s;env:OTLP_METRICS_INTERVAL"`
+
+	// OTLP trace export configuration
+	// OTLPTracesEndpoint is an OpenTelemetry collector that spans of the health
+	// probes, their sub-checks and admin calls are pushed to. Empty disables tracing.
+	OTLPTracesEndpoint string `cpln:"env:OTLP_TRACES_ENDPOINT"`
+
+	// OTLPTracesProtocol is grpc or http (protobuf over HTTP)
+	OTLPTracesProtocol string `cpln:"default:grpc;env:OTLP_TRACES_PROTOCOL"`
+
+	// OTLPTracesHeaders are sent with every export
+	OTLPTracesHeaders string `cpln:"env:OTLP_TRACES_HEADERS;sensitive"`
+
+	// OTLPTracesInsecure disables TLS to a host:port endpoint
+	OTLPTracesInsecure bool `cpln:"default:false;env:OTLP_TRACES_INSECURE"`
+
+	// OTLPTracesSampleRatio is the fraction of probes traced (0.0-1.0)
+	OTLPTracesSampleRatio float64 `cpln:"default:1;env:OTLP_TRACES_SAMPLE_RATIO"`
+
+	// StatsD export configuration
+	// StatsDAddress is a StatsD or DogStatsD agent, as host:port (UDP) or
+	// unix:///path. Empty disables the export.
+	StatsDAddress string `cpln:"env:STATSD_ADDRESS"`
+
+	// StatsDPrefix is prepended to every metric name, e.g. "prod."
+	StatsDPrefix string `cpln:"env:STATSD_PREFIX"`
+
+	// StatsDTagFormat is how labels are encoded: datadog, influx or graphite
+	StatsDTagFormat string `cpln:"default:datadog;env:STATSD_TAG_FORMAT"`
+
+	// StatsDTags are added to every metric, e.g. "env:prod,team:streaming"
+	StatsDTags string `cpln:"env:STATSD_TAGS"`
+
+	// StatsDMetrics is a regular expression selecting the metrics sent. Empty
+	// sends the memory metrics and core health signals (statsd.DefaultMetrics).
+	StatsDMetrics string `cpln:"env:STATSD_METRICS"`
+
+	// StatsDInterval is how often the metrics are sent
+	StatsDInterval time.Duration `cpln:"default:10s;env:STATSD_INTERVAL"`
+
+	// OOMPredictionWindow is how far back sampled OOM ratios are fitted to a
+	// trend for kafka_memory_oom_predicted_seconds. Zero disables the prediction.
+	OOMPredictionWindow time.Duration `cpln:"default:30m;env:OOM_PREDICTION_WINDOW"`
+
+	// OOMPredictionInterval is how often the OOM ratio is sampled for the prediction
+	OOMPredictionInterval time.Duration `cpln:"default:30s;env:OOM_PREDICTION_INTERVAL"`
+
+	// Quota recommendation configuration
+	// QuotaRecommenderEnabled samples per-principal throughput from the broker's
+	// quota MBeans and serves recommended client quotas. Requires JolokiaURL.
+	QuotaRecommenderEnabled bool `cpln:"default:false;env:QUOTA_RECOMMENDER_ENABLED"`
+
+	// QuotaSampleInterval is how often per-principal throughput is sampled
+	QuotaSampleInterval time.Duration `cpln:"default:30s;env:QUOTA_SAMPLE_INTERVAL"`
+
+	// QuotaSampleWindow is how long samples are kept for recommendations
+	QuotaSampleWindow time.Duration `cpln:"default:24h;env:QUOTA_SAMPLE_WINDOW"`
+
+	// QuotaHeadroomFactor multiplies the observed p95 throughput (1.5 = 50% headroom)
+	QuotaHeadroomFactor float64 `cpln:"default:1.5;env:QUOTA_HEADROOM_FACTOR"`
+
+	// QuotaMinByteRate is the lowest quota ever recommended, in bytes/sec
+	QuotaMinByteRate float64 `cpln:"default:1048576;env:QUOTA_MIN_BYTE_RATE"`
+
+	// env is the environment the configuration was loaded from, the process
+	// environment when nil
+	env discovery.Env
+	// fileEnv holds the variables set from the config file, over env
+	fileEnv map[string]string
+	// discovered holds the fields derived rather than read
+	discovered map[string]bool
 }
 
 var Config *ConfigSchema
 
-// Initialize initializes the configuration. Must be called before using Config.
-func Initialize(logger *slog.Logger) error {
-	Config = &ConfigSchema{}
+// Profile returns the subsystem profile of the configured role
+func (c *ConfigSchema) Profile() Profile {
+	return Role(c.Role).Profile()
+}
 
-	if err := config.ParseSchema(Config); err != nil {
+// Initialize loads the configuration from the process environment into Config.
+// Must be called before using Config.
+func Initialize(logger *slog.Logger) error {
+	cfg, err := Load(discovery.OSEnv, logger)
+	if err != nil {
 		return err
 	}
+	Config = cfg
+	return nil
+}
 
-	// Auto-discover broker ID if BROKER_ID env var is not explicitly set
-	if os.Getenv("BROKER_ID") == "" {
-		brokerID, err := discovery.DiscoverBrokerID()
-		if err != nil {
-			return err
-		}
-		Config.BrokerID = brokerID
-		logger.Info("auto-discovered broker ID from hostname",
-			"brokerID", brokerID,
-			"hostname", os.Getenv("HOSTNAME"))
+// Load parses and validates the configuration in env, and the config file it
+// names, without touching Config or the process environment
+func Load(env discovery.Env, logger *slog.Logger) (*ConfigSchema, error) {
+	cfg := &ConfigSchema{env: env, discovered: map[string]bool{}}
+	if err := load(cfg, cfg.discovered, logger); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	// Auto-build bootstrap servers if not explicitly set
-	if Config.BootstrapServers == "" {
-		// Try to get workload name from config, or discover from CPLN_WORKLOAD
-		workloadName := Config.WorkloadName
-		if workloadName == "" {
-			discovered, err := discovery.DiscoverWorkloadName()
-			if err != nil {
-				return err
-			}
-			workloadName = discovered
-			logger.Info("discovered workload name from CPLN_WORKLOAD",
-				"workloadName", workloadName)
+// environment returns the environment the configuration was loaded from, with
+// the variables of the config file over it
+func (c *ConfigSchema) environment() discovery.Env {
+	env := c.env
+	if env == nil {
+		env = discovery.OSEnv
+	}
+	if len(c.fileEnv) == 0 {
+		return env
+	}
+	return func(key string) (string, bool) {
+		if value, ok := c.fileEnv[key]; ok {
+			return value, true
 		}
+		return env(key)
+	}
+}
 
-		gvcAlias := Config.GvcAlias
-		if gvcAlias == "" {
-			discovered, err := discovery.DiscoverGvcAlias()
-			if err != nil {
-				return err
-			}
-			gvcAlias = discovered
-			logger.Info("discovered GVC alias from CPLN_GVC_ALIAS",
-				"gvcAlias", gvcAlias)
+// load parses the environment of cfg into it and validates it, recording the
+// fields derived rather than read in found
+func load(cfg *ConfigSchema, found map[string]bool, logger *slog.Logger) error {
+	if path := cfg.environment().Get("CONFIG_FILE"); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("invalid CONFIG_FILE: %w", err)
+		}
+		cfg.fileEnv = values
+	}
+	env := cfg.environment()
+	if err := parseSchema(cfg, env); err != nil {
+		return err
+	}
+	if err := applySecretFiles(cfg); err != nil {
+		return err
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+
+	role, err := ParseRole(cfg.Role)
+	if err != nil {
+		return err
+	}
+	cfg.Role = string(role)
+	if env.Get("BROKER_PROCESS_MATCH") == "" {
+		cfg.BrokerProcessMatch = role.Profile().ProcessMatch
+		found["BrokerProcessMatch"] = true
+	}
+	if err := validateRole(cfg, role.Profile()); err != nil {
+		return err
+	}
+
+	if cfg.CheckTimeoutMax < 0 {
+		return errors.New("CHECK_TIMEOUT_MAX must not be negative")
+	}
+
+	if cfg.KafkaRetryAttempts < 1 {
+		return errors.New("KAFKA_RETRY_ATTEMPTS must be at least 1")
+	}
+	if cfg.KafkaRetryBaseDelay <= 0 {
+		return errors.New("KAFKA_RETRY_BASE_DELAY must be positive")
+	}
+	if cfg.KafkaRetryMaxDelay < cfg.KafkaRetryBaseDelay {
+		return errors.New("KAFKA_RETRY_MAX_DELAY must be at least KAFKA_RETRY_BASE_DELAY")
+	}
+	if cfg.KafkaRetryJitter < 0 || cfg.KafkaRetryJitter > 1 {
+		return errors.New("KAFKA_RETRY_JITTER must be between 0 and 1")
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLSEnabled || cfg.TLSHandshakeListeners != "" || cfg.InterBrokerTLSCheckEnabled {
+		for _, file := range kafkaclient.ParseCAFiles(cfg.TLSCAFiles) {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("invalid TLS_CA_FILES: %w", err)
+			}
+		}
+	}
+	if cfg.TLSEnabled {
+		if cfg.TLSExpiryCheckInterval <= 0 {
+			return errors.New("TLS_EXPIRY_CHECK_INTERVAL must be positive")
+		}
+		if cfg.TLSExpiryMinValidity < 0 {
+			return errors.New("TLS_EXPIRY_MIN_VALIDITY must not be negative")
+		}
+	}
+
+	if _, err := health.NewTopicFilter(cfg.URPIncludeTopics, cfg.URPExcludeTopics); err != nil {
+		return fmt.Errorf("invalid URP_INCLUDE_TOPICS or URP_EXCLUDE_TOPICS: %w", err)
+	}
+	if _, err := health.ParseFaults(cfg.FaultInjection); err != nil {
+		return fmt.Errorf("invalid FAULT_INJECTION: %w", err)
+	}
+
+	if cfg.QuotaRecommenderEnabled {
+		if cfg.JolokiaURL == "" {
+			return errors.New("QUOTA_RECOMMENDER_ENABLED requires JOLOKIA_URL")
+		}
+		if cfg.QuotaHeadroomFactor < 1 {
+			return errors.New("QUOTA_HEADROOM_FACTOR must be at least 1")
+		}
+	}
+
+	if _, err := metrics.ParseCollectorNames(cfg.MetricsDisabledCollectors); err != nil {
+		return fmt.Errorf("invalid METRICS_DISABLED_COLLECTORS: %w", err)
+	}
+
+	if cfg.OOMPredictionWindow < 0 {
+		return errors.New("OOM_PREDICTION_WINDOW must not be negative")
+	}
+	if cfg.OOMPredictionWindow > 0 {
+		if cfg.OOMPredictionInterval <= 0 {
+			return errors.New("OOM_PREDICTION_INTERVAL must be positive")
+		}
+		if cfg.OOMPredictionWindow < 5*cfg.OOMPredictionInterval {
+			return errors.New("OOM_PREDICTION_WINDOW must hold at least 5 samples of OOM_PREDICTION_INTERVAL")
+		}
+	}
+
+	if cfg.RequestErrorsEnabled {
+		if cfg.JolokiaURL == "" {
+			return errors.New("REQUEST_ERRORS_ENABLED requires JOLOKIA_URL")
+		}
+		if cfg.RequestErrorsInterval <= 0 {
+			return errors.New("REQUEST_ERRORS_INTERVAL must be positive")
+		}
+		if cfg.RequestErrorsMaxRatio < 0 || cfg.RequestErrorsMaxRatio > 1 {
+			return errors.New("REQUEST_ERRORS_MAX_RATIO must be between 0 and 1")
+		}
+		if _, err := requesterrors.ParseThresholds(cfg.RequestErrorsMaxRatios); err != nil {
+			return fmt.Errorf("invalid REQUEST_ERRORS_MAX_RATIOS: %w", err)
+		}
+	}
+
+	if cfg.JMXMetricsEnabled && cfg.JolokiaURL == "" {
+		return errors.New("JMX_METRICS_ENABLED requires JOLOKIA_URL")
+	}
+
+	for _, path := range metrics.ParseDiskPaths(cfg.DiskUsagePaths) {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("DISK_USAGE_PATHS entries must be absolute paths: %s", path)
+		}
+	}
+	if cfg.ProbeMaxConcurrency < 0 {
+		return errors.New("PROBE_MAX_CONCURRENCY must not be negative")
+	}
+
+	if cfg.StatusFilePath != "" {
+		if !filepath.IsAbs(cfg.StatusFilePath) {
+			return errors.New("STATUS_FILE_PATH must be an absolute path")
+		}
+		if cfg.StatusFileInterval <= 0 {
+			return errors.New("STATUS_FILE_INTERVAL must be positive")
+		}
+		if cfg.StatusFileRefreshInterval < cfg.StatusFileInterval {
+			return errors.New("STATUS_FILE_REFRESH_INTERVAL must be at least STATUS_FILE_INTERVAL")
+		}
+	}
+
+	if cfg.DiskReadinessMaxUsageRatio < 0 || cfg.DiskReadinessMaxUsageRatio >= 1 {
+		return errors.New("DISK_READINESS_MAX_USAGE_RATIO must be at least 0 and below 1")
+	}
+	if cfg.DiskReadinessMaxUsageRatio > 0 && cfg.DiskUsagePaths == "" {
+		return errors.New("DISK_READINESS_MAX_USAGE_RATIO requires DISK_USAGE_PATHS")
+	}
+
+	if cfg.UpstreamMetricsURL != "" {
+		if u, err := url.Parse(cfg.UpstreamMetricsURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid UPSTREAM_METRICS_URL: %s", cfg.UpstreamMetricsURL)
+		}
+	}
+	if cfg.AuthTokenFile != "" {
+		if _, err := auth.LoadTokenFile(cfg.AuthTokenFile); err != nil {
+			return fmt.Errorf("invalid AUTH_TOKEN_FILE: %w", err)
+		}
+	}
+	if cfg.AuthJWKSURL != "" {
+		if u, err := url.Parse(cfg.AuthJWKSURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid AUTH_JWKS_URL: %s", cfg.AuthJWKSURL)
+		}
+	} else if cfg.AuthJWTIssuer != "" || cfg.AuthJWTAudience != "" {
+		return errors.New("AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE require AUTH_JWKS_URL")
+	}
+	if err := validateListeners(cfg); err != nil {
+		return err
+	}
+	if err := validateServerTLS(cfg); err != nil {
+		return err
+	}
+	if err := validateSecrets(cfg); err != nil {
+		return err
+	}
+	if _, err := requestlog.ParseSampling(cfg.RequestLogSampling); err != nil {
+		return fmt.Errorf("invalid REQUEST_LOG_SAMPLING: %w", err)
+	}
+	if cfg.AuditLogPath != "" {
+		if !filepath.IsAbs(cfg.AuditLogPath) {
+			return errors.New("AUDIT_LOG_PATH must be an absolute path")
+		}
+		if cfg.AuditLogMaxSize <= 0 {
+			return errors.New("AUDIT_LOG_MAX_SIZE must be positive")
+		}
+		if cfg.AuditLogMaxBackups < 0 {
+			return errors.New("AUDIT_LOG_MAX_BACKUPS must not be negative")
+		}
+		if cfg.AuditLogMaxBodyBytes < 0 || cfg.AuditLogMaxBodyBytes > 1<<20 {
+			return errors.New("AUDIT_LOG_MAX_BODY_BYTES must be between 0 and 1048576")
+		}
+	}
+	if err := validateAlerting(cfg); err != nil {
+		return err
+	}
+	if err := validateBackup(cfg); err != nil {
+		return err
+	}
+	if cfg.CORSAllowedOrigins != "" {
+		if _, err := cors.ParseOrigins(cfg.CORSAllowedOrigins); err != nil {
+			return fmt.Errorf("invalid CORS_ALLOWED_ORIGINS: %w", err)
+		}
+	}
+	if _, err := cors.ParseMethods(cfg.CORSAllowedMethods); err != nil {
+		return fmt.Errorf("invalid CORS_ALLOWED_METHODS: %w", err)
+	}
+	if cfg.MetricsRelabelFile != "" {
+		if _, err := metrics.LoadRelabelConfig(cfg.MetricsRelabelFile); err != nil {
+			return fmt.Errorf("invalid METRICS_RELABEL_FILE: %w", err)
+		}
+	}
+	if cfg.OTLPMetricsEndpoint != "" {
+		if _, err := otlp.ParseProtocol(cfg.OTLPMetricsProtocol); err != nil {
+			return fmt.Errorf("invalid OTLP_METRICS_PROTOCOL: %w", err)
+		}
+		if _, err := otlp.ParseHeaders(cfg.OTLPMetricsHeaders); err != nil {
+			return fmt.Errorf("invalid OTLP_METRICS_HEADERS: %w", err)
+		}
+		if cfg.OTLPMetricsInterval <= 0 {
+			return errors.New("OTLP_METRICS_INTERVAL must be positive")
+		}
+	}
+	if cfg.OTLPTracesEndpoint != "" {
+		if _, err := otlp.ParseProtocol(cfg.OTLPTracesProtocol); err != nil {
+			return fmt.Errorf("invalid OTLP_TRACES_PROTOCOL: %w", err)
+		}
+		if _, err := otlp.ParseHeaders(cfg.OTLPTracesHeaders); err != nil {
+			return fmt.Errorf("invalid OTLP_TRACES_HEADERS: %w", err)
+		}
+		if cfg.OTLPTracesSampleRatio < 0 || cfg.OTLPTracesSampleRatio > 1 {
+			return errors.New("OTLP_TRACES_SAMPLE_RATIO must be between 0 and 1")
+		}
+	}
+	if cfg.StatsDAddress != "" {
+		if _, err := statsd.ParseTagFormat(cfg.StatsDTagFormat); err != nil {
+			return fmt.Errorf("invalid STATSD_TAG_FORMAT: %w", err)
+		}
+		if _, err := statsd.ParseTags(cfg.StatsDTags); err != nil {
+			return fmt.Errorf("invalid STATSD_TAGS: %w", err)
+		}
+		if _, err := statsd.ParseMetrics(cfg.StatsDMetrics); err != nil {
+			return fmt.Errorf("invalid STATSD_METRICS: %w", err)
+		}
+		if cfg.StatsDInterval <= 0 {
+			return errors.New("STATSD_INTERVAL must be positive")
+		}
+	}
+
+	if cfg.OnboardingEnabled && cfg.OnboardingBatchSize <= 0 {
+		return errors.New("ONBOARDING_BATCH_SIZE must be positive")
+	}
+
+	if cfg.VerificationEnabled {
+		if cfg.VerificationMinLeadershipPercent < 0 || cfg.VerificationMinLeadershipPercent > 100 {
+			return errors.New("VERIFICATION_MIN_LEADERSHIP_PERCENT must be between 0 and 100")
+		}
+		if cfg.VerificationMaxBaselineRatio < 1 {
+			return errors.New("VERIFICATION_MAX_BASELINE_RATIO must be at least 1")
+		}
+		if cfg.VerificationBaselineInterval <= 0 {
+			return errors.New("VERIFICATION_BASELINE_INTERVAL must be positive")
+		}
+	}
+
+	if cfg.WebhookBatchMaxEvents < 1 {
+		return errors.New("WEBHOOK_BATCH_MAX_EVENTS must be at least 1")
+	}
+	if cfg.WebhookBatchMaxEvents > 1 {
+		if cfg.WebhookBatchMaxBytes <= 0 {
+			return errors.New("WEBHOOK_BATCH_MAX_BYTES must be positive")
+		}
+		if cfg.WebhookBatchFlushInterval <= 0 {
+			return errors.New("WEBHOOK_BATCH_FLUSH_INTERVAL must be positive")
+		}
+	}
+
+	if cfg.CanaryEnabled {
+		if cfg.CanaryTopic == "" {
+			return errors.New("CANARY_ENABLED requires CANARY_TOPIC")
+		}
+		if cfg.CanaryInterval <= 0 {
+			return errors.New("CANARY_INTERVAL must be positive")
+		}
+		if cfg.CanaryReplicationFactor <= 0 {
+			return errors.New("CANARY_REPLICATION_FACTOR must be positive")
+		}
+		if cfg.CanaryRebalanceGrace < 0 {
+			return errors.New("CANARY_REBALANCE_GRACE must not be negative")
+		}
+	}
+	if cfg.CanaryReadiness && !cfg.CanaryEnabled {
+		return errors.New("CANARY_READINESS requires CANARY_ENABLED")
+	}
+
+	if cfg.RequestLatencyEnabled && cfg.RequestLatencyInterval <= 0 {
+		return errors.New("REQUEST_LATENCY_INTERVAL must be positive")
+	}
+
+	if cfg.TLSHandshakeListeners != "" {
+		if _, err := handshake.ParseListeners(cfg.TLSHandshakeListeners); err != nil {
+			return fmt.Errorf("invalid TLS_HANDSHAKE_LISTENERS: %w", err)
+		}
+		if cfg.TLSHandshakeInterval <= 0 {
+			return errors.New("TLS_HANDSHAKE_INTERVAL must be positive")
+		}
+	}
+
+	if cfg.InterBrokerTLSCheckEnabled {
+		if cfg.InterBrokerTLSInterval <= 0 {
+			return errors.New("INTER_BROKER_TLS_INTERVAL must be positive")
+		}
+		if cfg.InterBrokerTLSPort < 0 || cfg.InterBrokerTLSPort > 65535 {
+			return errors.New("INTER_BROKER_TLS_PORT must be between 0 and 65535")
+		}
+	}
+
+	if cfg.PeerCheckEnabled && cfg.PeerCheckInterval <= 0 {
+		return errors.New("PEER_CHECK_INTERVAL must be positive")
+	}
+
+	if cfg.PartitionSizeMetricsEnabled {
+		if cfg.PartitionSizeInterval <= 0 {
+			return errors.New("PARTITION_SIZE_INTERVAL must be positive")
+		}
+		if cfg.PartitionSizeTopN < 0 {
+			return errors.New("PARTITION_SIZE_TOP_N must not be negative")
+		}
+		if cfg.PartitionSizeMaxSeries <= 0 {
+			return errors.New("PARTITION_SIZE_MAX_SERIES must be positive")
+		}
+	}
+
+	if cfg.ReplicationFactorEnabled {
+		if cfg.ReplicationFactorBatchSize <= 0 {
+			return errors.New("REPLICATION_FACTOR_BATCH_SIZE must be positive")
+		}
+		if cfg.ReplicationFactorThrottle < 0 {
+			return errors.New("REPLICATION_FACTOR_THROTTLE must not be negative")
+		}
+	}
+
+	if role.Profile().Standby && cfg.StandbyBatchSize <= 0 {
+		return errors.New("STANDBY_BATCH_SIZE must be positive")
+	}
+
+	if cfg.DecommissionEnabled {
+		if cfg.DecommissionBatchSize <= 0 {
+			return errors.New("DECOMMISSION_BATCH_SIZE must be positive")
+		}
+		switch cfg.DecommissionMinISRPolicy {
+		case "reject", "lower":
+		default:
+			return fmt.Errorf("unsupported DECOMMISSION_MIN_ISR_POLICY: %s (supported: reject, lower)", cfg.DecommissionMinISRPolicy)
+		}
+	}
+
+	if cfg.SCRAMCredentialsFile != "" && cfg.SCRAMCheckInterval <= 0 {
+		return errors.New("SCRAM_CHECK_INTERVAL must be positive")
+	}
+
+	if cfg.ReconcileConcurrency <= 0 {
+		return errors.New("RECONCILE_CONCURRENCY must be positive")
+	}
+	if cfg.ReconcileBatchSize < 0 {
+		return errors.New("RECONCILE_BATCH_SIZE must not be negative")
+	}
+	if cfg.ReconcileCycleBudget < 0 {
+		return errors.New("RECONCILE_CYCLE_BUDGET must not be negative")
+	}
+
+	if cfg.ConfigDriftSpecFile != "" && cfg.ConfigDriftCheckInterval <= 0 {
+		return errors.New("CONFIG_DRIFT_CHECK_INTERVAL must be positive")
+	}
+
+	if cfg.CatalogEnabled && cfg.CatalogRefreshInterval <= 0 {
+		return errors.New("CATALOG_REFRESH_INTERVAL must be positive")
+	}
+
+	if cfg.MaintenanceScoreEnabled {
+		if cfg.MaintenanceScoreInterval <= 0 {
+			return errors.New("MAINTENANCE_SCORE_INTERVAL must be positive")
+		}
+		if cfg.MaintenanceDiskMinFreeRatio <= 0 || cfg.MaintenanceDiskMinFreeRatio >= 1 {
+			return errors.New("MAINTENANCE_DISK_MIN_FREE_RATIO must be between 0 and 1")
+		}
+		if cfg.MaintenanceSafeScore < 0 || cfg.MaintenanceSafeScore > 100 {
+			return errors.New("MAINTENANCE_SAFE_SCORE must be between 0 and 100")
+		}
+	}
+
+	if cfg.KRaftMetadataLogDir != "" {
+		if cfg.KRaftMetadataLogInterval <= 0 {
+			return errors.New("KRAFT_METADATA_LOG_INTERVAL must be positive")
+		}
+		if cfg.KRaftMetadataLogMaxBytes < 0 {
+			return errors.New("KRAFT_METADATA_LOG_MAX_BYTES must not be negative")
+		}
+		if cfg.KRaftSnapshotMaxAge < 0 {
+			return errors.New("KRAFT_SNAPSHOT_MAX_AGE must not be negative")
+		}
+	}
+
+	if cfg.GCLogPath != "" && cfg.GCLogInterval <= 0 {
+		return errors.New("GC_LOG_INTERVAL must be positive")
+	}
+
+	if err := validateStaleAfter(cfg, role.Profile()); err != nil {
+		return err
+	}
+
+	if cfg.DNSSuffix, err = discovery.ParseDNSSuffix(cfg.DNSSuffix); err != nil {
+		return fmt.Errorf("invalid DNS_SUFFIX: %w", err)
+	}
+	if cfg.ReplicaHostnamePrefix, err = discovery.ParseReplicaPrefix(cfg.ReplicaHostnamePrefix); err != nil {
+		return fmt.Errorf("invalid REPLICA_HOSTNAME_PREFIX: %w", err)
+	}
+	if cfg.BootstrapRefreshInterval < 0 {
+		return errors.New("BOOTSTRAP_REFRESH_INTERVAL must not be negative")
+	}
+	if _, err := discovery.ParseBrokerIDMap(cfg.BrokerIDMap); err != nil {
+		return fmt.Errorf("invalid BROKER_ID_MAP: %w", err)
+	}
+	if cfg.ReplicaCountFromAPI && cfg.CplnToken == "" {
+		return errors.New("REPLICA_COUNT_FROM_API requires CPLN_TOKEN")
+	}
+	if cfg.DNSPreflightInterval <= 0 {
+		return errors.New("DNS_PREFLIGHT_INTERVAL must be positive")
+	}
+	if cfg.DNSPreflightTimeout <= 0 {
+		return errors.New("DNS_PREFLIGHT_TIMEOUT must be positive")
+	}
+	if cfg.KRaftControllerPort < 1 || cfg.KRaftControllerPort > 65535 {
+		return errors.New("KRAFT_CONTROLLER_PORT must be between 1 and 65535")
+	}
+	if cfg.KRaftControllerCount < 1 {
+		return errors.New("KRAFT_CONTROLLER_COUNT must be positive")
+	}
+	if cfg.KRaftControllerIDOffset < 0 {
+		return errors.New("KRAFT_CONTROLLER_ID_OFFSET must not be negative")
+	}
+	if _, err := listeners.ParsePortMap(cfg.ExternalListenerPortMap); err != nil {
+		return fmt.Errorf("invalid EXTERNAL_LISTENER_PORT_MAP: %w", err)
+	}
+	if err := (listeners.Options{
+		InternalName: cfg.InternalListenerName,
+		External: listeners.External{
+			Name: cfg.ExternalListenerName,
+			Host: cfg.ExternalListenerHost,
+			Port: cfg.ExternalListenerPort,
+		},
+	}).Validate(); err != nil {
+		return fmt.Errorf("invalid listeners: %w", err)
+	}
+
+	// Auto-discover broker ID if BROKER_ID env var is not explicitly set
+	if env.Get("BROKER_ID") == "" {
+		// Validated above
+		brokerIDMap, _ := discovery.ParseBrokerIDMap(cfg.BrokerIDMap)
+		brokerID, err := env.DiscoverBrokerID(discovery.BrokerIDMapping{
+			Offset:    cfg.BrokerIDOffset,
+			Hostnames: brokerIDMap,
+		})
+		if err != nil {
+			return err
+		}
+		cfg.BrokerID = brokerID
+		found["BrokerID"] = true
+		logger.Info("auto-discovered broker ID from hostname",
+			"brokerID", brokerID,
+			"hostname", env.Get("HOSTNAME"))
+	}
+
+	if cfg.RackZoneFile != "" && !filepath.IsAbs(cfg.RackZoneFile) {
+		return errors.New("RACK_ZONE_FILE must be an absolute path")
+	}
+	if cfg.RackEnvFile != "" && !filepath.IsAbs(cfg.RackEnvFile) {
+		return errors.New("RACK_ENV_FILE must be an absolute path")
+	}
+
+	// Derive the rack if not explicitly set. Outside Control Plane there is no
+	// location to derive it from, which only matters when the rack is asked for.
+	if cfg.Rack == "" {
+		rack, err := env.DiscoverRack(cfg.RackZoneFile)
+		switch {
+		case err == nil:
+			cfg.Rack = rack.String()
+			found["Rack"] = true
+			logger.Info("derived rack from location and zone",
+				"rack", cfg.Rack)
+		case cfg.RackZoneFile != "" || cfg.RackEnvFile != "":
+			return fmt.Errorf("failed to derive the rack: %w", err)
+		}
+	}
+
+	// Auto-build bootstrap servers if not explicitly set
+	if cfg.BootstrapServers == "" {
+		// Try to get workload name from config, or discover from CPLN_WORKLOAD
+		if cfg.WorkloadName == "" {
+			discovered, err := env.DiscoverWorkloadName()
+			if err != nil {
+				return err
+			}
+			cfg.WorkloadName = discovered
+			found["WorkloadName"] = true
+			logger.Info("discovered workload name from CPLN_WORKLOAD",
+				"workloadName", cfg.WorkloadName)
+		}
+
+		if cfg.GvcAlias == "" {
+			discovered, err := env.DiscoverGvcAlias()
+			if err != nil {
+				return err
+			}
+			cfg.GvcAlias = discovered
+			found["GvcAlias"] = true
+			logger.Info("discovered GVC alias from CPLN_GVC_ALIAS",
+				"gvcAlias", cfg.GvcAlias)
+		}
+
+		location, _ := env.DiscoverLocation()
+		locations, err := discovery.ParseLocations(cfg.Locations, location)
+		if err != nil {
+			return fmt.Errorf("invalid LOCATIONS: %w", err)
+		}
+		if len(locations) > 0 && cfg.GvcName == "" {
+			discovered, err := env.DiscoverGvcName()
+			if err != nil {
+				return fmt.Errorf("LOCATIONS requires GVC_NAME: %w", err)
+			}
+			cfg.GvcName = discovered
+			found["GvcName"] = true
+			logger.Info("discovered GVC name from CPLN_GVC",
+				"gvcName", cfg.GvcName)
+		}
+
+		topology := cfg.Topology()
+		counts := map[string]int{}
+		for _, site := range topology.Sites() {
+			counts[site] = cfg.ReplicaCount
+		}
+		if cfg.ReplicaCountFromAPI {
+			discoverReplicaCounts(cfg, topology, counts, found, logger)
+		}
+
+		cfg.BootstrapServers = strings.Join(topology.BootstrapServers(counts), ",")
+		found["BootstrapServers"] = true
+		logger.Info("auto-built bootstrap servers",
+			"bootstrapServers", cfg.BootstrapServers)
+	}
+
+	if cfg.KRaftVotersFile != "" {
+		if !filepath.IsAbs(cfg.KRaftVotersFile) {
+			return errors.New("KRAFT_VOTERS_FILE must be an absolute path")
+		}
+		if _, err := cfg.QuorumVoters(); err != nil {
+			return fmt.Errorf("failed to generate the quorum voters: %w", err)
+		}
+	}
+	if cfg.AdvertisedListenersFile != "" {
+		if !filepath.IsAbs(cfg.AdvertisedListenersFile) {
+			return errors.New("ADVERTISED_LISTENERS_FILE must be an absolute path")
+		}
+		if _, err := cfg.AdvertisedListeners(); err != nil {
+			return fmt.Errorf("failed to generate the advertised listeners: %w", err)
+		}
+	}
+	if cfg.BrokerEpochCheckEnabled {
+		if cfg.KRaftMetadataLogDir == "" {
+			return errors.New("BROKER_EPOCH_CHECK_ENABLED requires KRAFT_METADATA_LOG_DIR")
+		}
+		if cfg.BrokerEpochCheckInterval <= 0 {
+			return errors.New("BROKER_EPOCH_CHECK_INTERVAL must be positive")
+		}
+	}
+	if cfg.BrokerIDConflictCheckEnabled {
+		if _, err := cfg.AdvertisedListeners(); err != nil {
+			return fmt.Errorf("BROKER_ID_CONFLICT_CHECK_ENABLED: failed to generate the advertised listeners: %w", err)
+		}
+	}
+	if cfg.LogDirs == "" {
+		cfg.LogDirs = cfg.DiskUsagePaths
+		found["LogDirs"] = true
+	}
+	for _, path := range metrics.ParseDiskPaths(cfg.LogDirs) {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("LOG_DIRS entries must be absolute paths: %s", path)
+		}
+	}
+	if cfg.ClusterID != "" {
+		if err := kraft.ValidateClusterID(cfg.ClusterID); err != nil {
+			return fmt.Errorf("invalid CLUSTER_ID: %w", err)
+		}
+	}
+	if cfg.ClusterIDFile != "" && !filepath.IsAbs(cfg.ClusterIDFile) {
+		return errors.New("CLUSTER_ID_FILE must be an absolute path")
+	}
+	if _, err := kraft.NewClusterIDStore(cfg.ClusterID, cfg.ClusterIDFile); err != nil {
+		return fmt.Errorf("invalid CLUSTER_ID_FILE: %w", err)
+	}
+	if cfg.StorageInitEnabled && (cfg.ClusterID == "" || cfg.LogDirs == "") {
+		return errors.New("STORAGE_INIT_ENABLED requires CLUSTER_ID and LOG_DIRS or DISK_USAGE_PATHS")
+	}
+	if cfg.ServerPropertiesTemplate != "" && !filepath.IsAbs(cfg.ServerPropertiesTemplate) {
+		return errors.New("SERVER_PROPERTIES_TEMPLATE must be an absolute path")
+	}
+	if cfg.ServerPropertiesFile != "" {
+		if !filepath.IsAbs(cfg.ServerPropertiesFile) {
+			return errors.New("SERVER_PROPERTIES_FILE must be an absolute path")
+		}
+		if _, err := cfg.ServerProperties(); err != nil {
+			return fmt.Errorf("failed to render server.properties: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// replicaCountTimeout bounds the Control Plane API requests of discoverReplicaCounts
+const replicaCountTimeout = 10 * time.Second
+
+// discoverReplicaCounts replaces the replica count of every location with the
+// workload's scale there from the Control Plane API, and ReplicaCount with the
+// local one. Counts are kept when the API cannot be reached, so an API outage
+// does not stop the sidecar from starting.
+func discoverReplicaCounts(cfg *ConfigSchema, topology discovery.Topology, counts map[string]int, found map[string]bool, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaCountTimeout)
+	defer cancel()
+	opts := discovery.APIOptions{Endpoint: cfg.CplnEndpoint, Token: cfg.CplnToken}
+	for _, location := range topology.Sites() {
+		count, err := discovery.DiscoverReplicaCount(ctx, http.DefaultClient, opts, cfg.environment().Get("CPLN_WORKLOAD"), location)
+		if err != nil {
+			logger.Warn("failed to discover the replica count from the Control Plane API, using REPLICA_COUNT",
+				"location", location,
+				"replicaCount", counts[location],
+				"error", err)
+			continue
+		}
+		counts[location] = count
+		if location == topology.Local {
+			cfg.ReplicaCount = count
+			found["ReplicaCount"] = true
+		}
+		logger.Info("discovered replica count from the Control Plane API",
+			"location", location,
+			"replicaCount", count)
+	}
+}
+
+// validateListeners checks the ports and timeouts of the HTTP listeners
+func validateListeners(cfg *ConfigSchema) error {
+	if cfg.HTTPReadTimeout <= 0 || cfg.HTTPWriteTimeout <= 0 {
+		return errors.New("HTTP_READ_TIMEOUT and HTTP_WRITE_TIMEOUT must be positive")
+	}
+	if cfg.AdminPort < 0 {
+		return errors.New("ADMIN_PORT must not be negative")
+	}
+	if cfg.AdminPort == 0 {
+		if cfg.AdminTLSClientAuth != "" {
+			return errors.New("ADMIN_TLS_CLIENT_AUTH requires ADMIN_PORT")
+		}
+		return nil
+	}
+	if cfg.AdminPort == cfg.Port {
+		return errors.New("ADMIN_PORT must differ from PORT")
+	}
+	if cfg.AdminReadTimeout <= 0 || cfg.AdminWriteTimeout <= 0 {
+		return errors.New("ADMIN_READ_TIMEOUT and ADMIN_WRITE_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// validateServerTLS checks the TLS settings of the sidecar's HTTP listeners and
+// that the certificate and client CA bundles load
+func validateServerTLS(cfg *ConfigSchema) error {
+	clientAuth, err := servertls.ParseClientAuth(cfg.ServerTLSClientAuth)
+	if err != nil {
+		return fmt.Errorf("invalid SERVER_TLS_CLIENT_AUTH: %w", err)
+	}
+	adminClientAuth := clientAuth
+	if cfg.AdminTLSClientAuth != "" {
+		if adminClientAuth, err = servertls.ParseClientAuth(cfg.AdminTLSClientAuth); err != nil {
+			return fmt.Errorf("invalid ADMIN_TLS_CLIENT_AUTH: %w", err)
+		}
+	}
+	if (cfg.ServerTLSCertFile == "") != (cfg.ServerTLSKeyFile == "") {
+		return errors.New("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+	}
+	if cfg.ServerTLSCertFile == "" {
+		if clientAuth != servertls.ClientAuthNone || adminClientAuth != servertls.ClientAuthNone || cfg.AdminClientCertRequired {
+			return errors.New("SERVER_TLS_CLIENT_AUTH, ADMIN_TLS_CLIENT_AUTH and ADMIN_CLIENT_CERT_REQUIRED require SERVER_TLS_CERT_FILE")
+		}
+		return nil
+	}
+	if cfg.AdminClientCertRequired && adminClientAuth == servertls.ClientAuthNone {
+		return errors.New("ADMIN_CLIENT_CERT_REQUIRED requires client auth optional or require on the listener serving admin endpoints")
+	}
+	if (clientAuth != servertls.ClientAuthNone || adminClientAuth != servertls.ClientAuthNone) && cfg.ServerTLSClientCAFiles == "" {
+		return errors.New("SERVER_TLS_CLIENT_AUTH and ADMIN_TLS_CLIENT_AUTH require SERVER_TLS_CLIENT_CA_FILES")
+	}
+	for _, mode := range []servertls.ClientAuth{clientAuth, adminClientAuth} {
+		if _, err := servertls.NewConfig(servertls.Options{
+			CertFile:      cfg.ServerTLSCertFile,
+			KeyFile:       cfg.ServerTLSKeyFile,
+			ClientCAFiles: kafkaclient.ParseCAFiles(cfg.ServerTLSClientCAFiles),
+			ClientAuth:    mode,
+		}); err != nil {
+			return fmt.Errorf("invalid server TLS: %w", err)
+		}
+	}
+	return nil
+}
+
+// AlertingEnabled reports whether a notification sink is configured
+func (c *ConfigSchema) AlertingEnabled() bool {
+	return c.AlertSlackWebhookURL != "" || c.AlertPagerDutyRoutingKey != ""
+}
+
+// validateAlerting checks the notification sinks and alert conditions
+func validateAlerting(cfg *ConfigSchema) error {
+	if !cfg.AlertingEnabled() {
+		return nil
+	}
+	urls := map[string]string{
+		"ALERT_SLACK_WEBHOOK_URL": cfg.AlertSlackWebhookURL,
+		"ALERT_PAGERDUTY_URL":     cfg.AlertPagerDutyURL,
+	}
+	for env, raw := range urls {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			// The Slack webhook URL is a secret, so only the variable is named
+			return fmt.Errorf("invalid %s: expected an http or https URL", env)
+		}
+	}
+	if cfg.AlertInterval <= 0 {
+		return errors.New("ALERT_INTERVAL must be positive")
+	}
+	if cfg.AlertBrokerUnreadyAfter < 0 {
+		return errors.New("ALERT_BROKER_UNREADY_AFTER must not be negative")
+	}
+	if cfg.AlertDiskUsageRatio < 0 || cfg.AlertDiskUsageRatio >= 1 {
+		return errors.New("ALERT_DISK_USAGE_RATIO must be at least 0 and below 1")
+	}
+	if cfg.AlertCertMinValidity < 0 {
+		return errors.New("ALERT_CERT_MIN_VALIDITY must not be negative")
+	}
+	if cfg.AlertRepeatInterval < 0 {
+		return errors.New("ALERT_REPEAT_INTERVAL must not be negative")
+	}
+	if _, err := alerting.ParseTemplate(cfg.AlertTemplate); err != nil {
+		return fmt.Errorf("invalid ALERT_TEMPLATE: %w", err)
+	}
+	return nil
+}
+
+// validateBackup checks the bucket settings of the metadata backup
+func validateBackup(cfg *ConfigSchema) error {
+	if cfg.BackupBucket == "" {
+		return nil
+	}
+	if cfg.BackupEndpoint != "" {
+		if u, err := url.Parse(cfg.BackupEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid BACKUP_ENDPOINT %q: expected an http or https URL", cfg.BackupEndpoint)
+		}
+	}
+	if cfg.BackupRegion == "" {
+		return errors.New("BACKUP_BUCKET requires BACKUP_REGION")
+	}
+	if cfg.BackupAccessKeyID == "" || cfg.BackupSecretAccessKey == "" {
+		return errors.New("BACKUP_BUCKET requires BACKUP_ACCESS_KEY_ID and BACKUP_SECRET_ACCESS_KEY")
+	}
+	if cfg.BackupInterval <= 0 {
+		return errors.New("BACKUP_INTERVAL must be positive")
+	}
+	return nil
+}
+
+// BackupS3Options returns the bucket of the metadata backup
+func (c *ConfigSchema) BackupS3Options() backup.S3Options {
+	return backup.S3Options{
+		Bucket:          c.BackupBucket,
+		Endpoint:        c.BackupEndpoint,
+		Region:          c.BackupRegion,
+		AccessKeyID:     c.BackupAccessKeyID,
+		SecretAccessKey: c.BackupSecretAccessKey,
+		SessionToken:    c.BackupSessionToken,
+	}
+}
+
+// validateSecrets checks the secret manager settings and that the secrets
+// fetched from it have somewhere to go
+func validateSecrets(cfg *ConfigSchema) error {
+	refs := map[string]string{
+		"SECRET_SASL_USERNAME_REF": cfg.SecretSASLUsernameRef,
+		"SECRET_SASL_PASSWORD_REF": cfg.SecretSASLPasswordRef,
+		"SECRET_TLS_CERT_REF":      cfg.SecretTLSCertRef,
+		"SECRET_TLS_KEY_REF":       cfg.SecretTLSKeyRef,
+	}
+	set := 0
+	for env, ref := range refs {
+		if ref == "" {
+			continue
+		}
+		set++
+		if _, _, err := secrets.ParseRef(ref); err != nil {
+			return fmt.Errorf("invalid %s: %w", env, err)
+		}
+	}
+	if cfg.SecretProvider == "" {
+		if set > 0 {
+			return errors.New("SECRET_SASL_USERNAME_REF, SECRET_SASL_PASSWORD_REF, SECRET_TLS_CERT_REF and SECRET_TLS_KEY_REF require SECRET_PROVIDER")
+		}
+		return nil
+	}
+	if set == 0 {
+		return errors.New("SECRET_PROVIDER requires at least one of SECRET_SASL_USERNAME_REF, SECRET_SASL_PASSWORD_REF, SECRET_TLS_CERT_REF and SECRET_TLS_KEY_REF")
+	}
+	if (cfg.SecretSASLUsernameRef != "" || cfg.SecretSASLPasswordRef != "") && !cfg.SASLEnabled {
+		return errors.New("SECRET_SASL_USERNAME_REF and SECRET_SASL_PASSWORD_REF require SASL_ENABLED=true")
+	}
+	if (cfg.SecretTLSCertRef == "") != (cfg.SecretTLSKeyRef == "") {
+		return errors.New("SECRET_TLS_CERT_REF and SECRET_TLS_KEY_REF must be set together")
+	}
+	if cfg.SecretTLSCertRef != "" && (!cfg.TLSEnabled || cfg.TLSCertFile == "") {
+		return errors.New("SECRET_TLS_CERT_REF and SECRET_TLS_KEY_REF require TLS_ENABLED=true and TLS_CERT_FILE and TLS_KEY_FILE to write them to")
+	}
+	if cfg.SecretRefreshInterval < 0 {
+		return errors.New("SECRET_REFRESH_INTERVAL must not be negative")
+	}
+	if cfg.SecretTimeout <= 0 {
+		return errors.New("SECRET_TIMEOUT must be positive")
+	}
+
+	kind, err := secrets.ParseKind(cfg.SecretProvider)
+	if err != nil {
+		return fmt.Errorf("invalid SECRET_PROVIDER: %w", err)
+	}
+	if kind == secrets.KindGCP && cfg.GCPProject == "" {
+		for env, ref := range refs {
+			if ref != "" && !strings.HasPrefix(ref, "projects/") {
+				return fmt.Errorf("%s names a secret without its project, which requires GCP_PROJECT", env)
+			}
+		}
+	}
+	if _, err := secrets.New(cfg.SecretOptions()); err != nil {
+		return fmt.Errorf("invalid SECRET_PROVIDER settings: %w", err)
+	}
+	return nil
+}
+
+// SecretOptions returns the options of the secret manager
+func (c *ConfigSchema) SecretOptions() secrets.Options {
+	// Validated in validateSecrets
+	kind, _ := secrets.ParseKind(c.SecretProvider)
+	return secrets.Options{
+		Kind: kind,
+		Vault: secrets.VaultOptions{
+			Address:   c.VaultAddr,
+			Token:     c.VaultToken,
+			Namespace: c.VaultNamespace,
+			Mount:     c.VaultKVMount,
+		},
+		AWS: secrets.AWSOptions{
+			Region:          c.AWSRegion,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			SessionToken:    c.AWSSessionToken,
+			Endpoint:        c.AWSSecretsManagerEndpoint,
+		},
+		GCP:     secrets.GCPOptions{Project: c.GCPProject},
+		Timeout: c.SecretTimeout,
+	}
+}
+
+// Naming returns how the replicas are named in DNS
+func (c *ConfigSchema) Naming() discovery.Naming {
+	return discovery.Naming{ReplicaPrefix: c.ReplicaHostnamePrefix, DNSSuffix: c.DNSSuffix}
+}
+
+// Topology returns the locations the cluster's replicas run in
+func (c *ConfigSchema) Topology() discovery.Topology {
+	location, _ := c.environment().DiscoverLocation()
+	// Validated in load
+	locations, _ := discovery.ParseLocations(c.Locations, location)
+	return discovery.Topology{
+		WorkloadName: c.WorkloadName,
+		GvcAlias:     c.GvcAlias,
+		Port:         c.KafkaPort,
+		Naming:       c.Naming(),
+		Local:        location,
+		Locations:    locations,
+		GvcName:      c.GvcName,
+	}
+}
+
+// discoveredTopology returns Topology with the workload name and GVC alias
+// discovered when not set, as they are for auto-built bootstrap servers
+func (c *ConfigSchema) discoveredTopology() (discovery.Topology, error) {
+	topology := c.Topology()
+	var err error
+	if topology.WorkloadName == "" {
+		if topology.WorkloadName, err = c.environment().DiscoverWorkloadName(); err != nil {
+			return discovery.Topology{}, err
+		}
+	}
+	if topology.GvcAlias == "" {
+		if topology.GvcAlias, err = c.environment().DiscoverGvcAlias(); err != nil {
+			return discovery.Topology{}, err
+		}
+	}
+	return topology, nil
+}
+
+// QuorumVoters returns the KRaft controller quorum: the replicas of
+// KRaftControllerWorkload, or else of this workload in every location
+func (c *ConfigSchema) QuorumVoters() ([]discovery.Voter, error) {
+	topology, err := c.discoveredTopology()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.KRaftControllerWorkload != "" && c.KRaftControllerWorkload != topology.WorkloadName {
+		topology.WorkloadName = c.KRaftControllerWorkload
+		// The prefix names this workload's replicas, not the controllers'
+		topology.Naming.ReplicaPrefix = ""
+		counts := map[string]int{}
+		for _, site := range topology.Sites() {
+			counts[site] = c.KRaftControllerCount
+		}
+		return topology.Voters(counts, c.KRaftControllerPort, discovery.BrokerIDMapping{Offset: c.KRaftControllerIDOffset})
+	}
+
+	// Auto-built bootstrap servers hold the replica count of every location
+	counts := topology.Counts(kafkaclient.ParseBootstrapServers(c.BootstrapServers))
+	for _, site := range topology.Sites() {
+		if counts[site] == 0 {
+			counts[site] = c.ReplicaCount
+		}
+	}
+	// Validated in load
+	brokerIDMap, _ := discovery.ParseBrokerIDMap(c.BrokerIDMap)
+	return topology.Voters(counts, c.KRaftControllerPort, discovery.BrokerIDMapping{
+		Offset:    c.BrokerIDOffset,
+		Hostnames: brokerIDMap,
+	})
+}
+
+// AdvertisedListeners returns the broker's advertised.listeners: its own
+// headless Service record on KafkaPort, and the external endpoint if configured
+func (c *ConfigSchema) AdvertisedListeners() ([]listeners.Listener, error) {
+	topology, err := c.discoveredTopology()
+	if err != nil {
+		return nil, err
+	}
+	hostname := c.environment().Get("HOSTNAME")
+	index, err := discovery.ParseBrokerIDFromHostname(hostname)
+	if err != nil {
+		return nil, err
+	}
+	// Validated in load
+	ports, _ := listeners.ParsePortMap(c.ExternalListenerPortMap)
+	return listeners.Advertised(c.BrokerID, int(index), listeners.Options{
+		InternalName: c.InternalListenerName,
+		InternalHost: topology.Hostname(topology.Local, int(index)),
+		InternalPort: c.KafkaPort,
+		External: listeners.External{
+			Name:  c.ExternalListenerName,
+			Host:  c.ExternalListenerHost,
+			Port:  c.ExternalListenerPort,
+			Ports: ports,
+		},
+	})
+}
+
+// ServerProperties renders the node's server.properties from
+// ServerPropertiesTemplate, or the built-in template, and the discovered node
+// ID, rack, listeners, quorum voters and log directories
+func (c *ConfigSchema) ServerProperties() (string, error) {
+	if !c.Profile().MetadataLog {
+		return "", fmt.Errorf("ROLE %s does not run a KRaft node", c.Role)
+	}
+	tmpl := serverprops.DefaultTemplate
+	if c.ServerPropertiesTemplate != "" {
+		content, err := os.ReadFile(c.ServerPropertiesTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %w", err)
+		}
+		tmpl = string(content)
+	}
+	logDirs := metrics.ParseDiskPaths(c.LogDirs)
+	if len(logDirs) == 0 {
+		return "", errors.New("LOG_DIRS or DISK_USAGE_PATHS is required")
+	}
+	voters, err := c.QuorumVoters()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the quorum voters: %w", err)
+	}
+
+	// With a controller workload of their own, this workload's nodes are brokers only
+	broker := Role(c.Role) != RoleController
+	controller := !broker || c.KRaftControllerWorkload == ""
+	var roles []string
+	if broker {
+		roles = append(roles, "broker")
+	}
+	if controller {
+		roles = append(roles, "controller")
+	}
+
+	protocol := "PLAINTEXT"
+	switch {
+	case c.TLSEnabled && c.SASLEnabled:
+		protocol = "SASL_SSL"
+	case c.TLSEnabled:
+		protocol = "SSL"
+	case c.SASLEnabled:
+		protocol = "SASL_PLAINTEXT"
+	}
+	var bind, protocolMap []string
+	values := serverprops.Values{
+		NodeID:                 c.BrokerID,
+		ProcessRoles:           strings.Join(roles, ","),
+		Broker:                 broker,
+		InternalListenerName:   c.InternalListenerName,
+		ControllerListenerName: serverprops.ControllerListenerName,
+		QuorumVoters:           discovery.FormatVoters(voters),
+		LogDirs:                logDirs,
+	}
+	if broker {
+		values.Rack = c.Rack
+		advertised, err := c.AdvertisedListeners()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate the advertised listeners: %w", err)
+		}
+		values.AdvertisedListeners = listeners.Format(advertised)
+		bind = append(bind, fmt.Sprintf("%s://:%d", c.InternalListenerName, c.KafkaPort))
+		protocolMap = append(protocolMap, c.InternalListenerName+":"+protocol)
+		if c.ExternalListenerHost != "" {
+			// The port map only changes the advertised ports, behind a load balancer
+			bind = append(bind, fmt.Sprintf("%s://:%d", c.ExternalListenerName, c.ExternalListenerPort))
+			protocolMap = append(protocolMap, c.ExternalListenerName+":"+protocol)
+		}
+	}
+	if controller {
+		bind = append(bind, fmt.Sprintf("%s://:%d", serverprops.ControllerListenerName, c.KRaftControllerPort))
+		protocolMap = append(protocolMap, serverprops.ControllerListenerName+":PLAINTEXT")
+	}
+	values.Listeners = strings.Join(bind, ",")
+	values.ProtocolMap = strings.Join(protocolMap, ",")
+	return serverprops.Render(tmpl, values)
+}
+
+// KafkaRetryPolicy returns the retry policy of the sidecar's Kafka calls
+func (c *ConfigSchema) KafkaRetryPolicy() retry.Policy {
+	return retry.Policy{
+		Attempts:  c.KafkaRetryAttempts,
+		BaseDelay: c.KafkaRetryBaseDelay,
+		MaxDelay:  c.KafkaRetryMaxDelay,
+		Jitter:    c.KafkaRetryJitter,
+	}
+}
+
+// validateStaleAfter rejects a staleness bound that enabled background loops
+// cannot meet even when healthy
+func validateStaleAfter(cfg *ConfigSchema, profile Profile) error {
+	if cfg.CheckStaleAfter <= 0 {
+		return nil
+	}
+
+	intervals := map[string]time.Duration{}
+	if cfg.QuotaRecommenderEnabled {
+		intervals["QUOTA_SAMPLE_INTERVAL"] = cfg.QuotaSampleInterval
+	}
+	if cfg.OnboardingEnabled {
+		intervals["ONBOARDING_CHECK_INTERVAL"] = cfg.OnboardingCheckInterval
+	}
+	if cfg.CanaryEnabled {
+		intervals["CANARY_INTERVAL"] = cfg.CanaryInterval
+	}
+	if cfg.RequestLatencyEnabled {
+		intervals["REQUEST_LATENCY_INTERVAL"] = cfg.RequestLatencyInterval
+	}
+	if cfg.TLSHandshakeListeners != "" {
+		intervals["TLS_HANDSHAKE_INTERVAL"] = cfg.TLSHandshakeInterval
+	}
+	if cfg.InterBrokerTLSCheckEnabled {
+		intervals["INTER_BROKER_TLS_INTERVAL"] = cfg.InterBrokerTLSInterval
+	}
+	if cfg.PeerCheckEnabled {
+		intervals["PEER_CHECK_INTERVAL"] = cfg.PeerCheckInterval
+	}
+	if cfg.PartitionSizeMetricsEnabled {
+		intervals["PARTITION_SIZE_INTERVAL"] = cfg.PartitionSizeInterval
+	}
+	if cfg.TLSEnabled {
+		intervals["TLS_EXPIRY_CHECK_INTERVAL"] = cfg.TLSExpiryCheckInterval
+	}
+	if cfg.BootstrapServers == "" && cfg.BootstrapRefreshInterval > 0 {
+		intervals["BOOTSTRAP_REFRESH_INTERVAL"] = cfg.BootstrapRefreshInterval
+	}
+	if cfg.SecretProvider != "" && cfg.SecretRefreshInterval > 0 {
+		intervals["SECRET_REFRESH_INTERVAL"] = cfg.SecretRefreshInterval
+	}
+	if profile.Standby {
+		intervals["STANDBY_CHECK_INTERVAL"] = cfg.StandbyCheckInterval
+	}
+	if cfg.SCRAMCredentialsFile != "" {
+		intervals["SCRAM_CHECK_INTERVAL"] = cfg.SCRAMCheckInterval
+	}
+	if cfg.ConfigDriftSpecFile != "" {
+		intervals["CONFIG_DRIFT_CHECK_INTERVAL"] = cfg.ConfigDriftCheckInterval
+	}
+	if cfg.CatalogEnabled {
+		intervals["CATALOG_REFRESH_INTERVAL"] = cfg.CatalogRefreshInterval
+	}
+	if cfg.MaintenanceScoreEnabled {
+		intervals["MAINTENANCE_SCORE_INTERVAL"] = cfg.MaintenanceScoreInterval
+	}
+	if cfg.KRaftMetadataLogDir != "" {
+		intervals["KRAFT_METADATA_LOG_INTERVAL"] = cfg.KRaftMetadataLogInterval
+	}
+	if cfg.BrokerEpochCheckEnabled {
+		intervals["BROKER_EPOCH_CHECK_INTERVAL"] = cfg.BrokerEpochCheckInterval
+	}
+	if cfg.GCLogPath != "" {
+		intervals["GC_LOG_INTERVAL"] = cfg.GCLogInterval
+	}
+	if profile.BrokerChecks && cfg.RequestErrorsEnabled {
+		intervals["REQUEST_ERRORS_INTERVAL"] = cfg.RequestErrorsInterval
+	}
+	if profile.Metrics && cfg.OOMPredictionWindow > 0 {
+		intervals["OOM_PREDICTION_INTERVAL"] = cfg.OOMPredictionInterval
+	}
+	if profile.Metrics && cfg.OTLPMetricsEndpoint != "" {
+		intervals["OTLP_METRICS_INTERVAL"] = cfg.OTLPMetricsInterval
+	}
+	if profile.Metrics && cfg.StatsDAddress != "" {
+		intervals["STATSD_INTERVAL"] = cfg.StatsDInterval
+	}
+	if cfg.StatusFilePath != "" {
+		intervals["STATUS_FILE_INTERVAL"] = cfg.StatusFileInterval
+	}
+	if cfg.AlertingEnabled() {
+		intervals["ALERT_INTERVAL"] = cfg.AlertInterval
+	}
+	for name, interval := range intervals {
+		if interval >= cfg.CheckStaleAfter {
+			return fmt.Errorf("CHECK_STALE_AFTER (%s) must be longer than %s (%s)", cfg.CheckStaleAfter, name, interval)
 		}
-
-		Config.BootstrapServers = discovery.BuildBootstrapServers(
-			workloadName,
-			gvcAlias,
-			Config.ReplicaCount,
-			Config.KafkaPort,
-		)
-		logger.Info("auto-built bootstrap servers",
-			"bootstrapServers", Config.BootstrapServers)
 	}
-
 	return nil
 }
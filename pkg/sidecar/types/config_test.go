@@ -2,9 +2,16 @@ package types
 
 import (
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/listeners"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/serverprops"
 )
 
 func testLogger() *slog.Logger {
@@ -134,6 +141,442 @@ func TestInitialize_WithAllEnvVars(t *testing.T) {
 	}
 }
 
+func TestInitialize_CustomDNSNaming(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "replica-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "REPLICA_COUNT", "2"),
+		setEnv(t, "KAFKA_PORT", "9092"),
+		setEnv(t, "DNS_SUFFIX", "svc.corp.internal."),
+		setEnv(t, "REPLICA_HOSTNAME_PREFIX", "replica-"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	expected := "replica-0.kafka.abc123xyz.svc.corp.internal:9092,replica-1.kafka.abc123xyz.svc.corp.internal:9092"
+	if Config.BootstrapServers != expected {
+		t.Errorf("expected BootstrapServers=%q, got %q", expected, Config.BootstrapServers)
+	}
+	if Config.BrokerID != 1 {
+		t.Errorf("expected BrokerID=1, got %d", Config.BrokerID)
+	}
+}
+
+func TestInitialize_StretchCluster(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-0"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/prod/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "CPLN_GVC", "prod"),
+		setEnv(t, "CPLN_LOCATION", "aws-us-west-2"),
+		setEnv(t, "LOCATIONS", "aws-us-west-2,aws-us-east-1"),
+		setEnv(t, "REPLICA_COUNT", "2"),
+		setEnv(t, "KAFKA_PORT", "9092"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	expected := "kafka-0.kafka.abc123xyz.svc.cluster.local:9092,kafka-1.kafka.abc123xyz.svc.cluster.local:9092," +
+		"replica-0.kafka.aws-us-east-1.prod.cpln.local:9092,replica-1.kafka.aws-us-east-1.prod.cpln.local:9092"
+	if Config.BootstrapServers != expected {
+		t.Errorf("expected BootstrapServers=%q, got %q", expected, Config.BootstrapServers)
+	}
+
+	// The local location must be one of the cluster's
+	restore := setEnv(t, "CPLN_LOCATION", "gcp-us-east1")
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error when the local location is not listed")
+	}
+}
+
+func TestInitialize_ReplicaCountFromAPI(t *testing.T) {
+	logger := testLogger()
+
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available || r.URL.Path != "/org/test/gvc/test/workload/kafka" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"spec":{"defaultOptions":{"autoscaling":{"minScale":3,"maxScale":3}}}}`))
+	}))
+	defer server.Close()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-0"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "REPLICA_COUNT", "1"),
+		setEnv(t, "REPLICA_COUNT_FROM_API", "true"),
+		setEnv(t, "CPLN_ENDPOINT", server.URL),
+		setEnv(t, "CPLN_TOKEN", "token"),
+		unsetEnv(t, "CPLN_LOCATION"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.ReplicaCount != 3 {
+		t.Errorf("expected ReplicaCount=3 from the API, got %d", Config.ReplicaCount)
+	}
+
+	// An unavailable API falls back to REPLICA_COUNT
+	available = false
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.ReplicaCount != 1 {
+		t.Errorf("expected ReplicaCount=1 from REPLICA_COUNT, got %d", Config.ReplicaCount)
+	}
+}
+
+func TestInitialize_BrokerIDMapping(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-2"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "BROKER_ID_OFFSET", "100"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BROKER_ID_MAP"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.BrokerID != 102 {
+		t.Errorf("expected BrokerID=102, got %d", Config.BrokerID)
+	}
+
+	restore := setEnv(t, "BROKER_ID_MAP", "kafka-1=7,kafka-2=8")
+	defer restore()
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.BrokerID != 8 {
+		t.Errorf("expected BrokerID=8 from BROKER_ID_MAP, got %d", Config.BrokerID)
+	}
+
+	restoreInvalid := setEnv(t, "BROKER_ID_MAP", "kafka-1=7,kafka-2=7")
+	defer restoreInvalid()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a broker ID mapped twice")
+	}
+}
+
+func TestInitialize_Rack(t *testing.T) {
+	logger := testLogger()
+
+	zoneFile := filepath.Join(t.TempDir(), "labels")
+	if err := os.WriteFile(zoneFile, []byte("topology.kubernetes.io/zone=\"us-west-2a\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "CPLN_LOCATION", "/org/test/location/aws-us-west-2"),
+		setEnv(t, "RACK_ZONE_FILE", zoneFile),
+		unsetEnv(t, "RACK"),
+		unsetEnv(t, "RACK_ENV_FILE"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.Rack != "aws-us-west-2/us-west-2a" {
+		t.Errorf("expected the rack from the location and zone, got %q", Config.Rack)
+	}
+
+	restoreRack := setEnv(t, "RACK", "rack-1")
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.Rack != "rack-1" {
+		t.Errorf("expected the explicit rack, got %q", Config.Rack)
+	}
+	restoreRack()
+
+	restoreZone := setEnv(t, "RACK_ZONE_FILE", filepath.Join(t.TempDir(), "missing"))
+	defer restoreZone()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a missing zone file")
+	}
+}
+
+func TestInitialize_QuorumVoters(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-0"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "REPLICA_COUNT", "3"),
+		setEnv(t, "KRAFT_VOTERS_FILE", filepath.Join(t.TempDir(), "kafka.env")),
+		unsetEnv(t, "CPLN_LOCATION"),
+		unsetEnv(t, "LOCATIONS"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+		unsetEnv(t, "KRAFT_CONTROLLER_WORKLOAD"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	voters, err := Config.QuorumVoters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "0@kafka-0.kafka.abc123xyz.svc.cluster.local:9093," +
+		"1@kafka-1.kafka.abc123xyz.svc.cluster.local:9093," +
+		"2@kafka-2.kafka.abc123xyz.svc.cluster.local:9093"
+	if got := discovery.FormatVoters(voters); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Controllers running apart from the brokers
+	restore := []func(){
+		setEnv(t, "KRAFT_CONTROLLER_WORKLOAD", "kafka-controller"),
+		setEnv(t, "KRAFT_CONTROLLER_ID_OFFSET", "1000"),
+	}
+	defer func() {
+		for _, cleanup := range restore {
+			cleanup()
+		}
+	}()
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	voters, err = Config.QuorumVoters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(voters) != 3 || voters[0].String() != "1000@kafka-controller-0.kafka-controller.abc123xyz.svc.cluster.local:9093" {
+		t.Errorf("expected the controller workload's replicas, got %v", voters)
+	}
+}
+
+func TestInitialize_AdvertisedListeners(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "KAFKA_PORT", "9092"),
+		setEnv(t, "EXTERNAL_LISTENER_HOST", "kafka.example.com"),
+		setEnv(t, "EXTERNAL_LISTENER_PORT_MAP", "0=31090,1=31091"),
+		setEnv(t, "ADVERTISED_LISTENERS_FILE", filepath.Join(t.TempDir(), "kafka.env")),
+		unsetEnv(t, "CPLN_LOCATION"),
+		unsetEnv(t, "LOCATIONS"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BROKER_ID_OFFSET"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	advertised, err := Config.AdvertisedListeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "INTERNAL://kafka-1.kafka.abc123xyz.svc.cluster.local:9092,EXTERNAL://kafka.example.com:31091"
+	if got := listeners.Format(advertised); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	restore := setEnv(t, "EXTERNAL_LISTENER_PORT_MAP", "0=31090,1=31090")
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a port mapped to two brokers")
+	}
+}
+
+func TestInitialize_BrokerIDConflictCheck(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "BROKER_ID_CONFLICT_CHECK_ENABLED", "true"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "ROLE"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	restore := setEnv(t, "ROLE", "mirrormaker")
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a role without broker checks")
+	}
+}
+
+func TestInitialize_BrokerEpochCheck(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "BROKER_EPOCH_CHECK_ENABLED", "true"),
+		unsetEnv(t, "KRAFT_METADATA_LOG_DIR"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "ROLE"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error without KRAFT_METADATA_LOG_DIR")
+	}
+
+	restoreDir := setEnv(t, "KRAFT_METADATA_LOG_DIR", "/var/lib/kafka/metadata")
+	defer restoreDir()
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.BrokerEpochCheckInterval != 15*time.Second {
+		t.Errorf("expected a 15s interval, got %s", Config.BrokerEpochCheckInterval)
+	}
+
+	restoreInterval := setEnv(t, "BROKER_EPOCH_CHECK_INTERVAL", "0s")
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+	restoreInterval()
+
+	restoreRole := setEnv(t, "ROLE", "controller")
+	defer restoreRole()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a role without broker checks")
+	}
+}
+
+func TestInitialize_ServerProperties(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "REPLICA_COUNT", "3"),
+		setEnv(t, "DISK_USAGE_PATHS", "/var/lib/kafka/data"),
+		setEnv(t, "TLS_ENABLED", "false"),
+		setEnv(t, "SASL_ENABLED", "true"),
+		setEnv(t, "SERVER_PROPERTIES_FILE", filepath.Join(t.TempDir(), "server.properties")),
+		unsetEnv(t, "CPLN_LOCATION"),
+		unsetEnv(t, "LOCATIONS"),
+		unsetEnv(t, "BROKER_ID"),
+		unsetEnv(t, "BOOTSTRAP_SERVERS"),
+		unsetEnv(t, "KRAFT_CONTROLLER_WORKLOAD"),
+		unsetEnv(t, "LOG_DIRS"),
+		unsetEnv(t, "SERVER_PROPERTIES_TEMPLATE"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	rendered, err := Config.ServerProperties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := serverprops.Parse(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"node.id":                        "1",
+		"process.roles":                  "broker,controller",
+		"listeners":                      "INTERNAL://:9092,CONTROLLER://:9093",
+		"listener.security.protocol.map": "INTERNAL:SASL_PLAINTEXT,CONTROLLER:PLAINTEXT",
+		"advertised.listeners":           "INTERNAL://kafka-1.kafka.abc123xyz.svc.cluster.local:9092",
+		"log.dirs":                       "/var/lib/kafka/data",
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("expected %s=%s, got %q", key, value, props[key])
+		}
+	}
+
+	// A custom template that renders an invalid configuration fails startup
+	template := filepath.Join(t.TempDir(), "server.properties.tmpl")
+	if err := os.WriteFile(template, []byte("node.id={{.NodeID}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	restore := setEnv(t, "SERVER_PROPERTIES_TEMPLATE", template)
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for an incomplete server.properties")
+	}
+}
+
 func TestInitialize_WithExplicitBrokerID(t *testing.T) {
 	logger := testLogger()
 
@@ -161,6 +604,291 @@ func TestInitialize_WithExplicitBrokerID(t *testing.T) {
 	}
 }
 
+func TestInitialize_StorageInit(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "STORAGE_INIT_ENABLED", "true"),
+		setEnv(t, "LOG_DIRS", "/var/lib/kafka/data"),
+		unsetEnv(t, "CLUSTER_ID"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for STORAGE_INIT_ENABLED without CLUSTER_ID")
+	}
+
+	restore := setEnv(t, "CLUSTER_ID", "not-a-uuid")
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for an invalid CLUSTER_ID")
+	}
+
+	restoreID := setEnv(t, "CLUSTER_ID", "MkU3OEVBNTcwNTJENDM2Qg")
+	defer restoreID()
+	if err := Initialize(logger); err != nil {
+		t.Errorf("Initialize failed: %v", err)
+	}
+}
+
+func TestInitialize_ClusterIDFile(t *testing.T) {
+	logger := testLogger()
+
+	file := filepath.Join(t.TempDir(), "cluster-id")
+	if err := os.WriteFile(file, []byte("AAAAAAAAAAAAAAAAAAAAAA\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "CLUSTER_ID_FILE", file),
+		unsetEnv(t, "CLUSTER_ID"),
+		unsetEnv(t, "STORAGE_INIT_ENABLED"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// The stored cluster ID is not the configured one
+	restore := setEnv(t, "CLUSTER_ID", "MkU3OEVBNTcwNTJENDM2Qg")
+	defer restore()
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a cluster ID file of another cluster")
+	}
+}
+
+func TestInitialize_DNSPreflight(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "DNS_PREFLIGHT_ENABLED", "true"),
+		setEnv(t, "DNS_PREFLIGHT_INTERVAL", "0s"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Error("expected an error for a zero DNS_PREFLIGHT_INTERVAL")
+	}
+
+	restore := setEnv(t, "DNS_PREFLIGHT_INTERVAL", "5s")
+	defer restore()
+	if err := Initialize(logger); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if Config.DNSPreflightTimeout != 10*time.Minute {
+		t.Errorf("expected the default DNS_PREFLIGHT_TIMEOUT, got %v", Config.DNSPreflightTimeout)
+	}
+}
+
+func TestInitialize_InvalidURPTopicPattern(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "URP_EXCLUDE_TOPICS", "scratch-.*,test-(["),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Fatal("expected an error for an invalid URP_EXCLUDE_TOPICS pattern")
+	}
+}
+
+func TestInitialize_InvalidFaultInjection(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "FAULT_INJECTION", "urp=5,disk-full"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Fatal("expected an error for an unknown FAULT_INJECTION fault")
+	}
+}
+
+func TestInitialize_InvalidAuditLogPath(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "AUDIT_LOG_PATH", "audit/audit.log"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Fatal("expected an error for a relative AUDIT_LOG_PATH")
+	}
+}
+
+func TestInitialize_Alerting(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		expectErr bool
+	}{
+		{name: "slack", env: map[string]string{"ALERT_SLACK_WEBHOOK_URL": "https://hooks.slack.com/services/T0/B0/x"}},
+		{name: "pagerduty", env: map[string]string{"ALERT_PAGERDUTY_ROUTING_KEY": "key"}},
+		{name: "invalid slack url", env: map[string]string{"ALERT_SLACK_WEBHOOK_URL": "hooks.slack.com/services"}, expectErr: true},
+		{name: "invalid disk ratio", env: map[string]string{"ALERT_PAGERDUTY_ROUTING_KEY": "key", "ALERT_DISK_USAGE_RATIO": "1.5"}, expectErr: true},
+		{name: "invalid template", env: map[string]string{"ALERT_PAGERDUTY_ROUTING_KEY": "key", "ALERT_TEMPLATE": "{{.Broker}}"}, expectErr: true},
+		// Nothing is validated without a sink
+		{name: "disabled", env: map[string]string{"ALERT_TEMPLATE": "{{.Broker}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanups := []func(){
+				setEnv(t, "BROKER_ID", "0"),
+				setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+			}
+			for key, value := range tt.env {
+				cleanups = append(cleanups, setEnv(t, key, value))
+			}
+			defer func() {
+				for _, cleanup := range cleanups {
+					cleanup()
+				}
+			}()
+
+			err := Initialize(testLogger())
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestInitialize_Backup(t *testing.T) {
+	credentials := map[string]string{
+		"BACKUP_BUCKET":            "backups",
+		"BACKUP_ACCESS_KEY_ID":     "AKID",
+		"BACKUP_SECRET_ACCESS_KEY": "secret",
+	}
+	with := func(extra map[string]string) map[string]string {
+		env := map[string]string{}
+		for key, value := range credentials {
+			env[key] = value
+		}
+		for key, value := range extra {
+			env[key] = value
+		}
+		return env
+	}
+	tests := []struct {
+		name      string
+		env       map[string]string
+		expectErr bool
+	}{
+		{name: "aws", env: credentials},
+		{name: "gcs", env: with(map[string]string{"BACKUP_ENDPOINT": "https://storage.googleapis.com", "BACKUP_REGION": "auto"})},
+		{name: "invalid endpoint", env: with(map[string]string{"BACKUP_ENDPOINT": "storage.googleapis.com"}), expectErr: true},
+		{name: "no credentials", env: map[string]string{"BACKUP_BUCKET": "backups"}, expectErr: true},
+		{name: "invalid interval", env: with(map[string]string{"BACKUP_INTERVAL": "0s"}), expectErr: true},
+		// Nothing is validated without a bucket
+		{name: "disabled", env: map[string]string{"BACKUP_INTERVAL": "0s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanups := []func(){
+				setEnv(t, "BROKER_ID", "0"),
+				setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+			}
+			for key, value := range tt.env {
+				cleanups = append(cleanups, setEnv(t, key, value))
+			}
+			defer func() {
+				for _, cleanup := range cleanups {
+					cleanup()
+				}
+			}()
+
+			err := Initialize(testLogger())
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestInitialize_InvalidUpstreamMetricsURL(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "UPSTREAM_METRICS_URL", "localhost:7071/metrics"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Fatal("expected an error for an UPSTREAM_METRICS_URL without a scheme")
+	}
+}
+
+func TestInitialize_InvalidKafkaRetry(t *testing.T) {
+	logger := testLogger()
+
+	cleanups := []func(){
+		setEnv(t, "BROKER_ID", "0"),
+		setEnv(t, "BOOTSTRAP_SERVERS", "localhost:9092"),
+		setEnv(t, "KAFKA_RETRY_BASE_DELAY", "1s"),
+		setEnv(t, "KAFKA_RETRY_MAX_DELAY", "500ms"),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(logger); err == nil {
+		t.Fatal("expected an error for a KAFKA_RETRY_MAX_DELAY below KAFKA_RETRY_BASE_DELAY")
+	}
+}
+
 func TestInitialize_WithExplicitBootstrapServers(t *testing.T) {
 	logger := testLogger()
 
@@ -270,3 +998,294 @@ func TestConfigSchema_Tags(t *testing.T) {
 	_ = cfg.Port
 	_ = cfg.LogLevel
 }
+
+func TestValidateStaleAfter(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ConfigSchema
+		profile     Profile
+		expectError bool
+	}{
+		{
+			name: "no background loops",
+			cfg:  ConfigSchema{CheckStaleAfter: time.Minute},
+		},
+		{
+			name: "disabled",
+			cfg:  ConfigSchema{QuotaRecommenderEnabled: true, QuotaSampleInterval: time.Hour},
+		},
+		{
+			name: "longer than loop intervals",
+			cfg: ConfigSchema{
+				CheckStaleAfter:         5 * time.Minute,
+				QuotaRecommenderEnabled: true,
+				QuotaSampleInterval:     30 * time.Second,
+				StandbyCheckInterval:    30 * time.Second,
+			},
+			profile: RoleStandby.Profile(),
+		},
+		{
+			name: "shorter than standby interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:      time.Minute,
+				StandbyCheckInterval: 2 * time.Minute,
+			},
+			profile:     RoleStandby.Profile(),
+			expectError: true,
+		},
+		{
+			name: "shorter than bootstrap refresh interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:          time.Minute,
+				BootstrapRefreshInterval: 2 * time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "bootstrap servers set explicitly",
+			cfg: ConfigSchema{
+				CheckStaleAfter:          time.Minute,
+				BootstrapServers:         "kafka:9092",
+				BootstrapRefreshInterval: 2 * time.Minute,
+			},
+		},
+		{
+			name: "shorter than canary interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter: time.Minute,
+				CanaryEnabled:   true,
+				CanaryInterval:  2 * time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than request latency interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:        time.Minute,
+				RequestLatencyEnabled:  true,
+				RequestLatencyInterval: time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than TLS handshake interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:       time.Minute,
+				TLSHandshakeListeners: "internal=localhost:9093",
+				TLSHandshakeInterval:  2 * time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than TLS expiry interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:        time.Minute,
+				TLSEnabled:             true,
+				TLSExpiryCheckInterval: time.Hour,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than SCRAM interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:      time.Minute,
+				SCRAMCredentialsFile: "/etc/kafka/scram.json",
+				SCRAMCheckInterval:   time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than maintenance score interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:          time.Minute,
+				MaintenanceScoreEnabled:  true,
+				MaintenanceScoreInterval: 5 * time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than partition size interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:             time.Minute,
+				PartitionSizeMetricsEnabled: true,
+				PartitionSizeInterval:       5 * time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			name: "shorter than KRaft metadata log interval",
+			cfg: ConfigSchema{
+				CheckStaleAfter:          time.Minute,
+				KRaftMetadataLogDir:      "/var/lib/kafka/metadata",
+				KRaftMetadataLogInterval: 5 * time.Minute,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStaleAfter(&tt.cfg, tt.profile)
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateServerTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ConfigSchema
+		expectError bool
+	}{
+		{name: "disabled", cfg: ConfigSchema{ServerTLSClientAuth: "none"}},
+		{name: "unknown client auth", cfg: ConfigSchema{ServerTLSClientAuth: "maybe"}, expectError: true},
+		{name: "key without certificate", cfg: ConfigSchema{ServerTLSClientAuth: "none", ServerTLSKeyFile: "/tls/tls.key"}, expectError: true},
+		{name: "client auth without TLS", cfg: ConfigSchema{ServerTLSClientAuth: "require"}, expectError: true},
+		{name: "admin client certs without TLS", cfg: ConfigSchema{ServerTLSClientAuth: "none", AdminClientCertRequired: true}, expectError: true},
+		{name: "admin client auth without TLS", cfg: ConfigSchema{ServerTLSClientAuth: "none", AdminTLSClientAuth: "require"}, expectError: true},
+		{name: "unknown admin client auth", cfg: ConfigSchema{ServerTLSClientAuth: "none", AdminTLSClientAuth: "maybe"}, expectError: true},
+		{
+			name: "admin client certs without client auth",
+			cfg: ConfigSchema{
+				ServerTLSCertFile:       "/tls/tls.crt",
+				ServerTLSKeyFile:        "/tls/tls.key",
+				ServerTLSClientAuth:     "none",
+				AdminClientCertRequired: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "admin client auth without CA",
+			cfg: ConfigSchema{
+				ServerTLSCertFile:   "/tls/tls.crt",
+				ServerTLSKeyFile:    "/tls/tls.key",
+				ServerTLSClientAuth: "none",
+				AdminTLSClientAuth:  "require",
+			},
+			expectError: true,
+		},
+		{
+			name: "client auth without CA",
+			cfg: ConfigSchema{
+				ServerTLSCertFile:   "/tls/tls.crt",
+				ServerTLSKeyFile:    "/tls/tls.key",
+				ServerTLSClientAuth: "optional",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing certificate",
+			cfg: ConfigSchema{
+				ServerTLSCertFile:   "/nonexistent/tls.crt",
+				ServerTLSKeyFile:    "/nonexistent/tls.key",
+				ServerTLSClientAuth: "none",
+			},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateServerTLS(&tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateListeners(t *testing.T) {
+	valid := func() ConfigSchema {
+		return ConfigSchema{
+			Port:              8080,
+			HTTPReadTimeout:   30 * time.Second,
+			HTTPWriteTimeout:  30 * time.Second,
+			AdminReadTimeout:  30 * time.Second,
+			AdminWriteTimeout: 2 * time.Minute,
+		}
+	}
+	tests := []struct {
+		name        string
+		modify      func(*ConfigSchema)
+		expectError bool
+	}{
+		{name: "single port", modify: func(*ConfigSchema) {}},
+		{name: "admin port", modify: func(c *ConfigSchema) { c.AdminPort = 8443; c.AdminTLSClientAuth = "require" }},
+		{name: "admin port equals port", modify: func(c *ConfigSchema) { c.AdminPort = 8080 }, expectError: true},
+		{name: "negative admin port", modify: func(c *ConfigSchema) { c.AdminPort = -1 }, expectError: true},
+		{name: "admin client auth without admin port", modify: func(c *ConfigSchema) { c.AdminTLSClientAuth = "require" }, expectError: true},
+		{name: "zero write timeout", modify: func(c *ConfigSchema) { c.HTTPWriteTimeout = 0 }, expectError: true},
+		{name: "zero admin read timeout", modify: func(c *ConfigSchema) { c.AdminPort = 8443; c.AdminReadTimeout = 0 }, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := validateListeners(&cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	valid := func() ConfigSchema {
+		return ConfigSchema{
+			SASLEnabled:           true,
+			SecretProvider:        "vault",
+			SecretSASLPasswordRef: "kafka/sidecar#password",
+			SecretRefreshInterval: 5 * time.Minute,
+			SecretTimeout:         10 * time.Second,
+			VaultAddr:             "https://vault:8200",
+			VaultToken:            "token",
+			VaultKVMount:          "secret",
+		}
+	}
+	tests := []struct {
+		name        string
+		modify      func(*ConfigSchema)
+		expectError bool
+	}{
+		{name: "vault", modify: func(*ConfigSchema) {}},
+		{name: "disabled", modify: func(c *ConfigSchema) { *c = ConfigSchema{} }},
+		{name: "reference without provider", modify: func(c *ConfigSchema) { c.SecretProvider = "" }, expectError: true},
+		{name: "provider without reference", modify: func(c *ConfigSchema) { c.SecretSASLPasswordRef = "" }, expectError: true},
+		{name: "unknown provider", modify: func(c *ConfigSchema) { c.SecretProvider = "keychain" }, expectError: true},
+		{name: "vault without token", modify: func(c *ConfigSchema) { c.VaultToken = "" }, expectError: true},
+		{name: "sasl disabled", modify: func(c *ConfigSchema) { c.SASLEnabled = false }, expectError: true},
+		{name: "certificate without key", modify: func(c *ConfigSchema) {
+			c.TLSEnabled, c.TLSCertFile, c.TLSKeyFile = true, "/tls/client.crt", "/tls/client.key"
+			c.SecretTLSCertRef = "kafka/client#cert"
+		}, expectError: true},
+		{name: "certificate without files", modify: func(c *ConfigSchema) {
+			c.TLSEnabled = true
+			c.SecretTLSCertRef, c.SecretTLSKeyRef = "kafka/client#cert", "kafka/client#key"
+		}, expectError: true},
+		{name: "certificate", modify: func(c *ConfigSchema) {
+			c.TLSEnabled, c.TLSCertFile, c.TLSKeyFile = true, "/tls/client.crt", "/tls/client.key"
+			c.SecretTLSCertRef, c.SecretTLSKeyRef = "kafka/client#cert", "kafka/client#key"
+		}},
+		{name: "aws", modify: func(c *ConfigSchema) {
+			c.SecretProvider, c.AWSRegion, c.AWSAccessKeyID, c.AWSSecretAccessKey = "aws", "eu-west-1", "AKID", "secret"
+		}},
+		{name: "aws without region", modify: func(c *ConfigSchema) {
+			c.SecretProvider, c.AWSAccessKeyID, c.AWSSecretAccessKey = "aws", "AKID", "secret"
+		}, expectError: true},
+		{name: "gcp without project", modify: func(c *ConfigSchema) { c.SecretProvider = "gcp" }, expectError: true},
+		{name: "gcp with full name", modify: func(c *ConfigSchema) {
+			c.SecretProvider, c.SecretSASLPasswordRef = "gcp", "projects/prod/secrets/kafka-password"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := validateSecrets(&cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Source tells where the effective value of a setting came from
+type Source string
+
+const (
+	// SourceEnv is a value read from the setting's environment variable
+	SourceEnv Source = "env"
+	// SourceFile is a value read from the config file (CONFIG_FILE)
+	SourceFile Source = "file"
+	// SourceSecretFile is a secret read from the file named by <ENV>_FILE
+	SourceSecretFile Source = "secret-file"
+	// SourceDefault is the schema default, used when the variable is unset
+	SourceDefault Source = "default"
+	// SourceDiscovered is a value derived at startup, from the hostname,
+	// the Control Plane environment or the role
+	SourceDiscovered Source = "discovered"
+)
+
+// RedactedValue replaces the value of a sensitive setting that is set
+const RedactedValue = "[REDACTED]"
+
+// Setting is the effective value of one configuration field
+type Setting struct {
+	Env      string `json:"env"`
+	Field    string `json:"field"`
+	Value    any    `json:"value"`
+	Source   Source `json:"source"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// Discovered reports whether Initialize derived the field of Config instead of
+// reading it
+func Discovered(field string) bool {
+	return Config != nil && Config.Discovered(field)
+}
+
+// Discovered reports whether Load derived the field instead of reading it
+func (c *ConfigSchema) Discovered(field string) bool {
+	return c.discovered[field]
+}
+
+// Settings returns every field of the schema with its effective value and
+// source. Fields tagged sensitive are masked when set, and passwords in URLs
+// are masked wherever they appear.
+func (c *ConfigSchema) Settings() []Setting {
+	environment := c.environment()
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		env, sensitive := parseTag(field.Tag.Get("cpln"))
+		if env == "" {
+			continue
+		}
+
+		s := Setting{Env: env, Field: field.Name, Source: SourceDefault}
+		if c.discovered[field.Name] {
+			s.Source = SourceDiscovered
+		} else if fromSecretFile(environment, field) {
+			s.Source = SourceSecretFile
+		} else if _, ok := c.fileEnv[env]; ok {
+			s.Source = SourceFile
+		} else if _, ok := environment(env); ok {
+			s.Source = SourceEnv
+		}
+
+		value := v.Field(i).Interface()
+		switch typed := value.(type) {
+		case time.Duration:
+			value = typed.String()
+		case string:
+			if sensitive && typed != "" {
+				value, s.Redacted = RedactedValue, true
+			} else if masked := redactURL(typed); masked != typed {
+				value, s.Redacted = masked, true
+			}
+		}
+		s.Value = value
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+// Summary lists every setting with its value and source, one per line, masked
+// as in Settings. config.Summarize cannot be used on the schema, whose load
+// state is unexported.
+func (c *ConfigSchema) Summary() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, s := range c.Settings() {
+		fmt.Fprintf(tw, "%s\t%v\t(%s)\n", s.Field, s.Value, s.Source)
+	}
+	_ = tw.Flush()
+	return b.String()
+}
+
+// parseTag returns the environment variable and sensitivity of a cpln tag
+func parseTag(tag string) (env string, sensitive bool) {
+	for _, part := range strings.Split(tag, ";") {
+		switch {
+		case strings.HasPrefix(part, "env:"):
+			env = strings.TrimPrefix(part, "env:")
+		case part == "sensitive":
+			sensitive = true
+		}
+	}
+	return env, sensitive
+}
+
+// redactURL masks the password of a URL with user info and returns any other
+// value unchanged
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+	return u.Redacted()
+}
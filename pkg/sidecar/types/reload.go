@@ -0,0 +1,138 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// reloadable are the fields Reload applies to the running sidecar: the log
+// level, the health check timeouts, thresholds and injected faults, and the
+// SASL credentials.
+// Changes to any other field take effect on the next restart.
+var reloadable = map[string]bool{
+	"LogLevel":         true,
+	"CheckTimeout":     true,
+	"CheckTimeoutMax":  true,
+	"FDMinFreeRatio":   true,
+	"FormationGrace":   true,
+	"URPIncludeTopics": true,
+	"URPExcludeTopics": true,
+	"FaultInjection":   true,
+	"SASLUsername":     true,
+	"SASLPassword":     true,
+}
+
+// ReloadResult lists the settings whose value a reload changed, by
+// environment variable
+type ReloadResult struct {
+	// Applied were changed in the running sidecar
+	Applied []string `json:"applied"`
+	// RestartRequired changed but only take effect on the next restart
+	RestartRequired []string `json:"restartRequired,omitempty"`
+}
+
+// reloadMu serializes reloads
+var reloadMu sync.Mutex
+
+// Reload parses and validates the configuration again, from the environment
+// Config was loaded from, and replaces Config with a copy carrying the new
+// values of the reloadable settings. Config is replaced rather than modified,
+// so a reader holding the previous one keeps seeing consistent values. When
+// the configuration is invalid, Config is left unchanged.
+func Reload(logger *slog.Logger) (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, err := Load(Config.env, logger)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+
+	next := *Config
+	next.fileEnv = cfg.fileEnv
+	result := ReloadResult{Applied: []string{}}
+	current := reflect.ValueOf(Config).Elem()
+	loaded := reflect.ValueOf(cfg).Elem()
+	target := reflect.ValueOf(&next).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		env, _ := parseTag(field.Tag.Get("cpln"))
+		if env == "" || reflect.DeepEqual(current.Field(i).Interface(), loaded.Field(i).Interface()) {
+			continue
+		}
+		if reloadable[field.Name] {
+			target.Field(i).Set(loaded.Field(i))
+			result.Applied = append(result.Applied, env)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, env)
+		}
+	}
+	Config = &next
+	return result, nil
+}
+
+// readConfigFile parses KEY=VALUE lines. Blank lines and lines starting with #
+// are skipped, an "export " prefix is allowed, and values may be quoted. Keys
+// must be variables of the schema, so that a typo is an error rather than a
+// setting silently left at its default.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	known := schemaEnv()
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("%s:%d: unknown setting %s", path, n, key)
+		}
+		if key == "CONFIG_FILE" {
+			return nil, fmt.Errorf("%s:%d: CONFIG_FILE cannot be set from the config file", path, n)
+		}
+		values[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// unquote removes matching single or double quotes around a value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// schemaEnv returns the environment variables of the schema, including the
+// <ENV>_FILE variables of secrets
+func schemaEnv() map[string]bool {
+	t := reflect.TypeOf(ConfigSchema{})
+	known := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if env, _ := parseTag(t.Field(i).Tag.Get("cpln")); env != "" {
+			known[env] = true
+		}
+		if env, ok := fileSetting(t.Field(i)); ok {
+			known[env+FileSuffix] = true
+		}
+	}
+	return known
+}
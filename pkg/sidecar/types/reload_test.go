@@ -0,0 +1,154 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// writeConfigFile replaces the content of a config file
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sidecar.env")
+	writeConfigFile(t, path, `
+# Reloadable settings
+LOG_LEVEL=debug
+export CHECK_TIMEOUT = 5s
+SASL_PASSWORD="p=ss word"
+URP_EXCLUDE_TOPICS=''
+`)
+	values, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"LOG_LEVEL":          "debug",
+		"CHECK_TIMEOUT":      "5s",
+		"SASL_PASSWORD":      "p=ss word",
+		"URP_EXCLUDE_TOPICS": "",
+	}
+	if len(values) != len(want) {
+		t.Fatalf("expected %v, got %v", want, values)
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("expected %s=%q, got %q", key, value, values[key])
+		}
+	}
+
+	for name, content := range map[string]string{
+		"missing equals": "LOG_LEVEL\n",
+		"unknown key":    "LOG_LEVL=debug\n",
+		"nested file":    "CONFIG_FILE=/etc/other.env\n",
+	} {
+		writeConfigFile(t, path, content)
+		if _, err := readConfigFile(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sidecar.env")
+	env := discovery.MapEnv(map[string]string{
+		"HOSTNAME":       "kafka-1",
+		"CPLN_WORKLOAD":  "/org/test/gvc/test/workload/kafka",
+		"CPLN_GVC_ALIAS": "abc123xyz",
+		"CONFIG_FILE":    path,
+		"LOG_LEVEL":      "warn",
+	})
+
+	writeConfigFile(t, path, "LOG_LEVEL=debug\nCHECK_TIMEOUT=5s\n")
+	cfg, err := Load(env, testLogger())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.LogLevel != "debug" || cfg.CheckTimeout.String() != "5s" {
+		t.Fatalf("expected the file to override the environment, got LOG_LEVEL=%s CHECK_TIMEOUT=%s",
+			cfg.LogLevel, cfg.CheckTimeout)
+	}
+	if s := settingsByEnv(cfg)["LOG_LEVEL"]; s.Source != SourceFile {
+		t.Errorf("expected LOG_LEVEL to be reported as set from the file, got %s", s.Source)
+	}
+	if _, ok := os.LookupEnv("CHECK_TIMEOUT"); ok || Config == cfg {
+		t.Error("expected the process environment and Config to be left alone")
+	}
+	if !cfg.Discovered("BrokerID") || cfg.BrokerID != 1 {
+		t.Errorf("expected broker ID 1 discovered from the HOSTNAME of env, got %d", cfg.BrokerID)
+	}
+
+	// Settings dropped from the file fall back to the environment
+	writeConfigFile(t, path, "CHECK_TIMEOUT=7s\n")
+	if cfg, err = Load(env, testLogger()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("expected LOG_LEVEL to fall back to warn, got %s", cfg.LogLevel)
+	}
+	if s := settingsByEnv(cfg)["LOG_LEVEL"]; s.Source != SourceEnv {
+		t.Errorf("expected LOG_LEVEL to be reported as set from the environment, got %s", s.Source)
+	}
+	if cfg.CheckTimeout.String() != "7s" {
+		t.Errorf("expected CHECK_TIMEOUT=7s, got %s", cfg.CheckTimeout)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sidecar.env")
+	writeConfigFile(t, path, "LOG_LEVEL=info\n")
+	cleanups := []func(){
+		setEnv(t, "HOSTNAME", "kafka-1"),
+		setEnv(t, "CPLN_WORKLOAD", "/org/test/gvc/test/workload/kafka"),
+		setEnv(t, "CPLN_GVC_ALIAS", "abc123xyz"),
+		setEnv(t, "CONFIG_FILE", path),
+	}
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	if err := Initialize(testLogger()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	previous := Config
+
+	writeConfigFile(t, path, "LOG_LEVEL=debug\nCHECK_TIMEOUT=3s\nPROBE_MAX_CONCURRENCY=8\n")
+	result, err := Reload(testLogger())
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !slices.Equal(result.Applied, []string{"CHECK_TIMEOUT", "LOG_LEVEL"}) {
+		t.Errorf("expected LOG_LEVEL and CHECK_TIMEOUT to be applied, got %v", result.Applied)
+	}
+	if !slices.Equal(result.RestartRequired, []string{"PROBE_MAX_CONCURRENCY"}) {
+		t.Errorf("expected PROBE_MAX_CONCURRENCY to require a restart, got %v", result.RestartRequired)
+	}
+	if Config.LogLevel != "debug" || Config.CheckTimeout.String() != "3s" {
+		t.Errorf("expected the reloaded values, got LOG_LEVEL=%s CHECK_TIMEOUT=%s", Config.LogLevel, Config.CheckTimeout)
+	}
+	if Config.ProbeMaxConcurrency != previous.ProbeMaxConcurrency {
+		t.Errorf("expected PROBE_MAX_CONCURRENCY to keep %d until a restart, got %d", previous.ProbeMaxConcurrency, Config.ProbeMaxConcurrency)
+	}
+	if previous.LogLevel != "info" {
+		t.Errorf("expected the previous Config to be left unchanged, got LOG_LEVEL=%s", previous.LogLevel)
+	}
+
+	// An invalid configuration leaves Config unchanged
+	writeConfigFile(t, path, "LOG_LEVEL=loud\n")
+	if _, err := Reload(testLogger()); err == nil {
+		t.Fatal("expected an error for an invalid LOG_LEVEL")
+	}
+	if Config.LogLevel != "debug" {
+		t.Errorf("expected LOG_LEVEL to stay debug, got %s", Config.LogLevel)
+	}
+}
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is the kind of node the sidecar runs alongside
+type Role string
+
+const (
+	RoleBroker      Role = "broker"
+	RoleController  Role = "controller"
+	RoleMirrorMaker Role = "mirrormaker"
+	RoleConnect     Role = "connect"
+	RoleStandby     Role = "standby"
+)
+
+// Profile lists the subsystems the sidecar starts for a role and the role's defaults
+type Profile struct {
+	// BrokerChecks runs the broker-specific health checks (registration, ISR, log dirs)
+	// and allows request latency probes, peer reachability checks and partition size
+	// metrics of the local broker. Without them liveness
+	// only reports the sidecar itself and readiness checks that the cluster is
+	// reachable with an elected controller.
+	BrokerChecks bool
+	// Metrics serves /metrics (cgroup and process metrics)
+	Metrics bool
+	// BrokerWorkflows allows workflows acting on the local broker (onboarding,
+	// quota recommendations from the broker's MBeans, post-restart verification,
+	// the produce/consume canary)
+	BrokerWorkflows bool
+	// AdminAPIs allows cluster administration (decommission, replication factor
+	// changes, SCRAM credentials, config drift, consumer offsets export, topic catalog,
+	// maintenance safety score)
+	AdminAPIs bool
+	// MetadataLog allows inspecting the node's KRaft metadata log, which every
+	// KRaft broker and controller keeps on disk
+	MetadataLog bool
+	// Standby keeps the broker out of partition leadership until it is promoted
+	Standby bool
+	// ProcessMatch is the default command-line substring of the node's JVM
+	ProcessMatch string
+}
+
+// profiles holds the profile of every supported role
+var profiles = map[Role]Profile{
+	RoleBroker: {
+		BrokerChecks:    true,
+		Metrics:         true,
+		BrokerWorkflows: true,
+		AdminAPIs:       true,
+		MetadataLog:     true,
+		ProcessMatch:    "kafka.Kafka",
+	},
+	RoleController: {
+		Metrics:      true,
+		AdminAPIs:    true,
+		MetadataLog:  true,
+		ProcessMatch: "kafka.Kafka",
+	},
+	RoleStandby: {
+		BrokerChecks: true,
+		Metrics:      true,
+		MetadataLog:  true,
+		Standby:      true,
+		ProcessMatch: "kafka.Kafka",
+	},
+	RoleMirrorMaker: {
+		Metrics:      true,
+		ProcessMatch: "org.apache.kafka.connect.mirror.MirrorMaker",
+	},
+	RoleConnect: {
+		Metrics:      true,
+		ProcessMatch: "org.apache.kafka.connect.cli.ConnectDistributed",
+	},
+}
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := profiles[role]; !ok {
+		return "", fmt.Errorf("unsupported ROLE: %s (supported: broker, controller, standby, mirrormaker, connect)", s)
+	}
+	return role, nil
+}
+
+// Profile returns the role's profile. Unknown roles get the broker profile.
+func (r Role) Profile() Profile {
+	if p, ok := profiles[r]; ok {
+		return p
+	}
+	return profiles[RoleBroker]
+}
+
+// validateRole rejects features the configured role does not support
+func validateRole(cfg *ConfigSchema, profile Profile) error {
+	var unsupported []string
+	if !profile.BrokerChecks {
+		if cfg.RequestLatencyEnabled {
+			unsupported = append(unsupported, "REQUEST_LATENCY_ENABLED")
+		}
+		if cfg.PeerCheckEnabled {
+			unsupported = append(unsupported, "PEER_CHECK_ENABLED")
+		}
+		if cfg.ConnectivityDiagnosticsEnabled {
+			unsupported = append(unsupported, "CONNECTIVITY_DIAGNOSTICS_ENABLED")
+		}
+		if cfg.InterBrokerTLSCheckEnabled {
+			unsupported = append(unsupported, "INTER_BROKER_TLS_CHECK_ENABLED")
+		}
+		if cfg.PartitionSizeMetricsEnabled {
+			unsupported = append(unsupported, "PARTITION_SIZE_METRICS_ENABLED")
+		}
+		if cfg.BrokerIDConflictCheckEnabled {
+			unsupported = append(unsupported, "BROKER_ID_CONFLICT_CHECK_ENABLED")
+		}
+		if cfg.BrokerEpochCheckEnabled {
+			unsupported = append(unsupported, "BROKER_EPOCH_CHECK_ENABLED")
+		}
+	}
+	if !profile.BrokerWorkflows {
+		if cfg.OnboardingEnabled {
+			unsupported = append(unsupported, "ONBOARDING_ENABLED")
+		}
+		if cfg.QuotaRecommenderEnabled {
+			unsupported = append(unsupported, "QUOTA_RECOMMENDER_ENABLED")
+		}
+		if cfg.VerificationEnabled {
+			unsupported = append(unsupported, "VERIFICATION_ENABLED")
+		}
+		if cfg.CanaryEnabled {
+			unsupported = append(unsupported, "CANARY_ENABLED")
+		}
+	}
+	if !profile.AdminAPIs {
+		if cfg.DecommissionEnabled {
+			unsupported = append(unsupported, "DECOMMISSION_ENABLED")
+		}
+		if cfg.ReplicationFactorEnabled {
+			unsupported = append(unsupported, "REPLICATION_FACTOR_ENABLED")
+		}
+		if cfg.SCRAMCredentialsFile != "" {
+			unsupported = append(unsupported, "SCRAM_CREDENTIALS_FILE")
+		}
+		if cfg.ConfigDriftSpecFile != "" {
+			unsupported = append(unsupported, "CONFIG_DRIFT_SPEC_FILE")
+		}
+		if cfg.OffsetsExportEnabled {
+			unsupported = append(unsupported, "OFFSETS_EXPORT_ENABLED")
+		}
+		if cfg.OffsetsResetEnabled {
+			unsupported = append(unsupported, "OFFSETS_RESET_ENABLED")
+		}
+		if cfg.CatalogEnabled {
+			unsupported = append(unsupported, "CATALOG_ENABLED")
+		}
+		if cfg.MaintenanceScoreEnabled {
+			unsupported = append(unsupported, "MAINTENANCE_SCORE_ENABLED")
+		}
+	}
+
+	if !profile.MetadataLog && cfg.KRaftMetadataLogDir != "" {
+		unsupported = append(unsupported, "KRAFT_METADATA_LOG_DIR")
+	}
+	if !profile.MetadataLog && cfg.StorageInitEnabled {
+		unsupported = append(unsupported, "STORAGE_INIT_ENABLED")
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("ROLE %s does not support %s", cfg.Role, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
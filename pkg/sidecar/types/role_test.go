@@ -0,0 +1,169 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Role
+		expectError bool
+	}{
+		{input: "broker", expected: RoleBroker},
+		{input: "controller", expected: RoleController},
+		{input: " MirrorMaker ", expected: RoleMirrorMaker},
+		{input: "CONNECT", expected: RoleConnect},
+		{input: "standby", expected: RoleStandby},
+		{input: "zookeeper", expectError: true},
+		{input: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			role, err := ParseRole(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error for %q, got role %q", tt.input, role)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if role != tt.expected {
+				t.Errorf("expected role %q, got %q", tt.expected, role)
+			}
+		})
+	}
+}
+
+func TestRoleProfile(t *testing.T) {
+	broker := RoleBroker.Profile()
+	if !broker.BrokerChecks || !broker.Metrics || !broker.BrokerWorkflows || !broker.AdminAPIs {
+		t.Errorf("expected broker to enable every subsystem, got %+v", broker)
+	}
+
+	controller := RoleController.Profile()
+	if controller.BrokerChecks || controller.BrokerWorkflows {
+		t.Errorf("expected controller without broker checks or workflows, got %+v", controller)
+	}
+	if !controller.AdminAPIs {
+		t.Error("expected controller to allow admin APIs")
+	}
+
+	for _, role := range []Role{RoleMirrorMaker, RoleConnect} {
+		p := role.Profile()
+		if p.BrokerChecks || p.BrokerWorkflows || p.AdminAPIs {
+			t.Errorf("expected %s to run metrics only, got %+v", role, p)
+		}
+		if p.ProcessMatch == "kafka.Kafka" {
+			t.Errorf("expected %s to match its own JVM, got %q", role, p.ProcessMatch)
+		}
+	}
+
+	standby := RoleStandby.Profile()
+	if !standby.Standby || !standby.BrokerChecks || standby.BrokerWorkflows {
+		t.Errorf("expected standby to run broker checks without broker workflows, got %+v", standby)
+	}
+
+	if Role("unknown").Profile() != broker {
+		t.Error("expected unknown role to fall back to the broker profile")
+	}
+}
+
+func TestValidateRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ConfigSchema
+		expectError string
+	}{
+		{
+			name: "broker allows everything",
+			cfg: ConfigSchema{
+				Role:                    "broker",
+				OnboardingEnabled:       true,
+				QuotaRecommenderEnabled: true,
+				DecommissionEnabled:     true,
+			},
+		},
+		{
+			name: "controller allows admin APIs",
+			cfg:  ConfigSchema{Role: "controller", DecommissionEnabled: true, SCRAMCredentialsFile: "/etc/kafka/scram.json"},
+		},
+		{
+			name:        "controller rejects onboarding",
+			cfg:         ConfigSchema{Role: "controller", OnboardingEnabled: true},
+			expectError: "ONBOARDING_ENABLED",
+		},
+		{
+			name: "standby allows request latency",
+			cfg:  ConfigSchema{Role: "standby", RequestLatencyEnabled: true},
+		},
+		{
+			name:        "mirrormaker rejects request latency and peer checks",
+			cfg:         ConfigSchema{Role: "mirrormaker", RequestLatencyEnabled: true, PeerCheckEnabled: true},
+			expectError: "REQUEST_LATENCY_ENABLED, PEER_CHECK_ENABLED",
+		},
+		{
+			name:        "controller rejects partition size metrics",
+			cfg:         ConfigSchema{Role: "controller", PartitionSizeMetricsEnabled: true},
+			expectError: "PARTITION_SIZE_METRICS_ENABLED",
+		},
+		{
+			name:        "standby rejects verification and canary",
+			cfg:         ConfigSchema{Role: "standby", VerificationEnabled: true, CanaryEnabled: true},
+			expectError: "VERIFICATION_ENABLED, CANARY_ENABLED",
+		},
+		{
+			name: "connect rejects admin APIs and workflows",
+			cfg: ConfigSchema{
+				Role:                    "connect",
+				QuotaRecommenderEnabled: true,
+				DecommissionEnabled:     true,
+				OffsetsExportEnabled:    true,
+				OffsetsResetEnabled:     true,
+			},
+			expectError: "QUOTA_RECOMMENDER_ENABLED, DECOMMISSION_ENABLED, OFFSETS_EXPORT_ENABLED, OFFSETS_RESET_ENABLED",
+		},
+		{
+			name:        "mirrormaker rejects admin APIs",
+			cfg:         ConfigSchema{Role: "mirrormaker", ReplicationFactorEnabled: true, SCRAMCredentialsFile: "/etc/kafka/scram.json", CatalogEnabled: true},
+			expectError: "REPLICATION_FACTOR_ENABLED, SCRAM_CREDENTIALS_FILE, CATALOG_ENABLED",
+		},
+		{
+			name:        "standby rejects maintenance score",
+			cfg:         ConfigSchema{Role: "standby", MaintenanceScoreEnabled: true},
+			expectError: "MAINTENANCE_SCORE_ENABLED",
+		},
+		{
+			name: "controller allows maintenance score",
+			cfg:  ConfigSchema{Role: "controller", MaintenanceScoreEnabled: true},
+		},
+		{
+			name:        "connect rejects KRaft metadata log",
+			cfg:         ConfigSchema{Role: "connect", KRaftMetadataLogDir: "/var/lib/kafka/metadata"},
+			expectError: "KRAFT_METADATA_LOG_DIR",
+		},
+		{
+			name: "controller allows KRaft metadata log",
+			cfg:  ConfigSchema{Role: "controller", KRaftMetadataLogDir: "/var/lib/kafka/metadata"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRole(&tt.cfg, tt.cfg.Profile())
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/metadata"
+	"github.com/iancoleman/strcase"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// parseSchema is libs-go's config.ParseSchema reading variables from env rather
+// than the process environment. Every exported field is set from the variable
+// named by the env key of its cpln tag, or its field name in SCREAMING_SNAKE
+// case without one. An unset variable takes the tag's default, or is parsed as
+// the empty value without one; a variable that is set but empty does not take
+// the default.
+func parseSchema(cfg *ConfigSchema, env discovery.Env) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := metadata.ParseCplnTagString(f.Tag.Get("cpln"))
+		name := strcase.ToScreamingSnake(f.Name)
+		if e, ok := tag["env"]; ok {
+			name = e
+		}
+		value, ok := env(name)
+		if !ok {
+			value = tag["default"]
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setField parses value into a field of one of the types libs-go maps, bar
+// regexp.Regexp, which the schema has no use for. As with libs-go, a bool is
+// true for 1 or true in any case, and false otherwise, and a time is RFC 3339.
+func setField(field reflect.Value, value string) error {
+	switch field.Type() {
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		field.SetBool(value == "1" || strings.EqualFold(value, "true"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/config"
+	"github.com/controlplane-com/libs-go/pkg/metadata"
+	"github.com/iancoleman/strcase"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		expectLogLevel  string
+		expectUI        bool
+		expectSASL      bool
+		expectKafkaPort int
+		expectErr       bool
+	}{
+		{name: "unset", env: map[string]string{}, expectLogLevel: "info", expectUI: true, expectKafkaPort: 9092},
+		{
+			name:            "set",
+			env:             map[string]string{"LOG_LEVEL": "debug", "UI_ENABLED": "false", "SASL_ENABLED": "true", "KAFKA_PORT": "9093"},
+			expectLogLevel:  "debug",
+			expectSASL:      true,
+			expectKafkaPort: 9093,
+		},
+		{
+			// A variable set but empty is the empty value, not the default
+			name:            "empty",
+			env:             map[string]string{"LOG_LEVEL": "", "UI_ENABLED": ""},
+			expectKafkaPort: 9092,
+		},
+		{name: "bool one", env: map[string]string{"SASL_ENABLED": "1"}, expectLogLevel: "info", expectUI: true, expectSASL: true, expectKafkaPort: 9092},
+		{name: "bool any case", env: map[string]string{"SASL_ENABLED": "TRUE"}, expectLogLevel: "info", expectUI: true, expectSASL: true, expectKafkaPort: 9092},
+		// Anything other than 1 or true is false
+		{name: "bool other", env: map[string]string{"UI_ENABLED": "yes"}, expectLogLevel: "info", expectKafkaPort: 9092},
+		{name: "invalid int", env: map[string]string{"KAFKA_PORT": "kafka"}, expectErr: true},
+		{name: "empty int", env: map[string]string{"KAFKA_PORT": ""}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg ConfigSchema
+			err := parseSchema(&cfg, discovery.MapEnv(tt.env))
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.LogLevel != tt.expectLogLevel {
+				t.Errorf("expected LogLevel %q, got %q", tt.expectLogLevel, cfg.LogLevel)
+			}
+			if cfg.UIEnabled != tt.expectUI {
+				t.Errorf("expected UIEnabled %v, got %v", tt.expectUI, cfg.UIEnabled)
+			}
+			if cfg.SASLEnabled != tt.expectSASL {
+				t.Errorf("expected SASLEnabled %v, got %v", tt.expectSASL, cfg.SASLEnabled)
+			}
+			if cfg.KafkaPort != tt.expectKafkaPort {
+				t.Errorf("expected KafkaPort %d, got %d", tt.expectKafkaPort, cfg.KafkaPort)
+			}
+		})
+	}
+}
+
+// TestParseSchemaMatchesLibsGo reads every field of the schema with parseSchema
+// and with libs-go's config.ParseSchema, which reads the process environment
+func TestParseSchemaMatchesLibsGo(t *testing.T) {
+	schema := reflect.TypeOf(ConfigSchema{})
+	var fields []reflect.StructField
+	for i := 0; i < schema.NumField(); i++ {
+		if schema.Field(i).IsExported() {
+			fields = append(fields, schema.Field(i))
+		}
+	}
+	envName := func(f reflect.StructField) string {
+		if name, ok := metadata.ParseCplnTagString(f.Tag.Get("cpln"))["env"]; ok {
+			return name
+		}
+		return strcase.ToScreamingSnake(f.Name)
+	}
+	// sample is a valid value for the field, distinct per field so that a
+	// variable read into the wrong field shows
+	sample := func(i int, f reflect.StructField) string {
+		switch {
+		case f.Type == reflect.TypeOf(time.Duration(0)):
+			return fmt.Sprintf("%ds", i+1)
+		case f.Type.Kind() == reflect.Bool:
+			return []string{"TRUE", "1", "false", "yes"}[i%4]
+		case f.Type.Kind() == reflect.String:
+			return "value-" + f.Name
+		case f.Type.Kind() == reflect.Float64:
+			return fmt.Sprintf("%d.5", i)
+		default:
+			return strconv.Itoa(i + 1)
+		}
+	}
+
+	// compare parses env both ways, unsetting the variables it does not hold
+	compare := func(t *testing.T, env map[string]string) {
+		t.Helper()
+		for _, f := range fields {
+			name := envName(f)
+			value, ok := env[name]
+			t.Setenv(name, value)
+			if !ok {
+				_ = os.Unsetenv(name)
+			}
+		}
+		var want, got ConfigSchema
+		wantErr := config.ParseSchema(&want)
+		gotErr := parseSchema(&got, discovery.MapEnv(env))
+		if (gotErr == nil) != (wantErr == nil) {
+			t.Fatalf("expected error %v, got %v", wantErr, gotErr)
+		}
+		if wantErr != nil {
+			return
+		}
+		for _, f := range fields {
+			w := reflect.ValueOf(want).FieldByIndex(f.Index).Interface()
+			g := reflect.ValueOf(got).FieldByIndex(f.Index).Interface()
+			if !reflect.DeepEqual(w, g) {
+				t.Errorf("%s: expected %v, got %v", f.Name, w, g)
+			}
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		compare(t, map[string]string{})
+	})
+	t.Run("set", func(t *testing.T) {
+		env := map[string]string{}
+		for i, f := range fields {
+			env[envName(f)] = sample(i, f)
+		}
+		compare(t, env)
+	})
+	t.Run("empty", func(t *testing.T) {
+		env := map[string]string{}
+		for _, f := range fields {
+			if kind := f.Type.Kind(); kind == reflect.String || kind == reflect.Bool {
+				env[envName(f)] = ""
+			}
+		}
+		compare(t, env)
+	})
+	for _, f := range fields {
+		if kind := f.Type.Kind(); kind == reflect.String || kind == reflect.Bool {
+			continue
+		}
+		t.Run("invalid "+envName(f), func(t *testing.T) {
+			compare(t, map[string]string{envName(f): "invalid"})
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/discovery"
+)
+
+// FileSuffix is appended to the variable of a secret setting to name the file
+// its value is read from, so the secret itself is never in the environment
+const FileSuffix = "_FILE"
+
+// fileSetting returns the variable of a field that can be read from a file:
+// every sensitive setting, and SASL_USERNAME, which is usually kept in the same
+// secret as SASL_PASSWORD
+func fileSetting(field reflect.StructField) (env string, ok bool) {
+	env, sensitive := parseTag(field.Tag.Get("cpln"))
+	if env == "" || field.Type.Kind() != reflect.String {
+		return "", false
+	}
+	return env, sensitive || field.Name == "SASLUsername"
+}
+
+// fromSecretFile reports whether the variable is read from its <ENV>_FILE in
+// the environment
+func fromSecretFile(environment discovery.Env, field reflect.StructField) bool {
+	env, ok := fileSetting(field)
+	return ok && environment.Get(env+FileSuffix) != ""
+}
+
+// applySecretFiles sets the fields whose <ENV>_FILE variable is set from the
+// content of that file. Setting both a variable and its _FILE is an error, as
+// is an empty file, which usually means the secret was not mounted.
+func applySecretFiles(cfg *ConfigSchema) error {
+	environment := cfg.environment()
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		env, ok := fileSetting(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+		path := environment.Get(env + FileSuffix)
+		if path == "" {
+			continue
+		}
+		if environment.Get(env) != "" {
+			return fmt.Errorf("%s and %s%s are both set, set only one", env, env, FileSuffix)
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("invalid %s%s: %w", env, FileSuffix, err)
+		}
+		v.Field(i).SetString(value)
+	}
+	return nil
+}
+
+// readSecretFile returns the content of a secret file without its trailing
+// newline, which editors and `kubectl create secret --from-file` often add
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplySecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer unsetEnv(t, "SASL_PASSWORD")()
+	defer setEnv(t, "SASL_PASSWORD_FILE", passwordFile)()
+	cfg := &ConfigSchema{}
+	if err := applySecretFiles(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SASLPassword != "s3cret" {
+		t.Errorf("expected the password without its trailing newline, got %q", cfg.SASLPassword)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "missing file", env: map[string]string{"SASL_PASSWORD_FILE": filepath.Join(dir, "missing")}},
+		{name: "empty file", env: map[string]string{"SASL_PASSWORD_FILE": emptyFile}},
+		{name: "both set", env: map[string]string{"SASL_PASSWORD_FILE": passwordFile, "SASL_PASSWORD": "inline"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				defer setEnv(t, key, value)()
+			}
+			if err := applySecretFiles(&ConfigSchema{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSecretFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("s3cret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defer unsetEnv(t, "SASL_PASSWORD")()
+	defer setEnv(t, "SASL_PASSWORD_FILE", path)()
+
+	cfg := &ConfigSchema{}
+	if err := applySecretFiles(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range cfg.Settings() {
+		if s.Env != "SASL_PASSWORD" {
+			continue
+		}
+		if s.Source != SourceSecretFile || s.Value != RedactedValue {
+			t.Errorf("expected a redacted value from the secret file, got %+v", s)
+		}
+		return
+	}
+	t.Fatal("SASL_PASSWORD not listed")
+}
@@ -0,0 +1,55 @@
+package verification
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// baselinePath is where the broker's steady-state baseline is kept
+func (v *Verifier) baselinePath() string {
+	return filepath.Join(v.opts.ArtifactDir, fmt.Sprintf("baseline-broker-%d.json", v.brokerID))
+}
+
+// reportPath is where a verification report is stored
+func (v *Verifier) reportPath(report Report) string {
+	return filepath.Join(v.opts.ArtifactDir, fmt.Sprintf("verification-broker-%d-%d.json", v.brokerID, report.GeneratedAt.Unix()))
+}
+
+// loadBaseline reads the baseline recorded before the restart, if any
+func (v *Verifier) loadBaseline() (*Baseline, error) {
+	if v.opts.ArtifactDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(v.baselinePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	return &baseline, nil
+}
+
+// writeJSON atomically replaces path with the JSON encoding of v, so a restart
+// mid-write never leaves a truncated artifact behind
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
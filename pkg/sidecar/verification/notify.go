@@ -0,0 +1,67 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+const (
+	// webhookAttempts is how many times delivery of a report is attempted
+	webhookAttempts = 3
+	// canaryProbes is how many canary records are measured per latency sample
+	canaryProbes = 5
+)
+
+// defaultWebhook posts every report on its own, uncompressed
+func (v *Verifier) defaultWebhook() *webhook.Sender {
+	return webhook.NewSender(webhook.Options{
+		URL:      v.opts.WebhookURL,
+		Attempts: webhookAttempts,
+		Backoff:  v.opts.PollInterval,
+		Timeout:  v.opts.Timeout,
+	}, v.logger)
+}
+
+// defaultNotifier posts the report as JSON to the configured webhook
+func (v *Verifier) defaultNotifier(ctx context.Context, report Report) error {
+	return v.webhook.Send(ctx, report)
+}
+
+// defaultCanary produces canaryProbes records to the partition and returns the
+// median time the broker took to acknowledge them
+func (v *Verifier) defaultCanary(ctx context.Context, topic string, partition int32) (time.Duration, error) {
+	cl, cleanup, err := kafkaclient.NewClient(v.kafkaConfig, kgo.RecordPartitioner(kgo.ManualPartitioner()))
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, v.opts.Timeout)
+	defer cancel()
+
+	// The first record connects to the partition leader and is not measured
+	latencies := make([]time.Duration, 0, canaryProbes)
+	for i := 0; i <= canaryProbes; i++ {
+		record := &kgo.Record{
+			Topic:     topic,
+			Partition: partition,
+			Value:     []byte(fmt.Sprintf(`{"brokerId":%d,"at":%q}`, v.brokerID, v.clock.Now().UTC().Format(time.RFC3339Nano))),
+		}
+		start := v.clock.Now()
+		if err := cl.ProduceSync(ctx, record).FirstErr(); err != nil {
+			return 0, fmt.Errorf("failed to produce canary record: %w", err)
+		}
+		if i > 0 {
+			latencies = append(latencies, v.clock.Since(start))
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[len(latencies)/2], nil
+}
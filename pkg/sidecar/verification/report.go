@@ -0,0 +1,132 @@
+package verification
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// Report is the post-restart verification report of a broker
+type Report struct {
+	BrokerID int32 `json:"brokerId"`
+	// StartedAt is when the sidecar, and with it the broker, started
+	StartedAt   time.Time `json:"startedAt"`
+	ReadyAt     time.Time `json:"readyAt"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Passed      bool      `json:"passed"`
+	// Failures lists every criterion that did not pass
+	Failures   []string         `json:"failures,omitempty"`
+	Leadership LeadershipResult `json:"leadership"`
+	ISR        ISRResult        `json:"isr"`
+	Canary     *CanaryResult    `json:"canary,omitempty"`
+	Memory     *MemoryResult    `json:"memory,omitempty"`
+	// Baseline is the steady-state sample from before the restart the report compares against
+	Baseline *Baseline `json:"baseline,omitempty"`
+}
+
+// LeadershipResult reports how much of the broker's preferred leadership is restored
+type LeadershipResult struct {
+	// Preferred is the number of partitions whose preferred leader is this broker
+	Preferred int `json:"preferred"`
+	// Leading is how many of those this broker currently leads
+	Leading         int     `json:"leading"`
+	RestoredPercent float64 `json:"restoredPercent"`
+}
+
+// ISRResult reports whether the broker has rejoined the ISR of every partition it replicates
+type ISRResult struct {
+	Replicas  int      `json:"replicas"`
+	InSync    int      `json:"inSync"`
+	Complete  bool     `json:"complete"`
+	OutOfSync []string `json:"outOfSync,omitempty"`
+}
+
+// CanaryResult compares the produce latency of a canary record with the baseline
+type CanaryResult struct {
+	Topic      string   `json:"topic"`
+	Partition  int32    `json:"partition"`
+	LatencyMs  float64  `json:"latencyMs"`
+	BaselineMs *float64 `json:"baselineMs,omitempty"`
+	// Ratio is the latency relative to the baseline
+	Ratio *float64 `json:"ratio,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// MemoryResult compares the container's memory working set with the baseline
+type MemoryResult struct {
+	WorkingSetBytes         uint64   `json:"workingSetBytes"`
+	BaselineWorkingSetBytes *uint64  `json:"baselineWorkingSetBytes,omitempty"`
+	OOMRatio                float64  `json:"oomRatio"`
+	Ratio                   *float64 `json:"ratio,omitempty"`
+	Error                   string   `json:"error,omitempty"`
+}
+
+// Baseline is a steady-state sample the next restart is compared with
+type Baseline struct {
+	BrokerID        int32     `json:"brokerId"`
+	RecordedAt      time.Time `json:"recordedAt"`
+	CanaryLatencyMs *float64  `json:"canaryLatencyMs,omitempty"`
+	WorkingSetBytes *uint64   `json:"workingSetBytes,omitempty"`
+}
+
+// leadership counts the partitions this broker is the preferred leader of, and leads
+func leadership(md kadm.Metadata, broker int32) LeadershipResult {
+	var result LeadershipResult
+	for _, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			if len(p.Replicas) == 0 || p.Replicas[0] != broker {
+				continue
+			}
+			result.Preferred++
+			if p.Leader == broker {
+				result.Leading++
+			}
+		}
+	}
+	result.RestoredPercent = 100
+	if result.Preferred > 0 {
+		result.RestoredPercent = 100 * float64(result.Leading) / float64(result.Preferred)
+	}
+	return result
+}
+
+// isrMembership checks the broker is in the ISR of every partition it replicates
+func isrMembership(md kadm.Metadata, broker int32) ISRResult {
+	var result ISRResult
+	for name, topic := range md.Topics {
+		for _, p := range topic.Partitions {
+			if !contains(p.Replicas, broker) {
+				continue
+			}
+			result.Replicas++
+			if contains(p.ISR, broker) {
+				result.InSync++
+			} else {
+				result.OutOfSync = append(result.OutOfSync, fmt.Sprintf("%s-%d", name, p.Partition))
+			}
+		}
+	}
+	sort.Strings(result.OutOfSync)
+	result.Complete = result.InSync == result.Replicas
+	return result
+}
+
+// ratio returns value relative to baseline, or nil without a usable baseline
+func ratio(value float64, baseline *float64) *float64 {
+	if baseline == nil || *baseline <= 0 {
+		return nil
+	}
+	r := value / *baseline
+	return &r
+}
+
+func contains(ids []int32, id int32) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,495 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/libs-go/pkg/web"
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/freshness"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/webhook"
+)
+
+// CheckName identifies the verification loop in the freshness tracker
+const CheckName = "verification"
+
+// State is the state of the post-restart verification
+type State string
+
+const (
+	// StateWaiting means the broker has not been ready since it started
+	StateWaiting State = "waiting"
+	// StateSettling means the broker is ready and leadership is given time to move back
+	StateSettling State = "settling"
+	// StateCompleted means the report was generated; baselines are now being recorded
+	StateCompleted State = "completed"
+)
+
+// AdminClient defines the Kafka admin operations needed for verification.
+// This enables mocking in tests.
+type AdminClient interface {
+	Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+// ClientFactory creates Kafka admin clients. Allows injection for testing.
+type ClientFactory func() (AdminClient, func(), error)
+
+// Readiness reports whether the broker is ready
+type Readiness interface {
+	CheckReadiness(ctx context.Context) health.CheckResult
+}
+
+// CanaryProber measures the produce latency of a canary partition
+type CanaryProber func(ctx context.Context, topic string, partition int32) (time.Duration, error)
+
+// Notifier delivers a report
+type Notifier func(ctx context.Context, report Report) error
+
+// Options configures the post-restart verification
+type Options struct {
+	// CheckInterval is how often readiness is checked while waiting
+	CheckInterval time.Duration
+	// SettleDelay is how long after the broker is ready the report is generated,
+	// giving the controller time to hand preferred leadership back
+	SettleDelay time.Duration
+	// CanaryTopic is produced to for latency samples. Empty skips the canary.
+	CanaryTopic string
+	// WebhookURL receives every report as JSON. Empty disables delivery.
+	WebhookURL string
+	// ArtifactDir stores reports and the steady-state baseline. It must survive
+	// restarts for reports to compare with a baseline. Empty disables both.
+	ArtifactDir string
+	// BaselineInterval is how often a steady-state baseline is recorded after the report
+	BaselineInterval time.Duration
+	// MinLeadershipPercent is the share of preferred leadership that must be restored
+	MinLeadershipPercent float64
+	// MaxBaselineRatio is how far canary latency and memory working set may exceed the baseline
+	MaxBaselineRatio float64
+	// PollInterval is the base backoff between webhook delivery attempts
+	PollInterval time.Duration
+	// Timeout bounds each Kafka and webhook request
+	Timeout time.Duration
+}
+
+// Status is the observable state of the post-restart verification
+type Status struct {
+	State    State      `json:"state"`
+	BrokerID int32      `json:"brokerId"`
+	Message  string     `json:"message,omitempty"`
+	ReadyAt  *time.Time `json:"readyAt,omitempty"`
+	Report   *Report    `json:"report,omitempty"`
+	// Artifact is the stored report
+	Artifact      string `json:"artifact,omitempty"`
+	ArtifactError string `json:"artifactError,omitempty"`
+	WebhookError  string `json:"webhookError,omitempty"`
+	// Forming is set when the cluster was forming while the broker waited to be
+	// ready, i.e. a full-cluster cold start. A failed report is then stored but
+	// not delivered to the webhook, since its peers are still starting.
+	Forming            bool       `json:"forming,omitempty"`
+	BaselineRecordedAt *time.Time `json:"baselineRecordedAt,omitempty"`
+}
+
+// Verifier generates a verification report once the broker is ready after a restart
+type Verifier struct {
+	brokerID      int32
+	kafkaConfig   kafkaclient.Config
+	opts          Options
+	logger        *slog.Logger
+	readiness     Readiness
+	memory        metrics.CgroupReader
+	clientFactory ClientFactory
+	canary        CanaryProber
+	notifier      Notifier
+	webhook       *webhook.Sender
+	tracker       *freshness.Tracker
+	startedAt     time.Time
+	clock         clock.Clock
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewVerifier creates a new post-restart verification for the local broker
+func NewVerifier(brokerID int32, kafkaConfig kafkaclient.Config, readiness Readiness, memory metrics.CgroupReader, opts Options, logger *slog.Logger) *Verifier {
+	v := &Verifier{
+		brokerID:    brokerID,
+		kafkaConfig: kafkaConfig,
+		opts:        opts,
+		logger:      logger,
+		clock:       clock.Real,
+		readiness:   readiness,
+		memory:      memory,
+		status:      Status{State: StateWaiting, BrokerID: brokerID},
+	}
+	v.startedAt = v.clock.Now()
+	// Set default client factory, canary, notifier and webhook
+	v.clientFactory = v.defaultClientFactory
+	v.canary = v.defaultCanary
+	v.notifier = v.defaultNotifier
+	v.webhook = v.defaultWebhook()
+	return v
+}
+
+// SetClientFactory allows overriding the client factory for testing
+func (v *Verifier) SetClientFactory(factory ClientFactory) {
+	v.clientFactory = factory
+}
+
+// SetCanaryProber allows overriding the canary for testing
+func (v *Verifier) SetCanaryProber(canary CanaryProber) {
+	v.canary = canary
+}
+
+// SetNotifier allows overriding webhook delivery for testing
+func (v *Verifier) SetNotifier(notifier Notifier) {
+	v.notifier = notifier
+}
+
+// SetWebhook replaces the webhook sender, e.g. with one that compresses or
+// batches reports. It must deliver to WebhookURL.
+func (v *Verifier) SetWebhook(sender *webhook.Sender) {
+	v.webhook = sender
+}
+
+// SetTracker records every verification step with the freshness tracker
+func (v *Verifier) SetTracker(tracker *freshness.Tracker) {
+	v.tracker = tracker
+}
+
+// SetClock replaces the wall clock, for tests and simulations. The start time
+// of the report is taken from the clock's current time.
+func (v *Verifier) SetClock(clk clock.Clock) {
+	v.clock = clk
+	v.startedAt = clk.Now()
+}
+
+// defaultClientFactory creates a new Kafka admin client using franz-go
+func (v *Verifier) defaultClientFactory() (AdminClient, func(), error) {
+	return kafkaclient.NewAdminClient(v.kafkaConfig)
+}
+
+// Status returns a snapshot of the verification status
+func (v *Verifier) Status() Status {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.status
+}
+
+// Run waits for the broker to be ready, generates the report, and then records a
+// steady-state baseline every BaselineInterval until the context is cancelled
+func (v *Verifier) Run(ctx context.Context) {
+	ticker := v.clock.NewTicker(v.opts.CheckInterval)
+	defer ticker.Stop()
+	v.tracker.Register(CheckName)
+
+	for {
+		v.tracker.Record(CheckName, v.Step(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Step advances the verification by one check
+func (v *Verifier) Step(ctx context.Context) error {
+	status := v.Status()
+	switch status.State {
+	case StateWaiting:
+		result := v.readiness.CheckReadiness(ctx)
+		if !result.Healthy {
+			v.waiting(result)
+			return nil
+		}
+		v.ready(v.clock.Now())
+		v.logger.Info("verification: broker ready, settling before report",
+			"brokerId", v.brokerID,
+			"settleDelay", v.opts.SettleDelay)
+		return nil
+
+	case StateSettling:
+		if v.clock.Since(*status.ReadyAt) < v.opts.SettleDelay {
+			return nil
+		}
+		report, err := v.Verify(ctx, *status.ReadyAt)
+		if err != nil {
+			v.setMessage(err.Error())
+			return err
+		}
+		v.publish(ctx, report)
+		return nil
+
+	default:
+		if status.BaselineRecordedAt != nil && v.clock.Since(*status.BaselineRecordedAt) < v.opts.BaselineInterval {
+			return nil
+		}
+		return v.RecordBaseline(ctx)
+	}
+}
+
+// Verify measures the broker against the verification criteria and the baseline
+// recorded before the restart
+func (v *Verifier) Verify(ctx context.Context, readyAt time.Time) (Report, error) {
+	md, err := v.metadata(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	baseline, err := v.loadBaseline()
+	if err != nil {
+		// A damaged baseline only loses the comparison
+		v.logger.Warn("verification: ignoring baseline", "error", err)
+		baseline = nil
+	}
+
+	report := Report{
+		BrokerID:    v.brokerID,
+		StartedAt:   v.startedAt,
+		ReadyAt:     readyAt,
+		GeneratedAt: v.clock.Now().UTC(),
+		Leadership:  leadership(md, v.brokerID),
+		ISR:         isrMembership(md, v.brokerID),
+		Baseline:    baseline,
+	}
+
+	if report.Leadership.RestoredPercent < v.opts.MinLeadershipPercent {
+		report.Failures = append(report.Failures, fmt.Sprintf("leadership restored for %.1f%% of preferred partitions (minimum %.1f%%)",
+			report.Leadership.RestoredPercent, v.opts.MinLeadershipPercent))
+	}
+	if !report.ISR.Complete {
+		report.Failures = append(report.Failures, fmt.Sprintf("broker is out of the ISR of %d partitions",
+			report.ISR.Replicas-report.ISR.InSync))
+	}
+
+	if v.opts.CanaryTopic != "" {
+		var baselineMs *float64
+		if baseline != nil {
+			baselineMs = baseline.CanaryLatencyMs
+		}
+		canary := v.measureCanary(ctx, md, baselineMs)
+		report.Canary = &canary
+		switch {
+		case canary.Error != "":
+			report.Failures = append(report.Failures, "canary: "+canary.Error)
+		case canary.Ratio != nil && *canary.Ratio > v.opts.MaxBaselineRatio:
+			report.Failures = append(report.Failures, fmt.Sprintf("canary latency %.1fms is %.1fx the baseline (maximum %.1fx)",
+				canary.LatencyMs, *canary.Ratio, v.opts.MaxBaselineRatio))
+		}
+	}
+
+	if v.memory != nil {
+		var baselineBytes *uint64
+		if baseline != nil {
+			baselineBytes = baseline.WorkingSetBytes
+		}
+		memory := v.measureMemory(baselineBytes)
+		report.Memory = &memory
+		// Unreadable cgroups only lose the comparison
+		if memory.Ratio != nil && *memory.Ratio > v.opts.MaxBaselineRatio {
+			report.Failures = append(report.Failures, fmt.Sprintf("memory working set is %.1fx the baseline (maximum %.1fx)",
+				*memory.Ratio, v.opts.MaxBaselineRatio))
+		}
+	}
+
+	report.Passed = len(report.Failures) == 0
+	return report, nil
+}
+
+// RecordBaseline samples canary latency and memory while the broker is ready and
+// stores them for the next restart's report
+func (v *Verifier) RecordBaseline(ctx context.Context) error {
+	if v.opts.ArtifactDir == "" {
+		return nil
+	}
+	if result := v.readiness.CheckReadiness(ctx); !result.Healthy {
+		// Only steady state makes a useful baseline
+		return nil
+	}
+
+	baseline := Baseline{BrokerID: v.brokerID, RecordedAt: v.clock.Now().UTC()}
+	if v.opts.CanaryTopic != "" {
+		md, err := v.metadata(ctx)
+		if err != nil {
+			return err
+		}
+		canary := v.measureCanary(ctx, md, nil)
+		if canary.Error != "" {
+			return fmt.Errorf("failed to sample canary latency: %s", canary.Error)
+		}
+		baseline.CanaryLatencyMs = &canary.LatencyMs
+	}
+	if v.memory != nil {
+		if m, err := v.memory.ReadMemoryMetrics(); err == nil {
+			baseline.WorkingSetBytes = &m.WorkingSet
+		}
+	}
+
+	if err := writeJSON(v.baselinePath(), baseline); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.BaselineRecordedAt = &baseline.RecordedAt
+	return nil
+}
+
+// publish stores the report, delivers it to the webhook and completes the verification
+func (v *Verifier) publish(ctx context.Context, report Report) {
+	if report.Passed {
+		v.logger.Info("verification: broker passed post-restart verification", "brokerId", v.brokerID)
+	} else {
+		v.logger.Warn("verification: broker failed post-restart verification",
+			"brokerId", v.brokerID,
+			"failures", report.Failures)
+	}
+
+	var artifact, artifactErr, webhookErr string
+	if v.opts.ArtifactDir != "" {
+		artifact = v.reportPath(report)
+		if err := writeJSON(artifact, report); err != nil {
+			v.logger.Warn("verification: failed to store report", "error", err)
+			artifact, artifactErr = "", err.Error()
+		}
+	}
+	if v.opts.WebhookURL != "" {
+		if !report.Passed && v.Status().Forming {
+			// The peers of a cold-started broker are still starting, so
+			// leadership and ISR failures are expected
+			v.logger.Info("verification: not delivering the failed report of a cluster cold start", "brokerId", v.brokerID)
+		} else if err := v.notifier(ctx, report); err != nil {
+			v.logger.Warn("verification: failed to deliver report", "error", err)
+			webhookErr = err.Error()
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.State = StateCompleted
+	v.status.Message = ""
+	v.status.Report = &report
+	v.status.Artifact = artifact
+	v.status.ArtifactError = artifactErr
+	v.status.WebhookError = webhookErr
+}
+
+// measureCanary samples the produce latency of the canary partition the broker
+// leads, or else one it replicates
+func (v *Verifier) measureCanary(ctx context.Context, md kadm.Metadata, baselineMs *float64) CanaryResult {
+	result := CanaryResult{Topic: v.opts.CanaryTopic, BaselineMs: baselineMs}
+
+	partition, ok := canaryPartition(md, v.opts.CanaryTopic, v.brokerID)
+	if !ok {
+		result.Error = fmt.Sprintf("broker replicates no partition of canary topic %s", v.opts.CanaryTopic)
+		return result
+	}
+	result.Partition = partition
+
+	latency, err := v.canary(ctx, v.opts.CanaryTopic, partition)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.LatencyMs = float64(latency) / float64(time.Millisecond)
+	result.Ratio = ratio(result.LatencyMs, baselineMs)
+	return result
+}
+
+// measureMemory reads the working set and compares it with the baseline
+func (v *Verifier) measureMemory(baselineBytes *uint64) MemoryResult {
+	var result MemoryResult
+	m, err := v.memory.ReadMemoryMetrics()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.WorkingSetBytes = m.WorkingSet
+	result.OOMRatio = m.OOMRatio
+	if baselineBytes != nil {
+		result.BaselineWorkingSetBytes = baselineBytes
+		baseline := float64(*baselineBytes)
+		result.Ratio = ratio(float64(m.WorkingSet), &baseline)
+	}
+	return result
+}
+
+// canaryPartition picks the lowest canary partition the broker leads, falling
+// back to the lowest one it replicates
+func canaryPartition(md kadm.Metadata, topic string, broker int32) (int32, bool) {
+	detail, ok := md.Topics[topic]
+	if !ok {
+		return 0, false
+	}
+	var led, replicated []int32
+	for _, p := range detail.Partitions {
+		if p.Leader == broker {
+			led = append(led, p.Partition)
+		} else if contains(p.Replicas, broker) {
+			replicated = append(replicated, p.Partition)
+		}
+	}
+	for _, candidates := range [][]int32{led, replicated} {
+		if len(candidates) > 0 {
+			sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+			return candidates[0], true
+		}
+	}
+	return 0, false
+}
+
+func (v *Verifier) metadata(ctx context.Context) (kadm.Metadata, error) {
+	adm, cleanup, err := v.clientFactory()
+	if err != nil {
+		return kadm.Metadata{}, err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, v.opts.Timeout)
+	defer cancel()
+	md, err := adm.Metadata(ctx)
+	if err != nil {
+		return kadm.Metadata{}, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	return md, nil
+}
+
+func (v *Verifier) ready(at time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.State = StateSettling
+	v.status.Message = ""
+	v.status.ReadyAt = &at
+}
+
+// waiting records why the broker is not ready yet, and whether it is because
+// the cluster is forming
+func (v *Verifier) waiting(result health.CheckResult) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.Message = fmt.Sprintf("waiting for broker to be ready: %s", result.Message)
+	if result.Forming {
+		v.status.Forming = true
+	}
+}
+
+func (v *Verifier) setMessage(message string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.status.Message = message
+}
+
+// StatusHandler handles GET /admin/verification requests
+func (v *Verifier) StatusHandler(w http.ResponseWriter, _ *http.Request) {
+	_, _ = web.ReturnResponse(w, v.Status())
+}
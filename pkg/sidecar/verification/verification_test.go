@@ -0,0 +1,357 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/health"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/kafkaclient"
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/metrics"
+)
+
+// MockAdminClient is a mock implementation of AdminClient for testing
+type MockAdminClient struct {
+	MetadataFunc func(ctx context.Context, topics ...string) (kadm.Metadata, error)
+}
+
+func (m *MockAdminClient) Metadata(ctx context.Context, topics ...string) (kadm.Metadata, error) {
+	if m.MetadataFunc != nil {
+		return m.MetadataFunc(ctx, topics...)
+	}
+	return kadm.Metadata{}, nil
+}
+
+// MockReadiness is a mock implementation of Readiness for testing
+type MockReadiness struct {
+	Result health.CheckResult
+}
+
+func (m *MockReadiness) CheckReadiness(context.Context) health.CheckResult {
+	return m.Result
+}
+
+// MockCgroupReader is a mock implementation of metrics.CgroupReader for testing
+type MockCgroupReader struct {
+	WorkingSet uint64
+	Err        error
+}
+
+func (m *MockCgroupReader) ReadMemoryMetrics() (*metrics.MemoryMetrics, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &metrics.MemoryMetrics{WorkingSet: m.WorkingSet, OOMRatio: 0.5}, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// clusterMetadata has broker 0 preferred for orders-0, orders-1 and canary-1,
+// leading orders-0 and canary-1, and out of the ISR of orders-2
+func clusterMetadata() kadm.Metadata {
+	return kadm.Metadata{
+		Brokers: []kadm.BrokerDetail{{NodeID: 0}, {NodeID: 1}},
+		Topics: kadm.TopicDetails{
+			"orders": kadm.TopicDetail{
+				Partitions: kadm.PartitionDetails{
+					0: {Partition: 0, Leader: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+					1: {Partition: 1, Leader: 1, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+					2: {Partition: 2, Leader: 1, Replicas: []int32{1, 0}, ISR: []int32{1}},
+				},
+			},
+			"canary": kadm.TopicDetail{
+				Partitions: kadm.PartitionDetails{
+					0: {Partition: 0, Leader: 1, Replicas: []int32{1, 0}, ISR: []int32{1, 0}},
+					1: {Partition: 1, Leader: 0, Replicas: []int32{0, 1}, ISR: []int32{0, 1}},
+				},
+			},
+		},
+	}
+}
+
+func testOptions(dir string) Options {
+	return Options{
+		CheckInterval:        time.Second,
+		CanaryTopic:          "canary",
+		ArtifactDir:          dir,
+		BaselineInterval:     time.Hour,
+		MinLeadershipPercent: 50,
+		MaxBaselineRatio:     2,
+		PollInterval:         time.Millisecond,
+		Timeout:              5 * time.Second,
+	}
+}
+
+func newTestVerifier(readiness *MockReadiness, memory metrics.CgroupReader, opts Options) *Verifier {
+	v := NewVerifier(0, kafkaclient.Config{}, readiness, memory, opts, testLogger())
+	v.SetClientFactory(func() (AdminClient, func(), error) {
+		return &MockAdminClient{
+			MetadataFunc: func(context.Context, ...string) (kadm.Metadata, error) {
+				return clusterMetadata(), nil
+			},
+		}, func() {}, nil
+	})
+	v.SetCanaryProber(func(context.Context, string, int32) (time.Duration, error) {
+		return 30 * time.Millisecond, nil
+	})
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	baselineMs := 10.0
+	baselineBytes := uint64(1000)
+
+	tests := []struct {
+		name            string
+		baseline        *Baseline
+		workingSet      uint64
+		expectPassed    bool
+		expectFailures  []string
+		expectCanaryRat bool
+	}{
+		{
+			name:           "no baseline",
+			expectFailures: []string{"out of the ISR of 1 partitions"},
+		},
+		{
+			name:            "above baseline",
+			baseline:        &Baseline{CanaryLatencyMs: &baselineMs, WorkingSetBytes: &baselineBytes},
+			workingSet:      3000,
+			expectFailures:  []string{"out of the ISR", "canary latency 30.0ms is 3.0x", "memory working set is 3.0x"},
+			expectCanaryRat: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.baseline != nil {
+				if err := writeJSON(filepath.Join(dir, "baseline-broker-0.json"), tt.baseline); err != nil {
+					t.Fatal(err)
+				}
+			}
+			v := newTestVerifier(&MockReadiness{}, &MockCgroupReader{WorkingSet: tt.workingSet}, testOptions(dir))
+
+			report, err := v.Verify(context.Background(), time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if report.Leadership.Preferred != 3 || report.Leadership.Leading != 2 || report.Leadership.RestoredPercent != 100*float64(2)/float64(3) {
+				t.Errorf("unexpected leadership: %+v", report.Leadership)
+			}
+			if report.ISR.Complete || report.ISR.Replicas != 5 || len(report.ISR.OutOfSync) != 1 || report.ISR.OutOfSync[0] != "orders-2" {
+				t.Errorf("unexpected ISR: %+v", report.ISR)
+			}
+			if report.Canary == nil || report.Canary.Partition != 1 || report.Canary.LatencyMs != 30 {
+				t.Errorf("unexpected canary: %+v", report.Canary)
+			}
+			if (report.Canary.Ratio != nil) != tt.expectCanaryRat {
+				t.Errorf("expected canary ratio=%v, got %+v", tt.expectCanaryRat, report.Canary)
+			}
+			if report.Passed != tt.expectPassed {
+				t.Errorf("expected passed=%v, got %v", tt.expectPassed, report.Passed)
+			}
+			if len(report.Failures) != len(tt.expectFailures) {
+				t.Fatalf("expected failures %v, got %v", tt.expectFailures, report.Failures)
+			}
+			for i, want := range tt.expectFailures {
+				if !strings.Contains(report.Failures[i], want) {
+					t.Errorf("failure %d: expected %q in %q", i, want, report.Failures[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyCanaryError(t *testing.T) {
+	v := newTestVerifier(&MockReadiness{}, nil, testOptions(""))
+	v.SetCanaryProber(func(context.Context, string, int32) (time.Duration, error) {
+		return 0, errors.New("not enough replicas")
+	})
+
+	report, err := v.Verify(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Memory != nil {
+		t.Errorf("expected no memory result without a cgroup reader, got %+v", report.Memory)
+	}
+	if report.Passed || !strings.Contains(strings.Join(report.Failures, ";"), "canary: not enough replicas") {
+		t.Errorf("expected canary failure, got %v", report.Failures)
+	}
+}
+
+func TestStep(t *testing.T) {
+	dir := t.TempDir()
+	readiness := &MockReadiness{Result: health.CheckResult{Healthy: false, Message: "broker has under-replicated partitions"}}
+	v := newTestVerifier(readiness, &MockCgroupReader{WorkingSet: 2000}, testOptions(dir))
+
+	var delivered []Report
+	v.SetNotifier(func(_ context.Context, report Report) error {
+		delivered = append(delivered, report)
+		return nil
+	})
+	v.opts.WebhookURL = "http://example.invalid/hook"
+	ctx := context.Background()
+
+	// Not ready yet
+	if err := v.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := v.Status(); status.State != StateWaiting || !strings.Contains(status.Message, "under-replicated") {
+		t.Fatalf("expected waiting, got %+v", status)
+	}
+
+	// Ready, then the report once the (zero) settle delay has passed
+	readiness.Result = health.CheckResult{Healthy: true}
+	if err := v.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := v.Status(); status.State != StateSettling || status.ReadyAt == nil {
+		t.Fatalf("expected settling, got %+v", status)
+	}
+	if err := v.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := v.Status()
+	if status.State != StateCompleted || status.Report == nil || status.Artifact == "" {
+		t.Fatalf("expected completed with a stored report, got %+v", status)
+	}
+	if len(delivered) != 1 {
+		t.Errorf("expected the report to be delivered once, got %d", len(delivered))
+	}
+	data, err := os.ReadFile(status.Artifact)
+	if err != nil {
+		t.Fatalf("failed to read stored report: %v", err)
+	}
+	var stored Report
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("invalid stored report: %v", err)
+	}
+	if stored.Leadership != status.Report.Leadership {
+		t.Errorf("stored report differs: %+v", stored)
+	}
+
+	// Completed: a baseline is recorded for the next restart
+	if err := v.Step(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	baseline, err := v.loadBaseline()
+	if err != nil || baseline == nil {
+		t.Fatalf("expected a baseline, got %+v (%v)", baseline, err)
+	}
+	if *baseline.CanaryLatencyMs != 30 || *baseline.WorkingSetBytes != 2000 {
+		t.Errorf("unexpected baseline: %+v", baseline)
+	}
+	if v.Status().BaselineRecordedAt == nil {
+		t.Error("expected baseline time in status")
+	}
+}
+
+func TestStepForming(t *testing.T) {
+	readiness := &MockReadiness{Result: health.CheckResult{Healthy: false, Forming: true, Message: "cluster forming"}}
+	v := newTestVerifier(readiness, &MockCgroupReader{WorkingSet: 2000}, testOptions(t.TempDir()))
+
+	var delivered []Report
+	v.SetNotifier(func(_ context.Context, report Report) error {
+		delivered = append(delivered, report)
+		return nil
+	})
+	v.opts.WebhookURL = "http://example.invalid/hook"
+	ctx := context.Background()
+
+	// Cold start: ready once the cluster has formed, with peers still catching up
+	for _, result := range []health.CheckResult{readiness.Result, {Healthy: true}, {Healthy: true}} {
+		readiness.Result = result
+		if err := v.Step(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	status := v.Status()
+	if status.State != StateCompleted || !status.Forming || status.Report == nil || status.Report.Passed {
+		t.Fatalf("expected a failed cold start report, got %+v", status)
+	}
+	if status.Artifact == "" {
+		t.Error("expected the report to be stored")
+	}
+	if len(delivered) != 0 {
+		t.Errorf("expected no delivery during a cold start, got %d", len(delivered))
+	}
+}
+
+func TestDefaultNotifier(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil || report.BrokerID != 0 {
+			t.Errorf("unexpected webhook body: %+v (%v)", report, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	opts := testOptions("")
+	opts.WebhookURL = server.URL
+	v := newTestVerifier(&MockReadiness{}, nil, opts)
+
+	if err := v.defaultNotifier(context.Background(), Report{BrokerID: 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a retry after the failed delivery, got %d calls", calls)
+	}
+}
+
+func TestCanaryPartition(t *testing.T) {
+	md := clusterMetadata()
+
+	tests := []struct {
+		name     string
+		topic    string
+		broker   int32
+		expected int32
+		ok       bool
+	}{
+		{name: "led partition", topic: "canary", broker: 0, expected: 1, ok: true},
+		{name: "other led partition", topic: "canary", broker: 1, expected: 0, ok: true},
+		{name: "not a replica", topic: "canary", broker: 2},
+		{name: "unknown topic", topic: "missing", broker: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partition, ok := canaryPartition(md, tt.topic, tt.broker)
+			if ok != tt.ok || partition != tt.expected {
+				t.Errorf("expected (%d, %v), got (%d, %v)", tt.expected, tt.ok, partition, ok)
+			}
+		})
+	}
+
+	// Without a led partition, the lowest replicated one is used
+	canary := md.Topics["canary"]
+	p := canary.Partitions[1]
+	p.Leader = 1
+	canary.Partitions[1] = p
+	if partition, ok := canaryPartition(md, "canary", 0); !ok || partition != 0 {
+		t.Errorf("expected replicated partition 0, got (%d, %v)", partition, ok)
+	}
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+// Options configures webhook delivery
+type Options struct {
+	// URL receives the events as JSON
+	URL string
+	// Gzip compresses request bodies (Content-Encoding: gzip)
+	Gzip bool
+	// MaxBatchEvents is how many events are sent together as a JSON array. One
+	// or less sends every event on its own, as a JSON object.
+	MaxBatchEvents int
+	// MaxBatchBytes flushes a batch once its uncompressed size reaches it
+	MaxBatchBytes int
+	// FlushInterval is the longest a batched event waits before it is sent
+	FlushInterval time.Duration
+	// Attempts is how many times delivery of a body is attempted
+	Attempts int
+	// Backoff is the base wait between attempts, growing linearly
+	Backoff time.Duration
+	// Timeout bounds each request
+	Timeout time.Duration
+}
+
+// Sender delivers events to a webhook, optionally compressed and in batches so
+// that bursts of events (e.g. during an incident) do not saturate the egress link
+type Sender struct {
+	opts   Options
+	logger *slog.Logger
+	client *http.Client
+	clock  clock.Clock
+
+	mu           sync.Mutex
+	pending      []json.RawMessage
+	pendingBytes int
+}
+
+// NewSender creates a new webhook sender
+func NewSender(opts Options, logger *slog.Logger) *Sender {
+	if opts.Attempts < 1 {
+		opts.Attempts = 1
+	}
+	return &Sender{
+		opts:   opts,
+		logger: logger,
+		client: &http.Client{},
+		clock:  clock.Real,
+	}
+}
+
+// SetClock replaces the wall clock, for tests and simulations
+func (s *Sender) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// batching reports whether events are sent in batches
+func (s *Sender) batching() bool {
+	return s.opts.MaxBatchEvents > 1
+}
+
+// Send delivers the event. With batching it is queued and only delivered, by
+// the caller, once the batch is full; a partial batch is left to Run, whose
+// delivery errors are logged.
+func (s *Sender) Send(ctx context.Context, event any) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if !s.batching() {
+		return s.deliver(ctx, body)
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, body)
+	s.pendingBytes += len(body)
+	full := len(s.pending) >= s.opts.MaxBatchEvents || (s.opts.MaxBatchBytes > 0 && s.pendingBytes >= s.opts.MaxBatchBytes)
+	s.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return s.Flush(ctx)
+}
+
+// Flush delivers the queued events, if any
+func (s *Sender) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	events := s.pending
+	s.pending, s.pendingBytes = nil, 0
+	s.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	if err := s.deliver(ctx, body); err != nil {
+		return fmt.Errorf("failed to deliver %d events: %w", len(events), err)
+	}
+	return nil
+}
+
+// Run flushes partial batches every FlushInterval until the context is
+// cancelled, then flushes what is left. It returns at once without batching.
+func (s *Sender) Run(ctx context.Context) {
+	if !s.batching() || s.opts.FlushInterval <= 0 {
+		return
+	}
+	ticker := s.clock.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Give the final flush its own deadline
+			if err := s.Flush(context.WithoutCancel(ctx)); err != nil {
+				s.logger.Warn("webhook: failed to flush events on shutdown", "error", err)
+			}
+			return
+		case <-ticker.C():
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Warn("webhook: failed to flush events", "error", err)
+			}
+		}
+	}
+}
+
+// deliver posts the body, retrying failed attempts
+func (s *Sender) deliver(ctx context.Context, body []byte) error {
+	encoding := ""
+	if s.opts.Gzip {
+		compressed, err := compress(body)
+		if err != nil {
+			return err
+		}
+		body, encoding = compressed, "gzip"
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= s.opts.Attempts; attempt++ {
+		if lastErr = s.post(ctx, body, encoding); lastErr == nil {
+			return nil
+		}
+		if attempt == s.opts.Attempts {
+			break
+		}
+		s.logger.Warn("webhook: delivery failed", "attempt", attempt, "error", lastErr)
+
+		if err := s.wait(ctx, time.Duration(attempt)*s.opts.Backoff); err != nil {
+			return err
+		}
+	}
+	return lastErr
+}
+
+// wait sleeps for d on the sender's clock, returning early with the context's
+// error when it is cancelled
+func (s *Sender) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	ticker := s.clock.NewTicker(d)
+	defer ticker.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ticker.C():
+		return nil
+	}
+}
+
+func (s *Sender) post(ctx context.Context, body []byte, encoding string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
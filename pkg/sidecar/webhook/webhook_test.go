@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/controlplane-com/kafka-orchestrator/pkg/sidecar/clock"
+)
+
+type event struct {
+	ID int `json:"id"`
+}
+
+// recorder is a webhook endpoint that keeps every decoded body
+type recorder struct {
+	mu        sync.Mutex
+	bodies    []string
+	encodings []string
+	failFirst int
+	calls     int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.failFirst {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	raw, _ := io.ReadAll(body)
+	r.bodies = append(r.bodies, string(raw))
+	r.encodings = append(r.encodings, req.Header.Get("Content-Encoding"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestSender(url string, opts Options) *Sender {
+	opts.URL = url
+	opts.Timeout = time.Second
+	return NewSender(opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestSendRetries(t *testing.T) {
+	rec := &recorder{failFirst: 1}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := newTestSender(server.URL, Options{Attempts: 3, Backoff: time.Minute})
+	clk := clock.NewFake(time.Now())
+	s.SetClock(clk)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Send(context.Background(), event{ID: 1})
+	}()
+
+	// The retry waits for the backoff
+	for clk.Tickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected the retry to wait for the backoff, got %v", err)
+	default:
+	}
+	clk.Advance(time.Minute)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the retry once the backoff elapsed")
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.calls != 2 {
+		t.Errorf("expected a retry after the failed delivery, got %d calls", rec.calls)
+	}
+	if len(rec.bodies) != 1 || rec.bodies[0] != `{"id":1}` {
+		t.Errorf("expected a single JSON object, got %v", rec.bodies)
+	}
+}
+
+func TestSendGivesUp(t *testing.T) {
+	rec := &recorder{failFirst: 5}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := newTestSender(server.URL, Options{Attempts: 2, Backoff: time.Millisecond})
+	if err := s.Send(context.Background(), event{ID: 1}); err == nil {
+		t.Fatal("expected an error after every attempt failed")
+	}
+	if rec.calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", rec.calls)
+	}
+}
+
+func TestSendGzip(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := newTestSender(server.URL, Options{Gzip: true})
+	if err := s.Send(context.Background(), event{ID: 7}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.bodies) != 1 || rec.bodies[0] != `{"id":7}` || rec.encodings[0] != "gzip" {
+		t.Errorf("expected a gzip-encoded object, got %v %v", rec.bodies, rec.encodings)
+	}
+}
+
+func TestSendBatchesByCount(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := newTestSender(server.URL, Options{MaxBatchEvents: 3})
+	for i := 1; i <= 4; i++ {
+		if err := s.Send(context.Background(), event{ID: i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(rec.bodies) != 1 {
+		t.Fatalf("expected one full batch, got %v", rec.bodies)
+	}
+	var batch []event
+	if err := json.Unmarshal([]byte(rec.bodies[0]), &batch); err != nil || len(batch) != 3 {
+		t.Errorf("expected a JSON array of 3 events, got %s (%v)", rec.bodies[0], err)
+	}
+
+	// The fourth event waits for the next flush
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rec.bodies) != 2 || rec.bodies[1] != `[{"id":4}]` {
+		t.Errorf("expected the partial batch to be flushed, got %v", rec.bodies)
+	}
+	if err := s.Flush(context.Background()); err != nil || len(rec.bodies) != 2 {
+		t.Errorf("expected an empty flush to send nothing, got %v (%v)", rec.bodies, err)
+	}
+}
+
+func TestSendBatchesByBytes(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	// Each event is 8 bytes, so the second one reaches the limit
+	s := newTestSender(server.URL, Options{MaxBatchEvents: 100, MaxBatchBytes: 16})
+	for i := 1; i <= 2; i++ {
+		if err := s.Send(context.Background(), event{ID: i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(rec.bodies) != 1 || rec.bodies[0] != `[{"id":1},{"id":2}]` {
+		t.Errorf("expected a batch flushed by size, got %v", rec.bodies)
+	}
+}
+
+func TestRunFlushesOnShutdown(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	s := newTestSender(server.URL, Options{MaxBatchEvents: 10, FlushInterval: time.Hour})
+	if err := s.Send(context.Background(), event{ID: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	if len(rec.bodies) != 1 || rec.bodies[0] != `[{"id":1}]` {
+		t.Errorf("expected the pending event to be flushed on shutdown, got %v", rec.bodies)
+	}
+}